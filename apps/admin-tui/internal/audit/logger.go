@@ -25,6 +25,8 @@ const (
 	OpExport Operation = "export"
 	// OpSearch は検索操作
 	OpSearch Operation = "search"
+	// OpStatusChange は利用状態の変更操作
+	OpStatusChange Operation = "status_change"
 )
 
 // TargetType は監査ログの対象種別を表す。
@@ -37,8 +39,14 @@ const (
 	TargetClient TargetType = "client"
 	// TargetPolicy は認可ポリシー
 	TargetPolicy TargetType = "policy"
+	// TargetProfile はポリシープロファイル
+	TargetProfile TargetType = "profile"
 	// TargetSession はセッション
 	TargetSession TargetType = "session"
+	// TargetSUCIKey はSUCIホームネットワーク鍵
+	TargetSUCIKey TargetType = "suci_key"
+	// TargetLockout は認証失敗によるロックアウト
+	TargetLockout TargetType = "lockout"
 )
 
 // Entry は監査ログエントリを表す。
@@ -125,6 +133,16 @@ func (l *Logger) LogDelete(targetType TargetType, targetKey, targetIMSI string)
 	l.Log(OpDelete, targetType, targetKey, targetIMSI, string(targetType)+" deleted")
 }
 
+// LogStatusChange は利用状態の変更ログを出力する。
+// detailsには変更前後の状態と理由を「from -> to (reason)」の形式で記録する。
+func (l *Logger) LogStatusChange(targetType TargetType, targetKey, targetIMSI, from, to, reason string) {
+	details := from + " -> " + to
+	if reason != "" {
+		details += " (" + reason + ")"
+	}
+	l.LogWithDetails(OpStatusChange, targetType, targetKey, targetIMSI, string(targetType)+" status changed", details)
+}
+
 // LogImport はIMPORT操作のログを出力する。
 func (l *Logger) LogImport(targetType TargetType, count int, filename string) {
 	l.LogWithDetails(OpImport, targetType, filename, "", string(targetType)+" imported", "")
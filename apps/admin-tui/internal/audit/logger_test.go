@@ -104,6 +104,27 @@ func TestLogger_LogDelete(t *testing.T) {
 	}
 }
 
+func TestLogger_LogStatusChange(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithWriter(&buf, "admin")
+
+	logger.LogStatusChange(TargetSubscriber, "sub:440101234567890", "440101234567890", "active", "barred", "lost SIM")
+
+	output := buf.String()
+	if !strings.Contains(output, `"operation":"status_change"`) {
+		t.Error("expected operation to be status_change")
+	}
+	if !strings.Contains(output, `"details":"active -\u003e barred (lost SIM)"`) {
+		t.Errorf("expected details to contain status transition, got %s", output)
+	}
+
+	buf.Reset()
+	logger.LogStatusChange(TargetSubscriber, "sub:440101234567890", "440101234567890", "barred", "active", "")
+	if !strings.Contains(buf.String(), `"details":"barred -\u003e active"`) {
+		t.Errorf("expected details without reason, got %s", buf.String())
+	}
+}
+
 func TestLogger_LogImport(t *testing.T) {
 	var buf bytes.Buffer
 	logger := NewLoggerWithWriter(&buf, "admin")
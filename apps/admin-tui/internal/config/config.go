@@ -9,6 +9,7 @@ import (
 type Config struct {
 	ValkeyPassword string // Valkeyパスワード
 	ValkeyAddr     string // Valkeyアドレス（固定値）
+	SUCIKeyFile    string // SUCIホームネットワーク鍵ファイル（auth-serverと共通）
 }
 
 // Load は環境変数から設定を読み込む。
@@ -16,5 +17,6 @@ func Load() *Config {
 	return &Config{
 		ValkeyPassword: os.Getenv("VALKEY_PASSWORD"),
 		ValkeyAddr:     "127.0.0.1:6379", // 固定値
+		SUCIKeyFile:    os.Getenv("SUCI_KEY_FILE"),
 	}
 }
@@ -23,6 +23,15 @@ func TestLoad(t *testing.T) {
 		}
 	})
 
+	t.Run("reads SUCI_KEY_FILE from environment", func(t *testing.T) {
+		t.Setenv("SUCI_KEY_FILE", "/etc/eapaka/suci_keys.json")
+
+		cfg := Load()
+		if cfg.SUCIKeyFile != "/etc/eapaka/suci_keys.json" {
+			t.Errorf("expected SUCIKeyFile to be '/etc/eapaka/suci_keys.json', got '%s'", cfg.SUCIKeyFile)
+		}
+	})
+
 	t.Run("returns empty password when not set", func(t *testing.T) {
 		os.Unsetenv("VALKEY_PASSWORD")
 		cfg := Load()
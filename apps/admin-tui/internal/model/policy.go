@@ -1,23 +1,74 @@
 // Package model はAdmin TUI専用のデータモデルを提供する。
 package model
 
-import "encoding/json"
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+)
 
 // Policy は加入者のアクセスポリシーを表す（D-05/D-07準拠）。
 // Valkeyキー: policy:{IMSI}
+// PLMN・IMSIプレフィックスのポリシー（policy-prefix:{prefix}）と全体の既定ポリシー（policy-default）も同じ形式で扱う。
 type Policy struct {
-	IMSI      string       `json:"imsi"`       // 加入者IMSI
-	Default   string       `json:"default"`    // デフォルトアクション（"allow" or "deny"）
+	IMSI      string       `json:"imsi"`       // 加入者IMSI（プレフィックスのポリシーではプレフィックス、全体の既定ポリシーでは空）
+	Default   string       `json:"default"`    // デフォルトアクション（"allow" or "deny"、プロファイル参照時は空も可）
 	RulesJSON string       `json:"rules_json"` // ルールのJSON文字列（Valkey保存用）
 	Rules     []PolicyRule `json:"-"`          // パース済みルール（メモリ上のみ）
+
+	// RequireAKAPrime がtrueの場合、この加入者のEAP-AKA（AKA'以外）を拒否する
+	RequireAKAPrime bool `json:"require_aka_prime"`
+
+	// MaxSessions はこの加入者の同時セッション数の上限（0はAuth Serverの全体設定に従う）
+	MaxSessions int `json:"max_sessions,omitempty"`
+
+	// Profile は参照するポリシープロファイル名（空は参照なし）。
+	// 参照時はプロファイルの設定にこのポリシーの設定を重ねる（Defaultが空の場合はプロファイルに従う）。
+	Profile string `json:"profile,omitempty"`
 }
 
 // PolicyRule はポリシールールを表す（D-05/D-07準拠）。
+// NasID以外の一致条件は未設定（空）の場合は判定しない。
 type PolicyRule struct {
-	NasID          string   `json:"nas_id"`                    // NAS識別子（ワイルドカード可）
+	NasID          string   `json:"nas_id"`                    // NAS識別子（ワイルドカード*、?可）
 	AllowedSSIDs   []string `json:"allowed_ssids"`             // 許可SSIDリスト
 	VlanID         string   `json:"vlan_id,omitempty"`         // VLAN ID（空文字は未設定）
 	SessionTimeout int      `json:"session_timeout,omitempty"` // セッションタイムアウト秒（0は未設定）
+
+	NasIPs              []string `json:"nas_ips,omitempty"`               // NAS IPアドレスまたはCIDR
+	CallingStationAllow []string `json:"calling_station_allow,omitempty"` // 許可する端末MACアドレス
+	CallingStationDeny  []string `json:"calling_station_deny,omitempty"`  // 除外する端末MACアドレス
+	NasPortTypes        []int    `json:"nas_port_types,omitempty"`        // NAS-Port-Type値（例: 19=Wireless-802.11）
+	Days                []string `json:"days,omitempty"`                  // 曜日（"mon"〜"sun"）
+	TimeStart           string   `json:"time_start,omitempty"`            // 開始時刻（"HH:MM"）
+	TimeEnd             string   `json:"time_end,omitempty"`              // 終了時刻（"HH:MM"、開始より前の場合は日付をまたぐ）
+
+	ReplyAttributes []ReplyAttribute `json:"reply_attributes,omitempty"` // Access-Acceptへ付与するRADIUS属性
+
+	Action       string `json:"action,omitempty"`        // 一致時の動作（"allow"・"deny"・"reject"、空は"allow"）
+	RejectReason string `json:"reject_reason,omitempty"` // 拒否理由（Action="reject"の場合のみ）
+	Priority     int    `json:"priority,omitempty"`      // 評価順（小さいほど先に評価、同じ値の場合は配列の順）
+}
+
+// ルール一致時の動作
+const (
+	RuleActionAllow  = "allow"
+	RuleActionDeny   = "deny"
+	RuleActionReject = "reject"
+)
+
+// 拒否理由（Action="reject"で指定、EAP失敗通知の通知コードに対応）
+const (
+	RejectReasonGeneralFailure    = "general_failure"    // General failure after authentication
+	RejectReasonTemporarilyDenied = "temporarily_denied" // User has been temporarily denied access
+	RejectReasonNotSubscribed     = "not_subscribed"     // User has not subscribed to the requested service
+)
+
+// ReplyAttribute はAccess-Acceptへ付与するRADIUS属性を表す（D-09準拠）。
+type ReplyAttribute struct {
+	Name   string `json:"name"`             // 属性名（例: "Filter-Id"）
+	Vendor string `json:"vendor,omitempty"` // ベンダー名（標準属性は空）
+	Value  string `json:"value"`            // 属性値
 }
 
 // NewPolicy は新しいPolicyを生成する。
@@ -60,15 +111,71 @@ func (p *Policy) Clone() *Policy {
 		IMSI:      p.IMSI,
 		Default:   p.Default,
 		RulesJSON: p.RulesJSON,
-		Rules:     make([]PolicyRule, len(p.Rules)),
+		Rules:     cloneRules(p.Rules),
+
+		RequireAKAPrime: p.RequireAKAPrime,
+		MaxSessions:     p.MaxSessions,
+		Profile:         p.Profile,
 	}
-	for i, rule := range p.Rules {
-		clone.Rules[i] = PolicyRule{
+	return clone
+}
+
+// cloneRules はルールのリストのディープコピーを作成する。
+func cloneRules(rules []PolicyRule) []PolicyRule {
+	clone := make([]PolicyRule, len(rules))
+	for i, rule := range rules {
+		clone[i] = PolicyRule{
 			NasID:          rule.NasID,
 			AllowedSSIDs:   append([]string{}, rule.AllowedSSIDs...),
 			VlanID:         rule.VlanID,
 			SessionTimeout: rule.SessionTimeout,
+
+			NasIPs:              slices.Clone(rule.NasIPs),
+			CallingStationAllow: slices.Clone(rule.CallingStationAllow),
+			CallingStationDeny:  slices.Clone(rule.CallingStationDeny),
+			NasPortTypes:        slices.Clone(rule.NasPortTypes),
+			Days:                slices.Clone(rule.Days),
+			TimeStart:           rule.TimeStart,
+			TimeEnd:             rule.TimeEnd,
+
+			ReplyAttributes: slices.Clone(rule.ReplyAttributes),
+
+			Action:       rule.Action,
+			RejectReason: rule.RejectReason,
+			Priority:     rule.Priority,
 		}
 	}
 	return clone
 }
+
+// SortRules はルールを評価順（Priorityの小さい順、同じ値の場合は現在の順）に並べ替える。
+func (p *Policy) SortRules() {
+	SortRules(p.Rules)
+}
+
+// MoveRule はindexのルールを隣接するルールと入れ替える（deltaは-1で上、1で下）。
+// 移動できない場合はfalseを返す。
+func (p *Policy) MoveRule(index, delta int) bool {
+	return MoveRule(p.Rules, index, delta)
+}
+
+// SortRules はルールを評価順（Priorityの小さい順、同じ値の場合は現在の順）に並べ替える。
+func SortRules(rules []PolicyRule) {
+	slices.SortStableFunc(rules, func(a, b PolicyRule) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+}
+
+// MoveRule はindexのルールを隣接するルールと入れ替える（deltaは-1で上、1で下）。
+// Priorityが異なる場合は入れ替えたルールのPriorityも交換し、評価順を表示順と一致させる。
+// 移動できない場合はfalseを返す。
+func MoveRule(rules []PolicyRule, index, delta int) bool {
+	target := index + delta
+	if index < 0 || index >= len(rules) || target < 0 || target >= len(rules) || delta == 0 {
+		return false
+	}
+	a, b := &rules[index], &rules[target]
+	a.Priority, b.Priority = b.Priority, a.Priority
+	*a, *b = *b, *a
+	return true
+}
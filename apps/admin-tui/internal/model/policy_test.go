@@ -147,15 +147,28 @@ func TestPolicy_Clone(t *testing.T) {
 			{
 				NasID:        "*",
 				AllowedSSIDs: []string{"ssid1", "ssid2"},
+				NasIPs:       []string{"10.0.0.0/24"},
+				Days:         []string{"mon"},
+				TimeStart:    "09:00",
+
+				ReplyAttributes: []ReplyAttribute{{Name: "Filter-Id", Value: "staff"}},
 			},
 		},
+		RequireAKAPrime: true,
+		MaxSessions:     2,
 	}
 
 	clone := original.Clone()
+	if !clone.RequireAKAPrime || clone.MaxSessions != 2 {
+		t.Errorf("clone lost fields: %+v", clone)
+	}
 
 	// Modify clone
 	clone.IMSI = "999999999999999"
 	clone.Rules[0].AllowedSSIDs[0] = "modified"
+	clone.Rules[0].NasIPs[0] = "192.168.0.0/16"
+	clone.Rules[0].Days[0] = "sun"
+	clone.Rules[0].ReplyAttributes[0].Value = "guest"
 
 	// Original should be unchanged
 	if original.IMSI != "440101234567890" {
@@ -164,4 +177,57 @@ func TestPolicy_Clone(t *testing.T) {
 	if original.Rules[0].AllowedSSIDs[0] != "ssid1" {
 		t.Errorf("original AllowedSSIDs was modified")
 	}
+	if original.Rules[0].NasIPs[0] != "10.0.0.0/24" || original.Rules[0].Days[0] != "mon" {
+		t.Errorf("original match criteria were modified")
+	}
+	if original.Rules[0].ReplyAttributes[0].Value != "staff" {
+		t.Errorf("original ReplyAttributes was modified")
+	}
+	if clone.Rules[0].TimeStart != "09:00" || clone.Rules[0].CallingStationAllow != nil {
+		t.Errorf("clone match criteria mismatch: %+v", clone.Rules[0])
+	}
+}
+
+func TestPolicy_SortRules(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{NasID: "a", Priority: 10},
+			{NasID: "b"},
+			{NasID: "c", Priority: 10},
+			{NasID: "d", Priority: 5},
+		},
+	}
+	p.SortRules()
+
+	want := []string{"b", "d", "a", "c"}
+	for i, rule := range p.Rules {
+		if rule.NasID != want[i] {
+			t.Errorf("Rules[%d].NasID = %q, want %q", i, rule.NasID, want[i])
+		}
+	}
+}
+
+func TestPolicy_MoveRule(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{NasID: "a"},
+			{NasID: "b", Priority: 5},
+			{NasID: "c", Priority: 10},
+		},
+	}
+
+	// 位置ごとのPriorityは維持され、評価順が表示順と一致する
+	if !p.MoveRule(2, -1) {
+		t.Fatal("expected MoveRule to succeed")
+	}
+	if p.Rules[1].NasID != "c" || p.Rules[1].Priority != 5 {
+		t.Errorf("Rules[1] = %+v, want c with priority 5", p.Rules[1])
+	}
+	if p.Rules[2].NasID != "b" || p.Rules[2].Priority != 10 {
+		t.Errorf("Rules[2] = %+v, want b with priority 10", p.Rules[2])
+	}
+
+	if p.MoveRule(0, -1) || p.MoveRule(2, 1) || p.MoveRule(5, -1) {
+		t.Error("expected MoveRule out of range to fail")
+	}
 }
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Profile は複数の加入者で共有するポリシープロファイルを表す（D-05/D-07準拠）。
+// Valkeyキー: profile:{Name}
+// 加入者ポリシーのProfileで参照するか、IMSI範囲で加入者ポリシーのない加入者に適用する。
+type Profile struct {
+	Name        string       `json:"name"`        // プロファイル名
+	Description string       `json:"description"` // 説明
+	Default     string       `json:"default"`     // デフォルトアクション（"allow" or "deny"）
+	RulesJSON   string       `json:"rules_json"`  // ルールのJSON文字列（Valkey保存用）
+	Rules       []PolicyRule `json:"-"`           // パース済みルール（メモリ上のみ）
+
+	// RequireAKAPrime がtrueの場合、このプロファイルを適用する加入者のEAP-AKA（AKA'以外）を拒否する
+	RequireAKAPrime bool `json:"require_aka_prime"`
+
+	// MaxSessions は同時セッション数の上限（0はAuth Serverの全体設定に従う）
+	MaxSessions int `json:"max_sessions,omitempty"`
+
+	// IMSIRanges はこのプロファイルを適用するIMSI範囲（他のプロファイルの範囲と重複不可）
+	IMSIRanges []IMSIRange `json:"imsi_ranges,omitempty"`
+}
+
+// IMSIRange はIMSIの範囲（開始・終了を含む）を表す。
+type IMSIRange struct {
+	Start string `json:"start"` // 開始IMSI（15桁）
+	End   string `json:"end"`   // 終了IMSI（15桁）
+}
+
+// Contains はIMSIが範囲に含まれるかどうかを返す（IMSIは同じ桁数の数字列として比較する）。
+func (r IMSIRange) Contains(imsi string) bool {
+	return len(imsi) == len(r.Start) && r.Start <= imsi && imsi <= r.End
+}
+
+// Overlaps は2つの範囲が重複するかどうかを返す。
+func (r IMSIRange) Overlaps(other IMSIRange) bool {
+	return r.Start <= other.End && other.Start <= r.End
+}
+
+// NewProfile は新しいProfileを生成する。
+func NewProfile(name, defaultAction string) *Profile {
+	return &Profile{
+		Name:      name,
+		Default:   defaultAction,
+		RulesJSON: "[]",
+		Rules:     []PolicyRule{},
+	}
+}
+
+// ParseRules はRulesJSONをパースしてRulesに格納する。
+func (p *Profile) ParseRules() error {
+	if p.RulesJSON == "" || p.RulesJSON == "[]" {
+		p.Rules = []PolicyRule{}
+		return nil
+	}
+	return json.Unmarshal([]byte(p.RulesJSON), &p.Rules)
+}
+
+// EncodeRules はRulesをJSON文字列にエンコードしてRulesJSONに格納する。
+func (p *Profile) EncodeRules() error {
+	data, err := json.Marshal(p.Rules)
+	if err != nil {
+		return err
+	}
+	p.RulesJSON = string(data)
+	return nil
+}
+
+// SortRules はルールを評価順（Priorityの小さい順、同じ値の場合は現在の順）に並べ替える。
+func (p *Profile) SortRules() {
+	SortRules(p.Rules)
+}
+
+// Clone はプロファイルのディープコピーを作成する。
+func (p *Profile) Clone() *Profile {
+	return &Profile{
+		Name:        p.Name,
+		Description: p.Description,
+		Default:     p.Default,
+		RulesJSON:   p.RulesJSON,
+		Rules:       cloneRules(p.Rules),
+
+		RequireAKAPrime: p.RequireAKAPrime,
+		MaxSessions:     p.MaxSessions,
+		IMSIRanges:      slices.Clone(p.IMSIRanges),
+	}
+}
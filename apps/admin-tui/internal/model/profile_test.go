@@ -0,0 +1,86 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNewProfile(t *testing.T) {
+	p := NewProfile("staff", "deny")
+
+	if p.Name != "staff" || p.Default != "deny" {
+		t.Errorf("unexpected profile: %+v", p)
+	}
+	if p.RulesJSON != "[]" || len(p.Rules) != 0 {
+		t.Errorf("expected empty rules, got %q / %d rules", p.RulesJSON, len(p.Rules))
+	}
+}
+
+func TestProfile_EncodeRules(t *testing.T) {
+	p := &Profile{Rules: []PolicyRule{{NasID: "*", AllowedSSIDs: []string{"staff"}, VlanID: "100"}}}
+	if err := p.EncodeRules(); err != nil {
+		t.Fatalf("EncodeRules() error = %v", err)
+	}
+
+	p2 := &Profile{RulesJSON: p.RulesJSON}
+	if err := p2.ParseRules(); err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	if len(p2.Rules) != 1 || p2.Rules[0].VlanID != "100" {
+		t.Errorf("round-trip mismatch: %+v", p2.Rules)
+	}
+}
+
+func TestProfile_Clone(t *testing.T) {
+	original := &Profile{
+		Name:        "staff",
+		Default:     "allow",
+		Rules:       []PolicyRule{{NasID: "*", AllowedSSIDs: []string{"ssid1"}}},
+		MaxSessions: 3,
+		IMSIRanges:  []IMSIRange{{Start: "440100000000000", End: "440100000009999"}},
+	}
+
+	clone := original.Clone()
+	if clone.MaxSessions != 3 || clone.Name != "staff" {
+		t.Errorf("clone lost fields: %+v", clone)
+	}
+
+	clone.Rules[0].AllowedSSIDs[0] = "modified"
+	clone.IMSIRanges[0].End = "440100000000001"
+
+	if original.Rules[0].AllowedSSIDs[0] != "ssid1" {
+		t.Errorf("original AllowedSSIDs was modified")
+	}
+	if original.IMSIRanges[0].End != "440100000009999" {
+		t.Errorf("original IMSIRanges was modified")
+	}
+}
+
+func TestIMSIRange(t *testing.T) {
+	r := IMSIRange{Start: "440100000000000", End: "440100000009999"}
+
+	tests := []struct {
+		imsi string
+		want bool
+	}{
+		{"440100000000000", true},
+		{"440100000005000", true},
+		{"440100000009999", true},
+		{"440100000010000", false},
+		{"440099999999999", false},
+		{"44010000000500", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.imsi, func(t *testing.T) {
+			if got := r.Contains(tt.imsi); got != tt.want {
+				t.Errorf("Contains(%q) = %v, want %v", tt.imsi, got, tt.want)
+			}
+		})
+	}
+
+	if !r.Overlaps(IMSIRange{Start: "440100000009999", End: "440100000019999"}) {
+		t.Error("expected ranges sharing an endpoint to overlap")
+	}
+	if r.Overlaps(IMSIRange{Start: "440100000010000", End: "440100000019999"}) {
+		t.Error("expected adjacent ranges not to overlap")
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/model"
 	"github.com/redis/go-redis/v9"
@@ -53,9 +54,12 @@ func (s *ClientStore) Create(ctx context.Context, c *model.RadiusClient) error {
 	}
 
 	return s.client.HSet(ctx, key, map[string]any{
-		"secret": c.Secret,
-		"name":   c.Name,
-		"vendor": c.Vendor,
+		"secret":            c.Secret,
+		"name":              c.Name,
+		"vendor":            c.Vendor,
+		"require_aka_prime": strconv.FormatBool(c.RequireAKAPrime),
+		"network_name":      c.NetworkName,
+		"policy_profile":    c.PolicyProfile,
 	}).Err()
 }
 
@@ -73,9 +77,12 @@ func (s *ClientStore) Update(ctx context.Context, c *model.RadiusClient) error {
 	}
 
 	return s.client.HSet(ctx, key, map[string]any{
-		"secret": c.Secret,
-		"name":   c.Name,
-		"vendor": c.Vendor,
+		"secret":            c.Secret,
+		"name":              c.Name,
+		"vendor":            c.Vendor,
+		"require_aka_prime": strconv.FormatBool(c.RequireAKAPrime),
+		"network_name":      c.NetworkName,
+		"policy_profile":    c.PolicyProfile,
 	}).Err()
 }
 
@@ -176,9 +183,12 @@ func (s *ClientStore) BulkCreate(ctx context.Context, clients []*model.RadiusCli
 	for _, c := range clients {
 		key := ClientKey(c.IP)
 		pipe.HSet(ctx, key, map[string]any{
-			"secret": c.Secret,
-			"name":   c.Name,
-			"vendor": c.Vendor,
+			"secret":            c.Secret,
+			"name":              c.Name,
+			"vendor":            c.Vendor,
+			"require_aka_prime": strconv.FormatBool(c.RequireAKAPrime),
+			"network_name":      c.NetworkName,
+			"policy_profile":    c.PolicyProfile,
 		})
 	}
 
@@ -187,11 +197,16 @@ func (s *ClientStore) BulkCreate(ctx context.Context, clients []*model.RadiusCli
 }
 
 // clientFromHash はHashマップからRadiusClientを構築する。
+// require_aka_primeが未設定・不正な場合はAKA'必須なしとして扱う。
 func clientFromHash(ip string, fields map[string]string) *model.RadiusClient {
+	requireAKAPrime, _ := strconv.ParseBool(fields["require_aka_prime"])
 	return &model.RadiusClient{
-		IP:     ip,
-		Secret: fields["secret"],
-		Name:   fields["name"],
-		Vendor: fields["vendor"],
+		IP:              ip,
+		Secret:          fields["secret"],
+		Name:            fields["name"],
+		Vendor:          fields["vendor"],
+		RequireAKAPrime: requireAKAPrime,
+		NetworkName:     fields["network_name"],
+		PolicyProfile:   fields["policy_profile"],
 	}
 }
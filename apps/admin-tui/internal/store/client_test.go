@@ -146,3 +146,98 @@ func TestClientStore_List_Empty(t *testing.T) {
 		t.Errorf("List() len = %d, want 0", len(list))
 	}
 }
+
+func TestClientStore_RequireAKAPrime(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	cs := NewClientStore(client)
+	ctx := context.Background()
+
+	c := &model.RadiusClient{
+		IP:              "192.168.10.2",
+		Secret:          "TESTSECRET123",
+		Name:            "Customer02",
+		RequireAKAPrime: true,
+	}
+	if err := cs.Create(ctx, c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Auth Serverが参照するHashフィールドとして保存されること
+	if v := mr.HGet(ClientKey(c.IP), "require_aka_prime"); v != "true" {
+		t.Errorf("require_aka_prime = %q, want %q", v, "true")
+	}
+
+	c.RequireAKAPrime = false
+	if err := cs.Update(ctx, c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ := cs.Get(ctx, c.IP)
+	if got.RequireAKAPrime {
+		t.Error("RequireAKAPrime should be false after update")
+	}
+
+	// フィールド未設定の既存データはAKA'必須なし
+	mr.HDel(ClientKey(c.IP), "require_aka_prime")
+	got, _ = cs.Get(ctx, c.IP)
+	if got.RequireAKAPrime {
+		t.Error("RequireAKAPrime should default to false")
+	}
+}
+
+func TestClientStore_NetworkName(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	cs := NewClientStore(client)
+	ctx := context.Background()
+
+	c := &model.RadiusClient{
+		IP:          "192.168.10.3",
+		Secret:      "TESTSECRET123",
+		Name:        "Customer03",
+		NetworkName: "5G:mnc001.mcc001.3gppnetwork.org",
+	}
+	if err := cs.Create(ctx, c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Auth Serverが参照するHashフィールドとして保存されること
+	if v := mr.HGet(ClientKey(c.IP), "network_name"); v != c.NetworkName {
+		t.Errorf("network_name = %q, want %q", v, c.NetworkName)
+	}
+
+	c.NetworkName = ""
+	if err := cs.Update(ctx, c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ := cs.Get(ctx, c.IP)
+	if got.NetworkName != "" {
+		t.Errorf("NetworkName = %q, want empty after update", got.NetworkName)
+	}
+}
+
+func TestClientStore_PolicyProfile(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	cs := NewClientStore(client)
+	ctx := context.Background()
+
+	c := &model.RadiusClient{
+		IP:            "192.168.10.4",
+		Secret:        "TESTSECRET123",
+		Name:          "Customer04",
+		PolicyProfile: "visitor",
+	}
+	if err := cs.Create(ctx, c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Auth Serverが参照するHashフィールドとして保存されること
+	if v := mr.HGet(ClientKey(c.IP), "policy_profile"); v != c.PolicyProfile {
+		t.Errorf("policy_profile = %q, want %q", v, c.PolicyProfile)
+	}
+	got, _ := cs.Get(ctx, c.IP)
+	if got.PolicyProfile != "visitor" {
+		t.Errorf("PolicyProfile = %q, want visitor", got.PolicyProfile)
+	}
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// FallbackPolicyStore は加入者ポリシーがない場合に適用するフォールバックポリシー
+// （PLMN・IMSIプレフィックスのポリシーと全体の既定ポリシー）へのアクセスを提供する。
+// プレフィックスのポリシーはmodel.PolicyのIMSIにプレフィックスを設定して扱う。
+type FallbackPolicyStore struct {
+	client *redis.Client
+}
+
+// NewFallbackPolicyStore は新しいFallbackPolicyStoreを生成する。
+func NewFallbackPolicyStore(client *redis.Client) *FallbackPolicyStore {
+	return &FallbackPolicyStore{client: client}
+}
+
+// GetPrefix は指定されたプレフィックスのポリシーを取得する。
+func (s *FallbackPolicyStore) GetPrefix(ctx context.Context, prefix string) (*model.Policy, error) {
+	return s.get(ctx, PrefixPolicyKey(prefix), prefix)
+}
+
+// CreatePrefix は新しいプレフィックスのポリシーを作成する。
+func (s *FallbackPolicyStore) CreatePrefix(ctx context.Context, policy *model.Policy) error {
+	key := PrefixPolicyKey(policy.IMSI)
+
+	// 既存チェック
+	exists, err := s.client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return errors.New("prefix policy already exists")
+	}
+
+	return s.save(ctx, key, policy)
+}
+
+// UpdatePrefix は既存のプレフィックスのポリシーを更新する。
+func (s *FallbackPolicyStore) UpdatePrefix(ctx context.Context, policy *model.Policy) error {
+	key := PrefixPolicyKey(policy.IMSI)
+
+	// 存在チェック
+	exists, err := s.client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrPolicyNotFound
+	}
+
+	return s.save(ctx, key, policy)
+}
+
+// DeletePrefix はプレフィックスのポリシーを削除する。
+func (s *FallbackPolicyStore) DeletePrefix(ctx context.Context, prefix string) error {
+	return s.delete(ctx, PrefixPolicyKey(prefix))
+}
+
+// ListPrefixes は全プレフィックスのポリシーをプレフィックス順に取得する（SCAN使用）。
+func (s *FallbackPolicyStore) ListPrefixes(ctx context.Context) ([]*model.Policy, error) {
+	var policies []*model.Policy
+	var keys []string
+
+	iter := s.client.Scan(ctx, 0, PrefixPrefixPolicy+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return policies, nil
+	}
+
+	// Pipelineで一括取得（HGETALL）
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HGetAll(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		result, err := cmd.Result()
+		if err != nil || len(result) == 0 {
+			continue
+		}
+		policy, err := policyFromHash(keys[i][len(PrefixPrefixPolicy):], result)
+		if err != nil {
+			continue
+		}
+		policies = append(policies, policy)
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].IMSI < policies[j].IMSI
+	})
+	return policies, nil
+}
+
+// GetGlobal は全体の既定ポリシーを取得する（未設定の場合はErrPolicyNotFound）。
+func (s *FallbackPolicyStore) GetGlobal(ctx context.Context) (*model.Policy, error) {
+	return s.get(ctx, KeyDefaultPolicy, "")
+}
+
+// SaveGlobal は全体の既定ポリシーを作成または更新する。
+func (s *FallbackPolicyStore) SaveGlobal(ctx context.Context, policy *model.Policy) error {
+	return s.save(ctx, KeyDefaultPolicy, policy)
+}
+
+// DeleteGlobal は全体の既定ポリシーを削除する。
+func (s *FallbackPolicyStore) DeleteGlobal(ctx context.Context) error {
+	return s.delete(ctx, KeyDefaultPolicy)
+}
+
+func (s *FallbackPolicyStore) get(ctx context.Context, key, prefix string) (*model.Policy, error) {
+	result, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// キーが存在しない場合、HGetAllは空mapを返す
+	if len(result) == 0 {
+		return nil, ErrPolicyNotFound
+	}
+
+	return policyFromHash(prefix, result)
+}
+
+func (s *FallbackPolicyStore) save(ctx context.Context, key string, policy *model.Policy) error {
+	fields, err := policyFields(policy)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, key, fields).Err()
+}
+
+func (s *FallbackPolicyStore) delete(ctx context.Context, key string) error {
+	result, err := s.client.Del(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if result == 0 {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
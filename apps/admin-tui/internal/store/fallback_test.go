@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+)
+
+func TestFallbackPolicyStore_Prefix(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	fs := NewFallbackPolicyStore(client)
+	ctx := context.Background()
+
+	plmn := model.NewPolicy("00101", "allow")
+	plmn.MaxSessions = 3
+	if err := fs.CreatePrefix(ctx, plmn); err != nil {
+		t.Fatalf("CreatePrefix() error = %v", err)
+	}
+	if err := fs.CreatePrefix(ctx, plmn); err == nil {
+		t.Error("CreatePrefix() expected error for duplicate")
+	}
+	// Auth Serverが参照するキーに保存されること
+	if v := mr.HGet("policy-prefix:00101", "default"); v != "allow" {
+		t.Errorf("default = %q, want allow", v)
+	}
+
+	iot := model.NewPolicy("0010199", "")
+	iot.Profile = "iot"
+	if err := fs.CreatePrefix(ctx, iot); err != nil {
+		t.Fatalf("CreatePrefix() error = %v", err)
+	}
+
+	got, err := fs.GetPrefix(ctx, "0010199")
+	if err != nil {
+		t.Fatalf("GetPrefix() error = %v", err)
+	}
+	if got.IMSI != "0010199" || got.Profile != "iot" || got.Default != "" {
+		t.Errorf("GetPrefix() = %+v", got)
+	}
+
+	plmn.Default = "deny"
+	if err := fs.UpdatePrefix(ctx, plmn); err != nil {
+		t.Fatalf("UpdatePrefix() error = %v", err)
+	}
+	if err := fs.UpdatePrefix(ctx, model.NewPolicy("44010", "deny")); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("UpdatePrefix() error = %v, want ErrPolicyNotFound", err)
+	}
+
+	list, err := fs.ListPrefixes(ctx)
+	if err != nil {
+		t.Fatalf("ListPrefixes() error = %v", err)
+	}
+	if len(list) != 2 || list[0].IMSI != "00101" || list[0].Default != "deny" || list[1].IMSI != "0010199" {
+		t.Errorf("ListPrefixes() = %+v", list)
+	}
+
+	// 加入者ポリシーの一覧には含まれないこと
+	policies, err := NewPolicyStore(client).List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(policies) != 0 {
+		t.Errorf("PolicyStore.List() = %d policies, want 0", len(policies))
+	}
+
+	if err := fs.DeletePrefix(ctx, "00101"); err != nil {
+		t.Fatalf("DeletePrefix() error = %v", err)
+	}
+	if _, err := fs.GetPrefix(ctx, "00101"); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("GetPrefix() error = %v, want ErrPolicyNotFound", err)
+	}
+	if err := fs.DeletePrefix(ctx, "00101"); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("DeletePrefix() error = %v, want ErrPolicyNotFound", err)
+	}
+}
+
+func TestFallbackPolicyStore_Global(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	fs := NewFallbackPolicyStore(client)
+	ctx := context.Background()
+
+	if _, err := fs.GetGlobal(ctx); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("GetGlobal() error = %v, want ErrPolicyNotFound", err)
+	}
+
+	if err := fs.SaveGlobal(ctx, model.NewPolicy("", "deny")); err != nil {
+		t.Fatalf("SaveGlobal() error = %v", err)
+	}
+	if v := mr.HGet("policy-default", "default"); v != "deny" {
+		t.Errorf("default = %q, want deny", v)
+	}
+
+	global := model.NewPolicy("", "allow")
+	global.Profile = "visitor"
+	if err := fs.SaveGlobal(ctx, global); err != nil {
+		t.Fatalf("SaveGlobal() error = %v", err)
+	}
+	got, err := fs.GetGlobal(ctx)
+	if err != nil {
+		t.Fatalf("GetGlobal() error = %v", err)
+	}
+	if got.Default != "allow" || got.Profile != "visitor" {
+		t.Errorf("GetGlobal() = %+v", got)
+	}
+
+	if err := fs.DeleteGlobal(ctx); err != nil {
+		t.Fatalf("DeleteGlobal() error = %v", err)
+	}
+	if err := fs.DeleteGlobal(ctx); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("DeleteGlobal() error = %v, want ErrPolicyNotFound", err)
+	}
+}
@@ -9,6 +9,14 @@ const (
 	PrefixClient = "client:"
 	// PrefixPolicy は認可ポリシーキーのプレフィックス
 	PrefixPolicy = "policy:"
+	// PrefixPrefixPolicy はPLMN・IMSIプレフィックスのポリシーキーのプレフィックス
+	PrefixPrefixPolicy = "policy-prefix:"
+	// KeyDefaultPolicy は全体の既定ポリシーキー
+	KeyDefaultPolicy = "policy-default"
+	// PrefixProfile はポリシープロファイルキーのプレフィックス
+	PrefixProfile = "profile:"
+	// KeyProfileRanges はIMSI範囲→プロファイルのインデックス（Sorted Set）
+	KeyProfileRanges = "idx:profile:range"
 	// PrefixSession はセッションキーのプレフィックス
 	PrefixSession = "sess:"
 	// PrefixEAPContext はEAPコンテキストキーのプレフィックス
@@ -17,6 +25,16 @@ const (
 	PrefixUserIndex = "idx:user:"
 	// KeyStatistics は統計情報キー
 	KeyStatistics = "stats:global"
+	// KeyRateLimitStats はAuth Serverがレート制限により破棄した要求数のキー（field=対象種別）
+	KeyRateLimitStats = "stats:ratelimit"
+	// PrefixIMSILockout はIMSI単位のロックアウトキーのプレフィックス
+	PrefixIMSILockout = "lockout:imsi:"
+	// PrefixIMSIFailure はIMSI単位の認証失敗カウンタキーのプレフィックス
+	PrefixIMSIFailure = "authfail:imsi:"
+	// PrefixNASLockout はNAS単位のロックアウトキーのプレフィックス
+	PrefixNASLockout = "lockout:nas:"
+	// PrefixNASFailure はNAS単位の認証失敗カウンタキーのプレフィックス
+	PrefixNASFailure = "authfail:nas:"
 )
 
 // SubscriberKey は加入者のValkeyキーを生成する。
@@ -34,11 +52,37 @@ func PolicyKey(imsi string) string {
 	return PrefixPolicy + imsi
 }
 
+// PrefixPolicyKey はPLMN・IMSIプレフィックスのポリシーのValkeyキーを生成する。
+func PrefixPolicyKey(prefix string) string {
+	return PrefixPrefixPolicy + prefix
+}
+
+// ProfileKey はポリシープロファイルのValkeyキーを生成する。
+func ProfileKey(name string) string {
+	return PrefixProfile + name
+}
+
 // SessionKey はセッションのValkeyキーを生成する。
 func SessionKey(uuid string) string {
 	return PrefixSession + uuid
 }
 
+// LockoutKey はロックアウトのValkeyキーを生成する（kindはimsiまたはnas）。
+func LockoutKey(kind, target string) string {
+	if kind == LockoutKindNAS {
+		return PrefixNASLockout + target
+	}
+	return PrefixIMSILockout + target
+}
+
+// FailureKey は認証失敗カウンタのValkeyキーを生成する（kindはimsiまたはnas）。
+func FailureKey(kind, target string) string {
+	if kind == LockoutKindNAS {
+		return PrefixNASFailure + target
+	}
+	return PrefixIMSIFailure + target
+}
+
 // UserIndexKey はユーザーインデックスのValkeyキーを生成する。
 func UserIndexKey(imsi string) string {
 	return PrefixUserIndex + imsi
@@ -41,3 +41,37 @@ func TestUserIndexKey(t *testing.T) {
 		t.Errorf("UserIndexKey() = %s, want %s", key, expected)
 	}
 }
+
+func TestProfileKey(t *testing.T) {
+	key := ProfileKey("staff")
+	expected := "profile:staff"
+	if key != expected {
+		t.Errorf("ProfileKey() = %s, want %s", key, expected)
+	}
+}
+
+func TestPrefixPolicyKey(t *testing.T) {
+	key := PrefixPolicyKey("00101")
+	expected := "policy-prefix:00101"
+	if key != expected {
+		t.Errorf("PrefixPolicyKey() = %s, want %s", key, expected)
+	}
+}
+
+func TestLockoutKey(t *testing.T) {
+	if key := LockoutKey(LockoutKindIMSI, "440101234567890"); key != "lockout:imsi:440101234567890" {
+		t.Errorf("LockoutKey(imsi) = %s, want lockout:imsi:440101234567890", key)
+	}
+	if key := LockoutKey(LockoutKindNAS, "192.168.1.1"); key != "lockout:nas:192.168.1.1" {
+		t.Errorf("LockoutKey(nas) = %s, want lockout:nas:192.168.1.1", key)
+	}
+}
+
+func TestFailureKey(t *testing.T) {
+	if key := FailureKey(LockoutKindIMSI, "440101234567890"); key != "authfail:imsi:440101234567890" {
+		t.Errorf("FailureKey(imsi) = %s, want authfail:imsi:440101234567890", key)
+	}
+	if key := FailureKey(LockoutKindNAS, "192.168.1.1"); key != "authfail:nas:192.168.1.1" {
+		t.Errorf("FailureKey(nas) = %s, want authfail:nas:192.168.1.1", key)
+	}
+}
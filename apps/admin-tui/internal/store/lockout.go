@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ロックアウトの対象種別
+const (
+	// LockoutKindIMSI は認証失敗によるIMSI単位のロックアウト
+	LockoutKindIMSI = "imsi"
+	// LockoutKindNAS は不正なMessage-AuthenticatorによるNAS単位のロックアウト
+	LockoutKindNAS = "nas"
+)
+
+// ErrLockoutNotFound はロックアウトが見つからない場合のエラー
+var ErrLockoutNotFound = errors.New("lockout not found")
+
+// Lockout はAuth Serverが設定したロックアウトを表す。
+type Lockout struct {
+	Kind      string        // 対象種別（imsi・nas）
+	Target    string        // IMSIまたはNASのIPアドレス
+	Failures  int64         // ロックアウトに至った失敗回数
+	Reason    string        // ロックアウトの契機となった失敗のevent_id
+	LockedAt  int64         // ロックアウト開始時刻（Unix秒）
+	Remaining time.Duration // ロックアウト解除までの残り時間
+}
+
+// LockoutStore はロックアウトへのアクセスを提供する。
+type LockoutStore struct {
+	client *redis.Client
+}
+
+// NewLockoutStore は新しいLockoutStoreを生成する。
+func NewLockoutStore(client *redis.Client) *LockoutStore {
+	return &LockoutStore{client: client}
+}
+
+// List は有効なロックアウトをロックアウト開始時刻の新しい順に取得する（SCAN使用）。
+func (s *LockoutStore) List(ctx context.Context) ([]*Lockout, error) {
+	var lockouts []*Lockout
+	var keys []string
+
+	for _, prefix := range []string{PrefixIMSILockout, PrefixNASLockout} {
+		iter := s.client.Scan(ctx, 0, prefix+"*", 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(keys) == 0 {
+		return lockouts, nil
+	}
+
+	// Pipelineで一括取得（HGETALL・TTL）
+	pipe := s.client.Pipeline()
+	hashCmds := make([]*redis.MapStringStringCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		hashCmds[i] = pipe.HGetAll(ctx, key)
+		ttlCmds[i] = pipe.TTL(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, cmd := range hashCmds {
+		m, err := cmd.Result()
+		if err != nil || len(m) == 0 {
+			// SCAN後に期限切れとなったロックアウトは表示しない
+			continue
+		}
+
+		lockout := &Lockout{Reason: m["reason"]}
+		if target, ok := strings.CutPrefix(keys[i], PrefixNASLockout); ok {
+			lockout.Kind, lockout.Target = LockoutKindNAS, target
+		} else {
+			lockout.Kind, lockout.Target = LockoutKindIMSI, strings.TrimPrefix(keys[i], PrefixIMSILockout)
+		}
+		lockout.Failures, _ = strconv.ParseInt(m["failures"], 10, 64)
+		lockout.LockedAt, _ = strconv.ParseInt(m["locked_at"], 10, 64)
+		if ttl, err := ttlCmds[i].Result(); err == nil && ttl > 0 {
+			lockout.Remaining = ttl
+		}
+		lockouts = append(lockouts, lockout)
+	}
+
+	sort.Slice(lockouts, func(i, j int) bool {
+		return lockouts[i].LockedAt > lockouts[j].LockedAt
+	})
+	return lockouts, nil
+}
+
+// Clear はロックアウトを解除する。
+// 解除直後の失敗で再びロックアウトしないよう、認証失敗カウンタも合わせて削除する。
+func (s *LockoutStore) Clear(ctx context.Context, kind, target string) error {
+	pipe := s.client.TxPipeline()
+	lockCmd := pipe.Del(ctx, LockoutKey(kind, target))
+	pipe.Del(ctx, FailureKey(kind, target))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if lockCmd.Val() == 0 {
+		return ErrLockoutNotFound
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLockoutStore_List(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ls := NewLockoutStore(client)
+	ctx := context.Background()
+
+	// 空リスト
+	list, err := ls.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("List() returned %d items, want 0", len(list))
+	}
+
+	// Auth Serverと同じ形式で保存
+	client.HSet(ctx, LockoutKey(LockoutKindIMSI, "440101234567890"), map[string]any{
+		"failures": "5", "reason": "AUTH_MAC_INVALID", "locked_at": "1700000000",
+	})
+	client.Expire(ctx, LockoutKey(LockoutKindIMSI, "440101234567890"), 15*time.Minute)
+	client.HSet(ctx, LockoutKey(LockoutKindNAS, "192.168.10.1"), map[string]any{
+		"failures": "10", "reason": "PKT_MA_INVALID", "locked_at": "1700000100",
+	})
+	client.Expire(ctx, LockoutKey(LockoutKindNAS, "192.168.10.1"), 5*time.Minute)
+	// 認証失敗カウンタは一覧に含めない
+	client.Set(ctx, FailureKey(LockoutKindIMSI, "440109999999999"), "2", time.Minute)
+
+	list, err = ls.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("List() returned %d items, want 2", len(list))
+	}
+
+	// ロックアウト開始時刻の新しい順
+	nas := list[0]
+	if nas.Kind != LockoutKindNAS || nas.Target != "192.168.10.1" {
+		t.Errorf("list[0] = %s/%s, want nas/192.168.10.1", nas.Kind, nas.Target)
+	}
+	if nas.Failures != 10 || nas.Reason != "PKT_MA_INVALID" || nas.LockedAt != 1700000100 {
+		t.Errorf("list[0] = %+v", nas)
+	}
+	if nas.Remaining != 5*time.Minute {
+		t.Errorf("list[0].Remaining = %v, want 5m", nas.Remaining)
+	}
+	imsi := list[1]
+	if imsi.Kind != LockoutKindIMSI || imsi.Target != "440101234567890" {
+		t.Errorf("list[1] = %s/%s, want imsi/440101234567890", imsi.Kind, imsi.Target)
+	}
+
+	// 期限切れのロックアウトは表示しない
+	mr.FastForward(6 * time.Minute)
+	list, err = ls.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Kind != LockoutKindIMSI {
+		t.Errorf("List() after expiry = %+v, want only imsi lockout", list)
+	}
+}
+
+func TestLockoutStore_Clear(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ls := NewLockoutStore(client)
+	ctx := context.Background()
+
+	lockKey := LockoutKey(LockoutKindIMSI, "440101234567890")
+	failKey := FailureKey(LockoutKindIMSI, "440101234567890")
+	client.HSet(ctx, lockKey, "failures", "5", "reason", "AUTH_RES_MISMATCH", "locked_at", "1700000000")
+	client.Set(ctx, failKey, "1", time.Minute)
+
+	if err := ls.Clear(ctx, LockoutKindIMSI, "440101234567890"); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if mr.Exists(lockKey) {
+		t.Error("lockout key should be deleted")
+	}
+	if mr.Exists(failKey) {
+		t.Error("failure counter key should be deleted")
+	}
+
+	// 既に解除済み
+	err := ls.Clear(ctx, LockoutKindIMSI, "440101234567890")
+	if !errors.Is(err, ErrLockoutNotFound) {
+		t.Errorf("Clear() expected ErrLockoutNotFound, got: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
 	"github.com/redis/go-redis/v9"
@@ -36,29 +37,7 @@ func (s *PolicyStore) Get(ctx context.Context, imsi string) (*model.Policy, erro
 		return nil, ErrPolicyNotFound
 	}
 
-	policy := &model.Policy{
-		IMSI: imsi,
-	}
-
-	// defaultフィールドの取得
-	if defaultVal, ok := result["default"]; ok {
-		policy.Default = defaultVal
-	} else {
-		policy.Default = "deny" // デフォルト値
-	}
-
-	// rulesフィールドのJSONデシリアライズ
-	if rulesJSON, ok := result["rules"]; ok && rulesJSON != "" {
-		policy.RulesJSON = rulesJSON
-		if err := json.Unmarshal([]byte(rulesJSON), &policy.Rules); err != nil {
-			return nil, err
-		}
-	} else {
-		policy.RulesJSON = "[]"
-		policy.Rules = []model.PolicyRule{}
-	}
-
-	return policy, nil
+	return policyFromHash(imsi, result)
 }
 
 // Create は新しいポリシーを作成する。
@@ -102,23 +81,11 @@ func (s *PolicyStore) Upsert(ctx context.Context, policy *model.Policy) error {
 
 // saveAsHash はポリシーをHash形式で保存する内部メソッド。
 func (s *PolicyStore) saveAsHash(ctx context.Context, key string, policy *model.Policy) error {
-	// RulesをJSONにエンコード
-	rulesJSON := "[]"
-	if len(policy.Rules) > 0 {
-		data, err := json.Marshal(policy.Rules)
-		if err != nil {
-			return err
-		}
-		rulesJSON = string(data)
-	} else if policy.RulesJSON != "" {
-		rulesJSON = policy.RulesJSON
+	fields, err := policyFields(policy)
+	if err != nil {
+		return err
 	}
-
-	// Hash形式で保存
-	return s.client.HSet(ctx, key, map[string]interface{}{
-		"default": policy.Default,
-		"rules":   rulesJSON,
-	}).Err()
+	return s.client.HSet(ctx, key, fields).Err()
 }
 
 // Delete はポリシーを削除する。
@@ -176,28 +143,9 @@ func (s *PolicyStore) List(ctx context.Context) ([]*model.Policy, error) {
 		}
 
 		// キーからIMSIを抽出
-		imsi := keys[i][len(PrefixPolicy):]
-
-		policy := &model.Policy{
-			IMSI: imsi,
-		}
-
-		// defaultフィールドの取得
-		if defaultVal, ok := result["default"]; ok {
-			policy.Default = defaultVal
-		} else {
-			policy.Default = "deny"
-		}
-
-		// rulesフィールドのJSONデシリアライズ
-		if rulesJSON, ok := result["rules"]; ok && rulesJSON != "" {
-			policy.RulesJSON = rulesJSON
-			if err := json.Unmarshal([]byte(rulesJSON), &policy.Rules); err != nil {
-				continue
-			}
-		} else {
-			policy.RulesJSON = "[]"
-			policy.Rules = []model.PolicyRule{}
+		policy, err := policyFromHash(keys[i][len(PrefixPolicy):], result)
+		if err != nil {
+			continue
 		}
 
 		policies = append(policies, policy)
@@ -224,25 +172,11 @@ func (s *PolicyStore) BulkCreate(ctx context.Context, policies []*model.Policy)
 
 	pipe := s.client.TxPipeline()
 	for _, policy := range policies {
-		key := PolicyKey(policy.IMSI)
-
-		// RulesをJSONにエンコード
-		rulesJSON := "[]"
-		if len(policy.Rules) > 0 {
-			data, err := json.Marshal(policy.Rules)
-			if err != nil {
-				return err
-			}
-			rulesJSON = string(data)
-		} else if policy.RulesJSON != "" {
-			rulesJSON = policy.RulesJSON
+		fields, err := policyFields(policy)
+		if err != nil {
+			return err
 		}
-
-		// Hash形式で保存
-		pipe.HSet(ctx, key, map[string]interface{}{
-			"default": policy.Default,
-			"rules":   rulesJSON,
-		})
+		pipe.HSet(ctx, PolicyKey(policy.IMSI), fields)
 	}
 
 	_, err := pipe.Exec(ctx)
@@ -250,6 +184,7 @@ func (s *PolicyStore) BulkCreate(ctx context.Context, policies []*model.Policy)
 }
 
 // GetIMSIsWithPolicy はポリシーが設定されているIMSIのセットを返す。
+// 加入者ポリシーのない加入者も、プロファイルのIMSI範囲に含まれる場合は対象とする。
 func (s *PolicyStore) GetIMSIsWithPolicy(ctx context.Context) (map[string]bool, error) {
 	result := make(map[string]bool)
 
@@ -266,5 +201,103 @@ func (s *PolicyStore) GetIMSIsWithPolicy(ctx context.Context) (map[string]bool,
 		return nil, err
 	}
 
+	// プロファイルのIMSI範囲
+	members, err := s.client.ZRange(ctx, KeyProfileRanges, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	var ranges []model.IMSIRange
+	for _, member := range members {
+		if r, _, ok := parseRangeMember(member); ok {
+			ranges = append(ranges, r)
+		}
+	}
+	if len(ranges) == 0 {
+		return result, nil
+	}
+
+	iter = s.client.Scan(ctx, 0, PrefixSubscriber+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		imsi := iter.Val()[len(PrefixSubscriber):]
+		for _, r := range ranges {
+			if r.Contains(imsi) {
+				result[imsi] = true
+				break
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
+
+// policyFromHash はポリシーのHashフィールドをポリシーに変換する。
+// 加入者ポリシー・プレフィックスのポリシー・全体の既定ポリシーで共通の形式。
+func policyFromHash(imsi string, result map[string]string) (*model.Policy, error) {
+	policy := &model.Policy{
+		IMSI: imsi,
+	}
+
+	// defaultフィールドの取得
+	if defaultVal, ok := result["default"]; ok {
+		policy.Default = defaultVal
+	} else {
+		policy.Default = "deny" // デフォルト値
+	}
+
+	// require_aka_primeフィールドの取得（未設定・不正値はAKA'必須なし）
+	policy.RequireAKAPrime, _ = strconv.ParseBool(result["require_aka_prime"])
+
+	// max_sessionsフィールドの取得（未設定・不正値はAuth Serverの全体設定に従う）
+	policy.MaxSessions = parseMaxSessions(result["max_sessions"])
+
+	// profileフィールドの取得（未設定はプロファイル参照なし）
+	policy.Profile = result["profile"]
+
+	// rulesフィールドのJSONデシリアライズ
+	if rulesJSON, ok := result["rules"]; ok && rulesJSON != "" {
+		policy.RulesJSON = rulesJSON
+		if err := json.Unmarshal([]byte(rulesJSON), &policy.Rules); err != nil {
+			return nil, err
+		}
+	} else {
+		policy.RulesJSON = "[]"
+		policy.Rules = []model.PolicyRule{}
+	}
+
+	return policy, nil
+}
+
+// policyFields はポリシーをAuth Serverと互換性のあるHashフィールドに変換する。
+func policyFields(policy *model.Policy) (map[string]any, error) {
+	// RulesをJSONにエンコード
+	rulesJSON := "[]"
+	if len(policy.Rules) > 0 {
+		data, err := json.Marshal(policy.Rules)
+		if err != nil {
+			return nil, err
+		}
+		rulesJSON = string(data)
+	} else if policy.RulesJSON != "" {
+		rulesJSON = policy.RulesJSON
+	}
+
+	return map[string]any{
+		"default":           policy.Default,
+		"rules":             rulesJSON,
+		"require_aka_prime": strconv.FormatBool(policy.RequireAKAPrime),
+		"max_sessions":      strconv.Itoa(policy.MaxSessions),
+		"profile":           policy.Profile,
+	}, nil
+}
+
+// parseMaxSessions はmax_sessionsフィールドを解析する（未設定・不正値は0）
+func parseMaxSessions(v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
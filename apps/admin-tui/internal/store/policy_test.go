@@ -184,7 +184,7 @@ func TestPolicyStore_BulkCreate(t *testing.T) {
 }
 
 func TestPolicyStore_GetIMSIsWithPolicy(t *testing.T) {
-	_, client := newTestRedis(t)
+	mr, client := newTestRedis(t)
 	defer client.Close()
 
 	ps := NewPolicyStore(client)
@@ -203,6 +203,19 @@ func TestPolicyStore_GetIMSIsWithPolicy(t *testing.T) {
 	if !result["001010000000001"] {
 		t.Error("expected 001010000000001 in result")
 	}
+
+	// プロファイルのIMSI範囲に含まれる加入者も対象
+	mr.HSet(SubscriberKey("001010000000100"), "ki", "x")
+	mr.HSet(SubscriberKey("001010000000200"), "ki", "x")
+	mr.ZAdd(KeyProfileRanges, 1010000000000, "001010000000000:001010000000199:staff")
+
+	result, err = ps.GetIMSIsWithPolicy(ctx)
+	if err != nil {
+		t.Fatalf("GetIMSIsWithPolicy() error = %v", err)
+	}
+	if len(result) != 3 || !result["001010000000100"] || result["001010000000200"] {
+		t.Errorf("GetIMSIsWithPolicy() = %v", result)
+	}
 }
 
 func TestPolicyStore_Get_DefaultDeny(t *testing.T) {
@@ -274,3 +287,85 @@ func TestPolicyStore_BulkCreate_WithRulesJSON(t *testing.T) {
 		t.Errorf("Get().Rules len = %d, want 1", len(got.Rules))
 	}
 }
+
+func TestPolicyStore_RequireAKAPrime(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ps := NewPolicyStore(client)
+	ctx := context.Background()
+
+	p := model.NewPolicy("001010000000005", "deny")
+	p.RequireAKAPrime = true
+	if err := ps.Create(ctx, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Auth Serverが参照するHashフィールドとして保存されること
+	if v := mr.HGet(PolicyKey(p.IMSI), "require_aka_prime"); v != "true" {
+		t.Errorf("require_aka_prime = %q, want %q", v, "true")
+	}
+
+	got, err := ps.Get(ctx, p.IMSI)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !got.RequireAKAPrime {
+		t.Error("RequireAKAPrime should be true")
+	}
+
+	list, err := ps.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || !list[0].RequireAKAPrime {
+		t.Errorf("List() RequireAKAPrime not preserved: %+v", list)
+	}
+
+	// フィールド未設定の既存データはAKA'必須なし
+	mr.HDel(PolicyKey(p.IMSI), "require_aka_prime")
+	got, _ = ps.Get(ctx, p.IMSI)
+	if got.RequireAKAPrime {
+		t.Error("RequireAKAPrime should default to false")
+	}
+}
+
+func TestPolicyStore_MaxSessions(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ps := NewPolicyStore(client)
+	ctx := context.Background()
+
+	p := model.NewPolicy("001010000000006", "deny")
+	p.MaxSessions = 2
+	if err := ps.Create(ctx, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Auth Serverが参照するHashフィールドとして保存されること
+	if v := mr.HGet(PolicyKey(p.IMSI), "max_sessions"); v != "2" {
+		t.Errorf("max_sessions = %q, want %q", v, "2")
+	}
+
+	got, err := ps.Get(ctx, p.IMSI)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.MaxSessions != 2 {
+		t.Errorf("MaxSessions = %d, want 2", got.MaxSessions)
+	}
+
+	list, err := ps.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].MaxSessions != 2 {
+		t.Errorf("List() MaxSessions not preserved: %+v", list)
+	}
+
+	// フィールド未設定・不正値は全体設定に従う（0）
+	mr.HSet(PolicyKey(p.IMSI), "max_sessions", "abc")
+	got, _ = ps.Get(ctx, p.IMSI)
+	if got.MaxSessions != 0 {
+		t.Errorf("MaxSessions = %d, want 0", got.MaxSessions)
+	}
+}
@@ -0,0 +1,436 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrProfileNotFound はプロファイルが見つからない場合のエラー
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrProfileInUse はポリシー・RADIUSクライアントから参照されているプロファイルを削除しようとした場合のエラー
+	ErrProfileInUse = errors.New("profile is referenced by policies or clients")
+	// ErrProfileRangeOverlap はIMSI範囲が他のプロファイルの範囲と重複する場合のエラー
+	ErrProfileRangeOverlap = errors.New("IMSI range overlaps with another profile")
+)
+
+// ProfileStore はポリシープロファイルデータへのアクセスを提供する。
+// IMSI範囲はAuth Serverが参照するSorted Set（idx:profile:range）にも登録する。
+type ProfileStore struct {
+	client *redis.Client
+}
+
+// NewProfileStore は新しいProfileStoreを生成する。
+func NewProfileStore(client *redis.Client) *ProfileStore {
+	return &ProfileStore{client: client}
+}
+
+// Get は指定された名前のプロファイルを取得する。
+// Auth Serverと互換性のあるHash形式で読み取る。
+func (s *ProfileStore) Get(ctx context.Context, name string) (*model.Profile, error) {
+	result, err := s.client.HGetAll(ctx, ProfileKey(name)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// キーが存在しない場合、HGetAllは空mapを返す
+	if len(result) == 0 {
+		return nil, ErrProfileNotFound
+	}
+
+	return parseProfile(name, result)
+}
+
+// Create は新しいプロファイルを作成する。
+func (s *ProfileStore) Create(ctx context.Context, profile *model.Profile) error {
+	key := ProfileKey(profile.Name)
+
+	// 既存チェック
+	exists, err := s.client.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return errors.New("profile already exists")
+	}
+
+	return s.save(ctx, profile, nil)
+}
+
+// Update は既存のプロファイルを更新する。
+func (s *ProfileStore) Update(ctx context.Context, profile *model.Profile) error {
+	current, err := s.Get(ctx, profile.Name)
+	if err != nil {
+		return err
+	}
+	return s.save(ctx, profile, current.IMSIRanges)
+}
+
+// save はプロファイルをHash形式で保存し、IMSI範囲のインデックスを更新する内部メソッド。
+// oldRangesは更新前のIMSI範囲（インデックスから削除する）。
+func (s *ProfileStore) save(ctx context.Context, profile *model.Profile, oldRanges []model.IMSIRange) error {
+	if err := s.checkRangeOverlap(ctx, profile.Name, profile.IMSIRanges); err != nil {
+		return err
+	}
+
+	// RulesをJSONにエンコード
+	rulesJSON := "[]"
+	if len(profile.Rules) > 0 {
+		data, err := json.Marshal(profile.Rules)
+		if err != nil {
+			return err
+		}
+		rulesJSON = string(data)
+	} else if profile.RulesJSON != "" {
+		rulesJSON = profile.RulesJSON
+	}
+
+	rangesJSON := "[]"
+	if len(profile.IMSIRanges) > 0 {
+		data, err := json.Marshal(profile.IMSIRanges)
+		if err != nil {
+			return err
+		}
+		rangesJSON = string(data)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, ProfileKey(profile.Name), map[string]interface{}{
+		"description":       profile.Description,
+		"default":           profile.Default,
+		"rules":             rulesJSON,
+		"require_aka_prime": strconv.FormatBool(profile.RequireAKAPrime),
+		"max_sessions":      strconv.Itoa(profile.MaxSessions),
+		"imsi_ranges":       rangesJSON,
+	})
+	for _, r := range oldRanges {
+		pipe.ZRem(ctx, KeyProfileRanges, rangeMember(profile.Name, r))
+	}
+	for _, r := range profile.IMSIRanges {
+		score, err := strconv.ParseFloat(r.Start, 64)
+		if err != nil {
+			return fmt.Errorf("invalid IMSI range start %q: %w", r.Start, err)
+		}
+		pipe.ZAdd(ctx, KeyProfileRanges, redis.Z{Score: score, Member: rangeMember(profile.Name, r)})
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// checkRangeOverlap はIMSI範囲が他のプロファイルの範囲と重複していないか確認する。
+func (s *ProfileStore) checkRangeOverlap(ctx context.Context, name string, ranges []model.IMSIRange) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	members, err := s.client.ZRange(ctx, KeyProfileRanges, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		other, owner, ok := parseRangeMember(member)
+		if !ok || owner == name {
+			continue
+		}
+		for _, r := range ranges {
+			if r.Overlaps(other) {
+				return fmt.Errorf("%w: %s-%s (%s)", ErrProfileRangeOverlap, other.Start, other.End, owner)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete はプロファイルを削除する。
+// 加入者ポリシー・フォールバックポリシー・RADIUSクライアントから参照されている場合はErrProfileInUseを返す。
+func (s *ProfileStore) Delete(ctx context.Context, name string) error {
+	current, err := s.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	refs, err := s.referencingPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	if len(refs[name]) > 0 {
+		return fmt.Errorf("%w: %d policies", ErrProfileInUse, len(refs[name]))
+	}
+	fallbackRefs, err := s.referencingFallbacks(ctx)
+	if err != nil {
+		return err
+	}
+	if len(fallbackRefs[name]) > 0 {
+		return fmt.Errorf("%w: %s", ErrProfileInUse, strings.Join(fallbackRefs[name], ", "))
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, ProfileKey(name))
+	for _, r := range current.IMSIRanges {
+		pipe.ZRem(ctx, KeyProfileRanges, rangeMember(name, r))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// List は全プロファイルのリストを取得する（SCAN使用）。
+func (s *ProfileStore) List(ctx context.Context) ([]*model.Profile, error) {
+	var profiles []*model.Profile
+	var keys []string
+
+	// SCANで全キーを取得
+	iter := s.client.Scan(ctx, 0, PrefixProfile+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return profiles, nil
+	}
+
+	// Pipelineで一括取得（HGETALL）
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HGetAll(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		result, err := cmd.Result()
+		if err != nil || len(result) == 0 {
+			continue
+		}
+
+		profile, err := parseProfile(keys[i][len(PrefixProfile):], result)
+		if err != nil {
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// Exists は指定された名前のプロファイルが存在するか確認する。
+func (s *ProfileStore) Exists(ctx context.Context, name string) (bool, error) {
+	exists, err := s.client.Exists(ctx, ProfileKey(name)).Result()
+	if err != nil {
+		return false, err
+	}
+	return exists > 0, nil
+}
+
+// ListSubscribers は指定されたプロファイルを適用する加入者のIMSIをソートして返す。
+// 加入者ポリシーで参照している加入者と、加入者ポリシーがなくIMSI範囲に含まれる加入者が対象。
+func (s *ProfileStore) ListSubscribers(ctx context.Context, name string) ([]string, error) {
+	subscribers, err := s.SubscribersByProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return subscribers[name], nil
+}
+
+// SubscribersByProfile はプロファイル名ごとに、そのプロファイルを適用する加入者のIMSIをソートして返す。
+func (s *ProfileStore) SubscribersByProfile(ctx context.Context) (map[string][]string, error) {
+	result, err := s.referencingPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// IMSI範囲の一覧
+	members, err := s.client.ZRange(ctx, KeyProfileRanges, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	type profileRange struct {
+		r    model.IMSIRange
+		name string
+	}
+	var ranges []profileRange
+	for _, member := range members {
+		if r, name, ok := parseRangeMember(member); ok {
+			ranges = append(ranges, profileRange{r: r, name: name})
+		}
+	}
+
+	if len(ranges) > 0 {
+		// 加入者ポリシーのないIMSIの範囲を判定
+		iter := s.client.Scan(ctx, 0, PrefixSubscriber+"*", 100).Iterator()
+		var imsis []string
+		for iter.Next(ctx) {
+			imsis = append(imsis, iter.Val()[len(PrefixSubscriber):])
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+
+		if len(imsis) > 0 {
+			pipe := s.client.Pipeline()
+			cmds := make([]*redis.IntCmd, len(imsis))
+			for i, imsi := range imsis {
+				cmds[i] = pipe.Exists(ctx, PolicyKey(imsi))
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return nil, err
+			}
+
+			for i, imsi := range imsis {
+				if cmds[i].Val() > 0 {
+					continue
+				}
+				for _, pr := range ranges {
+					if pr.r.Contains(imsi) {
+						result[pr.name] = append(result[pr.name], imsi)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	for _, imsis := range result {
+		sort.Strings(imsis)
+	}
+	return result, nil
+}
+
+// referencingPolicies はプロファイル名ごとに、そのプロファイルを参照する加入者ポリシーのIMSIを返す。
+func (s *ProfileStore) referencingPolicies(ctx context.Context) (map[string][]string, error) {
+	result := make(map[string][]string)
+
+	var keys []string
+	iter := s.client.Scan(ctx, 0, PrefixPolicy+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.HGet(ctx, key, "profile")
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		if name := cmd.Val(); name != "" {
+			result[name] = append(result[name], keys[i][len(PrefixPolicy):])
+		}
+	}
+	return result, nil
+}
+
+// referencingFallbacks はプロファイル名ごとに、そのプロファイルを参照するフォールバックポリシー
+// （プレフィックスのポリシー・全体の既定ポリシー）とRADIUSクライアントのキーを返す。
+func (s *ProfileStore) referencingFallbacks(ctx context.Context) (map[string][]string, error) {
+	result := make(map[string][]string)
+
+	var keys []string
+	for _, pattern := range []string{PrefixPrefixPolicy + "*", PrefixClient + "*"} {
+		iter := s.client.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+	keys = append(keys, KeyDefaultPolicy)
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		field := "profile"
+		if strings.HasPrefix(key, PrefixClient) {
+			field = "policy_profile"
+		}
+		cmds[i] = pipe.HGet(ctx, key, field)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		if name := cmd.Val(); name != "" {
+			result[name] = append(result[name], keys[i])
+		}
+	}
+	return result, nil
+}
+
+// parseProfile はプロファイルのHashフィールドをプロファイルに変換する。
+func parseProfile(name string, result map[string]string) (*model.Profile, error) {
+	profile := &model.Profile{
+		Name:        name,
+		Description: result["description"],
+	}
+
+	// defaultフィールドの取得
+	if defaultVal, ok := result["default"]; ok {
+		profile.Default = defaultVal
+	} else {
+		profile.Default = "deny"
+	}
+	profile.RequireAKAPrime, _ = strconv.ParseBool(result["require_aka_prime"])
+	profile.MaxSessions = parseMaxSessions(result["max_sessions"])
+
+	// rulesフィールドのJSONデシリアライズ
+	if rulesJSON, ok := result["rules"]; ok && rulesJSON != "" {
+		profile.RulesJSON = rulesJSON
+		if err := json.Unmarshal([]byte(rulesJSON), &profile.Rules); err != nil {
+			return nil, err
+		}
+	} else {
+		profile.RulesJSON = "[]"
+		profile.Rules = []model.PolicyRule{}
+	}
+
+	// imsi_rangesフィールドのJSONデシリアライズ
+	if rangesJSON := result["imsi_ranges"]; rangesJSON != "" {
+		if err := json.Unmarshal([]byte(rangesJSON), &profile.IMSIRanges); err != nil {
+			return nil, err
+		}
+	}
+
+	return profile, nil
+}
+
+// rangeMember はIMSI範囲インデックスのメンバー（"{開始}:{終了}:{プロファイル名}"）を生成する。
+func rangeMember(name string, r model.IMSIRange) string {
+	return r.Start + ":" + r.End + ":" + name
+}
+
+// parseRangeMember はIMSI範囲インデックスのメンバーを範囲とプロファイル名に分解する。
+func parseRangeMember(member string) (model.IMSIRange, string, bool) {
+	parts := strings.SplitN(member, ":", 3)
+	if len(parts) != 3 {
+		return model.IMSIRange{}, "", false
+	}
+	return model.IMSIRange{Start: parts[0], End: parts[1]}, parts[2], true
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+)
+
+func TestProfileStore_CRUD(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ps := NewProfileStore(client)
+	ctx := context.Background()
+
+	profile := model.NewProfile("staff", "deny")
+	profile.Description = "Staff devices"
+	profile.Rules = []model.PolicyRule{{NasID: "*", AllowedSSIDs: []string{"STAFF"}, VlanID: "100"}}
+	profile.MaxSessions = 2
+	profile.IMSIRanges = []model.IMSIRange{{Start: "001010000000000", End: "001010000000999"}}
+
+	// Create
+	if err := ps.Create(ctx, profile); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := ps.Create(ctx, profile); err == nil {
+		t.Error("Create() duplicate should return error")
+	}
+
+	// Auth Serverが参照するHashフィールドとIMSI範囲インデックス
+	if v := mr.HGet(ProfileKey("staff"), "default"); v != "deny" {
+		t.Errorf("default = %q, want %q", v, "deny")
+	}
+	members, _ := mr.ZMembers(KeyProfileRanges)
+	if len(members) != 1 || members[0] != "001010000000000:001010000000999:staff" {
+		t.Errorf("range index = %v", members)
+	}
+	if score, _ := mr.ZScore(KeyProfileRanges, members[0]); score != 1010000000000 {
+		t.Errorf("range score = %v, want 1010000000000", score)
+	}
+
+	// Get
+	got, err := ps.Get(ctx, "staff")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Description != "Staff devices" || got.MaxSessions != 2 || len(got.Rules) != 1 || len(got.IMSIRanges) != 1 {
+		t.Errorf("Get() = %+v", got)
+	}
+
+	// Update（旧範囲はインデックスから削除される）
+	got.IMSIRanges = []model.IMSIRange{{Start: "001010000001000", End: "001010000001999"}}
+	if err := ps.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	members, _ = mr.ZMembers(KeyProfileRanges)
+	if len(members) != 1 || members[0] != "001010000001000:001010000001999:staff" {
+		t.Errorf("range index after update = %v", members)
+	}
+
+	if err := ps.Update(ctx, model.NewProfile("missing", "deny")); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Update() missing error = %v, want ErrProfileNotFound", err)
+	}
+
+	// List
+	list, err := ps.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Name != "staff" {
+		t.Errorf("List() = %+v", list)
+	}
+
+	// Delete
+	if err := ps.Delete(ctx, "staff"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if mr.Exists(KeyProfileRanges) {
+		members, _ = mr.ZMembers(KeyProfileRanges)
+		t.Errorf("range index not cleared: %v", members)
+	}
+	if _, err := ps.Get(ctx, "staff"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrProfileNotFound", err)
+	}
+	if err := ps.Delete(ctx, "staff"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Delete() missing error = %v, want ErrProfileNotFound", err)
+	}
+}
+
+func TestProfileStore_RangeOverlap(t *testing.T) {
+	_, client := newTestRedis(t)
+	defer client.Close()
+
+	ps := NewProfileStore(client)
+	ctx := context.Background()
+
+	staff := model.NewProfile("staff", "deny")
+	staff.IMSIRanges = []model.IMSIRange{{Start: "001010000000000", End: "001010000000999"}}
+	if err := ps.Create(ctx, staff); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	guest := model.NewProfile("guest", "deny")
+	guest.IMSIRanges = []model.IMSIRange{{Start: "001010000000900", End: "001010000001999"}}
+	if err := ps.Create(ctx, guest); !errors.Is(err, ErrProfileRangeOverlap) {
+		t.Errorf("Create() error = %v, want ErrProfileRangeOverlap", err)
+	}
+
+	// 自身の範囲との重複は更新で許可
+	staff.IMSIRanges = []model.IMSIRange{{Start: "001010000000000", End: "001010000000499"}}
+	if err := ps.Update(ctx, staff); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := ps.Create(ctx, guest); err != nil {
+		t.Errorf("Create() after shrinking range error = %v", err)
+	}
+}
+
+func TestProfileStore_Subscribers(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ps := NewProfileStore(client)
+	policies := NewPolicyStore(client)
+	ctx := context.Background()
+
+	staff := model.NewProfile("staff", "deny")
+	staff.IMSIRanges = []model.IMSIRange{{Start: "001010000000000", End: "001010000000999"}}
+	if err := ps.Create(ctx, staff); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// 加入者ポリシーで参照
+	ref := model.NewPolicy("001010000005000", "")
+	ref.Profile = "staff"
+	if err := policies.Create(ctx, ref); err != nil {
+		t.Fatalf("Create() policy error = %v", err)
+	}
+	// 範囲内だが独自の加入者ポリシーあり（範囲は適用されない）
+	if err := policies.Create(ctx, model.NewPolicy("001010000000002", "deny")); err != nil {
+		t.Fatalf("Create() policy error = %v", err)
+	}
+	// 範囲内で加入者ポリシーなし
+	mr.HSet(SubscriberKey("001010000000001"), "ki", "x")
+	mr.HSet(SubscriberKey("001010000000002"), "ki", "x")
+	mr.HSet(SubscriberKey("001010000002000"), "ki", "x")
+
+	got, err := ps.ListSubscribers(ctx, "staff")
+	if err != nil {
+		t.Fatalf("ListSubscribers() error = %v", err)
+	}
+	want := []string{"001010000000001", "001010000005000"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ListSubscribers() = %v, want %v", got, want)
+	}
+
+	// 参照されているプロファイルは削除不可
+	if err := ps.Delete(ctx, "staff"); !errors.Is(err, ErrProfileInUse) {
+		t.Errorf("Delete() error = %v, want ErrProfileInUse", err)
+	}
+
+	// Policy側でprofileフィールドが保存・取得されること
+	p, err := policies.Get(ctx, ref.IMSI)
+	if err != nil {
+		t.Fatalf("Get() policy error = %v", err)
+	}
+	if p.Profile != "staff" || p.Default != "" {
+		t.Errorf("policy Profile = %q, Default = %q", p.Profile, p.Default)
+	}
+}
+
+func TestProfileStore_DeleteReferencedByFallback(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ps := NewProfileStore(client)
+	ctx := context.Background()
+
+	if err := ps.Create(ctx, model.NewProfile("visitor", "allow")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	refs := []struct {
+		key   string
+		field string
+	}{
+		{"policy-prefix:00101", "profile"},
+		{"policy-default", "profile"},
+		{"client:192.168.1.1", "policy_profile"},
+	}
+	for _, ref := range refs {
+		mr.HSet(ref.key, ref.field, "visitor")
+		if err := ps.Delete(ctx, "visitor"); !errors.Is(err, ErrProfileInUse) {
+			t.Errorf("Delete() with %s error = %v, want ErrProfileInUse", ref.key, err)
+		}
+		mr.Del(ref.key)
+	}
+
+	if err := ps.Delete(ctx, "visitor"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -12,7 +13,11 @@ type Statistics struct {
 	ClientCount     int64 `json:"client_count"`
 	PolicyCount     int64 `json:"policy_count"`
 	SessionCount    int64 `json:"session_count"`
-	UpdatedAt       int64 `json:"updated_at"`
+	// Auth Serverがレート制限により破棄したAccess-Requestの累計（対象種別ごと）
+	RateLimitedClient  int64 `json:"rate_limited_client"`
+	RateLimitedStation int64 `json:"rate_limited_station"`
+	RateLimitedIMSI    int64 `json:"rate_limited_imsi"`
+	UpdatedAt          int64 `json:"updated_at"`
 }
 
 // StatisticsStore は統計情報へのアクセスを提供する。
@@ -64,9 +69,9 @@ func (s *StatisticsStore) Refresh(ctx context.Context) (*Statistics, error) {
 
 	// 並列で各カウントを取得
 	var wg sync.WaitGroup
-	var subscriberErr, clientErr, policyErr, sessionErr error
+	var subscriberErr, clientErr, policyErr, sessionErr, rateLimitErr error
 
-	wg.Add(4)
+	wg.Add(5)
 
 	go func() {
 		defer wg.Done()
@@ -112,6 +117,18 @@ func (s *StatisticsStore) Refresh(ctx context.Context) (*Statistics, error) {
 		stats.SessionCount = count
 	}()
 
+	go func() {
+		defer wg.Done()
+		m, err := s.sessionStore.client.HGetAll(ctx, KeyRateLimitStats).Result()
+		if err != nil {
+			rateLimitErr = err
+			return
+		}
+		stats.RateLimitedClient, _ = strconv.ParseInt(m["client"], 10, 64)
+		stats.RateLimitedStation, _ = strconv.ParseInt(m["station"], 10, 64)
+		stats.RateLimitedIMSI, _ = strconv.ParseInt(m["imsi"], 10, 64)
+	}()
+
 	wg.Wait()
 
 	// エラーがあっても部分的な結果を返す（エラーは最初のものを返す）
@@ -127,6 +144,9 @@ func (s *StatisticsStore) Refresh(ctx context.Context) (*Statistics, error) {
 	if sessionErr != nil {
 		return stats, sessionErr
 	}
+	if rateLimitErr != nil {
+		return stats, rateLimitErr
+	}
 
 	// キャッシュ更新
 	s.mu.Lock()
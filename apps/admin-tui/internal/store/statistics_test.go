@@ -57,6 +57,31 @@ func TestStatisticsStore_Refresh(t *testing.T) {
 	}
 }
 
+func TestStatisticsStore_Refresh_RateLimited(t *testing.T) {
+	_, client := newTestRedis(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	statsStore := NewStatisticsStore(NewSubscriberStore(client), NewClientStore(client), NewPolicyStore(client), NewSessionStore(client))
+
+	// Auth Serverと同じ形式で破棄数を保存（stationは未記録）
+	client.HSet(ctx, KeyRateLimitStats, "client", "12", "imsi", "3")
+
+	stats, err := statsStore.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if stats.RateLimitedClient != 12 {
+		t.Errorf("RateLimitedClient = %d, want 12", stats.RateLimitedClient)
+	}
+	if stats.RateLimitedStation != 0 {
+		t.Errorf("RateLimitedStation = %d, want 0", stats.RateLimitedStation)
+	}
+	if stats.RateLimitedIMSI != 3 {
+		t.Errorf("RateLimitedIMSI = %d, want 3", stats.RateLimitedIMSI)
+	}
+}
+
 func TestStatisticsStore_Get_Cache(t *testing.T) {
 	_, client := newTestRedis(t)
 	defer client.Close()
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/model"
@@ -59,13 +60,9 @@ func (s *SubscriberStore) Create(ctx context.Context, sub *model.Subscriber) err
 		createdAt = time.Now().UTC().Format(time.RFC3339)
 	}
 
-	return s.client.HSet(ctx, key, map[string]any{
-		"ki":         sub.Ki,
-		"opc":        sub.OPc,
-		"amf":        sub.AMF,
-		"sqn":        sub.SQN,
-		"created_at": createdAt,
-	}).Err()
+	fields := subscriberFields(sub)
+	fields["created_at"] = createdAt
+	return s.client.HSet(ctx, key, fields).Err()
 }
 
 // Update は既存の加入者を更新する。
@@ -81,12 +78,14 @@ func (s *SubscriberStore) Update(ctx context.Context, sub *model.Subscriber) err
 		return ErrSubscriberNotFound
 	}
 
-	return s.client.HSet(ctx, key, map[string]any{
-		"ki":  sub.Ki,
-		"opc": sub.OPc,
-		"amf": sub.AMF,
-		"sqn": sub.SQN,
-	}).Err()
+	// 空になった任意フィールドは削除する
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, key, subscriberFields(sub))
+	if empty := emptyOptionalFields(sub); len(empty) > 0 {
+		pipe.HDel(ctx, key, empty...)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
 // Delete は加入者を削除する。
@@ -191,27 +190,69 @@ func (s *SubscriberStore) BulkCreate(ctx context.Context, subscribers []*model.S
 			createdAt = time.Now().UTC().Format(time.RFC3339)
 		}
 
-		pipe.HSet(ctx, key, map[string]any{
-			"ki":         sub.Ki,
-			"opc":        sub.OPc,
-			"amf":        sub.AMF,
-			"sqn":        sub.SQN,
-			"created_at": createdAt,
-		})
+		fields := subscriberFields(sub)
+		fields["created_at"] = createdAt
+		pipe.HSet(ctx, key, fields)
 	}
 
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
+// subscriberFields はSubscriberを保存用のHashフィールドに変換する（created_atを除く）。
+// 利用状態・有効期間は設定されている場合のみ含める。
+func subscriberFields(sub *model.Subscriber) map[string]any {
+	fields := map[string]any{
+		"ki":          sub.Ki,
+		"opc":         sub.OPc,
+		"amf":         sub.AMF,
+		"sqn":         sub.SQN,
+		"sim_enabled": strconv.FormatBool(sub.SIMEnabled),
+	}
+	for name, value := range optionalSubscriberFields(sub) {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// emptyOptionalFields は未設定の利用状態・有効期間のフィールド名を返す。
+func emptyOptionalFields(sub *model.Subscriber) []string {
+	var names []string
+	for name, value := range optionalSubscriberFields(sub) {
+		if value == "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func optionalSubscriberFields(sub *model.Subscriber) map[string]string {
+	return map[string]string{
+		"status":        sub.Status,
+		"status_reason": sub.StatusReason,
+		"valid_from":    sub.ValidFrom,
+		"valid_until":   sub.ValidUntil,
+	}
+}
+
 // subscriberFromHash はHashマップからSubscriberを構築する。
+// sim_enabledが未設定・不正な場合はEAP-SIM不許可として扱う。
 func subscriberFromHash(imsi string, fields map[string]string) *model.Subscriber {
+	simEnabled, _ := strconv.ParseBool(fields["sim_enabled"])
 	return &model.Subscriber{
-		IMSI:      imsi,
-		Ki:        fields["ki"],
-		OPc:       fields["opc"],
-		AMF:       fields["amf"],
-		SQN:       fields["sqn"],
-		CreatedAt: fields["created_at"],
+		IMSI:       imsi,
+		Ki:         fields["ki"],
+		OPc:        fields["opc"],
+		AMF:        fields["amf"],
+		SQN:        fields["sqn"],
+		CreatedAt:  fields["created_at"],
+		SIMEnabled: simEnabled,
+
+		Status:       fields["status"],
+		StatusReason: fields["status_reason"],
+		ValidFrom:    fields["valid_from"],
+		ValidUntil:   fields["valid_until"],
 	}
 }
@@ -161,6 +161,97 @@ func TestSubscriberStore_Create_DefaultCreatedAt(t *testing.T) {
 	}
 }
 
+func TestSubscriberStore_SIMEnabled(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ss := NewSubscriberStore(client)
+	ctx := context.Background()
+
+	sub := &model.Subscriber{
+		IMSI: "001010000000004",
+		Ki:   "ki", OPc: "opc", AMF: "amf", SQN: "sqn",
+		SIMEnabled: true,
+	}
+	if err := ss.Create(ctx, sub); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Vector APIが参照するHashフィールドとして保存されること
+	if v := mr.HGet(SubscriberKey(sub.IMSI), "sim_enabled"); v != "true" {
+		t.Errorf("sim_enabled = %q, want %q", v, "true")
+	}
+
+	sub.SIMEnabled = false
+	if err := ss.Update(ctx, sub); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ := ss.Get(ctx, sub.IMSI)
+	if got.SIMEnabled {
+		t.Error("SIMEnabled should be false after update")
+	}
+
+	// フィールド未設定の既存データはEAP-SIM不許可
+	mr.HDel(SubscriberKey(sub.IMSI), "sim_enabled")
+	got, _ = ss.Get(ctx, sub.IMSI)
+	if got.SIMEnabled {
+		t.Error("SIMEnabled should default to false")
+	}
+}
+
+func TestSubscriberStore_Status(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer client.Close()
+
+	ss := NewSubscriberStore(client)
+	ctx := context.Background()
+
+	sub := &model.Subscriber{
+		IMSI: "001010000000005",
+		Ki:   "ki", OPc: "opc", AMF: "amf", SQN: "sqn",
+	}
+	if err := ss.Create(ctx, sub); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// 未設定の利用状態・有効期間はフィールドを作成しない
+	for _, field := range []string{"status", "status_reason", "valid_from", "valid_until"} {
+		if mr.HGet(SubscriberKey(sub.IMSI), field) != "" {
+			t.Errorf("%s should not be set", field)
+		}
+	}
+
+	sub.Status = model.SubscriberStatusBarred
+	sub.StatusReason = "lost SIM"
+	sub.ValidFrom = "2025-01-01T00:00:00Z"
+	sub.ValidUntil = "2026-01-01T00:00:00Z"
+	if err := ss.Update(ctx, sub); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	// Vector APIが参照するHashフィールドとして保存されること
+	if v := mr.HGet(SubscriberKey(sub.IMSI), "status"); v != "barred" {
+		t.Errorf("status = %q, want %q", v, "barred")
+	}
+	got, _ := ss.Get(ctx, sub.IMSI)
+	if got.Status != sub.Status || got.StatusReason != sub.StatusReason ||
+		got.ValidFrom != sub.ValidFrom || got.ValidUntil != sub.ValidUntil {
+		t.Errorf("Get() = %+v, want status fields of %+v", got, sub)
+	}
+
+	// 空にしたフィールドは削除される
+	sub.Status = model.SubscriberStatusActive
+	sub.StatusReason = ""
+	sub.ValidFrom = ""
+	if err := ss.Update(ctx, sub); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if mr.HGet(SubscriberKey(sub.IMSI), "status_reason") != "" {
+		t.Error("status_reason should be deleted")
+	}
+	got, _ = ss.Get(ctx, sub.IMSI)
+	if got.ValidFrom != "" || got.ValidUntil != "2026-01-01T00:00:00Z" {
+		t.Errorf("validity = %q..%q, want \"\"..2026-01-01T00:00:00Z", got.ValidFrom, got.ValidUntil)
+	}
+}
+
 func TestSubscriberStore_List_Empty(t *testing.T) {
 	_, client := newTestRedis(t)
 	defer client.Close()
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/suci"
+)
+
+// ErrSUCIKeyFileNotSet はSUCI鍵ファイルのパスが設定されていない場合のエラー
+var ErrSUCIKeyFileNotSet = errors.New("SUCI_KEY_FILE is not set")
+
+// SUCIKey はSUCIホームネットワーク鍵の表示用情報を表す。
+// 秘密鍵は画面に表示しない。
+type SUCIKey struct {
+	ID        uint8       // 鍵ID（SUCIのhnkey）
+	Scheme    suci.Scheme // 保護方式
+	PublicKey string      // USIMに設定する公開鍵（16進数）
+	CreatedAt time.Time   // 生成日時
+}
+
+// SUCIKeyStore はSUCIホームネットワーク鍵ファイルへのアクセスを提供する。
+// 鍵ファイルはauth-serverのSUCI_KEY_FILEと同じファイルを指定する。
+type SUCIKeyStore struct {
+	path string
+}
+
+// NewSUCIKeyStore は新しいSUCIKeyStoreを生成する。
+func NewSUCIKeyStore(path string) *SUCIKeyStore {
+	return &SUCIKeyStore{path: path}
+}
+
+// Path は鍵ファイルのパスを返す。
+func (s *SUCIKeyStore) Path() string {
+	return s.path
+}
+
+// List は鍵ファイルに登録された鍵を鍵ID順で返す。
+// 鍵ファイルが存在しない場合は空のスライスを返す。
+func (s *SUCIKeyStore) List() ([]*SUCIKey, error) {
+	kf, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SUCIKey, 0, len(kf.Keys))
+	for i := range kf.Keys {
+		k := &kf.Keys[i]
+		pub, err := k.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &SUCIKey{
+			ID:        k.ID,
+			Scheme:    k.Scheme,
+			PublicKey: hex.EncodeToString(pub),
+			CreatedAt: k.CreatedAt,
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+// NextID は未使用の最小の鍵IDを返す。
+func (s *SUCIKeyStore) NextID() (uint8, error) {
+	kf, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	id, ok := kf.NextID()
+	if !ok {
+		return 0, errors.New("no free key ID")
+	}
+	return id, nil
+}
+
+// Generate は新しい鍵ペアを生成して鍵ファイルに保存する。
+// 反映にはauth-serverの再起動が必要。
+func (s *SUCIKeyStore) Generate(id uint8, scheme suci.Scheme) (*SUCIKey, error) {
+	kf, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	key, err := kf.Generate(id, scheme)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := kf.Save(s.path); err != nil {
+		return nil, err
+	}
+	return &SUCIKey{
+		ID:        key.ID,
+		Scheme:    key.Scheme,
+		PublicKey: hex.EncodeToString(pub),
+		CreatedAt: key.CreatedAt,
+	}, nil
+}
+
+func (s *SUCIKeyStore) load() (*suci.KeyFile, error) {
+	if s.path == "" {
+		return nil, ErrSUCIKeyFileNotSet
+	}
+	kf, err := suci.LoadKeyFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &suci.KeyFile{}, nil
+	}
+	return kf, err
+}
+
+// SUCIKeyAuditKey は監査ログに記録するSUCI鍵の対象キーを生成する。
+func SUCIKeyAuditKey(id uint8) string {
+	return "suci_key:" + strconv.Itoa(int(id))
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/suci"
+)
+
+func TestSUCIKeyStore_GenerateAndList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suci_keys.json")
+	ks := NewSUCIKeyStore(path)
+
+	// 鍵ファイルが存在しない場合は空
+	keys, err := ks.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("List() len = %d, want 0", len(keys))
+	}
+
+	if _, err := ks.Generate(5, suci.SchemeProfileB); err != nil {
+		t.Fatalf("Generate(5) error = %v", err)
+	}
+	next, err := ks.NextID()
+	if err != nil || next != 0 {
+		t.Errorf("NextID() = %d, %v, want 0", next, err)
+	}
+	created, err := ks.Generate(next, suci.SchemeProfileA)
+	if err != nil {
+		t.Fatalf("Generate(0) error = %v", err)
+	}
+	if len(created.PublicKey) != 64 {
+		t.Errorf("Profile A public key length = %d, want 64 hex chars", len(created.PublicKey))
+	}
+
+	// 重複IDは拒否
+	if _, err := ks.Generate(5, suci.SchemeProfileA); !errors.Is(err, suci.ErrDuplicateKeyID) {
+		t.Errorf("Generate(duplicate) error = %v, want ErrDuplicateKeyID", err)
+	}
+
+	keys, err = ks.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != 0 || keys[1].ID != 5 {
+		t.Fatalf("List() = %+v, want IDs [0 5]", keys)
+	}
+	if keys[1].Scheme != suci.SchemeProfileB || len(keys[1].PublicKey) != 66 {
+		t.Errorf("key 5 = %+v, want Profile B with compressed public key", keys[1])
+	}
+
+	// auth-serverと同じ形式で読み込めること
+	kr, err := suci.LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	if ids := kr.KeyIDs(); len(ids) != 2 {
+		t.Errorf("KeyIDs() = %v, want 2 keys", ids)
+	}
+}
+
+func TestSUCIKeyStore_PathNotSet(t *testing.T) {
+	ks := NewSUCIKeyStore("")
+	if _, err := ks.List(); !errors.Is(err, ErrSUCIKeyFileNotSet) {
+		t.Errorf("List() error = %v, want ErrSUCIKeyFileNotSet", err)
+	}
+	if _, err := ks.Generate(1, suci.SchemeProfileA); !errors.Is(err, ErrSUCIKeyFileNotSet) {
+		t.Errorf("Generate() error = %v, want ErrSUCIKeyFileNotSet", err)
+	}
+}
+
+func TestSUCIKeyAuditKey(t *testing.T) {
+	if got := SUCIKeyAuditKey(27); got != "suci_key:27" {
+		t.Errorf("SUCIKeyAuditKey(27) = %q, want %q", got, "suci_key:27")
+	}
+}
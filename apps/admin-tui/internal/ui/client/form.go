@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"slices"
+	"sort"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/audit"
@@ -12,31 +14,36 @@ import (
 	"github.com/rivo/tview"
 )
 
+// noProfileOption は既定のポリシープロファイルを設定しない選択肢
+const noProfileOption = "-"
+
 // FormScreen はRADIUSクライアント登録/編集画面を表す。
 type FormScreen struct {
-	form        *tview.Form
-	app         *ui.App
-	clientStore *store.ClientStore
-	auditLogger *audit.Logger
-	editMode    bool
-	originalIP  string
-	onSave      func()
-	onCancel    func()
+	form         *tview.Form
+	app          *ui.App
+	clientStore  *store.ClientStore
+	profileStore *store.ProfileStore
+	auditLogger  *audit.Logger
+	editMode     bool
+	originalIP   string
+	onSave       func()
+	onCancel     func()
 }
 
 // NewFormScreen は新しいFormScreenを生成する。
-func NewFormScreen(app *ui.App, clientStore *store.ClientStore, auditLogger *audit.Logger) *FormScreen {
+func NewFormScreen(app *ui.App, clientStore *store.ClientStore, profileStore *store.ProfileStore, auditLogger *audit.Logger) *FormScreen {
 	form := tview.NewForm()
 
 	form.SetBorder(true).
 		SetBorderColor(tcell.ColorBlue)
 
 	screen := &FormScreen{
-		form:        form,
-		app:         app,
-		clientStore: clientStore,
-		auditLogger: auditLogger,
-		editMode:    false,
+		form:         form,
+		app:          app,
+		clientStore:  clientStore,
+		profileStore: profileStore,
+		auditLogger:  auditLogger,
+		editMode:     false,
 	}
 
 	return screen
@@ -69,6 +76,9 @@ func (s *FormScreen) SetupCreate() {
 	s.form.AddInputField("Secret", "", 40, nil, nil)
 	s.form.AddInputField("Name", "", 40, nil, nil)
 	s.form.AddInputField("Vendor", "", 40, nil, nil)
+	s.form.AddCheckbox("Require AKA'", false, nil)
+	s.form.AddInputField("Network Name", "", 40, nil, nil)
+	s.addProfileDropDown("")
 
 	s.form.AddButton("Save", s.handleSave)
 	s.form.AddButton("Cancel", s.handleCancel)
@@ -94,6 +104,9 @@ func (s *FormScreen) SetupEdit(ctx context.Context, ip string) error {
 	s.form.AddInputField("Secret", client.Secret, 40, nil, nil)
 	s.form.AddInputField("Name", client.Name, 40, nil, nil)
 	s.form.AddInputField("Vendor", client.Vendor, 40, nil, nil)
+	s.form.AddCheckbox("Require AKA'", client.RequireAKAPrime, nil)
+	s.form.AddInputField("Network Name", client.NetworkName, 40, nil, nil)
+	s.addProfileDropDown(client.PolicyProfile)
 
 	// IP入力フィールドを無効化
 	ipField := s.form.GetFormItemByLabel("IP Address").(*tview.InputField)
@@ -106,13 +119,39 @@ func (s *FormScreen) SetupEdit(ctx context.Context, ip string) error {
 	return nil
 }
 
+// addProfileDropDown は既定のポリシープロファイルのドロップダウンを追加する（"-"は未設定）。
+// 加入者ポリシー・プレフィックスのポリシーがない加入者にこのクライアント経由で適用される。
+// 一覧の取得に失敗した場合も現在の設定値は選択肢に含める。
+func (s *FormScreen) addProfileDropDown(current string) {
+	profiles, err := s.profileStore.List(context.Background())
+	if err != nil {
+		s.app.GetStatusBar().ShowError("Failed to load profiles: " + err.Error())
+	}
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	if current != "" && !slices.Contains(names, current) {
+		names = append(names, current)
+	}
+	sort.Strings(names)
+	options := append([]string{noProfileOption}, names...)
+
+	index := slices.Index(options, current)
+	if index < 0 {
+		index = 0
+	}
+	s.form.AddDropDown("Default Profile", options, index, nil)
+}
+
 func (s *FormScreen) handleSave() {
 	// フォームからデータを取得
 	input := &validation.ClientInput{
-		IP:     s.form.GetFormItemByLabel("IP Address").(*tview.InputField).GetText(),
-		Secret: s.form.GetFormItemByLabel("Secret").(*tview.InputField).GetText(),
-		Name:   s.form.GetFormItemByLabel("Name").(*tview.InputField).GetText(),
-		Vendor: s.form.GetFormItemByLabel("Vendor").(*tview.InputField).GetText(),
+		IP:          s.form.GetFormItemByLabel("IP Address").(*tview.InputField).GetText(),
+		Secret:      s.form.GetFormItemByLabel("Secret").(*tview.InputField).GetText(),
+		Name:        s.form.GetFormItemByLabel("Name").(*tview.InputField).GetText(),
+		Vendor:      s.form.GetFormItemByLabel("Vendor").(*tview.InputField).GetText(),
+		NetworkName: s.form.GetFormItemByLabel("Network Name").(*tview.InputField).GetText(),
 	}
 
 	// 正規化
@@ -131,10 +170,15 @@ func (s *FormScreen) save(input *validation.ClientInput) {
 	ctx := context.Background()
 
 	client := &model.RadiusClient{
-		IP:     input.IP,
-		Secret: input.Secret,
-		Name:   input.Name,
-		Vendor: input.Vendor,
+		IP:              input.IP,
+		Secret:          input.Secret,
+		Name:            input.Name,
+		Vendor:          input.Vendor,
+		RequireAKAPrime: s.form.GetFormItemByLabel("Require AKA'").(*tview.Checkbox).IsChecked(),
+		NetworkName:     input.NetworkName,
+	}
+	if _, profile := s.form.GetFormItemByLabel("Default Profile").(*tview.DropDown).GetCurrentOption(); profile != noProfileOption {
+		client.PolicyProfile = profile
 	}
 
 	if s.editMode {
@@ -53,10 +53,14 @@ func NewHelpModal(sections []HelpSection, onClose func()) *HelpModal {
 		}
 	}
 
-	// Policy Form用のF6キーバインド情報を右カラムに追記
-	rightContent += "\n[yellow::b]Policy Form[::-]\n"
+	// Policy/Profile Form用のF6キーバインド情報を右カラムに追記
+	rightContent += "\n[yellow::b]Policy/Profile Form[::-]\n"
 	rightContent += fmt.Sprintf("  [cyan]%-10s[-] %s\n", "F6", "Toggle Form/Rules focus")
 
+	// Fallback Policy List用のキーバインド情報を右カラムに追記
+	rightContent += "\n[yellow::b]Fallback Policy List[::-]\n"
+	rightContent += fmt.Sprintf("  [cyan]%-10s[-] %s\n", "g", "Edit global default policy")
+
 	leftView := tview.NewTextView().
 		SetDynamicColors(true).
 		SetText(leftContent)
@@ -104,6 +104,21 @@ func GetDefaultMenuItems() []MenuItem {
 			Description: "View statistics and active sessions",
 			Key:         '5',
 		},
+		{
+			Label:       "Home Network Keys (SUCI)",
+			Description: "Generate and list SUCI deconcealment keys",
+			Key:         '6',
+		},
+		{
+			Label:       "Policy Profiles",
+			Description: "Manage shared policy profiles and IMSI ranges",
+			Key:         '7',
+		},
+		{
+			Label:       "Fallback Policies",
+			Description: "Manage prefix and global default policies for subscribers without a policy",
+			Key:         '8',
+		},
 		{
 			Label:       "Exit",
 			Description: "Exit the application",
@@ -0,0 +1,283 @@
+package monitoring
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/format"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui"
+	"github.com/rivo/tview"
+)
+
+// LockoutListScreen は認証失敗によるIMSI・NAS単位のロックアウトの一覧画面を表す。
+type LockoutListScreen struct {
+	table        *tview.Table
+	app          *ui.App
+	lockoutStore *store.LockoutStore
+	lockouts     []*store.Lockout
+	filter       *ui.Filter
+	pagination   *ui.Pagination
+	onClear      func(lockout *store.Lockout)
+	onBack       func()
+}
+
+// NewLockoutListScreen は新しいLockoutListScreenを生成する。
+func NewLockoutListScreen(app *ui.App, lockoutStore *store.LockoutStore) *LockoutListScreen {
+	table := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	table.SetTitle(" Lockout List ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorBlue)
+
+	screen := &LockoutListScreen{
+		table:        table,
+		app:          app,
+		lockoutStore: lockoutStore,
+		filter:       ui.NewFilter("Target"),
+		pagination:   ui.NewPagination(ui.DefaultPageSize),
+	}
+
+	screen.setupKeyBindings()
+	return screen
+}
+
+// SetOnClear はロックアウト解除時のコールバックを設定する。
+func (s *LockoutListScreen) SetOnClear(handler func(lockout *store.Lockout)) {
+	s.onClear = handler
+}
+
+// SetOnBack は戻る時のコールバックを設定する。
+func (s *LockoutListScreen) SetOnBack(handler func()) {
+	s.onBack = handler
+}
+
+// GetTable は内部のtview.Tableを返す。
+func (s *LockoutListScreen) GetTable() *tview.Table {
+	return s.table
+}
+
+// Load はデータを読み込む。
+func (s *LockoutListScreen) Load(ctx context.Context) error {
+	lockouts, err := s.lockoutStore.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.lockouts = lockouts
+	s.render()
+	return nil
+}
+
+// Refresh はデータを再読み込みする。
+func (s *LockoutListScreen) Refresh(ctx context.Context) error {
+	return s.Load(ctx)
+}
+
+// SetFilter はフィルタを設定する。
+func (s *LockoutListScreen) SetFilter(query string) {
+	s.filter.SetQuery(query)
+	s.pagination.FirstPage()
+	s.render()
+}
+
+// ClearFilter はフィルタをクリアする。
+func (s *LockoutListScreen) ClearFilter() {
+	s.filter.Clear()
+	s.pagination.FirstPage()
+	s.render()
+}
+
+// GetSelectedLockout は選択されているロックアウトを返す。
+func (s *LockoutListScreen) GetSelectedLockout() *store.Lockout {
+	row, _ := s.table.GetSelection()
+	filtered := s.getFilteredLockouts()
+	if row < 1 || row > len(filtered) {
+		return nil
+	}
+
+	pageItems := ui.GetPageItems(filtered, s.pagination)
+	idx := row - 1
+	if idx < 0 || idx >= len(pageItems) {
+		return nil
+	}
+	return pageItems[idx]
+}
+
+func (s *LockoutListScreen) getFilteredLockouts() []*store.Lockout {
+	return ui.FilterItems(s.lockouts, s.filter, func(lockout *store.Lockout) []string {
+		return []string{lockout.Target, lockout.Reason}
+	})
+}
+
+func (s *LockoutListScreen) render() {
+	s.table.Clear()
+
+	// ヘッダー
+	headers := []string{"Kind", "Target", "Reason", "Failures", "Locked At", "Remaining"}
+	for col, header := range headers {
+		cell := tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetExpansion(1)
+		s.table.SetCell(0, col, cell)
+	}
+
+	// フィルタ適用
+	filtered := s.getFilteredLockouts()
+	pageItems := ui.GetPageItems(filtered, s.pagination)
+
+	// データ行
+	for i, lockout := range pageItems {
+		row := i + 1
+
+		s.table.SetCell(row, 0, tview.NewTableCell(lockout.Kind).
+			SetTextColor(tcell.ColorTeal).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 1, tview.NewTableCell(lockout.Target).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(2))
+
+		s.table.SetCell(row, 2, tview.NewTableCell(lockout.Reason).
+			SetTextColor(tcell.ColorRed).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 3, tview.NewTableCell(strconv.FormatInt(lockout.Failures, 10)).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 4, tview.NewTableCell(format.DateTimeShort(lockout.LockedAt)).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 5, tview.NewTableCell(format.Duration(int64(lockout.Remaining.Seconds()))).
+			SetTextColor(tcell.ColorGreen).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+	}
+
+	// タイトル更新
+	title := " Lockout List "
+	if s.filter.Active {
+		title += "[yellow](" + s.filter.FormatFilterStatus() + ")[-] "
+	}
+	title += "[gray]" + s.pagination.FormatPageInfo() + "[-] "
+	s.table.SetTitle(title)
+
+	// 選択を先頭に
+	if len(pageItems) > 0 {
+		s.table.SetSelectable(true, false)
+		s.table.Select(1, 0)
+	} else {
+		s.table.SetSelectable(false, false)
+		emptyCell := tview.NewTableCell("(No data)").
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false)
+		s.table.SetCell(1, 0, emptyCell)
+	}
+}
+
+func (s *LockoutListScreen) refresh() {
+	go func() {
+		s.app.QueueUpdateDraw(func() {
+			if err := s.Refresh(context.Background()); err != nil {
+				s.app.GetStatusBar().ShowError("Failed to refresh: " + err.Error())
+			} else {
+				s.app.GetStatusBar().ShowSuccess("Refreshed")
+			}
+		})
+	}()
+}
+
+// clearSelected は選択されているロックアウトを解除する。
+func (s *LockoutListScreen) clearSelected() {
+	if lockout := s.GetSelectedLockout(); lockout != nil && s.onClear != nil {
+		s.onClear(lockout)
+	}
+}
+
+func (s *LockoutListScreen) setupKeyBindings() {
+	s.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			if s.filter.Active {
+				s.ClearFilter()
+				return nil
+			}
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		case tcell.KeyF4:
+			s.clearSelected()
+			return nil
+		case tcell.KeyF5:
+			s.refresh()
+			return nil
+		case tcell.KeyPgUp:
+			if s.pagination.PrevPage() {
+				s.render()
+			}
+			return nil
+		case tcell.KeyPgDn:
+			if s.pagination.NextPage() {
+				s.render()
+			}
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'd':
+			s.clearSelected()
+			return nil
+		case 'r':
+			s.refresh()
+			return nil
+		case '/':
+			s.showFilterDialog()
+			return nil
+		case 'q':
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (s *LockoutListScreen) showFilterDialog() {
+	dialog := ui.NewInputDialog(
+		"Filter Lockouts",
+		"IMSI/IP or reason contains:",
+		s.filter.Query,
+		func(value string) {
+			s.SetFilter(value)
+			s.app.HidePage("filter-dialog")
+			s.app.RemovePage("filter-dialog")
+			s.app.SetFocus(s.table)
+		},
+		func() {
+			s.app.HidePage("filter-dialog")
+			s.app.RemovePage("filter-dialog")
+			s.app.SetFocus(s.table)
+		},
+	)
+
+	s.app.AddPage("filter-dialog", centered(dialog.GetForm(), 50, 7), true, true)
+	s.app.SetFocus(dialog.GetForm())
+}
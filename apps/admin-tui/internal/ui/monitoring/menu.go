@@ -11,6 +11,7 @@ type MenuScreen struct {
 	list          *tview.List
 	onStatistics  func()
 	onSessionList func()
+	onLockoutList func()
 	onBack        func()
 }
 
@@ -42,6 +43,11 @@ func (s *MenuScreen) SetOnSessionList(handler func()) {
 	s.onSessionList = handler
 }
 
+// SetOnLockoutList はロックアウト一覧選択時のコールバックを設定する。
+func (s *MenuScreen) SetOnLockoutList(handler func()) {
+	s.onLockoutList = handler
+}
+
 // SetOnBack は戻る時のコールバックを設定する。
 func (s *MenuScreen) SetOnBack(handler func()) {
 	s.onBack = handler
@@ -67,6 +73,12 @@ func (s *MenuScreen) setupMenu() {
 		}
 	})
 
+	s.list.AddItem("Lockout List", "View and clear active authentication lockouts", '3', func() {
+		if s.onLockoutList != nil {
+			s.onLockoutList()
+		}
+	})
+
 	s.list.AddItem("Back", "Return to main menu", 'q', func() {
 		if s.onBack != nil {
 			s.onBack()
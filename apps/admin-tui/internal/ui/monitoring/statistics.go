@@ -89,6 +89,11 @@ func (s *StatisticsScreen) render(stats *store.Statistics) {
 	content += fmt.Sprintf("  [cyan]Policies:[-]       %d\n", stats.PolicyCount)
 	content += fmt.Sprintf("  [cyan]Active Sessions:[-] %d\n", stats.SessionCount)
 
+	content += "\n[yellow::b]Rate Limited Requests (Auth Server)[-::-]\n\n"
+	content += fmt.Sprintf("  [cyan]By RADIUS Client:[-] %d\n", stats.RateLimitedClient)
+	content += fmt.Sprintf("  [cyan]By Station:[-]       %d\n", stats.RateLimitedStation)
+	content += fmt.Sprintf("  [cyan]By IMSI:[-]          %d\n", stats.RateLimitedIMSI)
+
 	content += "\n"
 	content += "[gray]Last updated: " + format.DateTime(stats.UpdatedAt) + "[-]\n"
 	content += "[gray](Statistics are cached for 1 minute. Press 'r' to force refresh)[-]\n"
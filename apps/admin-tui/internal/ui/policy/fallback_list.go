@@ -0,0 +1,441 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui"
+	"github.com/rivo/tview"
+)
+
+// フォールバックポリシーの段階（Auth Serverの解決順）
+const (
+	fallbackLevelPrefix = "prefix"
+	fallbackLevelClient = "client"
+	fallbackLevelGlobal = "global"
+)
+
+// fallbackEntry はフォールバックポリシー一覧の1行を表す。
+type fallbackEntry struct {
+	level   string        // 段階（prefix・client・global）
+	target  string        // プレフィックス・クライアントIP（globalは空）
+	label   string        // 表示用の対象名
+	profile string        // 参照するポリシープロファイル名
+	policy  *model.Policy // clientの場合はnil
+}
+
+// FallbackListScreen は加入者ポリシーがない場合に適用するフォールバックポリシーの一覧画面を表す。
+// PLMN・IMSIプレフィックスのポリシー、NASクライアントの既定プロファイル、全体の既定ポリシーを解決順に表示する。
+type FallbackListScreen struct {
+	table         *tview.Table
+	app           *ui.App
+	fallbackStore *store.FallbackPolicyStore
+	clientStore   *store.ClientStore
+	entries       []fallbackEntry
+	filter        *ui.Filter
+	pagination    *ui.Pagination
+	onCreate      func()
+	onEditPrefix  func(prefix string)
+	onEditGlobal  func()
+	onDelete      func(prefix string)
+	onBack        func()
+}
+
+// NewFallbackListScreen は新しいFallbackListScreenを生成する。
+func NewFallbackListScreen(app *ui.App, fallbackStore *store.FallbackPolicyStore, clientStore *store.ClientStore) *FallbackListScreen {
+	table := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	table.SetTitle(" Fallback Policy List ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorBlue)
+
+	screen := &FallbackListScreen{
+		table:         table,
+		app:           app,
+		fallbackStore: fallbackStore,
+		clientStore:   clientStore,
+		filter:        ui.NewFilter("Target"),
+		pagination:    ui.NewPagination(ui.DefaultPageSize),
+	}
+
+	screen.setupKeyBindings()
+	return screen
+}
+
+// SetOnCreate はプレフィックスのポリシーの新規作成時のコールバックを設定する。
+func (s *FallbackListScreen) SetOnCreate(handler func()) {
+	s.onCreate = handler
+}
+
+// SetOnEditPrefix はプレフィックスのポリシーの編集時のコールバックを設定する。
+func (s *FallbackListScreen) SetOnEditPrefix(handler func(prefix string)) {
+	s.onEditPrefix = handler
+}
+
+// SetOnEditGlobal は全体の既定ポリシーの編集時のコールバックを設定する。
+func (s *FallbackListScreen) SetOnEditGlobal(handler func()) {
+	s.onEditGlobal = handler
+}
+
+// SetOnDelete は削除時のコールバックを設定する（全体の既定ポリシーの場合はprefixが空）。
+func (s *FallbackListScreen) SetOnDelete(handler func(prefix string)) {
+	s.onDelete = handler
+}
+
+// SetOnBack は戻る時のコールバックを設定する。
+func (s *FallbackListScreen) SetOnBack(handler func()) {
+	s.onBack = handler
+}
+
+// GetTable は内部のtview.Tableを返す。
+func (s *FallbackListScreen) GetTable() *tview.Table {
+	return s.table
+}
+
+// Load はデータを読み込む。
+func (s *FallbackListScreen) Load(ctx context.Context) error {
+	var entries []fallbackEntry
+
+	prefixes, err := s.fallbackStore.ListPrefixes(ctx)
+	if err != nil {
+		return err
+	}
+	// 長いプレフィックスほど優先して適用されるため、長い順に並べる
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return len(prefixes[i].IMSI) > len(prefixes[j].IMSI)
+	})
+	for _, p := range prefixes {
+		entries = append(entries, fallbackEntry{
+			level:   fallbackLevelPrefix,
+			target:  p.IMSI,
+			label:   p.IMSI,
+			profile: p.Profile,
+			policy:  p,
+		})
+	}
+
+	clients, err := s.clientStore.List(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].IP < clients[j].IP
+	})
+	for _, c := range clients {
+		if c.PolicyProfile == "" {
+			continue
+		}
+		label := c.IP
+		if c.Name != "" {
+			label += " (" + c.Name + ")"
+		}
+		entries = append(entries, fallbackEntry{
+			level:   fallbackLevelClient,
+			target:  c.IP,
+			label:   label,
+			profile: c.PolicyProfile,
+		})
+	}
+
+	global, err := s.fallbackStore.GetGlobal(ctx)
+	if err != nil && !errors.Is(err, store.ErrPolicyNotFound) {
+		return err
+	}
+	if global != nil {
+		entries = append(entries, fallbackEntry{
+			level:   fallbackLevelGlobal,
+			label:   "*",
+			profile: global.Profile,
+			policy:  global,
+		})
+	}
+
+	s.entries = entries
+	s.render()
+	return nil
+}
+
+// Refresh はデータを再読み込みする。
+func (s *FallbackListScreen) Refresh(ctx context.Context) error {
+	return s.Load(ctx)
+}
+
+// SetFilter はフィルタを設定する。
+func (s *FallbackListScreen) SetFilter(query string) {
+	s.filter.SetQuery(query)
+	s.pagination.FirstPage()
+	s.render()
+}
+
+// ClearFilter はフィルタをクリアする。
+func (s *FallbackListScreen) ClearFilter() {
+	s.filter.Clear()
+	s.pagination.FirstPage()
+	s.render()
+}
+
+// getSelectedEntry は選択されている行を返す。
+func (s *FallbackListScreen) getSelectedEntry() (fallbackEntry, bool) {
+	row, _ := s.table.GetSelection()
+	filtered := s.getFilteredEntries()
+	if row < 1 || row > len(filtered) {
+		return fallbackEntry{}, false
+	}
+
+	pageItems := ui.GetPageItems(filtered, s.pagination)
+	idx := row - 1
+	if idx < 0 || idx >= len(pageItems) {
+		return fallbackEntry{}, false
+	}
+	return pageItems[idx], true
+}
+
+func (s *FallbackListScreen) getFilteredEntries() []fallbackEntry {
+	return ui.FilterItems(s.entries, s.filter, func(entry fallbackEntry) []string {
+		return []string{entry.label, entry.profile}
+	})
+}
+
+func (s *FallbackListScreen) render() {
+	s.table.Clear()
+
+	// ヘッダー
+	headers := []string{"Level", "Target", "Profile", "Default", "Rules", "Max Sessions"}
+	for col, header := range headers {
+		cell := tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetExpansion(1)
+		s.table.SetCell(0, col, cell)
+	}
+
+	// フィルタ適用
+	filtered := s.getFilteredEntries()
+	pageItems := ui.GetPageItems(filtered, s.pagination)
+
+	// データ行
+	for i, entry := range pageItems {
+		row := i + 1
+
+		s.table.SetCell(row, 0, tview.NewTableCell(entry.level).
+			SetTextColor(tcell.ColorTeal).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 1, tview.NewTableCell(entry.label).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(2))
+
+		profile := entry.profile
+		if profile == "" {
+			profile = noProfileOption
+		}
+		s.table.SetCell(row, 2, tview.NewTableCell(profile).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// NASクライアントはプロファイルの設定をそのまま適用する
+		defaultText, defaultColor := noProfileOption, tcell.ColorGray
+		rulesText, maxSessionsText := noProfileOption, noProfileOption
+		if entry.policy != nil {
+			defaultText, defaultColor = entry.policy.Default, tcell.ColorGreen
+			switch entry.policy.Default {
+			case "allow":
+				defaultColor = tcell.ColorYellow
+			case "":
+				defaultText, defaultColor = defaultInheritOption, tcell.ColorGray
+			}
+			rulesText = formatRulesCount(len(entry.policy.Rules))
+			maxSessionsText = strconv.Itoa(entry.policy.MaxSessions)
+		}
+		s.table.SetCell(row, 3, tview.NewTableCell(defaultText).
+			SetTextColor(defaultColor).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 4, tview.NewTableCell(rulesText).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 5, tview.NewTableCell(maxSessionsText).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+	}
+
+	// タイトル更新
+	title := " Fallback Policy List "
+	if s.filter.Active {
+		title += "[yellow](" + s.filter.FormatFilterStatus() + ")[-] "
+	}
+	title += "[gray]" + s.pagination.FormatPageInfo() + "[-] "
+	s.table.SetTitle(title)
+
+	// 選択を先頭に
+	if len(pageItems) > 0 {
+		s.table.SetSelectable(true, false)
+		s.table.Select(1, 0)
+	} else {
+		s.table.SetSelectable(false, false)
+		emptyCell := tview.NewTableCell("(No data)").
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false)
+		s.table.SetCell(1, 0, emptyCell)
+	}
+}
+
+func (s *FallbackListScreen) refresh() {
+	go func() {
+		s.app.QueueUpdateDraw(func() {
+			if err := s.Refresh(context.Background()); err != nil {
+				s.app.GetStatusBar().ShowError("Failed to refresh: " + err.Error())
+			} else {
+				s.app.GetStatusBar().ShowSuccess("Refreshed")
+			}
+		})
+	}()
+}
+
+// editSelected は選択されている行の編集画面を開く。
+// NASクライアントの既定プロファイルはRADIUSクライアント管理で変更する。
+func (s *FallbackListScreen) editSelected() {
+	entry, ok := s.getSelectedEntry()
+	if !ok {
+		return
+	}
+	switch entry.level {
+	case fallbackLevelPrefix:
+		if s.onEditPrefix != nil {
+			s.onEditPrefix(entry.target)
+		}
+	case fallbackLevelGlobal:
+		if s.onEditGlobal != nil {
+			s.onEditGlobal()
+		}
+	case fallbackLevelClient:
+		s.app.GetStatusBar().ShowInfo("Change the default profile in RADIUS Client Management")
+	}
+}
+
+// deleteSelected は選択されている行を削除する。
+func (s *FallbackListScreen) deleteSelected() {
+	entry, ok := s.getSelectedEntry()
+	if !ok {
+		return
+	}
+	if entry.level == fallbackLevelClient {
+		s.app.GetStatusBar().ShowInfo("Change the default profile in RADIUS Client Management")
+		return
+	}
+	if s.onDelete != nil {
+		s.onDelete(entry.target)
+	}
+}
+
+func (s *FallbackListScreen) setupKeyBindings() {
+	s.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			if s.filter.Active {
+				s.ClearFilter()
+				return nil
+			}
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		case tcell.KeyF2:
+			if s.onCreate != nil {
+				s.onCreate()
+			}
+			return nil
+		case tcell.KeyF3, tcell.KeyEnter:
+			s.editSelected()
+			return nil
+		case tcell.KeyF4:
+			s.deleteSelected()
+			return nil
+		case tcell.KeyF5:
+			s.refresh()
+			return nil
+		case tcell.KeyPgUp:
+			if s.pagination.PrevPage() {
+				s.render()
+			}
+			return nil
+		case tcell.KeyPgDn:
+			if s.pagination.NextPage() {
+				s.render()
+			}
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'n':
+			if s.onCreate != nil {
+				s.onCreate()
+			}
+			return nil
+		case 'g':
+			if s.onEditGlobal != nil {
+				s.onEditGlobal()
+			}
+			return nil
+		case 'e':
+			s.editSelected()
+			return nil
+		case 'd':
+			s.deleteSelected()
+			return nil
+		case 'r':
+			s.refresh()
+			return nil
+		case '/':
+			s.showFilterDialog()
+			return nil
+		case 'q':
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (s *FallbackListScreen) showFilterDialog() {
+	dialog := ui.NewInputDialog(
+		"Filter Fallback Policies",
+		"Target or profile contains:",
+		s.filter.Query,
+		func(value string) {
+			s.SetFilter(value)
+			s.app.HidePage("filter-dialog")
+			s.app.RemovePage("filter-dialog")
+			s.app.SetFocus(s.table)
+		},
+		func() {
+			s.app.HidePage("filter-dialog")
+			s.app.RemovePage("filter-dialog")
+			s.app.SetFocus(s.table)
+		},
+	)
+
+	s.app.AddPage("filter-dialog", centered(dialog.GetForm(), 50, 7), true, true)
+	s.app.SetFocus(dialog.GetForm())
+}
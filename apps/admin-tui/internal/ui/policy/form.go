@@ -2,7 +2,9 @@ package policy
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,23 +17,46 @@ import (
 	"github.com/rivo/tview"
 )
 
+// defaultActionOptions はデフォルトアクションのドロップダウン選択肢（末尾のinheritはプロファイル参照時のみ有効）
+var defaultActionOptions = []string{"deny", "allow", defaultInheritOption}
+
+const (
+	// defaultInheritOption はプロファイルのデフォルトアクションに従う選択肢
+	defaultInheritOption = "inherit"
+	// noProfileOption はプロファイルを参照しない選択肢
+	noProfileOption = "-"
+)
+
+// formScope はポリシーフォームの編集対象を表す。
+type formScope int
+
+const (
+	scopeSubscriber formScope = iota // 加入者ポリシー（policy:{IMSI}）
+	scopePrefix                      // PLMN・IMSIプレフィックスのポリシー（policy-prefix:{prefix}）
+	scopeGlobal                      // 全体の既定ポリシー（policy-default）
+)
+
 // FormScreen はポリシー登録/編集画面を表す。
 type FormScreen struct {
-	flex         *tview.Flex
-	form         *tview.Form
-	rulesList    *tview.List
-	app          *ui.App
-	policyStore  *store.PolicyStore
-	auditLogger  *audit.Logger
-	editMode     bool
-	originalIMSI string
-	policy       *model.Policy
-	onSave       func()
-	onCancel     func()
+	flex          *tview.Flex
+	form          *tview.Form
+	rulesList     *tview.List
+	ruleEditor    *ruleEditor
+	app           *ui.App
+	policyStore   *store.PolicyStore
+	fallbackStore *store.FallbackPolicyStore
+	profileStore  *store.ProfileStore
+	auditLogger   *audit.Logger
+	scope         formScope
+	editMode      bool
+	originalIMSI  string
+	policy        *model.Policy
+	onSave        func()
+	onCancel      func()
 }
 
 // NewFormScreen は新しいFormScreenを生成する。
-func NewFormScreen(app *ui.App, policyStore *store.PolicyStore, auditLogger *audit.Logger) *FormScreen {
+func NewFormScreen(app *ui.App, policyStore *store.PolicyStore, profileStore *store.ProfileStore, auditLogger *audit.Logger) *FormScreen {
 	form := tview.NewForm()
 	form.SetBorder(true).
 		SetTitle(" Policy Details ").
@@ -45,22 +70,33 @@ func NewFormScreen(app *ui.App, policyStore *store.PolicyStore, auditLogger *aud
 
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(form, 10, 0, true).
+		AddItem(form, 12, 0, true).
 		AddItem(rulesList, 0, 1, false)
 
 	screen := &FormScreen{
-		flex:        flex,
-		form:        form,
-		rulesList:   rulesList,
-		app:         app,
-		policyStore: policyStore,
-		auditLogger: auditLogger,
-		editMode:    false,
+		flex:         flex,
+		form:         form,
+		rulesList:    rulesList,
+		app:          app,
+		policyStore:  policyStore,
+		profileStore: profileStore,
+		auditLogger:  auditLogger,
+		editMode:     false,
 	}
+	screen.ruleEditor = newRuleEditor(app, rulesList, func() {
+		app.SetFocus(form)
+	})
 
 	return screen
 }
 
+// NewFallbackFormScreen はフォールバックポリシー（プレフィックスのポリシー・全体の既定ポリシー）の登録/編集画面を生成する。
+func NewFallbackFormScreen(app *ui.App, fallbackStore *store.FallbackPolicyStore, profileStore *store.ProfileStore, auditLogger *audit.Logger) *FormScreen {
+	screen := NewFormScreen(app, nil, profileStore, auditLogger)
+	screen.fallbackStore = fallbackStore
+	return screen
+}
+
 // SetOnSave は保存時のコールバックを設定する。
 func (s *FormScreen) SetOnSave(handler func()) {
 	s.onSave = handler
@@ -78,6 +114,7 @@ func (s *FormScreen) GetFlex() *tview.Flex {
 
 // SetupCreate は新規作成モードでフォームをセットアップする。
 func (s *FormScreen) SetupCreate() {
+	s.scope = scopeSubscriber
 	s.editMode = false
 	s.originalIMSI = ""
 	s.policy = model.NewPolicy("", "deny")
@@ -92,190 +129,192 @@ func (s *FormScreen) SetupEdit(ctx context.Context, imsi string) error {
 		return err
 	}
 
+	s.scope = scopeSubscriber
 	s.editMode = true
 	s.originalIMSI = imsi
 	s.policy = policy.Clone()
+	s.policy.SortRules()
 
 	s.setupForm()
 	return nil
 }
 
-func (s *FormScreen) setupForm() {
-	s.form.Clear(true)
-
-	if s.editMode {
-		s.flex.SetTitle(" Edit Policy ")
-	} else {
-		s.flex.SetTitle(" Create Policy ")
-	}
+// SetupCreatePrefix はプレフィックスのポリシーの新規作成モードでフォームをセットアップする。
+func (s *FormScreen) SetupCreatePrefix() {
+	s.scope = scopePrefix
+	s.editMode = false
+	s.originalIMSI = ""
+	s.policy = model.NewPolicy("", "deny")
 
-	// IMSI
-	s.form.AddInputField("IMSI", s.policy.IMSI, 20, nil, nil)
-	if s.editMode {
-		imsiField := s.form.GetFormItemByLabel("IMSI").(*tview.InputField)
-		imsiField.SetDisabled(true)
-	}
+	s.setupForm()
+}
 
-	// Default action
-	defaultOptions := []string{"deny", "allow"}
-	defaultIndex := 0
-	if s.policy.Default == "allow" {
-		defaultIndex = 1
+// SetupEditPrefix はプレフィックスのポリシーの編集モードでフォームをセットアップする。
+func (s *FormScreen) SetupEditPrefix(ctx context.Context, prefix string) error {
+	policy, err := s.fallbackStore.GetPrefix(ctx, prefix)
+	if err != nil {
+		return err
 	}
-	s.form.AddDropDown("Default Action", defaultOptions, defaultIndex, nil)
 
-	// Buttons
-	s.form.AddButton("Add Rule", s.showAddRuleDialog)
-	s.form.AddButton("Save", s.handleSave)
-	s.form.AddButton("Cancel", s.handleCancel)
+	s.scope = scopePrefix
+	s.editMode = true
+	s.originalIMSI = prefix
+	s.policy = policy.Clone()
+	s.policy.SortRules()
 
-	s.updateRulesList()
-	s.setupKeyBindings()
+	s.setupForm()
+	return nil
 }
 
-func (s *FormScreen) updateRulesList() {
-	s.rulesList.Clear()
-
-	if len(s.policy.Rules) == 0 {
-		s.rulesList.AddItem("(No rules defined)", "", 0, nil)
-		return
+// SetupGlobal は全体の既定ポリシーのフォームをセットアップする（未設定の場合は新規作成）。
+func (s *FormScreen) SetupGlobal(ctx context.Context) error {
+	policy, err := s.fallbackStore.GetGlobal(ctx)
+	switch {
+	case errors.Is(err, store.ErrPolicyNotFound):
+		s.editMode = false
+		s.policy = model.NewPolicy("", "deny")
+	case err != nil:
+		return err
+	default:
+		s.editMode = true
+		s.policy = policy.Clone()
+		s.policy.SortRules()
 	}
 
-	for i, rule := range s.policy.Rules {
-		idx := i
-		mainText := fmt.Sprintf("[%d] NAS: %s", i+1, rule.NasID)
-		secondText := fmt.Sprintf("SSIDs: %s", strings.Join(rule.AllowedSSIDs, ", "))
-		if rule.VlanID != "" {
-			secondText += fmt.Sprintf(" | VLAN: %s", rule.VlanID)
-		}
-		if rule.SessionTimeout > 0 {
-			secondText += fmt.Sprintf(" | Timeout: %ds", rule.SessionTimeout)
-		}
-
-		s.rulesList.AddItem(mainText, secondText, 0, func() {
-			s.showEditRuleDialog(idx)
-		})
-	}
+	s.scope = scopeGlobal
+	s.originalIMSI = ""
+	s.setupForm()
+	return nil
 }
 
-func (s *FormScreen) showAddRuleDialog() {
-	s.showRuleDialog(-1, &model.PolicyRule{
-		NasID:        "example.com",
-		AllowedSSIDs: []string{},
-	})
-}
+func (s *FormScreen) setupForm() {
+	s.form.Clear(true)
 
-func (s *FormScreen) showEditRuleDialog(index int) {
-	if index < 0 || index >= len(s.policy.Rules) {
-		return
+	s.flex.SetTitle(s.formTitle())
+
+	// IMSI（プレフィックスのポリシーではプレフィックス、全体の既定ポリシーでは入力なし）
+	if label := s.keyLabel(); label != "" {
+		s.form.AddInputField(label, s.policy.IMSI, 20, nil, nil)
+		if s.editMode {
+			keyField := s.form.GetFormItemByLabel(label).(*tview.InputField)
+			keyField.SetDisabled(true)
+		}
 	}
-	rule := s.policy.Rules[index]
-	s.showRuleDialog(index, &rule)
-}
 
-func (s *FormScreen) showRuleDialog(index int, rule *model.PolicyRule) {
-	ruleForm := tview.NewForm()
+	// 参照するポリシープロファイル（"-"は参照なし）
+	profileOptions := s.profileOptions()
+	s.form.AddDropDown("Profile", profileOptions, optionIndex(profileOptions, s.policy.Profile), nil)
 
-	title := " Add Rule "
-	if index >= 0 {
-		title = " Edit Rule "
+	// Default action（inheritはプロファイルのデフォルトアクションに従う）
+	defaultIndex := optionIndex(defaultActionOptions, s.policy.Default)
+	if s.policy.Default == "" && s.policy.Profile != "" {
+		defaultIndex = len(defaultActionOptions) - 1
 	}
+	s.form.AddDropDown("Default Action", defaultActionOptions, defaultIndex, nil)
 
-	ruleForm.SetTitle(title).
-		SetBorder(true).
-		SetBorderColor(tcell.ColorTeal)
-
-	ruleForm.AddInputField("NAS ID", rule.NasID, 40, nil, nil)
-	ruleForm.AddInputField("Allowed SSIDs", strings.Join(rule.AllowedSSIDs, ","), 40, nil, nil)
-	ruleForm.AddInputField("VLAN ID", rule.VlanID, 10, nil, nil)
-	ruleForm.AddInputField("Session Timeout", strconv.Itoa(rule.SessionTimeout), 10, nil, nil)
-
-	ruleForm.AddButton("OK", func() {
-		nasID := ruleForm.GetFormItemByLabel("NAS ID").(*tview.InputField).GetText()
-		ssidsStr := ruleForm.GetFormItemByLabel("Allowed SSIDs").(*tview.InputField).GetText()
-		vlanStr := ruleForm.GetFormItemByLabel("VLAN ID").(*tview.InputField).GetText()
-		timeoutStr := ruleForm.GetFormItemByLabel("Session Timeout").(*tview.InputField).GetText()
-
-		// Parse SSIDs
-		var ssids []string
-		for _, ssid := range strings.Split(ssidsStr, ",") {
-			ssid = strings.TrimSpace(ssid)
-			if ssid != "" {
-				ssids = append(ssids, ssid)
-			}
-		}
+	// EAP-AKA'必須
+	s.form.AddCheckbox("Require AKA'", s.policy.RequireAKAPrime, nil)
 
-		// Parse VLAN ID (string型として保持)
-		vlanID := strings.TrimSpace(vlanStr)
+	// 同時セッション数上限（0はAuth Serverの全体設定に従う）
+	s.form.AddInputField("Max Sessions", strconv.Itoa(s.policy.MaxSessions), 10, nil, nil)
 
-		// Parse Session Timeout
-		sessionTimeout := 0
-		if timeoutStr != "" {
-			if t, err := strconv.Atoi(timeoutStr); err == nil {
-				sessionTimeout = t
-			}
-		}
-
-		newRule := model.PolicyRule{
-			NasID:          strings.TrimSpace(nasID),
-			AllowedSSIDs:   ssids,
-			VlanID:         vlanID,
-			SessionTimeout: sessionTimeout,
-		}
+	// Buttons
+	s.form.AddButton("Add Rule", s.ruleEditor.showAdd)
+	s.form.AddButton("Save", s.handleSave)
+	s.form.AddButton("Cancel", s.handleCancel)
 
-		// Validate
-		if errs := validation.ValidatePolicyRule(&newRule); len(errs) > 0 {
-			s.app.GetStatusBar().ShowError("Validation error: " + errs[0].Error())
-			return
-		}
+	s.ruleEditor.rules = &s.policy.Rules
+	s.ruleEditor.update()
+	s.setupKeyBindings()
+}
 
-		if index >= 0 {
-			s.policy.Rules[index] = newRule
-		} else {
-			s.policy.Rules = append(s.policy.Rules, newRule)
+// formTitle は編集対象に応じたフォームのタイトルを返す。
+func (s *FormScreen) formTitle() string {
+	switch s.scope {
+	case scopePrefix:
+		if s.editMode {
+			return " Edit Prefix Policy "
 		}
-
-		s.app.HidePage("rule-dialog")
-		s.app.RemovePage("rule-dialog")
-		s.updateRulesList()
-		s.app.SetFocus(s.form)
-	})
-
-	if index >= 0 {
-		ruleForm.AddButton("Delete", func() {
-			s.policy.Rules = append(s.policy.Rules[:index], s.policy.Rules[index+1:]...)
-			s.app.HidePage("rule-dialog")
-			s.app.RemovePage("rule-dialog")
-			s.updateRulesList()
-			s.app.SetFocus(s.form)
-		})
+		return " Create Prefix Policy "
+	case scopeGlobal:
+		return " Global Default Policy "
 	}
+	if s.editMode {
+		return " Edit Policy "
+	}
+	return " Create Policy "
+}
 
-	ruleForm.AddButton("Cancel", func() {
-		s.app.HidePage("rule-dialog")
-		s.app.RemovePage("rule-dialog")
-		s.app.SetFocus(s.form)
-	})
+// keyLabel は編集対象のキー入力欄のラベルを返す（全体の既定ポリシーは空）。
+func (s *FormScreen) keyLabel() string {
+	switch s.scope {
+	case scopePrefix:
+		return "IMSI Prefix"
+	case scopeGlobal:
+		return ""
+	}
+	return "IMSI"
+}
 
-	s.app.AddPage("rule-dialog", centered(ruleForm, 60, 15), true, true)
-	s.app.SetFocus(ruleForm)
+// profileOptions はプロファイルのドロップダウン選択肢（先頭は参照なし）を返す。
+// 一覧の取得に失敗した場合も現在の参照先は選択肢に含める。
+func (s *FormScreen) profileOptions() []string {
+	options := []string{noProfileOption}
+	profiles, err := s.profileStore.List(context.Background())
+	if err != nil {
+		s.app.GetStatusBar().ShowError("Failed to load profiles: " + err.Error())
+	}
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	if s.policy.Profile != "" && !slices.Contains(names, s.policy.Profile) {
+		names = append(names, s.policy.Profile)
+	}
+	sort.Strings(names)
+	return append(options, names...)
 }
 
 func (s *FormScreen) handleSave() {
 	// フォームからデータを取得
-	imsi := s.form.GetFormItemByLabel("IMSI").(*tview.InputField).GetText()
+	imsi := ""
+	if label := s.keyLabel(); label != "" {
+		imsi = s.form.GetFormItemByLabel(label).(*tview.InputField).GetText()
+	}
 	_, defaultAction := s.form.GetFormItemByLabel("Default Action").(*tview.DropDown).GetCurrentOption()
+	_, profile := s.form.GetFormItemByLabel("Profile").(*tview.DropDown).GetCurrentOption()
+	if defaultAction == defaultInheritOption {
+		defaultAction = ""
+	}
+	if profile == noProfileOption {
+		profile = ""
+	}
 
 	s.policy.IMSI = strings.TrimSpace(imsi)
 	s.policy.Default = defaultAction
+	s.policy.Profile = profile
+	s.policy.RequireAKAPrime = s.form.GetFormItemByLabel("Require AKA'").(*tview.Checkbox).IsChecked()
+
+	maxSessionsStr := strings.TrimSpace(s.form.GetFormItemByLabel("Max Sessions").(*tview.InputField).GetText())
+	s.policy.MaxSessions = 0
+	if maxSessionsStr != "" {
+		n, err := strconv.Atoi(maxSessionsStr)
+		if err != nil {
+			s.app.GetStatusBar().ShowError("Validation error: Max Sessions must be a number")
+			return
+		}
+		s.policy.MaxSessions = n
+	}
 
 	// バリデーション
 	input := &validation.PolicyInput{
-		IMSI:    s.policy.IMSI,
-		Default: s.policy.Default,
-		Rules:   s.policy.Rules,
+		IMSI:        s.policy.IMSI,
+		Default:     s.policy.Default,
+		Rules:       s.policy.Rules,
+		MaxSessions: s.policy.MaxSessions,
+		Profile:     s.policy.Profile,
 	}
-	if errs := validation.ValidatePolicy(input); len(errs) > 0 {
+	if errs := s.validate(input); len(errs) > 0 {
 		s.app.GetStatusBar().ShowError("Validation error: " + errs[0].Error())
 		return
 	}
@@ -289,6 +328,17 @@ func (s *FormScreen) handleSave() {
 	s.save()
 }
 
+// validate は編集対象に応じたポリシーのバリデーションを行う。
+func (s *FormScreen) validate(input *validation.PolicyInput) []error {
+	switch s.scope {
+	case scopePrefix:
+		return validation.ValidatePrefixPolicy(input)
+	case scopeGlobal:
+		return validation.ValidateGlobalPolicy(input)
+	}
+	return validation.ValidatePolicy(input)
+}
+
 func (s *FormScreen) showAllowWarningDialog() {
 	dialog := ui.NewWarningDialog(
 		"Default Allow Warning",
@@ -311,11 +361,40 @@ func (s *FormScreen) showAllowWarningDialog() {
 func (s *FormScreen) save() {
 	ctx := context.Background()
 
+	switch s.scope {
+	case scopePrefix:
+		if !s.savePrefix(ctx) {
+			return
+		}
+	case scopeGlobal:
+		if err := s.fallbackStore.SaveGlobal(ctx, s.policy); err != nil {
+			s.app.GetStatusBar().ShowError("Failed to save: " + err.Error())
+			return
+		}
+		if s.editMode {
+			s.auditLogger.LogUpdate(audit.TargetPolicy, store.KeyDefaultPolicy, "")
+		} else {
+			s.auditLogger.LogCreate(audit.TargetPolicy, store.KeyDefaultPolicy, "")
+		}
+		s.app.GetStatusBar().ShowSuccess("Global default policy saved")
+	default:
+		if !s.saveSubscriber(ctx) {
+			return
+		}
+	}
+
+	if s.onSave != nil {
+		s.onSave()
+	}
+}
+
+// saveSubscriber は加入者ポリシーを保存する（失敗した場合はfalseを返す）。
+func (s *FormScreen) saveSubscriber(ctx context.Context) bool {
 	if s.editMode {
 		// 更新
 		if err := s.policyStore.Update(ctx, s.policy); err != nil {
 			s.app.GetStatusBar().ShowError("Failed to update: " + err.Error())
-			return
+			return false
 		}
 		s.auditLogger.LogUpdate(audit.TargetPolicy, store.PolicyKey(s.policy.IMSI), s.policy.IMSI)
 		s.app.GetStatusBar().ShowSuccess("Policy updated: " + s.policy.IMSI)
@@ -323,15 +402,33 @@ func (s *FormScreen) save() {
 		// 新規作成
 		if err := s.policyStore.Create(ctx, s.policy); err != nil {
 			s.app.GetStatusBar().ShowError("Failed to create: " + err.Error())
-			return
+			return false
 		}
 		s.auditLogger.LogCreate(audit.TargetPolicy, store.PolicyKey(s.policy.IMSI), s.policy.IMSI)
 		s.app.GetStatusBar().ShowSuccess("Policy created: " + s.policy.IMSI)
 	}
+	return true
+}
 
-	if s.onSave != nil {
-		s.onSave()
+// savePrefix はプレフィックスのポリシーを保存する（失敗した場合はfalseを返す）。
+func (s *FormScreen) savePrefix(ctx context.Context) bool {
+	key := store.PrefixPolicyKey(s.policy.IMSI)
+	if s.editMode {
+		if err := s.fallbackStore.UpdatePrefix(ctx, s.policy); err != nil {
+			s.app.GetStatusBar().ShowError("Failed to update: " + err.Error())
+			return false
+		}
+		s.auditLogger.LogUpdate(audit.TargetPolicy, key, "")
+		s.app.GetStatusBar().ShowSuccess("Prefix policy updated: " + s.policy.IMSI)
+	} else {
+		if err := s.fallbackStore.CreatePrefix(ctx, s.policy); err != nil {
+			s.app.GetStatusBar().ShowError("Failed to create: " + err.Error())
+			return false
+		}
+		s.auditLogger.LogCreate(audit.TargetPolicy, key, "")
+		s.app.GetStatusBar().ShowSuccess("Prefix policy created: " + s.policy.IMSI)
 	}
+	return true
 }
 
 func (s *FormScreen) handleCancel() {
@@ -358,7 +455,8 @@ func (s *FormScreen) setupKeyBindings() {
 			s.app.SetFocus(s.form)
 			return nil
 		}
-		return event
+		// Shift+↑/↓ で選択中のルールの評価順を入れ替える
+		return s.ruleEditor.handleListKey(event)
 	})
 }
 
@@ -143,7 +143,7 @@ func (s *ListScreen) render() {
 	s.table.Clear()
 
 	// ヘッダー
-	headers := []string{"IMSI", "Default", "Rules"}
+	headers := []string{"IMSI", "Default", "Profile", "Rules"}
 	for col, header := range headers {
 		cell := tview.NewTableCell(header).
 			SetTextColor(tcell.ColorYellow).
@@ -167,28 +167,32 @@ func (s *ListScreen) render() {
 			SetAlign(tview.AlignLeft).
 			SetExpansion(1))
 
-		// Default action
+		// Default action（空はプロファイルに従う）
+		defaultDisplay := policy.Default
 		defaultColor := tcell.ColorGreen
 		if policy.Default == "allow" {
 			defaultColor = tcell.ColorYellow
+		} else if policy.Default == "" {
+			defaultDisplay = defaultInheritOption
+			defaultColor = tcell.ColorGray
 		}
-		s.table.SetCell(row, 1, tview.NewTableCell(policy.Default).
+		s.table.SetCell(row, 1, tview.NewTableCell(defaultDisplay).
 			SetTextColor(defaultColor).
 			SetAlign(tview.AlignLeft).
 			SetExpansion(1))
 
-		// Rules count
-		rulesCount := len(policy.Rules)
-		rulesDisplay := "No rules"
-		if rulesCount == 1 {
-			rulesDisplay = "1 rule"
-		} else if rulesCount > 1 {
-			rulesDisplay = string(rune('0'+rulesCount)) + " rules"
-			if rulesCount >= 10 {
-				rulesDisplay = "10+ rules"
-			}
+		// Profile
+		profileDisplay := policy.Profile
+		if profileDisplay == "" {
+			profileDisplay = noProfileOption
 		}
-		s.table.SetCell(row, 2, tview.NewTableCell(rulesDisplay).
+		s.table.SetCell(row, 2, tview.NewTableCell(profileDisplay).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Rules count
+		s.table.SetCell(row, 3, tview.NewTableCell(formatRulesCount(len(policy.Rules))).
 			SetTextColor(tcell.ColorGray).
 			SetAlign(tview.AlignLeft).
 			SetExpansion(1))
@@ -334,4 +338,18 @@ func (s *ListScreen) showFilterDialog() {
 	s.app.SetFocus(dialog.GetForm())
 }
 
+// formatRulesCount は一覧に表示するルール数の文字列を返す。
+func formatRulesCount(rulesCount int) string {
+	rulesDisplay := "No rules"
+	if rulesCount == 1 {
+		rulesDisplay = "1 rule"
+	} else if rulesCount > 1 {
+		rulesDisplay = string(rune('0'+rulesCount)) + " rules"
+		if rulesCount >= 10 {
+			rulesDisplay = "10+ rules"
+		}
+	}
+	return rulesDisplay
+}
+
 // centered is defined in form.go
@@ -0,0 +1,271 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/audit"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/validation"
+	"github.com/rivo/tview"
+)
+
+// ProfileFormScreen はポリシープロファイル登録/編集画面を表す。
+type ProfileFormScreen struct {
+	flex         *tview.Flex
+	form         *tview.Form
+	rulesList    *tview.List
+	ruleEditor   *ruleEditor
+	app          *ui.App
+	profileStore *store.ProfileStore
+	auditLogger  *audit.Logger
+	editMode     bool
+	profile      *model.Profile
+	onSave       func()
+	onCancel     func()
+}
+
+// NewProfileFormScreen は新しいProfileFormScreenを生成する。
+func NewProfileFormScreen(app *ui.App, profileStore *store.ProfileStore, auditLogger *audit.Logger) *ProfileFormScreen {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(" Profile Details ").
+		SetBorderColor(tcell.ColorBlue)
+
+	rulesList := tview.NewList().
+		ShowSecondaryText(true)
+	rulesList.SetBorder(true).
+		SetTitle(" Rules ").
+		SetBorderColor(tcell.ColorBlue)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 16, 0, true).
+		AddItem(rulesList, 0, 1, false)
+
+	screen := &ProfileFormScreen{
+		flex:         flex,
+		form:         form,
+		rulesList:    rulesList,
+		app:          app,
+		profileStore: profileStore,
+		auditLogger:  auditLogger,
+		editMode:     false,
+	}
+	screen.ruleEditor = newRuleEditor(app, rulesList, func() {
+		app.SetFocus(form)
+	})
+
+	return screen
+}
+
+// SetOnSave は保存時のコールバックを設定する。
+func (s *ProfileFormScreen) SetOnSave(handler func()) {
+	s.onSave = handler
+}
+
+// SetOnCancel はキャンセル時のコールバックを設定する。
+func (s *ProfileFormScreen) SetOnCancel(handler func()) {
+	s.onCancel = handler
+}
+
+// GetFlex は内部のtview.Flexを返す。
+func (s *ProfileFormScreen) GetFlex() *tview.Flex {
+	return s.flex
+}
+
+// SetupCreate は新規作成モードでフォームをセットアップする。
+func (s *ProfileFormScreen) SetupCreate() {
+	s.editMode = false
+	s.profile = model.NewProfile("", "deny")
+
+	s.setupForm()
+}
+
+// SetupEdit は編集モードでフォームをセットアップする。
+func (s *ProfileFormScreen) SetupEdit(ctx context.Context, name string) error {
+	profile, err := s.profileStore.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	s.editMode = true
+	s.profile = profile.Clone()
+	s.profile.SortRules()
+
+	s.setupForm()
+	return nil
+}
+
+func (s *ProfileFormScreen) setupForm() {
+	s.form.Clear(true)
+
+	if s.editMode {
+		s.flex.SetTitle(" Edit Profile ")
+	} else {
+		s.flex.SetTitle(" Create Profile ")
+	}
+
+	// Name
+	s.form.AddInputField("Name", s.profile.Name, 30, nil, nil)
+	if s.editMode {
+		s.form.GetFormItemByLabel("Name").(*tview.InputField).SetDisabled(true)
+	}
+
+	s.form.AddInputField("Description", s.profile.Description, 50, nil, nil)
+
+	// Default action
+	profileDefaultOptions := defaultActionOptions[:2]
+	s.form.AddDropDown("Default Action", profileDefaultOptions, optionIndex(profileDefaultOptions, s.profile.Default), nil)
+
+	// EAP-AKA'必須
+	s.form.AddCheckbox("Require AKA'", s.profile.RequireAKAPrime, nil)
+
+	// 同時セッション数上限（0はAuth Serverの全体設定に従う）
+	s.form.AddInputField("Max Sessions", strconv.Itoa(s.profile.MaxSessions), 10, nil, nil)
+
+	// 適用するIMSI範囲（"開始-終了"のカンマ区切り）
+	s.form.AddInputField("IMSI Ranges", formatIMSIRanges(s.profile.IMSIRanges), 50, nil, nil)
+
+	// Buttons
+	s.form.AddButton("Add Rule", s.ruleEditor.showAdd)
+	s.form.AddButton("Save", s.handleSave)
+	s.form.AddButton("Cancel", s.handleCancel)
+
+	s.ruleEditor.rules = &s.profile.Rules
+	s.ruleEditor.update()
+	s.setupKeyBindings()
+}
+
+func (s *ProfileFormScreen) handleSave() {
+	// フォームからデータを取得
+	_, defaultAction := s.form.GetFormItemByLabel("Default Action").(*tview.DropDown).GetCurrentOption()
+
+	s.profile.Name = strings.TrimSpace(s.form.GetFormItemByLabel("Name").(*tview.InputField).GetText())
+	s.profile.Description = strings.TrimSpace(s.form.GetFormItemByLabel("Description").(*tview.InputField).GetText())
+	s.profile.Default = defaultAction
+	s.profile.RequireAKAPrime = s.form.GetFormItemByLabel("Require AKA'").(*tview.Checkbox).IsChecked()
+
+	maxSessionsStr := strings.TrimSpace(s.form.GetFormItemByLabel("Max Sessions").(*tview.InputField).GetText())
+	s.profile.MaxSessions = 0
+	if maxSessionsStr != "" {
+		n, err := strconv.Atoi(maxSessionsStr)
+		if err != nil {
+			s.app.GetStatusBar().ShowError("Validation error: Max Sessions must be a number")
+			return
+		}
+		s.profile.MaxSessions = n
+	}
+
+	ranges, err := parseIMSIRanges(s.form.GetFormItemByLabel("IMSI Ranges").(*tview.InputField).GetText())
+	if err != nil {
+		s.app.GetStatusBar().ShowError("Validation error: " + err.Error())
+		return
+	}
+	s.profile.IMSIRanges = ranges
+
+	// バリデーション
+	input := &validation.ProfileInput{
+		Name:        s.profile.Name,
+		Description: s.profile.Description,
+		Default:     s.profile.Default,
+		Rules:       s.profile.Rules,
+		MaxSessions: s.profile.MaxSessions,
+		IMSIRanges:  s.profile.IMSIRanges,
+	}
+	if errs := validation.ValidateProfile(input); len(errs) > 0 {
+		s.app.GetStatusBar().ShowError("Validation error: " + errs[0].Error())
+		return
+	}
+
+	s.save()
+}
+
+func (s *ProfileFormScreen) save() {
+	ctx := context.Background()
+	key := store.ProfileKey(s.profile.Name)
+
+	if s.editMode {
+		// 更新
+		if err := s.profileStore.Update(ctx, s.profile); err != nil {
+			s.app.GetStatusBar().ShowError("Failed to update: " + err.Error())
+			return
+		}
+		s.auditLogger.LogUpdate(audit.TargetProfile, key, "")
+		s.app.GetStatusBar().ShowSuccess("Profile updated: " + s.profile.Name)
+	} else {
+		// 新規作成
+		if err := s.profileStore.Create(ctx, s.profile); err != nil {
+			s.app.GetStatusBar().ShowError("Failed to create: " + err.Error())
+			return
+		}
+		s.auditLogger.LogCreate(audit.TargetProfile, key, "")
+		s.app.GetStatusBar().ShowSuccess("Profile created: " + s.profile.Name)
+	}
+
+	if s.onSave != nil {
+		s.onSave()
+	}
+}
+
+func (s *ProfileFormScreen) handleCancel() {
+	if s.onCancel != nil {
+		s.onCancel()
+	}
+}
+
+func (s *ProfileFormScreen) setupKeyBindings() {
+	s.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			s.handleCancel()
+			return nil
+		}
+		if event.Key() == tcell.KeyF6 {
+			s.app.SetFocus(s.rulesList)
+			return nil
+		}
+		return event
+	})
+
+	s.rulesList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyTab {
+			s.app.SetFocus(s.form)
+			return nil
+		}
+		// Shift+↑/↓ で選択中のルールの評価順を入れ替える
+		return s.ruleEditor.handleListKey(event)
+	})
+}
+
+// parseIMSIRanges は"開始-終了"をカンマで区切った入力をIMSI範囲のリストに変換する（空の場合はnil）。
+// 終了を省略した場合は単一のIMSIとする。
+func parseIMSIRanges(str string) ([]model.IMSIRange, error) {
+	var ranges []model.IMSIRange
+	for _, entry := range splitList(str) {
+		start, end, found := strings.Cut(entry, "-")
+		start = strings.TrimSpace(start)
+		end = strings.TrimSpace(end)
+		if !found {
+			end = start
+		}
+		if start == "" || end == "" {
+			return nil, fmt.Errorf("IMSI range %q must be in start-end format", entry)
+		}
+		ranges = append(ranges, model.IMSIRange{Start: start, End: end})
+	}
+	return ranges, nil
+}
+
+// formatIMSIRanges はIMSI範囲のリストを"開始-終了"のカンマ区切り文字列に変換する。
+func formatIMSIRanges(ranges []model.IMSIRange) string {
+	strs := make([]string, len(ranges))
+	for i, r := range ranges {
+		strs[i] = r.Start + "-" + r.End
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,369 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui"
+	"github.com/rivo/tview"
+)
+
+// ProfileListScreen はポリシープロファイル一覧画面を表す。
+type ProfileListScreen struct {
+	table        *tview.Table
+	app          *ui.App
+	profileStore *store.ProfileStore
+	profiles     []*model.Profile
+	subscribers  map[string][]string // プロファイル名→適用される加入者のIMSI
+	filter       *ui.Filter
+	pagination   *ui.Pagination
+	onCreate     func()
+	onEdit       func(name string)
+	onDelete     func(name string)
+	onBack       func()
+}
+
+// NewProfileListScreen は新しいProfileListScreenを生成する。
+func NewProfileListScreen(app *ui.App, profileStore *store.ProfileStore) *ProfileListScreen {
+	table := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	table.SetTitle(" Policy Profile List ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorBlue)
+
+	screen := &ProfileListScreen{
+		table:        table,
+		app:          app,
+		profileStore: profileStore,
+		subscribers:  map[string][]string{},
+		filter:       ui.NewFilter("Name"),
+		pagination:   ui.NewPagination(ui.DefaultPageSize),
+	}
+
+	screen.setupKeyBindings()
+	return screen
+}
+
+// SetOnCreate は新規作成時のコールバックを設定する。
+func (s *ProfileListScreen) SetOnCreate(handler func()) {
+	s.onCreate = handler
+}
+
+// SetOnEdit は編集時のコールバックを設定する。
+func (s *ProfileListScreen) SetOnEdit(handler func(name string)) {
+	s.onEdit = handler
+}
+
+// SetOnDelete は削除時のコールバックを設定する。
+func (s *ProfileListScreen) SetOnDelete(handler func(name string)) {
+	s.onDelete = handler
+}
+
+// SetOnBack は戻る時のコールバックを設定する。
+func (s *ProfileListScreen) SetOnBack(handler func()) {
+	s.onBack = handler
+}
+
+// GetTable は内部のtview.Tableを返す。
+func (s *ProfileListScreen) GetTable() *tview.Table {
+	return s.table
+}
+
+// Load はデータを読み込む。
+func (s *ProfileListScreen) Load(ctx context.Context) error {
+	profiles, err := s.profileStore.List(ctx)
+	if err != nil {
+		return err
+	}
+	subscribers, err := s.profileStore.SubscribersByProfile(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 名前でソート
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	s.profiles = profiles
+	s.subscribers = subscribers
+	s.render()
+	return nil
+}
+
+// Refresh はデータを再読み込みする。
+func (s *ProfileListScreen) Refresh(ctx context.Context) error {
+	return s.Load(ctx)
+}
+
+// SetFilter はフィルタを設定する。
+func (s *ProfileListScreen) SetFilter(query string) {
+	s.filter.SetQuery(query)
+	s.pagination.FirstPage()
+	s.render()
+}
+
+// ClearFilter はフィルタをクリアする。
+func (s *ProfileListScreen) ClearFilter() {
+	s.filter.Clear()
+	s.pagination.FirstPage()
+	s.render()
+}
+
+// GetSelectedName は選択されているプロファイル名を返す。
+func (s *ProfileListScreen) GetSelectedName() string {
+	row, _ := s.table.GetSelection()
+	filtered := s.getFilteredProfiles()
+	if row < 1 || row > len(filtered) {
+		return ""
+	}
+
+	pageItems := ui.GetPageItems(filtered, s.pagination)
+	idx := row - 1
+	if idx < 0 || idx >= len(pageItems) {
+		return ""
+	}
+	return pageItems[idx].Name
+}
+
+func (s *ProfileListScreen) getFilteredProfiles() []*model.Profile {
+	return ui.FilterItems(s.profiles, s.filter, func(profile *model.Profile) []string {
+		return []string{profile.Name}
+	})
+}
+
+func (s *ProfileListScreen) render() {
+	s.table.Clear()
+
+	// ヘッダー
+	headers := []string{"Name", "Default", "Rules", "IMSI Ranges", "Subscribers", "Description"}
+	for col, header := range headers {
+		cell := tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetExpansion(1)
+		s.table.SetCell(0, col, cell)
+	}
+
+	// フィルタ適用
+	filtered := s.getFilteredProfiles()
+	pageItems := ui.GetPageItems(filtered, s.pagination)
+
+	// データ行
+	for i, profile := range pageItems {
+		row := i + 1
+
+		s.table.SetCell(row, 0, tview.NewTableCell(profile.Name).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		defaultColor := tcell.ColorGreen
+		if profile.Default == "allow" {
+			defaultColor = tcell.ColorYellow
+		}
+		s.table.SetCell(row, 1, tview.NewTableCell(profile.Default).
+			SetTextColor(defaultColor).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 2, tview.NewTableCell(formatRulesCount(len(profile.Rules))).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf("%d", len(profile.IMSIRanges))).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 4, tview.NewTableCell(fmt.Sprintf("%d", len(s.subscribers[profile.Name]))).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 5, tview.NewTableCell(profile.Description).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(2))
+	}
+
+	// タイトル更新
+	title := " Policy Profile List "
+	if s.filter.Active {
+		title += "[yellow](" + s.filter.FormatFilterStatus() + ")[-] "
+	}
+	title += "[gray]" + s.pagination.FormatPageInfo() + "[-] "
+	s.table.SetTitle(title)
+
+	// 選択を先頭に
+	if len(pageItems) > 0 {
+		s.table.SetSelectable(true, false)
+		s.table.Select(1, 0)
+	} else {
+		s.table.SetSelectable(false, false)
+		emptyCell := tview.NewTableCell("(No data)").
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false)
+		s.table.SetCell(1, 0, emptyCell)
+	}
+}
+
+func (s *ProfileListScreen) refresh() {
+	go func() {
+		s.app.QueueUpdateDraw(func() {
+			if err := s.Refresh(context.Background()); err != nil {
+				s.app.GetStatusBar().ShowError("Failed to refresh: " + err.Error())
+			} else {
+				s.app.GetStatusBar().ShowSuccess("Refreshed")
+			}
+		})
+	}()
+}
+
+func (s *ProfileListScreen) setupKeyBindings() {
+	s.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			if s.filter.Active {
+				s.ClearFilter()
+				return nil
+			}
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		case tcell.KeyF2:
+			if s.onCreate != nil {
+				s.onCreate()
+			}
+			return nil
+		case tcell.KeyF3:
+			if name := s.GetSelectedName(); name != "" && s.onEdit != nil {
+				s.onEdit(name)
+			}
+			return nil
+		case tcell.KeyF4:
+			if name := s.GetSelectedName(); name != "" && s.onDelete != nil {
+				s.onDelete(name)
+			}
+			return nil
+		case tcell.KeyF5:
+			s.refresh()
+			return nil
+		case tcell.KeyPgUp:
+			if s.pagination.PrevPage() {
+				s.render()
+			}
+			return nil
+		case tcell.KeyPgDn:
+			if s.pagination.NextPage() {
+				s.render()
+			}
+			return nil
+		case tcell.KeyEnter:
+			if name := s.GetSelectedName(); name != "" {
+				s.showSubscribers(name)
+			}
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'n':
+			if s.onCreate != nil {
+				s.onCreate()
+			}
+			return nil
+		case 'e':
+			if name := s.GetSelectedName(); name != "" && s.onEdit != nil {
+				s.onEdit(name)
+			}
+			return nil
+		case 'd':
+			if name := s.GetSelectedName(); name != "" && s.onDelete != nil {
+				s.onDelete(name)
+			}
+			return nil
+		case 's':
+			if name := s.GetSelectedName(); name != "" {
+				s.showSubscribers(name)
+			}
+			return nil
+		case 'r':
+			s.refresh()
+			return nil
+		case '/':
+			s.showFilterDialog()
+			return nil
+		case 'q':
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		}
+
+		return event
+	})
+}
+
+// showSubscribers はプロファイルを適用する加入者のIMSI一覧を表示する。
+func (s *ProfileListScreen) showSubscribers(name string) {
+	imsis := s.subscribers[name]
+
+	text := "(No subscribers)"
+	if len(imsis) > 0 {
+		text = strings.Join(imsis, "\n")
+	}
+	view := tview.NewTextView().
+		SetText(text).
+		SetScrollable(true)
+	view.SetTitle(fmt.Sprintf(" Subscribers: %s (%d) ", name, len(imsis))).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorTeal)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Key() == tcell.KeyEnter || event.Rune() == 'q' {
+			s.app.HidePage("profile-subscribers")
+			s.app.RemovePage("profile-subscribers")
+			s.app.SetFocus(s.table)
+			return nil
+		}
+		return event
+	})
+
+	s.app.AddPage("profile-subscribers", centered(view, 40, 20), true, true)
+	s.app.SetFocus(view)
+}
+
+func (s *ProfileListScreen) showFilterDialog() {
+	dialog := ui.NewInputDialog(
+		"Filter Profiles",
+		"Name contains:",
+		s.filter.Query,
+		func(value string) {
+			s.SetFilter(value)
+			s.app.HidePage("filter-dialog")
+			s.app.RemovePage("filter-dialog")
+			s.app.SetFocus(s.table)
+		},
+		func() {
+			s.app.HidePage("filter-dialog")
+			s.app.RemovePage("filter-dialog")
+			s.app.SetFocus(s.table)
+		},
+	)
+
+	s.app.AddPage("filter-dialog", centered(dialog.GetForm(), 50, 7), true, true)
+	s.app.SetFocus(dialog.GetForm())
+}
@@ -1,5 +1,350 @@
 package policy
 
-// Note: The rule dialog functionality is implemented within form.go
-// as showRuleDialog, showAddRuleDialog, and showEditRuleDialog methods.
-// This file is kept for organizational purposes and potential future expansion.
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/validation"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/replyattr"
+	"github.com/rivo/tview"
+)
+
+// ruleActionOptions はルール動作のドロップダウン選択肢
+var ruleActionOptions = []string{model.RuleActionAllow, model.RuleActionDeny, model.RuleActionReject}
+
+// rejectReasonOptions は拒否理由のドロップダウン選択肢（先頭は未設定）
+var rejectReasonOptions = []string{
+	"-",
+	model.RejectReasonGeneralFailure,
+	model.RejectReasonTemporarilyDenied,
+	model.RejectReasonNotSubscribed,
+}
+
+// ruleEditor はポリシー・プロファイル画面で共通のルールリストと追加・編集ダイアログを扱う。
+type ruleEditor struct {
+	app     *ui.App
+	list    *tview.List
+	rules   *[]model.PolicyRule // 編集対象のルール（画面のセットアップ時に設定）
+	onClose func()              // ダイアログを閉じた後にフォームへフォーカスを戻す
+}
+
+// newRuleEditor は新しいruleEditorを生成する。
+func newRuleEditor(app *ui.App, list *tview.List, onClose func()) *ruleEditor {
+	return &ruleEditor{
+		app:     app,
+		list:    list,
+		onClose: onClose,
+	}
+}
+
+// update はルールリストの表示を更新する。
+func (e *ruleEditor) update() {
+	e.list.Clear()
+
+	if len(*e.rules) == 0 {
+		e.list.AddItem("(No rules defined)", "", 0, nil)
+		return
+	}
+
+	for i, rule := range *e.rules {
+		idx := i
+		action := rule.Action
+		if action == "" {
+			action = model.RuleActionAllow
+		}
+		if rule.RejectReason != "" {
+			action += " (" + rule.RejectReason + ")"
+		}
+		mainText := fmt.Sprintf("[%d] NAS: %s | %s | Priority: %d", i+1, rule.NasID, action, rule.Priority)
+		secondText := fmt.Sprintf("SSIDs: %s", strings.Join(rule.AllowedSSIDs, ", "))
+		if rule.VlanID != "" {
+			secondText += fmt.Sprintf(" | VLAN: %s", rule.VlanID)
+		}
+		if rule.SessionTimeout > 0 {
+			secondText += fmt.Sprintf(" | Timeout: %ds", rule.SessionTimeout)
+		}
+		if len(rule.NasIPs) > 0 {
+			secondText += fmt.Sprintf(" | NAS IP: %s", strings.Join(rule.NasIPs, ", "))
+		}
+		if len(rule.CallingStationAllow) > 0 || len(rule.CallingStationDeny) > 0 {
+			secondText += fmt.Sprintf(" | MAC: +%d/-%d", len(rule.CallingStationAllow), len(rule.CallingStationDeny))
+		}
+		if len(rule.NasPortTypes) > 0 {
+			secondText += fmt.Sprintf(" | Port Type: %s", joinInts(rule.NasPortTypes))
+		}
+		if len(rule.Days) > 0 || rule.TimeStart != "" || rule.TimeEnd != "" {
+			secondText += fmt.Sprintf(" | Time: %s %s-%s", strings.Join(rule.Days, ","), rule.TimeStart, rule.TimeEnd)
+		}
+		if len(rule.ReplyAttributes) > 0 {
+			secondText += fmt.Sprintf(" | Reply: %d", len(rule.ReplyAttributes))
+		}
+
+		e.list.AddItem(mainText, secondText, 0, func() {
+			e.showEdit(idx)
+		})
+	}
+}
+
+// handleListKey はルールリストのShift+↑/↓で選択中のルールの評価順を入れ替える。
+func (e *ruleEditor) handleListKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Modifiers()&tcell.ModShift != 0 && len(*e.rules) > 0 {
+		switch event.Key() {
+		case tcell.KeyUp:
+			e.move(e.list.GetCurrentItem(), -1)
+			return nil
+		case tcell.KeyDown:
+			e.move(e.list.GetCurrentItem(), 1)
+			return nil
+		}
+	}
+	return event
+}
+
+func (e *ruleEditor) showAdd() {
+	e.show(-1, &model.PolicyRule{
+		NasID:        "example.com",
+		AllowedSSIDs: []string{},
+	})
+}
+
+func (e *ruleEditor) showEdit(index int) {
+	if index < 0 || index >= len(*e.rules) {
+		return
+	}
+	rule := (*e.rules)[index]
+	e.show(index, &rule)
+}
+
+func (e *ruleEditor) close() {
+	e.app.HidePage("rule-dialog")
+	e.app.RemovePage("rule-dialog")
+}
+
+func (e *ruleEditor) show(index int, rule *model.PolicyRule) {
+	ruleForm := tview.NewForm()
+
+	title := " Add Rule "
+	if index >= 0 {
+		title = " Edit Rule "
+	}
+
+	ruleForm.SetTitle(title).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorTeal)
+
+	ruleForm.AddInputField("NAS ID", rule.NasID, 40, nil, nil)
+	ruleForm.AddInputField("Allowed SSIDs", strings.Join(rule.AllowedSSIDs, ","), 40, nil, nil)
+	ruleForm.AddDropDown("Action", ruleActionOptions, optionIndex(ruleActionOptions, rule.Action), nil)
+	ruleForm.AddDropDown("Reject Reason", rejectReasonOptions, optionIndex(rejectReasonOptions, rule.RejectReason), nil)
+	ruleForm.AddInputField("Priority", strconv.Itoa(rule.Priority), 10, nil, nil)
+	ruleForm.AddInputField("VLAN ID", rule.VlanID, 10, nil, nil)
+	ruleForm.AddInputField("Session Timeout", strconv.Itoa(rule.SessionTimeout), 10, nil, nil)
+	ruleForm.AddInputField("NAS IPs", strings.Join(rule.NasIPs, ","), 40, nil, nil)
+	ruleForm.AddInputField("MAC Allow", strings.Join(rule.CallingStationAllow, ","), 40, nil, nil)
+	ruleForm.AddInputField("MAC Deny", strings.Join(rule.CallingStationDeny, ","), 40, nil, nil)
+	ruleForm.AddInputField("NAS Port Types", joinInts(rule.NasPortTypes), 20, nil, nil)
+	ruleForm.AddInputField("Days", strings.Join(rule.Days, ","), 30, nil, nil)
+	ruleForm.AddInputField("Time Start", rule.TimeStart, 6, nil, nil)
+	ruleForm.AddInputField("Time End", rule.TimeEnd, 6, nil, nil)
+	ruleForm.AddInputField("Reply Attributes", formatReplyAttributes(rule.ReplyAttributes), 40, nil, nil)
+
+	ruleForm.AddButton("OK", func() {
+		nasID := ruleForm.GetFormItemByLabel("NAS ID").(*tview.InputField).GetText()
+		ssidsStr := ruleForm.GetFormItemByLabel("Allowed SSIDs").(*tview.InputField).GetText()
+		vlanStr := ruleForm.GetFormItemByLabel("VLAN ID").(*tview.InputField).GetText()
+		timeoutStr := ruleForm.GetFormItemByLabel("Session Timeout").(*tview.InputField).GetText()
+
+		// Parse SSIDs
+		ssids := splitList(ssidsStr)
+
+		// Parse NAS Port Types
+		var portTypes []int
+		for _, v := range splitList(ruleForm.GetFormItemByLabel("NAS Port Types").(*tview.InputField).GetText()) {
+			t, err := strconv.Atoi(v)
+			if err != nil {
+				e.app.GetStatusBar().ShowError("Validation error: NAS Port Types must be numbers")
+				return
+			}
+			portTypes = append(portTypes, t)
+		}
+
+		// Parse Reply Attributes（Name=Value;...、ベンダーは属性名から補完）
+		replyAttrs, err := parseReplyAttributes(ruleForm.GetFormItemByLabel("Reply Attributes").(*tview.InputField).GetText())
+		if err != nil {
+			e.app.GetStatusBar().ShowError("Validation error: " + err.Error())
+			return
+		}
+
+		// Parse Action / Reject Reason（拒否理由はrejectの場合のみ保持）
+		_, action := ruleForm.GetFormItemByLabel("Action").(*tview.DropDown).GetCurrentOption()
+		_, rejectReason := ruleForm.GetFormItemByLabel("Reject Reason").(*tview.DropDown).GetCurrentOption()
+		if rejectReason == rejectReasonOptions[0] {
+			rejectReason = ""
+		}
+
+		// Parse Priority
+		priority := 0
+		if v := strings.TrimSpace(ruleForm.GetFormItemByLabel("Priority").(*tview.InputField).GetText()); v != "" {
+			p, err := strconv.Atoi(v)
+			if err != nil {
+				e.app.GetStatusBar().ShowError("Validation error: Priority must be a number")
+				return
+			}
+			priority = p
+		}
+
+		// Parse Days（小文字に統一）
+		days := splitList(strings.ToLower(ruleForm.GetFormItemByLabel("Days").(*tview.InputField).GetText()))
+
+		// Parse VLAN ID (string型として保持)
+		vlanID := strings.TrimSpace(vlanStr)
+
+		// Parse Session Timeout
+		sessionTimeout := 0
+		if timeoutStr != "" {
+			if t, err := strconv.Atoi(timeoutStr); err == nil {
+				sessionTimeout = t
+			}
+		}
+
+		newRule := model.PolicyRule{
+			NasID:          strings.TrimSpace(nasID),
+			AllowedSSIDs:   ssids,
+			VlanID:         vlanID,
+			SessionTimeout: sessionTimeout,
+
+			NasIPs:              splitList(ruleForm.GetFormItemByLabel("NAS IPs").(*tview.InputField).GetText()),
+			CallingStationAllow: splitList(ruleForm.GetFormItemByLabel("MAC Allow").(*tview.InputField).GetText()),
+			CallingStationDeny:  splitList(ruleForm.GetFormItemByLabel("MAC Deny").(*tview.InputField).GetText()),
+			NasPortTypes:        portTypes,
+			Days:                days,
+			TimeStart:           strings.TrimSpace(ruleForm.GetFormItemByLabel("Time Start").(*tview.InputField).GetText()),
+			TimeEnd:             strings.TrimSpace(ruleForm.GetFormItemByLabel("Time End").(*tview.InputField).GetText()),
+
+			ReplyAttributes: replyAttrs,
+
+			Action:       action,
+			RejectReason: rejectReason,
+			Priority:     priority,
+		}
+
+		// Validate
+		if errs := validation.ValidatePolicyRule(&newRule); len(errs) > 0 {
+			e.app.GetStatusBar().ShowError("Validation error: " + errs[0].Error())
+			return
+		}
+
+		if index >= 0 {
+			(*e.rules)[index] = newRule
+		} else {
+			*e.rules = append(*e.rules, newRule)
+		}
+		model.SortRules(*e.rules)
+
+		e.close()
+		e.update()
+		e.onClose()
+	})
+
+	if index >= 0 {
+		// Up/Downは入力中の変更を破棄してルールの評価順を入れ替える
+		ruleForm.AddButton("Up", func() {
+			e.close()
+			e.move(index, -1)
+		})
+		ruleForm.AddButton("Down", func() {
+			e.close()
+			e.move(index, 1)
+		})
+		ruleForm.AddButton("Delete", func() {
+			*e.rules = append((*e.rules)[:index], (*e.rules)[index+1:]...)
+			e.close()
+			e.update()
+			e.onClose()
+		})
+	}
+
+	ruleForm.AddButton("Cancel", func() {
+		e.close()
+		e.onClose()
+	})
+
+	e.app.AddPage("rule-dialog", centered(ruleForm, 60, 34), true, true)
+	e.app.SetFocus(ruleForm)
+}
+
+// move はルールを隣接するルールと入れ替え、移動後のルールを選択した状態でルールリストにフォーカスする。
+func (e *ruleEditor) move(index, delta int) {
+	if model.MoveRule(*e.rules, index, delta) {
+		index += delta
+	}
+	e.update()
+	e.list.SetCurrentItem(index)
+	e.app.SetFocus(e.list)
+}
+
+// optionIndex はドロップダウンの選択肢からvalueの位置を返す（見つからない場合は先頭）。
+func optionIndex(options []string, value string) int {
+	if i := slices.Index(options, value); i >= 0 {
+		return i
+	}
+	return 0
+}
+
+// splitList はカンマ区切りの入力を空要素を除いたリストに分割する（空の場合はnil）。
+func splitList(str string) []string {
+	var list []string
+	for _, v := range strings.Split(str, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// joinInts は整数のリストをカンマ区切りの文字列に変換する。
+func joinInts(values []int) string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = strconv.Itoa(v)
+	}
+	return strings.Join(strs, ",")
+}
+
+// parseReplyAttributes は"Name=Value"をセミコロンで区切った入力を応答属性のリストに変換する（空の場合はnil）。
+// ベンダー名は属性名から補完する（未対応の属性名はバリデーションで検出する）。
+func parseReplyAttributes(str string) ([]model.ReplyAttribute, error) {
+	var attrs []model.ReplyAttribute
+	for _, entry := range strings.Split(str, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("reply attribute %q must be in Name=Value format", entry)
+		}
+		attr := model.ReplyAttribute{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)}
+		if def, found := replyattr.Lookup(attr.Name); found {
+			attr.Name = def.Name
+			attr.Vendor = def.Vendor
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
+
+// formatReplyAttributes は応答属性のリストを"Name=Value"のセミコロン区切り文字列に変換する。
+func formatReplyAttributes(attrs []model.ReplyAttribute) string {
+	strs := make([]string, len(attrs))
+	for i, attr := range attrs {
+		strs[i] = attr.Name + "=" + attr.Value
+	}
+	return strings.Join(strs, ";")
+}
@@ -22,10 +22,18 @@ type FormScreen struct {
 	editMode        bool
 	originalIMSI    string
 	originalSQN     string
+	originalStatus  string
 	onSave          func()
 	onCancel        func()
 }
 
+// statusOptions は利用状態ドロップダウンの選択肢。
+var statusOptions = []string{
+	model.SubscriberStatusActive,
+	model.SubscriberStatusSuspended,
+	model.SubscriberStatusBarred,
+}
+
 // NewFormScreen は新しいFormScreenを生成する。
 func NewFormScreen(app *ui.App, subscriberStore *store.SubscriberStore, auditLogger *audit.Logger) *FormScreen {
 	form := tview.NewForm()
@@ -64,6 +72,7 @@ func (s *FormScreen) SetupCreate() {
 	s.editMode = false
 	s.originalIMSI = ""
 	s.originalSQN = ""
+	s.originalStatus = model.SubscriberStatusActive
 
 	s.form.Clear(true)
 	s.form.SetTitle(" Create Subscriber ")
@@ -73,6 +82,8 @@ func (s *FormScreen) SetupCreate() {
 	s.form.AddInputField("OPc", "", 40, nil, nil)
 	s.form.AddInputField("AMF", "8000", 10, nil, nil)
 	s.form.AddInputField("SQN", "000000000000", 20, nil, nil)
+	s.form.AddCheckbox("EAP-SIM", false, nil)
+	s.addStatusFields(&model.Subscriber{})
 
 	s.form.AddButton("Save", s.handleSave)
 	s.form.AddButton("Cancel", s.handleCancel)
@@ -90,6 +101,7 @@ func (s *FormScreen) SetupEdit(ctx context.Context, imsi string) error {
 	s.editMode = true
 	s.originalIMSI = imsi
 	s.originalSQN = sub.SQN
+	s.originalStatus = sub.EffectiveStatus()
 
 	s.form.Clear(true)
 	s.form.SetTitle(" Edit Subscriber ")
@@ -100,6 +112,8 @@ func (s *FormScreen) SetupEdit(ctx context.Context, imsi string) error {
 	s.form.AddInputField("OPc", sub.OPc, 40, nil, nil)
 	s.form.AddInputField("AMF", sub.AMF, 10, nil, nil)
 	s.form.AddInputField("SQN", sub.SQN, 20, nil, nil)
+	s.form.AddCheckbox("EAP-SIM", sub.SIMEnabled, nil)
+	s.addStatusFields(sub)
 
 	// IMSI入力フィールドを無効化
 	imsiField := s.form.GetFormItemByLabel("IMSI").(*tview.InputField)
@@ -112,6 +126,20 @@ func (s *FormScreen) SetupEdit(ctx context.Context, imsi string) error {
 	return nil
 }
 
+// addStatusFields は利用状態と有効期間の入力欄を追加する。
+func (s *FormScreen) addStatusFields(sub *model.Subscriber) {
+	current := 0
+	for i, status := range statusOptions {
+		if status == sub.EffectiveStatus() {
+			current = i
+		}
+	}
+	s.form.AddDropDown("Status", statusOptions, current, nil)
+	s.form.AddInputField("Reason", sub.StatusReason, 40, nil, nil)
+	s.form.AddInputField("Valid From", sub.ValidFrom, 26, nil, nil)
+	s.form.AddInputField("Valid Until", sub.ValidUntil, 26, nil, nil)
+}
+
 func (s *FormScreen) handleSave() {
 	// フォームからデータを取得
 	input := &validation.SubscriberInput{
@@ -120,7 +148,12 @@ func (s *FormScreen) handleSave() {
 		OPc:  s.form.GetFormItemByLabel("OPc").(*tview.InputField).GetText(),
 		AMF:  s.form.GetFormItemByLabel("AMF").(*tview.InputField).GetText(),
 		SQN:  s.form.GetFormItemByLabel("SQN").(*tview.InputField).GetText(),
+
+		StatusReason: s.form.GetFormItemByLabel("Reason").(*tview.InputField).GetText(),
+		ValidFrom:    s.form.GetFormItemByLabel("Valid From").(*tview.InputField).GetText(),
+		ValidUntil:   s.form.GetFormItemByLabel("Valid Until").(*tview.InputField).GetText(),
 	}
+	_, input.Status = s.form.GetFormItemByLabel("Status").(*tview.DropDown).GetCurrentOption()
 
 	// 正規化
 	input = validation.NormalizeSubscriberInput(input)
@@ -170,6 +203,13 @@ func (s *FormScreen) save(input *validation.SubscriberInput) {
 		OPc:  input.OPc,
 		AMF:  input.AMF,
 		SQN:  input.SQN,
+
+		SIMEnabled: s.form.GetFormItemByLabel("EAP-SIM").(*tview.Checkbox).IsChecked(),
+
+		Status:       input.Status,
+		StatusReason: input.StatusReason,
+		ValidFrom:    input.ValidFrom,
+		ValidUntil:   input.ValidUntil,
 	}
 
 	if s.editMode {
@@ -179,6 +219,7 @@ func (s *FormScreen) save(input *validation.SubscriberInput) {
 			return
 		}
 		s.auditLogger.LogUpdate(audit.TargetSubscriber, store.SubscriberKey(sub.IMSI), sub.IMSI)
+		s.logStatusChange(sub)
 		s.app.GetStatusBar().ShowSuccess("Subscriber updated: " + sub.IMSI)
 	} else {
 		// 新規作成
@@ -188,6 +229,7 @@ func (s *FormScreen) save(input *validation.SubscriberInput) {
 			return
 		}
 		s.auditLogger.LogCreate(audit.TargetSubscriber, store.SubscriberKey(sub.IMSI), sub.IMSI)
+		s.logStatusChange(sub)
 		s.app.GetStatusBar().ShowSuccess("Subscriber created: " + sub.IMSI)
 	}
 
@@ -196,6 +238,16 @@ func (s *FormScreen) save(input *validation.SubscriberInput) {
 	}
 }
 
+// logStatusChange は利用状態が変更された場合に監査ログを出力する。
+// 新規作成時はactiveからの変更として扱う。
+func (s *FormScreen) logStatusChange(sub *model.Subscriber) {
+	if sub.EffectiveStatus() == s.originalStatus {
+		return
+	}
+	s.auditLogger.LogStatusChange(audit.TargetSubscriber, store.SubscriberKey(sub.IMSI), sub.IMSI,
+		s.originalStatus, sub.EffectiveStatus(), sub.StatusReason)
+}
+
 func (s *FormScreen) handleCancel() {
 	if s.onCancel != nil {
 		s.onCancel()
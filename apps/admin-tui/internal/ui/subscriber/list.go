@@ -4,6 +4,7 @@ package subscriber
 import (
 	"context"
 	"sort"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/store"
@@ -147,7 +148,7 @@ func (s *ListScreen) GetSelectedIMSI() string {
 
 func (s *ListScreen) getFilteredSubscribers() []*model.Subscriber {
 	return ui.FilterItems(s.subscribers, s.filter, func(sub *model.Subscriber) []string {
-		return []string{sub.IMSI}
+		return []string{sub.IMSI, statusDisplay(sub, time.Now())}
 	})
 }
 
@@ -155,7 +156,7 @@ func (s *ListScreen) render() {
 	s.table.Clear()
 
 	// ヘッダー
-	headers := []string{"", "IMSI", "Ki", "OPc", "AMF", "SQN", "Created"}
+	headers := []string{"", "IMSI", "Ki", "OPc", "AMF", "SQN", "SIM", "Status", "Created"}
 	for col, header := range headers {
 		cell := tview.NewTableCell(header).
 			SetTextColor(tcell.ColorYellow).
@@ -173,6 +174,7 @@ func (s *ListScreen) render() {
 	pageItems := ui.GetPageItems(filtered, s.pagination)
 
 	// データ行
+	now := time.Now()
 	for i, sub := range pageItems {
 		row := i + 1
 
@@ -225,12 +227,35 @@ func (s *ListScreen) render() {
 			SetAlign(tview.AlignLeft).
 			SetExpansion(1))
 
+		// SIM（EAP-SIM許可）
+		simDisplay := "-"
+		if sub.SIMEnabled {
+			simDisplay = "Yes"
+		}
+		s.table.SetCell(row, 6, tview.NewTableCell(simDisplay).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		// Status（停止中・有効期間外は色分け）
+		status := statusDisplay(sub, now)
+		statusColor := tcell.ColorWhite
+		if status == model.SubscriberStatusBarred {
+			statusColor = tcell.ColorRed
+		} else if status != model.SubscriberStatusActive {
+			statusColor = tcell.ColorOrange
+		}
+		s.table.SetCell(row, 7, tview.NewTableCell(status).
+			SetTextColor(statusColor).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
 		// Created
 		createdDisplay := sub.CreatedAt
 		if len(createdDisplay) > 10 {
 			createdDisplay = createdDisplay[:10]
 		}
-		s.table.SetCell(row, 6, tview.NewTableCell(createdDisplay).
+		s.table.SetCell(row, 8, tview.NewTableCell(createdDisplay).
 			SetTextColor(tcell.ColorGray).
 			SetAlign(tview.AlignLeft).
 			SetExpansion(1))
@@ -258,6 +283,19 @@ func (s *ListScreen) render() {
 	}
 }
 
+// statusDisplay は一覧に表示する利用状態を返す。
+// activeでも有効期間外の場合は"expired"（開始前は"pending"）と表示する。
+func statusDisplay(sub *model.Subscriber, now time.Time) string {
+	status := sub.EffectiveStatus()
+	if status != model.SubscriberStatusActive || sub.IsWithinValidity(now) {
+		return status
+	}
+	if sub.ValidFrom != "" && !model.WithinValidity(sub.ValidFrom, "", now) {
+		return "pending"
+	}
+	return "expired"
+}
+
 func (s *ListScreen) setupKeyBindings() {
 	s.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
@@ -0,0 +1,121 @@
+package sucikey
+
+import (
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/audit"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/suci"
+	"github.com/rivo/tview"
+)
+
+// schemeOptions は生成可能な保護方式の選択肢
+var schemeOptions = []suci.Scheme{suci.SchemeProfileA, suci.SchemeProfileB}
+
+// FormScreen はSUCI鍵生成画面を表す。
+type FormScreen struct {
+	form        *tview.Form
+	app         *ui.App
+	keyStore    *store.SUCIKeyStore
+	auditLogger *audit.Logger
+	onSave      func()
+	onCancel    func()
+}
+
+// NewFormScreen は新しいFormScreenを生成する。
+func NewFormScreen(app *ui.App, keyStore *store.SUCIKeyStore, auditLogger *audit.Logger) *FormScreen {
+	form := tview.NewForm()
+
+	form.SetBorder(true).
+		SetBorderColor(tcell.ColorBlue)
+
+	return &FormScreen{
+		form:        form,
+		app:         app,
+		keyStore:    keyStore,
+		auditLogger: auditLogger,
+	}
+}
+
+// SetOnSave は生成完了時のコールバックを設定する。
+func (s *FormScreen) SetOnSave(handler func()) {
+	s.onSave = handler
+}
+
+// SetOnCancel はキャンセル時のコールバックを設定する。
+func (s *FormScreen) SetOnCancel(handler func()) {
+	s.onCancel = handler
+}
+
+// GetForm は内部のtview.Formを返す。
+func (s *FormScreen) GetForm() *tview.Form {
+	return s.form
+}
+
+// Setup は未使用の最小の鍵IDを初期値としてフォームをセットアップする。
+func (s *FormScreen) Setup() error {
+	nextID, err := s.keyStore.NextID()
+	if err != nil {
+		return err
+	}
+
+	s.form.Clear(true)
+	s.form.SetTitle(" Generate SUCI Home Network Key ")
+
+	labels := make([]string, len(schemeOptions))
+	for i, scheme := range schemeOptions {
+		labels[i] = scheme.String()
+	}
+
+	s.form.AddInputField("Key ID (0-255)", strconv.Itoa(int(nextID)), 5, tview.InputFieldInteger, nil)
+	s.form.AddDropDown("Scheme", labels, 0, nil)
+
+	s.form.AddButton("Generate", s.handleGenerate)
+	s.form.AddButton("Cancel", s.handleCancel)
+
+	s.setupKeyBindings()
+	return nil
+}
+
+func (s *FormScreen) handleGenerate() {
+	idText := s.form.GetFormItemByLabel("Key ID (0-255)").(*tview.InputField).GetText()
+	id, err := strconv.ParseUint(idText, 10, 8)
+	if err != nil {
+		s.app.GetStatusBar().ShowError("Validation error: Key ID must be between 0 and 255")
+		return
+	}
+	idx, _ := s.form.GetFormItemByLabel("Scheme").(*tview.DropDown).GetCurrentOption()
+	if idx < 0 {
+		idx = 0
+	}
+
+	key, err := s.keyStore.Generate(uint8(id), schemeOptions[idx])
+	if err != nil {
+		s.app.GetStatusBar().ShowError("Failed to generate: " + err.Error())
+		return
+	}
+	s.auditLogger.LogCreate(audit.TargetSUCIKey, store.SUCIKeyAuditKey(key.ID), "")
+	s.app.GetStatusBar().ShowSuccess("Key generated: " + strconv.Itoa(int(key.ID)) + " (restart auth-server to activate)")
+
+	if s.onSave != nil {
+		s.onSave()
+	}
+}
+
+func (s *FormScreen) handleCancel() {
+	if s.onCancel != nil {
+		s.onCancel()
+	}
+}
+
+func (s *FormScreen) setupKeyBindings() {
+	s.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			s.handleCancel()
+			return nil
+		}
+		return event
+	})
+}
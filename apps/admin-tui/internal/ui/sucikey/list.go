@@ -0,0 +1,169 @@
+// Package sucikey はSUCIホームネットワーク鍵管理画面を提供する。
+package sucikey
+
+import (
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/format"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui"
+	"github.com/rivo/tview"
+)
+
+// ListScreen はSUCI鍵一覧画面を表す。
+// 鍵ファイルに登録された鍵（auth-serverが起動時に読み込む有効な鍵）を表示する。
+type ListScreen struct {
+	table    *tview.Table
+	app      *ui.App
+	keyStore *store.SUCIKeyStore
+	keys     []*store.SUCIKey
+	onCreate func()
+	onBack   func()
+}
+
+// NewListScreen は新しいListScreenを生成する。
+func NewListScreen(app *ui.App, keyStore *store.SUCIKeyStore) *ListScreen {
+	table := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	table.SetTitle(" SUCI Home Network Keys ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorder(true).
+		SetBorderColor(tcell.ColorBlue)
+
+	screen := &ListScreen{
+		table:    table,
+		app:      app,
+		keyStore: keyStore,
+	}
+
+	screen.setupKeyBindings()
+	return screen
+}
+
+// SetOnCreate は鍵生成時のコールバックを設定する。
+func (s *ListScreen) SetOnCreate(handler func()) {
+	s.onCreate = handler
+}
+
+// SetOnBack は戻る時のコールバックを設定する。
+func (s *ListScreen) SetOnBack(handler func()) {
+	s.onBack = handler
+}
+
+// GetTable は内部のtview.Tableを返す。
+func (s *ListScreen) GetTable() *tview.Table {
+	return s.table
+}
+
+// Load はデータを読み込む。
+func (s *ListScreen) Load() error {
+	keys, err := s.keyStore.List()
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.render()
+	return nil
+}
+
+func (s *ListScreen) render() {
+	s.table.Clear()
+
+	// ヘッダー
+	headers := []string{"Key ID", "Scheme", "Public Key", "Created"}
+	for col, header := range headers {
+		cell := tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetExpansion(1)
+		s.table.SetCell(0, col, cell)
+	}
+
+	// データ行
+	for i, key := range s.keys {
+		row := i + 1
+
+		s.table.SetCell(row, 0, tview.NewTableCell(strconv.Itoa(int(key.ID))).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft))
+
+		s.table.SetCell(row, 1, tview.NewTableCell(key.Scheme.String()).
+			SetTextColor(tcell.ColorWhite).
+			SetAlign(tview.AlignLeft))
+
+		s.table.SetCell(row, 2, tview.NewTableCell(key.PublicKey).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft).
+			SetExpansion(1))
+
+		s.table.SetCell(row, 3, tview.NewTableCell(format.DateTime(key.CreatedAt.Unix())).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignLeft))
+	}
+
+	// タイトル更新
+	s.table.SetTitle(" SUCI Home Network Keys [gray](" + s.keyStore.Path() + ")[-] ")
+
+	if len(s.keys) > 0 {
+		s.table.SetSelectable(true, false)
+		s.table.Select(1, 0)
+	} else {
+		s.table.SetSelectable(false, false)
+		emptyCell := tview.NewTableCell("(No keys)").
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false)
+		s.table.SetCell(1, 0, emptyCell)
+	}
+}
+
+func (s *ListScreen) setupKeyBindings() {
+	s.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		case tcell.KeyF2:
+			if s.onCreate != nil {
+				s.onCreate()
+			}
+			return nil
+		case tcell.KeyF5:
+			s.refresh()
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'n':
+			if s.onCreate != nil {
+				s.onCreate()
+			}
+			return nil
+		case 'r':
+			s.refresh()
+			return nil
+		case 'q':
+			if s.onBack != nil {
+				s.onBack()
+			}
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (s *ListScreen) refresh() {
+	if err := s.Load(); err != nil {
+		s.app.GetStatusBar().ShowError("Failed to refresh: " + err.Error())
+		return
+	}
+	s.app.GetStatusBar().ShowSuccess("Refreshed")
+}
@@ -65,12 +65,25 @@ func ValidateVendor(vendor string) error {
 	return nil
 }
 
+// ValidateNetworkName はEAP-AKA'のアクセスネットワーク名のバリデーションを行う。
+// 空の場合はAuth Server側の既定値を使用するため許可する。
+func ValidateNetworkName(networkName string) error {
+	if len(networkName) > MaxNetworkNameLength {
+		return &ClientValidationError{Field: "Network Name", Message: fmt.Sprintf("must be at most %d characters", MaxNetworkNameLength)}
+	}
+	if !NetworkNamePattern.MatchString(networkName) {
+		return &ClientValidationError{Field: "Network Name", Message: "must contain only printable ASCII characters (no spaces)"}
+	}
+	return nil
+}
+
 // ClientInput はRADIUSクライアントの入力データを表す。
 type ClientInput struct {
-	IP     string
-	Secret string
-	Name   string
-	Vendor string
+	IP          string
+	Secret      string
+	Name        string
+	Vendor      string
+	NetworkName string
 }
 
 // ValidateClient はRADIUSクライアントデータの全体バリデーションを行う。
@@ -89,6 +102,9 @@ func ValidateClient(input *ClientInput) []error {
 	if err := ValidateVendor(input.Vendor); err != nil {
 		errs = append(errs, err)
 	}
+	if err := ValidateNetworkName(input.NetworkName); err != nil {
+		errs = append(errs, err)
+	}
 
 	return errs
 }
@@ -96,9 +112,10 @@ func ValidateClient(input *ClientInput) []error {
 // NormalizeClientInput は入力データを正規化する。
 func NormalizeClientInput(input *ClientInput) *ClientInput {
 	return &ClientInput{
-		IP:     strings.TrimSpace(input.IP),
-		Secret: strings.TrimSpace(input.Secret),
-		Name:   strings.TrimSpace(input.Name),
-		Vendor: strings.TrimSpace(input.Vendor),
+		IP:          strings.TrimSpace(input.IP),
+		Secret:      strings.TrimSpace(input.Secret),
+		Name:        strings.TrimSpace(input.Name),
+		Vendor:      strings.TrimSpace(input.Vendor),
+		NetworkName: strings.TrimSpace(input.NetworkName),
 	}
 }
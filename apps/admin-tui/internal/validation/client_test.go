@@ -1,6 +1,9 @@
 package validation
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestValidateIPv4(t *testing.T) {
 	tests := []struct {
@@ -97,6 +100,29 @@ func TestValidateVendor(t *testing.T) {
 	}
 }
 
+func TestValidateNetworkName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"WLAN", "WLAN", false},
+		{"5G network name", "5G:mnc001.mcc001.3gppnetwork.org", false},
+		{"contains space", "Access Net", true},
+		{"too long", strings.Repeat("a", MaxNetworkNameLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNetworkName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNetworkName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateClient(t *testing.T) {
 	t.Run("valid input", func(t *testing.T) {
 		input := &ClientInput{
@@ -2,10 +2,13 @@ package validation
 
 import (
 	"fmt"
+	"net"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/replyattr"
 )
 
 // PolicyValidationError はポリシーバリデーションエラーを表す。
@@ -99,6 +102,142 @@ func ValidateSessionTimeout(timeout int) error {
 	return nil
 }
 
+// ValidateMaxSessions は同時セッション数上限のバリデーションを行う（0は全体設定に従う）。
+func ValidateMaxSessions(n int) error {
+	if n < 0 {
+		return &PolicyValidationError{Field: "MaxSessions", Message: "must be non-negative"}
+	}
+	if n > MaxConcurrentSessions {
+		return &PolicyValidationError{Field: "MaxSessions", Message: fmt.Sprintf("must be at most %d", MaxConcurrentSessions)}
+	}
+	return nil
+}
+
+// ValidateNasIPs はNAS IPアドレスまたはCIDRのリストのバリデーションを行う（空は未設定）。
+func ValidateNasIPs(nasIPs []string) error {
+	for i, entry := range nasIPs {
+		if _, _, err := net.ParseCIDR(entry); err == nil {
+			continue
+		}
+		if net.ParseIP(entry) == nil {
+			return &PolicyValidationError{
+				Field:   fmt.Sprintf("NasIPs[%d]", i),
+				Message: "must be a valid IP address or CIDR",
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateMACList はMACアドレスのリストのバリデーションを行う（空は未設定）。
+func ValidateMACList(field string, macs []string) error {
+	for i, mac := range macs {
+		if !MACAddressPattern.MatchString(mac) {
+			return &PolicyValidationError{
+				Field:   fmt.Sprintf("%s[%d]", field, i),
+				Message: "must be a valid MAC address",
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateNasPortTypes はNAS-Port-Typeのリストのバリデーションを行う（空は未設定）。
+func ValidateNasPortTypes(portTypes []int) error {
+	for i, t := range portTypes {
+		if t < 0 {
+			return &PolicyValidationError{
+				Field:   fmt.Sprintf("NasPortTypes[%d]", i),
+				Message: "must be non-negative",
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateDays は曜日のリストのバリデーションを行う（空は未設定）。
+func ValidateDays(days []string) error {
+	for i, day := range days {
+		if !slices.Contains(Weekdays, day) {
+			return &PolicyValidationError{
+				Field:   fmt.Sprintf("Days[%d]", i),
+				Message: "must be one of " + strings.Join(Weekdays, ", "),
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateTimeOfDay は時間帯の開始・終了時刻のバリデーションを行う（空は未設定）。
+func ValidateTimeOfDay(field, t string) error {
+	if t == "" {
+		return nil
+	}
+	if !TimeOfDayPattern.MatchString(t) {
+		return &PolicyValidationError{Field: field, Message: "must be in HH:MM format"}
+	}
+	return nil
+}
+
+// ValidateReplyAttributes は応答属性のリストのバリデーションを行う（空は未設定）。
+func ValidateReplyAttributes(attrs []model.ReplyAttribute) error {
+	for i, attr := range attrs {
+		field := fmt.Sprintf("ReplyAttributes[%d]", i)
+		def, ok := replyattr.Lookup(attr.Name)
+		if !ok {
+			return &PolicyValidationError{Field: field, Message: fmt.Sprintf("unsupported attribute %q", attr.Name)}
+		}
+		if !strings.EqualFold(attr.Vendor, def.Vendor) {
+			return &PolicyValidationError{Field: field, Message: fmt.Sprintf("vendor of %s must be %q", def.Name, def.Vendor)}
+		}
+		if attr.Value == "" {
+			return &PolicyValidationError{Field: field, Message: "value is required"}
+		}
+		if def.Integer {
+			if _, ok := def.IntegerValue(attr.Value); !ok {
+				return &PolicyValidationError{Field: field, Message: fmt.Sprintf("value of %s must be an unsigned 32-bit integer", def.Name)}
+			}
+			continue
+		}
+		maxLen := MaxReplyAttributeLength
+		if def.Vendor != "" {
+			maxLen = MaxVendorReplyAttributeLength
+		}
+		if len(attr.Value) > maxLen {
+			return &PolicyValidationError{Field: field, Message: fmt.Sprintf("value of %s must be at most %d characters", def.Name, maxLen)}
+		}
+	}
+	return nil
+}
+
+// ValidateRuleAction はルールの動作と拒否理由のバリデーションを行う（動作が空の場合は"allow"）。
+func ValidateRuleAction(action, rejectReason string) error {
+	if action != "" && !slices.Contains(RuleActions, action) {
+		return &PolicyValidationError{Field: "Action", Message: "must be one of " + strings.Join(RuleActions, ", ")}
+	}
+	if action != model.RuleActionReject {
+		if rejectReason != "" {
+			return &PolicyValidationError{Field: "RejectReason", Message: "can only be set when action is 'reject'"}
+		}
+		return nil
+	}
+	if !slices.Contains(RejectReasons, rejectReason) {
+		return &PolicyValidationError{Field: "RejectReason", Message: "must be one of " + strings.Join(RejectReasons, ", ")}
+	}
+	return nil
+}
+
+// ValidateRulePriority はルールの評価順のバリデーションを行う。
+func ValidateRulePriority(priority int) error {
+	if priority < 0 {
+		return &PolicyValidationError{Field: "Priority", Message: "must be non-negative"}
+	}
+	if priority > MaxRulePriority {
+		return &PolicyValidationError{Field: "Priority", Message: fmt.Sprintf("must be at most %d", MaxRulePriority)}
+	}
+	return nil
+}
+
 // ValidatePolicyRule はポリシールールのバリデーションを行う。
 func ValidatePolicyRule(rule *model.PolicyRule) []error {
 	var errs []error
@@ -115,15 +254,47 @@ func ValidatePolicyRule(rule *model.PolicyRule) []error {
 	if err := ValidateSessionTimeout(rule.SessionTimeout); err != nil {
 		errs = append(errs, err)
 	}
+	if err := ValidateNasIPs(rule.NasIPs); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateMACList("CallingStationAllow", rule.CallingStationAllow); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateMACList("CallingStationDeny", rule.CallingStationDeny); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateNasPortTypes(rule.NasPortTypes); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateDays(rule.Days); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateTimeOfDay("TimeStart", rule.TimeStart); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateTimeOfDay("TimeEnd", rule.TimeEnd); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateReplyAttributes(rule.ReplyAttributes); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateRuleAction(rule.Action, rule.RejectReason); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateRulePriority(rule.Priority); err != nil {
+		errs = append(errs, err)
+	}
 
 	return errs
 }
 
 // PolicyInput はポリシーの入力データを表す。
 type PolicyInput struct {
-	IMSI    string
-	Default string
-	Rules   []model.PolicyRule
+	IMSI        string
+	Default     string
+	Rules       []model.PolicyRule
+	MaxSessions int
+	Profile     string
 }
 
 // ValidatePolicy はポリシーデータの全体バリデーションを行う。
@@ -133,11 +304,65 @@ func ValidatePolicy(input *PolicyInput) []error {
 	if err := ValidateIMSI(input.IMSI); err != nil {
 		errs = append(errs, &PolicyValidationError{Field: "IMSI", Message: err.Error()})
 	}
-	if err := ValidateDefaultAction(input.Default); err != nil {
+
+	return append(errs, validatePolicySettings(input)...)
+}
+
+// ValidateIMSIPrefix はPLMN・IMSIプレフィックスのバリデーションを行う。
+func ValidateIMSIPrefix(prefix string) error {
+	if prefix == "" {
+		return &PolicyValidationError{Field: "Prefix", Message: "required"}
+	}
+	if !IMSIPrefixPattern.MatchString(prefix) {
+		return &PolicyValidationError{Field: "Prefix", Message: "must be 1-14 digits"}
+	}
+	return nil
+}
+
+// ValidatePrefixPolicy はPLMN・IMSIプレフィックスのポリシーのバリデーションを行う（IMSIにプレフィックスを指定する）。
+func ValidatePrefixPolicy(input *PolicyInput) []error {
+	var errs []error
+
+	if err := ValidateIMSIPrefix(input.IMSI); err != nil {
 		errs = append(errs, err)
 	}
 
-	for i, rule := range input.Rules {
+	return append(errs, validatePolicySettings(input)...)
+}
+
+// ValidateGlobalPolicy は全体の既定ポリシーのバリデーションを行う（IMSIは使用しない）。
+func ValidateGlobalPolicy(input *PolicyInput) []error {
+	return validatePolicySettings(input)
+}
+
+// validatePolicySettings はIMSI以外のポリシー設定のバリデーションを行う。
+func validatePolicySettings(input *PolicyInput) []error {
+	var errs []error
+
+	// プロファイル参照時はDefaultの省略（プロファイルに従う）を許可
+	if input.Profile != "" {
+		if err := ValidateProfileName(input.Profile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if input.Profile == "" || input.Default != "" {
+		if err := ValidateDefaultAction(input.Default); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := ValidateMaxSessions(input.MaxSessions); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, validateRules(input.Rules)...)
+
+	return errs
+}
+
+// validateRules はルールのリストのバリデーションを行う（フィールド名にルールの位置を付与する）。
+func validateRules(rules []model.PolicyRule) []error {
+	var errs []error
+	for i, rule := range rules {
 		ruleErrs := ValidatePolicyRule(&rule)
 		for _, e := range ruleErrs {
 			if pe, ok := e.(*PolicyValidationError); ok {
@@ -150,16 +375,17 @@ func ValidatePolicy(input *PolicyInput) []error {
 			}
 		}
 	}
-
 	return errs
 }
 
 // NormalizePolicyInput は入力データを正規化する。
 func NormalizePolicyInput(input *PolicyInput) *PolicyInput {
 	normalized := &PolicyInput{
-		IMSI:    strings.TrimSpace(input.IMSI),
-		Default: strings.ToLower(strings.TrimSpace(input.Default)),
-		Rules:   make([]model.PolicyRule, len(input.Rules)),
+		IMSI:        strings.TrimSpace(input.IMSI),
+		Default:     strings.ToLower(strings.TrimSpace(input.Default)),
+		Rules:       make([]model.PolicyRule, len(input.Rules)),
+		MaxSessions: input.MaxSessions,
+		Profile:     strings.TrimSpace(input.Profile),
 	}
 
 	for i, rule := range input.Rules {
@@ -173,8 +399,50 @@ func NormalizePolicyInput(input *PolicyInput) *PolicyInput {
 			AllowedSSIDs:   normalizedSSIDs,
 			VlanID:         rule.VlanID,
 			SessionTimeout: rule.SessionTimeout,
+
+			NasIPs:              normalizeList(rule.NasIPs, strings.TrimSpace),
+			CallingStationAllow: normalizeList(rule.CallingStationAllow, strings.TrimSpace),
+			CallingStationDeny:  normalizeList(rule.CallingStationDeny, strings.TrimSpace),
+			NasPortTypes:        rule.NasPortTypes,
+			Days:                normalizeList(rule.Days, func(s string) string { return strings.ToLower(strings.TrimSpace(s)) }),
+			TimeStart:           strings.TrimSpace(rule.TimeStart),
+			TimeEnd:             strings.TrimSpace(rule.TimeEnd),
+
+			ReplyAttributes: normalizeReplyAttributes(rule.ReplyAttributes),
+
+			Action:       strings.ToLower(strings.TrimSpace(rule.Action)),
+			RejectReason: strings.ToLower(strings.TrimSpace(rule.RejectReason)),
+			Priority:     rule.Priority,
 		}
 	}
 
 	return normalized
 }
+
+// normalizeList はリストの各要素を正規化する（nilはnilのまま返す）。
+func normalizeList(list []string, normalize func(string) string) []string {
+	if list == nil {
+		return nil
+	}
+	normalized := make([]string, len(list))
+	for i, v := range list {
+		normalized[i] = normalize(v)
+	}
+	return normalized
+}
+
+// normalizeReplyAttributes は応答属性の各項目の前後の空白を除去する（nilはnilのまま返す）。
+func normalizeReplyAttributes(attrs []model.ReplyAttribute) []model.ReplyAttribute {
+	if attrs == nil {
+		return nil
+	}
+	normalized := make([]model.ReplyAttribute, len(attrs))
+	for i, attr := range attrs {
+		normalized[i] = model.ReplyAttribute{
+			Name:   strings.TrimSpace(attr.Name),
+			Vendor: strings.TrimSpace(attr.Vendor),
+			Value:  strings.TrimSpace(attr.Value),
+		}
+	}
+	return normalized
+}
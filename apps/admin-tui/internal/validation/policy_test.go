@@ -138,6 +138,194 @@ func TestValidateSessionTimeout(t *testing.T) {
 	}
 }
 
+func TestValidateMaxSessions(t *testing.T) {
+	tests := []struct {
+		input   int
+		wantErr bool
+	}{
+		{0, false},
+		{1, false},
+		{100, false},
+		{-1, true},
+		{101, true},
+	}
+
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			err := ValidateMaxSessions(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMaxSessions(%d) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNasIPs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"ipv4", []string{"192.168.1.1"}, false},
+		{"cidr", []string{"10.0.0.0/8", "2001:db8::/32"}, false},
+		{"invalid ip", []string{"10.0.0.256"}, true},
+		{"invalid cidr", []string{"10.0.0.0/33"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNasIPs(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNasIPs(%v) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMACList(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"hyphen", []string{"AA-BB-CC-DD-EE-FF"}, false},
+		{"colon", []string{"aa:bb:cc:dd:ee:ff"}, false},
+		{"dotted", []string{"aabb.ccdd.eeff"}, false},
+		{"plain", []string{"aabbccddeeff"}, false},
+		{"short", []string{"AA-BB-CC-DD-EE"}, true},
+		{"non-hex", []string{"GG-BB-CC-DD-EE-FF"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMACList("CallingStationAllow", tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMACList(%v) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNasPortTypes(t *testing.T) {
+	if err := ValidateNasPortTypes([]int{0, 15, 19}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateNasPortTypes([]int{-1}); err == nil {
+		t.Error("expected error for negative NAS-Port-Type")
+	}
+}
+
+func TestValidateDays(t *testing.T) {
+	if err := ValidateDays([]string{"mon", "sun"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateDays([]string{"monday"}); err == nil {
+		t.Error("expected error for invalid day")
+	}
+}
+
+func TestValidateTimeOfDay(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"", false},
+		{"00:00", false},
+		{"23:59", false},
+		{"9:00", true},
+		{"24:00", true},
+		{"12:60", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := ValidateTimeOfDay("TimeStart", tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTimeOfDay(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReplyAttributes(t *testing.T) {
+	long := make([]byte, MaxVendorReplyAttributeLength+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+
+	tests := []struct {
+		name    string
+		attrs   []model.ReplyAttribute
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"string attribute", []model.ReplyAttribute{{Name: "Filter-Id", Value: "staff"}}, false},
+		{"integer attribute", []model.ReplyAttribute{{Name: "Idle-Timeout", Value: "600"}}, false},
+		{"enum name", []model.ReplyAttribute{{Name: "termination-action", Value: "RADIUS-Request"}}, false},
+		{"vendor attribute", []model.ReplyAttribute{{Name: "WISPr-Bandwidth-Max-Up", Vendor: "wispr", Value: "1000000"}}, false},
+		{"unsupported attribute", []model.ReplyAttribute{{Name: "Framed-IP-Address", Value: "10.0.0.1"}}, true},
+		{"missing vendor", []model.ReplyAttribute{{Name: "WISPr-Bandwidth-Max-Up", Value: "1000000"}}, true},
+		{"unexpected vendor", []model.ReplyAttribute{{Name: "Filter-Id", Vendor: "Aruba", Value: "staff"}}, true},
+		{"empty value", []model.ReplyAttribute{{Name: "Filter-Id"}}, true},
+		{"non-numeric integer", []model.ReplyAttribute{{Name: "Acct-Interim-Interval", Value: "5m"}}, true},
+		{"integer overflow", []model.ReplyAttribute{{Name: "Idle-Timeout", Value: "4294967296"}}, true},
+		{"vendor value too long", []model.ReplyAttribute{{Name: "Aruba-User-Role", Vendor: "Aruba", Value: string(long)}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReplyAttributes(tt.attrs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReplyAttributes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRuleAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		action       string
+		rejectReason string
+		wantErr      bool
+	}{
+		{"empty action", "", "", false},
+		{"allow", "allow", "", false},
+		{"deny", "deny", "", false},
+		{"reject with reason", "reject", "not_subscribed", false},
+		{"unknown action", "block", "", true},
+		{"reject without reason", "reject", "", true},
+		{"reject with unknown reason", "reject", "quota", true},
+		{"reason without reject", "deny", "temporarily_denied", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRuleAction(tt.action, tt.rejectReason)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRuleAction(%q, %q) error = %v, wantErr %v", tt.action, tt.rejectReason, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRulePriority(t *testing.T) {
+	if err := ValidateRulePriority(0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateRulePriority(MaxRulePriority); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateRulePriority(-1); err == nil {
+		t.Error("expected error for negative priority")
+	}
+	if err := ValidateRulePriority(MaxRulePriority + 1); err == nil {
+		t.Error("expected error for priority over maximum")
+	}
+}
+
 func TestValidatePolicyRule(t *testing.T) {
 	t.Run("valid rule", func(t *testing.T) {
 		rule := &model.PolicyRule{
@@ -164,6 +352,42 @@ func TestValidatePolicyRule(t *testing.T) {
 			t.Errorf("expected 4 errors, got %d", len(errs))
 		}
 	})
+
+	t.Run("valid match criteria", func(t *testing.T) {
+		rule := &model.PolicyRule{
+			NasID:               "ap-*",
+			AllowedSSIDs:        []string{"*"},
+			NasIPs:              []string{"10.0.0.0/24"},
+			CallingStationAllow: []string{"AA-BB-CC-DD-EE-FF"},
+			CallingStationDeny:  []string{"11:22:33:44:55:66"},
+			NasPortTypes:        []int{19},
+			Days:                []string{"mon", "fri"},
+			TimeStart:           "22:00",
+			TimeEnd:             "06:00",
+		}
+		errs := ValidatePolicyRule(rule)
+		if len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("invalid match criteria", func(t *testing.T) {
+		rule := &model.PolicyRule{
+			NasID:               "*",
+			AllowedSSIDs:        []string{"*"},
+			NasIPs:              []string{"nas-01"},
+			CallingStationAllow: []string{"invalid"},
+			CallingStationDeny:  []string{"invalid"},
+			NasPortTypes:        []int{-1},
+			Days:                []string{"holiday"},
+			TimeStart:           "25:00",
+			TimeEnd:             "6am",
+		}
+		errs := ValidatePolicyRule(rule)
+		if len(errs) != 7 {
+			t.Errorf("expected 7 errors, got %d: %v", len(errs), errs)
+		}
+	})
 }
 
 func TestValidatePolicy(t *testing.T) {
@@ -207,6 +431,27 @@ func TestValidatePolicy(t *testing.T) {
 			t.Errorf("expected 2 errors, got %d", len(errs))
 		}
 	})
+
+	t.Run("profile reference without default", func(t *testing.T) {
+		input := &PolicyInput{
+			IMSI:    "440101234567890",
+			Profile: "staff",
+		}
+		if errs := ValidatePolicy(input); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("invalid profile reference", func(t *testing.T) {
+		input := &PolicyInput{
+			IMSI:    "440101234567890",
+			Default: "invalid",
+			Profile: "bad:name",
+		}
+		if errs := ValidatePolicy(input); len(errs) != 2 {
+			t.Errorf("expected 2 errors, got %v", errs)
+		}
+	})
 }
 
 func TestNormalizePolicyInput(t *testing.T) {
@@ -218,6 +463,9 @@ func TestNormalizePolicyInput(t *testing.T) {
 				NasID:        "  *  ",
 				AllowedSSIDs: []string{"  ssid1  ", "  ssid2  "},
 				VlanID:       "100",
+				NasIPs:       []string{" 10.0.0.0/24 "},
+				Days:         []string{" Mon "},
+				TimeStart:    " 09:00 ",
 			},
 		},
 	}
@@ -236,4 +484,53 @@ func TestNormalizePolicyInput(t *testing.T) {
 	if normalized.Rules[0].AllowedSSIDs[0] != "ssid1" {
 		t.Errorf("expected AllowedSSIDs[0] 'ssid1', got '%s'", normalized.Rules[0].AllowedSSIDs[0])
 	}
+	if normalized.Rules[0].NasIPs[0] != "10.0.0.0/24" {
+		t.Errorf("expected NasIPs[0] '10.0.0.0/24', got '%s'", normalized.Rules[0].NasIPs[0])
+	}
+	if normalized.Rules[0].Days[0] != "mon" {
+		t.Errorf("expected Days[0] 'mon', got '%s'", normalized.Rules[0].Days[0])
+	}
+	if normalized.Rules[0].TimeStart != "09:00" {
+		t.Errorf("expected TimeStart '09:00', got '%s'", normalized.Rules[0].TimeStart)
+	}
+	if normalized.Rules[0].CallingStationAllow != nil {
+		t.Errorf("expected CallingStationAllow nil, got %v", normalized.Rules[0].CallingStationAllow)
+	}
+}
+
+func TestValidateIMSIPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{"4", false},
+		{"44010", false},
+		{"00101012345678", false},
+		{"", true},
+		{"001010123456789", true},
+		{"4401a", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			err := ValidateIMSIPrefix(tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIMSIPrefix(%q) error = %v, wantErr %v", tt.prefix, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFallbackPolicy(t *testing.T) {
+	if errs := ValidatePrefixPolicy(&PolicyInput{IMSI: "44010", Default: "deny"}); len(errs) != 0 {
+		t.Errorf("ValidatePrefixPolicy() expected no errors, got %v", errs)
+	}
+	if errs := ValidatePrefixPolicy(&PolicyInput{IMSI: "440101234567890", Default: "deny"}); len(errs) != 1 {
+		t.Errorf("ValidatePrefixPolicy() expected 1 error for full IMSI, got %v", errs)
+	}
+	if errs := ValidateGlobalPolicy(&PolicyInput{Profile: "visitor"}); len(errs) != 0 {
+		t.Errorf("ValidateGlobalPolicy() expected no errors, got %v", errs)
+	}
+	if errs := ValidateGlobalPolicy(&PolicyInput{}); len(errs) != 1 {
+		t.Errorf("ValidateGlobalPolicy() expected 1 error without default, got %v", errs)
+	}
 }
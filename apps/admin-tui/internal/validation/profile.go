@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+)
+
+// ValidateProfileName はポリシープロファイル名のバリデーションを行う。
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return &PolicyValidationError{Field: "Profile", Message: "required"}
+	}
+	if !ProfileNamePattern.MatchString(name) {
+		return &PolicyValidationError{Field: "Profile", Message: "must be 1-64 characters of letters, digits, hyphens or underscores"}
+	}
+	return nil
+}
+
+// ValidateIMSIRanges はIMSI範囲のリストのバリデーションを行う（空は未設定）。
+// 他のプロファイルとの重複はProfileStoreで確認する。
+func ValidateIMSIRanges(ranges []model.IMSIRange) error {
+	for i, r := range ranges {
+		field := fmt.Sprintf("IMSIRanges[%d]", i)
+		if !IMSIPattern.MatchString(r.Start) || !IMSIPattern.MatchString(r.End) {
+			return &PolicyValidationError{Field: field, Message: "start and end must be 15 digits"}
+		}
+		if r.Start > r.End {
+			return &PolicyValidationError{Field: field, Message: "start must not be greater than end"}
+		}
+		for j := range i {
+			if r.Overlaps(ranges[j]) {
+				return &PolicyValidationError{Field: field, Message: fmt.Sprintf("overlaps with IMSIRanges[%d]", j)}
+			}
+		}
+	}
+	return nil
+}
+
+// ProfileInput はポリシープロファイルの入力データを表す。
+type ProfileInput struct {
+	Name        string
+	Description string
+	Default     string
+	Rules       []model.PolicyRule
+	MaxSessions int
+	IMSIRanges  []model.IMSIRange
+}
+
+// ValidateProfile はポリシープロファイルデータの全体バリデーションを行う。
+func ValidateProfile(input *ProfileInput) []error {
+	var errs []error
+
+	if err := ValidateProfileName(input.Name); err != nil {
+		errs = append(errs, err)
+	}
+	if len(input.Description) > MaxProfileDescriptionLength {
+		errs = append(errs, &PolicyValidationError{Field: "Description", Message: fmt.Sprintf("must be at most %d characters", MaxProfileDescriptionLength)})
+	}
+	if err := ValidateDefaultAction(input.Default); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateMaxSessions(input.MaxSessions); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateIMSIRanges(input.IMSIRanges); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, validateRules(input.Rules)...)
+
+	return errs
+}
@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/model"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"staff", false},
+		{"iot_devices-01", false},
+		{"", true},
+		{"bad:name", true},
+		{"with space", true},
+		{strings.Repeat("a", 65), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfileName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfileName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateIMSIRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		ranges  []model.IMSIRange
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"single", []model.IMSIRange{{Start: "440100000000000", End: "440100000009999"}}, false},
+		{"single IMSI", []model.IMSIRange{{Start: "440100000000000", End: "440100000000000"}}, false},
+		{"disjoint", []model.IMSIRange{
+			{Start: "440100000000000", End: "440100000009999"},
+			{Start: "440100000010000", End: "440100000019999"},
+		}, false},
+		{"short IMSI", []model.IMSIRange{{Start: "44010000000000", End: "440100000009999"}}, true},
+		{"start after end", []model.IMSIRange{{Start: "440100000009999", End: "440100000000000"}}, true},
+		{"overlapping", []model.IMSIRange{
+			{Start: "440100000000000", End: "440100000009999"},
+			{Start: "440100000005000", End: "440100000019999"},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIMSIRanges(tt.ranges)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIMSIRanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	t.Run("valid profile", func(t *testing.T) {
+		input := &ProfileInput{
+			Name:       "staff",
+			Default:    "deny",
+			Rules:      []model.PolicyRule{{NasID: "*", AllowedSSIDs: []string{"ssid1"}}},
+			IMSIRanges: []model.IMSIRange{{Start: "440100000000000", End: "440100000009999"}},
+		}
+		if errs := ValidateProfile(input); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("invalid profile", func(t *testing.T) {
+		input := &ProfileInput{
+			Name:        "",
+			Description: strings.Repeat("x", MaxProfileDescriptionLength+1),
+			Default:     "",
+			Rules:       []model.PolicyRule{{NasID: "*"}},
+		}
+		if errs := ValidateProfile(input); len(errs) != 4 {
+			t.Errorf("expected 4 errors, got %v", errs)
+		}
+	})
+}
@@ -7,6 +7,8 @@ import "regexp"
 var (
 	// IMSIPattern はIMSI形式（15桁の数字）
 	IMSIPattern = regexp.MustCompile(`^[0-9]{15}$`)
+	// IMSIPrefixPattern はPLMN・IMSIプレフィックス形式（1-14桁の数字）
+	IMSIPrefixPattern = regexp.MustCompile(`^[0-9]{1,14}$`)
 
 	// KiPattern はKi形式（32桁の16進数）
 	KiPattern = regexp.MustCompile(`^[0-9A-Fa-f]{32}$`)
@@ -29,24 +31,40 @@ var (
 	// ClientNamePattern はクライアント名形式（1-64文字の英数字、ハイフン、アンダースコア）
 	ClientNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
 
+	// ProfileNamePattern はポリシープロファイル名形式（1-64文字の英数字、ハイフン、アンダースコア）
+	ProfileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
 	// VendorPattern はベンダー名形式（0-64文字の英数字、スペース、ハイフン）
 	VendorPattern = regexp.MustCompile(`^[a-zA-Z0-9 -]{0,64}$`)
 
+	// NetworkNamePattern はEAP-AKA'のアクセスネットワーク名形式（0-253文字のASCII印字可能文字）
+	NetworkNamePattern = regexp.MustCompile(`^[\x21-\x7E]{0,253}$`)
+
 	// NasIDPattern はNAS ID形式（1-253文字、ワイルドカード*可）
 	NasIDPattern = regexp.MustCompile(`^[\x21-\x7E*]{1,253}$`)
 
 	// SSIDPattern はSSID形式（1-32文字）
 	SSIDPattern = regexp.MustCompile(`^.{1,32}$`)
+
+	// MACAddressPattern はMACアドレス形式（区切りなし、:、-、またはaabb.ccdd.eeff形式）
+	MACAddressPattern = regexp.MustCompile(`^(?:[0-9A-Fa-f]{2}(?:[-:]?[0-9A-Fa-f]{2}){5}|(?:[0-9A-Fa-f]{4}\.){2}[0-9A-Fa-f]{4})$`)
+
+	// TimeOfDayPattern は時刻形式（HH:MM、24時間制）
+	TimeOfDayPattern = regexp.MustCompile(`^(?:[01][0-9]|2[0-3]):[0-5][0-9]$`)
 )
 
 // 定数
 const (
 	// MaxSecretLength はシークレットの最大長
 	MaxSecretLength = 128
+	// MaxStatusReasonLength は加入者の利用状態の理由の最大長
+	MaxStatusReasonLength = 128
 	// MaxClientNameLength はクライアント名の最大長
 	MaxClientNameLength = 64
 	// MaxVendorLength はベンダー名の最大長
 	MaxVendorLength = 64
+	// MaxNetworkNameLength はアクセスネットワーク名の最大長
+	MaxNetworkNameLength = 253
 	// MaxSSIDLength はSSIDの最大長
 	MaxSSIDLength = 32
 	// MaxNasIDLength はNAS IDの最大長
@@ -55,4 +73,23 @@ const (
 	MaxVlanID = 4094
 	// MaxSessionTimeout はセッションタイムアウトの最大値（24時間）
 	MaxSessionTimeout = 86400
+	// MaxConcurrentSessions は加入者あたりの同時セッション数上限の最大値
+	MaxConcurrentSessions = 100
+	// MaxReplyAttributeLength は応答属性（文字列型）の値の最大長
+	MaxReplyAttributeLength = 253
+	// MaxVendorReplyAttributeLength はベンダー固有の応答属性（文字列型）の値の最大長
+	MaxVendorReplyAttributeLength = 247
+	// MaxRulePriority はポリシールールの評価順（Priority）の最大値
+	MaxRulePriority = 9999
+	// MaxProfileDescriptionLength はポリシープロファイルの説明の最大長
+	MaxProfileDescriptionLength = 128
 )
+
+// Weekdays はポリシールールの曜日条件に指定できる値
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// RuleActions はポリシールールの動作に指定できる値（空は"allow"）
+var RuleActions = []string{"allow", "deny", "reject"}
+
+// RejectReasons はポリシールールの拒否理由に指定できる値
+var RejectReasons = []string{"general_failure", "temporarily_denied", "not_subscribed"}
@@ -3,6 +3,10 @@ package validation
 import (
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/model"
 )
 
 // SubscriberValidationError は加入者バリデーションエラーを表す。
@@ -74,6 +78,44 @@ func ValidateSQN(sqn string) error {
 	return nil
 }
 
+// ValidateSubscriberStatus は利用状態のバリデーションを行う（空はactive扱い）。
+func ValidateSubscriberStatus(status string) error {
+	switch status {
+	case "", model.SubscriberStatusActive, model.SubscriberStatusSuspended, model.SubscriberStatusBarred:
+		return nil
+	}
+	return &SubscriberValidationError{Field: "Status", Message: "must be active, suspended or barred"}
+}
+
+// ValidateStatusReason は利用状態の理由のバリデーションを行う。
+func ValidateStatusReason(reason string) error {
+	if utf8.RuneCountInString(reason) > MaxStatusReasonLength {
+		return &SubscriberValidationError{Field: "Reason", Message: fmt.Sprintf("must be at most %d characters", MaxStatusReasonLength)}
+	}
+	return nil
+}
+
+// ValidateValidity は有効期間のバリデーションを行う。
+// いずれもRFC3339形式（空は制限なし）で、両方指定時はValid FromがValid Untilより前であること。
+func ValidateValidity(validFrom, validUntil string) error {
+	var from, until time.Time
+	var err error
+	if validFrom != "" {
+		if from, err = time.Parse(time.RFC3339, validFrom); err != nil {
+			return &SubscriberValidationError{Field: "Valid From", Message: "must be YYYY-MM-DD or RFC3339"}
+		}
+	}
+	if validUntil != "" {
+		if until, err = time.Parse(time.RFC3339, validUntil); err != nil {
+			return &SubscriberValidationError{Field: "Valid Until", Message: "must be YYYY-MM-DD or RFC3339"}
+		}
+	}
+	if validFrom != "" && validUntil != "" && !from.Before(until) {
+		return &SubscriberValidationError{Field: "Valid Until", Message: "must be after Valid From"}
+	}
+	return nil
+}
+
 // SubscriberInput は加入者の入力データを表す。
 type SubscriberInput struct {
 	IMSI string
@@ -81,6 +123,11 @@ type SubscriberInput struct {
 	OPc  string
 	AMF  string
 	SQN  string
+
+	Status       string
+	StatusReason string
+	ValidFrom    string
+	ValidUntil   string
 }
 
 // ValidateSubscriber は加入者データの全体バリデーションを行う。
@@ -102,6 +149,15 @@ func ValidateSubscriber(input *SubscriberInput) []error {
 	if err := ValidateSQN(input.SQN); err != nil {
 		errs = append(errs, err)
 	}
+	if err := ValidateSubscriberStatus(input.Status); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateStatusReason(input.StatusReason); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateValidity(input.ValidFrom, input.ValidUntil); err != nil {
+		errs = append(errs, err)
+	}
 
 	return errs
 }
@@ -114,5 +170,19 @@ func NormalizeSubscriberInput(input *SubscriberInput) *SubscriberInput {
 		OPc:  strings.ToUpper(strings.TrimSpace(input.OPc)),
 		AMF:  strings.ToUpper(strings.TrimSpace(input.AMF)),
 		SQN:  strings.ToUpper(strings.TrimSpace(input.SQN)),
+
+		Status:       strings.ToLower(strings.TrimSpace(input.Status)),
+		StatusReason: strings.TrimSpace(input.StatusReason),
+		ValidFrom:    normalizeDateTime(input.ValidFrom),
+		ValidUntil:   normalizeDateTime(input.ValidUntil),
+	}
+}
+
+// normalizeDateTime は日付のみの入力（YYYY-MM-DD）をUTCの0時のRFC3339形式に変換する。
+func normalizeDateTime(s string) string {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.UTC().Format(time.RFC3339)
 	}
+	return s
 }
@@ -1,6 +1,9 @@
 package validation
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestValidateIMSI(t *testing.T) {
 	tests := []struct {
@@ -111,6 +114,63 @@ func TestValidateSQN(t *testing.T) {
 	}
 }
 
+func TestValidateSubscriberStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{"", false},
+		{"active", false},
+		{"suspended", false},
+		{"barred", false},
+		{"disabled", true},
+		{"Active", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateSubscriberStatus(tt.status)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSubscriberStatus(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateStatusReason(t *testing.T) {
+	if err := ValidateStatusReason(strings.Repeat("あ", MaxStatusReasonLength)); err != nil {
+		t.Errorf("expected no error for %d characters, got %v", MaxStatusReasonLength, err)
+	}
+	if err := ValidateStatusReason(strings.Repeat("a", MaxStatusReasonLength+1)); err == nil {
+		t.Error("expected error for too long reason")
+	}
+}
+
+func TestValidateValidity(t *testing.T) {
+	tests := []struct {
+		name       string
+		validFrom  string
+		validUntil string
+		wantErr    bool
+	}{
+		{"empty", "", "", false},
+		{"from only", "2025-01-01T00:00:00Z", "", false},
+		{"until only", "", "2025-01-01T00:00:00+09:00", false},
+		{"range", "2025-01-01T00:00:00Z", "2026-01-01T00:00:00Z", false},
+		{"invalid from", "2025/01/01", "", true},
+		{"invalid until", "", "2025-01-01", true},
+		{"same time", "2025-01-01T00:00:00Z", "2025-01-01T00:00:00Z", true},
+		{"reversed", "2026-01-01T00:00:00Z", "2025-01-01T00:00:00Z", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValidity(tt.validFrom, tt.validUntil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateValidity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateSubscriber(t *testing.T) {
 	t.Run("valid input", func(t *testing.T) {
 		input := &SubscriberInput{
@@ -168,3 +228,27 @@ func TestNormalizeSubscriberInput(t *testing.T) {
 		t.Errorf("expected SQN '000000000000', got '%s'", normalized.SQN)
 	}
 }
+
+func TestNormalizeSubscriberInput_Status(t *testing.T) {
+	input := &SubscriberInput{
+		Status:       " Barred ",
+		StatusReason: "  lost SIM  ",
+		ValidFrom:    " 2025-01-01 ",
+		ValidUntil:   "2026-01-01T09:00:00+09:00",
+	}
+
+	normalized := NormalizeSubscriberInput(input)
+
+	if normalized.Status != "barred" {
+		t.Errorf("expected Status 'barred', got '%s'", normalized.Status)
+	}
+	if normalized.StatusReason != "lost SIM" {
+		t.Errorf("expected StatusReason 'lost SIM', got '%s'", normalized.StatusReason)
+	}
+	if normalized.ValidFrom != "2025-01-01T00:00:00Z" {
+		t.Errorf("expected ValidFrom '2025-01-01T00:00:00Z', got '%s'", normalized.ValidFrom)
+	}
+	if normalized.ValidUntil != "2026-01-01T09:00:00+09:00" {
+		t.Errorf("expected ValidUntil unchanged, got '%s'", normalized.ValidUntil)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui/monitoring"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui/policy"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui/subscriber"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/admin-tui/internal/ui/sucikey"
 	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/valkey"
 	"github.com/redis/go-redis/v9"
 	"github.com/rivo/tview"
@@ -32,8 +33,12 @@ type Application struct {
 	subscriberStore *store.SubscriberStore
 	clientStore     *store.ClientStore
 	policyStore     *store.PolicyStore
+	profileStore    *store.ProfileStore
+	fallbackStore   *store.FallbackPolicyStore
 	sessionStore    *store.SessionStore
+	lockoutStore    *store.LockoutStore
 	statisticsStore *store.StatisticsStore
+	suciKeyStore    *store.SUCIKeyStore
 }
 
 func main() {
@@ -84,13 +89,17 @@ func (a *Application) connectValkey() error {
 	a.subscriberStore = store.NewSubscriberStore(client)
 	a.clientStore = store.NewClientStore(client)
 	a.policyStore = store.NewPolicyStore(client)
+	a.profileStore = store.NewProfileStore(client)
+	a.fallbackStore = store.NewFallbackPolicyStore(client)
 	a.sessionStore = store.NewSessionStore(client)
+	a.lockoutStore = store.NewLockoutStore(client)
 	a.statisticsStore = store.NewStatisticsStore(
 		a.subscriberStore,
 		a.clientStore,
 		a.policyStore,
 		a.sessionStore,
 	)
+	a.suciKeyStore = store.NewSUCIKeyStore(a.cfg.SUCIKeyFile)
 
 	return nil
 }
@@ -131,7 +140,10 @@ func (a *Application) showMainMenu() {
 	menuItems[2].Action = a.showPolicyList
 	menuItems[3].Action = a.showImportExportMenu
 	menuItems[4].Action = a.showMonitoringMenu
-	menuItems[5].Action = func() {
+	menuItems[5].Action = a.showSUCIKeyList
+	menuItems[6].Action = a.showProfileList
+	menuItems[7].Action = a.showFallbackList
+	menuItems[8].Action = func() {
 		a.cleanup()
 		a.app.Stop()
 	}
@@ -244,7 +256,7 @@ func (a *Application) showSubscriberForm(editMode bool, imsi string) {
 		screen.SetupCreate()
 	}
 
-	a.app.AddPage("subscriber-form", centered(screen.GetForm(), 60, 15), true, true)
+	a.app.AddPage("subscriber-form", centered(screen.GetForm(), 60, 23), true, true)
 	a.app.SetFocus(screen.GetForm())
 }
 
@@ -293,7 +305,7 @@ func (a *Application) showClientList() {
 }
 
 func (a *Application) showClientForm(editMode bool, ip string) {
-	screen := client.NewFormScreen(a.app, a.clientStore, a.auditLogger)
+	screen := client.NewFormScreen(a.app, a.clientStore, a.profileStore, a.auditLogger)
 
 	screen.SetOnSave(func() {
 		a.app.HidePage("client-form")
@@ -317,7 +329,7 @@ func (a *Application) showClientForm(editMode bool, ip string) {
 		screen.SetupCreate()
 	}
 
-	a.app.AddPage("client-form", centered(screen.GetForm(), 60, 12), true, true)
+	a.app.AddPage("client-form", centered(screen.GetForm(), 60, 17), true, true)
 	a.app.SetFocus(screen.GetForm())
 }
 
@@ -366,7 +378,7 @@ func (a *Application) showPolicyList() {
 }
 
 func (a *Application) showPolicyForm(editMode bool, imsi string) {
-	screen := policy.NewFormScreen(a.app, a.policyStore, a.auditLogger)
+	screen := policy.NewFormScreen(a.app, a.policyStore, a.profileStore, a.auditLogger)
 
 	screen.SetOnSave(func() {
 		a.app.HidePage("policy-form")
@@ -394,6 +406,173 @@ func (a *Application) showPolicyForm(editMode bool, imsi string) {
 	a.app.SetFocus(screen.GetFlex())
 }
 
+// Policy Profiles
+func (a *Application) showProfileList() {
+	screen := policy.NewProfileListScreen(a.app, a.profileStore)
+
+	screen.SetOnCreate(func() {
+		a.showProfileForm(false, "")
+	})
+
+	screen.SetOnEdit(func(name string) {
+		a.showProfileForm(true, name)
+	})
+
+	screen.SetOnDelete(func(name string) {
+		a.showDeleteConfirm("profile", name, screen.GetTable(), func() {
+			ctx := context.Background()
+			if err := a.profileStore.Delete(ctx, name); err != nil {
+				a.app.GetStatusBar().ShowError("Failed to delete: " + err.Error())
+				return
+			}
+			a.auditLogger.LogDelete(audit.TargetProfile, store.ProfileKey(name), "")
+			a.app.GetStatusBar().ShowSuccess("Profile deleted: " + name)
+			_ = screen.Refresh(ctx)
+		})
+	})
+
+	screen.SetOnBack(func() {
+		a.app.HidePage("profile-list")
+		a.app.RemovePage("profile-list")
+		a.app.SwitchToPage("main-menu")
+	})
+
+	a.app.AddPage("profile-list", screen.GetTable(), true, false)
+	a.app.SwitchToPage("profile-list")
+	a.app.SetFocus(screen.GetTable())
+
+	go func() {
+		a.app.QueueUpdateDraw(func() {
+			if err := screen.Load(context.Background()); err != nil {
+				a.app.GetStatusBar().ShowError("Failed to load: " + err.Error())
+			}
+		})
+	}()
+}
+
+func (a *Application) showProfileForm(editMode bool, name string) {
+	screen := policy.NewProfileFormScreen(a.app, a.profileStore, a.auditLogger)
+
+	screen.SetOnSave(func() {
+		a.app.HidePage("profile-form")
+		a.app.RemovePage("profile-form")
+		a.app.RemovePage("profile-list")
+		a.showProfileList()
+	})
+
+	screen.SetOnCancel(func() {
+		a.app.HidePage("profile-form")
+		a.app.RemovePage("profile-form")
+		a.app.SwitchToPage("profile-list")
+	})
+
+	if editMode {
+		if err := screen.SetupEdit(context.Background(), name); err != nil {
+			a.app.GetStatusBar().ShowError("Failed to load profile: " + err.Error())
+			return
+		}
+	} else {
+		screen.SetupCreate()
+	}
+
+	a.app.AddPage("profile-form", screen.GetFlex(), true, true)
+	a.app.SetFocus(screen.GetFlex())
+}
+
+// Fallback Policies
+func (a *Application) showFallbackList() {
+	screen := policy.NewFallbackListScreen(a.app, a.fallbackStore, a.clientStore)
+
+	screen.SetOnCreate(func() {
+		a.showFallbackForm(func(f *policy.FormScreen) error {
+			f.SetupCreatePrefix()
+			return nil
+		})
+	})
+
+	screen.SetOnEditPrefix(func(prefix string) {
+		a.showFallbackForm(func(f *policy.FormScreen) error {
+			return f.SetupEditPrefix(context.Background(), prefix)
+		})
+	})
+
+	screen.SetOnEditGlobal(func() {
+		a.showFallbackForm(func(f *policy.FormScreen) error {
+			return f.SetupGlobal(context.Background())
+		})
+	})
+
+	screen.SetOnDelete(func(prefix string) {
+		if prefix == "" {
+			a.showDeleteConfirm("global default policy", store.KeyDefaultPolicy, screen.GetTable(), func() {
+				ctx := context.Background()
+				if err := a.fallbackStore.DeleteGlobal(ctx); err != nil {
+					a.app.GetStatusBar().ShowError("Failed to delete: " + err.Error())
+					return
+				}
+				a.auditLogger.LogDelete(audit.TargetPolicy, store.KeyDefaultPolicy, "")
+				a.app.GetStatusBar().ShowSuccess("Global default policy deleted")
+				_ = screen.Refresh(ctx)
+			})
+			return
+		}
+		a.showDeleteConfirm("prefix policy", prefix, screen.GetTable(), func() {
+			ctx := context.Background()
+			if err := a.fallbackStore.DeletePrefix(ctx, prefix); err != nil {
+				a.app.GetStatusBar().ShowError("Failed to delete: " + err.Error())
+				return
+			}
+			a.auditLogger.LogDelete(audit.TargetPolicy, store.PrefixPolicyKey(prefix), "")
+			a.app.GetStatusBar().ShowSuccess("Prefix policy deleted: " + prefix)
+			_ = screen.Refresh(ctx)
+		})
+	})
+
+	screen.SetOnBack(func() {
+		a.app.HidePage("fallback-list")
+		a.app.RemovePage("fallback-list")
+		a.app.SwitchToPage("main-menu")
+	})
+
+	a.app.AddPage("fallback-list", screen.GetTable(), true, false)
+	a.app.SwitchToPage("fallback-list")
+	a.app.SetFocus(screen.GetTable())
+
+	go func() {
+		a.app.QueueUpdateDraw(func() {
+			if err := screen.Load(context.Background()); err != nil {
+				a.app.GetStatusBar().ShowError("Failed to load: " + err.Error())
+			}
+		})
+	}()
+}
+
+// showFallbackForm はフォールバックポリシーのフォームを表示する（setupでプレフィックス・全体の既定ポリシーを選択する）。
+func (a *Application) showFallbackForm(setup func(*policy.FormScreen) error) {
+	screen := policy.NewFallbackFormScreen(a.app, a.fallbackStore, a.profileStore, a.auditLogger)
+
+	screen.SetOnSave(func() {
+		a.app.HidePage("fallback-form")
+		a.app.RemovePage("fallback-form")
+		a.app.RemovePage("fallback-list")
+		a.showFallbackList()
+	})
+
+	screen.SetOnCancel(func() {
+		a.app.HidePage("fallback-form")
+		a.app.RemovePage("fallback-form")
+		a.app.SwitchToPage("fallback-list")
+	})
+
+	if err := setup(screen); err != nil {
+		a.app.GetStatusBar().ShowError("Failed to load policy: " + err.Error())
+		return
+	}
+
+	a.app.AddPage("fallback-form", screen.GetFlex(), true, true)
+	a.app.SetFocus(screen.GetFlex())
+}
+
 // Import/Export
 func (a *Application) showImportExportMenu() {
 	list := ui.NewMainMenu([]ui.MenuItem{
@@ -451,12 +630,60 @@ func (a *Application) showExportScreen() {
 	a.app.SwitchToPage("export-screen")
 }
 
+// SUCI Home Network Keys
+func (a *Application) showSUCIKeyList() {
+	screen := sucikey.NewListScreen(a.app, a.suciKeyStore)
+
+	screen.SetOnCreate(a.showSUCIKeyForm)
+
+	screen.SetOnBack(func() {
+		a.app.HidePage("suci-key-list")
+		a.app.RemovePage("suci-key-list")
+		a.app.SwitchToPage("main-menu")
+	})
+
+	if err := screen.Load(); err != nil {
+		a.app.GetStatusBar().ShowError("Failed to load: " + err.Error())
+		return
+	}
+
+	a.app.AddPage("suci-key-list", screen.GetTable(), true, false)
+	a.app.SwitchToPage("suci-key-list")
+	a.app.SetFocus(screen.GetTable())
+}
+
+func (a *Application) showSUCIKeyForm() {
+	screen := sucikey.NewFormScreen(a.app, a.suciKeyStore, a.auditLogger)
+
+	screen.SetOnSave(func() {
+		a.app.HidePage("suci-key-form")
+		a.app.RemovePage("suci-key-form")
+		a.app.RemovePage("suci-key-list")
+		a.showSUCIKeyList()
+	})
+
+	screen.SetOnCancel(func() {
+		a.app.HidePage("suci-key-form")
+		a.app.RemovePage("suci-key-form")
+		a.app.SwitchToPage("suci-key-list")
+	})
+
+	if err := screen.Setup(); err != nil {
+		a.app.GetStatusBar().ShowError("Failed to load keys: " + err.Error())
+		return
+	}
+
+	a.app.AddPage("suci-key-form", centered(screen.GetForm(), 60, 9), true, true)
+	a.app.SetFocus(screen.GetForm())
+}
+
 // Monitoring
 func (a *Application) showMonitoringMenu() {
 	screen := monitoring.NewMenuScreen()
 
 	screen.SetOnStatistics(a.showStatistics)
 	screen.SetOnSessionList(a.showSessionList)
+	screen.SetOnLockoutList(a.showLockoutList)
 	screen.SetOnBack(func() {
 		a.app.SwitchToPage("main-menu")
 	})
@@ -529,6 +756,45 @@ func (a *Application) showSessionDetail() {
 	screen.ShowSearchDialog()
 }
 
+func (a *Application) showLockoutList() {
+	screen := monitoring.NewLockoutListScreen(a.app, a.lockoutStore)
+
+	screen.SetOnClear(func(lockout *store.Lockout) {
+		a.showDeleteConfirm(lockout.Kind+" lockout", lockout.Target, screen.GetTable(), func() {
+			ctx := context.Background()
+			if err := a.lockoutStore.Clear(ctx, lockout.Kind, lockout.Target); err != nil {
+				a.app.GetStatusBar().ShowError("Failed to clear: " + err.Error())
+				return
+			}
+			targetIMSI := ""
+			if lockout.Kind == store.LockoutKindIMSI {
+				targetIMSI = lockout.Target
+			}
+			a.auditLogger.LogDelete(audit.TargetLockout, store.LockoutKey(lockout.Kind, lockout.Target), targetIMSI)
+			a.app.GetStatusBar().ShowSuccess("Lockout cleared: " + lockout.Target)
+			_ = screen.Refresh(ctx)
+		})
+	})
+
+	screen.SetOnBack(func() {
+		a.app.HidePage("lockout-list")
+		a.app.RemovePage("lockout-list")
+		a.app.SwitchToPage("monitoring-menu")
+	})
+
+	a.app.AddPage("lockout-list", screen.GetTable(), true, false)
+	a.app.SwitchToPage("lockout-list")
+	a.app.SetFocus(screen.GetTable())
+
+	go func() {
+		a.app.QueueUpdateDraw(func() {
+			if err := screen.Load(context.Background()); err != nil {
+				a.app.GetStatusBar().ShowError("Failed to load: " + err.Error())
+			}
+		})
+	}()
+}
+
 // Helpers
 func (a *Application) showDeleteConfirm(targetType, identifier string, focusAfter tview.Primitive, onConfirm func()) {
 	dialog := ui.NewConfirmDialog(
@@ -1,10 +1,15 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
 )
 
 // Config はアプリケーション設定を保持する
@@ -16,13 +21,80 @@ type Config struct {
 
 	// Vector Gateway設定
 	VectorAPIURL string `envconfig:"VECTOR_API_URL" required:"true"`
+	// 認証ベクターの先行取得（0または1の場合は先行取得しない）
+	// 1回の呼び出しで取得するベクター数と、未使用分を暗号化して保持するキャッシュの有効期間・暗号鍵（AES-256、Hex 64文字）
+	VectorPrefetchCount int           `envconfig:"VECTOR_PREFETCH_COUNT" default:"1"`
+	VectorCacheTTL      time.Duration `envconfig:"VECTOR_CACHE_TTL" default:"5m"`
+	VectorCacheKey      string        `envconfig:"VECTOR_CACHE_KEY"`
 
 	// RADIUS設定
 	RadiusSecret string `envconfig:"RADIUS_SECRET"`
 	ListenAddr   string `envconfig:"LISTEN_ADDR" default:":1812"`
+	// 再送Access-Requestへ前回の応答を返す期間（0の場合は重複検出なし）
+	DupCacheTTL time.Duration `envconfig:"RADIUS_DUP_CACHE_TTL" default:"5s"`
+	// Access-Request 1件あたりの処理期限。NASの再送間隔以下に設定する（0の場合は期限なし）
+	RequestTimeout time.Duration `envconfig:"RADIUS_REQUEST_TIMEOUT" default:"3s"`
 
 	// EAP-AKA'設定
 	NetworkName string `envconfig:"EAP_AKA_PRIME_NETWORK_NAME" default:"WLAN"`
+	// SSID（Called-Station-Id）・Realm単位のネットワーク名（"キー=ネットワーク名"のカンマ区切り、キーは大文字小文字を区別しない）
+	SSIDNetworkNames  NameMap `envconfig:"EAP_AKA_PRIME_NETWORK_NAME_BY_SSID"`
+	RealmNetworkNames NameMap `envconfig:"EAP_AKA_PRIME_NETWORK_NAME_BY_REALM"`
+	// EAP-AKA-ChallengeでAT_BIDDINGを送信し、AKA'対応を通知する（ビッドダウン攻撃対策）
+	BiddingEnabled bool `envconfig:"EAP_AKA_BIDDING" default:"true"`
+
+	// 匿名・3GPPプレフィックスのない外側Identityに対するAKA-Identity交換
+	// EAP方式（"aka"/"aka-prime"）はRealm対応表 → 既定値の順に決定し、最初の要求は"any"/"fullauth"/"permanent"から選択する
+	AnonymousMethod        string  `envconfig:"EAP_ANONYMOUS_METHOD" default:"aka"`
+	AnonymousMethodByRealm NameMap `envconfig:"EAP_ANONYMOUS_METHOD_BY_REALM"`
+	AnonymousIDReq         string  `envconfig:"EAP_ANONYMOUS_ID_REQ" default:"any"`
+
+	// 高速再認証設定（0の場合は再認証IDを発行しない）
+	ReauthMaxCount    int           `envconfig:"EAP_REAUTH_MAX_COUNT" default:"5"`
+	ReauthKeyLifetime time.Duration `envconfig:"EAP_REAUTH_KEY_LIFETIME" default:"1h"`
+
+	// ERP（RFC 6696）設定（ドメイン名が空の場合はERPを行わない）
+	// keyName-NAIのRealmとして使用し、フル認証成功時のEMSKからrRKを導出して有効期間保持する
+	ERPDomain      string        `envconfig:"EAP_ERP_DOMAIN"`
+	ERPKeyLifetime time.Duration `envconfig:"EAP_ERP_KEY_LIFETIME" default:"8h"`
+
+	// 加入者あたりの同時セッション数の上限（0の場合は無制限。ポリシーのmax_sessionsが優先）
+	// 上限到達時は"reject"で拒否し、"evict"で最も古いセッションを削除して受け付ける
+	MaxSessionsPerUser int    `envconfig:"MAX_SESSIONS_PER_USER" default:"0"`
+	SessionLimitAction string `envconfig:"SESSION_LIMIT_ACTION" default:"reject"`
+
+	// 認証失敗によるIMSI単位のロックアウト（閾値が0の場合はロックアウトしない。既定は無効）
+	// MAC・RES検証失敗、再同期上限超過が計数期間内に閾値に達した場合、ロックアウト期間はベクター要求を行わずに拒否する
+	AuthLockoutThreshold int           `envconfig:"AUTH_LOCKOUT_THRESHOLD" default:"0"`
+	AuthLockoutWindow    time.Duration `envconfig:"AUTH_LOCKOUT_WINDOW" default:"10m"`
+	AuthLockoutDuration  time.Duration `envconfig:"AUTH_LOCKOUT_DURATION" default:"15m"`
+	// Message-Authenticator検証失敗によるNAS（送信元IP）単位のロックアウト（閾値が0の場合はロックアウトしない。既定は無効）
+	// 送信元IPは詐称できるため、RADIUSポートに到達できる第三者が正規のNASをロックアウトさせ得る。
+	// 有効にする場合はRADIUSポートへの到達を信頼できるネットワークに限定すること
+	NASLockoutThreshold int           `envconfig:"NAS_LOCKOUT_THRESHOLD" default:"0"`
+	NASLockoutWindow    time.Duration `envconfig:"NAS_LOCKOUT_WINDOW" default:"1m"`
+	NASLockoutDuration  time.Duration `envconfig:"NAS_LOCKOUT_DURATION" default:"5m"`
+
+	// トークンバケットによる要求レート制限（RATEは1秒あたりの補充数、BURSTはバケットの容量。RATEが0の場合は制限しない。既定は無効）
+	// RADIUSクライアント単位は全Access-Request、端末（Calling-Station-Id）・IMSI単位はStateを含まない認証開始要求に適用する
+	RateLimitClientRate   float64 `envconfig:"RATE_LIMIT_CLIENT_RATE" default:"0"`
+	RateLimitClientBurst  int     `envconfig:"RATE_LIMIT_CLIENT_BURST" default:"200"`
+	RateLimitStationRate  float64 `envconfig:"RATE_LIMIT_STATION_RATE" default:"0"`
+	RateLimitStationBurst int     `envconfig:"RATE_LIMIT_STATION_BURST" default:"10"`
+	RateLimitIMSIRate     float64 `envconfig:"RATE_LIMIT_IMSI_RATE" default:"0"`
+	RateLimitIMSIBurst    int     `envconfig:"RATE_LIMIT_IMSI_BURST" default:"10"`
+
+	// ポリシールールの曜日・時間帯条件を判定するタイムゾーン（IANA名、"Local"はシステムのタイムゾーン）
+	PolicyTimezone string `envconfig:"POLICY_TIMEZONE" default:"Local"`
+
+	// 保護された結果通知（AT_RESULT_IND）の提示
+	ResultIndEnabled bool `envconfig:"EAP_RESULT_IND" default:"true"`
+
+	// EAP-SIM設定（1回のChallengeで使用するAT_RANDの個数、2〜3。0はVector APIの既定値）
+	SIMRandCount int `envconfig:"EAP_SIM_RAND_COUNT" default:"3"`
+
+	// SUCI（秘匿化IMSI）のホームネットワーク秘密鍵ファイル（空の場合はECIES方式の秘匿化IDを受け付けない）
+	SUCIKeyFile string `envconfig:"SUCI_KEY_FILE"`
 
 	// ログ設定
 	LogMaskIMSI bool `envconfig:"LOG_MASK_IMSI" default:"true"`
@@ -45,13 +117,207 @@ func (c *Config) ValkeyAddr() string {
 	return fmt.Sprintf("%s:%s", c.RedisHost, c.RedisPort)
 }
 
+// 解決したネットワーク名の取得元
+const (
+	NetworkNameSourceClient  = "client"
+	NetworkNameSourceSSID    = "ssid"
+	NetworkNameSourceRealm   = "realm"
+	NetworkNameSourceDefault = "default"
+)
+
+// NetworkNameFor はSSID・Realmに対応するAT_KDF_INPUTのネットワーク名と取得元を返す
+// SSID・Realmの順に対応表を参照し、いずれにも該当しない場合は既定値を返す
+func (c *Config) NetworkNameFor(ssid, realm string) (name, source string) {
+	if name, ok := c.SSIDNetworkNames.Lookup(ssid); ok {
+		return name, NetworkNameSourceSSID
+	}
+	if name, ok := c.RealmNetworkNames.Lookup(realm); ok {
+		return name, NetworkNameSourceRealm
+	}
+	return c.NetworkName, NetworkNameSourceDefault
+}
+
+// 匿名Identity時のEAP方式の取得元
+const (
+	AnonymousMethodSourcePeer    = "peer"
+	AnonymousMethodSourceClient  = "client"
+	AnonymousMethodSourceRealm   = "realm"
+	AnonymousMethodSourceDefault = "default"
+)
+
+// AnonymousEAPType はRealmに対応する匿名Identity時のEAP方式と取得元を返す
+// Realm対応表に該当しない場合は既定値（EAP_ANONYMOUS_METHOD、未設定の場合はEAP-AKA）を返す
+func (c *Config) AnonymousEAPType(realm string) (uint8, string) {
+	if method, ok := c.AnonymousMethodByRealm.Lookup(realm); ok {
+		eapType, _ := parseEAPMethod(method)
+		return eapType, AnonymousMethodSourceRealm
+	}
+	eapType, ok := parseEAPMethod(c.AnonymousMethod)
+	if !ok {
+		eapType = eapaka.TypeAKA
+	}
+	return eapType, AnonymousMethodSourceDefault
+}
+
+// AnonymousIdentityRequest は匿名Identity時に最初に送信するAKA-Identity要求種別を返す（未設定の場合はAT_ANY_ID_REQ）
+func (c *Config) AnonymousIdentityRequest() eap.IdentityReqType {
+	if req, ok := eap.ParseIdentityReqType(c.AnonymousIDReq); ok {
+		return req
+	}
+	return eap.IdentityReqAny
+}
+
+// parseEAPMethod は設定値のEAP方式名（"aka"/"aka-prime"）をEAP Typeに変換する
+func parseEAPMethod(s string) (uint8, bool) {
+	switch strings.ToLower(s) {
+	case "aka":
+		return eapaka.TypeAKA, true
+	case "aka-prime", "aka'":
+		return eapaka.TypeAKAPrime, true
+	default:
+		return 0, false
+	}
+}
+
+// NameMap はキー（小文字に正規化）からネットワーク名への対応表
+// ネットワーク名に":"を含められるよう、環境変数は"キー=値"のカンマ区切りで指定する
+type NameMap map[string]string
+
+// Decode はenvconfig.Decoderの実装
+func (m *NameMap) Decode(value string) error {
+	parsed := NameMap{}
+	for pair := range strings.SplitSeq(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, name, found := strings.Cut(pair, "=")
+		key, name = strings.TrimSpace(key), strings.TrimSpace(name)
+		if !found || key == "" || name == "" {
+			return fmt.Errorf("invalid network name mapping: %q", pair)
+		}
+		parsed[strings.ToLower(key)] = name
+	}
+	*m = parsed
+	return nil
+}
+
+// Lookup はキーに対応するネットワーク名を返す（大文字小文字を区別しない）
+func (m NameMap) Lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	name, ok := m[strings.ToLower(key)]
+	return name, ok
+}
+
 // validate は設定値のバリデーションを行う
 func (c *Config) validate() error {
 	if strings.TrimSpace(c.NetworkName) == "" {
 		return fmt.Errorf("EAP_AKA_PRIME_NETWORK_NAME must not be empty")
 	}
+	if _, ok := parseEAPMethod(c.AnonymousMethod); c.AnonymousMethod != "" && !ok {
+		return fmt.Errorf("EAP_ANONYMOUS_METHOD must be aka or aka-prime")
+	}
+	for realm, method := range c.AnonymousMethodByRealm {
+		if _, ok := parseEAPMethod(method); !ok {
+			return fmt.Errorf("EAP_ANONYMOUS_METHOD_BY_REALM has invalid method %q for %q", method, realm)
+		}
+	}
+	if _, ok := eap.ParseIdentityReqType(c.AnonymousIDReq); c.AnonymousIDReq != "" && !ok {
+		return fmt.Errorf("EAP_ANONYMOUS_ID_REQ must be any, fullauth or permanent")
+	}
+	if c.ReauthMaxCount < 0 || c.ReauthMaxCount > math.MaxUint16 {
+		return fmt.Errorf("EAP_REAUTH_MAX_COUNT must be between 0 and %d", math.MaxUint16)
+	}
+	if c.ReauthMaxCount > 0 && c.ReauthKeyLifetime <= 0 {
+		return fmt.Errorf("EAP_REAUTH_KEY_LIFETIME must be positive")
+	}
+	if len(c.ERPDomain) > 255 {
+		return fmt.Errorf("EAP_ERP_DOMAIN must be at most 255 characters")
+	}
+	if c.ERPDomain != "" && c.ERPKeyLifetime <= 0 {
+		return fmt.Errorf("EAP_ERP_KEY_LIFETIME must be positive")
+	}
+	if c.DupCacheTTL < 0 {
+		return fmt.Errorf("RADIUS_DUP_CACHE_TTL must not be negative")
+	}
+	if c.RequestTimeout < 0 {
+		return fmt.Errorf("RADIUS_REQUEST_TIMEOUT must not be negative")
+	}
+	if c.VectorPrefetchCount < 0 || c.VectorPrefetchCount > MaxVectorPrefetchCount {
+		return fmt.Errorf("VECTOR_PREFETCH_COUNT must be between 0 and %d", MaxVectorPrefetchCount)
+	}
+	if c.VectorPrefetchCount > 1 {
+		if c.VectorCacheTTL <= 0 {
+			return fmt.Errorf("VECTOR_CACHE_TTL must be positive")
+		}
+		if key, err := hex.DecodeString(c.VectorCacheKey); err != nil || len(key) != 32 {
+			return fmt.Errorf("VECTOR_CACHE_KEY must be 64 hex characters")
+		}
+	}
+	if c.MaxSessionsPerUser < 0 {
+		return fmt.Errorf("MAX_SESSIONS_PER_USER must not be negative")
+	}
+	switch c.SessionLimitAction {
+	case "", SessionLimitActionReject, SessionLimitActionEvict:
+	default:
+		return fmt.Errorf("SESSION_LIMIT_ACTION must be reject or evict")
+	}
+	if err := validateLockout("AUTH_LOCKOUT", c.AuthLockoutThreshold, c.AuthLockoutWindow, c.AuthLockoutDuration); err != nil {
+		return err
+	}
+	if err := validateLockout("NAS_LOCKOUT", c.NASLockoutThreshold, c.NASLockoutWindow, c.NASLockoutDuration); err != nil {
+		return err
+	}
+	if err := validateRateLimit("RATE_LIMIT_CLIENT", c.RateLimitClientRate, c.RateLimitClientBurst); err != nil {
+		return err
+	}
+	if err := validateRateLimit("RATE_LIMIT_STATION", c.RateLimitStationRate, c.RateLimitStationBurst); err != nil {
+		return err
+	}
+	if err := validateRateLimit("RATE_LIMIT_IMSI", c.RateLimitIMSIRate, c.RateLimitIMSIBurst); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(c.PolicyTimezone); err != nil {
+		return fmt.Errorf("POLICY_TIMEZONE is invalid: %w", err)
+	}
+	if c.SIMRandCount != 0 && (c.SIMRandCount < 2 || c.SIMRandCount > 3) {
+		return fmt.Errorf("EAP_SIM_RAND_COUNT must be 2 or 3")
+	}
 	if !strings.HasPrefix(c.VectorAPIURL, "http://") && !strings.HasPrefix(c.VectorAPIURL, "https://") {
 		return fmt.Errorf("VECTOR_API_URL must start with http:// or https://")
 	}
 	return nil
 }
+
+// validateLockout はロックアウト設定（prefix_THRESHOLD・prefix_WINDOW・prefix_DURATION）のバリデーションを行う
+// 計数期間・ロックアウト期間はValkeyのキー有効期限に使用するため1秒以上とする
+func validateLockout(prefix string, threshold int, window, duration time.Duration) error {
+	if threshold < 0 {
+		return fmt.Errorf("%s_THRESHOLD must not be negative", prefix)
+	}
+	if threshold == 0 {
+		return nil
+	}
+	if window < time.Second {
+		return fmt.Errorf("%s_WINDOW must be at least 1s", prefix)
+	}
+	if duration < time.Second {
+		return fmt.Errorf("%s_DURATION must be at least 1s", prefix)
+	}
+	return nil
+}
+
+// validateRateLimit はレート制限設定（prefix_RATE・prefix_BURST）のバリデーションを行う
+func validateRateLimit(prefix string, rate float64, burst int) error {
+	if rate < 0 {
+		return fmt.Errorf("%s_RATE must not be negative", prefix)
+	}
+	if rate == 0 {
+		return nil
+	}
+	if burst < 1 {
+		return fmt.Errorf("%s_BURST must be at least 1", prefix)
+	}
+	return nil
+}
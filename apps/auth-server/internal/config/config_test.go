@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -73,6 +74,63 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.RadiusSecret != "" {
 		t.Errorf("RadiusSecret default = %q, want %q", cfg.RadiusSecret, "")
 	}
+	if cfg.ResultIndEnabled != true {
+		t.Errorf("ResultIndEnabled default = %v, want %v", cfg.ResultIndEnabled, true)
+	}
+	if cfg.BiddingEnabled != true {
+		t.Errorf("BiddingEnabled default = %v, want %v", cfg.BiddingEnabled, true)
+	}
+	if cfg.SIMRandCount != 3 {
+		t.Errorf("SIMRandCount default = %d, want %d", cfg.SIMRandCount, 3)
+	}
+	if cfg.ReauthMaxCount != 5 {
+		t.Errorf("ReauthMaxCount default = %d, want %d", cfg.ReauthMaxCount, 5)
+	}
+	if cfg.ReauthKeyLifetime != time.Hour {
+		t.Errorf("ReauthKeyLifetime default = %v, want %v", cfg.ReauthKeyLifetime, time.Hour)
+	}
+	if cfg.ERPDomain != "" {
+		t.Errorf("ERPDomain default = %q, want empty", cfg.ERPDomain)
+	}
+	if cfg.ERPKeyLifetime != 8*time.Hour {
+		t.Errorf("ERPKeyLifetime default = %v, want %v", cfg.ERPKeyLifetime, 8*time.Hour)
+	}
+	if cfg.VectorPrefetchCount != 1 {
+		t.Errorf("VectorPrefetchCount default = %d, want %d", cfg.VectorPrefetchCount, 1)
+	}
+	if cfg.MaxSessionsPerUser != 0 {
+		t.Errorf("MaxSessionsPerUser default = %d, want %d", cfg.MaxSessionsPerUser, 0)
+	}
+	if cfg.SessionLimitAction != SessionLimitActionReject {
+		t.Errorf("SessionLimitAction default = %q, want %q", cfg.SessionLimitAction, SessionLimitActionReject)
+	}
+	if cfg.PolicyTimezone != "Local" {
+		t.Errorf("PolicyTimezone default = %q, want %q", cfg.PolicyTimezone, "Local")
+	}
+	if cfg.VectorCacheTTL != 5*time.Minute {
+		t.Errorf("VectorCacheTTL default = %v, want %v", cfg.VectorCacheTTL, 5*time.Minute)
+	}
+	if cfg.DupCacheTTL != 5*time.Second {
+		t.Errorf("DupCacheTTL default = %v, want %v", cfg.DupCacheTTL, 5*time.Second)
+	}
+	if cfg.RequestTimeout != 3*time.Second {
+		t.Errorf("RequestTimeout default = %v, want %v", cfg.RequestTimeout, 3*time.Second)
+	}
+	if cfg.AuthLockoutThreshold != 0 || cfg.AuthLockoutWindow != 10*time.Minute || cfg.AuthLockoutDuration != 15*time.Minute {
+		t.Errorf("AuthLockout default = %d/%v/%v, want 0/10m/15m", cfg.AuthLockoutThreshold, cfg.AuthLockoutWindow, cfg.AuthLockoutDuration)
+	}
+	if cfg.NASLockoutThreshold != 0 || cfg.NASLockoutWindow != time.Minute || cfg.NASLockoutDuration != 5*time.Minute {
+		t.Errorf("NASLockout default = %d/%v/%v, want 0/1m/5m", cfg.NASLockoutThreshold, cfg.NASLockoutWindow, cfg.NASLockoutDuration)
+	}
+	if cfg.RateLimitClientRate != 0 || cfg.RateLimitClientBurst != 200 {
+		t.Errorf("RateLimitClient default = %v/%d, want 0/200", cfg.RateLimitClientRate, cfg.RateLimitClientBurst)
+	}
+	if cfg.RateLimitStationRate != 0 || cfg.RateLimitStationBurst != 10 {
+		t.Errorf("RateLimitStation default = %v/%d, want 0/10", cfg.RateLimitStationRate, cfg.RateLimitStationBurst)
+	}
+	if cfg.RateLimitIMSIRate != 0 || cfg.RateLimitIMSIBurst != 10 {
+		t.Errorf("RateLimitIMSI default = %v/%d, want 0/10", cfg.RateLimitIMSIRate, cfg.RateLimitIMSIBurst)
+	}
 }
 
 func TestLoadMissingRequired(t *testing.T) {
@@ -176,6 +234,302 @@ func TestValidateVectorAPIURL(t *testing.T) {
 	}
 }
 
+func TestValidateReauth(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxCount int
+		lifetime time.Duration
+		wantErr  bool
+	}{
+		{name: "enabled", maxCount: 5, lifetime: time.Hour, wantErr: false},
+		{name: "disabled", maxCount: 0, lifetime: 0, wantErr: false},
+		{name: "negative count", maxCount: -1, lifetime: time.Hour, wantErr: true},
+		{name: "count exceeds counter range", maxCount: 65536, lifetime: time.Hour, wantErr: true},
+		{name: "zero lifetime", maxCount: 5, lifetime: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:       "WLAN",
+				VectorAPIURL:      "http://localhost:8080/api/v1/vector",
+				ReauthMaxCount:    tt.maxCount,
+				ReauthKeyLifetime: tt.lifetime,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateERP(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		lifetime time.Duration
+		wantErr  bool
+	}{
+		{name: "enabled", domain: "erp.example.net", lifetime: 8 * time.Hour, wantErr: false},
+		{name: "disabled", domain: "", lifetime: 0, wantErr: false},
+		{name: "zero lifetime", domain: "erp.example.net", lifetime: 0, wantErr: true},
+		{name: "domain too long", domain: strings.Repeat("a", 256), lifetime: 8 * time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:    "WLAN",
+				VectorAPIURL:   "http://localhost:8080/api/v1/vector",
+				ERPDomain:      tt.domain,
+				ERPKeyLifetime: tt.lifetime,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVectorPrefetch(t *testing.T) {
+	validKey := strings.Repeat("ab", 32)
+	tests := []struct {
+		name    string
+		count   int
+		ttl     time.Duration
+		key     string
+		wantErr bool
+	}{
+		{name: "enabled", count: 4, ttl: 5 * time.Minute, key: validKey, wantErr: false},
+		{name: "disabled", count: 1, ttl: 0, key: "", wantErr: false},
+		{name: "negative count", count: -1, ttl: 5 * time.Minute, key: validKey, wantErr: true},
+		{name: "count exceeds limit", count: MaxVectorPrefetchCount + 1, ttl: 5 * time.Minute, key: validKey, wantErr: true},
+		{name: "zero ttl", count: 4, ttl: 0, key: validKey, wantErr: true},
+		{name: "missing key", count: 4, ttl: 5 * time.Minute, key: "", wantErr: true},
+		{name: "short key", count: 4, ttl: 5 * time.Minute, key: strings.Repeat("ab", 16), wantErr: true},
+		{name: "non-hex key", count: 4, ttl: 5 * time.Minute, key: strings.Repeat("zz", 32), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:         "WLAN",
+				VectorAPIURL:        "http://localhost:8080/api/v1/vector",
+				VectorPrefetchCount: tt.count,
+				VectorCacheTTL:      tt.ttl,
+				VectorCacheKey:      tt.key,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSessionLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		action  string
+		wantErr bool
+	}{
+		{name: "unlimited", max: 0, action: "", wantErr: false},
+		{name: "reject", max: 2, action: SessionLimitActionReject, wantErr: false},
+		{name: "evict", max: 1, action: SessionLimitActionEvict, wantErr: false},
+		{name: "negative", max: -1, action: SessionLimitActionReject, wantErr: true},
+		{name: "unknown action", max: 2, action: "disconnect", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:        "WLAN",
+				VectorAPIURL:       "http://localhost:8080/api/v1/vector",
+				MaxSessionsPerUser: tt.max,
+				SessionLimitAction: tt.action,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLockout(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		window    time.Duration
+		duration  time.Duration
+		wantErr   bool
+	}{
+		{name: "disabled", threshold: 0, window: 0, duration: 0, wantErr: false},
+		{name: "valid", threshold: 5, window: 10 * time.Minute, duration: 15 * time.Minute, wantErr: false},
+		{name: "negative threshold", threshold: -1, window: time.Minute, duration: time.Minute, wantErr: true},
+		{name: "zero window", threshold: 5, window: 0, duration: time.Minute, wantErr: true},
+		{name: "sub-second duration", threshold: 5, window: time.Minute, duration: 500 * time.Millisecond, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cfg := range []*Config{
+				{AuthLockoutThreshold: tt.threshold, AuthLockoutWindow: tt.window, AuthLockoutDuration: tt.duration},
+				{NASLockoutThreshold: tt.threshold, NASLockoutWindow: tt.window, NASLockoutDuration: tt.duration},
+			} {
+				cfg.NetworkName = "WLAN"
+				cfg.VectorAPIURL = "http://localhost:8080/api/v1/vector"
+				err := cfg.validate()
+				if (err != nil) != tt.wantErr {
+					t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		wantErr bool
+	}{
+		{name: "disabled", rate: 0, burst: 0, wantErr: false},
+		{name: "valid", rate: 0.5, burst: 5, wantErr: false},
+		{name: "negative rate", rate: -1, burst: 10, wantErr: true},
+		{name: "zero burst", rate: 1, burst: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cfg := range []*Config{
+				{RateLimitClientRate: tt.rate, RateLimitClientBurst: tt.burst},
+				{RateLimitStationRate: tt.rate, RateLimitStationBurst: tt.burst},
+				{RateLimitIMSIRate: tt.rate, RateLimitIMSIBurst: tt.burst},
+			} {
+				cfg.NetworkName = "WLAN"
+				cfg.VectorAPIURL = "http://localhost:8080/api/v1/vector"
+				err := cfg.validate()
+				if (err != nil) != tt.wantErr {
+					t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePolicyTimezone(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		wantErr bool
+	}{
+		{name: "empty", tz: "", wantErr: false},
+		{name: "local", tz: "Local", wantErr: false},
+		{name: "utc", tz: "UTC", wantErr: false},
+		{name: "iana", tz: "Asia/Tokyo", wantErr: false},
+		{name: "unknown", tz: "Mars/Olympus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:    "WLAN",
+				VectorAPIURL:   "http://localhost:8080/api/v1/vector",
+				PolicyTimezone: tt.tz,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDupCacheTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{name: "enabled", ttl: 5 * time.Second, wantErr: false},
+		{name: "disabled", ttl: 0, wantErr: false},
+		{name: "negative", ttl: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:  "WLAN",
+				VectorAPIURL: "http://localhost:8080/api/v1/vector",
+				DupCacheTTL:  tt.ttl,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequestTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{name: "enabled", timeout: 3 * time.Second, wantErr: false},
+		{name: "disabled", timeout: 0, wantErr: false},
+		{name: "negative", timeout: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:    "WLAN",
+				VectorAPIURL:   "http://localhost:8080/api/v1/vector",
+				RequestTimeout: tt.timeout,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSIMRandCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		wantErr bool
+	}{
+		{name: "two", count: 2, wantErr: false},
+		{name: "three", count: 3, wantErr: false},
+		{name: "unset", count: 0, wantErr: false},
+		{name: "one", count: 1, wantErr: true},
+		{name: "four", count: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				NetworkName:  "WLAN",
+				VectorAPIURL: "http://localhost:8080/api/v1/vector",
+				SIMRandCount: tt.count,
+			}
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestConstants(t *testing.T) {
 	// 定数値が設計書に準拠していることを確認
 	if ValkeyConnectTimeout != 3*time.Second {
@@ -218,3 +572,123 @@ func TestConstants(t *testing.T) {
 		t.Errorf("MaxResyncCount = %d, want %d", MaxResyncCount, 32)
 	}
 }
+
+func TestLoadNetworkNameMaps(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("EAP_AKA_PRIME_NETWORK_NAME_BY_SSID", "Corp-WiFi=CORP, guest=GUEST")
+	t.Setenv("EAP_AKA_PRIME_NETWORK_NAME_BY_REALM", "visited.example.org=5G:mnc002.mcc001.3gppnetwork.org")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got := cfg.SSIDNetworkNames["corp-wifi"]; got != "CORP" {
+		t.Errorf("SSIDNetworkNames[corp-wifi] = %q, want %q", got, "CORP")
+	}
+	if got := cfg.SSIDNetworkNames["guest"]; got != "GUEST" {
+		t.Errorf("SSIDNetworkNames[guest] = %q, want %q", got, "GUEST")
+	}
+	// ネットワーク名に":"を含められること
+	if got := cfg.RealmNetworkNames["visited.example.org"]; got != "5G:mnc002.mcc001.3gppnetwork.org" {
+		t.Errorf("RealmNetworkNames = %q, want %q", got, "5G:mnc002.mcc001.3gppnetwork.org")
+	}
+}
+
+func TestLoadNetworkNameMapsInvalid(t *testing.T) {
+	for _, value := range []string{"corp-wifi", "=CORP", "corp-wifi="} {
+		t.Run(value, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("EAP_AKA_PRIME_NETWORK_NAME_BY_SSID", value)
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail for %q", value)
+			}
+		})
+	}
+}
+
+func TestNetworkNameFor(t *testing.T) {
+	cfg := &Config{
+		NetworkName:       "WLAN",
+		SSIDNetworkNames:  NameMap{"corp-wifi": "CORP"},
+		RealmNetworkNames: NameMap{"visited.example.org": "VISITED"},
+	}
+
+	tests := []struct {
+		name       string
+		ssid       string
+		realm      string
+		wantName   string
+		wantSource string
+	}{
+		{"SSID優先", "Corp-WiFi", "visited.example.org", "CORP", NetworkNameSourceSSID},
+		{"Realm", "guest", "Visited.Example.org", "VISITED", NetworkNameSourceRealm},
+		{"既定値", "", "", "WLAN", NetworkNameSourceDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, source := cfg.NetworkNameFor(tt.ssid, tt.realm)
+			if name != tt.wantName || source != tt.wantSource {
+				t.Errorf("NetworkNameFor(%q, %q) = (%q, %q), want (%q, %q)",
+					tt.ssid, tt.realm, name, source, tt.wantName, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestLoadAnonymousIdentity(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("EAP_ANONYMOUS_METHOD", "aka-prime")
+	t.Setenv("EAP_ANONYMOUS_METHOD_BY_REALM", "legacy.example.org=aka")
+	t.Setenv("EAP_ANONYMOUS_ID_REQ", "permanent")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if eapType, source := cfg.AnonymousEAPType("Legacy.Example.org"); eapType != 23 || source != AnonymousMethodSourceRealm {
+		t.Errorf("AnonymousEAPType(realm) = (%d, %q), want (23, %q)", eapType, source, AnonymousMethodSourceRealm)
+	}
+	if eapType, source := cfg.AnonymousEAPType("other.example.org"); eapType != 50 || source != AnonymousMethodSourceDefault {
+		t.Errorf("AnonymousEAPType(other) = (%d, %q), want (50, %q)", eapType, source, AnonymousMethodSourceDefault)
+	}
+	if got := cfg.AnonymousIdentityRequest().String(); got != "AT_PERMANENT_ID_REQ" {
+		t.Errorf("AnonymousIdentityRequest() = %s, want AT_PERMANENT_ID_REQ", got)
+	}
+}
+
+func TestLoadAnonymousIdentityDefaults(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if eapType, _ := cfg.AnonymousEAPType("realm"); eapType != 23 {
+		t.Errorf("AnonymousEAPType default = %d, want 23", eapType)
+	}
+	if got := cfg.AnonymousIdentityRequest().String(); got != "AT_ANY_ID_REQ" {
+		t.Errorf("AnonymousIdentityRequest() default = %s, want AT_ANY_ID_REQ", got)
+	}
+}
+
+func TestLoadAnonymousIdentityInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"不正な方式", "EAP_ANONYMOUS_METHOD", "sim"},
+		{"Realm対応表の不正な方式", "EAP_ANONYMOUS_METHOD_BY_REALM", "example.org=peap"},
+		{"不正な要求種別", "EAP_ANONYMOUS_ID_REQ", "identity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv(tt.key, tt.value)
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail for %s=%q", tt.key, tt.value)
+			}
+		})
+	}
+}
@@ -13,6 +13,8 @@ const (
 const (
 	VectorConnectTimeout = 2 * time.Second
 	VectorRequestTimeout = 5 * time.Second
+	// MaxVectorPrefetchCount は1回のVector Gateway呼び出しで先行取得するベクター数の上限（Vector APIの上限と同じ）
+	MaxVectorPrefetchCount = 8
 )
 
 // Circuit Breaker設定（D-06準拠）
@@ -28,6 +30,13 @@ const (
 const (
 	EAPContextTTL = 60 * time.Second
 	SessionTTL    = 24 * time.Hour
+	PseudonymTTL  = 24 * time.Hour
+)
+
+// 同時セッション数上限到達時の動作
+const (
+	SessionLimitActionReject = "reject"
+	SessionLimitActionEvict  = "evict"
 )
 
 // 再同期上限（D-02準拠）
@@ -8,8 +8,9 @@ import (
 )
 
 // BuildChallenge はEAP-Request/AKA-Challengeパケットを構築する
-// 属性: AT_RAND, AT_AUTN, AT_MAC
-func BuildChallenge(identifier uint8, rand, autn, kAut []byte) ([]byte, error) {
+// 属性: AT_RAND, AT_AUTN, [AT_RESULT_IND], [AT_BIDDING], [AT_CHECKCODE], [AT_IV, AT_ENCR_DATA], AT_MAC
+// optsがnilの場合は追加属性を付与しない
+func BuildChallenge(identifier uint8, rand, autn, kAut []byte, opts *eap.ChallengeOptions) ([]byte, error) {
 	pkt := &eapaka.Packet{
 		Code:       eapaka.CodeRequest,
 		Identifier: identifier,
@@ -18,10 +19,17 @@ func BuildChallenge(identifier uint8, rand, autn, kAut []byte) ([]byte, error) {
 		Attributes: []eapaka.Attribute{
 			&eapaka.AtRand{Rand: rand},
 			&eapaka.AtAutn{Autn: autn},
-			&eapaka.AtMac{MAC: make([]byte, 16)},
 		},
 	}
 
+	// 追加属性（暗号化属性等）
+	extra, err := opts.Attributes()
+	if err != nil {
+		return nil, err
+	}
+	pkt.Attributes = append(pkt.Attributes, extra...)
+	pkt.Attributes = append(pkt.Attributes, &eapaka.AtMac{MAC: make([]byte, 16)})
+
 	// MAC計算・設定
 	if err := pkt.CalculateAndSetMac(kAut); err != nil {
 		return nil, err
@@ -31,8 +39,9 @@ func BuildChallenge(identifier uint8, rand, autn, kAut []byte) ([]byte, error) {
 }
 
 // VerifyChallengeResponse はEAP-Response/AKA-Challengeを検証する
-// 検証順序: AT_MAC → AT_RES
-func VerifyChallengeResponse(pkt *eapaka.Packet, kAut, xres []byte) error {
+// 検証順序: AT_MAC → AT_CHECKCODE → AT_RES
+// checkcodeはサーバーで計算したAT_CHECKCODEの値（AKA-Identity交換を行っていない場合はnil）
+func VerifyChallengeResponse(pkt *eapaka.Packet, kAut, xres, checkcode []byte) error {
 	// 1. MAC検証
 	ok, err := pkt.VerifyMac(kAut)
 	if err != nil {
@@ -42,18 +51,23 @@ func VerifyChallengeResponse(pkt *eapaka.Packet, kAut, xres []byte) error {
 		return eap.ErrMACInvalid
 	}
 
-	// 2. AT_RES取得
+	// 2. AT_CHECKCODE検証（AKA-Identity交換の改ざん検知）
+	if err := eap.VerifyCheckcode(pkt, checkcode); err != nil {
+		return err
+	}
+
+	// 3. AT_RES取得
 	atRes, found := eap.GetAttribute[*eapaka.AtRes](pkt)
 	if !found {
 		return eap.ErrRESNotFound
 	}
 
-	// 3. RES長チェック
+	// 4. RES長チェック
 	if len(atRes.Res) != len(xres) {
 		return eap.ErrRESLengthMismatch
 	}
 
-	// 4. RES値比較（タイミング攻撃対策）
+	// 5. RES値比較（タイミング攻撃対策）
 	if subtle.ConstantTimeCompare(atRes.Res, xres) != 1 {
 		return eap.ErrRESMismatch
 	}
@@ -60,7 +60,7 @@ func buildValidResponse(t *testing.T, kAut, xres []byte) *eapaka.Packet {
 func TestBuildChallenge_Success(t *testing.T) {
 	kAut, rand, autn, _ := setupChallengeTest(t)
 
-	data, err := BuildChallenge(1, rand, autn, kAut)
+	data, err := BuildChallenge(1, rand, autn, kAut, nil)
 	if err != nil {
 		t.Fatalf("BuildChallenge失敗: %v", err)
 	}
@@ -81,7 +81,7 @@ func TestBuildChallenge_Success(t *testing.T) {
 func TestBuildChallenge_ContainsAttributes(t *testing.T) {
 	kAut, rand, autn, _ := setupChallengeTest(t)
 
-	data, err := BuildChallenge(1, rand, autn, kAut)
+	data, err := BuildChallenge(1, rand, autn, kAut, nil)
 	if err != nil {
 		t.Fatalf("BuildChallenge失敗: %v", err)
 	}
@@ -108,7 +108,7 @@ func TestBuildChallenge_ContainsAttributes(t *testing.T) {
 func TestBuildChallenge_MACIsSet(t *testing.T) {
 	kAut, rand, autn, _ := setupChallengeTest(t)
 
-	data, err := BuildChallenge(1, rand, autn, kAut)
+	data, err := BuildChallenge(1, rand, autn, kAut, nil)
 	if err != nil {
 		t.Fatalf("BuildChallenge失敗: %v", err)
 	}
@@ -140,7 +140,7 @@ func TestVerifyChallengeResponse_Success(t *testing.T) {
 
 	pkt := buildValidResponse(t, kAut, xres)
 
-	if err := VerifyChallengeResponse(pkt, kAut, xres); err != nil {
+	if err := VerifyChallengeResponse(pkt, kAut, xres, nil); err != nil {
 		t.Errorf("検証成功を期待したがエラー: %v", err)
 	}
 }
@@ -156,7 +156,7 @@ func TestVerifyChallengeResponse_MACInvalid(t *testing.T) {
 		wrongKAut[i] = 0xFF
 	}
 
-	err := VerifyChallengeResponse(pkt, wrongKAut, xres)
+	err := VerifyChallengeResponse(pkt, wrongKAut, xres, nil)
 	if !errors.Is(err, eap.ErrMACInvalid) {
 		t.Errorf("ErrMACInvalidを期待したが: %v", err)
 	}
@@ -179,7 +179,7 @@ func TestVerifyChallengeResponse_RESNotFound(t *testing.T) {
 		t.Fatalf("MAC計算失敗: %v", err)
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, make([]byte, 8))
+	err := VerifyChallengeResponse(pkt, kAut, make([]byte, 8), nil)
 	if !errors.Is(err, eap.ErrRESNotFound) {
 		t.Errorf("ErrRESNotFoundを期待したが: %v", err)
 	}
@@ -197,7 +197,7 @@ func TestVerifyChallengeResponse_RESLengthMismatch(t *testing.T) {
 
 	// 異なる長さのxresで検証
 	xres := make([]byte, 16) // 長さ不一致
-	err := VerifyChallengeResponse(pkt, kAut, xres)
+	err := VerifyChallengeResponse(pkt, kAut, xres, nil)
 	if !errors.Is(err, eap.ErrRESLengthMismatch) {
 		t.Errorf("ErrRESLengthMismatchを期待したが: %v", err)
 	}
@@ -217,7 +217,7 @@ func TestVerifyChallengeResponse_VerifyMacError(t *testing.T) {
 		},
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, xres)
+	err := VerifyChallengeResponse(pkt, kAut, xres, nil)
 	if !errors.Is(err, eap.ErrMACInvalid) {
 		t.Errorf("ErrMACInvalidを期待したが: %v", err)
 	}
@@ -234,8 +234,166 @@ func TestVerifyChallengeResponse_RESMismatch(t *testing.T) {
 		wrongXres[i] = 0xFF
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, wrongXres)
+	err := VerifyChallengeResponse(pkt, kAut, wrongXres, nil)
 	if !errors.Is(err, eap.ErrRESMismatch) {
 		t.Errorf("ErrRESMismatchを期待したが: %v", err)
 	}
 }
+
+func TestBuildChallenge_WithNextPseudonym(t *testing.T) {
+	kAut, rand, autn, _ := setupChallengeTest(t)
+	kEncr := make([]byte, 16)
+	for i := range kEncr {
+		kEncr[i] = byte(i + 0x50)
+	}
+
+	data, err := BuildChallenge(1, rand, autn, kAut, &eap.ChallengeOptions{
+		KEncr:         kEncr,
+		NextPseudonym: "2abcdef",
+	})
+	if err != nil {
+		t.Fatalf("BuildChallenge失敗: %v", err)
+	}
+
+	pkt, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パケットのパース失敗: %v", err)
+	}
+
+	atIv, found := eap.GetAttribute[*eapaka.AtIv](pkt)
+	if !found {
+		t.Fatal("AT_IVが見つからない")
+	}
+	atEncr, found := eap.GetAttribute[*eapaka.AtEncrData](pkt)
+	if !found {
+		t.Fatal("AT_ENCR_DATAが見つからない")
+	}
+
+	attrs, err := eap.DecryptAttributes(kEncr, atIv, atEncr)
+	if err != nil {
+		t.Fatalf("復号失敗: %v", err)
+	}
+	if len(attrs) != 1 {
+		t.Fatalf("属性数が不正: got=%d, want=1", len(attrs))
+	}
+	next, ok := attrs[0].(*eapaka.AtNextPseudonym)
+	if !ok {
+		t.Fatalf("AT_NEXT_PSEUDONYMではない: %T", attrs[0])
+	}
+	if next.Pseudonym != "2abcdef" {
+		t.Errorf("Pseudonym: got=%q, want=%q", next.Pseudonym, "2abcdef")
+	}
+
+	// MACが追加属性を含めて計算されていること
+	if ok, err := pkt.VerifyMac(kAut); err != nil || !ok {
+		t.Errorf("MAC検証失敗: ok=%v, err=%v", ok, err)
+	}
+}
+
+func TestBuildChallenge_WithBidding(t *testing.T) {
+	kAut, rand, autn, _ := setupChallengeTest(t)
+
+	data, err := BuildChallenge(1, rand, autn, kAut, &eap.ChallengeOptions{Bidding: true})
+	if err != nil {
+		t.Fatalf("BuildChallenge失敗: %v", err)
+	}
+
+	pkt, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パケットのパース失敗: %v", err)
+	}
+
+	atBidding, found := eap.GetAttribute[*eapaka.AtBidding](pkt)
+	if !found {
+		t.Fatal("AT_BIDDINGが見つからない")
+	}
+	if !atBidding.SupportsAKAPrime() {
+		t.Errorf("AT_BIDDINGのDビットが未設定: flags=0x%04x", atBidding.Flags)
+	}
+
+	// AT_BIDDINGがMAC計算対象に含まれること
+	if ok, err := pkt.VerifyMac(kAut); err != nil || !ok {
+		t.Errorf("MAC検証失敗: ok=%v, err=%v", ok, err)
+	}
+}
+
+func TestBuildChallenge_WithoutBidding(t *testing.T) {
+	kAut, rand, autn, _ := setupChallengeTest(t)
+
+	data, err := BuildChallenge(1, rand, autn, kAut, nil)
+	if err != nil {
+		t.Fatalf("BuildChallenge失敗: %v", err)
+	}
+
+	pkt, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パケットのパース失敗: %v", err)
+	}
+	if _, found := eap.GetAttribute[*eapaka.AtBidding](pkt); found {
+		t.Error("AT_BIDDINGは付与されないことを期待")
+	}
+}
+
+func TestVerifyChallengeResponse_Checkcode(t *testing.T) {
+	kAut, _, _, xres := setupChallengeTest(t)
+	checkcode := make([]byte, 20)
+	checkcode[0] = 0x01
+
+	tests := []struct {
+		name     string
+		attr     *eapaka.AtCheckcode
+		expected []byte
+		wantErr  error
+	}{
+		{"一致", &eapaka.AtCheckcode{Checkcode: checkcode}, checkcode, nil},
+		{"不一致", &eapaka.AtCheckcode{Checkcode: make([]byte, 20)}, checkcode, eap.ErrCheckcodeMismatch},
+		{"応答にAT_CHECKCODEなし", nil, checkcode, eap.ErrCheckcodeMismatch},
+		{"Identity交換なし・空のAT_CHECKCODE", &eapaka.AtCheckcode{}, nil, nil},
+		{"Identity交換なし・値ありのAT_CHECKCODE", &eapaka.AtCheckcode{Checkcode: checkcode}, nil, eap.ErrCheckcodeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkt := &eapaka.Packet{
+				Code:       eapaka.CodeResponse,
+				Identifier: 1,
+				Type:       eapaka.TypeAKA,
+				Subtype:    eapaka.SubtypeChallenge,
+				Attributes: []eapaka.Attribute{&eapaka.AtRes{Res: xres}},
+			}
+			if tt.attr != nil {
+				pkt.Attributes = append(pkt.Attributes, tt.attr)
+			}
+			pkt.Attributes = append(pkt.Attributes, &eapaka.AtMac{MAC: make([]byte, 16)})
+			if err := pkt.CalculateAndSetMac(kAut); err != nil {
+				t.Fatalf("MAC計算失敗: %v", err)
+			}
+
+			err := VerifyChallengeResponse(pkt, kAut, xres, tt.expected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyChallengeResponse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildChallenge_Checkcode(t *testing.T) {
+	kAut, rand, autn, _ := setupChallengeTest(t)
+	checkcode := make([]byte, 20)
+	checkcode[19] = 0xAB
+
+	data, err := BuildChallenge(1, rand, autn, kAut, &eap.ChallengeOptions{Checkcode: checkcode})
+	if err != nil {
+		t.Fatalf("BuildChallenge失敗: %v", err)
+	}
+	pkt, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	atCheckcode, found := eap.GetAttribute[*eapaka.AtCheckcode](pkt)
+	if !found {
+		t.Fatal("AT_CHECKCODEが見つからない")
+	}
+	if string(atCheckcode.Checkcode) != string(checkcode) {
+		t.Errorf("AT_CHECKCODE: got %x, want %x", atCheckcode.Checkcode, checkcode)
+	}
+}
@@ -1,6 +1,10 @@
 package aka
 
 import (
+	"crypto/sha1"
+	"encoding/binary"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
 	eapaka "github.com/oyaguma3/go-eapaka"
 )
 
@@ -10,16 +14,38 @@ type KeyMaterial struct {
 	K_aut  []byte // 16バイト: 認証鍵
 	MSK    []byte // 64バイト: マスターセッション鍵
 	EMSK   []byte // 64バイト: 拡張マスターセッション鍵
+	MK     []byte // 20バイト: マスター鍵（高速再認証で使用）
 }
 
 // DeriveKeys はEAP-AKA鍵導出を行う（RFC 4187 Section 7）
 // eapaka.DeriveKeysAKA のラッパー
 func DeriveKeys(identity string, ck, ik []byte) *KeyMaterial {
 	keys := eapaka.DeriveKeysAKA(identity, ck, ik)
+
+	// MK = SHA1(Identity | IK | CK)（go-eapakaは返さないため再計算する）
+	h := sha1.New()
+	h.Write([]byte(identity))
+	h.Write(ik)
+	h.Write(ck)
+
 	return &KeyMaterial{
 		K_encr: keys.K_encr,
 		K_aut:  keys.K_aut,
 		MSK:    keys.MSK,
 		EMSK:   keys.EMSK,
+		MK:     h.Sum(nil),
 	}
 }
+
+// DeriveReauthKeys は高速再認証時のMSK/EMSKを導出する（RFC 4187 Section 7）
+// XKEY' = SHA1(Identity | counter | NONCE_S | MK)
+func DeriveReauthKeys(identity string, counter uint16, nonceS, mk []byte) (msk, emsk []byte) {
+	h := sha1.New()
+	h.Write([]byte(identity))
+	h.Write(binary.BigEndian.AppendUint16(nil, counter))
+	h.Write(nonceS)
+	h.Write(mk)
+
+	keyBlock := eap.FIPS186PRF(h.Sum(nil), 128)
+	return keyBlock[0:64], keyBlock[64:128]
+}
@@ -105,3 +105,26 @@ func TestDeriveKeys_Deterministic(t *testing.T) {
 		t.Error("同一入力で異なるEMSKが生成された")
 	}
 }
+
+func TestDeriveKeys_MK(t *testing.T) {
+	km := DeriveKeys("0123456789012345@example.com", make([]byte, 16), make([]byte, 16))
+	if len(km.MK) != 20 {
+		t.Errorf("MKのサイズが不正: got=%d, want=20", len(km.MK))
+	}
+}
+
+func TestDeriveReauthKeys(t *testing.T) {
+	mk := bytes.Repeat([]byte{0x01}, 20)
+	nonceS := bytes.Repeat([]byte{0x02}, 16)
+
+	msk, emsk := DeriveReauthKeys("4reauth@example.com", 1, nonceS, mk)
+	if len(msk) != 64 || len(emsk) != 64 {
+		t.Fatalf("鍵サイズが不正: msk=%d, emsk=%d", len(msk), len(emsk))
+	}
+
+	// カウンタが異なれば異なるMSKになる
+	msk2, _ := DeriveReauthKeys("4reauth@example.com", 2, nonceS, mk)
+	if bytes.Equal(msk, msk2) {
+		t.Error("カウンタが異なるのにMSKが同一")
+	}
+}
@@ -8,8 +8,9 @@ import (
 )
 
 // BuildChallenge はEAP-Request/AKA'-Challengeパケットを構築する
-// 属性: AT_RAND, AT_AUTN, AT_KDF_INPUT, AT_KDF, AT_MAC
-func BuildChallenge(identifier uint8, rand, autn []byte, networkName string, kAut []byte) ([]byte, error) {
+// 属性: AT_RAND, AT_AUTN, AT_KDF_INPUT, AT_KDF, [AT_RESULT_IND], [AT_CHECKCODE], [AT_IV, AT_ENCR_DATA], AT_MAC
+// AT_KDFはKDFOfferの値を付与する。optsがnilの場合は追加属性を付与しない
+func BuildChallenge(identifier uint8, rand, autn []byte, networkName string, kAut []byte, opts *eap.ChallengeOptions) ([]byte, error) {
 	pkt := &eapaka.Packet{
 		Code:       eapaka.CodeRequest,
 		Identifier: identifier,
@@ -19,10 +20,19 @@ func BuildChallenge(identifier uint8, rand, autn []byte, networkName string, kAu
 			&eapaka.AtRand{Rand: rand},
 			&eapaka.AtAutn{Autn: autn},
 			&eapaka.AtKdfInput{NetworkName: networkName},
-			&eapaka.AtKdf{KDF: eapaka.KDFAKAPrimeWithCKIK},
-			&eapaka.AtMac{MAC: make([]byte, 16)},
 		},
 	}
+	for _, kdf := range KDFOffer {
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtKdf{KDF: kdf})
+	}
+
+	// 追加属性（暗号化属性等）
+	extra, err := opts.Attributes()
+	if err != nil {
+		return nil, err
+	}
+	pkt.Attributes = append(pkt.Attributes, extra...)
+	pkt.Attributes = append(pkt.Attributes, &eapaka.AtMac{MAC: make([]byte, 16)})
 
 	// MAC計算・設定
 	if err := pkt.CalculateAndSetMac(kAut); err != nil {
@@ -33,8 +43,9 @@ func BuildChallenge(identifier uint8, rand, autn []byte, networkName string, kAu
 }
 
 // VerifyChallengeResponse はEAP-Response/AKA'-Challengeを検証する
-// 検証順序: AT_KDF → AT_MAC → AT_RES
-func VerifyChallengeResponse(pkt *eapaka.Packet, kAut, xres []byte) error {
+// 検証順序: AT_KDF → AT_MAC → AT_CHECKCODE → AT_RES
+// checkcodeはサーバーで計算したAT_CHECKCODEの値（AKA-Identity交換を行っていない場合はnil）
+func VerifyChallengeResponse(pkt *eapaka.Packet, kAut, xres, checkcode []byte) error {
 	// 1. KDF検証
 	if err := validateKdfInResponse(pkt); err != nil {
 		return err
@@ -49,18 +60,23 @@ func VerifyChallengeResponse(pkt *eapaka.Packet, kAut, xres []byte) error {
 		return eap.ErrMACInvalid
 	}
 
-	// 3. AT_RES取得
+	// 3. AT_CHECKCODE検証（AKA'-Identity交換の改ざん検知）
+	if err := eap.VerifyCheckcode(pkt, checkcode); err != nil {
+		return err
+	}
+
+	// 4. AT_RES取得
 	atRes, found := eap.GetAttribute[*eapaka.AtRes](pkt)
 	if !found {
 		return eap.ErrRESNotFound
 	}
 
-	// 4. RES長チェック
+	// 5. RES長チェック
 	if len(atRes.Res) != len(xres) {
 		return eap.ErrRESLengthMismatch
 	}
 
-	// 5. RES値比較（タイミング攻撃対策）
+	// 6. RES値比較（タイミング攻撃対策）
 	if subtle.ConstantTimeCompare(atRes.Res, xres) != 1 {
 		return eap.ErrRESMismatch
 	}
@@ -77,8 +93,8 @@ func validateKdfInResponse(pkt *eapaka.Packet) error {
 		return nil
 	}
 
-	// KDF値が1つでKDF=1 → 正常
-	if len(kdfValues) == 1 && kdfValues[0] == eapaka.KDFAKAPrimeWithCKIK {
+	// KDF値が1つでサポート対象 → 正常
+	if len(kdfValues) == 1 && IsSupportedKDF(kdfValues[0]) {
 		return nil
 	}
 
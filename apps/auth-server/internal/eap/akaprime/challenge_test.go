@@ -69,7 +69,7 @@ func buildValidAKAPrimeResponse(t *testing.T, kAut, xres []byte) *eapaka.Packet
 func TestBuildChallenge_Success(t *testing.T) {
 	kAut, rand, autn, _ := setupAKAPrimeChallengeTest(t)
 
-	data, err := BuildChallenge(1, rand, autn, testNetworkName, kAut)
+	data, err := BuildChallenge(1, rand, autn, testNetworkName, kAut, nil)
 	if err != nil {
 		t.Fatalf("BuildChallenge失敗: %v", err)
 	}
@@ -89,7 +89,7 @@ func TestBuildChallenge_Success(t *testing.T) {
 func TestBuildChallenge_ContainsKdfAttributes(t *testing.T) {
 	kAut, rand, autn, _ := setupAKAPrimeChallengeTest(t)
 
-	data, err := BuildChallenge(1, rand, autn, testNetworkName, kAut)
+	data, err := BuildChallenge(1, rand, autn, testNetworkName, kAut, nil)
 	if err != nil {
 		t.Fatalf("BuildChallenge失敗: %v", err)
 	}
@@ -121,7 +121,7 @@ func TestBuildChallenge_ContainsKdfAttributes(t *testing.T) {
 func TestBuildChallenge_MACIsSet(t *testing.T) {
 	kAut, rand, autn, _ := setupAKAPrimeChallengeTest(t)
 
-	data, err := BuildChallenge(1, rand, autn, testNetworkName, kAut)
+	data, err := BuildChallenge(1, rand, autn, testNetworkName, kAut, nil)
 	if err != nil {
 		t.Fatalf("BuildChallenge失敗: %v", err)
 	}
@@ -153,7 +153,7 @@ func TestVerifyChallengeResponse_Success(t *testing.T) {
 
 	pkt := buildValidAKAPrimeResponse(t, kAut, xres)
 
-	if err := VerifyChallengeResponse(pkt, kAut, xres); err != nil {
+	if err := VerifyChallengeResponse(pkt, kAut, xres, nil); err != nil {
 		t.Errorf("検証成功を期待したがエラー: %v", err)
 	}
 }
@@ -177,7 +177,7 @@ func TestVerifyChallengeResponse_KDFNotSupported(t *testing.T) {
 		t.Fatalf("MAC計算失敗: %v", err)
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, xres)
+	err := VerifyChallengeResponse(pkt, kAut, xres, nil)
 	if !errors.Is(err, eap.ErrKDFNotSupported) {
 		t.Errorf("ErrKDFNotSupportedを期待したが: %v", err)
 	}
@@ -203,7 +203,7 @@ func TestVerifyChallengeResponse_KDFMultipleValues(t *testing.T) {
 		t.Fatalf("MAC計算失敗: %v", err)
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, xres)
+	err := VerifyChallengeResponse(pkt, kAut, xres, nil)
 	if !errors.Is(err, eap.ErrKDFNotSupported) {
 		t.Errorf("ErrKDFNotSupportedを期待したが: %v", err)
 	}
@@ -227,7 +227,7 @@ func TestVerifyChallengeResponse_KDFAbsent(t *testing.T) {
 		t.Fatalf("MAC計算失敗: %v", err)
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, xres)
+	err := VerifyChallengeResponse(pkt, kAut, xres, nil)
 	if err != nil {
 		t.Errorf("AT_KDFなしは正常を期待したがエラー: %v", err)
 	}
@@ -244,7 +244,7 @@ func TestVerifyChallengeResponse_MACInvalid(t *testing.T) {
 		wrongKAut[i] = 0xFF
 	}
 
-	err := VerifyChallengeResponse(pkt, wrongKAut, xres)
+	err := VerifyChallengeResponse(pkt, wrongKAut, xres, nil)
 	if !errors.Is(err, eap.ErrMACInvalid) {
 		t.Errorf("ErrMACInvalidを期待したが: %v", err)
 	}
@@ -268,7 +268,7 @@ func TestVerifyChallengeResponse_RESNotFound(t *testing.T) {
 		t.Fatalf("MAC計算失敗: %v", err)
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, make([]byte, 8))
+	err := VerifyChallengeResponse(pkt, kAut, make([]byte, 8), nil)
 	if !errors.Is(err, eap.ErrRESNotFound) {
 		t.Errorf("ErrRESNotFoundを期待したが: %v", err)
 	}
@@ -286,7 +286,7 @@ func TestVerifyChallengeResponse_RESLengthMismatch(t *testing.T) {
 
 	// 異なる長さのxresで検証
 	xres := make([]byte, 16)
-	err := VerifyChallengeResponse(pkt, kAut, xres)
+	err := VerifyChallengeResponse(pkt, kAut, xres, nil)
 	if !errors.Is(err, eap.ErrRESLengthMismatch) {
 		t.Errorf("ErrRESLengthMismatchを期待したが: %v", err)
 	}
@@ -306,7 +306,7 @@ func TestVerifyChallengeResponse_VerifyMacError(t *testing.T) {
 		},
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, xres)
+	err := VerifyChallengeResponse(pkt, kAut, xres, nil)
 	if !errors.Is(err, eap.ErrMACInvalid) {
 		t.Errorf("ErrMACInvalidを期待したが: %v", err)
 	}
@@ -323,8 +323,62 @@ func TestVerifyChallengeResponse_RESMismatch(t *testing.T) {
 		wrongXres[i] = 0xFF
 	}
 
-	err := VerifyChallengeResponse(pkt, kAut, wrongXres)
+	err := VerifyChallengeResponse(pkt, kAut, wrongXres, nil)
 	if !errors.Is(err, eap.ErrRESMismatch) {
 		t.Errorf("ErrRESMismatchを期待したが: %v", err)
 	}
 }
+
+func TestBuildChallenge_KDFOffer(t *testing.T) {
+	kAut, rand, autn, _ := setupAKAPrimeChallengeTest(t)
+
+	// 提示一覧はKDF=1のみ
+	kdfs := []uint16{eapaka.KDFAKAPrimeWithCKIK}
+	data, err := BuildChallenge(1, rand, autn, testNetworkName, kAut, nil)
+	if err != nil {
+		t.Fatalf("BuildChallenge失敗: %v", err)
+	}
+
+	pkt, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パケットのパース失敗: %v", err)
+	}
+
+	got := eapaka.KdfValuesFromAttributes(pkt.Attributes)
+	if len(got) != len(kdfs) {
+		t.Fatalf("AT_KDFの数が不正: got=%v, want=%v", got, kdfs)
+	}
+	for i := range kdfs {
+		if got[i] != kdfs[i] {
+			t.Errorf("AT_KDF[%d]が不正: got=%d, want=%d", i, got[i], kdfs[i])
+		}
+	}
+}
+
+func TestVerifyChallengeResponse_CheckcodeMismatch(t *testing.T) {
+	kAut, _, _, xres := setupAKAPrimeChallengeTest(t)
+
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 1,
+		Type:       eapaka.TypeAKAPrime,
+		Subtype:    eapaka.SubtypeChallenge,
+		Attributes: []eapaka.Attribute{
+			&eapaka.AtRes{Res: xres},
+			&eapaka.AtCheckcode{Checkcode: make([]byte, 32)},
+			&eapaka.AtMac{MAC: make([]byte, 16)},
+		},
+	}
+	if err := pkt.CalculateAndSetMac(kAut); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+
+	expected := make([]byte, 32)
+	expected[0] = 0x01
+	if err := VerifyChallengeResponse(pkt, kAut, xres, expected); !errors.Is(err, eap.ErrCheckcodeMismatch) {
+		t.Errorf("ErrCheckcodeMismatchを期待: got %v", err)
+	}
+	if err := VerifyChallengeResponse(pkt, kAut, xres, make([]byte, 32)); err != nil {
+		t.Errorf("一致時は成功を期待: got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package akaprime
+
+import (
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// KDFOffer はAKA'-Challengeで提示するAT_KDFの一覧
+// 割り当て済みのKDFはKDF=1（RFC 5448）のみのため固定とし、設定による変更は行わない
+var KDFOffer = []uint16{eapaka.KDFAKAPrimeWithCKIK}
+
+// IsSupportedKDF は鍵導出を実装済みのKDF値かを返す
+func IsSupportedKDF(kdf uint16) bool {
+	return kdf == eapaka.KDFAKAPrimeWithCKIK
+}
+
+// IsKDFSelection はAKA'-Challenge応答がKDF選択（AT_KDFのみでAT_MACなし）かを返す
+func IsKDFSelection(pkt *eapaka.Packet) bool {
+	if len(eapaka.KdfValuesFromAttributes(pkt.Attributes)) == 0 {
+		return false
+	}
+	_, hasMac := eap.GetAttribute[*eapaka.AtMac](pkt)
+	return !hasMac
+}
@@ -0,0 +1,35 @@
+package akaprime
+
+import (
+	"testing"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// buildKDFSelection はAT_KDFのみを含むKDF選択応答を構築する
+func buildKDFSelection(values ...uint16) *eapaka.Packet {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 1,
+		Type:       eapaka.TypeAKAPrime,
+		Subtype:    eapaka.SubtypeChallenge,
+	}
+	for _, v := range values {
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtKdf{KDF: v})
+	}
+	return pkt
+}
+
+func TestIsKDFSelection(t *testing.T) {
+	if !IsKDFSelection(buildKDFSelection(1)) {
+		t.Error("AT_KDFのみの応答はKDF選択と判定されるべき")
+	}
+	if IsKDFSelection(buildKDFSelection()) {
+		t.Error("AT_KDFなしの応答はKDF選択ではない")
+	}
+
+	kAut, _, _, xres := setupAKAPrimeChallengeTest(t)
+	if IsKDFSelection(buildValidAKAPrimeResponse(t, kAut, xres)) {
+		t.Error("AT_MACを含む応答はKDF選択ではない")
+	}
+}
@@ -1,6 +1,9 @@
 package akaprime
 
 import (
+	"encoding/binary"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
 	eapaka "github.com/oyaguma3/go-eapaka"
 )
 
@@ -39,3 +42,15 @@ func DeriveAllKeys(identity string, ck, ik, autn []byte, networkName string) (*K
 	}
 	return DeriveKeys(identity, ckPrime, ikPrime), nil
 }
+
+// DeriveReauthKeys は高速再認証時のMSK/EMSKを導出する（RFC 5448 Section 3.3）
+// MK = PRF'(K_re, "EAP-AKA' re-auth" | Identity | counter | NONCE_S)
+func DeriveReauthKeys(identity string, counter uint16, nonceS, kRe []byte) (msk, emsk []byte) {
+	seed := []byte("EAP-AKA' re-auth")
+	seed = append(seed, identity...)
+	seed = binary.BigEndian.AppendUint16(seed, counter)
+	seed = append(seed, nonceS...)
+
+	mk := eap.PRFPlus(kRe, seed, 128)
+	return mk[0:64], mk[64:128]
+}
@@ -401,3 +401,19 @@ func TestDeriveAllKeys_Deterministic(t *testing.T) {
 		t.Error("同一入力で異なるEMSKが生成された")
 	}
 }
+
+func TestDeriveReauthKeys(t *testing.T) {
+	kRe := bytes.Repeat([]byte{0x01}, 32)
+	nonceS := bytes.Repeat([]byte{0x02}, 16)
+
+	msk, emsk := DeriveReauthKeys("8reauth@example.com", 1, nonceS, kRe)
+	if len(msk) != 64 || len(emsk) != 64 {
+		t.Fatalf("鍵サイズが不正: msk=%d, emsk=%d", len(msk), len(emsk))
+	}
+
+	// NONCE_Sが異なれば異なるMSKになる
+	msk2, _ := DeriveReauthKeys("8reauth@example.com", 1, make([]byte, 16), kRe)
+	if bytes.Equal(msk, msk2) {
+		t.Error("NONCE_Sが異なるのにMSKが同一")
+	}
+}
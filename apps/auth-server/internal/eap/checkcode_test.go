@@ -0,0 +1,77 @@
+package eap
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+func TestCheckcode_Sum(t *testing.T) {
+	req := []byte{eapaka.CodeRequest, 1, 0, 12, EAPTypeAKA, 5, 0, 0, 13, 1, 0, 0}
+	resp := []byte{eapaka.CodeResponse, 1, 0, 8, EAPTypeAKA, 5, 0, 0}
+	concat := append(append([]byte(nil), req...), resp...)
+
+	sha1Sum := sha1.Sum(concat)
+	sha256Sum := sha256.Sum256(concat)
+	tests := []struct {
+		name    string
+		eapType uint8
+		want    []byte
+	}{
+		{"EAP-AKAはSHA-1", EAPTypeAKA, sha1Sum[:]},
+		{"EAP-AKA'はSHA-256", EAPTypeAKAPrime, sha256Sum[:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCheckcode(tt.eapType)
+			c.Write(req)
+
+			// 途中状態を保存・復元しても同じ値になる
+			state, err := c.State()
+			if err != nil {
+				t.Fatalf("State() error: %v", err)
+			}
+			restored, err := RestoreCheckcode(state)
+			if err != nil {
+				t.Fatalf("RestoreCheckcode() error: %v", err)
+			}
+			restored.Write(resp)
+
+			if got := restored.Sum(); !bytes.Equal(got, tt.want) {
+				t.Errorf("Sum() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreCheckcode_Empty(t *testing.T) {
+	c, err := RestoreCheckcode("")
+	if err != nil || c != nil {
+		t.Fatalf("RestoreCheckcode(\"\") = (%v, %v), want (nil, nil)", c, err)
+	}
+	if c.Sum() != nil {
+		t.Error("nilのSum()はnilを返すべき")
+	}
+}
+
+func TestRestoreCheckcode_Invalid(t *testing.T) {
+	for _, state := range []string{"zz", "17", "1700112233"} {
+		if _, err := RestoreCheckcode(state); err == nil {
+			t.Errorf("RestoreCheckcode(%q) should fail", state)
+		}
+	}
+}
+
+func TestVerifyCheckcode_Missing(t *testing.T) {
+	pkt := &eapaka.Packet{Code: eapaka.CodeResponse, Type: eapaka.TypeAKA, Subtype: eapaka.SubtypeChallenge}
+	if err := VerifyCheckcode(pkt, nil); err != nil {
+		t.Errorf("Identity交換なし・AT_CHECKCODEなしは成功を期待: %v", err)
+	}
+	if err := VerifyCheckcode(pkt, make([]byte, 20)); !errors.Is(err, ErrCheckcodeMismatch) {
+		t.Errorf("ErrCheckcodeMismatchを期待: %v", err)
+	}
+}
@@ -19,7 +19,7 @@ const (
 	IdentityPrefixAKAPrimeReauth    = '8' // EAP-AKA'再認証ID
 )
 
-// EAP-SIM（非対応）
+// EAP-SIM（RFC 4186、仮名・再認証IDは発行しない）
 const (
 	IdentityPrefixSIMPermanent = '1' // EAP-SIM永続ID
 	IdentityPrefixSIMPseudonym = '3' // EAP-SIM仮名
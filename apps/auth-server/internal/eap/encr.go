@@ -0,0 +1,113 @@
+package eap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// encrBlockSize はAT_ENCR_DATAの暗号ブロック長（AES-128-CBC、RFC 4187 10.12）
+const encrBlockSize = aes.BlockSize
+
+// EncryptAttributes は属性列をK_encrでAES-128-CBC暗号化し、AT_IVとAT_ENCR_DATAを返す
+// 平文が16バイト境界に揃うようAT_PADDINGを付与する（RFC 4187 10.12）
+func EncryptAttributes(kEncr []byte, attrs []eapaka.Attribute) (*eapaka.AtIv, *eapaka.AtEncrData, error) {
+	plain, err := marshalAttributes(attrs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// AT_PADDING付与（長さは4/8/12バイトのいずれか）
+	if rem := len(plain) % encrBlockSize; rem != 0 {
+		padding, err := (&eapaka.AtPadding{Length: encrBlockSize - rem - 2}).Marshal()
+		if err != nil {
+			return nil, nil, err
+		}
+		plain = append(plain, padding...)
+	}
+
+	block, err := aes.NewCipher(kEncr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("eap: invalid K_encr: %w", err)
+	}
+
+	iv := make([]byte, encrBlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, fmt.Errorf("eap: failed to generate IV: %w", err)
+	}
+
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+
+	return &eapaka.AtIv{IV: iv}, &eapaka.AtEncrData{EncryptedData: encrypted}, nil
+}
+
+// DecryptAttributes はAT_IV/AT_ENCR_DATAをK_encrで復号し、内包された属性列を返す
+// AT_PADDINGは結果から除外する
+func DecryptAttributes(kEncr []byte, iv *eapaka.AtIv, encr *eapaka.AtEncrData) ([]eapaka.Attribute, error) {
+	if iv == nil || encr == nil {
+		return nil, ErrEncrDataInvalid
+	}
+	if len(iv.IV) != encrBlockSize || len(encr.EncryptedData) == 0 || len(encr.EncryptedData)%encrBlockSize != 0 {
+		return nil, ErrEncrDataInvalid
+	}
+
+	block, err := aes.NewCipher(kEncr)
+	if err != nil {
+		return nil, fmt.Errorf("eap: invalid K_encr: %w", err)
+	}
+
+	plain := make([]byte, len(encr.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv.IV).CryptBlocks(plain, encr.EncryptedData)
+
+	attrs, err := unmarshalAttributes(plain)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEncrDataInvalid, err)
+	}
+
+	result := make([]eapaka.Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Type() == eapaka.AT_PADDING {
+			continue
+		}
+		result = append(result, attr)
+	}
+	return result, nil
+}
+
+// marshalAttributes は属性列をワイヤ形式にエンコードする
+// go-eapakaは属性単体のエンコードを公開していないため、パケットとしてエンコードして本体を切り出す
+func marshalAttributes(attrs []eapaka.Attribute) ([]byte, error) {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeRequest,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeChallenge,
+		Attributes: attrs,
+	}
+	data, err := pkt.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	// EAPヘッダ(4) + Type(1) + Subtype(1) + Reserved(2)
+	return data[8:], nil
+}
+
+// unmarshalAttributes はワイヤ形式の属性列をデコードする
+func unmarshalAttributes(data []byte) ([]eapaka.Attribute, error) {
+	buf := make([]byte, 8+len(data))
+	buf[0] = eapaka.CodeResponse
+	binary.BigEndian.PutUint16(buf[2:4], uint16(len(buf)))
+	buf[4] = eapaka.TypeAKA
+	buf[5] = eapaka.SubtypeChallenge
+	copy(buf[8:], data)
+
+	pkt, err := eapaka.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	return pkt.Attributes, nil
+}
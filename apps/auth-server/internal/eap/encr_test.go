@@ -0,0 +1,99 @@
+package eap
+
+import (
+	"errors"
+	"testing"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+func testKEncr() []byte {
+	k := make([]byte, 16)
+	for i := range k {
+		k[i] = byte(i + 1)
+	}
+	return k
+}
+
+func TestEncryptAttributes_RoundTrip(t *testing.T) {
+	// 各パディング長（0/4/8/12バイト）を網羅するよう名前長を変える
+	for _, name := range []string{"2a", "2abcdefgh", "2abcdefghijkl", "2abcdefghijklmnop"} {
+		t.Run(name, func(t *testing.T) {
+			kEncr := testKEncr()
+			atIv, atEncr, err := EncryptAttributes(kEncr, []eapaka.Attribute{
+				&eapaka.AtNextPseudonym{Pseudonym: name},
+			})
+			if err != nil {
+				t.Fatalf("暗号化失敗: %v", err)
+			}
+			if len(atEncr.EncryptedData)%16 != 0 {
+				t.Errorf("暗号文長が16の倍数ではない: %d", len(atEncr.EncryptedData))
+			}
+
+			attrs, err := DecryptAttributes(kEncr, atIv, atEncr)
+			if err != nil {
+				t.Fatalf("復号失敗: %v", err)
+			}
+			if len(attrs) != 1 {
+				t.Fatalf("属性数: got %d, want 1", len(attrs))
+			}
+			next, ok := attrs[0].(*eapaka.AtNextPseudonym)
+			if !ok || next.Pseudonym != name {
+				t.Errorf("復号結果が不正: %+v", attrs[0])
+			}
+		})
+	}
+}
+
+func TestDecryptAttributes_InvalidLength(t *testing.T) {
+	_, err := DecryptAttributes(testKEncr(), &eapaka.AtIv{IV: make([]byte, 16)}, &eapaka.AtEncrData{EncryptedData: make([]byte, 15)})
+	if !errors.Is(err, ErrEncrDataInvalid) {
+		t.Errorf("ErrEncrDataInvalidが返るべき: got %v", err)
+	}
+}
+
+func TestDecryptAttributes_WrongKey(t *testing.T) {
+	atIv, atEncr, err := EncryptAttributes(testKEncr(), []eapaka.Attribute{
+		&eapaka.AtNextPseudonym{Pseudonym: "2abcdef"},
+	})
+	if err != nil {
+		t.Fatalf("暗号化失敗: %v", err)
+	}
+
+	wrongKey := make([]byte, 16)
+	attrs, err := DecryptAttributes(wrongKey, atIv, atEncr)
+	if err == nil {
+		// 誤った鍵で偶然パースできても元の属性は得られない
+		for _, a := range attrs {
+			if next, ok := a.(*eapaka.AtNextPseudonym); ok && next.Pseudonym == "2abcdef" {
+				t.Error("誤った鍵で元の値が復号された")
+			}
+		}
+	}
+}
+
+func TestChallengeOptions_NilAndEmpty(t *testing.T) {
+	var opts *ChallengeOptions
+	attrs, err := opts.Attributes()
+	if err != nil || attrs != nil {
+		t.Errorf("nilオプションは属性なし: attrs=%v, err=%v", attrs, err)
+	}
+
+	attrs, err = (&ChallengeOptions{KEncr: testKEncr()}).Attributes()
+	if err != nil || attrs != nil {
+		t.Errorf("空オプションは属性なし: attrs=%v, err=%v", attrs, err)
+	}
+}
+
+func TestChallengeOptions_ResultInd(t *testing.T) {
+	attrs, err := (&ChallengeOptions{KEncr: testKEncr(), ResultInd: true}).Attributes()
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if len(attrs) != 1 {
+		t.Fatalf("属性数: got %d, want 1", len(attrs))
+	}
+	if _, ok := attrs[0].(*eapaka.AtResultInd); !ok {
+		t.Errorf("AT_RESULT_INDではない: %T", attrs[0])
+	}
+}
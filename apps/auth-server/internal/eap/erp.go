@@ -0,0 +1,285 @@
+package eap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// ERPのEAP Code（RFC 6696 Section 5.3）
+const (
+	CodeInitiate uint8 = 5 // EAP-Initiate
+	CodeFinish   uint8 = 6 // EAP-Finish
+)
+
+// ERPのメッセージType（RFC 6696 Section 5.3.1/5.3.2）
+const (
+	ERPTypeReauthStart uint8 = 1 // Re-auth-Start
+	ERPTypeReauth      uint8 = 2 // Re-auth
+)
+
+// ERPフラグ（RFC 6696 Section 5.3.2/5.3.3）
+const (
+	ERPFlagResult    uint8 = 0x80 // EAP-Finish: 'R'（1=失敗）
+	ERPFlagBootstrap uint8 = 0x40 // 'B'（明示的ブートストラップ）
+	ERPFlagLifetime  uint8 = 0x20 // 'L'（鍵有効期間の通知要求）
+)
+
+// ERPのTV/TLV Type（RFC 6696 Section 5.3.4）
+const (
+	ERPTLVKeyNameNAI      uint8 = 1 // keyName-NAI（TLV）
+	ERPTVRRKLifetime      uint8 = 2 // rRK Lifetime（TV、4バイト）
+	ERPTVRMSKLifetime     uint8 = 3 // rMSK Lifetime（TV、4バイト）
+	ERPTLVDomainName      uint8 = 4 // Domain-Name（TLV）
+	ERPTLVCryptosuiteList uint8 = 5 // Cryptosuite List（TLV）
+)
+
+// ERP Cryptosuite（RFC 6696 Section 5.3.5）
+const (
+	ERPCryptosuiteHMACSHA256_64  uint8 = 1
+	ERPCryptosuiteHMACSHA256_128 uint8 = 2 // 実装必須
+	ERPCryptosuiteHMACSHA256_256 uint8 = 3
+)
+
+// ERPSupportedCryptosuites はサーバーが受け付けるCryptosuite（優先順）
+// 64ビットのタグは強度不足のため受け付けない
+var ERPSupportedCryptosuites = []uint8{ERPCryptosuiteHMACSHA256_128, ERPCryptosuiteHMACSHA256_256}
+
+// ERP鍵長（RFC 6696 Section 4.1/4.3/4.6、RFC 5295 Section 3.2）
+const (
+	ERPKeyNameLength = 8  // EMSKname（rRK/rIKの名前）
+	ERPRRKLength     = 64 // rRK（EMSK長）
+	ERPRIKLength     = 32 // rIK（HMAC-SHA256の鍵長）
+	ERPRMSKLength    = 64 // rMSK
+)
+
+// ERP鍵導出ラベル
+const (
+	erpLabelEMSKName = "EMSK"
+	erpLabelRRK      = "EAP Re-authentication Root Key@ietf.org"
+	erpLabelRIK      = "Re-authentication Integrity Key@ietf.org"
+	erpLabelRMSK     = "Re-authentication Master Session Key@ietf.org"
+)
+
+// erpHeaderLength はCode/Identifier/Length/Type/Flags/SEQのバイト長
+const erpHeaderLength = 8
+
+// ERPReauth はEAP-Initiate/Re-authの解析結果を保持する（RFC 6696 Section 5.3.2）
+type ERPReauth struct {
+	Identifier  uint8
+	Flags       uint8
+	SEQ         uint16
+	KeyNameNAI  string
+	Cryptosuite uint8
+	signed      []byte // 認証タグの計算対象（CodeからCryptosuiteまで）
+	tag         []byte
+}
+
+// KeyName はkeyName-NAIのユーザー名部（EMSKnameの16進表記）を返す
+func (r *ERPReauth) KeyName() string {
+	name, _, _ := strings.Cut(r.KeyNameNAI, "@")
+	return name
+}
+
+// Realm はkeyName-NAIのRealm部（ERPドメイン名）を返す
+func (r *ERPReauth) Realm() string {
+	_, realm, _ := strings.Cut(r.KeyNameNAI, "@")
+	return realm
+}
+
+// ERPFinishParams はEAP-Finish/Re-authの構築パラメータを保持する（RFC 6696 Section 5.3.3）
+type ERPFinishParams struct {
+	Identifier   uint8 // EAP-Initiate/Re-authと同一
+	Flags        uint8 // 'R'/'B'/'L'
+	SEQ          uint16
+	KeyNameNAI   string
+	DomainName   string  // 空の場合は送信しない
+	RRKLifetime  uint32  // 'L'設定時のみ送信（秒）
+	RMSKLifetime uint32  // 'L'設定時のみ送信（秒）
+	Cryptosuites []uint8 // Cryptosuite不一致時に通知する一覧（空の場合は送信しない）
+	Cryptosuite  uint8
+	RIK          []byte
+}
+
+// ERPTagLength はCryptosuiteに対応する認証タグ長を返す。未知の場合は0を返す
+func ERPTagLength(cryptosuite uint8) int {
+	switch cryptosuite {
+	case ERPCryptosuiteHMACSHA256_64:
+		return 8
+	case ERPCryptosuiteHMACSHA256_128:
+		return 16
+	case ERPCryptosuiteHMACSHA256_256:
+		return 32
+	}
+	return 0
+}
+
+// IsERPSupportedCryptosuite はCryptosuiteを受け付けるかどうかを返す
+func IsERPSupportedCryptosuite(cryptosuite uint8) bool {
+	for _, c := range ERPSupportedCryptosuites {
+		if c == cryptosuite {
+			return true
+		}
+	}
+	return false
+}
+
+// IsERPInitiate はEAPパケットがEAP-Initiateかどうかを返す
+func IsERPInitiate(data []byte) bool {
+	return len(data) >= 1 && data[0] == CodeInitiate
+}
+
+// ParseERPReauth はEAP-Initiate/Re-authを解析する
+// Cryptosuiteと認証タグは末尾から判定し、TV/TLV領域がちょうど解析できる組み合わせを採用する
+func ParseERPReauth(data []byte) (*ERPReauth, error) {
+	if len(data) < erpHeaderLength || data[0] != CodeInitiate {
+		return nil, fmt.Errorf("eap: not an EAP-Initiate packet")
+	}
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	if length < erpHeaderLength || length > len(data) {
+		return nil, fmt.Errorf("eap: invalid EAP-Initiate length: %d", length)
+	}
+	data = data[:length]
+	if data[4] != ERPTypeReauth {
+		return nil, fmt.Errorf("eap: unsupported EAP-Initiate type: %d", data[4])
+	}
+
+	for _, cs := range []uint8{ERPCryptosuiteHMACSHA256_64, ERPCryptosuiteHMACSHA256_128, ERPCryptosuiteHMACSHA256_256} {
+		tagLen := ERPTagLength(cs)
+		csPos := length - tagLen - 1
+		if csPos < erpHeaderLength || data[csPos] != cs {
+			continue
+		}
+		keyNameNAI, ok := parseERPTLVs(data[erpHeaderLength:csPos])
+		if !ok {
+			continue
+		}
+		if keyNameNAI == "" {
+			return nil, fmt.Errorf("eap: keyName-NAI not found")
+		}
+		return &ERPReauth{
+			Identifier:  data[1],
+			Flags:       data[5],
+			SEQ:         binary.BigEndian.Uint16(data[6:8]),
+			KeyNameNAI:  keyNameNAI,
+			Cryptosuite: cs,
+			signed:      data[:csPos+1],
+			tag:         data[csPos+1:],
+		}, nil
+	}
+	return nil, fmt.Errorf("eap: invalid EAP-Initiate/Re-auth payload")
+}
+
+// parseERPTLVs はTV/TLV領域を解析してkeyName-NAIを返す
+// 領域の末尾で過不足なく終わらない場合はfalseを返す
+func parseERPTLVs(b []byte) (string, bool) {
+	var keyNameNAI string
+	for len(b) > 0 {
+		switch b[0] {
+		case ERPTVRRKLifetime, ERPTVRMSKLifetime:
+			if len(b) < 5 {
+				return "", false
+			}
+			b = b[5:]
+		default:
+			if len(b) < 2 || len(b) < 2+int(b[1]) {
+				return "", false
+			}
+			if b[0] == ERPTLVKeyNameNAI {
+				keyNameNAI = string(b[2 : 2+int(b[1])])
+			}
+			b = b[2+int(b[1]):]
+		}
+	}
+	return keyNameNAI, true
+}
+
+// VerifyERPReauth はEAP-Initiate/Re-authの認証タグをrIKで検証する（RFC 6696 Section 5.3.2）
+func VerifyERPReauth(r *ERPReauth, rIK []byte) error {
+	expected := erpTag(rIK, r.signed, len(r.tag))
+	if !hmac.Equal(expected, r.tag) {
+		return ErrERPTagInvalid
+	}
+	return nil
+}
+
+// BuildERPFinish はEAP-Finish/Re-authパケットを構築する（RFC 6696 Section 5.3.3）
+func BuildERPFinish(p *ERPFinishParams) ([]byte, error) {
+	tagLen := ERPTagLength(p.Cryptosuite)
+	if tagLen == 0 {
+		return nil, fmt.Errorf("eap: unsupported ERP cryptosuite: %d", p.Cryptosuite)
+	}
+	if len(p.KeyNameNAI) > 255 || len(p.DomainName) > 255 || len(p.Cryptosuites) > 255 {
+		return nil, fmt.Errorf("eap: ERP TLV too long")
+	}
+
+	buf := []byte{CodeFinish, p.Identifier, 0, 0, ERPTypeReauth, p.Flags}
+	buf = binary.BigEndian.AppendUint16(buf, p.SEQ)
+	buf = append(buf, ERPTLVKeyNameNAI, byte(len(p.KeyNameNAI)))
+	buf = append(buf, p.KeyNameNAI...)
+	if p.Flags&ERPFlagLifetime != 0 && p.Flags&ERPFlagResult == 0 {
+		buf = append(buf, ERPTVRRKLifetime)
+		buf = binary.BigEndian.AppendUint32(buf, p.RRKLifetime)
+		buf = append(buf, ERPTVRMSKLifetime)
+		buf = binary.BigEndian.AppendUint32(buf, p.RMSKLifetime)
+	}
+	if p.DomainName != "" {
+		buf = append(buf, ERPTLVDomainName, byte(len(p.DomainName)))
+		buf = append(buf, p.DomainName...)
+	}
+	if len(p.Cryptosuites) > 0 {
+		buf = append(buf, ERPTLVCryptosuiteList, byte(len(p.Cryptosuites)))
+		buf = append(buf, p.Cryptosuites...)
+	}
+	buf = append(buf, p.Cryptosuite)
+
+	binary.BigEndian.PutUint16(buf[2:4], uint16(len(buf)+tagLen))
+	return append(buf, erpTag(p.RIK, buf, tagLen)...), nil
+}
+
+// erpTag はHMAC-SHA256を指定長に切り詰めた認証タグを計算する
+func erpTag(rIK, msg []byte, tagLen int) []byte {
+	h := hmac.New(sha256.New, rIK)
+	h.Write(msg)
+	return h.Sum(nil)[:tagLen]
+}
+
+// erpKDF はRFC 5295 Section 3.1のKDF（HMAC-SHA-256ベースのPRF+）でlengthバイトを導出する
+// S = label | "\0" | optional data | length（2バイト）
+func erpKDF(key []byte, label string, optional []byte, length int) []byte {
+	seed := append([]byte(label), 0)
+	seed = append(seed, optional...)
+	seed = binary.BigEndian.AppendUint16(seed, uint16(length))
+	return PRFPlus(key, seed, length)
+}
+
+// DeriveEMSKName はEAP Session-IDからEMSKname（rRK名）を導出する（RFC 5295 Section 3.2）
+func DeriveEMSKName(sessionID []byte) []byte {
+	return erpKDF(sessionID, erpLabelEMSKName, nil, ERPKeyNameLength)
+}
+
+// DeriveRRK はEMSKからrRKを導出する（RFC 6696 Section 4.1）
+func DeriveRRK(emsk []byte) []byte {
+	return erpKDF(emsk, erpLabelRRK, nil, ERPRRKLength)
+}
+
+// DeriveRIK はrRKからCryptosuite用のrIKを導出する（RFC 6696 Section 4.3）
+func DeriveRIK(rRK []byte, cryptosuite uint8) []byte {
+	return erpKDF(rRK, erpLabelRIK, []byte{cryptosuite}, ERPRIKLength)
+}
+
+// DeriveRMSK はrRKとSEQからrMSKを導出する（RFC 6696 Section 4.6）
+func DeriveRMSK(rRK []byte, seq uint16) []byte {
+	return erpKDF(rRK, erpLabelRMSK, binary.BigEndian.AppendUint16(nil, seq), ERPRMSKLength)
+}
+
+// SessionID はEAP方式のSession-IDを構築する（RFC 5247 Appendix A、RFC 5448 Section 3.4）
+// EAP-AKA/AKA': Type | RAND | AUTN、EAP-SIM: Type | n*RAND | NONCE_MT
+func SessionID(eapType uint8, rand, second []byte) []byte {
+	id := make([]byte, 0, 1+len(rand)+len(second))
+	id = append(id, eapType)
+	id = append(id, rand...)
+	return append(id, second...)
+}
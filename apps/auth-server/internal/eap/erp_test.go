@@ -0,0 +1,198 @@
+package eap
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// buildTestERPReauth はピア側のEAP-Initiate/Re-authを構築する
+func buildTestERPReauth(identifier, flags uint8, seq uint16, keyNameNAI string, cryptosuite uint8, rIK []byte) []byte {
+	buf := []byte{CodeInitiate, identifier, 0, 0, ERPTypeReauth, flags}
+	buf = binary.BigEndian.AppendUint16(buf, seq)
+	buf = append(buf, ERPTLVKeyNameNAI, byte(len(keyNameNAI)))
+	buf = append(buf, keyNameNAI...)
+	buf = append(buf, cryptosuite)
+	tagLen := ERPTagLength(cryptosuite)
+	binary.BigEndian.PutUint16(buf[2:4], uint16(len(buf)+tagLen))
+	h := hmac.New(sha256.New, rIK)
+	h.Write(buf)
+	return append(buf, h.Sum(nil)[:tagLen]...)
+}
+
+func TestParseERPReauth(t *testing.T) {
+	rIK := bytes.Repeat([]byte{0x5a}, ERPRIKLength)
+	for _, cs := range []uint8{ERPCryptosuiteHMACSHA256_64, ERPCryptosuiteHMACSHA256_128, ERPCryptosuiteHMACSHA256_256} {
+		msg := buildTestERPReauth(7, ERPFlagLifetime, 3, "0123456789abcdef@erp.example.net", cs, rIK)
+
+		r, err := ParseERPReauth(msg)
+		if err != nil {
+			t.Fatalf("cryptosuite %d: ParseERPReauth() error = %v", cs, err)
+		}
+		if r.Identifier != 7 || r.Flags != ERPFlagLifetime || r.SEQ != 3 || r.Cryptosuite != cs {
+			t.Errorf("cryptosuite %d: got %+v", cs, r)
+		}
+		if r.KeyName() != "0123456789abcdef" || r.Realm() != "erp.example.net" {
+			t.Errorf("keyName-NAI: got (%q, %q)", r.KeyName(), r.Realm())
+		}
+		if err := VerifyERPReauth(r, rIK); err != nil {
+			t.Errorf("cryptosuite %d: VerifyERPReauth() error = %v", cs, err)
+		}
+	}
+}
+
+func TestVerifyERPReauth_TagInvalid(t *testing.T) {
+	rIK := bytes.Repeat([]byte{0x5a}, ERPRIKLength)
+	msg := buildTestERPReauth(1, 0, 0, "0123456789abcdef@erp.example.net", ERPCryptosuiteHMACSHA256_128, rIK)
+	msg[len(msg)-1] ^= 0xff
+
+	r, err := ParseERPReauth(msg)
+	if err != nil {
+		t.Fatalf("ParseERPReauth() error = %v", err)
+	}
+	if err := VerifyERPReauth(r, rIK); !errors.Is(err, ErrERPTagInvalid) {
+		t.Errorf("VerifyERPReauth() error = %v, want ErrERPTagInvalid", err)
+	}
+}
+
+func TestParseERPReauth_Invalid(t *testing.T) {
+	rIK := bytes.Repeat([]byte{0x5a}, ERPRIKLength)
+	valid := buildTestERPReauth(1, 0, 0, "0123456789abcdef@erp.example.net", ERPCryptosuiteHMACSHA256_128, rIK)
+
+	reauthStart := append([]byte(nil), valid...)
+	reauthStart[4] = ERPTypeReauthStart
+
+	notInitiate := append([]byte(nil), valid...)
+	notInitiate[0] = CodeFinish
+
+	badLength := append([]byte(nil), valid...)
+	binary.BigEndian.PutUint16(badLength[2:4], uint16(len(valid)+1))
+
+	noKeyName := []byte{CodeInitiate, 1, 0, 0, ERPTypeReauth, 0, 0, 0, ERPTLVDomainName, 1, 'x', ERPCryptosuiteHMACSHA256_128}
+	binary.BigEndian.PutUint16(noKeyName[2:4], uint16(len(noKeyName)+16))
+	noKeyName = append(noKeyName, make([]byte, 16)...)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "short", data: valid[:6]},
+		{name: "not initiate", data: notInitiate},
+		{name: "reauth-start", data: reauthStart},
+		{name: "length exceeds data", data: badLength},
+		{name: "truncated tag", data: valid[:len(valid)-4]},
+		{name: "no keyName-NAI", data: noKeyName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseERPReauth(tt.data); err == nil {
+				t.Error("ParseERPReauth() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestBuildERPFinish(t *testing.T) {
+	rIK := bytes.Repeat([]byte{0x5a}, ERPRIKLength)
+	msg, err := BuildERPFinish(&ERPFinishParams{
+		Identifier:   9,
+		Flags:        ERPFlagLifetime,
+		SEQ:          4,
+		KeyNameNAI:   "0123456789abcdef@erp.example.net",
+		DomainName:   "erp.example.net",
+		RRKLifetime:  3600,
+		RMSKLifetime: 1800,
+		Cryptosuite:  ERPCryptosuiteHMACSHA256_128,
+		RIK:          rIK,
+	})
+	if err != nil {
+		t.Fatalf("BuildERPFinish() error = %v", err)
+	}
+
+	if msg[0] != CodeFinish || msg[1] != 9 || msg[4] != ERPTypeReauth || msg[5] != ERPFlagLifetime {
+		t.Errorf("header: got % x", msg[:6])
+	}
+	if int(binary.BigEndian.Uint16(msg[2:4])) != len(msg) {
+		t.Errorf("Length: got %d, want %d", binary.BigEndian.Uint16(msg[2:4]), len(msg))
+	}
+	if binary.BigEndian.Uint16(msg[6:8]) != 4 {
+		t.Errorf("SEQ: got %d, want 4", binary.BigEndian.Uint16(msg[6:8]))
+	}
+	lifetimes := []byte{ERPTVRRKLifetime, 0, 0, 0x0e, 0x10, ERPTVRMSKLifetime, 0, 0, 0x07, 0x08}
+	if !bytes.Contains(msg, lifetimes) {
+		t.Error("rRK/rMSK Lifetime TVが含まれない")
+	}
+	if !bytes.Contains(msg, append([]byte{ERPTLVDomainName, 15}, "erp.example.net"...)) {
+		t.Error("Domain-Name TLVが含まれない")
+	}
+
+	h := hmac.New(sha256.New, rIK)
+	h.Write(msg[:len(msg)-16])
+	if !bytes.Equal(msg[len(msg)-16:], h.Sum(nil)[:16]) {
+		t.Error("認証タグが一致しない")
+	}
+	if msg[len(msg)-17] != ERPCryptosuiteHMACSHA256_128 {
+		t.Errorf("Cryptosuite: got %d", msg[len(msg)-17])
+	}
+}
+
+func TestBuildERPFinish_FailureOmitsLifetime(t *testing.T) {
+	msg, err := BuildERPFinish(&ERPFinishParams{
+		Flags:        ERPFlagResult | ERPFlagLifetime,
+		KeyNameNAI:   "0123456789abcdef@erp.example.net",
+		RRKLifetime:  3600,
+		Cryptosuites: ERPSupportedCryptosuites,
+		Cryptosuite:  ERPCryptosuiteHMACSHA256_128,
+		RIK:          bytes.Repeat([]byte{0x5a}, ERPRIKLength),
+	})
+	if err != nil {
+		t.Fatalf("BuildERPFinish() error = %v", err)
+	}
+	if bytes.Contains(msg, []byte{ERPTVRRKLifetime, 0, 0, 0x0e, 0x10}) {
+		t.Error("失敗時にLifetime TVを送信した")
+	}
+	if !bytes.Contains(msg, []byte{ERPTLVCryptosuiteList, 2, ERPCryptosuiteHMACSHA256_128, ERPCryptosuiteHMACSHA256_256}) {
+		t.Error("Cryptosuite List TLVが含まれない")
+	}
+}
+
+func TestBuildERPFinish_UnknownCryptosuite(t *testing.T) {
+	if _, err := BuildERPFinish(&ERPFinishParams{KeyNameNAI: "x@y", Cryptosuite: 9}); err == nil {
+		t.Error("BuildERPFinish() error = nil, want error")
+	}
+}
+
+func TestERPKeyDerivation(t *testing.T) {
+	emsk := bytes.Repeat([]byte{0x11}, 64)
+
+	rRK := DeriveRRK(emsk)
+	wantRRK := PRFPlus(emsk, append([]byte("EAP Re-authentication Root Key@ietf.org\x00"), 0, 64), 64)
+	if !bytes.Equal(rRK, wantRRK) {
+		t.Error("rRKがRFC 5295のKDFと一致しない")
+	}
+
+	rIK128 := DeriveRIK(rRK, ERPCryptosuiteHMACSHA256_128)
+	rIK256 := DeriveRIK(rRK, ERPCryptosuiteHMACSHA256_256)
+	if len(rIK128) != ERPRIKLength || bytes.Equal(rIK128, rIK256) {
+		t.Error("rIKがCryptosuiteごとに導出されていない")
+	}
+
+	rMSK1 := DeriveRMSK(rRK, 1)
+	rMSK2 := DeriveRMSK(rRK, 2)
+	if len(rMSK1) != ERPRMSKLength || bytes.Equal(rMSK1, rMSK2) {
+		t.Error("rMSKがSEQごとに導出されていない")
+	}
+
+	sessionID := SessionID(EAPTypeAKA, bytes.Repeat([]byte{0x22}, 16), bytes.Repeat([]byte{0x33}, 16))
+	if len(sessionID) != 33 || sessionID[0] != EAPTypeAKA {
+		t.Errorf("SessionID: got % x", sessionID)
+	}
+	name := DeriveEMSKName(sessionID)
+	wantName := PRFPlus(sessionID, []byte("EMSK\x00\x00\x08"), 8)
+	if !bytes.Equal(name, wantName) {
+		t.Error("EMSKnameがRFC 5295のKDFと一致しない")
+	}
+}
@@ -1,6 +1,9 @@
 package eap
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Identity解析エラー
 var (
@@ -12,6 +15,9 @@ var (
 
 	// ErrMissingRealm はIdentityにRealmが含まれていない場合のエラー
 	ErrMissingRealm = errors.New("missing realm in identity")
+
+	// ErrUnrecognizedIdentity はIdentityが3GPPのプレフィックスで始まらない場合のエラー（ErrInvalidIdentityの一種）
+	ErrUnrecognizedIdentity = fmt.Errorf("%w: no 3GPP identity prefix", ErrInvalidIdentity)
 )
 
 // Challenge検証エラー
@@ -27,6 +33,9 @@ var (
 
 	// ErrRESMismatch はAT_RESの値が一致しない場合のエラー
 	ErrRESMismatch = errors.New("AT_RES mismatch")
+
+	// ErrCheckcodeMismatch はAT_CHECKCODEがサーバーで計算したAKA-Identityメッセージのハッシュと一致しない場合のエラー
+	ErrCheckcodeMismatch = errors.New("AT_CHECKCODE mismatch")
 )
 
 // AT_KDFエラー
@@ -58,3 +67,33 @@ var (
 	// ErrEAPParseFailed はEAPパケットのパースに失敗した場合のエラー
 	ErrEAPParseFailed = errors.New("eap packet parse failed")
 )
+
+// 暗号化属性エラー
+var (
+	// ErrEncrDataInvalid はAT_IV/AT_ENCR_DATAの形式または復号結果が不正な場合のエラー
+	ErrEncrDataInvalid = errors.New("invalid AT_ENCR_DATA")
+)
+
+// EAP-SIMエラー
+var (
+	// ErrNonceMTNotFound はSIM/Start応答にAT_NONCE_MTが含まれない場合のエラー
+	ErrNonceMTNotFound = errors.New("AT_NONCE_MT not found")
+
+	// ErrSIMVersionUnsupported はAT_SELECTED_VERSIONが未設定または非対応の場合のエラー
+	ErrSIMVersionUnsupported = errors.New("unsupported EAP-SIM version")
+
+	// ErrRANDCountInvalid はRAND（トリプレット）の個数が2〜3でない場合のエラー
+	ErrRANDCountInvalid = errors.New("invalid number of RAND challenges")
+)
+
+// 再認証エラー
+var (
+	// ErrCounterMismatch はAT_COUNTERが送信値と一致しない場合のエラー
+	ErrCounterMismatch = errors.New("AT_COUNTER mismatch")
+)
+
+// ERPエラー
+var (
+	// ErrERPTagInvalid はEAP-Initiate/Re-authの認証タグの検証に失敗した場合のエラー
+	ErrERPTagInvalid = errors.New("ERP authentication tag verification failed")
+)
@@ -1,8 +1,10 @@
 package eap
 
 import (
+	"errors"
 	"strings"
 
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/suci"
 	eapaka "github.com/oyaguma3/go-eapaka"
 )
 
@@ -16,17 +18,23 @@ const (
 	IdentityTypePermanentAKAPrime                     // '6' EAP-AKA'永続ID
 	IdentityTypePseudonymAKAPrime                     // '7' EAP-AKA'仮名
 	IdentityTypeReauthAKAPrime                        // '8' EAP-AKA'再認証ID
-	IdentityTypeUnsupported                           // EAP-SIM等の非対応種別
+	IdentityTypeUnsupported                           // 非対応種別
 	IdentityTypeInvalid                               // 不正形式
+	IdentityTypePermanentSIM                          // '1' EAP-SIM永続ID
+	IdentityTypePseudonymSIM                          // '3' EAP-SIM仮名
+	IdentityTypeReauthSIM                             // '5' EAP-SIM再認証ID
+	IdentityTypeConcealed                             // SUCI形式の秘匿化ID（復号前）
 )
 
 // ParsedIdentity はパース済みのIdentity情報を保持する
 type ParsedIdentity struct {
-	Type    IdentityType // Identity種別
-	IMSI    string       // 永続IDの場合のIMSI（先頭プレフィックス除く）
-	Raw     string       // 元のIdentity文字列
-	Realm   string       // @以降の部分
-	EAPType uint8        // eapaka.TypeAKA(23) or eapaka.TypeAKAPrime(50)
+	Type     IdentityType   // Identity種別
+	IMSI     string         // 永続IDの場合のIMSI（先頭プレフィックス除く）
+	Raw      string         // 元のIdentity文字列
+	UserPart string         // @より前の部分（仮名・再認証IDの検索キー）
+	Realm    string         // @以降の部分
+	EAPType  uint8          // eapaka.TypeAKA(23), eapaka.TypeAKAPrime(50) or EAPTypeSIM(18)
+	SUCI     *suci.Identity // 秘匿化IDの場合の解析結果
 }
 
 // ParseIdentity はIdentity文字列を解析してParsedIdentityを返す
@@ -46,12 +54,30 @@ func ParseIdentity(identity string) (*ParsedIdentity, error) {
 		return nil, ErrInvalidIdentity
 	}
 
-	prefix := byte(userPart[0])
 	parsed := &ParsedIdentity{
-		Raw:   identity,
-		Realm: realm,
+		Raw:      identity,
+		UserPart: userPart,
+		Realm:    realm,
 	}
 
+	// SUCI形式（TS 23.003 Section 28.7.3）。方式を示すプレフィックスを持たないため、
+	// EAP方式は既定でEAP-AKA'とし、IMSIは復号後に設定する
+	if suci.IsNAI(userPart) {
+		id, err := suci.ParseNAI(identity)
+		if err != nil {
+			if errors.Is(err, suci.ErrUnsupportedScheme) {
+				return nil, ErrUnsupportedIdentity
+			}
+			return nil, ErrInvalidIdentity
+		}
+		parsed.Type = IdentityTypeConcealed
+		parsed.EAPType = eapaka.TypeAKAPrime
+		parsed.SUCI = id
+		return parsed, nil
+	}
+
+	prefix := byte(userPart[0])
+
 	switch prefix {
 	case byte(IdentityPrefixAKAPermanent): // '0'
 		parsed.Type = IdentityTypePermanentAKA
@@ -73,17 +99,71 @@ func ParseIdentity(identity string) (*ParsedIdentity, error) {
 	case byte(IdentityPrefixAKAPrimeReauth): // '8'
 		parsed.Type = IdentityTypeReauthAKAPrime
 		parsed.EAPType = eapaka.TypeAKAPrime
-	case byte(IdentityPrefixSIMPermanent), // '1'
-		byte(IdentityPrefixSIMPseudonym), // '3'
-		byte(IdentityPrefixSIMReauth):    // '5'
-		return nil, ErrUnsupportedIdentity
+	case byte(IdentityPrefixSIMPermanent): // '1'
+		parsed.Type = IdentityTypePermanentSIM
+		parsed.IMSI = userPart[1:]
+		parsed.EAPType = EAPTypeSIM
+	case byte(IdentityPrefixSIMPseudonym): // '3'
+		parsed.Type = IdentityTypePseudonymSIM
+		parsed.EAPType = EAPTypeSIM
+	case byte(IdentityPrefixSIMReauth): // '5'
+		parsed.Type = IdentityTypeReauthSIM
+		parsed.EAPType = EAPTypeSIM
 	default:
-		return nil, ErrInvalidIdentity
+		return nil, ErrUnrecognizedIdentity
 	}
 
 	return parsed, nil
 }
 
+// anonymousUserPart は匿名Identityとして扱うユーザー部（RFC 7542 Section 2.4）
+const anonymousUserPart = "anonymous"
+
+// IsAnonymousIdentity はIdentityが未設定または匿名（"anonymous@realm"、"@realm"等）かどうかを判定する
+// 匿名の場合はAT_ANY_ID_REQで改めてIdentityを要求する
+func IsAnonymousIdentity(identity string) bool {
+	userPart, _, _ := strings.Cut(identity, "@")
+	return userPart == "" || strings.EqualFold(userPart, anonymousUserPart)
+}
+
+// IsUnrecognizedNAI は3GPPのプレフィックスを持たないが、NAIとして正しい形式（RFC 7542）のIdentityかどうかを判定する
+// 端末のプロファイルが送信する任意の外側Identityを想定し、AKA-Identity交換で改めてIdentityを要求する対象とする
+func IsUnrecognizedNAI(identity string) bool {
+	if _, err := ParseIdentity(identity); !errors.Is(err, ErrUnrecognizedIdentity) {
+		return false
+	}
+	userPart, realm, _ := strings.Cut(identity, "@")
+	return isValidNAIUserPart(userPart) && isValidNAIRealm(realm)
+}
+
+// isValidNAIUserPart はNAIのユーザー部に空白・制御文字を含まないかを判定する
+func isValidNAIUserPart(userPart string) bool {
+	for _, c := range userPart {
+		if c <= ' ' || c == 0x7f {
+			return false
+		}
+	}
+	return userPart != ""
+}
+
+// isValidNAIRealm はRealmが英数字・ハイフンからなるラベルの並びかを判定する（RFC 7542 Section 2.2）
+func isValidNAIRealm(realm string) bool {
+	if realm == "" {
+		return false
+	}
+	for label := range strings.SplitSeq(realm, ".") {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // RequiresFullAuth は仮名IDまたは再認証IDの場合にtrueを返す
 // フル認証への誘導が必要かどうかの判定に使用する
 func (p *ParsedIdentity) RequiresFullAuth() bool {
@@ -103,9 +183,44 @@ func (p *ParsedIdentity) IsPermanent() bool {
 	return p.Type == IdentityTypePermanentAKA || p.Type == IdentityTypePermanentAKAPrime
 }
 
+// IsPseudonym は仮名（'2' or '7'）かどうかを判定する
+func (p *ParsedIdentity) IsPseudonym() bool {
+	return p.Type == IdentityTypePseudonymAKA || p.Type == IdentityTypePseudonymAKAPrime
+}
+
+// IsReauth は再認証ID（'4' or '8'）かどうかを判定する
+func (p *ParsedIdentity) IsReauth() bool {
+	return p.Type == IdentityTypeReauthAKA || p.Type == IdentityTypeReauthAKAPrime
+}
+
 // IsAKAPrime はEAP-AKA'方式（'6','7','8'）かどうかを判定する
 func (p *ParsedIdentity) IsAKAPrime() bool {
 	return p.Type == IdentityTypePermanentAKAPrime ||
 		p.Type == IdentityTypePseudonymAKAPrime ||
 		p.Type == IdentityTypeReauthAKAPrime
 }
+
+// IsConcealed はSUCI形式の秘匿化ID（復号前）かどうかを判定する
+func (p *ParsedIdentity) IsConcealed() bool {
+	return p.Type == IdentityTypeConcealed
+}
+
+// SetDeconcealed は秘匿化IDの復号結果を設定し、eapTypeの永続IDとして扱えるようにする
+// Rawは鍵導出に使用するため、ピアが送信した秘匿化IDのまま保持する
+func (p *ParsedIdentity) SetDeconcealed(imsi string, eapType uint8) {
+	p.IMSI = imsi
+	p.EAPType = eapType
+	if eapType == eapaka.TypeAKA {
+		p.Type = IdentityTypePermanentAKA
+	} else {
+		p.Type = IdentityTypePermanentAKAPrime
+	}
+}
+
+// IsSIM はEAP-SIM方式（'1','3','5'）かどうかを判定する
+// EAP-SIMのIdentityはIsPermanent/IsPseudonym/IsReauthの対象外とする
+func (p *ParsedIdentity) IsSIM() bool {
+	return p.Type == IdentityTypePermanentSIM ||
+		p.Type == IdentityTypePseudonymSIM ||
+		p.Type == IdentityTypeReauthSIM
+}
@@ -101,23 +101,70 @@ func TestParseIdentity_ReauthAKAPrime(t *testing.T) {
 }
 
 func TestParseIdentity_SIMPermanent(t *testing.T) {
-	_, err := ParseIdentity("1001010123456789@realm")
-	if !errors.Is(err, ErrUnsupportedIdentity) {
-		t.Errorf("got %v, want ErrUnsupportedIdentity", err)
+	id, err := ParseIdentity("1001010123456789@realm")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if id.Type != IdentityTypePermanentSIM {
+		t.Errorf("Type: got %d, want %d", id.Type, IdentityTypePermanentSIM)
+	}
+	if id.IMSI != "001010123456789" {
+		t.Errorf("IMSI: got %q, want %q", id.IMSI, "001010123456789")
+	}
+	if id.EAPType != EAPTypeSIM {
+		t.Errorf("EAPType: got %d, want %d", id.EAPType, EAPTypeSIM)
 	}
 }
 
 func TestParseIdentity_SIMPseudonym(t *testing.T) {
-	_, err := ParseIdentity("3pseudonym@realm")
-	if !errors.Is(err, ErrUnsupportedIdentity) {
-		t.Errorf("got %v, want ErrUnsupportedIdentity", err)
+	id, err := ParseIdentity("3pseudonym@realm")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if id.Type != IdentityTypePseudonymSIM {
+		t.Errorf("Type: got %d, want %d", id.Type, IdentityTypePseudonymSIM)
+	}
+	if id.IMSI != "" {
+		t.Errorf("IMSI: got %q, want empty", id.IMSI)
 	}
 }
 
 func TestParseIdentity_SIMReauth(t *testing.T) {
-	_, err := ParseIdentity("5reauth@realm")
-	if !errors.Is(err, ErrUnsupportedIdentity) {
-		t.Errorf("got %v, want ErrUnsupportedIdentity", err)
+	id, err := ParseIdentity("5reauth@realm")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if id.Type != IdentityTypeReauthSIM {
+		t.Errorf("Type: got %d, want %d", id.Type, IdentityTypeReauthSIM)
+	}
+}
+
+func TestIsSIM(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		want     bool
+	}{
+		{"SIM永続ID", "1001010123456789@realm", true},
+		{"SIM仮名", "3pseudonym@realm", true},
+		{"SIM再認証", "5reauth@realm", true},
+		{"AKA永続ID", "0001010123456789@realm", false},
+		{"AKA'仮名", "7pseudonym@realm", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ParseIdentity(tt.identity)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got := id.IsSIM(); got != tt.want {
+				t.Errorf("IsSIM() = %v, want %v", got, tt.want)
+			}
+			// EAP-SIMはAKA系の判定対象外
+			if tt.want && (id.IsPermanent() || id.IsPseudonym() || id.IsReauth() || id.RequiresFullAuth()) {
+				t.Error("EAP-SIMのIdentityがAKA系の判定に該当")
+			}
+		})
 	}
 }
 
@@ -147,6 +194,87 @@ func TestParseIdentity_UnknownPrefix(t *testing.T) {
 	if !errors.Is(err, ErrInvalidIdentity) {
 		t.Errorf("got %v, want ErrInvalidIdentity", err)
 	}
+	if !errors.Is(err, ErrUnrecognizedIdentity) {
+		t.Errorf("got %v, want ErrUnrecognizedIdentity", err)
+	}
+}
+
+func TestIsUnrecognizedNAI(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		want     bool
+	}{
+		{"3GPPレルムの任意ユーザー", "user@wlan.mnc001.mcc001.3gppnetwork.org", true},
+		{"単一ラベルのレルム", "Xtest@realm", true},
+		{"匿名", "anonymous@realm", true},
+		{"永続ID", "0001010123456789@realm", false},
+		{"Realmなし", "user", false},
+		{"ユーザー部なし", "@realm", false},
+		{"ユーザー部に空白", "user name@realm", false},
+		{"空ラベル", "user@wlan..org", false},
+		{"ハイフン始まりのラベル", "user@-wlan.org", false},
+		{"不正文字を含むレルム", "user@wlan_1.org", false},
+		{"@が複数", "user@realm@realm", false},
+		{"不正なSUCI", "type0.rid0.schid1@realm", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUnrecognizedNAI(tt.identity); got != tt.want {
+				t.Errorf("IsUnrecognizedNAI(%q) = %v, want %v", tt.identity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIdentity_Concealed(t *testing.T) {
+	raw := "type0.rid0.schid1.hnkey3.ecckey0a0b.cip0102.mac0011223344556677@nai.5gc.mnc001.mcc001.3gppnetwork.org"
+	id, err := ParseIdentity(raw)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if !id.IsConcealed() || id.IsPermanent() {
+		t.Errorf("Type: got %d, want %d", id.Type, IdentityTypeConcealed)
+	}
+	if id.IMSI != "" {
+		t.Errorf("IMSI: got %q, want empty", id.IMSI)
+	}
+	if id.EAPType != eapaka.TypeAKAPrime {
+		t.Errorf("EAPType: got %d, want %d", id.EAPType, eapaka.TypeAKAPrime)
+	}
+	if id.SUCI == nil || id.SUCI.KeyID != 3 {
+		t.Fatalf("SUCI: got %+v", id.SUCI)
+	}
+
+	id.SetDeconcealed("001010123456789", eapaka.TypeAKA)
+	if id.Type != IdentityTypePermanentAKA || id.IMSI != "001010123456789" || id.Raw != raw {
+		t.Errorf("SetDeconcealed: got Type=%d IMSI=%q Raw=%q", id.Type, id.IMSI, id.Raw)
+	}
+	id.SetDeconcealed("001010123456789", eapaka.TypeAKAPrime)
+	if !id.IsAKAPrime() || !id.IsPermanent() {
+		t.Errorf("SetDeconcealed(AKA'): got Type=%d", id.Type)
+	}
+}
+
+func TestParseIdentity_ConcealedInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{"不正なレルム", "type0.rid0.schid1.hnkey3.ecckey0a.cip01.mac0011223344556677@realm", ErrInvalidIdentity},
+		{"MAC欠落", "type0.rid0.schid1.hnkey3.ecckey0a.cip01@nai.5gc.mnc001.mcc001.3gppnetwork.org", ErrInvalidIdentity},
+		{"非対応の保護方式", "type0.rid0.schid9.hnkey3.ecckey0a.cip01.mac0011223344556677@nai.5gc.mnc001.mcc001.3gppnetwork.org", ErrUnsupportedIdentity},
+		{"非対応のSUPIタイプ", "type1.rid0.schid0.useridabc@nai.5gc.mnc001.mcc001.3gppnetwork.org", ErrUnsupportedIdentity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseIdentity(tt.raw); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
 }
 
 func TestRequiresFullAuth_Permanent(t *testing.T) {
@@ -263,3 +391,49 @@ func TestIsAKAPrime(t *testing.T) {
 		})
 	}
 }
+
+func TestParseIdentity_UserPart(t *testing.T) {
+	id, err := ParseIdentity("7abcdef@realm")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if id.UserPart != "7abcdef" {
+		t.Errorf("UserPart: got %q, want %q", id.UserPart, "7abcdef")
+	}
+	if !id.IsPseudonym() {
+		t.Error("IsPseudonym: got false, want true")
+	}
+}
+
+func TestParsedIdentity_IsPseudonym_NonPseudonym(t *testing.T) {
+	for _, raw := range []string{"0001010123456789@realm", "4reauth@realm", "6001010123456789@realm", "8reauth@realm"} {
+		id, err := ParseIdentity(raw)
+		if err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+		if id.IsPseudonym() {
+			t.Errorf("%s: IsPseudonym got true, want false", raw)
+		}
+	}
+}
+
+func TestIsAnonymousIdentity(t *testing.T) {
+	tests := []struct {
+		identity string
+		want     bool
+	}{
+		{"", true},
+		{"@realm", true},
+		{"anonymous@realm", true},
+		{"Anonymous@realm", true},
+		{"anonymous", true},
+		{"0001010123456789@realm", false},
+		{"2pseudonym@realm", false},
+		{"Xinvalid@realm", false},
+	}
+	for _, tt := range tests {
+		if got := IsAnonymousIdentity(tt.identity); got != tt.want {
+			t.Errorf("IsAnonymousIdentity(%q): got %v, want %v", tt.identity, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package eap
+
+// IdentityReqType はAKA-Identity Requestで要求するIdentity種別を表す（RFC 4187 Section 4.1.6）
+// 送信済み要求の記録にはビット集合として使用する
+type IdentityReqType uint8
+
+// AKA-Identity要求種別（要求の強さの昇順）
+const (
+	IdentityReqAny       IdentityReqType = 1 << iota // AT_ANY_ID_REQ（任意のIdentity）
+	IdentityReqFullAuth                              // AT_FULLAUTH_ID_REQ（仮名または永続ID）
+	IdentityReqPermanent                             // AT_PERMANENT_ID_REQ（永続IDのみ）
+)
+
+// String はログ出力用の属性名を返す
+func (t IdentityReqType) String() string {
+	switch t {
+	case IdentityReqAny:
+		return "AT_ANY_ID_REQ"
+	case IdentityReqFullAuth:
+		return "AT_FULLAUTH_ID_REQ"
+	case IdentityReqPermanent:
+		return "AT_PERMANENT_ID_REQ"
+	default:
+		return "NONE"
+	}
+}
+
+// ParseIdentityReqType は設定値（"any"/"fullauth"/"permanent"）を要求種別に変換する
+func ParseIdentityReqType(s string) (IdentityReqType, bool) {
+	switch s {
+	case "any":
+		return IdentityReqAny, true
+	case "fullauth":
+		return IdentityReqFullAuth, true
+	case "permanent":
+		return IdentityReqPermanent, true
+	default:
+		return 0, false
+	}
+}
+
+// Last は送信済み要求の集合から最後に送信した（最も強い）要求を返す
+// 未送信の場合は0を返す
+func (t IdentityReqType) Last() IdentityReqType {
+	for _, r := range []IdentityReqType{IdentityReqPermanent, IdentityReqFullAuth, IdentityReqAny} {
+		if t&r != 0 {
+			return r
+		}
+	}
+	return 0
+}
+
+// NextIdentityRequest は送信済み要求sentを踏まえ、次に送信する要求種別を決定する
+// wantより弱い要求や送信済み以下の要求は送れないため、必要に応じて強い要求へ繰り上げる
+// 要求はANY → FULLAUTH → PERMANENTの順にそれぞれ最大1回まで（RFC 4187 Section 4.1.5）
+// これ以上要求できない場合（AT_PERMANENT_ID_REQ送信済み）はfalseを返す
+func NextIdentityRequest(sent, want IdentityReqType) (IdentityReqType, bool) {
+	next := want
+	if last := sent.Last(); next <= last {
+		next = last << 1
+	}
+	if next > IdentityReqPermanent {
+		return 0, false
+	}
+	return next, true
+}
+
+// Accepts は最後に送信した要求に対してIdentity応答が妥当かどうかを判定する
+// AT_PERMANENT_ID_REQには永続ID、AT_FULLAUTH_ID_REQには再認証ID以外を要求する
+func (t IdentityReqType) Accepts(identity *ParsedIdentity) bool {
+	switch t.Last() {
+	case IdentityReqPermanent:
+		return identity.IsPermanent()
+	case IdentityReqFullAuth:
+		return !identity.IsReauth()
+	default:
+		return true
+	}
+}
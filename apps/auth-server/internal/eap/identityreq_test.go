@@ -0,0 +1,88 @@
+package eap
+
+import "testing"
+
+func TestNextIdentityRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		sent   IdentityReqType
+		want   IdentityReqType
+		expect IdentityReqType
+		ok     bool
+	}{
+		{"初回ANY", 0, IdentityReqAny, IdentityReqAny, true},
+		{"初回FULLAUTH", 0, IdentityReqFullAuth, IdentityReqFullAuth, true},
+		{"初回PERMANENT", 0, IdentityReqPermanent, IdentityReqPermanent, true},
+		{"ANY後に未知の再認証ID", IdentityReqAny, IdentityReqFullAuth, IdentityReqFullAuth, true},
+		{"ANY後に未知の仮名", IdentityReqAny, IdentityReqPermanent, IdentityReqPermanent, true},
+		{"ANY後に再度匿名", IdentityReqAny, IdentityReqAny, IdentityReqFullAuth, true},
+		{"FULLAUTH後に未知の仮名", IdentityReqAny | IdentityReqFullAuth, IdentityReqPermanent, IdentityReqPermanent, true},
+		{"FULLAUTH後にFULLAUTH要求は繰り上げ", IdentityReqFullAuth, IdentityReqFullAuth, IdentityReqPermanent, true},
+		{"PERMANENT送信済み", IdentityReqPermanent, IdentityReqPermanent, 0, false},
+		{"全種別送信済み", IdentityReqAny | IdentityReqFullAuth | IdentityReqPermanent, IdentityReqAny, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NextIdentityRequest(tt.sent, tt.want)
+			if got != tt.expect || ok != tt.ok {
+				t.Errorf("got (%v, %v), want (%v, %v)", got, ok, tt.expect, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIdentityReqType_Accepts(t *testing.T) {
+	permanent := &ParsedIdentity{Type: IdentityTypePermanentAKA}
+	pseudonym := &ParsedIdentity{Type: IdentityTypePseudonymAKA}
+	reauth := &ParsedIdentity{Type: IdentityTypeReauthAKAPrime}
+
+	tests := []struct {
+		name     string
+		sent     IdentityReqType
+		identity *ParsedIdentity
+		want     bool
+	}{
+		{"ANYに再認証ID", IdentityReqAny, reauth, true},
+		{"ANYに仮名", IdentityReqAny, pseudonym, true},
+		{"FULLAUTHに仮名", IdentityReqAny | IdentityReqFullAuth, pseudonym, true},
+		{"FULLAUTHに再認証ID", IdentityReqFullAuth, reauth, false},
+		{"PERMANENTに永続ID", IdentityReqPermanent, permanent, true},
+		{"PERMANENTに仮名", IdentityReqFullAuth | IdentityReqPermanent, pseudonym, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sent.Accepts(tt.identity); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityReqType_String(t *testing.T) {
+	if got := IdentityReqFullAuth.String(); got != "AT_FULLAUTH_ID_REQ" {
+		t.Errorf("got %q, want AT_FULLAUTH_ID_REQ", got)
+	}
+	if got := IdentityReqType(0).String(); got != "NONE" {
+		t.Errorf("got %q, want NONE", got)
+	}
+}
+
+func TestParseIdentityReqType(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   IdentityReqType
+		wantOK bool
+	}{
+		{"any", IdentityReqAny, true},
+		{"fullauth", IdentityReqFullAuth, true},
+		{"permanent", IdentityReqPermanent, true},
+		{"ANY", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseIdentityReqType(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseIdentityReqType(%q) = (%v, %v), want (%v, %v)", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package eap
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"hash"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// macLength はAT_MACの値長
+const macLength = 16
+
+// CalculateMACWithExtra はパケット全体に追加データを連結してAT_MACを計算・設定する
+// 再認証応答（NONCE_S連結、RFC 4187 Section 10.15）等で使用する
+func CalculateMACWithExtra(pkt *eapaka.Packet, kAut, extra []byte) error {
+	mac, err := computeMAC(pkt, kAut, extra)
+	if err != nil {
+		return err
+	}
+	atMac, _ := GetAttribute[*eapaka.AtMac](pkt)
+	atMac.MAC = mac
+	return nil
+}
+
+// VerifyMACWithExtra はパケット全体に追加データを連結してAT_MACを検証する
+func VerifyMACWithExtra(pkt *eapaka.Packet, kAut, extra []byte) error {
+	atMac, found := GetAttribute[*eapaka.AtMac](pkt)
+	if !found || len(atMac.MAC) != macLength {
+		return ErrMACInvalid
+	}
+	received := append([]byte(nil), atMac.MAC...)
+	defer func() { atMac.MAC = received }()
+
+	expected, err := computeMAC(pkt, kAut, extra)
+	if err != nil {
+		return ErrMACInvalid
+	}
+	if subtle.ConstantTimeCompare(received, expected) != 1 {
+		return ErrMACInvalid
+	}
+	return nil
+}
+
+// computeMAC はAT_MACをゼロクリアした状態でMAC値を計算する
+// EAP-SIM/EAP-AKAはHMAC-SHA1-128、EAP-AKA'はHMAC-SHA-256-128
+func computeMAC(pkt *eapaka.Packet, kAut, extra []byte) ([]byte, error) {
+	atMac, found := GetAttribute[*eapaka.AtMac](pkt)
+	if !found {
+		return nil, ErrMACInvalid
+	}
+	atMac.MAC = make([]byte, macLength)
+
+	data, err := MarshalPacket(pkt)
+	if err != nil {
+		return nil, err
+	}
+
+	var h hash.Hash
+	if pkt.Type == eapaka.TypeAKAPrime {
+		h = hmac.New(sha256.New, kAut)
+	} else {
+		h = hmac.New(sha1.New, kAut)
+	}
+	h.Write(data)
+	h.Write(extra)
+	return h.Sum(nil)[:macLength], nil
+}
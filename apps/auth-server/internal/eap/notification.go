@@ -0,0 +1,107 @@
+package eap
+
+import (
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// AT_NOTIFICATIONの通知コード（RFC 4187 Section 10.19、S/Pビットを含む16ビット値）
+const (
+	NotificationGeneralFailureAfterAuth uint16 = 0     // General failure after authentication
+	NotificationTemporarilyDenied       uint16 = 1026  // User has been temporarily denied access
+	NotificationNotSubscribed           uint16 = 1031  // User has not subscribed to the requested service
+	NotificationGeneralFailure          uint16 = 16384 // General failure（認証前）
+	NotificationSuccess                 uint16 = 32768 // Success
+)
+
+// notificationSBit/notificationPBit はAT_NOTIFICATIONのS/Pビット
+const (
+	notificationSBit uint16 = 0x8000 // 0: 失敗, 1: 成功
+	notificationPBit uint16 = 0x4000 // 0: 認証後（保護あり）, 1: 認証前
+)
+
+// IsNotificationSuccess は通知コードが成功（Sビット=1）かどうかを判定する
+func IsNotificationSuccess(code uint16) bool {
+	return code&notificationSBit != 0
+}
+
+// IsNotificationProtected は通知コードが認証後（Pビット=0）でAT_MACによる保護が必要かどうかを判定する
+func IsNotificationProtected(code uint16) bool {
+	return code&notificationPBit == 0
+}
+
+// NotificationParams はEAP-Request/AKA-Notificationの構築パラメータを保持する
+type NotificationParams struct {
+	Identifier uint8
+	EAPType    uint8  // eapaka.TypeAKA, eapaka.TypeAKAPrime or EAPTypeSIM
+	Code       uint16 // AT_NOTIFICATIONの通知コード
+	KAut       []byte // Pビット=0の場合のAT_MAC計算鍵
+	KEncr      []byte // 高速再認証時のK_encr
+	Counter    uint16 // 高速再認証時のAT_COUNTER（0の場合は含めない）
+}
+
+// BuildNotificationRequest はEAP-Request/AKA-Notificationパケットを構築する（RFC 4187 Section 9.10）
+// 認証後の通知（Pビット=0）はAT_MACを付与し、高速再認証時はAT_COUNTERを暗号化して含める
+func BuildNotificationRequest(p *NotificationParams) ([]byte, error) {
+	attrs := []eapaka.Attribute{
+		&eapaka.AtNotification{
+			S:    IsNotificationSuccess(p.Code),
+			P:    !IsNotificationProtected(p.Code),
+			Code: p.Code &^ (notificationSBit | notificationPBit),
+		},
+	}
+
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeRequest,
+		Identifier: p.Identifier,
+		Type:       p.EAPType,
+		Subtype:    eapaka.SubtypeNotification,
+	}
+	if !IsNotificationProtected(p.Code) {
+		pkt.Attributes = attrs
+		return MarshalPacket(pkt)
+	}
+
+	if p.Counter > 0 {
+		atIv, atEncr, err := EncryptAttributes(p.KEncr, []eapaka.Attribute{&eapaka.AtCounter{Counter: p.Counter}})
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, atIv, atEncr)
+	}
+	pkt.Attributes = append(attrs, &eapaka.AtMac{MAC: make([]byte, macLength)})
+
+	if err := CalculateMACWithExtra(pkt, p.KAut, nil); err != nil {
+		return nil, err
+	}
+	return MarshalPacket(pkt)
+}
+
+// VerifyNotificationResponse は認証後通知に対するEAP-Response/AKA-Notificationを検証する（RFC 4187 Section 9.11）
+// AT_MACを検証し、counterが0以外の場合は暗号化されたAT_COUNTERも照合する
+func VerifyNotificationResponse(pkt *eapaka.Packet, kAut, kEncr []byte, counter uint16) error {
+	if err := VerifyMACWithExtra(pkt, kAut, nil); err != nil {
+		return err
+	}
+	if counter == 0 {
+		return nil
+	}
+
+	atIv, _ := GetAttribute[*eapaka.AtIv](pkt)
+	atEncr, _ := GetAttribute[*eapaka.AtEncrData](pkt)
+	attrs, err := DecryptAttributes(kEncr, atIv, atEncr)
+	if err != nil {
+		return err
+	}
+	for _, attr := range attrs {
+		if a, ok := attr.(*eapaka.AtCounter); ok && a.Counter == counter {
+			return nil
+		}
+	}
+	return ErrCounterMismatch
+}
+
+// HasResultInd はパケットにAT_RESULT_INDが含まれるかどうかを判定する
+func HasResultInd(pkt *eapaka.Packet) bool {
+	_, found := GetAttribute[*eapaka.AtResultInd](pkt)
+	return found
+}
@@ -0,0 +1,231 @@
+package eap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// buildTestNotificationResponse はピア側のEAP-Response/AKA-Notificationを構築する
+func buildTestNotificationResponse(t *testing.T, kEncr, kAut []byte, counter uint16) *eapaka.Packet {
+	t.Helper()
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 3,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeNotification,
+	}
+	if counter > 0 {
+		atIv, atEncr, err := EncryptAttributes(kEncr, []eapaka.Attribute{&eapaka.AtCounter{Counter: counter}})
+		if err != nil {
+			t.Fatalf("暗号化失敗: %v", err)
+		}
+		pkt.Attributes = append(pkt.Attributes, atIv, atEncr)
+	}
+	pkt.Attributes = append(pkt.Attributes, &eapaka.AtMac{MAC: make([]byte, 16)})
+	if err := pkt.CalculateAndSetMac(kAut); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+	data, _ := pkt.Marshal()
+	parsed, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	return parsed
+}
+
+func TestNotificationCodeBits(t *testing.T) {
+	tests := []struct {
+		code      uint16
+		success   bool
+		protected bool
+	}{
+		{NotificationGeneralFailureAfterAuth, false, true},
+		{NotificationTemporarilyDenied, false, true},
+		{NotificationNotSubscribed, false, true},
+		{NotificationGeneralFailure, false, false},
+		{NotificationSuccess, true, true},
+	}
+	for _, tt := range tests {
+		if got := IsNotificationSuccess(tt.code); got != tt.success {
+			t.Errorf("IsNotificationSuccess(%d): got %v, want %v", tt.code, got, tt.success)
+		}
+		if got := IsNotificationProtected(tt.code); got != tt.protected {
+			t.Errorf("IsNotificationProtected(%d): got %v, want %v", tt.code, got, tt.protected)
+		}
+	}
+}
+
+func TestBuildNotificationRequest_Protected(t *testing.T) {
+	kAut := bytes.Repeat([]byte{0x22}, 16)
+	data, err := BuildNotificationRequest(&NotificationParams{
+		Identifier: 5,
+		EAPType:    eapaka.TypeAKA,
+		Code:       NotificationTemporarilyDenied,
+		KAut:       kAut,
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	pkt, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	if pkt.Subtype != eapaka.SubtypeNotification || pkt.Identifier != 5 {
+		t.Errorf("Subtype/Identifier: got %d/%d", pkt.Subtype, pkt.Identifier)
+	}
+	atNotif, found := GetAttribute[*eapaka.AtNotification](pkt)
+	if !found {
+		t.Fatal("AT_NOTIFICATIONが見つからない")
+	}
+	if atNotif.S || atNotif.P || atNotif.Code != NotificationTemporarilyDenied {
+		t.Errorf("AT_NOTIFICATION: got S=%v P=%v Code=%d", atNotif.S, atNotif.P, atNotif.Code)
+	}
+	if ok, _ := pkt.VerifyMac(kAut); !ok {
+		t.Error("AT_MAC検証失敗")
+	}
+	if _, found := GetAttribute[*eapaka.AtEncrData](pkt); found {
+		t.Error("フル認証後の通知にAT_ENCR_DATAを含めるべきでない")
+	}
+}
+
+func TestBuildNotificationRequest_Success(t *testing.T) {
+	kAut := bytes.Repeat([]byte{0x22}, 16)
+	data, err := BuildNotificationRequest(&NotificationParams{
+		Identifier: 1,
+		EAPType:    eapaka.TypeAKAPrime,
+		Code:       NotificationSuccess,
+		KAut:       kAut,
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	pkt, _ := eapaka.Parse(data)
+	atNotif, _ := GetAttribute[*eapaka.AtNotification](pkt)
+	if !atNotif.S || atNotif.P || atNotif.Code != 0 {
+		t.Errorf("AT_NOTIFICATION: got S=%v P=%v Code=%d", atNotif.S, atNotif.P, atNotif.Code)
+	}
+	if ok, _ := pkt.VerifyMac(kAut); !ok {
+		t.Error("AT_MAC検証失敗")
+	}
+}
+
+func TestBuildNotificationRequest_Reauth(t *testing.T) {
+	kEncr := testKEncr()
+	kAut := bytes.Repeat([]byte{0x22}, 16)
+	data, err := BuildNotificationRequest(&NotificationParams{
+		Identifier: 1,
+		EAPType:    eapaka.TypeAKA,
+		Code:       NotificationSuccess,
+		KAut:       kAut,
+		KEncr:      kEncr,
+		Counter:    3,
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	pkt, _ := eapaka.Parse(data)
+	atIv, _ := GetAttribute[*eapaka.AtIv](pkt)
+	atEncr, _ := GetAttribute[*eapaka.AtEncrData](pkt)
+	attrs, err := DecryptAttributes(kEncr, atIv, atEncr)
+	if err != nil {
+		t.Fatalf("復号失敗: %v", err)
+	}
+	if c, ok := attrs[0].(*eapaka.AtCounter); !ok || c.Counter != 3 {
+		t.Errorf("AT_COUNTER: got %v", attrs)
+	}
+}
+
+func TestBuildNotificationRequest_Unprotected(t *testing.T) {
+	data, err := BuildNotificationRequest(&NotificationParams{
+		Identifier: 1,
+		EAPType:    eapaka.TypeAKA,
+		Code:       NotificationGeneralFailure,
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	pkt, _ := eapaka.Parse(data)
+	if _, found := GetAttribute[*eapaka.AtMac](pkt); found {
+		t.Error("認証前の通知にAT_MACを含めるべきでない")
+	}
+	atNotif, _ := GetAttribute[*eapaka.AtNotification](pkt)
+	if atNotif.S || !atNotif.P {
+		t.Errorf("AT_NOTIFICATION: got S=%v P=%v", atNotif.S, atNotif.P)
+	}
+}
+
+func TestBuildNotificationRequest_SIM(t *testing.T) {
+	kAut := bytes.Repeat([]byte{0x33}, 16)
+	data, err := BuildNotificationRequest(&NotificationParams{
+		Identifier: 4,
+		EAPType:    EAPTypeSIM,
+		Code:       NotificationSuccess,
+		KAut:       kAut,
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if GetEAPType(data) != EAPTypeSIM {
+		t.Fatalf("Type: got %d, want %d", GetEAPType(data), EAPTypeSIM)
+	}
+
+	pkt, err := ParseEAPPacket(data)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	if pkt.Subtype != eapaka.SubtypeNotification {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeNotification)
+	}
+	// EAP-SIMもHMAC-SHA1-128で保護される
+	if err := VerifyMACWithExtra(pkt, kAut, nil); err != nil {
+		t.Errorf("AT_MAC検証失敗: %v", err)
+	}
+}
+
+func TestVerifyNotificationResponse(t *testing.T) {
+	kEncr := testKEncr()
+	kAut := bytes.Repeat([]byte{0x22}, 16)
+
+	tests := []struct {
+		name    string
+		kAut    []byte
+		sent    uint16
+		expect  uint16
+		wantErr error
+	}{
+		{name: "フル認証後", kAut: kAut},
+		{name: "高速再認証後", kAut: kAut, sent: 2, expect: 2},
+		{name: "MAC不正", kAut: bytes.Repeat([]byte{0x33}, 16), wantErr: ErrMACInvalid},
+		{name: "AT_COUNTER不一致", kAut: kAut, sent: 1, expect: 2, wantErr: ErrCounterMismatch},
+		{name: "AT_COUNTERなし", kAut: kAut, expect: 2, wantErr: ErrEncrDataInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkt := buildTestNotificationResponse(t, kEncr, tt.kAut, tt.sent)
+			err := VerifyNotificationResponse(pkt, kAut, kEncr, tt.expect)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("予期しないエラー: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHasResultInd(t *testing.T) {
+	pkt := &eapaka.Packet{Attributes: []eapaka.Attribute{&eapaka.AtResultInd{}}}
+	if !HasResultInd(pkt) {
+		t.Error("AT_RESULT_INDを検出できない")
+	}
+	if HasResultInd(&eapaka.Packet{}) {
+		t.Error("AT_RESULT_INDなしでtrue")
+	}
+}
@@ -0,0 +1,49 @@
+package eap
+
+import eapaka "github.com/oyaguma3/go-eapaka"
+
+// ChallengeOptions はAKA/AKA'-Challengeに付加する任意属性を保持する
+type ChallengeOptions struct {
+	KEncr         []byte // AT_ENCR_DATAの暗号鍵（K_encr）
+	NextPseudonym string // AT_NEXT_PSEUDONYM（空の場合は送信しない）
+	NextReauthID  string // AT_NEXT_REAUTH_ID（空の場合は送信しない）
+	ResultInd     bool   // AT_RESULT_IND（保護された結果通知の利用を提示）
+	Bidding       bool   // AT_BIDDING（EAP-AKA'対応を通知、EAP-AKA-Challengeのみ）
+	Checkcode     []byte // AT_CHECKCODE（AKA-Identityメッセージのハッシュ、nilの場合は送信しない）
+}
+
+// Attributes はAT_MACの前に挿入する追加属性を返す
+// 暗号化対象の属性がある場合はAT_IV/AT_ENCR_DATAにまとめる
+func (o *ChallengeOptions) Attributes() ([]eapaka.Attribute, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	var attrs []eapaka.Attribute
+	if o.ResultInd {
+		attrs = append(attrs, &eapaka.AtResultInd{})
+	}
+	if o.Bidding {
+		attrs = append(attrs, &eapaka.AtBidding{Flags: eapaka.AtBiddingFlagAKAPrime})
+	}
+	if o.Checkcode != nil {
+		attrs = append(attrs, &eapaka.AtCheckcode{Checkcode: o.Checkcode})
+	}
+
+	var encrAttrs []eapaka.Attribute
+	if o.NextPseudonym != "" {
+		encrAttrs = append(encrAttrs, &eapaka.AtNextPseudonym{Pseudonym: o.NextPseudonym})
+	}
+	if o.NextReauthID != "" {
+		encrAttrs = append(encrAttrs, &eapaka.AtNextReauthId{Identity: o.NextReauthID})
+	}
+	if len(encrAttrs) == 0 {
+		return attrs, nil
+	}
+
+	atIv, atEncr, err := EncryptAttributes(o.KEncr, encrAttrs)
+	if err != nil {
+		return nil, err
+	}
+	return append(attrs, atIv, atEncr), nil
+}
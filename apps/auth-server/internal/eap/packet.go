@@ -10,6 +10,7 @@ import (
 // EAP Type定数（RFC 3748）
 const (
 	EAPTypeIdentity uint8 = 1  // RFC 3748 Identity
+	EAPTypeSIM      uint8 = 18 // RFC 4186 EAP-SIM
 	EAPTypeAKA      uint8 = 23 // RFC 4187 EAP-AKA
 	EAPTypeAKAPrime uint8 = 50 // RFC 5448 EAP-AKA'
 )
@@ -30,12 +31,54 @@ func GetEAPIdentifier(data []byte) uint8 {
 	return data[1]
 }
 
+// GetIdentityPayload はRFC 3748 EAP-Response/Identity（Type=1）のType-Data（Identity）を返す
+// Identityパケットでない場合、Lengthフィールドが不正な場合はfalseを返す
+func GetIdentityPayload(data []byte) (string, bool) {
+	if len(data) < 5 || data[0] != eapaka.CodeResponse || data[4] != EAPTypeIdentity {
+		return "", false
+	}
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	if length < 5 || length > len(data) {
+		return "", false
+	}
+	return string(data[5:length]), true
+}
+
 // ParseEAPPacket はバイト列をEAPパケットとしてパースする
+// EAP-SIMはEAP-AKAと同一のパケット形式（RFC 4186 Section 8.1）のため、Typeを置換してパースする
 func ParseEAPPacket(data []byte) (*eapaka.Packet, error) {
 	if len(data) == 0 {
 		return nil, fmt.Errorf("eap: empty packet data")
 	}
-	return eapaka.Parse(data)
+	if GetEAPType(data) != EAPTypeSIM || (data[0] != eapaka.CodeRequest && data[0] != eapaka.CodeResponse) {
+		return eapaka.Parse(data)
+	}
+
+	buf := append([]byte(nil), data...)
+	buf[4] = eapaka.TypeAKA
+	pkt, err := eapaka.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	pkt.Type = EAPTypeSIM
+	return pkt, nil
+}
+
+// MarshalPacket はEAPパケットをバイト列に変換する
+// go-eapakaが直接扱えないEAP-SIMはEAP-AKAとしてシリアライズしてTypeを置換する
+func MarshalPacket(pkt *eapaka.Packet) ([]byte, error) {
+	if pkt.Type != EAPTypeSIM || (pkt.Code != eapaka.CodeRequest && pkt.Code != eapaka.CodeResponse) {
+		return pkt.Marshal()
+	}
+
+	tmp := *pkt
+	tmp.Type = eapaka.TypeAKA
+	data, err := tmp.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	data[4] = EAPTypeSIM
+	return data, nil
 }
 
 // GetAttribute はパケットから指定型の属性を検索して返す
@@ -70,17 +113,27 @@ func BuildEAPFailure(identifier uint8) ([]byte, error) {
 	return buf, nil
 }
 
-// BuildAKAIdentityRequest はフル認証誘導用のAKA-Identity Requestパケットを構築する
-// AT_PERMANENT_ID_REQを含む
-func BuildAKAIdentityRequest(identifier uint8, eapType uint8) ([]byte, error) {
+// BuildAKAIdentityRequest はAKA-Identity Requestパケットを構築する
+// reqTypeに応じてAT_ANY_ID_REQ/AT_FULLAUTH_ID_REQ/AT_PERMANENT_ID_REQのいずれかを含む
+func BuildAKAIdentityRequest(identifier uint8, eapType uint8, reqType IdentityReqType) ([]byte, error) {
+	var attr eapaka.Attribute
+	switch reqType {
+	case IdentityReqAny:
+		attr = &eapaka.AtAnyIdReq{}
+	case IdentityReqFullAuth:
+		attr = &eapaka.AtFullauthIdReq{}
+	case IdentityReqPermanent:
+		attr = &eapaka.AtPermanentIdReq{}
+	default:
+		return nil, fmt.Errorf("eap: invalid identity request type: %d", reqType)
+	}
+
 	pkt := &eapaka.Packet{
 		Code:       eapaka.CodeRequest,
 		Identifier: identifier,
 		Type:       eapType,
 		Subtype:    eapaka.SubtypeIdentity,
-		Attributes: []eapaka.Attribute{
-			&eapaka.AtPermanentIdReq{},
-		},
+		Attributes: []eapaka.Attribute{attr},
 	}
 	return pkt.Marshal()
 }
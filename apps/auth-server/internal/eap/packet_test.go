@@ -1,6 +1,7 @@
 package eap
 
 import (
+	"bytes"
 	"testing"
 
 	eapaka "github.com/oyaguma3/go-eapaka"
@@ -153,7 +154,7 @@ func TestBuildEAPFailure(t *testing.T) {
 }
 
 func TestBuildAKAIdentityRequest_AKA(t *testing.T) {
-	data, err := BuildAKAIdentityRequest(10, eapaka.TypeAKA)
+	data, err := BuildAKAIdentityRequest(10, eapaka.TypeAKA, IdentityReqPermanent)
 	if err != nil {
 		t.Fatalf("予期しないエラー: %v", err)
 	}
@@ -237,8 +238,33 @@ func TestGetEAPIdentifier_ShortData(t *testing.T) {
 	}
 }
 
+func TestGetIdentityPayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		want   string
+		wantOK bool
+	}{
+		{"Identityあり", []byte{eapaka.CodeResponse, 1, 0, 11, EAPTypeIdentity, 'u', 's', 'e', 'r', '@', 'r'}, "user@r", true},
+		{"Identity空", []byte{eapaka.CodeResponse, 1, 0, 5, EAPTypeIdentity}, "", true},
+		{"Length以降のパディングは無視", []byte{eapaka.CodeResponse, 1, 0, 7, EAPTypeIdentity, 'a', 'b', 0, 0}, "ab", true},
+		{"Lengthがデータ長超過", []byte{eapaka.CodeResponse, 1, 0, 20, EAPTypeIdentity, 'a'}, "", false},
+		{"Identity以外のType", []byte{eapaka.CodeResponse, 1, 0, 8, EAPTypeAKA, 5, 0, 0}, "", false},
+		{"Request", []byte{eapaka.CodeRequest, 1, 0, 5, EAPTypeIdentity}, "", false},
+		{"短すぎる", []byte{eapaka.CodeResponse, 1, 0}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := GetIdentityPayload(tt.data)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("GetIdentityPayload() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestBuildAKAIdentityRequest_AKAPrime(t *testing.T) {
-	data, err := BuildAKAIdentityRequest(20, eapaka.TypeAKAPrime)
+	data, err := BuildAKAIdentityRequest(20, eapaka.TypeAKAPrime, IdentityReqPermanent)
 	if err != nil {
 		t.Fatalf("予期しないエラー: %v", err)
 	}
@@ -265,3 +291,103 @@ func TestBuildAKAIdentityRequest_AKAPrime(t *testing.T) {
 		t.Error("AT_PERMANENT_ID_REQが見つからない")
 	}
 }
+
+func TestBuildAKAIdentityRequest_RequestTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		reqType IdentityReqType
+		check   func(*eapaka.Packet) bool
+	}{
+		{"AT_ANY_ID_REQ", IdentityReqAny, func(p *eapaka.Packet) bool {
+			_, ok := GetAttribute[*eapaka.AtAnyIdReq](p)
+			return ok
+		}},
+		{"AT_FULLAUTH_ID_REQ", IdentityReqFullAuth, func(p *eapaka.Packet) bool {
+			_, ok := GetAttribute[*eapaka.AtFullauthIdReq](p)
+			return ok
+		}},
+		{"AT_PERMANENT_ID_REQ", IdentityReqPermanent, func(p *eapaka.Packet) bool {
+			_, ok := GetAttribute[*eapaka.AtPermanentIdReq](p)
+			return ok
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := BuildAKAIdentityRequest(1, eapaka.TypeAKA, tt.reqType)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			pkt, err := ParseEAPPacket(data)
+			if err != nil {
+				t.Fatalf("再パース失敗: %v", err)
+			}
+			if len(pkt.Attributes) != 1 || !tt.check(pkt) {
+				t.Errorf("%sのみを含むべき: %v", tt.name, pkt.Attributes)
+			}
+		})
+	}
+}
+
+func TestBuildAKAIdentityRequest_InvalidType(t *testing.T) {
+	if _, err := BuildAKAIdentityRequest(1, eapaka.TypeAKA, 0); err == nil {
+		t.Error("不正な要求種別でエラーにならない")
+	}
+}
+
+func TestMarshalParsePacket_SIM(t *testing.T) {
+	nonceMT := bytes.Repeat([]byte{0x11}, 16)
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 7,
+		Type:       EAPTypeSIM,
+		Subtype:    10, // SIM/Start
+		Attributes: []eapaka.Attribute{
+			&eapaka.AtNonceMt{NonceMt: nonceMT},
+			&eapaka.AtSelectedVersion{Version: 1},
+		},
+	}
+
+	data, err := MarshalPacket(pkt)
+	if err != nil {
+		t.Fatalf("MarshalPacket失敗: %v", err)
+	}
+	if GetEAPType(data) != EAPTypeSIM {
+		t.Fatalf("Type: got %d, want %d", GetEAPType(data), EAPTypeSIM)
+	}
+	if pkt.Type != EAPTypeSIM {
+		t.Error("MarshalPacketが元パケットのTypeを変更した")
+	}
+
+	parsed, err := ParseEAPPacket(data)
+	if err != nil {
+		t.Fatalf("ParseEAPPacket失敗: %v", err)
+	}
+	if parsed.Type != EAPTypeSIM || parsed.Subtype != 10 || parsed.Identifier != 7 {
+		t.Errorf("Type/Subtype/Identifier: got %d/%d/%d", parsed.Type, parsed.Subtype, parsed.Identifier)
+	}
+	atNonce, found := GetAttribute[*eapaka.AtNonceMt](parsed)
+	if !found || !bytes.Equal(atNonce.NonceMt, nonceMT) {
+		t.Error("AT_NONCE_MTが復元されない")
+	}
+	if data[4] != EAPTypeSIM {
+		t.Error("ParseEAPPacketが入力バイト列を変更した")
+	}
+}
+
+func TestMarshalPacket_AKA(t *testing.T) {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeRequest,
+		Identifier: 1,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeIdentity,
+		Attributes: []eapaka.Attribute{&eapaka.AtAnyIdReq{}},
+	}
+	got, err := MarshalPacket(pkt)
+	if err != nil {
+		t.Fatalf("MarshalPacket失敗: %v", err)
+	}
+	want, _ := pkt.Marshal()
+	if !bytes.Equal(got, want) {
+		t.Errorf("EAP-AKAはpkt.Marshalと同一であるべき: got %x, want %x", got, want)
+	}
+}
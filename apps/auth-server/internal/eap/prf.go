@@ -0,0 +1,95 @@
+package eap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// sha1BlockSize はSHA-1のブロック長
+const sha1BlockSize = 64
+
+// sha1InitState はSHA-1の初期ハッシュ値（FIPS 180-4）
+var sha1InitState = [5]uint32{0x67452301, 0xEFCDAB89, 0x98BADCFE, 0x10325476, 0xC3D2E1F0}
+
+// FIPS186PRF はFIPS 186-2 Change Notice 1の擬似乱数関数でoutputLenバイトを生成する
+// EAP-AKA再認証（RFC 4187 Section 7）およびEAP-SIM（RFC 4186 Section 7）の鍵導出に使用する
+// go-eapakaは本関数を公開していないため、同等の実装を持つ
+func FIPS186PRF(xkey []byte, outputLen int) []byte {
+	mod := new(big.Int).Lsh(big.NewInt(1), 160)
+	one := big.NewInt(1)
+
+	block := make([]byte, sha1BlockSize)
+	copy(block, xkey)
+
+	var output []byte
+	for len(output) < outputLen {
+		// G(t, XKEY): SHA-1圧縮関数（パディングなし）
+		w := sha1Compress(block)
+		output = append(output, w...)
+
+		// XKEY = (1 + XKEY + w) mod 2^160
+		x := new(big.Int).SetBytes(block[:20])
+		x.Add(x, new(big.Int).SetBytes(w))
+		x.Add(x, one)
+		x.Mod(x, mod)
+		clear(block[:20])
+		xb := x.Bytes()
+		copy(block[20-len(xb):20], xb)
+	}
+	return output[:outputLen]
+}
+
+// PRFPlus はRFC 5448 Section 3.4のPRF'（HMAC-SHA-256ベースのPRF+）でoutputLenバイトを生成する
+func PRFPlus(key, seed []byte, outputLen int) []byte {
+	h := hmac.New(sha256.New, key)
+	var output, prev []byte
+	for i := byte(1); len(output) < outputLen; i++ {
+		h.Reset()
+		h.Write(prev)
+		h.Write(seed)
+		h.Write([]byte{i})
+		prev = h.Sum(nil)
+		output = append(output, prev...)
+	}
+	return output[:outputLen]
+}
+
+// sha1Compress は初期状態からSHA-1圧縮関数を1ブロック分実行し、20バイトの結果を返す
+func sha1Compress(block []byte) []byte {
+	var w [80]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(block[i*4:])
+	}
+	for i := 16; i < 80; i++ {
+		w[i] = bits.RotateLeft32(w[i-3]^w[i-8]^w[i-14]^w[i-16], 1)
+	}
+
+	h := sha1InitState
+	a, b, c, d, e := h[0], h[1], h[2], h[3], h[4]
+	for i := 0; i < 80; i++ {
+		var f, k uint32
+		switch {
+		case i < 20:
+			f, k = b&c|(^b)&d, 0x5A827999
+		case i < 40:
+			f, k = b^c^d, 0x6ED9EBA1
+		case i < 60:
+			f, k = (b&c)|(b&d)|(c&d), 0x8F1BBCDC
+		default:
+			f, k = b^c^d, 0xCA62C1D6
+		}
+		t := bits.RotateLeft32(a, 5) + f + e + w[i] + k
+		a, b, c, d, e = t, a, bits.RotateLeft32(b, 30), c, d
+	}
+
+	out := make([]byte, 20)
+	binary.BigEndian.PutUint32(out[0:], h[0]+a)
+	binary.BigEndian.PutUint32(out[4:], h[1]+b)
+	binary.BigEndian.PutUint32(out[8:], h[2]+c)
+	binary.BigEndian.PutUint32(out[12:], h[3]+d)
+	binary.BigEndian.PutUint32(out[16:], h[4]+e)
+	return out
+}
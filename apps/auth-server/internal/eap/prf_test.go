@@ -0,0 +1,58 @@
+package eap
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"testing"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+func TestFIPS186PRF_MatchesAKAKeyDerivation(t *testing.T) {
+	identity := "0001010123456789@realm"
+	ck := bytes.Repeat([]byte{0x11}, 16)
+	ik := bytes.Repeat([]byte{0x22}, 16)
+
+	h := sha1.New()
+	h.Write([]byte(identity))
+	h.Write(ik)
+	h.Write(ck)
+	block := FIPS186PRF(h.Sum(nil), 160)
+
+	keys := eapaka.DeriveKeysAKA(identity, ck, ik)
+	if !bytes.Equal(block[0:16], keys.K_encr) {
+		t.Error("K_encrがgo-eapakaの導出結果と一致しない")
+	}
+	if !bytes.Equal(block[16:32], keys.K_aut) {
+		t.Error("K_autがgo-eapakaの導出結果と一致しない")
+	}
+	if !bytes.Equal(block[32:96], keys.MSK) {
+		t.Error("MSKがgo-eapakaの導出結果と一致しない")
+	}
+	if !bytes.Equal(block[96:160], keys.EMSK) {
+		t.Error("EMSKがgo-eapakaの導出結果と一致しない")
+	}
+}
+
+func TestPRFPlus_MatchesAKAPrimeKeyDerivation(t *testing.T) {
+	identity := "6001010123456789@realm"
+	ckPrime := bytes.Repeat([]byte{0x33}, 16)
+	ikPrime := bytes.Repeat([]byte{0x44}, 16)
+
+	key := append(append([]byte{}, ikPrime...), ckPrime...)
+	block := PRFPlus(key, append([]byte("EAP-AKA'"), identity...), 208)
+
+	keys := eapaka.DeriveKeysAKAPrime(identity, ckPrime, ikPrime)
+	if !bytes.Equal(block[0:16], keys.K_encr) {
+		t.Error("K_encrがgo-eapakaの導出結果と一致しない")
+	}
+	if !bytes.Equal(block[16:48], keys.K_aut) {
+		t.Error("K_autがgo-eapakaの導出結果と一致しない")
+	}
+	if !bytes.Equal(block[48:80], keys.K_re) {
+		t.Error("K_reがgo-eapakaの導出結果と一致しない")
+	}
+	if !bytes.Equal(block[80:144], keys.MSK) {
+		t.Error("MSKがgo-eapakaの導出結果と一致しない")
+	}
+}
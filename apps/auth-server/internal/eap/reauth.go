@@ -0,0 +1,99 @@
+package eap
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// NonceSLength はAT_NONCE_Sの値長（RFC 4187 Section 10.18）
+const NonceSLength = 16
+
+// ReauthParams はEAP-Request/AKA-Reauthenticationの構築パラメータを保持する
+type ReauthParams struct {
+	Identifier   uint8
+	EAPType      uint8  // eapaka.TypeAKA or eapaka.TypeAKAPrime
+	Counter      uint16 // AT_COUNTER
+	NonceS       []byte // AT_NONCE_S（16バイト）
+	NextReauthID string // AT_NEXT_REAUTH_ID（空の場合は送信しない）
+	KEncr        []byte // フル認証時のK_encr
+	KAut         []byte // フル認証時のK_aut
+	ResultInd    bool   // AT_RESULT_IND（保護された結果通知の利用を提示）
+}
+
+// BuildReauthRequest はEAP-Request/AKA-Reauthenticationパケットを構築する（RFC 4187 Section 9.7）
+// 属性: AT_IV, AT_ENCR_DATA(AT_COUNTER, AT_NONCE_S, [AT_NEXT_REAUTH_ID]), [AT_RESULT_IND], AT_MAC
+func BuildReauthRequest(p *ReauthParams) ([]byte, error) {
+	encrAttrs := []eapaka.Attribute{
+		&eapaka.AtCounter{Counter: p.Counter},
+		&eapaka.AtNonceS{NonceS: p.NonceS},
+	}
+	if p.NextReauthID != "" {
+		encrAttrs = append(encrAttrs, &eapaka.AtNextReauthId{Identity: p.NextReauthID})
+	}
+	atIv, atEncr, err := EncryptAttributes(p.KEncr, encrAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeRequest,
+		Identifier: p.Identifier,
+		Type:       p.EAPType,
+		Subtype:    eapaka.SubtypeReauthentication,
+		Attributes: []eapaka.Attribute{atIv, atEncr},
+	}
+	if p.ResultInd {
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtResultInd{})
+	}
+	pkt.Attributes = append(pkt.Attributes, &eapaka.AtMac{MAC: make([]byte, macLength)})
+
+	// サーバー送信時のMACはパケットのみを対象とする
+	if err := pkt.CalculateAndSetMac(p.KAut); err != nil {
+		return nil, err
+	}
+	return pkt.Marshal()
+}
+
+// VerifyReauthResponse はEAP-Response/AKA-Reauthenticationを検証する（RFC 4187 Section 9.8）
+// 検証順序: AT_MAC（パケット|NONCE_S）→ AT_ENCR_DATA復号 → AT_COUNTER
+// ピアがAT_COUNTER_TOO_SMALLを含めた場合はcounterTooSmall=trueを返す
+func VerifyReauthResponse(pkt *eapaka.Packet, kEncr, kAut, nonceS []byte, counter uint16) (counterTooSmall bool, err error) {
+	// 1. MAC検証（NONCE_Sを連結）
+	if err := VerifyMACWithExtra(pkt, kAut, nonceS); err != nil {
+		return false, err
+	}
+
+	// 2. AT_ENCR_DATA復号
+	atIv, _ := GetAttribute[*eapaka.AtIv](pkt)
+	atEncr, _ := GetAttribute[*eapaka.AtEncrData](pkt)
+	attrs, err := DecryptAttributes(kEncr, atIv, atEncr)
+	if err != nil {
+		return false, err
+	}
+
+	// 3. AT_COUNTER照合
+	var gotCounter *eapaka.AtCounter
+	for _, attr := range attrs {
+		switch a := attr.(type) {
+		case *eapaka.AtCounter:
+			gotCounter = a
+		case *eapaka.AtCounterTooSmall:
+			counterTooSmall = true
+		}
+	}
+	if gotCounter == nil || gotCounter.Counter != counter {
+		return false, ErrCounterMismatch
+	}
+	return counterTooSmall, nil
+}
+
+// GenerateNonceS はAT_NONCE_S用の乱数を生成する
+func GenerateNonceS() ([]byte, error) {
+	nonce := make([]byte, NonceSLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("eap: failed to generate NONCE_S: %w", err)
+	}
+	return nonce, nil
+}
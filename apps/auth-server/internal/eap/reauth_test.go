@@ -0,0 +1,137 @@
+package eap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// buildTestReauthResponse はピア側のEAP-Response/AKA-Reauthenticationを構築する
+func buildTestReauthResponse(t *testing.T, eapType uint8, kEncr, kAut, nonceS []byte, counter uint16, tooSmall bool) *eapaka.Packet {
+	t.Helper()
+	encrAttrs := []eapaka.Attribute{&eapaka.AtCounter{Counter: counter}}
+	if tooSmall {
+		encrAttrs = append(encrAttrs, &eapaka.AtCounterTooSmall{})
+	}
+	atIv, atEncr, err := EncryptAttributes(kEncr, encrAttrs)
+	if err != nil {
+		t.Fatalf("暗号化失敗: %v", err)
+	}
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 2,
+		Type:       eapType,
+		Subtype:    eapaka.SubtypeReauthentication,
+		Attributes: []eapaka.Attribute{atIv, atEncr, &eapaka.AtMac{MAC: make([]byte, 16)}},
+	}
+	if err := CalculateMACWithExtra(pkt, kAut, nonceS); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+	data, _ := pkt.Marshal()
+	parsed, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	return parsed
+}
+
+func TestBuildReauthRequest(t *testing.T) {
+	kEncr := testKEncr()
+	kAut := bytes.Repeat([]byte{0x5a}, 16)
+	nonceS, err := GenerateNonceS()
+	if err != nil {
+		t.Fatalf("NONCE_S生成失敗: %v", err)
+	}
+
+	data, err := BuildReauthRequest(&ReauthParams{
+		Identifier:   3,
+		EAPType:      eapaka.TypeAKA,
+		Counter:      2,
+		NonceS:       nonceS,
+		NextReauthID: "4next",
+		KEncr:        kEncr,
+		KAut:         kAut,
+	})
+	if err != nil {
+		t.Fatalf("BuildReauthRequest失敗: %v", err)
+	}
+
+	pkt, err := eapaka.Parse(data)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	if pkt.Subtype != eapaka.SubtypeReauthentication {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeReauthentication)
+	}
+	if ok, err := pkt.VerifyMac(kAut); err != nil || !ok {
+		t.Errorf("MAC検証失敗: ok=%v, err=%v", ok, err)
+	}
+
+	atIv, _ := GetAttribute[*eapaka.AtIv](pkt)
+	atEncr, _ := GetAttribute[*eapaka.AtEncrData](pkt)
+	attrs, err := DecryptAttributes(kEncr, atIv, atEncr)
+	if err != nil {
+		t.Fatalf("復号失敗: %v", err)
+	}
+	var counter *eapaka.AtCounter
+	var gotNonce *eapaka.AtNonceS
+	var next *eapaka.AtNextReauthId
+	for _, a := range attrs {
+		switch v := a.(type) {
+		case *eapaka.AtCounter:
+			counter = v
+		case *eapaka.AtNonceS:
+			gotNonce = v
+		case *eapaka.AtNextReauthId:
+			next = v
+		}
+	}
+	if counter == nil || counter.Counter != 2 {
+		t.Errorf("AT_COUNTERが不正: %+v", counter)
+	}
+	if gotNonce == nil || !bytes.Equal(gotNonce.NonceS, nonceS) {
+		t.Error("AT_NONCE_Sが不正")
+	}
+	if next == nil || next.Identity != "4next" {
+		t.Errorf("AT_NEXT_REAUTH_IDが不正: %+v", next)
+	}
+}
+
+func TestVerifyReauthResponse(t *testing.T) {
+	kEncr := testKEncr()
+	nonceS := bytes.Repeat([]byte{0x77}, 16)
+
+	tests := []struct {
+		name         string
+		eapType      uint8
+		kAutLen      int
+		sendCounter  uint16
+		tooSmall     bool
+		verifyNonce  []byte
+		wantErr      error
+		wantTooSmall bool
+	}{
+		{name: "AKA正常", eapType: eapaka.TypeAKA, kAutLen: 16, sendCounter: 3, verifyNonce: nonceS},
+		{name: "AKA'正常", eapType: eapaka.TypeAKAPrime, kAutLen: 32, sendCounter: 3, verifyNonce: nonceS},
+		{name: "COUNTER_TOO_SMALL", eapType: eapaka.TypeAKA, kAutLen: 16, sendCounter: 3, tooSmall: true, verifyNonce: nonceS, wantTooSmall: true},
+		{name: "カウンタ不一致", eapType: eapaka.TypeAKA, kAutLen: 16, sendCounter: 2, verifyNonce: nonceS, wantErr: ErrCounterMismatch},
+		{name: "NONCE_S不一致", eapType: eapaka.TypeAKA, kAutLen: 16, sendCounter: 3, verifyNonce: make([]byte, 16), wantErr: ErrMACInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kAut := bytes.Repeat([]byte{0x5a}, tt.kAutLen)
+			pkt := buildTestReauthResponse(t, tt.eapType, kEncr, kAut, nonceS, tt.sendCounter, tt.tooSmall)
+
+			tooSmall, err := VerifyReauthResponse(pkt, kEncr, kAut, tt.verifyNonce, 3)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err: got %v, want %v", err, tt.wantErr)
+			}
+			if tooSmall != tt.wantTooSmall {
+				t.Errorf("counterTooSmall: got %v, want %v", tooSmall, tt.wantTooSmall)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package sim
+
+import (
+	"bytes"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// RAND個数の範囲（RFC 4186 Section 10.9）
+const (
+	MinRANDs = 2
+	MaxRANDs = 3
+)
+
+// randLength はGSM RANDの長さ
+const randLength = 16
+
+// BuildChallenge はEAP-Request/SIM/Challengeパケットを構築する（RFC 4186 Section 9.4）
+// 属性: AT_RAND（n*RAND）, [AT_RESULT_IND, AT_IV, AT_ENCR_DATA], AT_MAC
+// AT_MACはパケットにNONCE_MTを連結して計算する
+func BuildChallenge(identifier uint8, rands [][]byte, nonceMT, kAut []byte, opts *eap.ChallengeOptions) ([]byte, error) {
+	if len(rands) < MinRANDs || len(rands) > MaxRANDs {
+		return nil, eap.ErrRANDCountInvalid
+	}
+
+	// go-eapakaのAtRandはRAND1個のみ対応のため、予約2バイト + n*RANDを直接組み立てる
+	randData := make([]byte, 2, 2+len(rands)*randLength)
+	for _, r := range rands {
+		if len(r) != randLength {
+			return nil, eap.ErrRANDCountInvalid
+		}
+		randData = append(randData, r...)
+	}
+
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeRequest,
+		Identifier: identifier,
+		Type:       eap.EAPTypeSIM,
+		Subtype:    SubtypeChallenge,
+		Attributes: []eapaka.Attribute{
+			&eapaka.GenericAttribute{AttrType: eapaka.AT_RAND, Data: randData},
+		},
+	}
+
+	extra, err := opts.Attributes()
+	if err != nil {
+		return nil, err
+	}
+	pkt.Attributes = append(pkt.Attributes, extra...)
+	pkt.Attributes = append(pkt.Attributes, &eapaka.AtMac{MAC: make([]byte, 16)})
+
+	if err := eap.CalculateMACWithExtra(pkt, kAut, nonceMT); err != nil {
+		return nil, err
+	}
+	return eap.MarshalPacket(pkt)
+}
+
+// VerifyChallengeResponse はEAP-Response/SIM/Challengeを検証する（RFC 4186 Section 9.5）
+// AT_MACはパケットにn*SRESを連結して計算する
+func VerifyChallengeResponse(pkt *eapaka.Packet, kAut []byte, sres [][]byte) error {
+	return eap.VerifyMACWithExtra(pkt, kAut, bytes.Join(sres, nil))
+}
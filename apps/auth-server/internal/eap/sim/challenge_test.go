@@ -0,0 +1,151 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// setupChallengeTest はテスト用のK_aut・RAND・SRES・NONCE_MTを生成する
+func setupChallengeTest(t *testing.T, n int) (kAut []byte, rands, sres [][]byte, nonceMT []byte) {
+	t.Helper()
+
+	kcs := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		r := make([]byte, 16)
+		s := make([]byte, 4)
+		kc := make([]byte, 8)
+		for j := range r {
+			r[j] = byte(i*16 + j)
+		}
+		for j := range s {
+			s[j] = byte(0xa0 + i*4 + j)
+		}
+		for j := range kc {
+			kc[j] = byte(0xc0 + i*8 + j)
+		}
+		rands = append(rands, r)
+		sres = append(sres, s)
+		kcs[i] = kc
+	}
+	nonceMT = bytes.Repeat([]byte{0x5a}, 16)
+	kAut = DeriveKeys("1001010123456789@realm", kcs, nonceMT, Version1).K_aut
+	return
+}
+
+// buildValidResponse はテスト用の有効なEAP-Response/SIM/Challengeパケットを構築する
+func buildValidResponse(t *testing.T, kAut []byte, sres [][]byte) *eapaka.Packet {
+	t.Helper()
+
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 2,
+		Type:       eap.EAPTypeSIM,
+		Subtype:    SubtypeChallenge,
+		Attributes: []eapaka.Attribute{&eapaka.AtMac{MAC: make([]byte, 16)}},
+	}
+	if err := eap.CalculateMACWithExtra(pkt, kAut, bytes.Join(sres, nil)); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+	return pkt
+}
+
+func TestBuildChallenge_Success(t *testing.T) {
+	for _, n := range []int{MinRANDs, MaxRANDs} {
+		kAut, rands, _, nonceMT := setupChallengeTest(t, n)
+
+		data, err := BuildChallenge(1, rands, nonceMT, kAut, &eap.ChallengeOptions{ResultInd: true})
+		if err != nil {
+			t.Fatalf("BuildChallenge失敗: %v", err)
+		}
+		if eap.GetEAPType(data) != eap.EAPTypeSIM || data[5] != SubtypeChallenge {
+			t.Fatalf("Type/Subtypeが不正: %d/%d", data[4], data[5])
+		}
+
+		// AT_RAND: Type(1) + Length(1) + Reserved(2) + n*16
+		atRand := data[8:]
+		if eapaka.AttributeType(atRand[0]) != eapaka.AT_RAND || int(atRand[1])*4 != 4+n*16 {
+			t.Fatalf("AT_RANDが不正: type=%d len=%d", atRand[0], atRand[1])
+		}
+		if !bytes.Equal(atRand[4:4+n*16], bytes.Join(rands, nil)) {
+			t.Error("AT_RANDの値が不正")
+		}
+		if binary.BigEndian.Uint16(data[2:4]) != uint16(len(data)) {
+			t.Error("Lengthが不正")
+		}
+	}
+}
+
+func TestBuildChallenge_MACIncludesNonceMT(t *testing.T) {
+	kAut, rands, _, nonceMT := setupChallengeTest(t, 2)
+
+	data1, err := BuildChallenge(1, rands, nonceMT, kAut, nil)
+	if err != nil {
+		t.Fatalf("BuildChallenge失敗: %v", err)
+	}
+	data2, err := BuildChallenge(1, rands, make([]byte, 16), kAut, nil)
+	if err != nil {
+		t.Fatalf("BuildChallenge失敗: %v", err)
+	}
+	if bytes.Equal(data1, data2) {
+		t.Error("NONCE_MTが異なるのにAT_MACが一致")
+	}
+}
+
+func TestBuildChallenge_InvalidRANDCount(t *testing.T) {
+	kAut, rands, _, nonceMT := setupChallengeTest(t, 3)
+
+	tests := []struct {
+		name  string
+		rands [][]byte
+	}{
+		{"1個", rands[:1]},
+		{"4個", append(rands, rands[0])},
+		{"長さ不正", [][]byte{rands[0], rands[1][:8]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildChallenge(1, tt.rands, nonceMT, kAut, nil)
+			if !errors.Is(err, eap.ErrRANDCountInvalid) {
+				t.Errorf("エラーが不正: got=%v, want=%v", err, eap.ErrRANDCountInvalid)
+			}
+		})
+	}
+}
+
+func TestVerifyChallengeResponse_Success(t *testing.T) {
+	kAut, _, sres, _ := setupChallengeTest(t, 3)
+	pkt := buildValidResponse(t, kAut, sres)
+
+	if err := VerifyChallengeResponse(pkt, kAut, sres); err != nil {
+		t.Errorf("検証失敗: %v", err)
+	}
+}
+
+func TestVerifyChallengeResponse_SRESMismatch(t *testing.T) {
+	kAut, _, sres, _ := setupChallengeTest(t, 3)
+	pkt := buildValidResponse(t, kAut, sres)
+
+	wrong := [][]byte{sres[0], sres[1], {0, 0, 0, 0}}
+	if err := VerifyChallengeResponse(pkt, kAut, wrong); !errors.Is(err, eap.ErrMACInvalid) {
+		t.Errorf("エラーが不正: got=%v, want=%v", err, eap.ErrMACInvalid)
+	}
+}
+
+func TestVerifyChallengeResponse_NoMAC(t *testing.T) {
+	kAut, _, sres, _ := setupChallengeTest(t, 2)
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 2,
+		Type:       eap.EAPTypeSIM,
+		Subtype:    SubtypeChallenge,
+	}
+
+	if err := VerifyChallengeResponse(pkt, kAut, sres); !errors.Is(err, eap.ErrMACInvalid) {
+		t.Errorf("エラーが不正: got=%v, want=%v", err, eap.ErrMACInvalid)
+	}
+}
@@ -0,0 +1,40 @@
+package sim
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+)
+
+// KeyMaterial はEAP-SIM鍵導出結果を保持する
+type KeyMaterial struct {
+	K_encr []byte // 16バイト: 暗号化鍵
+	K_aut  []byte // 16バイト: 認証鍵
+	MSK    []byte // 64バイト: マスターセッション鍵
+	EMSK   []byte // 64バイト: 拡張マスターセッション鍵
+	MK     []byte // 20バイト: マスター鍵
+}
+
+// DeriveKeys はEAP-SIM鍵導出を行う（RFC 4186 Section 7）
+// MK = SHA1(Identity | n*Kc | NONCE_MT | Version List | Selected Version)
+func DeriveKeys(identity string, kcs [][]byte, nonceMT []byte, selectedVersion uint16) *KeyMaterial {
+	h := sha1.New()
+	h.Write([]byte(identity))
+	for _, kc := range kcs {
+		h.Write(kc)
+	}
+	h.Write(nonceMT)
+	h.Write(versionListBytes())
+	h.Write(binary.BigEndian.AppendUint16(nil, selectedVersion))
+	mk := h.Sum(nil)
+
+	keyBlock := eap.FIPS186PRF(mk, 160)
+	return &KeyMaterial{
+		K_encr: keyBlock[0:16],
+		K_aut:  keyBlock[16:32],
+		MSK:    keyBlock[32:96],
+		EMSK:   keyBlock[96:160],
+		MK:     mk,
+	}
+}
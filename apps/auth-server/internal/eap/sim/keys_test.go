@@ -0,0 +1,51 @@
+package sim
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// RFC 4186 Appendix A のテストベクター
+func TestDeriveKeys_RFC4186Vector(t *testing.T) {
+	kc1, _ := hex.DecodeString("a0a1a2a3a4a5a6a7")
+	kc2, _ := hex.DecodeString("b0b1b2b3b4b5b6b7")
+	kc3, _ := hex.DecodeString("c0c1c2c3c4c5c6c7")
+	nonceMT, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+
+	km := DeriveKeys("1244070100000001@eapsim.foo", [][]byte{kc1, kc2, kc3}, nonceMT, Version1)
+
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"MK", km.MK, "e576d5ca332e9930018bf1baee2763c795b3c712"},
+		{"K_encr", km.K_encr, "536e5ebc4465582aa6a8ec9986ebb620"},
+		{"K_aut", km.K_aut, "25af1942efcbf4bc72b3943421f2a974"},
+		{"MSK", km.MSK, "39d45aeaf4e30601983e972b6cfd46d1c363773365690d09cd44976b525f47d3" +
+			"a60a985e955c53b090b2e4b73719196a402542968fd14a888f46b9a7886e4488"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.got); got != tt.want {
+				t.Errorf("%sが不正: got=%s, want=%s", tt.name, got, tt.want)
+			}
+		})
+	}
+	if len(km.EMSK) != 64 {
+		t.Errorf("EMSKのサイズが不正: got=%d, want=64", len(km.EMSK))
+	}
+}
+
+func TestDeriveKeys_DifferentNonce(t *testing.T) {
+	kcs := [][]byte{make([]byte, 8), make([]byte, 8)}
+	n1 := make([]byte, 16)
+	n2 := make([]byte, 16)
+	n2[0] = 1
+
+	km1 := DeriveKeys("1001010123456789@realm", kcs, n1, Version1)
+	km2 := DeriveKeys("1001010123456789@realm", kcs, n2, Version1)
+	if hex.EncodeToString(km1.MK) == hex.EncodeToString(km2.MK) {
+		t.Error("NONCE_MTが異なるのにMKが一致")
+	}
+}
@@ -0,0 +1,89 @@
+// Package sim はEAP-SIM（RFC 4186）のパケット構築・検証と鍵導出を提供する
+package sim
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// EAP-SIM Subtype（RFC 4186 Section 11）
+// Notification/Re-authentication/Client-ErrorはEAP-AKAと同じ値を使用する
+const (
+	SubtypeStart     uint8 = 10
+	SubtypeChallenge uint8 = 11
+)
+
+// Version1 はサポートするEAP-SIMバージョン（RFC 4186 Section 10.2）
+const Version1 uint16 = 1
+
+// supportedVersions はAT_VERSION_LISTで提示するバージョン一覧
+var supportedVersions = []uint16{Version1}
+
+// StartResponse はEAP-Response/SIM/Startから抽出した値を保持する
+type StartResponse struct {
+	NonceMT         []byte // AT_NONCE_MT（16バイト）
+	SelectedVersion uint16 // AT_SELECTED_VERSION
+	Identity        string // AT_IDENTITY（含まれない場合は空）
+}
+
+// BuildStart はEAP-Request/SIM/Startパケットを構築する（RFC 4186 Section 9.2）
+// 属性: AT_VERSION_LIST, [AT_PERMANENT_ID_REQ | AT_FULLAUTH_ID_REQ | AT_ANY_ID_REQ]
+// reqTypeが0の場合はIdentity要求属性を含めない
+func BuildStart(identifier uint8, reqType eap.IdentityReqType) ([]byte, error) {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeRequest,
+		Identifier: identifier,
+		Type:       eap.EAPTypeSIM,
+		Subtype:    SubtypeStart,
+		Attributes: []eapaka.Attribute{
+			&eapaka.AtVersionList{Versions: supportedVersions},
+		},
+	}
+
+	switch reqType {
+	case 0:
+	case eap.IdentityReqAny:
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtAnyIdReq{})
+	case eap.IdentityReqFullAuth:
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtFullauthIdReq{})
+	case eap.IdentityReqPermanent:
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtPermanentIdReq{})
+	default:
+		return nil, fmt.Errorf("sim: invalid identity request type: %d", reqType)
+	}
+
+	return eap.MarshalPacket(pkt)
+}
+
+// ParseStartResponse はEAP-Response/SIM/Startから鍵導出に必要な値を取り出す（RFC 4186 Section 9.3）
+func ParseStartResponse(pkt *eapaka.Packet) (*StartResponse, error) {
+	atNonce, found := eap.GetAttribute[*eapaka.AtNonceMt](pkt)
+	if !found {
+		return nil, eap.ErrNonceMTNotFound
+	}
+	atVersion, found := eap.GetAttribute[*eapaka.AtSelectedVersion](pkt)
+	if !found || atVersion.Version != Version1 {
+		return nil, eap.ErrSIMVersionUnsupported
+	}
+
+	resp := &StartResponse{
+		NonceMT:         atNonce.NonceMt,
+		SelectedVersion: atVersion.Version,
+	}
+	if atIdentity, found := eap.GetAttribute[*eapaka.AtIdentity](pkt); found {
+		resp.Identity = atIdentity.Identity
+	}
+	return resp, nil
+}
+
+// versionListBytes はMK計算に使用するAT_VERSION_LISTの値（実データ部）を返す
+func versionListBytes() []byte {
+	buf := make([]byte, 0, len(supportedVersions)*2)
+	for _, v := range supportedVersions {
+		buf = binary.BigEndian.AppendUint16(buf, v)
+	}
+	return buf
+}
@@ -0,0 +1,146 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+func TestBuildStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		reqType eap.IdentityReqType
+		want    eapaka.AttributeType // 0の場合はIdentity要求属性なし
+	}{
+		{"Identity要求なし", 0, 0},
+		{"AT_PERMANENT_ID_REQ", eap.IdentityReqPermanent, eapaka.AT_PERMANENT_ID_REQ},
+		{"AT_FULLAUTH_ID_REQ", eap.IdentityReqFullAuth, eapaka.AT_FULLAUTH_ID_REQ},
+		{"AT_ANY_ID_REQ", eap.IdentityReqAny, eapaka.AT_ANY_ID_REQ},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := BuildStart(5, tt.reqType)
+			if err != nil {
+				t.Fatalf("BuildStart失敗: %v", err)
+			}
+			if eap.GetEAPType(data) != eap.EAPTypeSIM {
+				t.Fatalf("Typeが不正: got=%d, want=%d", eap.GetEAPType(data), eap.EAPTypeSIM)
+			}
+
+			pkt, err := eap.ParseEAPPacket(data)
+			if err != nil {
+				t.Fatalf("パース失敗: %v", err)
+			}
+			if pkt.Subtype != SubtypeStart || pkt.Identifier != 5 {
+				t.Errorf("Subtype/Identifierが不正: got=%d/%d", pkt.Subtype, pkt.Identifier)
+			}
+			vl, found := eap.GetAttribute[*eapaka.AtVersionList](pkt)
+			if !found || len(vl.Versions) != 1 || vl.Versions[0] != Version1 {
+				t.Errorf("AT_VERSION_LISTが不正: %+v", vl)
+			}
+
+			var idReq eapaka.AttributeType
+			for _, attr := range pkt.Attributes {
+				switch attr.Type() {
+				case eapaka.AT_PERMANENT_ID_REQ, eapaka.AT_FULLAUTH_ID_REQ, eapaka.AT_ANY_ID_REQ:
+					idReq = attr.Type()
+				}
+			}
+			if idReq != tt.want {
+				t.Errorf("Identity要求属性が不正: got=%d, want=%d", idReq, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildStart_InvalidReqType(t *testing.T) {
+	if _, err := BuildStart(1, eap.IdentityReqType(0x80)); err == nil {
+		t.Error("不正な要求種別でエラーにならない")
+	}
+}
+
+func TestParseStartResponse(t *testing.T) {
+	nonceMT := make([]byte, 16)
+	for i := range nonceMT {
+		nonceMT[i] = byte(i)
+	}
+
+	tests := []struct {
+		name    string
+		attrs   []eapaka.Attribute
+		wantErr error
+		wantID  string
+	}{
+		{
+			name: "正常（AT_IDENTITYあり）",
+			attrs: []eapaka.Attribute{
+				&eapaka.AtNonceMt{NonceMt: nonceMT},
+				&eapaka.AtSelectedVersion{Version: Version1},
+				&eapaka.AtIdentity{Identity: "1001010123456789@realm"},
+			},
+			wantID: "1001010123456789@realm",
+		},
+		{
+			name: "正常（AT_IDENTITYなし）",
+			attrs: []eapaka.Attribute{
+				&eapaka.AtNonceMt{NonceMt: nonceMT},
+				&eapaka.AtSelectedVersion{Version: Version1},
+			},
+		},
+		{
+			name:    "AT_NONCE_MTなし",
+			attrs:   []eapaka.Attribute{&eapaka.AtSelectedVersion{Version: Version1}},
+			wantErr: eap.ErrNonceMTNotFound,
+		},
+		{
+			name:    "AT_SELECTED_VERSIONなし",
+			attrs:   []eapaka.Attribute{&eapaka.AtNonceMt{NonceMt: nonceMT}},
+			wantErr: eap.ErrSIMVersionUnsupported,
+		},
+		{
+			name: "非対応バージョン",
+			attrs: []eapaka.Attribute{
+				&eapaka.AtNonceMt{NonceMt: nonceMT},
+				&eapaka.AtSelectedVersion{Version: 2},
+			},
+			wantErr: eap.ErrSIMVersionUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 送受信と同じ経路でシリアライズ・パースする
+			data, err := eap.MarshalPacket(&eapaka.Packet{
+				Code:       eapaka.CodeResponse,
+				Identifier: 1,
+				Type:       eap.EAPTypeSIM,
+				Subtype:    SubtypeStart,
+				Attributes: tt.attrs,
+			})
+			if err != nil {
+				t.Fatalf("MarshalPacket失敗: %v", err)
+			}
+			pkt, err := eap.ParseEAPPacket(data)
+			if err != nil {
+				t.Fatalf("パース失敗: %v", err)
+			}
+
+			resp, err := ParseStartResponse(pkt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("エラーが不正: got=%v, want=%v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if string(resp.NonceMT) != string(nonceMT) {
+				t.Errorf("NONCE_MTが不正: %x", resp.NonceMT)
+			}
+			if resp.Identity != tt.wantID {
+				t.Errorf("Identityが不正: got=%q, want=%q", resp.Identity, tt.wantID)
+			}
+		})
+	}
+}
@@ -3,14 +3,17 @@ package eap
 // EAPState はEAP認証の状態を表す型（D-03セクション2.2準拠）
 type EAPState string
 
-// EAP認証状態の定数（8状態）
+// EAP認証状態の定数（11状態）
 const (
 	StateNew              EAPState = "NEW"               // 初期状態
-	StateWaitingIdentity  EAPState = "WAITING_IDENTITY"  // 永続ID応答待ち
+	StateWaitingIdentity  EAPState = "WAITING_IDENTITY"  // AKA-Identity応答待ち
+	StateSIMStartSent     EAPState = "SIM_START_SENT"    // EAP-SIM Start送信済み
 	StateIdentityReceived EAPState = "IDENTITY_RECEIVED" // 永続ID受領済み
 	StateWaitingVector    EAPState = "WAITING_VECTOR"    // Vector Gateway応答待ち
 	StateChallengeSent    EAPState = "CHALLENGE_SENT"    // Challenge送信済み
 	StateResyncSent       EAPState = "RESYNC_SENT"       // 再同期処理中
+	StateReauthSent       EAPState = "REAUTH_SENT"       // 高速再認証Request送信済み
+	StateNotificationSent EAPState = "NOTIFICATION_SENT" // 認証後のAKA-Notification送信済み
 	StateSuccess          EAPState = "SUCCESS"           // 認証成功（終了状態）
 	StateFailure          EAPState = "FAILURE"           // 認証失敗（終了状態）
 )
@@ -18,12 +21,14 @@ const (
 // StateEvent はEAP認証の状態遷移イベントを表す型（D-03セクション2.4準拠）
 type StateEvent string
 
-// EAP認証イベントの定数（16イベント）
+// EAP認証イベントの定数（25イベント）
 const (
 	EventPermanentIdentity   StateEvent = "PERMANENT_IDENTITY"   // 永続ID受信（'0','6'）
-	EventPseudonymIdentity   StateEvent = "PSEUDONYM_IDENTITY"   // 仮名/再認証ID受信（'2','4','7','8'）
-	EventUnsupportedIdentity StateEvent = "UNSUPPORTED_IDENTITY" // 非対応ID（EAP-SIM: '1','3','5'）
+	EventPseudonymIdentity   StateEvent = "PSEUDONYM_IDENTITY"   // 未知の仮名/再認証ID・匿名ID受信（'2','4','7','8'）
+	EventUnsupportedIdentity StateEvent = "UNSUPPORTED_IDENTITY" // 非対応ID
 	EventInvalidIdentity     StateEvent = "INVALID_IDENTITY"     // 不正形式
+	EventSIMIdentity         StateEvent = "SIM_IDENTITY"         // EAP-SIM ID受信（'1','3','5'）→ SIM/Start送信
+	EventSIMStartResponse    StateEvent = "SIM_START_RESPONSE"   // SIM/Start応答受信（NONCE_MT・永続ID確定）
 	EventVectorRequest       StateEvent = "VECTOR_REQUEST"       // Vector Gateway呼び出し開始
 	EventVectorSuccess       StateEvent = "VECTOR_SUCCESS"       // Vector API成功
 	EventVectorError         StateEvent = "VECTOR_ERROR"         // Vector APIエラー
@@ -36,6 +41,13 @@ const (
 	EventClientError         StateEvent = "CLIENT_ERROR"         // Client-Error受信
 	EventResyncSuccess       StateEvent = "RESYNC_SUCCESS"       // 再同期Vector成功
 	EventResyncError         StateEvent = "RESYNC_ERROR"         // 再同期Vectorエラー
+	EventReauthIdentity      StateEvent = "REAUTH_IDENTITY"      // 既知の再認証ID受信（'4','8'）
+	EventReauthOK            StateEvent = "REAUTH_OK"            // 再認証応答のMAC/COUNTER検証OK + ポリシーOK
+	EventReauthFail          StateEvent = "REAUTH_FAIL"          // 再認証応答の検証NG / ポリシーNG
+	EventCounterTooSmall     StateEvent = "COUNTER_TOO_SMALL"    // AT_COUNTER_TOO_SMALL受信（フル認証へ移行）
+	EventResultNotify        StateEvent = "RESULT_NOTIFY"        // 保護された結果通知（成功/認証後の失敗）送信
+	EventNotifySuccessAck    StateEvent = "NOTIFY_SUCCESS_ACK"   // 成功通知へのNotification応答受信
+	EventNotifyFailureAck    StateEvent = "NOTIFY_FAILURE_ACK"   // 失敗通知へのNotification応答受信
 )
 
 // transitionTable はEAP状態遷移テーブル（D-03セクション2.3準拠）
@@ -43,16 +55,23 @@ var transitionTable = map[EAPState]map[StateEvent]EAPState{
 	StateNew: {
 		EventPermanentIdentity:   StateIdentityReceived,
 		EventPseudonymIdentity:   StateWaitingIdentity,
+		EventReauthIdentity:      StateReauthSent,
+		EventSIMIdentity:         StateSIMStartSent,
 		EventUnsupportedIdentity: StateFailure,
 		EventInvalidIdentity:     StateFailure,
 	},
 	StateWaitingIdentity: {
 		EventPermanentIdentity:   StateIdentityReceived,
-		EventPseudonymIdentity:   StateFailure, // 永続ID応答拒否
+		EventPseudonymIdentity:   StateWaitingIdentity, // より強いAKA-Identity要求を再送
+		EventReauthIdentity:      StateReauthSent,
 		EventUnsupportedIdentity: StateFailure,
 		EventInvalidIdentity:     StateFailure,
 		EventClientError:         StateFailure,
 	},
+	StateSIMStartSent: {
+		EventSIMStartResponse: StateIdentityReceived,
+		EventClientError:      StateFailure,
+	},
 	StateIdentityReceived: {
 		EventVectorRequest: StateWaitingVector,
 	},
@@ -67,11 +86,24 @@ var transitionTable = map[EAPState]map[StateEvent]EAPState{
 		EventResyncLimit:   StateFailure,
 		EventAuthReject:    StateFailure,
 		EventClientError:   StateFailure,
+		EventResultNotify:  StateNotificationSent,
 	},
 	StateResyncSent: {
 		EventResyncSuccess: StateChallengeSent,
 		EventResyncError:   StateFailure,
 	},
+	StateReauthSent: {
+		EventReauthOK:        StateSuccess,
+		EventReauthFail:      StateFailure,
+		EventCounterTooSmall: StateIdentityReceived,
+		EventClientError:     StateFailure,
+		EventResultNotify:    StateNotificationSent,
+	},
+	StateNotificationSent: {
+		EventNotifySuccessAck: StateSuccess,
+		EventNotifyFailureAck: StateFailure,
+		EventClientError:      StateFailure,
+	},
 }
 
 // ValidateTransition は現在の状態とイベントから次の状態を返す。
@@ -104,10 +136,13 @@ func IsTerminal(state EAPState) bool {
 var validStates = map[EAPState]struct{}{
 	StateNew:              {},
 	StateWaitingIdentity:  {},
+	StateSIMStartSent:     {},
 	StateIdentityReceived: {},
 	StateWaitingVector:    {},
 	StateChallengeSent:    {},
 	StateResyncSent:       {},
+	StateReauthSent:       {},
+	StateNotificationSent: {},
 	StateSuccess:          {},
 	StateFailure:          {},
 }
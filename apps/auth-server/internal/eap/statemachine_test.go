@@ -15,16 +15,23 @@ func TestValidateTransition_Valid(t *testing.T) {
 		// NEW状態からの遷移
 		{"NEW->IDENTITY_RECEIVED(永続ID)", StateNew, EventPermanentIdentity, StateIdentityReceived},
 		{"NEW->WAITING_IDENTITY(仮名ID)", StateNew, EventPseudonymIdentity, StateWaitingIdentity},
+		{"NEW->REAUTH_SENT(再認証ID)", StateNew, EventReauthIdentity, StateReauthSent},
+		{"NEW->SIM_START_SENT(EAP-SIM ID)", StateNew, EventSIMIdentity, StateSIMStartSent},
 		{"NEW->FAILURE(非対応ID)", StateNew, EventUnsupportedIdentity, StateFailure},
 		{"NEW->FAILURE(不正形式)", StateNew, EventInvalidIdentity, StateFailure},
 
 		// WAITING_IDENTITY状態からの遷移
 		{"WAITING_IDENTITY->IDENTITY_RECEIVED(永続ID)", StateWaitingIdentity, EventPermanentIdentity, StateIdentityReceived},
-		{"WAITING_IDENTITY->FAILURE(仮名ID拒否)", StateWaitingIdentity, EventPseudonymIdentity, StateFailure},
+		{"WAITING_IDENTITY->WAITING_IDENTITY(未知の仮名ID)", StateWaitingIdentity, EventPseudonymIdentity, StateWaitingIdentity},
+		{"WAITING_IDENTITY->REAUTH_SENT(再認証ID)", StateWaitingIdentity, EventReauthIdentity, StateReauthSent},
 		{"WAITING_IDENTITY->FAILURE(非対応ID)", StateWaitingIdentity, EventUnsupportedIdentity, StateFailure},
 		{"WAITING_IDENTITY->FAILURE(不正形式)", StateWaitingIdentity, EventInvalidIdentity, StateFailure},
 		{"WAITING_IDENTITY->FAILURE(ClientError)", StateWaitingIdentity, EventClientError, StateFailure},
 
+		// SIM_START_SENT状態からの遷移
+		{"SIM_START_SENT->IDENTITY_RECEIVED(Start応答)", StateSIMStartSent, EventSIMStartResponse, StateIdentityReceived},
+		{"SIM_START_SENT->FAILURE(ClientError)", StateSIMStartSent, EventClientError, StateFailure},
+
 		// IDENTITY_RECEIVED状態からの遷移
 		{"IDENTITY_RECEIVED->WAITING_VECTOR", StateIdentityReceived, EventVectorRequest, StateWaitingVector},
 
@@ -39,10 +46,23 @@ func TestValidateTransition_Valid(t *testing.T) {
 		{"CHALLENGE_SENT->FAILURE(再同期上限)", StateChallengeSent, EventResyncLimit, StateFailure},
 		{"CHALLENGE_SENT->FAILURE(AuthReject)", StateChallengeSent, EventAuthReject, StateFailure},
 		{"CHALLENGE_SENT->FAILURE(ClientError)", StateChallengeSent, EventClientError, StateFailure},
+		{"CHALLENGE_SENT->NOTIFICATION_SENT(結果通知)", StateChallengeSent, EventResultNotify, StateNotificationSent},
 
 		// RESYNC_SENT状態からの遷移
 		{"RESYNC_SENT->CHALLENGE_SENT(再同期成功)", StateResyncSent, EventResyncSuccess, StateChallengeSent},
 		{"RESYNC_SENT->FAILURE(再同期エラー)", StateResyncSent, EventResyncError, StateFailure},
+
+		// REAUTH_SENT状態からの遷移
+		{"REAUTH_SENT->SUCCESS(再認証OK)", StateReauthSent, EventReauthOK, StateSuccess},
+		{"REAUTH_SENT->FAILURE(再認証NG)", StateReauthSent, EventReauthFail, StateFailure},
+		{"REAUTH_SENT->IDENTITY_RECEIVED(COUNTER_TOO_SMALL)", StateReauthSent, EventCounterTooSmall, StateIdentityReceived},
+		{"REAUTH_SENT->FAILURE(ClientError)", StateReauthSent, EventClientError, StateFailure},
+		{"REAUTH_SENT->NOTIFICATION_SENT(結果通知)", StateReauthSent, EventResultNotify, StateNotificationSent},
+
+		// NOTIFICATION_SENT状態からの遷移
+		{"NOTIFICATION_SENT->SUCCESS(成功通知応答)", StateNotificationSent, EventNotifySuccessAck, StateSuccess},
+		{"NOTIFICATION_SENT->FAILURE(失敗通知応答)", StateNotificationSent, EventNotifyFailureAck, StateFailure},
+		{"NOTIFICATION_SENT->FAILURE(ClientError)", StateNotificationSent, EventClientError, StateFailure},
 	}
 
 	for _, tt := range tests {
@@ -74,6 +94,13 @@ func TestValidateTransition_Invalid(t *testing.T) {
 		{"WAITING_IDENTITY+VectorRequest", StateWaitingIdentity, EventVectorRequest},
 		{"WAITING_IDENTITY+ChallengeOK", StateWaitingIdentity, EventChallengeOK},
 
+		// SIM_START_SENT状態で無効なイベント
+		{"SIM_START_SENT+VectorRequest", StateSIMStartSent, EventVectorRequest},
+		{"SIM_START_SENT+ChallengeOK", StateSIMStartSent, EventChallengeOK},
+
+		// WAITING_IDENTITY状態でEAP-SIM IDは受け付けない（AKA-Identity交換中）
+		{"WAITING_IDENTITY+SIMIdentity", StateWaitingIdentity, EventSIMIdentity},
+
 		// IDENTITY_RECEIVED状態で無効なイベント
 		{"IDENTITY_RECEIVED+PermanentIdentity", StateIdentityReceived, EventPermanentIdentity},
 		{"IDENTITY_RECEIVED+ChallengeOK", StateIdentityReceived, EventChallengeOK},
@@ -89,6 +116,14 @@ func TestValidateTransition_Invalid(t *testing.T) {
 		// RESYNC_SENT状態で無効なイベント
 		{"RESYNC_SENT+PermanentIdentity", StateResyncSent, EventPermanentIdentity},
 		{"RESYNC_SENT+ChallengeOK", StateResyncSent, EventChallengeOK},
+
+		// REAUTH_SENT状態で無効なイベント
+		{"REAUTH_SENT+ChallengeOK", StateReauthSent, EventChallengeOK},
+		{"REAUTH_SENT+SyncFailure", StateReauthSent, EventSyncFailure},
+
+		// NOTIFICATION_SENT状態で無効なイベント
+		{"NOTIFICATION_SENT+ChallengeOK", StateNotificationSent, EventChallengeOK},
+		{"NOTIFICATION_SENT+ResultNotify", StateNotificationSent, EventResultNotify},
 	}
 
 	for _, tt := range tests {
@@ -189,13 +224,16 @@ func TestIsValidState(t *testing.T) {
 		input string
 		want  bool
 	}{
-		// 有効な8状態
+		// 有効な11状態
 		{"NEW", true},
 		{"WAITING_IDENTITY", true},
+		{"SIM_START_SENT", true},
 		{"IDENTITY_RECEIVED", true},
 		{"WAITING_VECTOR", true},
 		{"CHALLENGE_SENT", true},
 		{"RESYNC_SENT", true},
+		{"REAUTH_SENT", true},
+		{"NOTIFICATION_SENT", true},
 		{"SUCCESS", true},
 		{"FAILURE", true},
 
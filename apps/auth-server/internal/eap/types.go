@@ -1,6 +1,10 @@
 package eap
 
-import "context"
+import (
+	"context"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+)
 
 // Action はEAP処理結果のアクション種別を表す
 type Action string
@@ -14,13 +18,16 @@ const (
 
 // Request はEAP処理への入力を表す
 type Request struct {
-	TraceID       string // リクエスト追跡用UUID
-	SrcIP         string // 送信元IPアドレス
-	NASIdentifier string // NAS-Identifier属性
-	CalledStation string // Called-Station-Id属性
-	UserName      string // User-Name属性
-	State         []byte // RADIUS State属性（TraceID格納）
-	EAPMessage    []byte // EAP-Messageバイト列
+	TraceID        string // リクエスト追跡用UUID
+	SrcIP          string // 送信元IPアドレス
+	NASIdentifier  string // NAS-Identifier属性
+	NASIPAddress   string // NAS-IP-Address属性
+	NASPortType    int    // NAS-Port-Type属性（属性なしの場合は-1）
+	CalledStation  string // Called-Station-Id属性
+	CallingStation string // Calling-Station-Id属性
+	UserName       string // User-Name属性
+	State          []byte // RADIUS State属性（TraceID格納）
+	EAPMessage     []byte // EAP-Messageバイト列
 }
 
 // Result はEAP処理の結果を表す
@@ -33,6 +40,8 @@ type Result struct {
 	MSK            []byte // Accept時: Master Session Key
 	VlanID         string // Accept時: VLAN ID
 	SessionTimeout int    // Accept時: セッションタイムアウト秒数
+
+	ReplyAttributes []policy.ReplyAttribute // Accept時: ポリシールールの応答属性
 }
 
 // EAPProcessor はEAP認証処理のインターフェース
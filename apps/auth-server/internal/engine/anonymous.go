@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// handleAnonymousIdentity は匿名ID・3GPP形式でないNAIを受信した場合の処理を行う
+// EAP方式を決定し、EAP_ANONYMOUS_ID_REQで指定した種別のAKA-Identity Requestで改めてIdentityを要求する
+func (e *EngineImpl) handleAnonymousIdentity(ctx context.Context, req *eap.Request, pkt *eapaka.Packet, eapType uint8, rawIdentity string) (*eap.Result, error) {
+	kind := "anonymous"
+	if !eap.IsAnonymousIdentity(rawIdentity) {
+		kind = "unrecognized"
+	}
+
+	method, source := e.anonymousMethod(ctx, req, eapType, rawIdentity)
+
+	attrs := []any{
+		"event_id", "EAP_IDENTITY_RECEIVED",
+		"trace_id", req.TraceID,
+		"identity_kind", kind,
+		"eap_type", method,
+		"method_source", source,
+	}
+	if kind == "unrecognized" {
+		attrs = append(attrs, "user_name", rawIdentity)
+	}
+	slog.Info("匿名/未知形式のIdentityのためAKA-Identity要求を開始", attrs...)
+
+	return e.handleFullAuthRedirect(ctx, req, pkt, method, e.cfg.AnonymousIdentityRequest())
+}
+
+// anonymousMethod は匿名Identityに対して使用するEAP方式とその取得元を返す
+// 優先順位はピアが指定した方式（EAP-AKA/AKA'パケット）→ Realm対応表 → 既定値とし、
+// RADIUSクライアントがEAP-AKA'必須の場合はEAP-AKAをEAP-AKA'に置き換える
+func (e *EngineImpl) anonymousMethod(ctx context.Context, req *eap.Request, eapType uint8, rawIdentity string) (uint8, string) {
+	if eapType == eapaka.TypeAKA || eapType == eapaka.TypeAKAPrime {
+		return eapType, config.AnonymousMethodSourcePeer
+	}
+
+	method, source := e.cfg.AnonymousEAPType(identityRealm(rawIdentity))
+	if method != eapaka.TypeAKA || e.clientStore == nil {
+		return method, source
+	}
+
+	// 取得失敗時は既定の方式で継続する
+	client, ok := e.lookupClient(ctx, req, req.TraceID)
+	if ok && client != nil && client.RequireAKAPrime {
+		return eapaka.TypeAKAPrime, config.AnonymousMethodSourceClient
+	}
+	return method, source
+}
+
+// identityRealm はNAIのRealm部分を返す（"@"を含まない場合は空文字列）
+func identityRealm(identity string) string {
+	_, realm, _ := strings.Cut(identity, "@")
+	return realm
+}
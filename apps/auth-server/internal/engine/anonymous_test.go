@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// newAnonymousTestEngine は匿名Identityテスト用のエンジンとモックを生成する
+func newAnonymousTestEngine(ctrl *gomock.Controller, cfg *config.Config) (*EngineImpl, *mocks.MockContextStore, *mocks.MockClientStore) {
+	mockClientStore := mocks.NewMockClientStore(ctrl)
+	eng, m := newTestEngine(ctrl, cfg, WithClientStore(mockClientStore))
+	return eng, m.ctxStore, mockClientStore
+}
+
+// expectIdentityRequestType はAKA-Identity RequestのEAP Typeを検証する
+func expectIdentityRequestType(t *testing.T, result *eap.Result, want uint8) {
+	t.Helper()
+	pkt, err := eapaka.Parse(result.EAPMessage)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	if pkt.Type != want {
+		t.Errorf("EAP Type: got %d, want %d", pkt.Type, want)
+	}
+}
+
+func TestEngine_UnrecognizedNAI_AnyIDRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqAny)).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "user@wlan.mnc001.mcc001.3gppnetwork.org",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKAPrime),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqAny)
+	// ピアが指定した方式を優先する
+	expectIdentityRequestType(t, result, eapaka.TypeAKAPrime)
+}
+
+func TestEngine_AnonymousIdentity_MethodSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		client   *store.RadiusClient
+		wantType uint8
+	}{
+		{"既定値", "anonymous@wlan.mnc001.mcc001.3gppnetwork.org", nil, eapaka.TypeAKA},
+		{"Realm対応表", "anonymous@aka-prime.example.org", nil, eapaka.TypeAKAPrime},
+		{"Realm対応表（未知形式NAI）", "user@Aka-Prime.Example.org", nil, eapaka.TypeAKAPrime},
+		{"クライアントのAKA'必須設定", "anonymous@realm", &store.RadiusClient{IP: "192.168.1.1", RequireAKAPrime: true}, eapaka.TypeAKAPrime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cfg := newTestConfig()
+			cfg.AnonymousMethodByRealm = config.NameMap{"aka-prime.example.org": "aka-prime"}
+			eng, mockCtxStore, mockClientStore := newAnonymousTestEngine(ctrl, cfg)
+
+			if tt.wantType == eapaka.TypeAKA || tt.client != nil {
+				mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").Return(tt.client, nil)
+			}
+			mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+			mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				SrcIP:      "192.168.1.1",
+				UserName:   tt.userName,
+				EAPMessage: buildEAPResponseIdentity(1, tt.userName),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			expectIdentityRequest(t, result, eap.IdentityReqAny)
+			expectIdentityRequestType(t, result, tt.wantType)
+		})
+	}
+}
+
+func TestEngine_AnonymousIdentity_ClientLookupError_UsesDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockCtxStore, mockClientStore := newAnonymousTestEngine(ctrl, newTestConfig())
+
+	mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").Return(nil, errors.New("connection refused"))
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		SrcIP:      "192.168.1.1",
+		UserName:   "anonymous@realm",
+		EAPMessage: buildEAPResponseIdentity(1, "anonymous@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequestType(t, result, eapaka.TypeAKA)
+}
+
+func TestEngine_AnonymousIdentity_PermanentIDRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := newTestConfig()
+	cfg.AnonymousIDReq = "permanent"
+	eng, mockCtxStore, _ := newAnonymousTestEngine(ctrl, cfg)
+
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqPermanent)).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "anonymous@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqPermanent)
+}
+
+func TestEngine_IdentityResponse_UnrecognizedNAI_Escalates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqAny|eap.IdentityReqFullAuth)).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "user@example.org"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqFullAuth)
+}
@@ -0,0 +1,23 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+)
+
+// restoreCheckcode はEAPコンテキストに保存したAKA-Identityメッセージのハッシュ途中状態を復元する
+// AKA-Identity交換を行っていない場合はnil、復元できない場合はfalseを返す
+func (e *EngineImpl) restoreCheckcode(traceID string, eapCtx *session.EAPContext) (*eap.Checkcode, bool) {
+	cc, err := eap.RestoreCheckcode(eapCtx.Checkcode)
+	if err != nil {
+		slog.Error("AT_CHECKCODE状態復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return nil, false
+	}
+	return cc, true
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// buildChallengeResponseWithCheckcode はAT_CHECKCODEを含むEAP-Response/AKA-Challengeを構築する
+func buildChallengeResponseWithCheckcode(identifier uint8, kAut, xres, checkcode []byte) []byte {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeChallenge,
+		Attributes: []eapaka.Attribute{
+			&eapaka.AtRes{Res: xres},
+			&eapaka.AtCheckcode{Checkcode: checkcode},
+			&eapaka.AtMac{MAC: make([]byte, 16)},
+		},
+	}
+	_ = pkt.CalculateAndSetMac(kAut)
+	data, _ := pkt.Marshal()
+	return data
+}
+
+func TestEngine_Checkcode_IdentityExchangeToChallenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	// 1. 匿名ID → AKA-Identity Request送信（送信メッセージのハッシュ途中状態を保存）
+	var reqUpdates map[string]any
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			reqUpdates = u
+			return nil
+		})
+
+	identityReq, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "anonymous@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, identityReq, eap.IdentityReqAny)
+
+	// 2. AKA-Identity応答 → AT_CHECKCODE付きのChallenge送信
+	identityResp := buildAKAIdentityResponse(2, eapaka.TypeAKA, "0"+testIMSI+"@realm")
+	eapCtx := makeWaitingIdentityContext(eap.IdentityReqAny)
+	eapCtx.Checkcode = reqUpdates["checkcode"].(string)
+
+	var idUpdates map[string]any
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockCtxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, u map[string]any) error {
+			idUpdates = u
+			return nil
+		})
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	challenge, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: identityResp,
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if challenge.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", challenge.Action, eap.ActionChallenge)
+	}
+
+	pkt, err := eapaka.Parse(challenge.EAPMessage)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	atCheckcode, found := eap.GetAttribute[*eapaka.AtCheckcode](pkt)
+	if !found {
+		t.Fatal("ChallengeにAT_CHECKCODEが含まれていない")
+	}
+	want := sha1.Sum(append(append([]byte(nil), identityReq.EAPMessage...), identityResp...))
+	if !bytes.Equal(atCheckcode.Checkcode, want[:]) {
+		t.Errorf("AT_CHECKCODE: got %x, want %x", atCheckcode.Checkcode, want)
+	}
+	if _, ok := idUpdates["checkcode"].(string); !ok {
+		t.Error("Challenge応答検証用のcheckcodeが保存されていない")
+	}
+}
+
+func TestEngine_Checkcode_Mismatch_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	// サーバー側で保存したAKA-Identityメッセージのハッシュ
+	cc := eap.NewCheckcode(eapaka.TypeAKA)
+	cc.Write(buildAKAIdentityResponse(2, eapaka.TypeAKA, "0"+testIMSI+"@realm"))
+	state, err := cc.State()
+	if err != nil {
+		t.Fatalf("State() error: %v", err)
+	}
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.Checkcode = state
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	// ピアが観測したAKA-Identity交換が改ざんされていた（ハッシュ不一致）
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseWithCheckcode(3, keys.K_aut, testXRES, make([]byte, 20)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_Checkcode_InvalidState_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.Checkcode = "not-hex"
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(3, eapaka.TypeAKA, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
@@ -0,0 +1,25 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+// lookupClient は送信元IPのRADIUSクライアント設定を取得する（未登録の場合はnil）
+// 取得できない場合はAUTH_CLIENT_LOOKUP_ERRを出力してokにfalseを返す。継続・拒否は呼び出し元が判断する
+func (e *EngineImpl) lookupClient(ctx context.Context, req *eap.Request, traceID string) (client *store.RadiusClient, ok bool) {
+	client, err := e.clientStore.GetClient(ctx, req.SrcIP)
+	if err != nil {
+		slog.Warn("RADIUSクライアント取得失敗",
+			"event_id", "AUTH_CLIENT_LOOKUP_ERR",
+			"trace_id", traceID,
+			"src_ip", req.SrcIP,
+			"error", err,
+		)
+		return nil, false
+	}
+	return client, true
+}
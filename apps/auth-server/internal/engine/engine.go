@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"time"
@@ -11,10 +12,13 @@ import (
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/aka"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/akaprime"
-	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/logging"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/logging"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/model"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/suci"
 	eapaka "github.com/oyaguma3/go-eapaka"
 )
 
@@ -23,12 +27,22 @@ type EngineImpl struct {
 	vectorClient vector.VectorClient
 	ctxStore     session.ContextStore
 	sessStore    session.SessionStore
+	pseudoStore  session.PseudonymStore
+	reauthStore  session.ReauthStore
+	erpStore     session.ERPStore
+	vectorCache  session.VectorCache
+	lockoutStore session.LockoutStore
+	rateLimiter  session.RateLimiter
 	policyStore  policy.PolicyStore
 	evaluator    policy.Evaluator
+	clientStore  store.ClientStore
+	subStore     store.SubscriberStore
+	keyring      *suci.Keyring
 	cfg          *config.Config
 }
 
 // NewEngine は新しいEAPエンジンを生成する
+// 任意機能はoptsで有効化する（指定しない機能は無効）
 func NewEngine(
 	vc vector.VectorClient,
 	cs session.ContextStore,
@@ -36,8 +50,9 @@ func NewEngine(
 	ps policy.PolicyStore,
 	ev policy.Evaluator,
 	cfg *config.Config,
+	opts ...Option,
 ) *EngineImpl {
-	return &EngineImpl{
+	e := &EngineImpl{
 		vectorClient: vc,
 		ctxStore:     cs,
 		sessStore:    ss,
@@ -45,10 +60,69 @@ func NewEngine(
 		evaluator:    ev,
 		cfg:          cfg,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Option はEAPエンジンの任意機能を設定する
+type Option func(*EngineImpl)
+
+// WithPseudonymStore は仮名の発行・解決を有効化する
+func WithPseudonymStore(s session.PseudonymStore) Option {
+	return func(e *EngineImpl) { e.pseudoStore = s }
+}
+
+// WithReauthStore は高速再認証を有効化する
+func WithReauthStore(s session.ReauthStore) Option {
+	return func(e *EngineImpl) { e.reauthStore = s }
+}
+
+// WithERPStore はERPを有効化する
+func WithERPStore(s session.ERPStore) Option {
+	return func(e *EngineImpl) { e.erpStore = s }
+}
+
+// WithVectorCache は認証ベクターの先行取得を有効化する
+func WithVectorCache(s session.VectorCache) Option {
+	return func(e *EngineImpl) { e.vectorCache = s }
+}
+
+// WithLockoutStore は認証失敗によるIMSI単位のロックアウトを有効化する
+func WithLockoutStore(s session.LockoutStore) Option {
+	return func(e *EngineImpl) { e.lockoutStore = s }
+}
+
+// WithRateLimiter はIMSI単位のベクター要求レート制限を有効化する
+func WithRateLimiter(r session.RateLimiter) Option {
+	return func(e *EngineImpl) { e.rateLimiter = r }
+}
+
+// WithClientStore はRADIUSクライアント単位の設定（AKA'必須判定・ネットワーク名・匿名Identityの扱い）を有効化する
+func WithClientStore(s store.ClientStore) Option {
+	return func(e *EngineImpl) { e.clientStore = s }
+}
+
+// WithSubscriberStore は認証成功後の加入者の利用状態確認を有効化する
+// 指定しない場合はVector APIでの確認のみ行う
+func WithSubscriberStore(s store.SubscriberStore) Option {
+	return func(e *EngineImpl) { e.subStore = s }
+}
+
+// WithKeyring はECIES方式の秘匿化ID（SUCI）の秘匿解除を有効化する
+// 指定しない場合はNull-schemeのみ受け付ける
+func WithKeyring(kr *suci.Keyring) Option {
+	return func(e *EngineImpl) { e.keyring = kr }
 }
 
 // Process はEAP認証リクエストを処理する
 func (e *EngineImpl) Process(ctx context.Context, req *eap.Request) (*eap.Result, error) {
+	// EAP-Initiate（ERP）→ EAPコンテキストを持たない1往復の再認証
+	if eap.IsERPInitiate(req.EAPMessage) {
+		return e.handleERPReauth(ctx, req)
+	}
+
 	if len(req.State) == 0 {
 		// State無し → 初回Identity処理
 		return e.handleIdentity(ctx, req)
@@ -66,6 +140,11 @@ func (e *EngineImpl) Process(ctx context.Context, req *eap.Request) (*eap.Result
 		return e.buildReject(0), nil
 	}
 
+	// 直前に送信したRequestと異なるIdentifierの応答は再送・遅延パケットとして破棄
+	if e.isStaleResponse(traceID, eapCtx, req.EAPMessage) {
+		return &eap.Result{Action: eap.ActionDrop}, nil
+	}
+
 	return e.handleSubsequent(ctx, req, traceID, eapCtx)
 }
 
@@ -111,20 +190,28 @@ func (e *EngineImpl) handleIdentity(ctx context.Context, req *eap.Request) (*eap
 		}
 	}
 
+	// EAP層のIdentityを正とする（User-Nameは含まれない場合のみ使用）
+	rawIdentity := e.peerIdentity(req, pkt, eapType)
+
+	// Identity未設定・匿名・3GPP形式でないNAI → AKA-Identity Requestで改めてIdentityを要求
+	if eap.IsAnonymousIdentity(rawIdentity) || eap.IsUnrecognizedNAI(rawIdentity) {
+		return e.handleAnonymousIdentity(ctx, req, pkt, eapType, rawIdentity)
+	}
+
 	// Identity解析
-	identity, err := eap.ParseIdentity(req.UserName)
+	identity, err := eap.ParseIdentity(rawIdentity)
 	if err != nil {
 		if errors.Is(err, eap.ErrUnsupportedIdentity) {
 			slog.Warn("非対応のIdentity種別",
 				"event_id", "EAP_UNSUPPORTED_TYPE",
 				"trace_id", req.TraceID,
-				"user_name", req.UserName,
+				"user_name", rawIdentity,
 			)
 		} else {
 			slog.Warn("Identity解析失敗",
 				"event_id", "EAP_IDENTITY_INVALID",
 				"trace_id", req.TraceID,
-				"user_name", req.UserName,
+				"user_name", rawIdentity,
 				"error", err,
 			)
 		}
@@ -135,14 +222,36 @@ func (e *EngineImpl) handleIdentity(ctx context.Context, req *eap.Request) (*eap
 		}, nil
 	}
 
-	// フル認証誘導（仮名/再認証ID）
+	// 秘匿化ID（SUCI）→ 復号したIMSIの永続IDとして扱う
+	if identity.IsConcealed() && !e.deconcealIdentity(req.TraceID, identity, eapType) {
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// EAP-SIM（RFC 4186）
+	if identity.IsSIM() {
+		return e.handleSIMIdentity(ctx, req, pkt, identity)
+	}
+
+	// 仮名解決（解決できた場合は永続IDと同様にフル認証を開始）
+	if identity.IsPseudonym() && e.resolvePseudonym(ctx, req.TraceID, identity) {
+		return e.handlePermanentIdentity(ctx, req, req.TraceID, pkt, identity)
+	}
+
+	// 高速再認証（既知の再認証ID）
+	if identity.IsReauth() {
+		if rc := e.lookupReauthContext(ctx, req.TraceID, identity); rc != nil {
+			return e.handleReauthIdentity(ctx, req, req.TraceID, pkt, identity, rc, eap.StateNew)
+		}
+	}
+
+	// フル認証誘導（未知の仮名/再認証ID）
 	if identity.RequiresFullAuth() {
-		return e.handleFullAuthRedirect(ctx, req, pkt, identity)
+		return e.handleFullAuthRedirect(ctx, req, pkt, identity.EAPType, identityRequestFor(identity))
 	}
 
 	// 永続ID処理
 	if identity.IsPermanent() {
-		return e.handlePermanentIdentity(ctx, req, pkt, identity)
+		return e.handlePermanentIdentity(ctx, req, req.TraceID, pkt, identity)
 	}
 
 	// ここには到達しないはず
@@ -150,12 +259,12 @@ func (e *EngineImpl) handleIdentity(ctx context.Context, req *eap.Request) (*eap
 }
 
 // handleFullAuthRedirect はフル認証への誘導処理を行う
-func (e *EngineImpl) handleFullAuthRedirect(ctx context.Context, req *eap.Request, pkt *eapaka.Packet, identity *eap.ParsedIdentity) (*eap.Result, error) {
+// EAPコンテキストを作成し、wantで指定した種別のAKA-Identity Requestを送信する
+func (e *EngineImpl) handleFullAuthRedirect(ctx context.Context, req *eap.Request, pkt *eapaka.Packet, eapType uint8, want eap.IdentityReqType) (*eap.Result, error) {
 	// EAPContext作成
 	eapCtx := &session.EAPContext{
-		Stage:                string(eap.StateNew),
-		PermanentIDRequested: true,
-		EAPType:              identity.EAPType,
+		Stage:   string(eap.StateNew),
+		EAPType: eapType,
 	}
 	if err := e.ctxStore.Create(ctx, req.TraceID, eapCtx); err != nil {
 		slog.Error("EAPコンテキスト作成失敗",
@@ -166,38 +275,192 @@ func (e *EngineImpl) handleFullAuthRedirect(ctx context.Context, req *eap.Reques
 		return e.buildReject(pkt.Identifier + 1), nil
 	}
 
+	return e.sendIdentityRequest(ctx, req.TraceID, pkt.Identifier, eapType, 0, want, nil)
+}
+
+// identityRequestFor は未知の仮名/再認証IDに対して送信するAKA-Identity要求種別を返す
+// 再認証IDは仮名での応答を許容するAT_FULLAUTH_ID_REQ、仮名はAT_PERMANENT_ID_REQとする
+func identityRequestFor(identity *eap.ParsedIdentity) eap.IdentityReqType {
+	if identity.IsReauth() {
+		return eap.IdentityReqFullAuth
+	}
+	return eap.IdentityReqPermanent
+}
+
+// sendIdentityRequest は送信済み要求sentを踏まえてAKA-Identity Requestを構築・送信する
+// RFC 4187の制約上これ以上要求できない場合は認証失敗とする
+// ccはこれまでのAKA-Identityメッセージのハッシュ（nilの場合は新規に開始する）
+func (e *EngineImpl) sendIdentityRequest(ctx context.Context, traceID string, identifier uint8, eapType uint8, sent, want eap.IdentityReqType, cc *eap.Checkcode) (*eap.Result, error) {
+	next, ok := eap.NextIdentityRequest(sent, want)
+	if !ok {
+		slog.Warn("AKA-Identity要求回数上限",
+			"event_id", "EAP_IDENTITY_REQ_LIMIT",
+			"trace_id", traceID,
+			"last_id_req", sent.Last().String(),
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		eapFailure, _ := eap.BuildEAPFailure(identifier + 1)
+		return &eap.Result{
+			Action:     eap.ActionReject,
+			EAPMessage: eapFailure,
+		}, nil
+	}
+
 	// AKA-Identity Request構築
-	identityReq, err := eap.BuildAKAIdentityRequest(pkt.Identifier+1, identity.EAPType)
+	identityReq, err := eap.BuildAKAIdentityRequest(identifier+1, eapType, next)
 	if err != nil {
 		slog.Error("Identity Request構築失敗",
 			"event_id", "EAP_BUILD_ERR",
-			"trace_id", req.TraceID,
+			"trace_id", traceID,
 			"error", err,
 		)
-		return e.buildReject(pkt.Identifier + 1), nil
+		return e.buildReject(identifier + 1), nil
+	}
+
+	// AT_CHECKCODE計算用に送信するAKA-Identity Requestをハッシュへ追加
+	if cc == nil {
+		cc = eap.NewCheckcode(eapType)
+	}
+	cc.Write(identityReq)
+	checkcode, err := cc.State()
+	if err != nil {
+		slog.Error("AT_CHECKCODE状態保存失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(identifier + 1), nil
 	}
 
-	// Stage更新: WAITING_IDENTITY
-	if err := e.ctxStore.Update(ctx, req.TraceID, map[string]any{
-		"stage": string(eap.StateWaitingIdentity),
+	// Stage更新: WAITING_IDENTITY（送信済み要求・AKA-Identityメッセージのハッシュを記録）
+	if err := e.ctxStore.Update(ctx, traceID, map[string]any{
+		"stage":             string(eap.StateWaitingIdentity),
+		"identity_req_sent": uint8(sent | next),
+		"checkcode":         checkcode,
+		"eap_identifier":    identifierField(identifier + 1),
 	}); err != nil {
 		slog.Error("EAPコンテキスト更新失敗",
 			"event_id", "EAP_CTX_UPDATE_ERR",
-			"trace_id", req.TraceID,
+			"trace_id", traceID,
 			"error", err,
 		)
-		return e.buildReject(pkt.Identifier + 1), nil
+		return e.buildReject(identifier + 1), nil
 	}
 
+	slog.Info("AKA-Identity Request送信",
+		"event_id", "EAP_IDENTITY_REQ_SENT",
+		"trace_id", traceID,
+		"id_req", next.String(),
+		"eap_type", eapType,
+	)
+
 	return &eap.Result{
 		Action:     eap.ActionChallenge,
 		EAPMessage: identityReq,
-		State:      []byte(req.TraceID),
+		State:      []byte(traceID),
 	}, nil
 }
 
-// handlePermanentIdentity は永続ID受信時の処理を行う
-func (e *EngineImpl) handlePermanentIdentity(ctx context.Context, req *eap.Request, pkt *eapaka.Packet, identity *eap.ParsedIdentity) (*eap.Result, error) {
+// resolvePseudonym は仮名からIMSIを解決し、成功時はidentity.IMSIに設定する
+// 未登録・期限切れ・ストア障害の場合はfalseを返し、永続ID要求へフォールバックさせる
+func (e *EngineImpl) resolvePseudonym(ctx context.Context, traceID string, identity *eap.ParsedIdentity) bool {
+	if e.pseudoStore == nil {
+		return false
+	}
+
+	entry, err := e.pseudoStore.Get(ctx, identity.UserPart)
+	if err != nil {
+		if errors.Is(err, session.ErrPseudonymNotFound) {
+			slog.Info("未知の仮名",
+				"event_id", "EAP_PSEUDONYM_UNKNOWN",
+				"trace_id", traceID,
+			)
+		} else {
+			slog.Warn("仮名解決失敗",
+				"event_id", "EAP_PSEUDONYM_LOOKUP_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+		}
+		return false
+	}
+
+	// 発行時と異なる方式の仮名は受け付けない
+	if entry.EAPType != identity.EAPType {
+		slog.Warn("仮名のEAP方式不一致",
+			"event_id", "EAP_PSEUDONYM_UNKNOWN",
+			"trace_id", traceID,
+			"eap_type", identity.EAPType,
+		)
+		return false
+	}
+
+	identity.IMSI = entry.IMSI
+	slog.Info("仮名解決成功",
+		"event_id", "EAP_PSEUDONYM_RESOLVED",
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(entry.IMSI),
+	)
+	return true
+}
+
+// generatePseudonym は次回用の仮名を生成する
+// ValkeyへはChallengeの成功時に登録する（savePseudonym）。無効時・失敗時は空文字列を返す
+func (e *EngineImpl) generatePseudonym(traceID string, eapType uint8) string {
+	if e.pseudoStore == nil {
+		return ""
+	}
+
+	prefix := rune(eap.IdentityPrefixAKAPseudonym)
+	if eapType == eapaka.TypeAKAPrime {
+		prefix = eap.IdentityPrefixAKAPrimePseudonym
+	}
+	pseudonym, err := session.GeneratePseudonym(prefix)
+	if err != nil {
+		slog.Warn("仮名生成失敗",
+			"event_id", "EAP_PSEUDONYM_ISSUE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return ""
+	}
+	return pseudonym
+}
+
+// savePseudonym はフル認証の成功時に、AT_NEXT_PSEUDONYMで通知した仮名を登録し、今回の認証で使用された仮名を削除する
+// 登録に失敗しても認証結果には影響させない（次回は未知の仮名として永続IDを要求する）
+func (e *EngineImpl) savePseudonym(ctx context.Context, traceID string, eapCtx *session.EAPContext) {
+	if e.pseudoStore == nil || eapCtx.NextPseudonym == "" {
+		return
+	}
+
+	if err := e.pseudoStore.Create(ctx, eapCtx.NextPseudonym, &session.PseudonymEntry{
+		IMSI:      eapCtx.IMSI,
+		EAPType:   eapCtx.EAPType,
+		CreatedAt: time.Now().Unix(),
+	}); err != nil {
+		slog.Warn("仮名登録失敗",
+			"event_id", "EAP_PSEUDONYM_ISSUE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return
+	}
+
+	// ピアは新しい仮名を使用するため、使用済みの仮名は無効化する（登録失敗時は使用済みの仮名を残す）
+	if eapCtx.Pseudonym != "" {
+		if err := e.pseudoStore.Delete(ctx, eapCtx.Pseudonym); err != nil {
+			slog.Warn("使用済み仮名の削除失敗",
+				"event_id", "EAP_PSEUDONYM_ISSUE_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+		}
+	}
+}
+
+// handlePermanentIdentity は永続ID（または解決済みの仮名）受信時の処理を行う
+func (e *EngineImpl) handlePermanentIdentity(ctx context.Context, req *eap.Request, traceID string, pkt *eapaka.Packet, identity *eap.ParsedIdentity) (*eap.Result, error) {
 	maskedIMSI := e.maskIMSI(identity.IMSI)
 
 	// EAPContext作成
@@ -206,10 +469,10 @@ func (e *EngineImpl) handlePermanentIdentity(ctx context.Context, req *eap.Reque
 		Stage:   string(eap.StateIdentityReceived),
 		EAPType: identity.EAPType,
 	}
-	if err := e.ctxStore.Create(ctx, req.TraceID, eapCtx); err != nil {
+	if err := e.ctxStore.Create(ctx, traceID, eapCtx); err != nil {
 		slog.Error("EAPコンテキスト作成失敗",
 			"event_id", "EAP_CTX_CREATE_ERR",
-			"trace_id", req.TraceID,
+			"trace_id", traceID,
 			"imsi", maskedIMSI,
 			"error", err,
 		)
@@ -221,31 +484,58 @@ func (e *EngineImpl) handlePermanentIdentity(ctx context.Context, req *eap.Reque
 	if err != nil {
 		slog.Error("状態遷移失敗",
 			"event_id", "EAP_STATE_ERR",
-			"trace_id", req.TraceID,
+			"trace_id", traceID,
 			"error", err,
 		)
 		return e.buildReject(pkt.Identifier + 1), nil
 	}
 
 	// Vector Gateway呼び出し + Challenge構築
-	return e.requestVectorAndBuildChallenge(ctx, req, pkt.Identifier, identity, req.TraceID)
+	return e.requestVectorAndBuildChallenge(ctx, req, pkt.Identifier, identity, traceID, nil)
 }
 
 // requestVectorAndBuildChallenge はVector取得→鍵導出→Challenge構築を行う
+// checkcodeはAT_CHECKCODEの値（AKA-Identity交換を行っていない場合はnilとし、送信しない）
 func (e *EngineImpl) requestVectorAndBuildChallenge(
 	ctx context.Context,
 	req *eap.Request,
 	identifier uint8,
 	identity *eap.ParsedIdentity,
 	traceID string,
+	checkcode []byte,
 ) (*eap.Result, error) {
 	maskedIMSI := e.maskIMSI(identity.IMSI)
 
-	// Vector Gateway呼び出し
-	vCtx := vector.WithTraceID(ctx, traceID)
-	vecResp, err := e.vectorClient.GetVector(vCtx, &vector.VectorRequest{
-		IMSI: identity.IMSI,
-	})
+	// 認証失敗の繰り返しによりロックアウト中のIMSIはベクターを要求しない（SQNの浪費を防ぐ）
+	if e.imsiLocked(ctx, traceID, identity.IMSI) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(identifier + 1), nil
+	}
+
+	// IMSI単位の要求レート超過 → 応答なし（ピアは認証をやり直す）
+	if e.imsiRateLimited(ctx, req, traceID, identity.IMSI) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return &eap.Result{Action: eap.ActionDrop}, nil
+	}
+
+	// AKA'必須のクライアントではEAP-AKAによるフル認証を行わない
+	if e.clientRequiresAKAPrime(ctx, req, traceID, identity.IMSI, identity.EAPType) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(identifier + 1), nil
+	}
+
+	// AKA'のAT_KDF_INPUTのネットワーク名
+	var networkName string
+	if identity.IsAKAPrime() {
+		var ok bool
+		if networkName, ok = e.resolveNetworkName(ctx, req, traceID, identity.Realm); !ok {
+			_ = e.ctxStore.Delete(ctx, traceID)
+			return e.buildReject(identifier + 1), nil
+		}
+	}
+
+	// 認証ベクター取得（先行取得したベクターがあれば使用する）
+	vecResp, err := e.acquireVector(ctx, traceID, identity.IMSI, nil)
 	if err != nil {
 		e.logVectorError(err, traceID, maskedIMSI)
 		eapFailure, _ := eap.BuildEAPFailure(identifier + 1)
@@ -255,38 +545,22 @@ func (e *EngineImpl) requestVectorAndBuildChallenge(
 		}, nil
 	}
 
-	// 鍵導出
-	var kAut, msk []byte
-	if identity.IsAKAPrime() {
-		keys, err := akaprime.DeriveAllKeys(identity.Raw, vecResp.CK, vecResp.IK, vecResp.AUTN, e.cfg.NetworkName)
-		if err != nil {
-			slog.Error("AKA'鍵導出失敗",
-				"event_id", "EAP_KEY_DERIVE_ERR",
-				"trace_id", traceID,
-				"imsi", maskedIMSI,
-				"error", err,
-			)
-			return e.buildReject(identifier + 1), nil
-		}
-		kAut = keys.K_aut
-		msk = keys.MSK
-	} else {
-		keys := aka.DeriveKeys(identity.Raw, vecResp.CK, vecResp.IK)
-		kAut = keys.K_aut
-		msk = keys.MSK
+	// 鍵導出・次回用IDの生成
+	kAut, opts, updates, ok := e.prepareChallenge(ctx, traceID, identity, vecResp, networkName, checkcode, identifier)
+	if !ok {
+		return e.buildReject(identifier + 1), nil
 	}
 
-	// EAPContext更新
-	updates := map[string]any{
-		"stage":    string(eap.StateChallengeSent),
-		"rand":     hex.EncodeToString(vecResp.RAND),
-		"autn":     hex.EncodeToString(vecResp.AUTN),
-		"xres":     hex.EncodeToString(vecResp.XRES),
-		"k_aut":    hex.EncodeToString(kAut),
-		"msk":      hex.EncodeToString(msk),
-		"imsi":     identity.IMSI,
-		"eap_type": identity.EAPType,
+	// EAPContext更新（フル認証固有の項目を追加）
+	updates["imsi"] = identity.IMSI
+	updates["eap_type"] = identity.EAPType
+	updates["reauth_id"] = "" // 高速再認証から移行した場合の使用済み再認証IDを無効化
+	updates["pseudonym"] = ""
+	if identity.IsPseudonym() {
+		updates["pseudonym"] = identity.UserPart // 成功時に無効化する使用済みの仮名
 	}
+	updates["identity"] = identity.Raw
+	updates["network_name"] = networkName
 	if err := e.ctxStore.Update(ctx, traceID, updates); err != nil {
 		slog.Error("EAPコンテキスト更新失敗",
 			"event_id", "EAP_CTX_UPDATE_ERR",
@@ -297,12 +571,7 @@ func (e *EngineImpl) requestVectorAndBuildChallenge(
 	}
 
 	// Challenge構築
-	var challengeMsg []byte
-	if identity.IsAKAPrime() {
-		challengeMsg, err = akaprime.BuildChallenge(identifier+1, vecResp.RAND, vecResp.AUTN, e.cfg.NetworkName, kAut)
-	} else {
-		challengeMsg, err = aka.BuildChallenge(identifier+1, vecResp.RAND, vecResp.AUTN, kAut)
-	}
+	challengeMsg, err := buildChallengeMessage(identifier+1, identity, vecResp, networkName, kAut, opts)
 	if err != nil {
 		slog.Error("Challenge構築失敗",
 			"event_id", "EAP_BUILD_ERR",
@@ -327,6 +596,82 @@ func (e *EngineImpl) requestVectorAndBuildChallenge(
 	}, nil
 }
 
+// prepareChallenge はベクターから鍵を導出し、Challengeの追加属性とEAPコンテキストの更新内容を構築する
+// フル認証と再同期で共通に使用する（導出した鍵・次回用IDの保存先はここで一元管理する）
+// identifierは受信したEAPパケットのIdentifier。AKA'の鍵導出に失敗した場合はokにfalseを返す
+func (e *EngineImpl) prepareChallenge(
+	ctx context.Context,
+	traceID string,
+	identity *eap.ParsedIdentity,
+	vecResp *vector.VectorResponse,
+	networkName string,
+	checkcode []byte,
+	identifier uint8,
+) (kAut []byte, opts *eap.ChallengeOptions, updates map[string]any, ok bool) {
+	// 鍵導出（AKA'はサポート対象のKDF=1（CK'/IK'導出）のみ）
+	var kEncr, msk, emsk, reauthKey []byte
+	if identity.IsAKAPrime() {
+		keys, err := akaprime.DeriveAllKeys(identity.Raw, vecResp.CK, vecResp.IK, vecResp.AUTN, networkName)
+		if err != nil {
+			slog.Error("AKA'鍵導出失敗",
+				"event_id", "EAP_KEY_DERIVE_ERR",
+				"trace_id", traceID,
+				"imsi", e.maskIMSI(identity.IMSI),
+				"error", err,
+			)
+			return nil, nil, nil, false
+		}
+		kEncr = keys.K_encr
+		kAut = keys.K_aut
+		msk = keys.MSK
+		emsk = keys.EMSK
+		reauthKey = keys.K_re
+	} else {
+		keys := aka.DeriveKeys(identity.Raw, vecResp.CK, vecResp.IK)
+		kEncr = keys.K_encr
+		kAut = keys.K_aut
+		msk = keys.MSK
+		emsk = keys.EMSK
+		reauthKey = keys.MK
+	}
+
+	// 次回用の仮名・再認証ID（AT_ENCR_DATAで暗号化して付与）
+	opts = &eap.ChallengeOptions{
+		KEncr:         kEncr,
+		NextPseudonym: e.generatePseudonym(traceID, identity.EAPType),
+		NextReauthID:  e.generateReauthID(traceID, identity.EAPType),
+		ResultInd:     e.cfg.ResultIndEnabled,
+		Checkcode:     checkcode,
+		Bidding:       identity.EAPType == eapaka.TypeAKA && e.cfg.BiddingEnabled,
+	}
+
+	updates = map[string]any{
+		"stage":          string(eap.StateChallengeSent),
+		"rand":           hex.EncodeToString(vecResp.RAND),
+		"autn":           hex.EncodeToString(vecResp.AUTN),
+		"xres":           hex.EncodeToString(vecResp.XRES),
+		"k_aut":          hex.EncodeToString(kAut),
+		"msk":            hex.EncodeToString(msk),
+		"k_encr":         hex.EncodeToString(kEncr),
+		"reauth_key":     hex.EncodeToString(reauthKey),
+		"next_reauth_id": opts.NextReauthID,
+		"next_pseudonym": opts.NextPseudonym,
+		"eap_identifier": identifierField(identifier + 1),
+	}
+	if e.erpEnabled() {
+		updates["emsk"] = hex.EncodeToString(emsk)
+	}
+	return kAut, opts, updates, true
+}
+
+// buildChallengeMessage はEAP方式に応じたEAP-Request/AKA(')-Challengeを構築する
+func buildChallengeMessage(identifier uint8, identity *eap.ParsedIdentity, vecResp *vector.VectorResponse, networkName string, kAut []byte, opts *eap.ChallengeOptions) ([]byte, error) {
+	if identity.IsAKAPrime() {
+		return akaprime.BuildChallenge(identifier, vecResp.RAND, vecResp.AUTN, networkName, kAut, opts)
+	}
+	return aka.BuildChallenge(identifier, vecResp.RAND, vecResp.AUTN, kAut, opts)
+}
+
 // handleSubsequent はState有りの後続リクエストを処理する
 func (e *EngineImpl) handleSubsequent(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext) (*eap.Result, error) {
 	// EAPパケットパース
@@ -340,6 +685,21 @@ func (e *EngineImpl) handleSubsequent(ctx context.Context, req *eap.Request, tra
 		return e.buildReject(0), nil
 	}
 
+	// EAP-SIMはSubtype体系が異なるため別系統で処理する（方式の混在は拒否）
+	if pkt.Type == eap.EAPTypeSIM || eapCtx.EAPType == eap.EAPTypeSIM {
+		if pkt.Type != eapCtx.EAPType {
+			slog.Warn("EAP Type不一致",
+				"event_id", "EAP_TYPE_MISMATCH",
+				"trace_id", traceID,
+				"eap_type", pkt.Type,
+				"expected", eapCtx.EAPType,
+			)
+			_ = e.ctxStore.Delete(ctx, traceID)
+			return e.buildReject(pkt.Identifier + 1), nil
+		}
+		return e.handleSIMSubsequent(ctx, req, traceID, eapCtx, pkt)
+	}
+
 	// Subtype分岐
 	switch pkt.Subtype {
 	case eapaka.SubtypeChallenge:
@@ -348,6 +708,12 @@ func (e *EngineImpl) handleSubsequent(ctx context.Context, req *eap.Request, tra
 	case eapaka.SubtypeSynchronizationFailure:
 		return e.handleResync(ctx, req, traceID, eapCtx, pkt)
 
+	case eapaka.SubtypeReauthentication:
+		return e.handleReauthResponse(ctx, req, traceID, eapCtx, pkt)
+
+	case eapaka.SubtypeNotification:
+		return e.handleNotificationResponse(ctx, req, traceID, eapCtx, pkt)
+
 	case eapaka.SubtypeAuthenticationReject:
 		slog.Warn("Authentication-Reject受信",
 			"event_id", "EAP_AUTH_REJECT",
@@ -389,20 +755,52 @@ func (e *EngineImpl) handleSubsequent(ctx context.Context, req *eap.Request, tra
 }
 
 // handleIdentityResponse はWAITING_IDENTITY状態でIdentity応答を処理する
+// 未知の仮名/再認証ID・匿名IDの場合はRFC 4187の範囲でより強いAKA-Identity要求を再送する
 func (e *EngineImpl) handleIdentityResponse(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, pkt *eapaka.Packet) (*eap.Result, error) {
-	identity, err := eap.ParseIdentity(req.UserName)
+	sent := eap.IdentityReqType(eapCtx.IdentityReqSent)
+
+	// AKA-Identity要求への応答はAT_IDENTITY必須（RFC 4187 Section 9.3）
+	// User-Nameは外側の匿名ID等のため鍵導出には使用しない
+	atIdentity, found := eap.GetAttribute[*eapaka.AtIdentity](pkt)
+	if !found {
+		slog.Warn("AKA-Identity応答にAT_IDENTITYなし",
+			"event_id", "EAP_IDENTITY_INVALID",
+			"trace_id", traceID,
+			"id_req", sent.Last().String(),
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+	rawIdentity := atIdentity.Identity
+
+	// AT_CHECKCODE計算用に受信したAKA-Identity応答をハッシュへ追加
+	cc, ok := e.restoreCheckcode(traceID, eapCtx)
+	if !ok {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+	if cc != nil {
+		cc.Write(req.EAPMessage)
+	}
+
+	// 匿名ID・3GPP形式でないNAI → 次のAKA-Identity要求
+	if eap.IsAnonymousIdentity(rawIdentity) || eap.IsUnrecognizedNAI(rawIdentity) {
+		return e.sendIdentityRequest(ctx, traceID, pkt.Identifier, eapCtx.EAPType, sent, eap.IdentityReqAny, cc)
+	}
+
+	identity, err := eap.ParseIdentity(rawIdentity)
 	if err != nil {
 		if errors.Is(err, eap.ErrUnsupportedIdentity) {
 			slog.Warn("非対応のIdentity種別",
 				"event_id", "EAP_UNSUPPORTED_TYPE",
 				"trace_id", traceID,
-				"user_name", req.UserName,
+				"user_name", rawIdentity,
 			)
 		} else {
 			slog.Warn("Identity解析失敗",
 				"event_id", "EAP_IDENTITY_INVALID",
 				"trace_id", traceID,
-				"user_name", req.UserName,
+				"user_name", rawIdentity,
 				"error", err,
 			)
 		}
@@ -414,11 +812,19 @@ func (e *EngineImpl) handleIdentityResponse(ctx context.Context, req *eap.Reques
 		}, nil
 	}
 
-	if !identity.IsPermanent() {
-		slog.Warn("永続ID応答が仮名/再認証ID",
+	// 秘匿化ID（SUCI）→ 復号したIMSIの永続IDとして扱う
+	if identity.IsConcealed() && !e.deconcealIdentity(traceID, identity, eapCtx.EAPType) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 送信した要求に反する応答（AT_PERMANENT_ID_REQに対する仮名等）およびEAP-SIMのIDは拒否
+	if identity.IsSIM() || !sent.Accepts(identity) {
+		slog.Warn("要求と異なる種別のIdentity応答",
 			"event_id", "EAP_IDENTITY_INVALID",
 			"trace_id", traceID,
-			"user_name", req.UserName,
+			"id_req", sent.Last().String(),
+			"user_name", rawIdentity,
 		)
 		_ = e.ctxStore.Delete(ctx, traceID)
 		eapFailure, _ := eap.BuildEAPFailure(pkt.Identifier + 1)
@@ -428,6 +834,32 @@ func (e *EngineImpl) handleIdentityResponse(ctx context.Context, req *eap.Reques
 		}, nil
 	}
 
+	// 高速再認証（AT_ANY_ID_REQに対する既知の再認証ID）
+	if identity.IsReauth() {
+		if rc := e.lookupReauthContext(ctx, traceID, identity); rc != nil {
+			return e.handleReauthIdentity(ctx, req, traceID, pkt, identity, rc, eap.StateWaitingIdentity)
+		}
+	}
+
+	// 仮名解決（解決できた場合は永続IDと同様にフル認証を開始）
+	resolved := identity.IsPermanent()
+	if identity.IsPseudonym() {
+		resolved = e.resolvePseudonym(ctx, traceID, identity)
+	}
+
+	// 未知の仮名/再認証ID → 次のAKA-Identity要求
+	if !resolved {
+		if _, err := eap.ValidateTransition(eap.StateWaitingIdentity, eap.EventPseudonymIdentity); err != nil {
+			slog.Error("状態遷移失敗",
+				"event_id", "EAP_STATE_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+			return e.buildReject(pkt.Identifier + 1), nil
+		}
+		return e.sendIdentityRequest(ctx, traceID, pkt.Identifier, identity.EAPType, sent, identityRequestFor(identity), cc)
+	}
+
 	// 状態遷移: WAITING_IDENTITY → IDENTITY_RECEIVED
 	_, err = eap.ValidateTransition(eap.StateWaitingIdentity, eap.EventPermanentIdentity)
 	if err != nil {
@@ -439,22 +871,31 @@ func (e *EngineImpl) handleIdentityResponse(ctx context.Context, req *eap.Reques
 		return e.buildReject(pkt.Identifier + 1), nil
 	}
 
-	// Context更新
-	if err := e.ctxStore.Update(ctx, traceID, map[string]any{
+	// Context更新（Challenge応答のAT_CHECKCODE検証用にAKA-Identityメッセージのハッシュを保存）
+	// 並行して同じ応答を処理している場合は1つだけがVector取得へ進む
+	updates := map[string]any{
 		"imsi":     identity.IMSI,
 		"stage":    string(eap.StateIdentityReceived),
 		"eap_type": identity.EAPType,
-	}); err != nil {
-		slog.Error("EAPコンテキスト更新失敗",
-			"event_id", "EAP_CTX_UPDATE_ERR",
-			"trace_id", traceID,
-			"error", err,
-		)
-		return e.buildReject(pkt.Identifier + 1), nil
+	}
+	if cc != nil {
+		state, err := cc.State()
+		if err != nil {
+			slog.Error("AT_CHECKCODE状態保存失敗",
+				"event_id", "EAP_BUILD_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+			return e.buildReject(pkt.Identifier + 1), nil
+		}
+		updates["checkcode"] = state
+	}
+	if res := e.transitionContext(ctx, traceID, eapCtx, pkt.Identifier, updates); res != nil {
+		return res, nil
 	}
 
 	// Vector取得 + Challenge構築
-	return e.requestVectorAndBuildChallenge(ctx, req, pkt.Identifier, identity, traceID)
+	return e.requestVectorAndBuildChallenge(ctx, req, pkt.Identifier, identity, traceID, cc.Sum())
 }
 
 // handleChallengeResponse はChallenge応答を検証して認証結果を返す
@@ -471,6 +912,11 @@ func (e *EngineImpl) handleChallengeResponse(ctx context.Context, req *eap.Reque
 		return e.buildReject(pkt.Identifier + 1), nil
 	}
 
+	// AKA'のKDF選択応答（AT_KDFのみ）→ KDFネゴシエーション
+	if eapCtx.EAPType == eapaka.TypeAKAPrime && akaprime.IsKDFSelection(pkt) {
+		return e.handleKDFSelection(ctx, traceID, eapCtx, pkt)
+	}
+
 	// Valkey保存値の復元
 	kAut, err := hex.DecodeString(eapCtx.Kaut)
 	if err != nil {
@@ -499,13 +945,17 @@ func (e *EngineImpl) handleChallengeResponse(ctx context.Context, req *eap.Reque
 		)
 		return e.buildReject(pkt.Identifier + 1), nil
 	}
+	cc, ok := e.restoreCheckcode(traceID, eapCtx)
+	if !ok {
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
 
 	// Challenge応答検証（AKA/AKA'分岐）
 	var verifyErr error
 	if eapCtx.EAPType == eapaka.TypeAKAPrime {
-		verifyErr = akaprime.VerifyChallengeResponse(pkt, kAut, xres)
+		verifyErr = akaprime.VerifyChallengeResponse(pkt, kAut, xres, cc.Sum())
 	} else {
-		verifyErr = aka.VerifyChallengeResponse(pkt, kAut, xres)
+		verifyErr = aka.VerifyChallengeResponse(pkt, kAut, xres, cc.Sum())
 	}
 
 	if verifyErr != nil {
@@ -514,6 +964,8 @@ func (e *EngineImpl) handleChallengeResponse(ctx context.Context, req *eap.Reque
 			eventID = "AUTH_MAC_INVALID"
 		} else if errors.Is(verifyErr, eap.ErrRESMismatch) {
 			eventID = "AUTH_RES_MISMATCH"
+		} else if errors.Is(verifyErr, eap.ErrCheckcodeMismatch) {
+			eventID = "AUTH_CHECKCODE_MISMATCH"
 		} else {
 			eventID = "AUTH_VERIFY_FAIL"
 		}
@@ -523,6 +975,10 @@ func (e *EngineImpl) handleChallengeResponse(ctx context.Context, req *eap.Reque
 			"imsi", maskedIMSI,
 			"error", verifyErr,
 		)
+		switch eventID {
+		case "AUTH_MAC_INVALID", "AUTH_RES_MISMATCH":
+			e.recordAuthFailure(ctx, traceID, eapCtx.IMSI, eventID)
+		}
 		_ = e.ctxStore.Delete(ctx, traceID)
 		eapFailure, _ := eap.BuildEAPFailure(pkt.Identifier + 1)
 		return &eap.Result{
@@ -531,8 +987,77 @@ func (e *EngineImpl) handleChallengeResponse(ctx context.Context, req *eap.Reque
 		}, nil
 	}
 
-	// ポリシー取得
-	pol, err := e.policyStore.GetPolicy(ctx, eapCtx.IMSI)
+	resultInd := e.cfg.ResultIndEnabled && eap.HasResultInd(pkt)
+	return e.completeAuthentication(ctx, req, traceID, eapCtx, pkt.Identifier, mskBytes, resultInd), nil
+}
+
+// completeAuthentication は検証成功後のポリシー評価を行い、認証結果を返す
+// フル認証と高速再認証で共通に使用する
+// resultIndがtrue（AT_RESULT_INDを双方が提示）の場合は結果をAKA-Notificationで通知し、
+// Notification応答の受信後にAccept/Rejectを返す（RFC 4187 Section 6.2）
+func (e *EngineImpl) completeAuthentication(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, identifier uint8, msk []byte, resultInd bool) *eap.Result {
+	authz, denyCode, ok := e.authorize(ctx, req, traceID, eapCtx.IMSI, eapCtx.EAPType)
+	if !ok {
+		return e.rejectAfterAuthentication(ctx, traceID, eapCtx, identifier, denyCode, resultInd)
+	}
+
+	// 保護された成功通知（Notification応答の受信後にAccept）
+	if resultInd {
+		return e.sendNotification(ctx, traceID, eapCtx, identifier, eap.NotificationSuccess, map[string]any{
+			"vlan_id":          authz.vlanID,
+			"session_timeout":  authz.sessionTimeout,
+			"max_sessions":     authz.maxSessions,
+			"reply_attributes": encodeReplyAttributes(authz.replyAttributes),
+		})
+	}
+
+	return e.acceptAuthentication(ctx, req, traceID, eapCtx, identifier, msk, authz)
+}
+
+// authorization は認可結果（Acceptに適用する属性と同時セッション数の上限）を保持する
+type authorization struct {
+	vlanID          string
+	sessionTimeout  int
+	maxSessions     int // 0の場合は無制限
+	replyAttributes []policy.ReplyAttribute
+	policySource    string // ポリシーを解決した段階（policy.PolicySourceIMSI等）
+}
+
+// encodeReplyAttributes は成功通知応答まで持ち回る応答属性をEAPコンテキスト保存用のJSONに変換する
+// 応答属性がない場合は空文字を返す
+func encodeReplyAttributes(attrs []policy.ReplyAttribute) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(attrs)
+	return string(data)
+}
+
+// decodeReplyAttributes はEAPコンテキストに保存した応答属性を復元する
+func decodeReplyAttributes(s string) ([]policy.ReplyAttribute, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var attrs []policy.ReplyAttribute
+	if err := json.Unmarshal([]byte(s), &attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// authorize は認証成功後のポリシー取得・評価を行い、Accept時の認可結果を返す
+// 拒否する場合はokにfalseを、denyCodeに失敗通知の通知コードを返す（フル認証・高速再認証・ERPで共通）
+func (e *EngineImpl) authorize(ctx context.Context, req *eap.Request, traceID, imsi string, eapType uint8) (authz authorization, denyCode uint16, ok bool) {
+	maskedIMSI := e.maskIMSI(imsi)
+	denyCode = eap.NotificationGeneralFailureAfterAuth
+
+	// 利用状態の確認（キャッシュ済みベクター・高速再認証・ERPはVector APIを経由しないため）
+	if code, ok := e.checkSubscriberStatus(ctx, traceID, imsi); !ok {
+		return authz, code, false
+	}
+
+	// ポリシー取得（加入者ポリシーがない場合はNASクライアント・全体の既定ポリシーへフォールバック）
+	pol, err := e.policyStore.GetPolicy(ctx, imsi, req.SrcIP)
 	if err != nil {
 		slog.Warn("ポリシー取得失敗",
 			"event_id", "AUTH_POLICY_NOT_FOUND",
@@ -540,75 +1065,193 @@ func (e *EngineImpl) handleChallengeResponse(ctx context.Context, req *eap.Reque
 			"imsi", maskedIMSI,
 			"error", err,
 		)
-		_ = e.ctxStore.Delete(ctx, traceID)
-		eapFailure, _ := eap.BuildEAPFailure(pkt.Identifier + 1)
-		return &eap.Result{
-			Action:     eap.ActionReject,
-			EAPMessage: eapFailure,
-		}, nil
+		return authz, denyCode, false
+	}
+
+	// AKA'必須の加入者はEAP-AKA'以外の方式（EAP-AKA・EAP-SIM、フル認証・高速再認証・ERPとも）を拒否
+	if eapType != eapaka.TypeAKAPrime && pol.RequireAKAPrime {
+		slog.Warn("EAP-AKA'必須の加入者でEAP-AKA'以外の方式を拒否",
+			"event_id", "AUTH_AKA_PRIME_REQUIRED",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"eap_type", eapType,
+			"source", "policy",
+			"policy_source", pol.Source,
+		)
+		return authz, denyCode, false
 	}
 
 	// ポリシー評価
-	ssid := policy.ExtractSSID(req.CalledStation)
-	evalResult := e.evaluator.Evaluate(pol, req.NASIdentifier, ssid)
+	evalResult := e.evaluator.Evaluate(pol, policyAttributes(req))
 	if !evalResult.Allowed {
 		slog.Warn("ポリシー拒否",
 			"event_id", "AUTH_POLICY_DENIED",
 			"trace_id", traceID,
 			"imsi", maskedIMSI,
 			"reason", evalResult.DenyReason,
+			"reject_reason", evalResult.RejectReason,
+			"profile", pol.Profile,
+			"policy_source", pol.Source,
 		)
-		_ = e.ctxStore.Delete(ctx, traceID)
-		eapFailure, _ := eap.BuildEAPFailure(pkt.Identifier + 1)
-		return &eap.Result{
-			Action:     eap.ActionReject,
-			EAPMessage: eapFailure,
-		}, nil
+		return authz, rejectNotificationCode(evalResult.RejectReason), false
 	}
 
-	// セッション作成
-	sessionID := session.GenerateSessionID()
-	sess := &session.Session{
-		IMSI:      eapCtx.IMSI,
-		NasIP:     req.SrcIP,
-		StartTime: time.Now().Unix(),
+	authz.policySource = pol.Source
+
+	// VLAN/Timeout取得
+	if evalResult.MatchedRule != nil {
+		authz.vlanID = evalResult.MatchedRule.VlanID
+		authz.sessionTimeout = evalResult.MatchedRule.SessionTimeout
+		authz.replyAttributes = evalResult.MatchedRule.ReplyAttributes
 	}
-	if err := e.sessStore.Create(ctx, sessionID, sess); err != nil {
-		slog.Error("セッション作成失敗",
-			"event_id", "SESSION_CREATE_ERR",
-			"trace_id", traceID,
-			"error", err,
-		)
-		return e.buildReject(pkt.Identifier + 1), nil
+
+	// 同時セッション数の上限
+	authz.maxSessions = e.sessionLimit(pol)
+	if e.sessionLimitReached(ctx, traceID, imsi, authz.maxSessions) {
+		return authz, eap.NotificationTemporarilyDenied, false
 	}
-	if err := e.sessStore.AddUserIndex(ctx, eapCtx.IMSI, sessionID); err != nil {
-		slog.Warn("ユーザーインデックス追加失敗",
-			"event_id", "SESSION_INDEX_ERR",
+	return authz, denyCode, true
+}
+
+// checkSubscriberStatus は加入者の利用状態と有効期間を確認する
+// 認証を許可しない場合はokにfalseを、codeに失敗通知の通知コードを返す
+// 一時停止はTemporarily denied、利用停止・有効期間外はNot subscribedとする
+func (e *EngineImpl) checkSubscriberStatus(ctx context.Context, traceID, imsi string) (code uint16, ok bool) {
+	if e.subStore == nil {
+		return 0, true
+	}
+
+	st, err := e.subStore.GetSubscriberStatus(ctx, imsi)
+	if err != nil {
+		slog.Error("加入者状態取得失敗",
+			"event_id", "AUTH_SUBSCRIBER_STATUS_ERR",
 			"trace_id", traceID,
+			"imsi", e.maskIMSI(imsi),
 			"error", err,
 		)
-		// インデックス失敗は致命的ではない
+		return eap.NotificationGeneralFailureAfterAuth, false
+	}
+
+	var eventID, msg string
+	switch {
+	case st.Status == model.SubscriberStatusBarred:
+		eventID, msg, code = "AUTH_SUBSCRIBER_BARRED", "利用停止中の加入者を拒否", eap.NotificationNotSubscribed
+	case st.Status != "" && st.Status != model.SubscriberStatusActive:
+		eventID, msg, code = "AUTH_SUBSCRIBER_SUSPENDED", "一時停止中の加入者を拒否", eap.NotificationTemporarilyDenied
+	case !model.WithinValidity(st.ValidFrom, st.ValidUntil, time.Now()):
+		eventID, msg, code = "AUTH_SUBSCRIBER_EXPIRED", "有効期間外の加入者を拒否", eap.NotificationNotSubscribed
+	default:
+		return 0, true
+	}
+	slog.Warn(msg,
+		"event_id", eventID,
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(imsi),
+		"status", st.Status,
+	)
+	return code, false
+}
+
+// rejectNotificationCode はルールの拒否理由を失敗通知の通知コードに変換する
+// 拒否理由の指定がない場合はGeneral failure after authenticationを返す
+func rejectNotificationCode(reason string) uint16 {
+	switch reason {
+	case policy.RejectReasonTemporarilyDenied:
+		return eap.NotificationTemporarilyDenied
+	case policy.RejectReasonNotSubscribed:
+		return eap.NotificationNotSubscribed
+	default:
+		return eap.NotificationGeneralFailureAfterAuth
+	}
+}
+
+// policyAttributes はポリシー評価に使用するリクエスト属性を組み立てる
+// NAS-IP-Address属性がない場合は送信元IPアドレスをNAS IPアドレスとして扱う
+func policyAttributes(req *eap.Request) *policy.Attributes {
+	nasIP := req.NASIPAddress
+	if nasIP == "" {
+		nasIP = req.SrcIP
+	}
+	return &policy.Attributes{
+		NASIdentifier:  req.NASIdentifier,
+		NASIPAddress:   nasIP,
+		SSID:           policy.ExtractSSID(req.CalledStation),
+		CallingStation: req.CallingStation,
+		NASPortType:    req.NASPortType,
+		Time:           time.Now(),
+	}
+}
+
+// rejectAfterAuthentication は認証成功後の拒否を行う
+// resultIndがtrueの場合は失敗通知を送信し、Notification応答の受信後にRejectする
+func (e *EngineImpl) rejectAfterAuthentication(ctx context.Context, traceID string, eapCtx *session.EAPContext, identifier uint8, code uint16, resultInd bool) *eap.Result {
+	if resultInd {
+		return e.sendNotification(ctx, traceID, eapCtx, identifier, code, nil)
 	}
 
-	// EAPContext削除
 	_ = e.ctxStore.Delete(ctx, traceID)
+	eapFailure, _ := eap.BuildEAPFailure(identifier + 1)
+	return &eap.Result{
+		Action:     eap.ActionReject,
+		EAPMessage: eapFailure,
+	}
+}
 
-	// EAP-Success構築
-	eapSuccess, _ := eap.BuildEAPSuccess(pkt.Identifier + 1)
+// acceptAuthentication はセッション作成・再認証コンテキスト保存を行い、Acceptを構築する
+func (e *EngineImpl) acceptAuthentication(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, identifier uint8, msk []byte, authz authorization) *eap.Result {
+	maskedIMSI := e.maskIMSI(eapCtx.IMSI)
 
-	// VLAN/Timeout取得
-	var vlanID string
-	var sessionTimeout int
-	if evalResult.MatchedRule != nil {
-		vlanID = evalResult.MatchedRule.VlanID
-		sessionTimeout = evalResult.MatchedRule.SessionTimeout
+	// 処理期限超過 → 成功を確定せずに応答なし（EAPコンテキストを残し、NASの再送で改めて処理する）
+	if ctx.Err() != nil {
+		return &eap.Result{Action: eap.ActionDrop}
+	}
+
+	// 成功への遷移を確定（同一Stateの並行リクエストによるセッションの重複作成・MSKの重複送信を防ぐ）
+	if res := e.transitionContext(ctx, traceID, eapCtx, identifier, map[string]any{
+		"stage": string(eap.StateSuccess),
+	}); res != nil {
+		if res.Action != eap.ActionDrop {
+			_ = e.ctxStore.Delete(ctx, traceID)
+		}
+		return res
+	}
+
+	// 確定後は再送を処理できないため、処理期限を超過してもセッション作成等を完了させる
+	ctx = context.WithoutCancel(ctx)
+
+	// セッション作成
+	sessionID, ok := e.createSession(ctx, req, traceID, eapCtx.IMSI, authz.maxSessions)
+	if !ok {
+		return e.buildReject(identifier + 1)
+	}
+
+	// 高速再認証コンテキストの保存・更新
+	e.saveReauthContext(ctx, traceID, eapCtx)
+
+	// 次回用の仮名の登録・使用済みの仮名の削除
+	e.savePseudonym(ctx, traceID, eapCtx)
+
+	// ERP鍵（rRK）の保存
+	e.saveERPKey(ctx, traceID, eapCtx)
+
+	// 認証失敗回数のリセット（AUTN/RESを検証したフル認証の成功時のみ。高速再認証は保存済みの鍵の所持しか示さない）
+	if eapCtx.ReauthID == "" {
+		e.clearAuthFailures(ctx, traceID, eapCtx.IMSI)
 	}
 
+	// EAPContext削除
+	_ = e.ctxStore.Delete(ctx, traceID)
+
+	// EAP-Success構築
+	eapSuccess, _ := eap.BuildEAPSuccess(identifier + 1)
+
 	slog.Info("認証成功",
 		"event_id", "AUTH_SUCCESS",
 		"trace_id", traceID,
 		"imsi", maskedIMSI,
 		"session_id", sessionID,
+		"reauth", eapCtx.ReauthID != "",
+		"policy_source", authz.policySource,
 	)
 
 	return &eap.Result{
@@ -616,10 +1259,58 @@ func (e *EngineImpl) handleChallengeResponse(ctx context.Context, req *eap.Reque
 		EAPMessage:     eapSuccess,
 		IMSI:           eapCtx.IMSI,
 		SessionID:      sessionID,
-		MSK:            mskBytes,
-		VlanID:         vlanID,
-		SessionTimeout: sessionTimeout,
-	}, nil
+		MSK:            msk,
+		VlanID:         authz.vlanID,
+		SessionTimeout: authz.sessionTimeout,
+
+		ReplyAttributes: authz.replyAttributes,
+	}
+}
+
+// createSession はアクティブセッションとユーザーインデックスを作成し、セッションIDを返す
+// SESSION_LIMIT_ACTIONがevictの場合は、maxSessionsを超えた古いセッションを削除する
+// セッション作成に失敗した場合、またはrejectで同時セッション数が上限に達している場合はokにfalseを返す
+func (e *EngineImpl) createSession(ctx context.Context, req *eap.Request, traceID, imsi string, maxSessions int) (sessionID string, ok bool) {
+	sessionID = session.GenerateSessionID()
+	sess := &session.Session{
+		IMSI:      imsi,
+		NasIP:     req.SrcIP,
+		StartTime: time.Now().Unix(),
+	}
+	if err := e.sessStore.Create(ctx, sessionID, sess); err != nil {
+		slog.Error("セッション作成失敗",
+			"event_id", "SESSION_CREATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return "", false
+	}
+	// 上限到達時に拒否する設定では、上限の確認とインデックスへの追加を不可分に行う
+	limit := maxSessions
+	if e.evictEnabled() {
+		limit = 0
+	}
+	added, err := e.sessStore.AddUserIndex(ctx, imsi, sessionID, limit)
+	if err != nil {
+		slog.Warn("ユーザーインデックス追加失敗",
+			"event_id", "SESSION_INDEX_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		// インデックス失敗は致命的ではない
+	} else if !added {
+		// 上限の事前確認後に並行する認証が先にセッションを作成した
+		slog.Warn("同時セッション数上限",
+			"event_id", "AUTH_SESSION_LIMIT",
+			"trace_id", traceID,
+			"imsi", e.maskIMSI(imsi),
+			"max_sessions", maxSessions,
+		)
+		_ = e.sessStore.Delete(ctx, imsi, sessionID)
+		return "", false
+	}
+	e.evictSessions(ctx, traceID, imsi, sessionID, maxSessions)
+	return sessionID, true
 }
 
 // handleResync は再同期失敗応答を処理する
@@ -644,6 +1335,7 @@ func (e *EngineImpl) handleResync(ctx context.Context, req *eap.Request, traceID
 			"imsi", maskedIMSI,
 			"resync_count", eapCtx.ResyncCount,
 		)
+		e.recordAuthFailure(ctx, traceID, eapCtx.IMSI, "AUTH_RESYNC_LIMIT")
 		_ = e.ctxStore.Delete(ctx, traceID)
 		eapFailure, _ := eap.BuildEAPFailure(pkt.Identifier + 1)
 		return &eap.Result{
@@ -663,14 +1355,24 @@ func (e *EngineImpl) handleResync(ctx context.Context, req *eap.Request, traceID
 		return e.buildReject(pkt.Identifier + 1), nil
 	}
 
-	// Vector Gateway呼び出し（再同期情報付き）
-	vCtx := vector.WithTraceID(ctx, traceID)
-	vecResp, err := e.vectorClient.GetVector(vCtx, &vector.VectorRequest{
-		IMSI: eapCtx.IMSI,
-		ResyncInfo: &vector.ResyncInfo{
-			RAND: eapCtx.RAND,
-			AUTS: hex.EncodeToString(atAuts.Auts),
-		},
+	// AKA-Identity交換を行った場合は再同期後のChallengeにも同じAT_CHECKCODEを付与する
+	cc, ok := e.restoreCheckcode(traceID, eapCtx)
+	if !ok {
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 状態遷移: CHALLENGE_SENT → RESYNC_SENT（resync_countを加算し、並行する同期失敗応答による重複再同期を防ぐ）
+	if res := e.transitionContext(ctx, traceID, eapCtx, pkt.Identifier, map[string]any{
+		"stage":        string(eap.StateResyncSent),
+		"resync_count": eapCtx.ResyncCount + 1,
+	}); res != nil {
+		return res, nil
+	}
+
+	// 認証ベクター取得（再同期情報付き）
+	vecResp, err := e.acquireVector(ctx, traceID, eapCtx.IMSI, &vector.ResyncInfo{
+		RAND: eapCtx.RAND,
+		AUTS: hex.EncodeToString(atAuts.Auts),
 	})
 	if err != nil {
 		e.logVectorError(err, traceID, maskedIMSI)
@@ -682,9 +1384,13 @@ func (e *EngineImpl) handleResync(ctx context.Context, req *eap.Request, traceID
 		}, nil
 	}
 
-	// Identity情報復元（鍵導出に必要）
+	// Identity情報復元（鍵導出に必要、初回Challengeと同じIdentityを使用する）
+	raw := eapCtx.Identity
+	if raw == "" {
+		raw = req.UserName
+	}
 	identity := &eap.ParsedIdentity{
-		Raw:     req.UserName,
+		Raw:     raw,
 		IMSI:    eapCtx.IMSI,
 		EAPType: eapCtx.EAPType,
 		Type:    eap.IdentityTypePermanentAKA,
@@ -693,36 +1399,16 @@ func (e *EngineImpl) handleResync(ctx context.Context, req *eap.Request, traceID
 		identity.Type = eap.IdentityTypePermanentAKAPrime
 	}
 
-	// 新しい鍵導出
-	var kAut, msk []byte
-	if identity.IsAKAPrime() {
-		keys, err := akaprime.DeriveAllKeys(identity.Raw, vecResp.CK, vecResp.IK, vecResp.AUTN, e.cfg.NetworkName)
-		if err != nil {
-			slog.Error("AKA'鍵導出失敗（再同期）",
-				"event_id", "EAP_KEY_DERIVE_ERR",
-				"trace_id", traceID,
-				"imsi", maskedIMSI,
-				"error", err,
-			)
-			return e.buildReject(pkt.Identifier + 1), nil
-		}
-		kAut = keys.K_aut
-		msk = keys.MSK
-	} else {
-		keys := aka.DeriveKeys(identity.Raw, vecResp.CK, vecResp.IK)
-		kAut = keys.K_aut
-		msk = keys.MSK
+	// 初回Challengeと同じネットワーク名を使用する（未保存の場合は既定値）
+	networkName := eapCtx.NetworkName
+	if networkName == "" {
+		networkName = e.cfg.NetworkName
 	}
 
-	// EAPContext更新（新RAND/AUTN/XRES/Kaut/MSK + resync_count++）
-	updates := map[string]any{
-		"stage":        string(eap.StateChallengeSent),
-		"rand":         hex.EncodeToString(vecResp.RAND),
-		"autn":         hex.EncodeToString(vecResp.AUTN),
-		"xres":         hex.EncodeToString(vecResp.XRES),
-		"k_aut":        hex.EncodeToString(kAut),
-		"msk":          hex.EncodeToString(msk),
-		"resync_count": eapCtx.ResyncCount + 1,
+	// 新しい鍵導出・次回用IDの生成（resync_countは遷移時に加算済み）
+	kAut, opts, updates, ok := e.prepareChallenge(ctx, traceID, identity, vecResp, networkName, cc.Sum(), pkt.Identifier)
+	if !ok {
+		return e.buildReject(pkt.Identifier + 1), nil
 	}
 	if err := e.ctxStore.Update(ctx, traceID, updates); err != nil {
 		slog.Error("EAPコンテキスト更新失敗（再同期）",
@@ -734,12 +1420,7 @@ func (e *EngineImpl) handleResync(ctx context.Context, req *eap.Request, traceID
 	}
 
 	// 新Challenge構築
-	var challengeMsg []byte
-	if identity.IsAKAPrime() {
-		challengeMsg, err = akaprime.BuildChallenge(pkt.Identifier+1, vecResp.RAND, vecResp.AUTN, e.cfg.NetworkName, kAut)
-	} else {
-		challengeMsg, err = aka.BuildChallenge(pkt.Identifier+1, vecResp.RAND, vecResp.AUTN, kAut)
-	}
+	challengeMsg, err := buildChallengeMessage(pkt.Identifier+1, identity, vecResp, networkName, kAut, opts)
 	if err != nil {
 		slog.Error("Challenge構築失敗（再同期）",
 			"event_id", "EAP_BUILD_ERR",
@@ -769,8 +1450,17 @@ func (e *EngineImpl) logVectorError(err error, traceID, maskedIMSI string) {
 	var apiErr *vector.APIError
 	if errors.As(err, &apiErr) {
 		eventID := "VECTOR_API_ERR"
-		if apiErr.IsNotFound() {
+		switch {
+		case apiErr.IsNotFound():
 			eventID = "VECTOR_IMSI_NOT_FOUND"
+		case apiErr.IsSubscriberBarred():
+			eventID = "AUTH_SUBSCRIBER_BARRED"
+		case apiErr.IsSubscriberSuspended():
+			eventID = "AUTH_SUBSCRIBER_SUSPENDED"
+		case apiErr.IsSubscriberOutsideValidity():
+			eventID = "AUTH_SUBSCRIBER_EXPIRED"
+		case apiErr.IsForbidden():
+			eventID = "VECTOR_SIM_NOT_PERMITTED"
 		}
 		slog.Error("Vector APIエラー",
 			"event_id", eventID,
@@ -793,6 +1483,16 @@ func (e *EngineImpl) logVectorError(err error, traceID, maskedIMSI string) {
 		return
 	}
 
+	if errors.Is(err, vector.ErrDeadlineExceeded) {
+		slog.Warn("Vector取得が処理期限を超過",
+			"event_id", "VECTOR_DEADLINE_EXCEEDED",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"error", err.Error(),
+		)
+		return
+	}
+
 	if errors.Is(err, vector.ErrCircuitOpen) {
 		slog.Error("Circuit Breaker Open",
 			"event_id", "VECTOR_CB_OPEN",
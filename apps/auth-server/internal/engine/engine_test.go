@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/aka"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/akaprime"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/httputil"
 	eapaka "github.com/oyaguma3/go-eapaka"
 	"go.uber.org/mock/gomock"
 )
@@ -60,6 +64,25 @@ func newTestConfig() *config.Config {
 	}
 }
 
+// policyAttributesMatcher はポリシー評価に渡されるリクエスト属性のNAS-IDとSSIDを検証する
+type policyAttributesMatcher struct {
+	nasID string
+	ssid  string
+}
+
+func matchPolicyAttributes(nasID, ssid string) gomock.Matcher {
+	return policyAttributesMatcher{nasID: nasID, ssid: ssid}
+}
+
+func (m policyAttributesMatcher) Matches(x any) bool {
+	attrs, ok := x.(*policy.Attributes)
+	return ok && attrs.NASIdentifier == m.nasID && attrs.SSID == m.ssid
+}
+
+func (m policyAttributesMatcher) String() string {
+	return fmt.Sprintf("NAS-Identifier=%q, SSID=%q", m.nasID, m.ssid)
+}
+
 // buildIdentityEAPMessage はEAP-Response/Identityパケットを構築する
 func buildIdentityEAPMessage(identifier uint8, eapType uint8) []byte {
 	pkt := &eapaka.Packet{
@@ -131,13 +154,13 @@ func buildClientErrorEAPMessage(identifier uint8, eapType uint8) []byte {
 	return data
 }
 
-// buildNotificationEAPMessage はEAP-Response/Notificationパケットを構築する（未知Subtype用）
-func buildNotificationEAPMessage(identifier uint8, eapType uint8) []byte {
+// buildUnknownSubtypeEAPMessage は未定義Subtypeのパケットを構築する
+func buildUnknownSubtypeEAPMessage(identifier uint8, eapType uint8) []byte {
 	pkt := &eapaka.Packet{
 		Code:       eapaka.CodeResponse,
 		Identifier: identifier,
 		Type:       eapType,
-		Subtype:    eapaka.SubtypeNotification,
+		Subtype:    99,
 	}
 	data, _ := pkt.Marshal()
 	return data
@@ -256,36 +279,6 @@ func TestEngine_Pseudonym_Fallback(t *testing.T) {
 	}
 }
 
-func TestEngine_SIM_Reject(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockVector := mocks.NewMockVectorClient(ctrl)
-	mockCtxStore := mocks.NewMockContextStore(ctrl)
-	mockSessStore := mocks.NewMockSessionStore(ctrl)
-	mockPolicyStore := mocks.NewMockPolicyStore(ctrl)
-	mockEvaluator := mocks.NewMockEvaluator(ctrl)
-	cfg := newTestConfig()
-
-	eng := NewEngine(mockVector, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator, cfg)
-
-	eapMsg := buildIdentityEAPMessage(1, eapaka.TypeAKA)
-
-	req := &eap.Request{
-		TraceID:    testTraceID,
-		UserName:   "1001010123456789@realm",
-		EAPMessage: eapMsg,
-	}
-
-	result, err := eng.Process(context.Background(), req)
-	if err != nil {
-		t.Fatalf("予期しないエラー: %v", err)
-	}
-	if result.Action != eap.ActionReject {
-		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
-	}
-}
-
 func TestEngine_InvalidIdentity_Reject(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -303,7 +296,7 @@ func TestEngine_InvalidIdentity_Reject(t *testing.T) {
 
 	req := &eap.Request{
 		TraceID:    testTraceID,
-		UserName:   "Xinvalid@realm",
+		UserName:   "Xinvalid@-realm",
 		EAPMessage: eapMsg,
 	}
 
@@ -384,6 +377,29 @@ func TestEngine_Vector404_Reject(t *testing.T) {
 	}
 }
 
+// engineTestMocks はテスト用エンジンの必須依存のモック一式
+type engineTestMocks struct {
+	vector    *mocks.MockVectorClient
+	ctxStore  *mocks.MockContextStore
+	sessStore *mocks.MockSessionStore
+	policy    *mocks.MockPolicyStore
+	evaluator *mocks.MockEvaluator
+}
+
+// newTestEngine は必須依存をモックにしたエンジンを生成する
+// 任意機能のストアはoptsで指定する
+func newTestEngine(ctrl *gomock.Controller, cfg *config.Config, opts ...Option) (*EngineImpl, *engineTestMocks) {
+	m := &engineTestMocks{
+		vector:    mocks.NewMockVectorClient(ctrl),
+		ctxStore:  mocks.NewMockContextStore(ctrl),
+		sessStore: mocks.NewMockSessionStore(ctrl),
+		policy:    mocks.NewMockPolicyStore(ctrl),
+		evaluator: mocks.NewMockEvaluator(ctrl),
+	}
+	eng := NewEngine(m.vector, m.ctxStore, m.sessStore, m.policy, m.evaluator, cfg, opts...)
+	return eng, m
+}
+
 // --- Challenge テスト ---
 
 // newChallengeTestEngine はChallenge応答テスト用のエンジンとモックを生成する
@@ -395,14 +411,8 @@ func newChallengeTestEngine(ctrl *gomock.Controller) (
 	*mocks.MockPolicyStore,
 	*mocks.MockEvaluator,
 ) {
-	mockVector := mocks.NewMockVectorClient(ctrl)
-	mockCtxStore := mocks.NewMockContextStore(ctrl)
-	mockSessStore := mocks.NewMockSessionStore(ctrl)
-	mockPolicyStore := mocks.NewMockPolicyStore(ctrl)
-	mockEvaluator := mocks.NewMockEvaluator(ctrl)
-	cfg := newTestConfig()
-	eng := NewEngine(mockVector, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator, cfg)
-	return eng, mockVector, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator
+	eng, m := newTestEngine(ctrl, newTestConfig())
+	return eng, m.vector, m.ctxStore, m.sessStore, m.policy, m.evaluator
 }
 
 // makeChallengeContext はChallenge応答テスト用のEAPContextを生成する
@@ -433,17 +443,18 @@ func TestEngine_ChallengeSuccess_Accept(t *testing.T) {
 	challengeResp := buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI).
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
 		Return(&policy.Policy{Default: "allow", Rules: []policy.PolicyRule{
 			{NasID: testNASID, AllowedSSIDs: []string{"*"}, VlanID: "100", SessionTimeout: 3600},
 		}}, nil)
-	mockEvaluator.EXPECT().Evaluate(gomock.Any(), testNASID, testSSID).
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
 		Return(&policy.EvaluationResult{
 			Allowed:     true,
 			MatchedRule: &policy.PolicyRule{VlanID: "100", SessionTimeout: 3600},
 		})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
 	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
 	req := &eap.Request{
@@ -557,7 +568,7 @@ func TestEngine_PolicyNotFound_Reject(t *testing.T) {
 	challengeResp := buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI).
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
 		Return(nil, policy.ErrPolicyNotFound)
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
@@ -590,9 +601,9 @@ func TestEngine_PolicyDenied_Reject(t *testing.T) {
 	challengeResp := buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI).
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
 		Return(&policy.Policy{Default: "deny"}, nil)
-	mockEvaluator.EXPECT().Evaluate(gomock.Any(), testNASID, testSSID).
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
 		Return(&policy.EvaluationResult{Allowed: false, DenyReason: "no matching rule"})
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
@@ -657,6 +668,7 @@ func TestEngine_SyncFailure_Success(t *testing.T) {
 	syncMsg := buildSyncFailureEAPMessage(2, eapaka.TypeAKA, auts)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(mockCtxStore, eap.StateResyncSent).Return(nil)
 	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
 		Return(&vector.VectorResponse{
 			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
@@ -788,7 +800,7 @@ func TestEngine_UnknownSubtype(t *testing.T) {
 		Stage: string(eap.StateChallengeSent),
 	}
 
-	notifMsg := buildNotificationEAPMessage(2, eapaka.TypeAKA)
+	unknownMsg := buildUnknownSubtypeEAPMessage(2, eapaka.TypeAKA)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
 
@@ -796,7 +808,7 @@ func TestEngine_UnknownSubtype(t *testing.T) {
 		TraceID:    testTraceID,
 		UserName:   "0" + testIMSI + "@realm",
 		State:      []byte(testTraceID),
-		EAPMessage: notifMsg,
+		EAPMessage: unknownMsg,
 	}
 
 	result, err := eng.Process(context.Background(), req)
@@ -840,15 +852,15 @@ func TestEngine_IdentityResponse_Success(t *testing.T) {
 
 	// WAITING_IDENTITY状態のEAPContext
 	eapCtx := &session.EAPContext{
-		Stage:                string(eap.StateWaitingIdentity),
-		PermanentIDRequested: true,
-		EAPType:              eapaka.TypeAKA,
+		Stage:           string(eap.StateWaitingIdentity),
+		IdentityReqSent: uint8(eap.IdentityReqPermanent),
+		EAPType:         eapaka.TypeAKA,
 	}
 
-	identityMsg := buildIdentityEAPMessage(2, eapaka.TypeAKA)
+	identityMsg := buildAKAIdentityResponse(2, eapaka.TypeAKA, "0"+testIMSI+"@realm")
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
 	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
 		Return(&vector.VectorResponse{
 			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
@@ -857,7 +869,6 @@ func TestEngine_IdentityResponse_Success(t *testing.T) {
 
 	req := &eap.Request{
 		TraceID:    testTraceID,
-		UserName:   "0" + testIMSI + "@realm",
 		State:      []byte(testTraceID),
 		EAPMessage: identityMsg,
 	}
@@ -878,19 +889,18 @@ func TestEngine_IdentityResponse_NonPermanent_Reject(t *testing.T) {
 	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
 
 	eapCtx := &session.EAPContext{
-		Stage:                string(eap.StateWaitingIdentity),
-		PermanentIDRequested: true,
-		EAPType:              eapaka.TypeAKA,
+		Stage:           string(eap.StateWaitingIdentity),
+		IdentityReqSent: uint8(eap.IdentityReqPermanent),
+		EAPType:         eapaka.TypeAKA,
 	}
 
-	identityMsg := buildIdentityEAPMessage(2, eapaka.TypeAKA)
+	identityMsg := buildAKAIdentityResponse(2, eapaka.TypeAKA, "2pseudonym@realm") // 仮名ID → 非永続
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
 	req := &eap.Request{
 		TraceID:    testTraceID,
-		UserName:   "2pseudonym@realm", // 仮名ID → 非永続
 		State:      []byte(testTraceID),
 		EAPMessage: identityMsg,
 	}
@@ -911,19 +921,18 @@ func TestEngine_IdentityResponse_InvalidIdentity_Reject(t *testing.T) {
 	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
 
 	eapCtx := &session.EAPContext{
-		Stage:                string(eap.StateWaitingIdentity),
-		PermanentIDRequested: true,
-		EAPType:              eapaka.TypeAKA,
+		Stage:           string(eap.StateWaitingIdentity),
+		IdentityReqSent: uint8(eap.IdentityReqPermanent),
+		EAPType:         eapaka.TypeAKA,
 	}
 
-	identityMsg := buildIdentityEAPMessage(2, eapaka.TypeAKA)
+	identityMsg := buildAKAIdentityResponse(2, eapaka.TypeAKA, "Xinvalid@-realm") // 不正なIdentity
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
 	req := &eap.Request{
 		TraceID:    testTraceID,
-		UserName:   "Xinvalid@realm", // 不正なIdentity
 		State:      []byte(testTraceID),
 		EAPMessage: identityMsg,
 	}
@@ -1021,6 +1030,42 @@ func TestEngine_VectorUnknownError_Reject(t *testing.T) {
 	}
 }
 
+func TestEngine_VectorSubscriberBarred_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	eapMsg := buildIdentityEAPMessage(1, eapaka.TypeAKA)
+
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(nil, &vector.APIError{
+			StatusCode: 403,
+			Message:    "Subscriber Barred",
+			Details: &vector.ProblemDetails{
+				Type:   httputil.ProblemTypeSubscriberBarred,
+				Title:  "Subscriber Barred",
+				Detail: "Subscriber is barred",
+				Status: 403,
+			},
+		})
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: eapMsg,
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
 // --- NotIdentity テスト ---
 
 func TestEngine_Identity_NotIdentitySubtype(t *testing.T) {
@@ -1097,6 +1142,7 @@ func TestEngine_Resync_VectorError(t *testing.T) {
 	syncMsg := buildSyncFailureEAPMessage(2, eapaka.TypeAKA, auts)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(mockCtxStore, eap.StateResyncSent).Return(nil)
 	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
 		Return(nil, &vector.APIError{StatusCode: 500, Message: "internal error"})
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
@@ -1410,7 +1456,7 @@ func TestEngine_Subsequent_ParseError_Reject(t *testing.T) {
 
 // --- E. handleIdentityResponse系 追加テスト ---
 
-// TestEngine_IdentityResponse_UnsupportedIdentity_Reject はSIM Identity（非対応）のテスト
+// TestEngine_IdentityResponse_UnsupportedIdentity_Reject はAKA-Identity交換中のSIM Identityのテスト
 func TestEngine_IdentityResponse_UnsupportedIdentity_Reject(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1418,19 +1464,18 @@ func TestEngine_IdentityResponse_UnsupportedIdentity_Reject(t *testing.T) {
 	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
 
 	eapCtx := &session.EAPContext{
-		Stage:                string(eap.StateWaitingIdentity),
-		PermanentIDRequested: true,
-		EAPType:              eapaka.TypeAKA,
+		Stage:           string(eap.StateWaitingIdentity),
+		IdentityReqSent: uint8(eap.IdentityReqPermanent),
+		EAPType:         eapaka.TypeAKA,
 	}
 
-	identityMsg := buildIdentityEAPMessage(2, eapaka.TypeAKA)
+	identityMsg := buildAKAIdentityResponse(2, eapaka.TypeAKA, "1"+testIMSI+"@realm") // SIM Identity (prefix '1')
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
 	req := &eap.Request{
 		TraceID:    testTraceID,
-		UserName:   "1" + testIMSI + "@realm", // SIM Identity (prefix '1')
 		State:      []byte(testTraceID),
 		EAPMessage: identityMsg,
 	}
@@ -1452,20 +1497,19 @@ func TestEngine_IdentityResponse_CtxUpdateError_Reject(t *testing.T) {
 	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
 
 	eapCtx := &session.EAPContext{
-		Stage:                string(eap.StateWaitingIdentity),
-		PermanentIDRequested: true,
-		EAPType:              eapaka.TypeAKA,
+		Stage:           string(eap.StateWaitingIdentity),
+		IdentityReqSent: uint8(eap.IdentityReqPermanent),
+		EAPType:         eapaka.TypeAKA,
 	}
 
-	identityMsg := buildIdentityEAPMessage(2, eapaka.TypeAKA)
+	identityMsg := buildAKAIdentityResponse(2, eapaka.TypeAKA, "0"+testIMSI+"@realm")
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).
 		Return(errors.New("valkey error"))
 
 	req := &eap.Request{
 		TraceID:    testTraceID,
-		UserName:   "0" + testIMSI + "@realm",
 		State:      []byte(testTraceID),
 		EAPMessage: identityMsg,
 	}
@@ -1620,17 +1664,18 @@ func TestEngine_ChallengeSuccess_AKAPrime_Accept(t *testing.T) {
 	challengeResp := buildAKAPrimeChallengeResponseEAPMessage(2, keys.K_aut, testXRES)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI).
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
 		Return(&policy.Policy{Default: "allow", Rules: []policy.PolicyRule{
 			{NasID: testNASID, AllowedSSIDs: []string{"*"}, VlanID: "200", SessionTimeout: 7200},
 		}}, nil)
-	mockEvaluator.EXPECT().Evaluate(gomock.Any(), testNASID, testSSID).
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
 		Return(&policy.EvaluationResult{
 			Allowed:     true,
 			MatchedRule: &policy.PolicyRule{VlanID: "200", SessionTimeout: 7200},
 		})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
 	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
 	req := &eap.Request{
@@ -1702,10 +1747,11 @@ func TestEngine_ChallengeResponse_SessionCreateError_Reject(t *testing.T) {
 	challengeResp := buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI).
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
 		Return(&policy.Policy{Default: "allow"}, nil)
-	mockEvaluator.EXPECT().Evaluate(gomock.Any(), testNASID, testSSID).
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
 		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
 	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(errors.New("valkey error"))
 
@@ -1740,13 +1786,14 @@ func TestEngine_ChallengeResponse_AddUserIndexError_Continue(t *testing.T) {
 	challengeResp := buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
-	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI).
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
 		Return(&policy.Policy{Default: "allow"}, nil)
-	mockEvaluator.EXPECT().Evaluate(gomock.Any(), testNASID, testSSID).
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
 		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
 	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any()).
-		Return(errors.New("index error")) // 非致命的エラー
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).
+		Return(false, errors.New("index error")) // 非致命的エラー
 	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
 
 	req := &eap.Request{
@@ -1836,6 +1883,7 @@ func TestEngine_Resync_AKAPrime_Success(t *testing.T) {
 	syncMsg := buildSyncFailureEAPMessage(2, eapaka.TypeAKAPrime, auts)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(mockCtxStore, eap.StateResyncSent).Return(nil)
 	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
 		Return(&vector.VectorResponse{
 			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
@@ -1876,6 +1924,7 @@ func TestEngine_Resync_CtxUpdateError_Reject(t *testing.T) {
 	syncMsg := buildSyncFailureEAPMessage(2, eapaka.TypeAKA, auts)
 
 	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(mockCtxStore, eap.StateResyncSent).Return(nil)
 	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
 		Return(&vector.VectorResponse{
 			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
@@ -1898,3 +1947,325 @@ func TestEngine_Resync_CtxUpdateError_Reject(t *testing.T) {
 		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
 	}
 }
+
+// --- 仮名テスト ---
+
+// newPseudonymTestEngine は仮名ストア付きのエンジンとモックを生成する
+func newPseudonymTestEngine(ctrl *gomock.Controller) (
+	*EngineImpl,
+	*mocks.MockVectorClient,
+	*mocks.MockContextStore,
+	*mocks.MockPseudonymStore,
+) {
+	mockPseudoStore := mocks.NewMockPseudonymStore(ctrl)
+	eng, m := newTestEngine(ctrl, newTestConfig(), WithPseudonymStore(mockPseudoStore))
+	return eng, m.vector, m.ctxStore, mockPseudoStore
+}
+
+// extractNextPseudonym はChallengeのAT_ENCR_DATAからAT_NEXT_PSEUDONYMを取り出す
+func extractNextPseudonym(t *testing.T, challengeMsg []byte, identity string, eapType uint8) string {
+	t.Helper()
+	pkt, err := eapaka.Parse(challengeMsg)
+	if err != nil {
+		t.Fatalf("Challengeパース失敗: %v", err)
+	}
+	atIv, ok := eap.GetAttribute[*eapaka.AtIv](pkt)
+	if !ok {
+		t.Fatal("AT_IVが見つからない")
+	}
+	atEncr, ok := eap.GetAttribute[*eapaka.AtEncrData](pkt)
+	if !ok {
+		t.Fatal("AT_ENCR_DATAが見つからない")
+	}
+
+	var kEncr []byte
+	if eapType == eapaka.TypeAKAPrime {
+		keys, err := akaprime.DeriveAllKeys(identity, testCK, testIK, testAUTN, testNetworkName)
+		if err != nil {
+			t.Fatalf("鍵導出失敗: %v", err)
+		}
+		kEncr = keys.K_encr
+	} else {
+		kEncr = aka.DeriveKeys(identity, testCK, testIK).K_encr
+	}
+
+	attrs, err := eap.DecryptAttributes(kEncr, atIv, atEncr)
+	if err != nil {
+		t.Fatalf("AT_ENCR_DATA復号失敗: %v", err)
+	}
+	for _, a := range attrs {
+		if next, ok := a.(*eapaka.AtNextPseudonym); ok {
+			return next.Pseudonym
+		}
+	}
+	t.Fatal("AT_NEXT_PSEUDONYMが見つからない")
+	return ""
+}
+
+func TestEngine_PermanentAKA_IssuesPseudonym(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 仮名はChallengeで通知し、Valkeyへは認証成功時に登録する（ここではCreateを呼ばない）
+	eng, mockVector, mockCtxStore, _ := newPseudonymTestEngine(ctrl)
+	userName := "0" + testIMSI + "@realm"
+
+	var issued, used any
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			issued = u["next_pseudonym"]
+			used = u["pseudonym"]
+			return nil
+		})
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   userName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	next, _ := issued.(string)
+	if next == "" || next[0] != '2' {
+		t.Fatalf("EAP-AKA仮名が生成されていない: %q", next)
+	}
+	if used != "" {
+		t.Errorf("pseudonym: got %v, want empty", used)
+	}
+
+	got := extractNextPseudonym(t, result.EAPMessage, userName, eapaka.TypeAKA)
+	if got != next {
+		t.Errorf("AT_NEXT_PSEUDONYM: got %q, want %q", got, next)
+	}
+}
+
+func TestEngine_ChallengeSuccess_SavesPseudonym(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPseudoStore := mocks.NewMockPseudonymStore(ctrl)
+	eng, m := newTestEngine(ctrl, newTestConfig(), WithPseudonymStore(mockPseudoStore))
+
+	// 成功時に通知済みの仮名を登録し、使用済みの仮名を削除する
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	eapCtx.NextPseudonym = "2next"
+	eapCtx.Pseudonym = "2used"
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	gomock.InOrder(
+		mockPseudoStore.EXPECT().Create(gomock.Any(), "2next", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, entry *session.PseudonymEntry) error {
+				if entry.IMSI != testIMSI || entry.EAPType != eapaka.TypeAKA {
+					t.Errorf("entry: got %+v", entry)
+				}
+				return nil
+			}),
+		mockPseudoStore.EXPECT().Delete(gomock.Any(), "2used").Return(nil),
+	)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_ChallengeSuccess_PseudonymSaveError_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPseudoStore := mocks.NewMockPseudonymStore(ctrl)
+	eng, m := newTestEngine(ctrl, newTestConfig(), WithPseudonymStore(mockPseudoStore))
+
+	// 登録に失敗しても認証は成功とし、使用済みの仮名は残す
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	eapCtx.NextPseudonym = "2next"
+	eapCtx.Pseudonym = "2used"
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	mockPseudoStore.EXPECT().Create(gomock.Any(), "2next", gomock.Any()).Return(errors.New("valkey down"))
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_ChallengeFailure_DoesNotSavePseudonym(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 仮名ストアへの呼び出しを期待しない（呼ばれた場合はモックがテストを失敗させる）
+	mockPseudoStore := mocks.NewMockPseudonymStore(ctrl)
+	eng, m := newTestEngine(ctrl, newTestConfig(), WithPseudonymStore(mockPseudoStore))
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.NextPseudonym = "2next"
+	eapCtx.Pseudonym = "2used"
+	wrongRES := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil).AnyTimes()
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, wrongRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_KnownPseudonym_DirectChallenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, mockPseudoStore := newPseudonymTestEngine(ctrl)
+	userName := "7known@realm"
+
+	mockPseudoStore.EXPECT().Get(gomock.Any(), "7known").
+		Return(&session.PseudonymEntry{IMSI: testIMSI, EAPType: eapaka.TypeAKAPrime}, nil)
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, eapCtx *session.EAPContext) error {
+			if eapCtx.IMSI != testIMSI {
+				t.Errorf("IMSI: got %q, want %q", eapCtx.IMSI, testIMSI)
+			}
+			return nil
+		})
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Cond(func(u map[string]any) bool {
+		return u["pseudonym"] == "7known" // 成功時に無効化する使用済みの仮名
+	})).Return(nil)
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   userName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKAPrime),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	if pkt.Subtype != eapaka.SubtypeChallenge {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeChallenge)
+	}
+	// 鍵導出は仮名Identityで行われる
+	if next := extractNextPseudonym(t, result.EAPMessage, userName, eapaka.TypeAKAPrime); next[0] != '7' {
+		t.Errorf("EAP-AKA'仮名のプレフィックスが不正: %q", next)
+	}
+}
+
+func TestEngine_UnknownPseudonym_FallbackToPermanentIDRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		getErr error
+		entry  *session.PseudonymEntry
+	}{
+		{name: "未登録", getErr: session.ErrPseudonymNotFound},
+		{name: "ストア障害", getErr: errors.New("valkey down")},
+		{name: "EAP方式不一致", entry: &session.PseudonymEntry{IMSI: testIMSI, EAPType: eapaka.TypeAKAPrime}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, _, mockCtxStore, mockPseudoStore := newPseudonymTestEngine(ctrl)
+
+			mockPseudoStore.EXPECT().Get(gomock.Any(), "2unknown").Return(tt.entry, tt.getErr)
+			mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+			mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqPermanent)).Return(nil)
+
+			req := &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   "2unknown@realm",
+				EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+			}
+
+			result, err := eng.Process(context.Background(), req)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionChallenge {
+				t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+			}
+			pkt, _ := eapaka.Parse(result.EAPMessage)
+			if pkt.Subtype != eapaka.SubtypeIdentity {
+				t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeIdentity)
+			}
+			if _, found := eap.GetAttribute[*eapaka.AtPermanentIdReq](pkt); !found {
+				t.Error("AT_PERMANENT_ID_REQが見つからない")
+			}
+		})
+	}
+}
+
+func TestPolicyAttributes(t *testing.T) {
+	req := &eap.Request{
+		SrcIP:          "192.168.1.1",
+		NASIdentifier:  testNASID,
+		NASPortType:    19,
+		CalledStation:  "AA-BB-CC-DD-EE-FF:" + testSSID,
+		CallingStation: "11-22-33-44-55-66",
+	}
+
+	// NAS-IP-Address属性がない場合は送信元IPアドレスを使用する
+	attrs := policyAttributes(req)
+	if attrs.NASIdentifier != testNASID || attrs.SSID != testSSID || attrs.NASPortType != 19 {
+		t.Errorf("属性が引き継がれていない: %+v", attrs)
+	}
+	if attrs.NASIPAddress != "192.168.1.1" {
+		t.Errorf("NASIPAddress: got %q, want 192.168.1.1", attrs.NASIPAddress)
+	}
+	if attrs.CallingStation != "11-22-33-44-55-66" {
+		t.Errorf("CallingStation: got %q", attrs.CallingStation)
+	}
+	if attrs.Time.IsZero() {
+		t.Error("Timeが設定されていない")
+	}
+
+	req.NASIPAddress = "10.0.0.1"
+	if attrs := policyAttributes(req); attrs.NASIPAddress != "10.0.0.1" {
+		t.Errorf("NASIPAddress: got %q, want 10.0.0.1", attrs.NASIPAddress)
+	}
+}
@@ -0,0 +1,280 @@
+package engine
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+)
+
+// erpEnabled はERP（RFC 6696）が有効かどうかを返す
+func (e *EngineImpl) erpEnabled() bool {
+	return e.erpStore != nil && e.cfg.ERPDomain != ""
+}
+
+// saveERPKey はフル認証成功時にEMSKからrRKを導出して保存する（RFC 6696 Section 4.1、暗黙的ブートストラップ）
+// 高速再認証ではEAP Session-IDを持たないためrRKを更新しない
+// 保存に失敗しても認証結果には影響させない（次回はフル認証となる）
+func (e *EngineImpl) saveERPKey(ctx context.Context, traceID string, eapCtx *session.EAPContext) {
+	if !e.erpEnabled() || eapCtx.EMSK == "" || eapCtx.ReauthID != "" {
+		return
+	}
+
+	// EAP Session-ID（EAP-AKA/AKA': Type | RAND | AUTN、EAP-SIM: Type | n*RAND | NONCE_MT）
+	second := eapCtx.AUTN
+	if eapCtx.EAPType == eap.EAPTypeSIM {
+		second = eapCtx.NonceMT
+	}
+	emsk, err1 := hex.DecodeString(eapCtx.EMSK)
+	rand, err2 := hex.DecodeString(eapCtx.RAND)
+	secondBytes, err3 := hex.DecodeString(second)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		slog.Error("EMSK/Session-ID復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return
+	}
+
+	keyName := hex.EncodeToString(eap.DeriveEMSKName(eap.SessionID(eapCtx.EAPType, rand, secondBytes)))
+	key := &session.ERPKey{
+		IMSI:      eapCtx.IMSI,
+		EAPType:   eapCtx.EAPType,
+		RRK:       hex.EncodeToString(eap.DeriveRRK(emsk)),
+		Seq:       -1,
+		ExpiresAt: time.Now().Add(e.cfg.ERPKeyLifetime).Unix(),
+	}
+	if err := e.erpStore.Create(ctx, keyName, key); err != nil {
+		slog.Warn("ERP鍵保存失敗",
+			"event_id", "EAP_ERP_ISSUE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return
+	}
+
+	slog.Info("ERP鍵保存",
+		"event_id", "EAP_ERP_KEY_ISSUED",
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(eapCtx.IMSI),
+		"key_name", keyName,
+	)
+}
+
+// handleERPReauth はEAP-Initiate/Re-authを処理する（RFC 6696 Section 5.3.2）
+// 成功時はEAP-Finish/Re-authとrMSKを含むAcceptを1往復で返す
+// rRKが見つからない場合はEAP-Messageを含まないRejectを返し、ピアにフル認証を行わせる
+func (e *EngineImpl) handleERPReauth(ctx context.Context, req *eap.Request) (*eap.Result, error) {
+	traceID := req.TraceID
+
+	if !e.erpEnabled() {
+		slog.Info("ERP無効のためEAP-Initiateを拒否",
+			"event_id", "EAP_ERP_DISABLED",
+			"trace_id", traceID,
+		)
+		return &eap.Result{Action: eap.ActionReject}, nil
+	}
+
+	r, err := eap.ParseERPReauth(req.EAPMessage)
+	if err != nil {
+		slog.Warn("EAP-Initiate/Re-auth解析失敗",
+			"event_id", "EAP_ERP_INVALID",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return &eap.Result{Action: eap.ActionReject}, nil
+	}
+
+	// 他ドメインのkeyName-NAIは本サーバーのrRKではない
+	if !strings.EqualFold(r.Realm(), e.cfg.ERPDomain) {
+		slog.Info("ERPドメイン不一致",
+			"event_id", "EAP_ERP_UNKNOWN_KEY",
+			"trace_id", traceID,
+			"realm", r.Realm(),
+		)
+		return &eap.Result{Action: eap.ActionReject}, nil
+	}
+
+	key, err := e.erpStore.Get(ctx, r.KeyName())
+	if err != nil {
+		if errors.Is(err, session.ErrERPKeyNotFound) {
+			slog.Info("未知のERP鍵",
+				"event_id", "EAP_ERP_UNKNOWN_KEY",
+				"trace_id", traceID,
+				"key_name", r.KeyName(),
+			)
+		} else {
+			slog.Warn("ERP鍵取得失敗",
+				"event_id", "EAP_ERP_LOOKUP_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+		}
+		return &eap.Result{Action: eap.ActionReject}, nil
+	}
+	maskedIMSI := e.maskIMSI(key.IMSI)
+
+	rRK, err := hex.DecodeString(key.RRK)
+	if err != nil {
+		slog.Error("rRK復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return &eap.Result{Action: eap.ActionReject}, nil
+	}
+
+	// ロックアウト中のIMSIはrRKによる再認証も行わない
+	if e.imsiLocked(ctx, traceID, key.IMSI) {
+		return e.buildERPFailure(traceID, r, rRK, nil), nil
+	}
+
+	// 非対応のCryptosuite → 対応一覧を通知して失敗
+	if !eap.IsERPSupportedCryptosuite(r.Cryptosuite) {
+		slog.Warn("ERP Cryptosuite非対応",
+			"event_id", "AUTH_ERP_CRYPTOSUITE_UNSUPPORTED",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"cryptosuite", r.Cryptosuite,
+		)
+		return e.buildERPFailure(traceID, r, rRK, eap.ERPSupportedCryptosuites), nil
+	}
+
+	// 認証タグ検証
+	if err := eap.VerifyERPReauth(r, eap.DeriveRIK(rRK, r.Cryptosuite)); err != nil {
+		slog.Warn("ERP認証タグ検証失敗",
+			"event_id", "AUTH_ERP_TAG_INVALID",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+		)
+		return e.buildERPFailure(traceID, r, rRK, nil), nil
+	}
+
+	// 処理期限超過 → SEQを記録せずに応答なし（NASの再送で改めて処理する）
+	if ctx.Err() != nil {
+		return &eap.Result{Action: eap.ActionDrop}, nil
+	}
+
+	// リプレイ保護（検証済みのSEQのみ記録する）
+	if err := e.erpStore.AdvanceSeq(ctx, r.KeyName(), r.SEQ); err != nil {
+		if errors.Is(err, session.ErrERPSeqReplay) {
+			slog.Warn("ERP SEQ再利用",
+				"event_id", "AUTH_ERP_SEQ_REPLAY",
+				"trace_id", traceID,
+				"imsi", maskedIMSI,
+				"seq", r.SEQ,
+			)
+		} else {
+			slog.Warn("ERP SEQ更新失敗",
+				"event_id", "EAP_ERP_LOOKUP_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+		}
+		return e.buildERPFailure(traceID, r, rRK, nil), nil
+	}
+
+	// SEQ記録後は再送を処理できないため、処理期限を超過しても認可・セッション作成を完了させる
+	ctx = context.WithoutCancel(ctx)
+
+	// AKA'必須のクライアントではEAP-AKA・EAP-SIM由来のrRKによる再認証も行わない
+	if e.clientRequiresAKAPrime(ctx, req, traceID, key.IMSI, key.EAPType) {
+		return e.buildERPFailure(traceID, r, rRK, nil), nil
+	}
+
+	authz, _, ok := e.authorize(ctx, req, traceID, key.IMSI, key.EAPType)
+	if !ok {
+		return e.buildERPFailure(traceID, r, rRK, nil), nil
+	}
+
+	sessionID, ok := e.createSession(ctx, req, traceID, key.IMSI, authz.maxSessions)
+	if !ok {
+		return e.buildERPFailure(traceID, r, rRK, nil), nil
+	}
+
+	// 'L'フラグ → rRK/rMSKの残り有効期間を通知（rMSKはセッションタイムアウトを優先）
+	rrkLifetime := uint32(max(key.ExpiresAt-time.Now().Unix(), 0))
+	rmskLifetime := rrkLifetime
+	if authz.sessionTimeout > 0 {
+		rmskLifetime = uint32(authz.sessionTimeout)
+	}
+	params := &eap.ERPFinishParams{
+		Identifier:   r.Identifier,
+		Flags:        r.Flags & (eap.ERPFlagBootstrap | eap.ERPFlagLifetime),
+		SEQ:          r.SEQ,
+		KeyNameNAI:   r.KeyNameNAI,
+		RRKLifetime:  rrkLifetime,
+		RMSKLifetime: rmskLifetime,
+		Cryptosuite:  r.Cryptosuite,
+		RIK:          eap.DeriveRIK(rRK, r.Cryptosuite),
+	}
+	if r.Flags&eap.ERPFlagBootstrap != 0 {
+		params.DomainName = e.cfg.ERPDomain
+	}
+	finish, err := eap.BuildERPFinish(params)
+	if err != nil {
+		slog.Error("EAP-Finish/Re-auth構築失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return &eap.Result{Action: eap.ActionReject}, nil
+	}
+
+	slog.Info("認証成功",
+		"event_id", "AUTH_SUCCESS",
+		"trace_id", traceID,
+		"imsi", maskedIMSI,
+		"session_id", sessionID,
+		"erp", true,
+		"policy_source", authz.policySource,
+	)
+
+	return &eap.Result{
+		Action:         eap.ActionAccept,
+		EAPMessage:     finish,
+		IMSI:           key.IMSI,
+		SessionID:      sessionID,
+		MSK:            eap.DeriveRMSK(rRK, r.SEQ),
+		VlanID:         authz.vlanID,
+		SessionTimeout: authz.sessionTimeout,
+
+		ReplyAttributes: authz.replyAttributes,
+	}, nil
+}
+
+// buildERPFailure は'R'フラグ付きEAP-Finish/Re-authを含むRejectを構築する
+// cryptosuitesが空でない場合はCryptosuite List TLVで対応一覧を通知する
+func (e *EngineImpl) buildERPFailure(traceID string, r *eap.ERPReauth, rRK []byte, cryptosuites []uint8) *eap.Result {
+	cs := r.Cryptosuite
+	if !eap.IsERPSupportedCryptosuite(cs) {
+		cs = eap.ERPSupportedCryptosuites[0]
+	}
+	finish, err := eap.BuildERPFinish(&eap.ERPFinishParams{
+		Identifier:   r.Identifier,
+		Flags:        eap.ERPFlagResult | r.Flags&eap.ERPFlagBootstrap,
+		SEQ:          r.SEQ,
+		KeyNameNAI:   r.KeyNameNAI,
+		Cryptosuites: cryptosuites,
+		Cryptosuite:  cs,
+		RIK:          eap.DeriveRIK(rRK, cs),
+	})
+	if err != nil {
+		slog.Error("EAP-Finish/Re-auth構築失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return &eap.Result{Action: eap.ActionReject}
+	}
+	return &eap.Result{
+		Action:     eap.ActionReject,
+		EAPMessage: finish,
+	}
+}
@@ -0,0 +1,298 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testERPDomain  = "erp.example.net"
+	testERPKeyName = "0123456789abcdef"
+)
+
+// テスト用のrRK
+var testRRK = bytes.Repeat([]byte{0x71}, eap.ERPRRKLength)
+
+// erpTestMocks はERPテスト用のモック一式
+type erpTestMocks struct {
+	*engineTestMocks
+	erp *mocks.MockERPStore
+}
+
+// newERPTestEngine はERPストア付きのエンジンとモックを生成する
+func newERPTestEngine(ctrl *gomock.Controller) (*EngineImpl, *erpTestMocks) {
+	cfg := newTestConfig()
+	cfg.ERPDomain = testERPDomain
+	cfg.ERPKeyLifetime = time.Hour
+	erp := mocks.NewMockERPStore(ctrl)
+	eng, m := newTestEngine(ctrl, cfg, WithERPStore(erp))
+	return eng, &erpTestMocks{engineTestMocks: m, erp: erp}
+}
+
+// makeERPKey はテスト用のERP鍵を生成する
+func makeERPKey() *session.ERPKey {
+	return &session.ERPKey{
+		IMSI:      testIMSI,
+		EAPType:   eapaka.TypeAKA,
+		RRK:       hex.EncodeToString(testRRK),
+		Seq:       -1,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// buildERPInitiateMessage はピア側のEAP-Initiate/Re-authを構築する
+func buildERPInitiateMessage(identifier, flags uint8, seq uint16, cryptosuite uint8, rIK []byte) []byte {
+	keyNameNAI := testERPKeyName + "@" + testERPDomain
+	buf := []byte{eap.CodeInitiate, identifier, 0, 0, eap.ERPTypeReauth, flags}
+	buf = binary.BigEndian.AppendUint16(buf, seq)
+	buf = append(buf, eap.ERPTLVKeyNameNAI, byte(len(keyNameNAI)))
+	buf = append(buf, keyNameNAI...)
+	buf = append(buf, cryptosuite)
+	tagLen := eap.ERPTagLength(cryptosuite)
+	if tagLen == 0 {
+		tagLen = 16
+	}
+	binary.BigEndian.PutUint16(buf[2:4], uint16(len(buf)+tagLen))
+	h := hmac.New(sha256.New, rIK)
+	h.Write(buf)
+	return append(buf, h.Sum(nil)[:tagLen]...)
+}
+
+// assertERPFinish はEAP-Finish/Re-authのCode・Identifier・'R'フラグを検証する
+func assertERPFinish(t *testing.T, msg []byte, identifier uint8, failure bool) {
+	t.Helper()
+	if len(msg) < 6 || msg[0] != eap.CodeFinish || msg[1] != identifier {
+		t.Fatalf("EAP-Finish/Re-authではない: % x", msg)
+	}
+	if got := msg[5]&eap.ERPFlagResult != 0; got != failure {
+		t.Errorf("'R'フラグ: got %v, want %v", got, failure)
+	}
+}
+
+func TestEngine_FullAuth_StoresERPKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newERPTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.EMSK = hex.EncodeToString(keys.EMSK)
+
+	wantKeyName := hex.EncodeToString(eap.DeriveEMSKName(eap.SessionID(eapaka.TypeAKA, testRAND, testAUTN)))
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	m.erp.EXPECT().Create(gomock.Any(), wantKeyName, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, key *session.ERPKey) error {
+			if key.IMSI != testIMSI || key.EAPType != eapaka.TypeAKA || key.Seq != -1 {
+				t.Errorf("ERP鍵が不正: %+v", key)
+			}
+			if key.RRK != hex.EncodeToString(eap.DeriveRRK(keys.EMSK)) {
+				t.Error("rRKがEMSKから導出されていない")
+			}
+			if key.ExpiresAt <= time.Now().Unix() {
+				t.Error("ExpiresAtが未来ではない")
+			}
+			return nil
+		})
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_ERPReauth_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newERPTestEngine(ctrl)
+
+	cs := eap.ERPCryptosuiteHMACSHA256_128
+	rIK := eap.DeriveRIK(testRRK, cs)
+
+	m.erp.EXPECT().Get(gomock.Any(), testERPKeyName).Return(makeERPKey(), nil)
+	m.erp.EXPECT().AdvanceSeq(gomock.Any(), testERPKeyName, uint16(5)).Return(nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
+		Return(&policy.EvaluationResult{
+			Allowed:     true,
+			MatchedRule: &policy.PolicyRule{VlanID: "100", SessionTimeout: 1800},
+		})
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:       testTraceID,
+		NASIdentifier: testNASID,
+		CalledStation: "AA-BB-CC-DD-EE-FF:" + testSSID,
+		EAPMessage:    buildERPInitiateMessage(3, eap.ERPFlagBootstrap|eap.ERPFlagLifetime, 5, cs, rIK),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+	if !bytes.Equal(result.MSK, eap.DeriveRMSK(testRRK, 5)) {
+		t.Error("MSKがrMSKではない")
+	}
+	if result.IMSI != testIMSI || result.VlanID != "100" || result.SessionTimeout != 1800 {
+		t.Errorf("認可結果が不正: %+v", result)
+	}
+
+	assertERPFinish(t, result.EAPMessage, 3, false)
+	if !bytes.Contains(result.EAPMessage, append([]byte{eap.ERPTLVDomainName, byte(len(testERPDomain))}, testERPDomain...)) {
+		t.Error("'B'フラグに対するDomain-Name TLVが含まれない")
+	}
+	if !bytes.Contains(result.EAPMessage, []byte{eap.ERPTVRMSKLifetime, 0, 0, 0x07, 0x08}) {
+		t.Error("rMSK LifetimeがSession-Timeoutと一致しない")
+	}
+	tagStart := len(result.EAPMessage) - 16
+	h := hmac.New(sha256.New, rIK)
+	h.Write(result.EAPMessage[:tagStart])
+	if !bytes.Equal(result.EAPMessage[tagStart:], h.Sum(nil)[:16]) {
+		t.Error("EAP-Finishの認証タグが不正")
+	}
+}
+
+func TestEngine_ERPReauth_TagInvalid_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newERPTestEngine(ctrl)
+
+	wrongRIK := bytes.Repeat([]byte{0xee}, eap.ERPRIKLength)
+	m.erp.EXPECT().Get(gomock.Any(), testERPKeyName).Return(makeERPKey(), nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		EAPMessage: buildERPInitiateMessage(3, 0, 5, eap.ERPCryptosuiteHMACSHA256_128, wrongRIK),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+	assertERPFinish(t, result.EAPMessage, 3, true)
+}
+
+func TestEngine_ERPReauth_SeqReplay_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newERPTestEngine(ctrl)
+
+	cs := eap.ERPCryptosuiteHMACSHA256_256
+	m.erp.EXPECT().Get(gomock.Any(), testERPKeyName).Return(makeERPKey(), nil)
+	m.erp.EXPECT().AdvanceSeq(gomock.Any(), testERPKeyName, uint16(5)).Return(session.ErrERPSeqReplay)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		EAPMessage: buildERPInitiateMessage(3, 0, 5, cs, eap.DeriveRIK(testRRK, cs)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+	assertERPFinish(t, result.EAPMessage, 3, true)
+}
+
+func TestEngine_ERPReauth_UnsupportedCryptosuite_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newERPTestEngine(ctrl)
+
+	m.erp.EXPECT().Get(gomock.Any(), testERPKeyName).Return(makeERPKey(), nil)
+
+	cs := eap.ERPCryptosuiteHMACSHA256_64
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		EAPMessage: buildERPInitiateMessage(3, 0, 5, cs, eap.DeriveRIK(testRRK, cs)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+	assertERPFinish(t, result.EAPMessage, 3, true)
+	if !bytes.Contains(result.EAPMessage, []byte{eap.ERPTLVCryptosuiteList, 2, eap.ERPCryptosuiteHMACSHA256_128, eap.ERPCryptosuiteHMACSHA256_256}) {
+		t.Error("Cryptosuite List TLVが含まれない")
+	}
+}
+
+func TestEngine_ERPReauth_UnknownKey_RejectWithoutFinish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newERPTestEngine(ctrl)
+
+	m.erp.EXPECT().Get(gomock.Any(), testERPKeyName).Return(nil, session.ErrERPKeyNotFound)
+
+	cs := eap.ERPCryptosuiteHMACSHA256_128
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		EAPMessage: buildERPInitiateMessage(3, 0, 5, cs, eap.DeriveRIK(testRRK, cs)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+	if len(result.EAPMessage) != 0 {
+		t.Error("未知の鍵でEAP-Messageを返した（ピアがフル認証へ移行できない）")
+	}
+}
+
+func TestEngine_ERPReauth_Disabled_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, _, _, _, _ := newChallengeTestEngine(ctrl)
+
+	cs := eap.ERPCryptosuiteHMACSHA256_128
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		EAPMessage: buildERPInitiateMessage(3, 0, 5, cs, eap.DeriveRIK(testRRK, cs)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject || len(result.EAPMessage) != 0 {
+		t.Errorf("got %+v, want EAP-MessageなしのReject", result)
+	}
+}
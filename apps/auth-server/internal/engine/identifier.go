@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+)
+
+// identifierField は送信するEAP-RequestのIdentifierをEAPContext保存用の値に変換する
+// Identifier=0も有効な値のため、未記録（0）と区別できるよう+1して保存する
+func identifierField(identifier uint8) int {
+	return int(identifier) + 1
+}
+
+// isStaleResponse は受信したEAP-ResponseのIdentifierが直前に送信したRequestと一致しないか判定する（RFC 3748 Section 4.1）
+// 一致しない応答は再送や遅延で届いた古いパケットとみなし、呼び出し元で破棄する
+func (e *EngineImpl) isStaleResponse(traceID string, eapCtx *session.EAPContext, eapMessage []byte) bool {
+	if eapCtx.EAPIdentifier == 0 {
+		return false
+	}
+	got := eap.GetEAPIdentifier(eapMessage)
+	if identifierField(got) == eapCtx.EAPIdentifier {
+		return false
+	}
+
+	slog.Warn("EAP Identifier不一致のため応答を破棄",
+		"event_id", "EAP_IDENTIFIER_MISMATCH",
+		"trace_id", traceID,
+		"expected", eapCtx.EAPIdentifier-1,
+		"received", got,
+		"stage", eapCtx.Stage,
+	)
+	return true
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIdentifierField(t *testing.T) {
+	if got := identifierField(0); got != 1 {
+		t.Errorf("identifierField(0): got %d, want 1", got)
+	}
+	if got := identifierField(255); got != 256 {
+		t.Errorf("identifierField(255): got %d, want 256", got)
+	}
+}
+
+func TestEngine_StaleIdentifier_Drop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.EAPIdentifier = identifierField(3)
+
+	// 前回Request（Identifier=2）への応答が遅れて到着 → 破棄（Delete・Rejectしない）
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionDrop {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionDrop)
+	}
+}
+
+func TestEngine_MatchingIdentifier_Processed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.EAPIdentifier = identifierField(2)
+
+	// Identifier一致 → 通常処理（MAC検証失敗でReject）
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, make([]byte, 16), testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_Challenge_RecordsIdentifier(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Cond(func(u map[string]any) bool {
+		return u["eap_identifier"] == identifierField(8)
+	})).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(7, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	if got := eap.GetEAPIdentifier(result.EAPMessage); got != 8 {
+		t.Errorf("Identifier: got %d, want 8", got)
+	}
+}
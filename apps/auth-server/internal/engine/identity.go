@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// peerIdentity はピアがEAP層で送信したIdentityを返す
+// RFC 3748 EAP-Response/IdentityのペイロードまたはAT_IDENTITYを正とし（鍵導出もこの値で行う）、
+// いずれも含まれない場合のみUser-Nameを使用する。User-Nameと異なる場合はEAP_IDENTITY_MISMATCHを出力する
+func (e *EngineImpl) peerIdentity(req *eap.Request, pkt *eapaka.Packet, eapType uint8) string {
+	var identity string
+	var found bool
+	if eapType == eap.EAPTypeIdentity {
+		identity, found = eap.GetIdentityPayload(req.EAPMessage)
+	} else if atIdentity, ok := eap.GetAttribute[*eapaka.AtIdentity](pkt); ok {
+		identity, found = atIdentity.Identity, true
+	}
+	if !found {
+		return req.UserName
+	}
+
+	if req.UserName != "" && req.UserName != identity {
+		slog.Warn("User-NameとEAP層のIdentityが不一致",
+			"event_id", "EAP_IDENTITY_MISMATCH",
+			"trace_id", req.TraceID,
+			"user_name_kind", identityKind(req.UserName),
+			"eap_identity_kind", identityKind(identity),
+		)
+	}
+	return identity
+}
+
+// identityKind はログ出力用にIdentityの種別を返す
+// 永続ID（IMSI）を含み得るため、Identityの値そのものはログに出力しない
+func identityKind(raw string) string {
+	if eap.IsAnonymousIdentity(raw) {
+		return "anonymous"
+	}
+	identity, err := eap.ParseIdentity(raw)
+	if err != nil {
+		return "unrecognized"
+	}
+	switch identity.Type {
+	case eap.IdentityTypePermanentAKA, eap.IdentityTypePermanentAKAPrime, eap.IdentityTypePermanentSIM:
+		return "permanent"
+	case eap.IdentityTypePseudonymAKA, eap.IdentityTypePseudonymAKAPrime, eap.IdentityTypePseudonymSIM:
+		return "pseudonym"
+	case eap.IdentityTypeReauthAKA, eap.IdentityTypeReauthAKAPrime, eap.IdentityTypeReauthSIM:
+		return "reauth"
+	case eap.IdentityTypeConcealed:
+		return "concealed"
+	default:
+		return "unrecognized"
+	}
+}
@@ -0,0 +1,488 @@
+package engine
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// buildAKAIdentityResponse はAT_IDENTITYを含むEAP-Response/AKA-Identityを構築する
+func buildAKAIdentityResponse(identifier uint8, eapType uint8, identity string) []byte {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eapType,
+		Subtype:    eapaka.SubtypeIdentity,
+		Attributes: []eapaka.Attribute{
+			&eapaka.AtIdentity{Identity: identity},
+		},
+	}
+	data, _ := pkt.Marshal()
+	return data
+}
+
+// identityReqUpdate はAKA-Identity Request送信時のEAPContext更新内容に一致するMatcherを返す
+// checkcode（AKA-Identityメッセージのハッシュ途中状態）は設定されていることのみ確認する
+func identityReqUpdate(sent eap.IdentityReqType) gomock.Matcher {
+	return gomock.Cond(func(u map[string]any) bool {
+		return len(u) == 4 &&
+			u["stage"] == string(eap.StateWaitingIdentity) &&
+			u["identity_req_sent"] == uint8(sent) &&
+			u["checkcode"] != "" &&
+			u["eap_identifier"] != 0
+	})
+}
+
+// makeWaitingIdentityContext はWAITING_IDENTITY状態のEAPContextを生成する
+func makeWaitingIdentityContext(sent eap.IdentityReqType) *session.EAPContext {
+	return &session.EAPContext{
+		Stage:           string(eap.StateWaitingIdentity),
+		EAPType:         eapaka.TypeAKA,
+		IdentityReqSent: uint8(sent),
+	}
+}
+
+// expectIdentityRequest はAKA-Identity Requestの要求属性を検証する
+func expectIdentityRequest(t *testing.T, result *eap.Result, want eap.IdentityReqType) {
+	t.Helper()
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	pkt, err := eapaka.Parse(result.EAPMessage)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	if pkt.Subtype != eapaka.SubtypeIdentity {
+		t.Fatalf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeIdentity)
+	}
+	var found bool
+	switch want {
+	case eap.IdentityReqAny:
+		_, found = eap.GetAttribute[*eapaka.AtAnyIdReq](pkt)
+	case eap.IdentityReqFullAuth:
+		_, found = eap.GetAttribute[*eapaka.AtFullauthIdReq](pkt)
+	case eap.IdentityReqPermanent:
+		_, found = eap.GetAttribute[*eapaka.AtPermanentIdReq](pkt)
+	}
+	if !found || len(pkt.Attributes) != 1 {
+		t.Errorf("%sのみを含むべき: %v", want, pkt.Attributes)
+	}
+}
+
+func TestEngine_AnonymousIdentity_AnyIDRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+	}{
+		{"匿名", "anonymous@realm"},
+		{"ユーザー部なし", "@realm"},
+		{"User-Nameなし", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+			mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+			mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqAny)).Return(nil)
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   tt.userName,
+				EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			expectIdentityRequest(t, result, eap.IdentityReqAny)
+		})
+	}
+}
+
+func TestEngine_UnknownReauthID_FullAuthIDRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqFullAuth)).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "4unknown@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqFullAuth)
+}
+
+func TestEngine_IdentityResponse_UnknownPseudonymAfterAny_PermanentIDRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqAny|eap.IdentityReqPermanent)).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "anonymous@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "2unknown@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqPermanent)
+}
+
+func TestEngine_IdentityResponse_UnknownReauthAfterAny_FullAuthIDRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	m.reauth.EXPECT().Get(gomock.Any(), "4unknown").Return(nil, session.ErrReauthNotFound)
+	m.ctxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqAny|eap.IdentityReqFullAuth)).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "anonymous@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "4unknown@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqFullAuth)
+}
+
+func TestEngine_IdentityResponse_AnonymousAgain_Escalates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, identityReqUpdate(eap.IdentityReqAny|eap.IdentityReqFullAuth)).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "anonymous@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqFullAuth)
+}
+
+func TestEngine_IdentityResponse_RequestsExhausted_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny|eap.IdentityReqFullAuth|eap.IdentityReqPermanent), nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(4, eapaka.TypeAKA, "anonymous@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_IdentityResponse_ReauthAfterFullAuthReq_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqFullAuth), nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "4reauth@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_IdentityResponse_PermanentViaATIdentity_Challenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "anonymous@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "0"+testIMSI+"@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	if pkt.Subtype != eapaka.SubtypeChallenge {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeChallenge)
+	}
+}
+
+func TestEngine_IdentityResponse_KnownPseudonym_Challenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, mockPseudoStore := newPseudonymTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	mockPseudoStore.EXPECT().Get(gomock.Any(), "2known").
+		Return(&session.PseudonymEntry{IMSI: testIMSI, EAPType: eapaka.TypeAKA}, nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "2known@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge || result.IMSI != testIMSI {
+		t.Errorf("Action/IMSI: got %v/%q, want %v/%q", result.Action, result.IMSI, eap.ActionChallenge, testIMSI)
+	}
+}
+
+func TestEngine_IdentityResponse_KnownReauthID_ReauthRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(makeReauthContext(0), nil)
+	m.ctxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    "new-trace-id",
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, testReauthUserName),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	if string(result.State) != testTraceID {
+		t.Errorf("State: got %q, want %q", result.State, testTraceID)
+	}
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	if pkt.Subtype != eapaka.SubtypeReauthentication {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeReauthentication)
+	}
+}
+
+func TestEngine_EAPIdentity_TakesPrecedenceOverUserName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	// NASがUser-Nameを書き換えた場合でも、鍵導出はピアがEAP層で送信したIdentityで行う
+	eapIdentity := "0" + testIMSI + "@wlan.mnc001.mcc001.3gppnetwork.org"
+	var updates map[string]any
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildEAPResponseIdentity(1, eapIdentity),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	if updates["identity"] != eapIdentity {
+		t.Errorf("identity: got %v, want %s", updates["identity"], eapIdentity)
+	}
+	keys := eapaka.DeriveKeysAKA(eapIdentity, testCK, testIK)
+	if updates["k_aut"] != hex.EncodeToString(keys.K_aut) {
+		t.Error("K_autがEAP層のIdentityで導出されていない")
+	}
+}
+
+func TestEngine_EAPIdentity_AnonymousPayloadIgnoresUserName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	// EAP層が匿名IDの場合、User-Nameに永続IDがあってもAKA-Identity要求を行う
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildEAPResponseIdentity(1, "anonymous@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectIdentityRequest(t, result, eap.IdentityReqAny)
+}
+
+func TestEngine_IdentityResponse_MissingATIdentity_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	// User-Nameは外側のIdentityのため、AT_IDENTITYの代わりには使用しない
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildIdentityEAPMessage(2, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_Resync_UsesContextIdentity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	// 再同期後の鍵導出はUser-Nameではなく初回Challengeで使用したIdentityで行う
+	identity := "0" + testIMSI + "@realm"
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, nil, testXRES, nil)
+	eapCtx.Identity = identity
+
+	var updates map[string]any
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(mockCtxStore, eap.StateResyncSent).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "anonymous@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildSyncFailureEAPMessage(2, eapaka.TypeAKA, make([]byte, 14)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	keys := eapaka.DeriveKeysAKA(identity, testCK, testIK)
+	if updates["k_aut"] != hex.EncodeToString(keys.K_aut) {
+		t.Error("K_autが初回ChallengeのIdentityで導出されていない")
+	}
+}
+
+func TestIdentityKind(t *testing.T) {
+	tests := []struct {
+		identity string
+		want     string
+	}{
+		{"0" + testIMSI + "@realm", "permanent"},
+		{"6" + testIMSI + "@realm", "permanent"},
+		{"1" + testIMSI + "@realm", "permanent"},
+		{"2pseudonym@realm", "pseudonym"},
+		{"7pseudonym@realm", "pseudonym"},
+		{"4reauth@realm", "reauth"},
+		{"anonymous@realm", "anonymous"},
+		{"@realm", "anonymous"},
+		{"user@example.com", "unrecognized"},
+		{testIMSI, "unrecognized"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.identity, func(t *testing.T) {
+			if got := identityKind(tt.identity); got != tt.want {
+				t.Errorf("identityKind(%q): got %q, want %q", tt.identity, got, tt.want)
+			}
+		})
+	}
+}
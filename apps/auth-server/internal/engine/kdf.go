@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/akaprime"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// handleKDFSelection はAKA'-Challengeに対するピアのKDF選択応答を処理する（RFC 9048 Section 3.2）
+// 提示一覧はKDF=1のみ（akaprime.KDFOffer）のため、再提示できる提示済みKDFとの重なりはない
+// KDF選択応答はいずれも先頭の再選択・未提示値・複数値のいずれかであり、認証失敗とする
+func (e *EngineImpl) handleKDFSelection(ctx context.Context, traceID string, eapCtx *session.EAPContext, pkt *eapaka.Packet) (*eap.Result, error) {
+	slog.Warn("KDFネゴシエーション失敗",
+		"event_id", "EAP_KDF_NEGOTIATION_FAILED",
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(eapCtx.IMSI),
+		"offer", akaprime.KDFOffer,
+		"response", eapaka.KdfValuesFromAttributes(pkt.Attributes),
+	)
+	_ = e.ctxStore.Delete(ctx, traceID)
+	return e.buildReject(pkt.Identifier + 1), nil
+}
+
+// clientRequiresAKAPrime はRADIUSクライアントの設定によりEAP-AKA'以外の方式（EAP-AKA・EAP-SIM）を拒否するかを判定する
+// 拒否する場合はtrueを返す。クライアント情報を取得できない場合はAKA'必須かを判定できないため拒否する（AKA'のネットワーク名決定と同じ）
+// 加入者単位の設定（ポリシーのrequire_aka_prime）は認証後のポリシー評価で判定する
+func (e *EngineImpl) clientRequiresAKAPrime(ctx context.Context, req *eap.Request, traceID, imsi string, eapType uint8) bool {
+	if e.clientStore == nil || eapType == eapaka.TypeAKAPrime {
+		return false
+	}
+
+	client, ok := e.lookupClient(ctx, req, traceID)
+	if !ok {
+		return true
+	}
+	if client == nil || !client.RequireAKAPrime {
+		return false
+	}
+
+	slog.Warn("EAP-AKA'必須のクライアントでEAP-AKA'以外の方式を拒否",
+		"event_id", "AUTH_AKA_PRIME_REQUIRED",
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(imsi),
+		"src_ip", req.SrcIP,
+		"eap_type", eapType,
+		"source", "client",
+	)
+	return true
+}
@@ -0,0 +1,339 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// buildKDFSelectionEAPMessage はAT_KDFのみを含むEAP-Response/AKA'-Challenge（KDF選択応答）を構築する
+func buildKDFSelectionEAPMessage(identifier uint8, kdfs ...uint16) []byte {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eapaka.TypeAKAPrime,
+		Subtype:    eapaka.SubtypeChallenge,
+	}
+	for _, kdf := range kdfs {
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtKdf{KDF: kdf})
+	}
+	data, _ := pkt.Marshal()
+	return data
+}
+
+func TestEngine_KDFSelection_PreferredKDF_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("6"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKAPrime, keys.K_aut, testXRES, keys.MSK)
+
+	// 提示一覧の先頭（KDF=1）を選択し直す応答はMAC検証失敗と同様に扱う
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "6" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildKDFSelectionEAPMessage(2, eapaka.KDFAKAPrimeWithCKIK),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_KDFSelection_NotOffered_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("6"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKAPrime, keys.K_aut, testXRES, keys.MSK)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "6" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildKDFSelectionEAPMessage(2, 2),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_AKAChallenge_IncludesBidding(t *testing.T) {
+	tests := []struct {
+		name    string
+		eapType uint8
+		prefix  string
+		bidding bool
+		want    bool
+	}{
+		{"EAP-AKA", eapaka.TypeAKA, "0", true, true},
+		{"EAP-AKA（無効化）", eapaka.TypeAKA, "0", false, false},
+		{"EAP-AKA'", eapaka.TypeAKAPrime, "6", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+			eng.cfg.BiddingEnabled = tt.bidding
+
+			mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+			mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+				Return(&vector.VectorResponse{
+					RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+				}, nil)
+			mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   tt.prefix + testIMSI + "@realm",
+				EAPMessage: buildIdentityEAPMessage(1, tt.eapType),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			pkt, err := eapaka.Parse(result.EAPMessage)
+			if err != nil {
+				t.Fatalf("パース失敗: %v", err)
+			}
+			_, found := eap.GetAttribute[*eapaka.AtBidding](pkt)
+			if found != tt.want {
+				t.Errorf("AT_BIDDING: got %v, want %v", found, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_RequireAKAPrime_Client(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *store.RadiusClient
+		clientErr error
+		wantVec   bool
+	}{
+		{"AKA'必須", &store.RadiusClient{RequireAKAPrime: true}, nil, false},
+		{"要求なし", &store.RadiusClient{}, nil, true},
+		{"未登録", nil, nil, true},
+		{"取得失敗（判定できないため拒否）", nil, store.ErrValkeyUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClientStore := mocks.NewMockClientStore(ctrl)
+			eng, m := newTestEngine(ctrl, newTestConfig(), WithClientStore(mockClientStore))
+			mockVector, mockCtxStore := m.vector, m.ctxStore
+
+			mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+			mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").Return(tt.client, tt.clientErr)
+			if tt.wantVec {
+				mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("vector unavailable"))
+			} else {
+				mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+			}
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				SrcIP:      "192.168.1.1",
+				UserName:   "0" + testIMSI + "@realm",
+				EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionReject {
+				t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+			}
+		})
+	}
+}
+
+func TestEngine_RequireAKAPrime_Client_AKAPrimeAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClientStore := mocks.NewMockClientStore(ctrl)
+	eng, m := newTestEngine(ctrl, newTestConfig(), WithClientStore(mockClientStore))
+	mockVector, mockCtxStore := m.vector, m.ctxStore
+
+	// EAP-AKA'ではAKA'必須設定による拒否を行わない（クライアント設定はネットワーク名の決定にのみ使用）
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").
+		Return(&store.RadiusClient{IP: "192.168.1.1", RequireAKAPrime: true}, nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		SrcIP:      "192.168.1.1",
+		UserName:   "6" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKAPrime),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+}
+
+func TestEngine_RequireAKAPrime_Policy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, mockPolicyStore, _ := newChallengeTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+
+	// 加入者ポリシーでAKA'必須 → 認証成功後もEAP-AKAは拒否（評価は行わない）
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
+		Return(&policy.Policy{Default: "allow", RequireAKAPrime: true}, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:       testTraceID,
+		NASIdentifier: testNASID,
+		CalledStation: "AA-BB-CC-DD-EE-FF:" + testSSID,
+		UserName:      "0" + testIMSI + "@realm",
+		State:         []byte(testTraceID),
+		EAPMessage:    buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_RequireAKAPrime_Client_SIM(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClientStore := mocks.NewMockClientStore(ctrl)
+	eng, _ := newTestEngine(ctrl, newTestConfig(), WithClientStore(mockClientStore))
+
+	// AKA'必須のクライアントではEAP-SIMも拒否（EAPコンテキストは作成しない）
+	mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").
+		Return(&store.RadiusClient{IP: "192.168.1.1", RequireAKAPrime: true}, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		SrcIP:      "192.168.1.1",
+		UserName:   testSIMIdentity,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_RequireAKAPrime_Policy_SIM(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, mockPolicyStore, _ := newChallengeTestEngine(ctrl)
+	triplets := makeTestTriplets(2)
+	eapCtx, keys := makeSIMChallengeContext(triplets)
+
+	// 加入者ポリシーでAKA'必須 → 認証成功後もEAP-SIMは拒否（評価は行わない）
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
+		Return(&policy.Policy{Default: "allow", RequireAKAPrime: true}, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:       testTraceID,
+		NASIdentifier: testNASID,
+		CalledStation: "AA-BB-CC-DD-EE-FF:" + testSSID,
+		UserName:      testSIMIdentity,
+		State:         []byte(testTraceID),
+		EAPMessage:    buildSIMChallengeResponse(t, 3, keys.K_aut, [][]byte{triplets[0].SRES, triplets[1].SRES}),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_RequireAKAPrime_Client_ERP(t *testing.T) {
+	tests := []struct {
+		name    string
+		eapType uint8
+	}{
+		{"EAP-AKA由来", eapaka.TypeAKA},
+		{"EAP-SIM由来", eap.EAPTypeSIM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cfg := newTestConfig()
+			cfg.ERPDomain = testERPDomain
+			mockERPStore := mocks.NewMockERPStore(ctrl)
+			mockClientStore := mocks.NewMockClientStore(ctrl)
+			eng, _ := newTestEngine(ctrl, cfg, WithERPStore(mockERPStore), WithClientStore(mockClientStore))
+
+			cs := eap.ERPCryptosuiteHMACSHA256_128
+			key := makeERPKey()
+			key.EAPType = tt.eapType
+
+			// AKA'必須のクライアントではAKA'以外で導出したrRKによる再認証を行わない
+			mockERPStore.EXPECT().Get(gomock.Any(), testERPKeyName).Return(key, nil)
+			mockERPStore.EXPECT().AdvanceSeq(gomock.Any(), testERPKeyName, uint16(5)).Return(nil)
+			mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").
+				Return(&store.RadiusClient{IP: "192.168.1.1", RequireAKAPrime: true}, nil)
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				SrcIP:      "192.168.1.1",
+				EAPMessage: buildERPInitiateMessage(3, 0, 5, cs, eap.DeriveRIK(testRRK, cs)),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionReject {
+				t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionReject)
+			}
+			assertERPFinish(t, result.EAPMessage, 3, true)
+		})
+	}
+}
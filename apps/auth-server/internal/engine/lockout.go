@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+)
+
+// imsiLocked はIMSIが認証失敗によりロックアウト中かどうかを判定する
+// ロックアウト状態を取得できない場合は認証を妨げない
+func (e *EngineImpl) imsiLocked(ctx context.Context, traceID, imsi string) bool {
+	if e.lockoutStore == nil {
+		return false
+	}
+
+	locked, err := e.lockoutStore.IsLocked(ctx, imsi)
+	if err != nil {
+		slog.Warn("ロックアウト状態取得失敗",
+			"event_id", "AUTH_LOCKOUT_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return false
+	}
+	if locked {
+		slog.Warn("ロックアウト中のIMSIを拒否",
+			"event_id", "AUTH_IMSI_LOCKED",
+			"trace_id", traceID,
+			"imsi", e.maskIMSI(imsi),
+		)
+	}
+	return locked
+}
+
+// recordAuthFailure は認証失敗（reasonは失敗時のevent_id）を計数し、閾値に達した場合はIMSIをロックアウトする
+// 計数に失敗しても認証結果には影響させない
+func (e *EngineImpl) recordAuthFailure(ctx context.Context, traceID, imsi, reason string) {
+	if e.lockoutStore == nil || imsi == "" {
+		return
+	}
+
+	failures, locked, err := e.lockoutStore.RecordFailure(ctx, imsi, reason)
+	if err != nil {
+		slog.Warn("認証失敗回数の記録失敗",
+			"event_id", "AUTH_LOCKOUT_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return
+	}
+	if locked {
+		slog.Warn("認証失敗回数超過によりIMSIをロックアウト",
+			"event_id", "AUTH_IMSI_LOCKOUT",
+			"trace_id", traceID,
+			"imsi", e.maskIMSI(imsi),
+			"failures", failures,
+			"reason", reason,
+		)
+	}
+}
+
+// clearAuthFailures は認証成功時にIMSIの失敗回数をリセットする
+// 散発的な失敗が成功を挟んで累積し、正常な加入者がロックアウトされることを防ぐ
+func (e *EngineImpl) clearAuthFailures(ctx context.Context, traceID, imsi string) {
+	if e.lockoutStore == nil || imsi == "" {
+		return
+	}
+
+	if err := e.lockoutStore.ClearFailures(ctx, imsi); err != nil {
+		slog.Warn("認証失敗回数のリセット失敗",
+			"event_id", "AUTH_LOCKOUT_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,302 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEngine_IMSILocked_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	// ロックアウト中はVector Gatewayを呼び出さない
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), testIMSI).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_IMSILockoutError_Continues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	// ロックアウト状態を取得できない場合は認証を妨げない
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), testIMSI).Return(false, errors.New("valkey down"))
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+}
+
+func TestEngine_ChallengeFailure_RecordsLockout(t *testing.T) {
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	wrongXRES := make([]byte, 8)
+	for i := range wrongXRES {
+		wrongXRES[i] = 0xFF
+	}
+
+	tests := []struct {
+		name    string
+		kAut    []byte // Challenge応答のMAC計算に使用するK_aut
+		ctxXRES []byte // コンテキストに保存するXRES
+		eventID string
+	}{
+		{"MAC不一致", make([]byte, 16), testXRES, "AUTH_MAC_INVALID"},
+		{"RES不一致", keys.K_aut, wrongXRES, "AUTH_RES_MISMATCH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+			mockLockout := mocks.NewMockLockoutStore(ctrl)
+			eng.lockoutStore = mockLockout
+
+			eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, tt.ctxXRES, keys.MSK)
+			mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+			mockLockout.EXPECT().RecordFailure(gomock.Any(), testIMSI, tt.eventID).Return(int64(5), true, nil)
+			mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   "0" + testIMSI + "@realm",
+				State:      []byte(testTraceID),
+				EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, tt.kAut, testXRES),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionReject {
+				t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+			}
+		})
+	}
+}
+
+func TestEngine_ResyncLimit_RecordsLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.ResyncCount = 32
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	// 計数に失敗しても認証結果には影響させない
+	mockLockout.EXPECT().RecordFailure(gomock.Any(), testIMSI, "AUTH_RESYNC_LIMIT").Return(int64(0), false, errors.New("valkey down"))
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildSyncFailureEAPMessage(2, eapaka.TypeAKA, make([]byte, 14)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_SIM_IMSILocked_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	// ロックアウト中はトリプレットを要求しない
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeSIMStartContext(testIMSI, testSIMIdentity, 0), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), testIMSI).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testSIMIdentity,
+		State:      []byte(testTraceID),
+		EAPMessage: buildSIMStartResponse(t, 2, ""),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_ChallengeSuccess_ClearsFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
+		Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	// 認証成功時は失敗回数をリセットする（失敗しても認証結果には影響させない）
+	mockLockout.EXPECT().ClearFailures(gomock.Any(), testIMSI).Return(errors.New("valkey down"))
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		SrcIP:      "192.168.1.1",
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_ReauthIdentity_IMSILocked_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	// ロックアウト中は保存済みの鍵があっても高速再認証を開始しない
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(makeReauthContext(1), nil)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), testIMSI).Return(true, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_ReauthSuccess_KeepsFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	// 高速再認証の成功では失敗回数をリセットしない（ClearFailuresを呼び出さない）
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(makeReauthSentContext(2, "4next"), nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(makeReauthContext(1), nil)
+	m.reauth.EXPECT().Delete(gomock.Any(), testReauthID).Return(nil)
+	m.reauth.EXPECT().Create(gomock.Any(), "4next", gomock.Any()).Return(nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildReauthResponseEAPMessage(t, 2, 2, false, testReauthKAut),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_ERPReauth_IMSILocked_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newERPTestEngine(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	eng.lockoutStore = mockLockout
+
+	// ロックアウト中はrRKによる再認証も失敗とする（SEQは進めない）
+	cs := eap.ERPCryptosuiteHMACSHA256_128
+	m.erp.EXPECT().Get(gomock.Any(), testERPKeyName).Return(makeERPKey(), nil)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), testIMSI).Return(true, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		EAPMessage: buildERPInitiateMessage(3, 0, 5, cs, eap.DeriveRIK(testRRK, cs)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+	assertERPFinish(t, result.EAPMessage, 3, true)
+}
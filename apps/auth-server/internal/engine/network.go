@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+)
+
+// resolveNetworkName はEAP-AKA'のAT_KDF_INPUTで使用するネットワーク名をリクエスト単位で決定する
+// 優先順位はRADIUSクライアント設定 → SSID（Called-Station-Id）→ Realm → 既定値（EAP_AKA_PRIME_NETWORK_NAME）
+// クライアント情報を取得できない場合は、誤ったネットワーク名での鍵導出を避けるためfalseを返す
+func (e *EngineImpl) resolveNetworkName(ctx context.Context, req *eap.Request, traceID, realm string) (string, bool) {
+	name, source := "", ""
+	if e.clientStore != nil {
+		client, ok := e.lookupClient(ctx, req, traceID)
+		if !ok {
+			return "", false
+		}
+		if client != nil && client.NetworkName != "" {
+			name, source = client.NetworkName, config.NetworkNameSourceClient
+		}
+	}
+	if name == "" {
+		name, source = e.cfg.NetworkNameFor(policy.ExtractSSID(req.CalledStation), realm)
+	}
+
+	slog.Debug("AKA'ネットワーク名決定",
+		"event_id", "EAP_NETWORK_NAME_RESOLVED",
+		"trace_id", traceID,
+		"network_name", name,
+		"source", source,
+	)
+	return name, true
+}
@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/akaprime"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// newNetworkNameTestEngine はClientStoreとネットワーク名対応表を設定したエンジンを生成する
+func newNetworkNameTestEngine(ctrl *gomock.Controller) (*EngineImpl, *mocks.MockVectorClient, *mocks.MockContextStore, *mocks.MockClientStore) {
+	mockClientStore := mocks.NewMockClientStore(ctrl)
+	cfg := newTestConfig()
+	cfg.SSIDNetworkNames = config.NameMap{"corp-wifi": "CORP"}
+	cfg.RealmNetworkNames = config.NameMap{"visited.example.org": "5G:mnc002.mcc001.3gppnetwork.org"}
+	eng, m := newTestEngine(ctrl, cfg, WithClientStore(mockClientStore))
+	return eng, m.vector, m.ctxStore, mockClientStore
+}
+
+// kdfInputOf はAKA'-ChallengeのAT_KDF_INPUTのネットワーク名を返す
+func kdfInputOf(t *testing.T, eapMsg []byte) string {
+	t.Helper()
+	pkt, err := eapaka.Parse(eapMsg)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	atKdfInput, found := eap.GetAttribute[*eapaka.AtKdfInput](pkt)
+	if !found {
+		t.Fatal("AT_KDF_INPUTが見つからない")
+	}
+	return atKdfInput.NetworkName
+}
+
+func TestEngine_NetworkName_Resolution(t *testing.T) {
+	tests := []struct {
+		name          string
+		client        *store.RadiusClient
+		calledStation string
+		realm         string
+		want          string
+	}{
+		{
+			name:          "クライアント設定が最優先",
+			client:        &store.RadiusClient{IP: "192.168.1.1", NetworkName: "CLIENT-NET"},
+			calledStation: "AA-BB-CC-DD-EE-FF:corp-wifi",
+			realm:         "visited.example.org",
+			want:          "CLIENT-NET",
+		},
+		{
+			name:          "SSIDの対応表（大文字小文字を区別しない）",
+			client:        &store.RadiusClient{IP: "192.168.1.1"},
+			calledStation: "AA-BB-CC-DD-EE-FF:Corp-WiFi",
+			realm:         "visited.example.org",
+			want:          "CORP",
+		},
+		{
+			name:          "Realmの対応表",
+			calledStation: "AA-BB-CC-DD-EE-FF:guest",
+			realm:         "visited.example.org",
+			want:          "5G:mnc002.mcc001.3gppnetwork.org",
+		},
+		{
+			name:          "いずれにも該当しない場合は既定値",
+			calledStation: "AA-BB-CC-DD-EE-FF:guest",
+			realm:         "realm",
+			want:          testNetworkName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, mockVector, mockCtxStore, mockClientStore := newNetworkNameTestEngine(ctrl)
+			userName := "6" + testIMSI + "@" + tt.realm
+
+			mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+			mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").Return(tt.client, nil)
+			mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+				Return(&vector.VectorResponse{
+					RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+				}, nil)
+
+			var updates map[string]any
+			mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+					updates = u
+					return nil
+				})
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:       testTraceID,
+				SrcIP:         "192.168.1.1",
+				CalledStation: tt.calledStation,
+				UserName:      userName,
+				EAPMessage:    buildIdentityEAPMessage(1, eapaka.TypeAKAPrime),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionChallenge {
+				t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+			}
+			if got := kdfInputOf(t, result.EAPMessage); got != tt.want {
+				t.Errorf("AT_KDF_INPUT: got %q, want %q", got, tt.want)
+			}
+			if updates["network_name"] != tt.want {
+				t.Errorf("network_name: got %v, want %q", updates["network_name"], tt.want)
+			}
+
+			// 鍵導出にも同じネットワーク名を使用すること
+			keys, err := akaprime.DeriveAllKeys(userName, testCK, testIK, testAUTN, tt.want)
+			if err != nil {
+				t.Fatalf("鍵導出失敗: %v", err)
+			}
+			if updates["k_aut"] != hex.EncodeToString(keys.K_aut) {
+				t.Error("K_autが解決したネットワーク名で導出されていない")
+			}
+		})
+	}
+}
+
+func TestEngine_NetworkName_ClientLookupError_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockClientStore := newNetworkNameTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockClientStore.EXPECT().GetClient(gomock.Any(), "192.168.1.1").Return(nil, errors.New("connection refused"))
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		SrcIP:      "192.168.1.1",
+		UserName:   "6" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKAPrime),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_NetworkName_ReusedFromContext(t *testing.T) {
+	const stored = "STORED-NET"
+
+	t.Run("再同期", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// ClientStoreは参照しない（保存済みのネットワーク名を使用する）
+		eng, mockVector, mockCtxStore, _ := newNetworkNameTestEngine(ctrl)
+		eapCtx := &session.EAPContext{
+			IMSI:        testIMSI,
+			Stage:       string(eap.StateChallengeSent),
+			EAPType:     eapaka.TypeAKAPrime,
+			RAND:        hex.EncodeToString(testRAND),
+			NetworkName: stored,
+		}
+
+		mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+		expectTransition(mockCtxStore, eap.StateResyncSent).Return(nil)
+		mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+			Return(&vector.VectorResponse{
+				RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+			}, nil)
+		mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+		result, err := eng.Process(context.Background(), &eap.Request{
+			TraceID:       testTraceID,
+			SrcIP:         "192.168.1.1",
+			CalledStation: "AA-BB-CC-DD-EE-FF:corp-wifi",
+			UserName:      "6" + testIMSI + "@realm",
+			State:         []byte(testTraceID),
+			EAPMessage:    buildSyncFailureEAPMessage(2, eapaka.TypeAKAPrime, make([]byte, 14)),
+		})
+		if err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+		if result.Action != eap.ActionChallenge {
+			t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+		}
+		if got := kdfInputOf(t, result.EAPMessage); got != stored {
+			t.Errorf("AT_KDF_INPUT: got %q, want %q", got, stored)
+		}
+	})
+
+}
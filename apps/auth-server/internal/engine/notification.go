@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// sendNotification は認証結果をEAP-Request/AKA-Notificationで通知する（RFC 4187 Section 6.2）
+// 通知コードと追加フィールド（Accept時のVLAN等）をEAPContextに保存し、Notification応答を待つ
+func (e *EngineImpl) sendNotification(ctx context.Context, traceID string, eapCtx *session.EAPContext, identifier uint8, code uint16, extra map[string]any) *eap.Result {
+	maskedIMSI := e.maskIMSI(eapCtx.IMSI)
+
+	if _, err := eap.ValidateTransition(eap.EAPState(eapCtx.Stage), eap.EventResultNotify); err != nil {
+		slog.Error("状態遷移失敗",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(identifier + 1)
+	}
+
+	params := &eap.NotificationParams{
+		Identifier: identifier + 1,
+		EAPType:    eapCtx.EAPType,
+		Code:       code,
+	}
+	if eap.IsNotificationProtected(code) {
+		kAut, err := hex.DecodeString(eapCtx.Kaut)
+		if err != nil {
+			slog.Error("K_aut復元失敗",
+				"event_id", "EAP_CTX_DECODE_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+			_ = e.ctxStore.Delete(ctx, traceID)
+			return e.buildReject(identifier + 1)
+		}
+		params.KAut = kAut
+
+		// 高速再認証時は暗号化したAT_COUNTERを含める（RFC 4187 Section 9.10）
+		if eapCtx.ReauthID != "" {
+			kEncr, err := hex.DecodeString(eapCtx.KEncr)
+			if err != nil {
+				slog.Error("K_encr復元失敗",
+					"event_id", "EAP_CTX_DECODE_ERR",
+					"trace_id", traceID,
+					"error", err,
+				)
+				_ = e.ctxStore.Delete(ctx, traceID)
+				return e.buildReject(identifier + 1)
+			}
+			params.KEncr = kEncr
+			params.Counter = uint16(eapCtx.Counter)
+		}
+	}
+
+	notifyMsg, err := eap.BuildNotificationRequest(params)
+	if err != nil {
+		slog.Error("Notification構築失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(identifier + 1)
+	}
+
+	updates := map[string]any{
+		"stage":          string(eap.StateNotificationSent),
+		"notification":   int(code),
+		"eap_identifier": identifierField(identifier + 1),
+	}
+	for k, v := range extra {
+		updates[k] = v
+	}
+	if res := e.transitionContext(ctx, traceID, eapCtx, identifier, updates); res != nil {
+		if res.Action != eap.ActionDrop {
+			_ = e.ctxStore.Delete(ctx, traceID)
+		}
+		return res
+	}
+
+	slog.Info("Notification送信",
+		"event_id", "EAP_NOTIFICATION_SENT",
+		"trace_id", traceID,
+		"imsi", maskedIMSI,
+		"notification", code,
+	)
+
+	return &eap.Result{
+		Action:     eap.ActionChallenge,
+		EAPMessage: notifyMsg,
+		State:      []byte(traceID),
+		IMSI:       eapCtx.IMSI,
+	}
+}
+
+// handleNotificationResponse はEAP-Response/AKA-Notificationを検証し、通知済みの結果に従ってAccept/Rejectを返す
+func (e *EngineImpl) handleNotificationResponse(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, pkt *eapaka.Packet) (*eap.Result, error) {
+	maskedIMSI := e.maskIMSI(eapCtx.IMSI)
+
+	// 状態遷移検証
+	if eap.EAPState(eapCtx.Stage) != eap.StateNotificationSent {
+		slog.Warn("不正な状態でNotification応答受信",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"stage", eapCtx.Stage,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	code := uint16(eapCtx.Notification)
+	if eap.IsNotificationProtected(code) {
+		kAut, err1 := hex.DecodeString(eapCtx.Kaut)
+		kEncr, err2 := hex.DecodeString(eapCtx.KEncr)
+		if err := errors.Join(err1, err2); err != nil {
+			slog.Error("Notificationコンテキスト復元失敗",
+				"event_id", "EAP_CTX_DECODE_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+			_ = e.ctxStore.Delete(ctx, traceID)
+			return e.buildReject(pkt.Identifier + 1), nil
+		}
+
+		var counter uint16
+		if eapCtx.ReauthID != "" {
+			counter = uint16(eapCtx.Counter)
+		}
+		if err := eap.VerifyNotificationResponse(pkt, kAut, kEncr, counter); err != nil {
+			eventID := "AUTH_VERIFY_FAIL"
+			if errors.Is(err, eap.ErrMACInvalid) {
+				eventID = "AUTH_MAC_INVALID"
+			} else if errors.Is(err, eap.ErrCounterMismatch) {
+				eventID = "AUTH_REAUTH_COUNTER_INVALID"
+			}
+			slog.Warn("Notification応答検証失敗",
+				"event_id", eventID,
+				"trace_id", traceID,
+				"imsi", maskedIMSI,
+				"error", err,
+			)
+			if eventID == "AUTH_MAC_INVALID" {
+				e.recordAuthFailure(ctx, traceID, eapCtx.IMSI, eventID)
+			}
+			_ = e.ctxStore.Delete(ctx, traceID)
+			return e.buildReject(pkt.Identifier + 1), nil
+		}
+	}
+
+	// 失敗通知への応答 → Reject
+	if !eap.IsNotificationSuccess(code) {
+		slog.Info("失敗通知の応答受信",
+			"event_id", "EAP_NOTIFICATION_FAILURE_ACK",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"notification", code,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	msk, err := hex.DecodeString(eapCtx.MSK)
+	if err != nil {
+		slog.Error("MSK復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	replyAttrs, err := decodeReplyAttributes(eapCtx.ReplyAttributes)
+	if err != nil {
+		slog.Error("応答属性復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	return e.acceptAuthentication(ctx, req, traceID, eapCtx, pkt.Identifier, msk, authorization{
+		vlanID:          eapCtx.VlanID,
+		sessionTimeout:  eapCtx.SessionTimeout,
+		maxSessions:     eapCtx.MaxSessions,
+		replyAttributes: replyAttrs,
+	}), nil
+}
@@ -0,0 +1,581 @@
+package engine
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// newResultIndTestEngine はAT_RESULT_INDを有効化したエンジンとモックを生成する
+func newResultIndTestEngine(ctrl *gomock.Controller) (*EngineImpl, *reauthTestMocks) {
+	cfg := newTestConfig()
+	cfg.ResultIndEnabled = true
+	eng, m := newTestEngine(ctrl, cfg)
+	return eng, &reauthTestMocks{engineTestMocks: m, reauth: mocks.NewMockReauthStore(ctrl)}
+}
+
+// buildResultIndChallengeResponse はAT_RESULT_IND付きのEAP-Response/AKA-Challengeを構築する
+func buildResultIndChallengeResponse(identifier uint8, kAut, xres []byte) []byte {
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeChallenge,
+		Attributes: []eapaka.Attribute{
+			&eapaka.AtRes{Res: xres},
+			&eapaka.AtResultInd{},
+			&eapaka.AtMac{MAC: make([]byte, 16)},
+		},
+	}
+	_ = pkt.CalculateAndSetMac(kAut)
+	data, _ := pkt.Marshal()
+	return data
+}
+
+// buildNotificationResponse はピア側のEAP-Response/AKA-Notificationを構築する
+// counterが0以外の場合は暗号化したAT_COUNTERを含める
+func buildNotificationResponse(t *testing.T, identifier uint8, kAut, kEncr []byte, counter uint16) []byte {
+	t.Helper()
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeNotification,
+	}
+	if counter != 0 {
+		atIv, atEncr, err := eap.EncryptAttributes(kEncr, []eapaka.Attribute{&eapaka.AtCounter{Counter: counter}})
+		if err != nil {
+			t.Fatalf("暗号化失敗: %v", err)
+		}
+		pkt.Attributes = append(pkt.Attributes, atIv, atEncr)
+	}
+	pkt.Attributes = append(pkt.Attributes, &eapaka.AtMac{MAC: make([]byte, 16)})
+	if err := pkt.CalculateAndSetMac(kAut); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+	data, _ := pkt.Marshal()
+	return data
+}
+
+// parseNotification はEAP-Request/AKA-Notificationを解析してAT_NOTIFICATIONを返す
+func parseNotification(t *testing.T, msg []byte) (*eapaka.Packet, *eapaka.AtNotification) {
+	t.Helper()
+	pkt, err := eapaka.Parse(msg)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	if pkt.Subtype != eapaka.SubtypeNotification {
+		t.Fatalf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeNotification)
+	}
+	notif, ok := eap.GetAttribute[*eapaka.AtNotification](pkt)
+	if !ok {
+		t.Fatal("AT_NOTIFICATIONが見つからない")
+	}
+	return pkt, notif
+}
+
+// makeNotificationSentContext はNOTIFICATION_SENT状態のEAPContextを生成する
+func makeNotificationSentContext(code uint16, kAut, msk []byte) *session.EAPContext {
+	return &session.EAPContext{
+		IMSI:           testIMSI,
+		Stage:          string(eap.StateNotificationSent),
+		EAPType:        eapaka.TypeAKA,
+		Kaut:           hex.EncodeToString(kAut),
+		MSK:            hex.EncodeToString(msk),
+		Notification:   int(code),
+		VlanID:         "100",
+		SessionTimeout: 3600,
+	}
+}
+
+func TestEngine_ResultInd_ChallengeIncludesResultInd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	m.ctxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	m.vector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	m.ctxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	if !eap.HasResultInd(pkt) {
+		t.Error("ChallengeにAT_RESULT_INDが含まれていない")
+	}
+}
+
+func TestEngine_ResultInd_SuccessNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+
+	var updates map[string]any
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
+		Return(&policy.EvaluationResult{
+			Allowed: true,
+			MatchedRule: &policy.PolicyRule{
+				VlanID:          "100",
+				SessionTimeout:  3600,
+				ReplyAttributes: []policy.ReplyAttribute{{Name: "Filter-Id", Value: "staff"}},
+			},
+		})
+	m.ctxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, u map[string]any) error {
+			updates = u
+			return nil
+		})
+	// Notification応答までセッションは作成しない
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:       testTraceID,
+		NASIdentifier: testNASID,
+		CalledStation: "AA-BB-CC-DD-EE-FF:" + testSSID,
+		UserName:      "0" + testIMSI + "@realm",
+		State:         []byte(testTraceID),
+		EAPMessage:    buildResultIndChallengeResponse(2, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, notif := parseNotification(t, result.EAPMessage)
+	if pkt.Identifier != 3 {
+		t.Errorf("Identifier: got %d, want 3", pkt.Identifier)
+	}
+	if !notif.S || notif.P {
+		t.Errorf("S/Pビットが不正: S=%v, P=%v", notif.S, notif.P)
+	}
+	if err := eap.VerifyMACWithExtra(pkt, keys.K_aut, nil); err != nil {
+		t.Errorf("AT_MAC検証失敗: %v", err)
+	}
+
+	if updates["stage"] != string(eap.StateNotificationSent) {
+		t.Errorf("stage: got %v, want %v", updates["stage"], eap.StateNotificationSent)
+	}
+	if updates["notification"] != int(eap.NotificationSuccess) {
+		t.Errorf("notification: got %v, want %d", updates["notification"], eap.NotificationSuccess)
+	}
+	if updates["vlan_id"] != "100" || updates["session_timeout"] != 3600 {
+		t.Errorf("VLAN/Timeoutが保存されていない: %v", updates)
+	}
+	if updates["reply_attributes"] != `[{"name":"Filter-Id","value":"staff"}]` {
+		t.Errorf("reply_attributes: got %v", updates["reply_attributes"])
+	}
+}
+
+func TestEngine_ResultInd_PolicyDenied_FailureNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+
+	var updates map[string]any
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "deny"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
+		Return(&policy.EvaluationResult{Allowed: false, DenyReason: "no matching rule"})
+	m.ctxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:       testTraceID,
+		NASIdentifier: testNASID,
+		CalledStation: "AA-BB-CC-DD-EE-FF:" + testSSID,
+		UserName:      "0" + testIMSI + "@realm",
+		State:         []byte(testTraceID),
+		EAPMessage:    buildResultIndChallengeResponse(2, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, notif := parseNotification(t, result.EAPMessage)
+	if notif.S || notif.P || notif.Code != 0 {
+		t.Errorf("General failure after authenticationではない: %+v", notif)
+	}
+	if err := eap.VerifyMACWithExtra(pkt, keys.K_aut, nil); err != nil {
+		t.Errorf("AT_MAC検証失敗: %v", err)
+	}
+	if updates["notification"] != int(eap.NotificationGeneralFailureAfterAuth) {
+		t.Errorf("notification: got %v, want %d", updates["notification"], eap.NotificationGeneralFailureAfterAuth)
+	}
+}
+
+func TestEngine_ResultInd_RejectRule_FailureNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	// rejectルールの拒否理由が失敗通知の通知コードになる
+	req, eapCtx, kAut := challengeSuccessRequest(true)
+	rule := &policy.PolicyRule{NasID: testNASID, AllowedSSIDs: []string{testSSID}, Action: policy.RuleActionReject, RejectReason: policy.RejectReasonNotSubscribed}
+	var updates map[string]any
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
+		Return(&policy.EvaluationResult{Allowed: false, MatchedRule: rule, DenyReason: "matched reject rule", RejectReason: rule.RejectReason})
+	m.ctxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, notif := parseNotification(t, result.EAPMessage)
+	if notif.S || notif.P || notif.Code != eap.NotificationNotSubscribed {
+		t.Errorf("User has not subscribed to the requested serviceではない: %+v", notif)
+	}
+	if err := eap.VerifyMACWithExtra(pkt, kAut, nil); err != nil {
+		t.Errorf("AT_MAC検証失敗: %v", err)
+	}
+	if updates["notification"] != int(eap.NotificationNotSubscribed) {
+		t.Errorf("notification: got %v, want %d", updates["notification"], eap.NotificationNotSubscribed)
+	}
+}
+
+func TestRejectNotificationCode(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   uint16
+	}{
+		{"", eap.NotificationGeneralFailureAfterAuth},
+		{policy.RejectReasonGeneralFailure, eap.NotificationGeneralFailureAfterAuth},
+		{policy.RejectReasonTemporarilyDenied, eap.NotificationTemporarilyDenied},
+		{policy.RejectReasonNotSubscribed, eap.NotificationNotSubscribed},
+		{"unknown", eap.NotificationGeneralFailureAfterAuth},
+	}
+	for _, tt := range tests {
+		if got := rejectNotificationCode(tt.reason); got != tt.want {
+			t.Errorf("rejectNotificationCode(%q) = %d, want %d", tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestEngine_ResultInd_PeerWithoutResultInd_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_NotificationResponse_Success_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeNotificationSentContext(eap.NotificationSuccess, keys.K_aut, keys.MSK)
+	eapCtx.ReplyAttributes = `[{"name":"WISPr-Bandwidth-Max-Up","vendor":"WISPr","value":"1000000"}]`
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		SrcIP:      "192.168.1.1",
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildNotificationResponse(t, 3, keys.K_aut, nil, 0),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+	if result.VlanID != "100" || result.SessionTimeout != 3600 {
+		t.Errorf("VLAN/Timeout: got %q/%d", result.VlanID, result.SessionTimeout)
+	}
+	want := policy.ReplyAttribute{Name: "WISPr-Bandwidth-Max-Up", Vendor: "WISPr", Value: "1000000"}
+	if len(result.ReplyAttributes) != 1 || result.ReplyAttributes[0] != want {
+		t.Errorf("ReplyAttributes: got %+v", result.ReplyAttributes)
+	}
+	if len(result.MSK) == 0 {
+		t.Error("MSKが空")
+	}
+	if result.EAPMessage[0] != eapaka.CodeSuccess || result.EAPMessage[1] != 4 {
+		t.Errorf("EAP-Successが不正: %x", result.EAPMessage)
+	}
+}
+
+func TestEngine_NotificationResponse_ReplyAttributesInvalid_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeNotificationSentContext(eap.NotificationSuccess, keys.K_aut, keys.MSK)
+	eapCtx.ReplyAttributes = "{broken"
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildNotificationResponse(t, 3, keys.K_aut, nil, 0),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_NotificationResponse_FailureAck_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeNotificationSentContext(eap.NotificationGeneralFailureAfterAuth, keys.K_aut, keys.MSK)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildNotificationResponse(t, 3, keys.K_aut, nil, 0),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_NotificationResponse_MACInvalid_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeNotificationSentContext(eap.NotificationSuccess, keys.K_aut, keys.MSK)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	wrongKey := make([]byte, 16)
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildNotificationResponse(t, 3, wrongKey, nil, 0),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_NotificationResponse_WrongStage_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildNotificationResponse(t, 2, keys.K_aut, nil, 0),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_ReauthResponse_ResultInd_NotificationWithCounter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+	eng.cfg.ResultIndEnabled = true
+
+	eapCtx := makeReauthSentContext(2, "4next")
+
+	// AT_RESULT_IND付きの再認証応答
+	atIv, atEncr, err := eap.EncryptAttributes(testReauthKEncr, []eapaka.Attribute{&eapaka.AtCounter{Counter: 2}})
+	if err != nil {
+		t.Fatalf("暗号化失敗: %v", err)
+	}
+	resp := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: 2,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeReauthentication,
+		Attributes: []eapaka.Attribute{atIv, atEncr, &eapaka.AtResultInd{}, &eapaka.AtMac{MAC: make([]byte, 16)}},
+	}
+	if err := eap.CalculateMACWithExtra(resp, testReauthKAut, testNonceS); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+	respMsg, _ := resp.Marshal()
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true})
+	m.ctxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: respMsg,
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, notif := parseNotification(t, result.EAPMessage)
+	if !notif.S {
+		t.Error("成功通知ではない")
+	}
+	notifIv, _ := eap.GetAttribute[*eapaka.AtIv](pkt)
+	notifEncr, _ := eap.GetAttribute[*eapaka.AtEncrData](pkt)
+	attrs, err := eap.DecryptAttributes(testReauthKEncr, notifIv, notifEncr)
+	if err != nil {
+		t.Fatalf("復号失敗: %v", err)
+	}
+	found := false
+	for _, a := range attrs {
+		if c, ok := a.(*eapaka.AtCounter); ok && c.Counter == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("暗号化されたAT_COUNTERが含まれていない")
+	}
+}
+
+func TestEngine_NotificationResponse_Reauth_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+	eng.cfg.ResultIndEnabled = true
+
+	eapCtx := makeReauthSentContext(2, "4next")
+	eapCtx.Stage = string(eap.StateNotificationSent)
+	eapCtx.Notification = int(eap.NotificationSuccess)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(makeReauthContext(1), nil)
+	m.reauth.EXPECT().Delete(gomock.Any(), testReauthID).Return(nil)
+	m.reauth.EXPECT().Create(gomock.Any(), "4next", gomock.Any()).Return(nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildNotificationResponse(t, 3, testReauthKAut, testReauthKEncr, 2),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+)
+
+// acquireVector はEAP-AKA/AKA'用の認証ベクターを取得する
+// ベクターキャッシュが有効な場合はキャッシュを優先し、キャッシュが空であれば
+// VECTOR_PREFETCH_COUNT件をまとめて取得して残りをキャッシュする
+// 再同期時はキャッシュ済みのベクター（旧SQN系列）を破棄してから取得する
+// キャッシュの障害は認証結果に影響させない（Vector Gatewayから都度取得する）
+func (e *EngineImpl) acquireVector(ctx context.Context, traceID, imsi string, resync *vector.ResyncInfo) (*vector.VectorResponse, error) {
+	vCtx := vector.WithTraceID(ctx, traceID)
+	if e.vectorCache == nil {
+		return e.vectorClient.GetVector(vCtx, &vector.VectorRequest{IMSI: imsi, ResyncInfo: resync})
+	}
+
+	if resync != nil {
+		if err := e.vectorCache.Flush(ctx, imsi); err != nil {
+			slog.Warn("ベクターキャッシュ破棄失敗",
+				"event_id", "VECTOR_CACHE_ERR",
+				"trace_id", traceID,
+				"imsi", e.maskIMSI(imsi),
+				"error", err,
+			)
+		}
+	} else {
+		q, err := e.vectorCache.Pop(ctx, imsi)
+		if err != nil {
+			slog.Warn("ベクターキャッシュ取得失敗",
+				"event_id", "VECTOR_CACHE_ERR",
+				"trace_id", traceID,
+				"imsi", e.maskIMSI(imsi),
+				"error", err,
+			)
+		} else if q != nil {
+			slog.Debug("キャッシュ済みベクター使用",
+				"event_id", "VECTOR_CACHE_HIT",
+				"trace_id", traceID,
+				"imsi", e.maskIMSI(imsi),
+			)
+			return &vector.VectorResponse{
+				RAND: q.RAND,
+				AUTN: q.AUTN,
+				XRES: q.XRES,
+				CK:   q.CK,
+				IK:   q.IK,
+				SQN:  q.SQN,
+			}, nil
+		}
+	}
+
+	resp, err := e.vectorClient.GetVector(vCtx, &vector.VectorRequest{
+		IMSI:       imsi,
+		Count:      e.cfg.VectorPrefetchCount,
+		ResyncInfo: resync,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 使用するベクターのSQNを記録し、残りをキャッシュする
+	if err := e.vectorCache.Store(ctx, imsi, resp.SQN, resp.Prefetched); err != nil {
+		slog.Warn("ベクターキャッシュ保存失敗",
+			"event_id", "VECTOR_CACHE_ERR",
+			"trace_id", traceID,
+			"imsi", e.maskIMSI(imsi),
+			"error", err,
+		)
+	}
+	return resp, nil
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	"go.uber.org/mock/gomock"
+)
+
+func newPrefetchTestEngine(ctrl *gomock.Controller) (*EngineImpl, *mocks.MockVectorClient, *mocks.MockVectorCache) {
+	mockCache := mocks.NewMockVectorCache(ctrl)
+	cfg := newTestConfig()
+	cfg.VectorPrefetchCount = 4
+	eng, m := newTestEngine(ctrl, cfg, WithVectorCache(mockCache))
+	return eng, m.vector, mockCache
+}
+
+func TestAcquireVector_CacheHit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCache := newPrefetchTestEngine(ctrl)
+
+	// キャッシュにベクターがあればVector Gatewayを呼び出さない
+	mockCache.EXPECT().Pop(gomock.Any(), testIMSI).Return(&vector.Quintet{
+		RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK, SQN: 0x60,
+	}, nil)
+
+	resp, err := eng.acquireVector(context.Background(), testTraceID, testIMSI, nil)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if !bytes.Equal(resp.RAND, testRAND) || !bytes.Equal(resp.IK, testIK) || resp.SQN != 0x60 {
+		t.Errorf("キャッシュ済みベクターが返されていない: %+v", resp)
+	}
+}
+
+func TestAcquireVector_CacheMiss_Prefetch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCache := newPrefetchTestEngine(ctrl)
+
+	prefetched := []vector.Quintet{{SQN: 0x60}, {SQN: 0x80}, {SQN: 0xa0}}
+	mockCache.EXPECT().Pop(gomock.Any(), testIMSI).Return(nil, nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI, Count: 4}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+			SQN: 0x40, Prefetched: prefetched,
+		}, nil)
+	mockCache.EXPECT().Store(gomock.Any(), testIMSI, uint64(0x40), prefetched).Return(nil)
+
+	resp, err := eng.acquireVector(context.Background(), testTraceID, testIMSI, nil)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if resp.SQN != 0x40 {
+		t.Errorf("SQN: got %x, want 40", resp.SQN)
+	}
+}
+
+func TestAcquireVector_CacheError_Fallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCache := newPrefetchTestEngine(ctrl)
+
+	// キャッシュ障害は認証結果に影響させない
+	mockCache.EXPECT().Pop(gomock.Any(), testIMSI).Return(nil, errors.New("valkey down"))
+	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(&vector.VectorResponse{RAND: testRAND, SQN: 0x40}, nil)
+	mockCache.EXPECT().Store(gomock.Any(), testIMSI, uint64(0x40), gomock.Any()).Return(errors.New("valkey down"))
+
+	if _, err := eng.acquireVector(context.Background(), testTraceID, testIMSI, nil); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+}
+
+func TestAcquireVector_Resync_Flush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCache := newPrefetchTestEngine(ctrl)
+
+	// 再同期時はキャッシュを使用せず、破棄してから取得する
+	resync := &vector.ResyncInfo{RAND: "00", AUTS: "11"}
+	gomock.InOrder(
+		mockCache.EXPECT().Flush(gomock.Any(), testIMSI).Return(nil),
+		mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI, Count: 4, ResyncInfo: resync}).
+			Return(&vector.VectorResponse{RAND: testRAND, SQN: 0x20}, nil),
+		mockCache.EXPECT().Store(gomock.Any(), testIMSI, uint64(0x20), gomock.Any()).Return(nil),
+	)
+
+	if _, err := eng.acquireVector(context.Background(), testTraceID, testIMSI, resync); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+}
+
+func TestAcquireVector_VectorError_NoStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCache := newPrefetchTestEngine(ctrl)
+
+	mockCache.EXPECT().Pop(gomock.Any(), testIMSI).Return(nil, nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).Return(nil, vector.ErrCircuitOpen)
+
+	if _, err := eng.acquireVector(context.Background(), testTraceID, testIMSI, nil); !errors.Is(err, vector.ErrCircuitOpen) {
+		t.Errorf("got %v, want ErrCircuitOpen", err)
+	}
+}
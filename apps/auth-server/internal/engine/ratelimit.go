@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+)
+
+// imsiRateLimited はIMSI単位のベクター要求レートを超過しているかどうかを判定する
+// 匿名・SUCI・EAP層のみのIdentityも含め、永続IDの確定後に判定する
+// レート制限の状態を取得できない場合は認証を妨げない
+func (e *EngineImpl) imsiRateLimited(ctx context.Context, req *eap.Request, traceID, imsi string) bool {
+	if e.rateLimiter == nil || imsi == "" {
+		return false
+	}
+
+	allowed, err := e.rateLimiter.Allow(ctx, session.RateLimitIMSI, imsi)
+	if err != nil {
+		slog.Warn("レート制限状態の取得失敗",
+			"event_id", "RATE_LIMIT_ERR",
+			"trace_id", traceID,
+			"src_ip", req.SrcIP,
+			"limit", string(session.RateLimitIMSI),
+			"error", err,
+		)
+		return false
+	}
+	if !allowed {
+		slog.Warn("要求レート超過のためAccess-Requestを破棄",
+			"event_id", "RATE_LIMITED",
+			"trace_id", traceID,
+			"src_ip", req.SrcIP,
+			"limit", string(session.RateLimitIMSI),
+			"imsi", e.maskIMSI(imsi),
+		)
+	}
+	return !allowed
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEngine_IMSIRateLimited_Drop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLimiter := mocks.NewMockRateLimiter(ctrl)
+	eng.rateLimiter = mockLimiter
+
+	// レート超過時はVector Gatewayを呼び出さずに破棄する
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitIMSI, testIMSI).Return(false, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionDrop {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionDrop)
+	}
+}
+
+func TestEngine_IMSIRateLimited_AnonymousOuterIdentity_Drop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLimiter := mocks.NewMockRateLimiter(ctrl)
+	eng.rateLimiter = mockLimiter
+
+	// 外側Identityが匿名の場合も、AKA-Identity交換で確定した永続IDのIMSIで判定する
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqAny), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitIMSI, testIMSI).Return(false, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "anonymous@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "0"+testIMSI+"@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionDrop {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionDrop)
+	}
+}
+
+func TestEngine_IMSIRateLimitError_Continues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLimiter := mocks.NewMockRateLimiter(ctrl)
+	eng.rateLimiter = mockLimiter
+
+	// レート制限の状態を取得できない場合は認証を妨げない
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitIMSI, testIMSI).Return(true, errors.New("valkey down"))
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+}
+
+func TestEngine_SIM_IMSIRateLimited_Drop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	mockLimiter := mocks.NewMockRateLimiter(ctrl)
+	eng.rateLimiter = mockLimiter
+
+	// EAP-SIMもトリプレット要求の前に判定する
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeSIMStartContext(testIMSI, testSIMIdentity, 0), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitIMSI, testIMSI).Return(false, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testSIMIdentity,
+		State:      []byte(testTraceID),
+		EAPMessage: buildSIMStartResponse(t, 2, ""),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionDrop {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionDrop)
+	}
+}
@@ -0,0 +1,358 @@
+package engine
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/aka"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/akaprime"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// reauthEnabled は高速再認証が有効かどうかを返す
+func (e *EngineImpl) reauthEnabled() bool {
+	return e.reauthStore != nil && e.cfg.ReauthMaxCount > 0
+}
+
+// generateReauthID は次回用の再認証IDを生成する
+// ValkeyへはChallenge/再認証の成功時に登録する。無効時・失敗時は空文字列を返す
+func (e *EngineImpl) generateReauthID(traceID string, eapType uint8) string {
+	if !e.reauthEnabled() {
+		return ""
+	}
+
+	prefix := rune(eap.IdentityPrefixAKAReauth)
+	if eapType == eapaka.TypeAKAPrime {
+		prefix = eap.IdentityPrefixAKAPrimeReauth
+	}
+	reauthID, err := session.GenerateReauthID(prefix)
+	if err != nil {
+		slog.Warn("再認証ID生成失敗",
+			"event_id", "EAP_REAUTH_ISSUE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return ""
+	}
+	return reauthID
+}
+
+// lookupReauthContext は再認証IDに対応するコンテキストを取得する
+// 未登録・期限切れ・ストア障害の場合はnilを返し、フル認証へフォールバックさせる
+func (e *EngineImpl) lookupReauthContext(ctx context.Context, traceID string, identity *eap.ParsedIdentity) *session.ReauthContext {
+	if !e.reauthEnabled() {
+		return nil
+	}
+
+	rc, err := e.reauthStore.Get(ctx, identity.UserPart)
+	if err != nil {
+		if errors.Is(err, session.ErrReauthNotFound) {
+			slog.Info("未知の再認証ID",
+				"event_id", "EAP_REAUTH_UNKNOWN",
+				"trace_id", traceID,
+			)
+		} else {
+			slog.Warn("再認証コンテキスト取得失敗",
+				"event_id", "EAP_REAUTH_LOOKUP_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+		}
+		return nil
+	}
+
+	// 発行時と異なる方式の再認証IDは受け付けない
+	if rc.EAPType != identity.EAPType {
+		slog.Warn("再認証IDのEAP方式不一致",
+			"event_id", "EAP_REAUTH_UNKNOWN",
+			"trace_id", traceID,
+			"eap_type", identity.EAPType,
+		)
+		return nil
+	}
+	return rc
+}
+
+// handleReauthIdentity は既知の再認証ID受信時にEAP-Request/AKA-Reauthenticationを送信する
+// 再認証回数が上限に達している場合はフル認証を開始する
+func (e *EngineImpl) handleReauthIdentity(ctx context.Context, req *eap.Request, traceID string, pkt *eapaka.Packet, identity *eap.ParsedIdentity, rc *session.ReauthContext, current eap.EAPState) (*eap.Result, error) {
+	maskedIMSI := e.maskIMSI(rc.IMSI)
+	identity.IMSI = rc.IMSI
+
+	// ロックアウト中のIMSIは保存済みの鍵による高速再認証も行わない
+	if e.imsiLocked(ctx, traceID, rc.IMSI) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// AKA'必須のクライアントではEAP-AKAの高速再認証も行わない
+	if e.clientRequiresAKAPrime(ctx, req, traceID, rc.IMSI, identity.EAPType) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 再認証回数上限 → フル認証
+	if rc.Counter >= e.cfg.ReauthMaxCount {
+		slog.Info("再認証回数上限到達、フル認証へ移行",
+			"event_id", "EAP_REAUTH_LIMIT",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"counter", rc.Counter,
+		)
+		_ = e.reauthStore.Delete(ctx, identity.UserPart)
+		return e.handlePermanentIdentity(ctx, req, traceID, pkt, identity)
+	}
+
+	// 状態遷移: NEW/WAITING_IDENTITY → REAUTH_SENT
+	if _, err := eap.ValidateTransition(current, eap.EventReauthIdentity); err != nil {
+		slog.Error("状態遷移失敗",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 保存済み鍵の復元
+	reauthKey, err1 := hex.DecodeString(rc.ReauthKey)
+	kEncr, err2 := hex.DecodeString(rc.KEncr)
+	kAut, err3 := hex.DecodeString(rc.Kaut)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		slog.Error("再認証鍵復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		_ = e.reauthStore.Delete(ctx, identity.UserPart)
+		return e.handlePermanentIdentity(ctx, req, traceID, pkt, identity)
+	}
+
+	nonceS, err := eap.GenerateNonceS()
+	if err != nil {
+		slog.Error("NONCE_S生成失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 新しいMSK導出（鍵導出には再認証IDそのものを使用する）
+	counter := uint16(rc.Counter + 1)
+	var msk []byte
+	if identity.IsAKAPrime() {
+		msk, _ = akaprime.DeriveReauthKeys(identity.Raw, counter, nonceS, reauthKey)
+	} else {
+		msk, _ = aka.DeriveReauthKeys(identity.Raw, counter, nonceS, reauthKey)
+	}
+
+	// 上限未満の場合のみ次回用の再認証IDを通知する
+	var nextReauthID string
+	if int(counter) < e.cfg.ReauthMaxCount {
+		nextReauthID = e.generateReauthID(traceID, identity.EAPType)
+	}
+
+	eapCtx := &session.EAPContext{
+		IMSI:          rc.IMSI,
+		Stage:         string(eap.StateReauthSent),
+		EAPType:       identity.EAPType,
+		Kaut:          rc.Kaut,
+		KEncr:         rc.KEncr,
+		MSK:           hex.EncodeToString(msk),
+		ReauthKey:     rc.ReauthKey,
+		ReauthID:      identity.UserPart,
+		Identity:      identity.Raw,
+		NextReauthID:  nextReauthID,
+		Counter:       int(counter),
+		NonceS:        hex.EncodeToString(nonceS),
+		EAPIdentifier: identifierField(pkt.Identifier + 1),
+	}
+	if err := e.ctxStore.Create(ctx, traceID, eapCtx); err != nil {
+		slog.Error("EAPコンテキスト作成失敗",
+			"event_id", "EAP_CTX_CREATE_ERR",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	reauthMsg, err := eap.BuildReauthRequest(&eap.ReauthParams{
+		Identifier:   pkt.Identifier + 1,
+		EAPType:      identity.EAPType,
+		Counter:      counter,
+		NonceS:       nonceS,
+		NextReauthID: nextReauthID,
+		KEncr:        kEncr,
+		KAut:         kAut,
+		ResultInd:    e.cfg.ResultIndEnabled,
+	})
+	if err != nil {
+		slog.Error("Reauthentication構築失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	slog.Info("高速再認証Request送信",
+		"event_id", "EAP_REAUTH_SENT",
+		"trace_id", traceID,
+		"imsi", maskedIMSI,
+		"eap_type", identity.EAPType,
+		"counter", counter,
+	)
+
+	return &eap.Result{
+		Action:     eap.ActionChallenge,
+		EAPMessage: reauthMsg,
+		State:      []byte(traceID),
+		IMSI:       rc.IMSI,
+	}, nil
+}
+
+// handleReauthResponse はEAP-Response/AKA-Reauthenticationを検証して認証結果を返す
+// AT_COUNTER_TOO_SMALLを受信した場合はフル認証へ移行する
+func (e *EngineImpl) handleReauthResponse(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, pkt *eapaka.Packet) (*eap.Result, error) {
+	maskedIMSI := e.maskIMSI(eapCtx.IMSI)
+
+	// 状態遷移検証
+	if eap.EAPState(eapCtx.Stage) != eap.StateReauthSent {
+		slog.Warn("不正な状態で再認証応答受信",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"stage", eapCtx.Stage,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// Valkey保存値の復元
+	kAut, err1 := hex.DecodeString(eapCtx.Kaut)
+	kEncr, err2 := hex.DecodeString(eapCtx.KEncr)
+	nonceS, err3 := hex.DecodeString(eapCtx.NonceS)
+	msk, err4 := hex.DecodeString(eapCtx.MSK)
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		slog.Error("再認証コンテキスト復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 再認証応答検証
+	counterTooSmall, verifyErr := eap.VerifyReauthResponse(pkt, kEncr, kAut, nonceS, uint16(eapCtx.Counter))
+	if verifyErr != nil {
+		eventID := "AUTH_VERIFY_FAIL"
+		if errors.Is(verifyErr, eap.ErrMACInvalid) {
+			eventID = "AUTH_MAC_INVALID"
+		} else if errors.Is(verifyErr, eap.ErrCounterMismatch) {
+			eventID = "AUTH_REAUTH_COUNTER_INVALID"
+		}
+		slog.Warn("再認証応答検証失敗",
+			"event_id", eventID,
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"error", verifyErr,
+		)
+		if eventID == "AUTH_MAC_INVALID" {
+			e.recordAuthFailure(ctx, traceID, eapCtx.IMSI, eventID)
+		}
+		// 検証失敗した再認証IDは以後使用させない
+		_ = e.reauthStore.Delete(ctx, eapCtx.ReauthID)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// AT_COUNTER_TOO_SMALL → フル認証へ移行（RFC 4187 Section 5.5）
+	if counterTooSmall {
+		slog.Info("AT_COUNTER_TOO_SMALL受信、フル認証へ移行",
+			"event_id", "EAP_REAUTH_COUNTER_TOO_SMALL",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"counter", eapCtx.Counter,
+		)
+		_ = e.reauthStore.Delete(ctx, eapCtx.ReauthID)
+
+		if _, err := eap.ValidateTransition(eap.StateReauthSent, eap.EventCounterTooSmall); err != nil {
+			slog.Error("状態遷移失敗",
+				"event_id", "EAP_STATE_ERR",
+				"trace_id", traceID,
+				"error", err,
+			)
+			return e.buildReject(pkt.Identifier + 1), nil
+		}
+		if res := e.transitionContext(ctx, traceID, eapCtx, pkt.Identifier, map[string]any{
+			"stage": string(eap.StateIdentityReceived),
+		}); res != nil {
+			return res, nil
+		}
+
+		// フル認証の鍵導出はピアが使用した再認証IDで行う
+		raw := eapCtx.Identity
+		if raw == "" {
+			raw = req.UserName
+		}
+		identity := &eap.ParsedIdentity{
+			Raw:     raw,
+			IMSI:    eapCtx.IMSI,
+			EAPType: eapCtx.EAPType,
+			Type:    eap.IdentityTypeReauthAKA,
+		}
+		if eapCtx.EAPType == eapaka.TypeAKAPrime {
+			identity.Type = eap.IdentityTypeReauthAKAPrime
+		}
+		return e.requestVectorAndBuildChallenge(ctx, req, pkt.Identifier, identity, traceID, nil)
+	}
+
+	resultInd := e.cfg.ResultIndEnabled && eap.HasResultInd(pkt)
+	return e.completeAuthentication(ctx, req, traceID, eapCtx, pkt.Identifier, msk, resultInd), nil
+}
+
+// saveReauthContext は認証成功時に次回用の再認証コンテキストを保存する
+// フル認証時は新規作成、高速再認証時は鍵を引き継いでカウンタを進める
+// 保存に失敗しても認証結果には影響させない（次回はフル認証となる）
+func (e *EngineImpl) saveReauthContext(ctx context.Context, traceID string, eapCtx *session.EAPContext) {
+	if !e.reauthEnabled() {
+		return
+	}
+
+	var rc *session.ReauthContext
+	if eapCtx.ReauthID != "" {
+		// 使用済み再認証IDを破棄し、鍵有効期限を引き継ぐ
+		old, err := e.reauthStore.Get(ctx, eapCtx.ReauthID)
+		_ = e.reauthStore.Delete(ctx, eapCtx.ReauthID)
+		if err != nil {
+			return
+		}
+		rc = old
+		rc.Counter = eapCtx.Counter
+	} else {
+		rc = &session.ReauthContext{
+			IMSI:      eapCtx.IMSI,
+			EAPType:   eapCtx.EAPType,
+			ReauthKey: eapCtx.ReauthKey,
+			KEncr:     eapCtx.KEncr,
+			Kaut:      eapCtx.Kaut,
+			Counter:   0,
+			ExpiresAt: time.Now().Add(e.cfg.ReauthKeyLifetime).Unix(),
+		}
+	}
+
+	if eapCtx.NextReauthID == "" {
+		return
+	}
+	if err := e.reauthStore.Create(ctx, eapCtx.NextReauthID, rc); err != nil {
+		slog.Warn("再認証コンテキスト保存失敗",
+			"event_id", "EAP_REAUTH_ISSUE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,521 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/aka"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testReauthID       = "4reauthid"
+	testReauthUserName = testReauthID + "@realm"
+	testReauthMax      = 3
+)
+
+// テスト用の再認証鍵
+var (
+	testReauthKEncr = bytes.Repeat([]byte{0x61}, 16)
+	testReauthKAut  = bytes.Repeat([]byte{0x62}, 16)
+	testReauthMK    = bytes.Repeat([]byte{0x63}, 20)
+	testNonceS      = bytes.Repeat([]byte{0x64}, 16)
+)
+
+// reauthTestMocks は再認証テスト用のモック一式
+type reauthTestMocks struct {
+	*engineTestMocks
+	reauth *mocks.MockReauthStore
+}
+
+// newReauthTestEngine は再認証ストア付きのエンジンとモックを生成する
+func newReauthTestEngine(ctrl *gomock.Controller) (*EngineImpl, *reauthTestMocks) {
+	cfg := newTestConfig()
+	cfg.ReauthMaxCount = testReauthMax
+	cfg.ReauthKeyLifetime = time.Hour
+	reauth := mocks.NewMockReauthStore(ctrl)
+	eng, m := newTestEngine(ctrl, cfg, WithReauthStore(reauth))
+	return eng, &reauthTestMocks{engineTestMocks: m, reauth: reauth}
+}
+
+// makeReauthContext はテスト用の再認証コンテキストを生成する
+func makeReauthContext(counter int) *session.ReauthContext {
+	return &session.ReauthContext{
+		IMSI:      testIMSI,
+		EAPType:   eapaka.TypeAKA,
+		ReauthKey: hex.EncodeToString(testReauthMK),
+		KEncr:     hex.EncodeToString(testReauthKEncr),
+		Kaut:      hex.EncodeToString(testReauthKAut),
+		Counter:   counter,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// makeReauthSentContext はREAUTH_SENT状態のEAPContextを生成する
+func makeReauthSentContext(counter int, nextReauthID string) *session.EAPContext {
+	msk, _ := aka.DeriveReauthKeys(testReauthUserName, uint16(counter), testNonceS, testReauthMK)
+	return &session.EAPContext{
+		IMSI:         testIMSI,
+		Stage:        string(eap.StateReauthSent),
+		EAPType:      eapaka.TypeAKA,
+		Kaut:         hex.EncodeToString(testReauthKAut),
+		KEncr:        hex.EncodeToString(testReauthKEncr),
+		MSK:          hex.EncodeToString(msk),
+		ReauthKey:    hex.EncodeToString(testReauthMK),
+		ReauthID:     testReauthID,
+		NextReauthID: nextReauthID,
+		Counter:      counter,
+		NonceS:       hex.EncodeToString(testNonceS),
+	}
+}
+
+// buildReauthResponseEAPMessage はピア側のEAP-Response/AKA-Reauthenticationを構築する
+func buildReauthResponseEAPMessage(t *testing.T, identifier uint8, counter uint16, tooSmall bool, kAut []byte) []byte {
+	t.Helper()
+	encrAttrs := []eapaka.Attribute{&eapaka.AtCounter{Counter: counter}}
+	if tooSmall {
+		encrAttrs = append(encrAttrs, &eapaka.AtCounterTooSmall{})
+	}
+	atIv, atEncr, err := eap.EncryptAttributes(testReauthKEncr, encrAttrs)
+	if err != nil {
+		t.Fatalf("暗号化失敗: %v", err)
+	}
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eapaka.TypeAKA,
+		Subtype:    eapaka.SubtypeReauthentication,
+		Attributes: []eapaka.Attribute{atIv, atEncr, &eapaka.AtMac{MAC: make([]byte, 16)}},
+	}
+	if err := eap.CalculateMACWithExtra(pkt, kAut, testNonceS); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+	data, _ := pkt.Marshal()
+	return data
+}
+
+func TestEngine_FullAuth_IssuesReauthID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	var updates map[string]any
+	m.ctxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	m.vector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	m.ctxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	userName := "0" + testIMSI + "@realm"
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   userName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	nextID, _ := updates["next_reauth_id"].(string)
+	if len(nextID) == 0 || nextID[0] != '4' {
+		t.Fatalf("next_reauth_id が不正: %q", nextID)
+	}
+	keys := aka.DeriveKeys(userName, testCK, testIK)
+	if updates["reauth_key"] != hex.EncodeToString(keys.MK) {
+		t.Error("reauth_key にMKが保存されていない")
+	}
+
+	// Challenge内のAT_NEXT_REAUTH_IDを確認
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	atIv, _ := eap.GetAttribute[*eapaka.AtIv](pkt)
+	atEncr, _ := eap.GetAttribute[*eapaka.AtEncrData](pkt)
+	attrs, err := eap.DecryptAttributes(keys.K_encr, atIv, atEncr)
+	if err != nil {
+		t.Fatalf("復号失敗: %v", err)
+	}
+	found := false
+	for _, a := range attrs {
+		if next, ok := a.(*eapaka.AtNextReauthId); ok && next.Identity == nextID {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("AT_NEXT_REAUTH_IDが見つからない")
+	}
+}
+
+func TestEngine_ChallengeSuccess_StoresReauthContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	eapCtx.NextReauthID = "4next"
+	eapCtx.ReauthKey = hex.EncodeToString(testReauthMK)
+	eapCtx.KEncr = hex.EncodeToString(keys.K_encr)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	m.reauth.EXPECT().Create(gomock.Any(), "4next", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, rc *session.ReauthContext) error {
+			if rc.Counter != 0 {
+				t.Errorf("Counter: got %d, want 0", rc.Counter)
+			}
+			if rc.IMSI != testIMSI || rc.ReauthKey != eapCtx.ReauthKey || rc.Kaut != eapCtx.Kaut {
+				t.Errorf("再認証コンテキストが不正: %+v", rc)
+			}
+			if rc.ExpiresAt <= time.Now().Unix() {
+				t.Error("ExpiresAtが未来ではない")
+			}
+			return nil
+		})
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_KnownReauthID_SendsReauthRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	var created *session.EAPContext
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(makeReauthContext(1), nil)
+	m.ctxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, c *session.EAPContext) error {
+			created = c
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, err := eapaka.Parse(result.EAPMessage)
+	if err != nil {
+		t.Fatalf("パース失敗: %v", err)
+	}
+	if pkt.Subtype != eapaka.SubtypeReauthentication {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeReauthentication)
+	}
+	if ok, _ := pkt.VerifyMac(testReauthKAut); !ok {
+		t.Error("MAC検証失敗")
+	}
+
+	if created.Stage != string(eap.StateReauthSent) {
+		t.Errorf("Stage: got %q, want %q", created.Stage, eap.StateReauthSent)
+	}
+	if created.Counter != 2 {
+		t.Errorf("Counter: got %d, want 2", created.Counter)
+	}
+	if created.ReauthID != testReauthID {
+		t.Errorf("ReauthID: got %q, want %q", created.ReauthID, testReauthID)
+	}
+	if created.NextReauthID == "" {
+		t.Error("上限未満の場合は次回用再認証IDを通知すべき")
+	}
+
+	// 送信したAT_COUNTER/AT_NONCE_SとEAPContextが一致する
+	atIv, _ := eap.GetAttribute[*eapaka.AtIv](pkt)
+	atEncr, _ := eap.GetAttribute[*eapaka.AtEncrData](pkt)
+	attrs, err := eap.DecryptAttributes(testReauthKEncr, atIv, atEncr)
+	if err != nil {
+		t.Fatalf("復号失敗: %v", err)
+	}
+	for _, a := range attrs {
+		switch v := a.(type) {
+		case *eapaka.AtCounter:
+			if v.Counter != 2 {
+				t.Errorf("AT_COUNTER: got %d, want 2", v.Counter)
+			}
+		case *eapaka.AtNonceS:
+			if hex.EncodeToString(v.NonceS) != created.NonceS {
+				t.Error("AT_NONCE_SがEAPContextと一致しない")
+			}
+		}
+	}
+}
+
+func TestEngine_KnownReauthID_LastAllowed_NoNextID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(makeReauthContext(testReauthMax-1), nil)
+	m.ctxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, c *session.EAPContext) error {
+			if c.NextReauthID != "" {
+				t.Errorf("最終回の再認証で次回用IDを通知すべきでない: %q", c.NextReauthID)
+			}
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+}
+
+func TestEngine_ReauthLimit_FullAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(makeReauthContext(testReauthMax), nil)
+	m.reauth.EXPECT().Delete(gomock.Any(), testReauthID).Return(nil)
+	m.ctxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	m.vector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	m.ctxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	if pkt.Subtype != eapaka.SubtypeChallenge {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeChallenge)
+	}
+}
+
+func TestEngine_UnknownReauthID_FullAuthRedirect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(nil, session.ErrReauthNotFound)
+	m.ctxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+	m.ctxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	if pkt.Subtype != eapaka.SubtypeIdentity {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeIdentity)
+	}
+}
+
+func TestEngine_ReauthResponse_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+	eapCtx := makeReauthSentContext(2, "4next")
+	wantMSK, _ := aka.DeriveReauthKeys(testReauthUserName, 2, testNonceS, testReauthMK)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(m.ctxStore, eap.StateSuccess).Return(nil)
+	m.sessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	m.sessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	old := makeReauthContext(1)
+	m.reauth.EXPECT().Get(gomock.Any(), testReauthID).Return(old, nil)
+	m.reauth.EXPECT().Delete(gomock.Any(), testReauthID).Return(nil)
+	m.reauth.EXPECT().Create(gomock.Any(), "4next", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, rc *session.ReauthContext) error {
+			if rc.Counter != 2 {
+				t.Errorf("Counter: got %d, want 2", rc.Counter)
+			}
+			if rc.ExpiresAt != old.ExpiresAt {
+				t.Error("鍵有効期限が引き継がれていない")
+			}
+			return nil
+		})
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildReauthResponseEAPMessage(t, 2, 2, false, testReauthKAut),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+	if !bytes.Equal(result.MSK, wantMSK) {
+		t.Error("MSKが再認証で導出した値と一致しない")
+	}
+}
+
+func TestEngine_ReauthResponse_MACInvalid_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(makeReauthSentContext(2, ""), nil)
+	m.reauth.EXPECT().Delete(gomock.Any(), testReauthID).Return(nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildReauthResponseEAPMessage(t, 2, 2, false, make([]byte, 16)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_ReauthResponse_CounterMismatch_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(makeReauthSentContext(2, ""), nil)
+	m.reauth.EXPECT().Delete(gomock.Any(), testReauthID).Return(nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildReauthResponseEAPMessage(t, 2, 1, false, testReauthKAut),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_ReauthResponse_CounterTooSmall_FullAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(makeReauthSentContext(2, ""), nil)
+	m.reauth.EXPECT().Delete(gomock.Any(), testReauthID).Return(nil)
+	expectTransition(m.ctxStore, eap.StateIdentityReceived).Return(nil)
+	m.vector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+		Return(&vector.VectorResponse{
+			RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+		}, nil)
+	m.ctxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			if u["stage"] != string(eap.StateChallengeSent) {
+				t.Errorf("stage: got %v, want %v", u["stage"], eap.StateChallengeSent)
+			}
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildReauthResponseEAPMessage(t, 2, 2, true, testReauthKAut),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	pkt, _ := eapaka.Parse(result.EAPMessage)
+	if pkt.Subtype != eapaka.SubtypeChallenge {
+		t.Errorf("Subtype: got %d, want %d", pkt.Subtype, eapaka.SubtypeChallenge)
+	}
+}
+
+func TestEngine_ReauthResponse_WrongStage_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newReauthTestEngine(ctrl)
+
+	eapCtx := makeReauthSentContext(2, "")
+	eapCtx.Stage = string(eap.StateChallengeSent)
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildReauthResponseEAPMessage(t, 2, 2, false, testReauthKAut),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
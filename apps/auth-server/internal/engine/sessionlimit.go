@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+)
+
+// sessionLimit は加入者の同時セッション数の上限を返す（0の場合は無制限）
+// ポリシーのmax_sessionsを優先し、未設定の場合はMAX_SESSIONS_PER_USERを適用する
+func (e *EngineImpl) sessionLimit(pol *policy.Policy) int {
+	if pol.MaxSessions > 0 {
+		return pol.MaxSessions
+	}
+	return e.cfg.MaxSessionsPerUser
+}
+
+// evictEnabled は上限到達時に古いセッションを削除して受け付けるかどうかを返す
+func (e *EngineImpl) evictEnabled() bool {
+	return e.cfg.SessionLimitAction == config.SessionLimitActionEvict
+}
+
+// sessionLimitReached は上限到達時に拒否する設定で、アクティブセッション数が上限に達しているかを判定する
+// 失敗通知を返すための事前確認で、並行する認証に対する上限はセッション作成時（createSession）に不可分に確認する
+// セッション一覧を取得できない場合は認証を妨げない
+func (e *EngineImpl) sessionLimitReached(ctx context.Context, traceID, imsi string, maxSessions int) bool {
+	if maxSessions <= 0 || e.evictEnabled() {
+		return false
+	}
+
+	sessions, err := e.sessStore.ListByIMSI(ctx, imsi)
+	if err != nil {
+		slog.Warn("アクティブセッション取得失敗",
+			"event_id", "SESSION_INDEX_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return false
+	}
+	if len(sessions) < maxSessions {
+		return false
+	}
+
+	slog.Warn("同時セッション数上限",
+		"event_id", "AUTH_SESSION_LIMIT",
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(imsi),
+		"active_sessions", len(sessions),
+		"max_sessions", maxSessions,
+	)
+	return true
+}
+
+// evictSessions は上限到達時に古いセッションを削除する設定で、新しいセッションを除いて
+// アクティブセッション数がmaxSessions以下になるまで開始時刻の古いセッションを削除する
+// 削除に失敗しても認証結果には影響させない
+func (e *EngineImpl) evictSessions(ctx context.Context, traceID, imsi, newSessionID string, maxSessions int) {
+	if maxSessions <= 0 || !e.evictEnabled() {
+		return
+	}
+
+	sessions, err := e.sessStore.ListByIMSI(ctx, imsi)
+	if err != nil {
+		slog.Warn("アクティブセッション取得失敗",
+			"event_id", "SESSION_INDEX_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return
+	}
+
+	excess := len(sessions) - maxSessions
+	for _, sess := range sessions {
+		if excess <= 0 {
+			break
+		}
+		if sess.ID == newSessionID {
+			continue
+		}
+		if err := e.sessStore.Delete(ctx, imsi, sess.ID); err != nil {
+			slog.Warn("セッション削除失敗",
+				"event_id", "SESSION_EVICT_ERR",
+				"trace_id", traceID,
+				"session_id", sess.ID,
+				"error", err,
+			)
+			return
+		}
+		excess--
+		slog.Info("同時セッション数上限により古いセッションを削除",
+			"event_id", "SESSION_EVICTED",
+			"trace_id", traceID,
+			"imsi", e.maskIMSI(imsi),
+			"session_id", sess.ID,
+			"new_session_id", newSessionID,
+			"max_sessions", maxSessions,
+		)
+	}
+}
@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// activeSessions はテスト用のアクティブセッション一覧（開始時刻の古い順）を生成する
+func activeSessions(ids ...string) []*session.Session {
+	sessions := make([]*session.Session, len(ids))
+	for i, id := range ids {
+		sessions[i] = &session.Session{ID: id, IMSI: testIMSI, StartTime: int64(1700000000 + i)}
+	}
+	return sessions
+}
+
+// challengeSuccessRequest はChallenge応答（検証成功）のリクエストとEAPContextを生成する
+func challengeSuccessRequest(resultInd bool) (*eap.Request, *session.EAPContext, []byte) {
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+	msg := buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES)
+	if resultInd {
+		msg = buildResultIndChallengeResponse(2, keys.K_aut, testXRES)
+	}
+	return &eap.Request{
+		TraceID:       testTraceID,
+		SrcIP:         "192.168.1.1",
+		NASIdentifier: testNASID,
+		CalledStation: "AA-BB-CC-DD-EE-FF:" + testSSID,
+		UserName:      "0" + testIMSI + "@realm",
+		State:         []byte(testTraceID),
+		EAPMessage:    msg,
+	}, eapCtx, keys.K_aut
+}
+
+func TestEngine_SessionLimit_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	eng.cfg.MaxSessionsPerUser = 2
+
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).Return(&policy.EvaluationResult{Allowed: true})
+	mockSessStore.EXPECT().ListByIMSI(gomock.Any(), testIMSI).Return(activeSessions("s1", "s2"), nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_SessionLimit_PolicyOverridesDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	eng.cfg.MaxSessionsPerUser = 1
+
+	// ポリシーのmax_sessions（3）が全体設定（1）より優先される
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow", MaxSessions: 3}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
+	mockSessStore.EXPECT().ListByIMSI(gomock.Any(), testIMSI).Return(activeSessions("s1", "s2"), nil)
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 3).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_SessionLimit_ResultInd_TemporarilyDenied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, m := newResultIndTestEngine(ctrl)
+	eng.cfg.MaxSessionsPerUser = 1
+
+	req, eapCtx, kAut := challengeSuccessRequest(true)
+	var updates map[string]any
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	m.policy.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	m.evaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).Return(&policy.EvaluationResult{Allowed: true})
+	m.sessStore.EXPECT().ListByIMSI(gomock.Any(), testIMSI).Return(activeSessions("s1"), nil)
+	m.ctxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, notif := parseNotification(t, result.EAPMessage)
+	if notif.S || notif.P || notif.Code != eap.NotificationTemporarilyDenied {
+		t.Errorf("User has been temporarily denied accessではない: %+v", notif)
+	}
+	if err := eap.VerifyMACWithExtra(pkt, kAut, nil); err != nil {
+		t.Errorf("AT_MAC検証失敗: %v", err)
+	}
+	if updates["notification"] != int(eap.NotificationTemporarilyDenied) {
+		t.Errorf("notification: got %v, want %d", updates["notification"], eap.NotificationTemporarilyDenied)
+	}
+}
+
+func TestEngine_SessionLimit_ListError_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	eng.cfg.MaxSessionsPerUser = 1
+
+	// セッション一覧を取得できない場合は認証を妨げない
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
+	mockSessStore.EXPECT().ListByIMSI(gomock.Any(), testIMSI).Return(nil, errors.New("valkey down"))
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 1).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_SessionLimit_ConcurrentSessionCreated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	eng.cfg.MaxSessionsPerUser = 2
+
+	// 事前確認の後に並行する認証が上限までセッションを作成した場合は、作成したセッションを削除して拒否する
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
+	mockSessStore.EXPECT().ListByIMSI(gomock.Any(), testIMSI).Return(activeSessions("s1"), nil)
+	var sessionID string
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string, _ *session.Session) error {
+			sessionID = id
+			return nil
+		})
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 2).Return(false, nil)
+	mockSessStore.EXPECT().Delete(gomock.Any(), testIMSI, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, id string) error {
+			if id != sessionID {
+				t.Errorf("deleted session: got %s, want %s", id, sessionID)
+			}
+			return nil
+		})
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_SessionLimit_EvictOldest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	eng.cfg.MaxSessionsPerUser = 2
+	eng.cfg.SessionLimitAction = config.SessionLimitActionEvict
+
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	var newSessionID string
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string, _ *session.Session) error {
+			newSessionID = id
+			return nil
+		})
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	// 新しいセッションを含めて4件 → 古い2件を削除
+	mockSessStore.EXPECT().ListByIMSI(gomock.Any(), testIMSI).
+		DoAndReturn(func(context.Context, string) ([]*session.Session, error) {
+			return activeSessions("s1", "s2", "s3", newSessionID), nil
+		})
+	gomock.InOrder(
+		mockSessStore.EXPECT().Delete(gomock.Any(), testIMSI, "s1").Return(nil),
+		mockSessStore.EXPECT().Delete(gomock.Any(), testIMSI, "s2").Return(nil),
+	)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+	if result.SessionID != newSessionID {
+		t.Errorf("SessionID: got %q, want %q", result.SessionID, newSessionID)
+	}
+}
+
+func TestEvictSessions_KeepsNewSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, _, mockSessStore, _, _ := newChallengeTestEngine(ctrl)
+	eng.cfg.SessionLimitAction = config.SessionLimitActionEvict
+
+	// 開始時刻が同じで新しいセッションが先頭に並んでも、新しいセッションは削除しない
+	mockSessStore.EXPECT().ListByIMSI(gomock.Any(), testIMSI).Return(activeSessions("new", "old"), nil)
+	mockSessStore.EXPECT().Delete(gomock.Any(), testIMSI, "old").Return(nil)
+
+	eng.evictSessions(context.Background(), testTraceID, testIMSI, "new", 1)
+}
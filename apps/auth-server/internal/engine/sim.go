@@ -0,0 +1,350 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/sim"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// GSMトリプレットの各値の長さ
+const (
+	sresLength = 4
+	kcLength   = 8
+)
+
+// handleSIMIdentity はEAP-SIM Identity受信時の処理を行う（RFC 4186 Section 9.2）
+// EAPコンテキストを作成し、EAP-Request/SIM/Startを送信する
+// EAP-SIMの仮名・再認証IDは発行しないため、'3'/'5'の場合はAT_PERMANENT_ID_REQで永続IDを要求する
+func (e *EngineImpl) handleSIMIdentity(ctx context.Context, req *eap.Request, pkt *eapaka.Packet, identity *eap.ParsedIdentity) (*eap.Result, error) {
+	traceID := req.TraceID
+
+	if _, err := eap.ValidateTransition(eap.StateNew, eap.EventSIMIdentity); err != nil {
+		slog.Error("状態遷移失敗",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// AKA'必須のクライアントではEAP-SIMによる認証を行わない
+	if e.clientRequiresAKAPrime(ctx, req, traceID, identity.IMSI, eap.EAPTypeSIM) {
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	var reqType eap.IdentityReqType
+	if identity.Type != eap.IdentityTypePermanentSIM {
+		reqType = eap.IdentityReqPermanent
+	}
+
+	// EAPContext作成
+	eapCtx := &session.EAPContext{
+		IMSI:            identity.IMSI,
+		Stage:           string(eap.StateSIMStartSent),
+		EAPType:         eap.EAPTypeSIM,
+		Identity:        identity.Raw,
+		IdentityReqSent: uint8(reqType),
+		EAPIdentifier:   identifierField(pkt.Identifier + 1),
+	}
+	if err := e.ctxStore.Create(ctx, traceID, eapCtx); err != nil {
+		slog.Error("EAPコンテキスト作成失敗",
+			"event_id", "EAP_CTX_CREATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	startMsg, err := sim.BuildStart(pkt.Identifier+1, reqType)
+	if err != nil {
+		slog.Error("SIM/Start構築失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	slog.Info("SIM/Start送信",
+		"event_id", "EAP_SIM_START_SENT",
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(identity.IMSI),
+		"id_req", reqType.String(),
+	)
+
+	return &eap.Result{
+		Action:     eap.ActionChallenge,
+		EAPMessage: startMsg,
+		State:      []byte(traceID),
+		IMSI:       identity.IMSI,
+	}, nil
+}
+
+// handleSIMSubsequent はEAP-SIMの後続パケットをSubtypeごとに処理する
+func (e *EngineImpl) handleSIMSubsequent(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, pkt *eapaka.Packet) (*eap.Result, error) {
+	switch pkt.Subtype {
+	case sim.SubtypeStart:
+		return e.handleSIMStartResponse(ctx, req, traceID, eapCtx, pkt)
+
+	case sim.SubtypeChallenge:
+		return e.handleSIMChallengeResponse(ctx, req, traceID, eapCtx, pkt)
+
+	case eapaka.SubtypeNotification:
+		return e.handleNotificationResponse(ctx, req, traceID, eapCtx, pkt)
+
+	case eapaka.SubtypeClientError:
+		slog.Warn("Client-Error受信",
+			"event_id", "EAP_CLIENT_ERROR",
+			"trace_id", traceID,
+			"imsi", e.maskIMSI(eapCtx.IMSI),
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+
+	default:
+		slog.Warn("未知のSubtype",
+			"event_id", "EAP_UNKNOWN_SUBTYPE",
+			"trace_id", traceID,
+			"subtype", pkt.Subtype,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+}
+
+// handleSIMStartResponse はEAP-Response/SIM/Startを処理する（RFC 4186 Section 9.3）
+// NONCE_MTと永続IDを確定し、トリプレット取得→鍵導出→SIM/Challenge送信を行う
+func (e *EngineImpl) handleSIMStartResponse(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, pkt *eapaka.Packet) (*eap.Result, error) {
+	// 状態遷移検証
+	if eap.EAPState(eapCtx.Stage) != eap.StateSIMStartSent {
+		slog.Warn("不正な状態でSIM/Start応答受信",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"stage", eapCtx.Stage,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	start, err := sim.ParseStartResponse(pkt)
+	if err != nil {
+		slog.Warn("SIM/Start応答不正",
+			"event_id", "EAP_SIM_START_INVALID",
+			"trace_id", traceID,
+			"error", err,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// AT_IDENTITYは要求した場合のみ受け付け、EAP-SIM永続IDに限る
+	identityRaw, imsi := eapCtx.Identity, eapCtx.IMSI
+	if eapCtx.IdentityReqSent != 0 || start.Identity != "" {
+		identity, err := eap.ParseIdentity(start.Identity)
+		if eapCtx.IdentityReqSent == 0 || err != nil || identity.Type != eap.IdentityTypePermanentSIM {
+			slog.Warn("SIM/Start応答のIdentity不正",
+				"event_id", "EAP_IDENTITY_INVALID",
+				"trace_id", traceID,
+				"user_name", start.Identity,
+			)
+			_ = e.ctxStore.Delete(ctx, traceID)
+			return e.buildReject(pkt.Identifier + 1), nil
+		}
+		identityRaw, imsi = identity.Raw, identity.IMSI
+	}
+	maskedIMSI := e.maskIMSI(imsi)
+
+	// 状態遷移: SIM_START_SENT → IDENTITY_RECEIVED → WAITING_VECTOR
+	if _, err := eap.ValidateTransition(eap.StateSIMStartSent, eap.EventSIMStartResponse); err != nil {
+		slog.Error("状態遷移失敗",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+	if _, err := eap.ValidateTransition(eap.StateIdentityReceived, eap.EventVectorRequest); err != nil {
+		slog.Error("状態遷移失敗",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 並行するSIM/Start応答による重複トリプレット取得を防ぐ
+	if res := e.transitionContext(ctx, traceID, eapCtx, pkt.Identifier, map[string]any{
+		"stage": string(eap.StateIdentityReceived),
+	}); res != nil {
+		return res, nil
+	}
+
+	// ロックアウト中のIMSIはトリプレットを要求しない
+	if e.imsiLocked(ctx, traceID, imsi) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// IMSI単位の要求レート超過 → 応答なし
+	if e.imsiRateLimited(ctx, req, traceID, imsi) {
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return &eap.Result{Action: eap.ActionDrop}, nil
+	}
+
+	// Vector Gateway呼び出し（トリプレット）
+	vCtx := vector.WithTraceID(ctx, traceID)
+	vecResp, err := e.vectorClient.GetVector(vCtx, &vector.VectorRequest{
+		IMSI:  imsi,
+		Mode:  vector.ModeTriplet,
+		Count: e.cfg.SIMRandCount,
+	})
+	if err != nil {
+		e.logVectorError(err, traceID, maskedIMSI)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	rands, sres, kcs, err := splitTriplets(vecResp.Triplets)
+	if err != nil {
+		slog.Error("トリプレット不正",
+			"event_id", "VECTOR_TRIPLET_INVALID",
+			"trace_id", traceID,
+			"imsi", maskedIMSI,
+			"triplets", len(vecResp.Triplets),
+			"error", err,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// 鍵導出（RFC 4186 Section 7）
+	keys := sim.DeriveKeys(identityRaw, kcs, start.NonceMT, start.SelectedVersion)
+	opts := &eap.ChallengeOptions{
+		ResultInd: e.cfg.ResultIndEnabled,
+	}
+
+	// EAPContext更新
+	updates := map[string]any{
+		"stage":          string(eap.StateChallengeSent),
+		"imsi":           imsi,
+		"identity":       identityRaw,
+		"rand":           hex.EncodeToString(bytes.Join(rands, nil)),
+		"sres":           hex.EncodeToString(bytes.Join(sres, nil)),
+		"nonce_mt":       hex.EncodeToString(start.NonceMT),
+		"k_aut":          hex.EncodeToString(keys.K_aut),
+		"msk":            hex.EncodeToString(keys.MSK),
+		"eap_identifier": identifierField(pkt.Identifier + 1),
+	}
+	if e.erpEnabled() {
+		updates["emsk"] = hex.EncodeToString(keys.EMSK)
+	}
+	if err := e.ctxStore.Update(ctx, traceID, updates); err != nil {
+		slog.Error("EAPコンテキスト更新失敗",
+			"event_id", "EAP_CTX_UPDATE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// SIM/Challenge構築
+	challengeMsg, err := sim.BuildChallenge(pkt.Identifier+1, rands, start.NonceMT, keys.K_aut, opts)
+	if err != nil {
+		slog.Error("Challenge構築失敗",
+			"event_id", "EAP_BUILD_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	slog.Info("Challenge送信",
+		"event_id", "EAP_CHALLENGE_SENT",
+		"trace_id", traceID,
+		"imsi", maskedIMSI,
+		"eap_type", eap.EAPTypeSIM,
+		"rand_count", len(rands),
+	)
+
+	return &eap.Result{
+		Action:     eap.ActionChallenge,
+		EAPMessage: challengeMsg,
+		State:      []byte(traceID),
+		IMSI:       imsi,
+	}, nil
+}
+
+// handleSIMChallengeResponse はEAP-Response/SIM/Challengeを検証して認証結果を返す（RFC 4186 Section 9.5）
+func (e *EngineImpl) handleSIMChallengeResponse(ctx context.Context, req *eap.Request, traceID string, eapCtx *session.EAPContext, pkt *eapaka.Packet) (*eap.Result, error) {
+	// 状態遷移検証
+	if eap.EAPState(eapCtx.Stage) != eap.StateChallengeSent {
+		slog.Warn("不正な状態でChallenge応答受信",
+			"event_id", "EAP_STATE_ERR",
+			"trace_id", traceID,
+			"stage", eapCtx.Stage,
+		)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	// Valkey保存値の復元
+	kAut, err1 := hex.DecodeString(eapCtx.Kaut)
+	sresAll, err2 := hex.DecodeString(eapCtx.SRES)
+	msk, err3 := hex.DecodeString(eapCtx.MSK)
+	if err := errors.Join(err1, err2, err3); err != nil || len(sresAll)%sresLength != 0 {
+		slog.Error("SIMコンテキスト復元失敗",
+			"event_id", "EAP_CTX_DECODE_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+	var sres [][]byte
+	for i := 0; i < len(sresAll); i += sresLength {
+		sres = append(sres, sresAll[i:i+sresLength])
+	}
+
+	// AT_MAC検証（SRES不一致もMAC不一致として検出される）
+	if err := sim.VerifyChallengeResponse(pkt, kAut, sres); err != nil {
+		slog.Warn("Challenge応答検証失敗",
+			"event_id", "AUTH_MAC_INVALID",
+			"trace_id", traceID,
+			"imsi", e.maskIMSI(eapCtx.IMSI),
+			"error", err,
+		)
+		e.recordAuthFailure(ctx, traceID, eapCtx.IMSI, "AUTH_MAC_INVALID")
+		_ = e.ctxStore.Delete(ctx, traceID)
+		return e.buildReject(pkt.Identifier + 1), nil
+	}
+
+	resultInd := e.cfg.ResultIndEnabled && eap.HasResultInd(pkt)
+	return e.completeAuthentication(ctx, req, traceID, eapCtx, pkt.Identifier, msk, resultInd), nil
+}
+
+// splitTriplets はトリプレットをRAND/SRES/Kcの列に分解し、個数・長さ・RANDの重複を検証する
+func splitTriplets(triplets []vector.Triplet) (rands, sres, kcs [][]byte, err error) {
+	if len(triplets) < sim.MinRANDs || len(triplets) > sim.MaxRANDs {
+		return nil, nil, nil, eap.ErrRANDCountInvalid
+	}
+	for i, t := range triplets {
+		if len(t.RAND) != 16 || len(t.SRES) != sresLength || len(t.Kc) != kcLength {
+			return nil, nil, nil, vector.ErrInvalidResponse
+		}
+		// RFC 4186 Section 9.3: 同一Challenge内のRANDは互いに異なること
+		for _, r := range rands[:i] {
+			if bytes.Equal(r, t.RAND) {
+				return nil, nil, nil, eap.ErrRANDCountInvalid
+			}
+		}
+		rands = append(rands, t.RAND)
+		sres = append(sres, t.SRES)
+		kcs = append(kcs, t.Kc)
+	}
+	return rands, sres, kcs, nil
+}
@@ -0,0 +1,514 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap/sim"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+const testSIMIdentity = "1" + testIMSI + "@realm"
+
+// テスト用NONCE_MT
+var testNonceMT = bytes.Repeat([]byte{0x5a}, 16)
+
+// makeTestTriplets はテスト用のトリプレットをn個生成する
+func makeTestTriplets(n int) []vector.Triplet {
+	triplets := make([]vector.Triplet, n)
+	for i := range triplets {
+		triplets[i] = vector.Triplet{
+			RAND: bytes.Repeat([]byte{byte(0x10 + i)}, 16),
+			SRES: bytes.Repeat([]byte{byte(0xa0 + i)}, 4),
+			Kc:   bytes.Repeat([]byte{byte(0xc0 + i)}, 8),
+		}
+	}
+	return triplets
+}
+
+// buildSIMStartResponse はEAP-Response/SIM/Startを構築する
+func buildSIMStartResponse(t *testing.T, identifier uint8, identity string) []byte {
+	t.Helper()
+
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eap.EAPTypeSIM,
+		Subtype:    sim.SubtypeStart,
+		Attributes: []eapaka.Attribute{
+			&eapaka.AtNonceMt{NonceMt: testNonceMT},
+			&eapaka.AtSelectedVersion{Version: sim.Version1},
+		},
+	}
+	if identity != "" {
+		pkt.Attributes = append(pkt.Attributes, &eapaka.AtIdentity{Identity: identity})
+	}
+	data, err := eap.MarshalPacket(pkt)
+	if err != nil {
+		t.Fatalf("SIM/Start応答構築失敗: %v", err)
+	}
+	return data
+}
+
+// buildSIMChallengeResponse はEAP-Response/SIM/Challengeを構築する（AT_MACはSRESを連結して計算）
+func buildSIMChallengeResponse(t *testing.T, identifier uint8, kAut []byte, sres [][]byte) []byte {
+	t.Helper()
+
+	pkt := &eapaka.Packet{
+		Code:       eapaka.CodeResponse,
+		Identifier: identifier,
+		Type:       eap.EAPTypeSIM,
+		Subtype:    sim.SubtypeChallenge,
+		Attributes: []eapaka.Attribute{&eapaka.AtMac{MAC: make([]byte, 16)}},
+	}
+	if err := eap.CalculateMACWithExtra(pkt, kAut, bytes.Join(sres, nil)); err != nil {
+		t.Fatalf("MAC計算失敗: %v", err)
+	}
+	data, err := eap.MarshalPacket(pkt)
+	if err != nil {
+		t.Fatalf("SIM/Challenge応答構築失敗: %v", err)
+	}
+	return data
+}
+
+// makeSIMStartContext はSIM/Start送信済みのEAPContextを生成する
+func makeSIMStartContext(imsi, identity string, reqType eap.IdentityReqType) *session.EAPContext {
+	return &session.EAPContext{
+		IMSI:            imsi,
+		Stage:           string(eap.StateSIMStartSent),
+		EAPType:         eap.EAPTypeSIM,
+		Identity:        identity,
+		IdentityReqSent: uint8(reqType),
+	}
+}
+
+// makeSIMChallengeContext はSIM/Challenge送信済みのEAPContextを生成する
+func makeSIMChallengeContext(triplets []vector.Triplet) (*session.EAPContext, *sim.KeyMaterial) {
+	var kcs, sres [][]byte
+	for _, tr := range triplets {
+		kcs = append(kcs, tr.Kc)
+		sres = append(sres, tr.SRES)
+	}
+	keys := sim.DeriveKeys(testSIMIdentity, kcs, testNonceMT, sim.Version1)
+	return &session.EAPContext{
+		IMSI:     testIMSI,
+		Stage:    string(eap.StateChallengeSent),
+		EAPType:  eap.EAPTypeSIM,
+		Identity: testSIMIdentity,
+		SRES:     hex.EncodeToString(bytes.Join(sres, nil)),
+		NonceMT:  hex.EncodeToString(testNonceMT),
+		Kaut:     hex.EncodeToString(keys.K_aut),
+		MSK:      hex.EncodeToString(keys.MSK),
+	}, keys
+}
+
+func TestEngine_SIM_PermanentIdentity_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	var created *session.EAPContext
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, c *session.EAPContext) error {
+			created = c
+			return nil
+		})
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testSIMIdentity,
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, err := eap.ParseEAPPacket(result.EAPMessage)
+	if err != nil {
+		t.Fatalf("SIM/Startパース失敗: %v", err)
+	}
+	if pkt.Type != eap.EAPTypeSIM || pkt.Subtype != sim.SubtypeStart {
+		t.Errorf("Type/Subtype: got %d/%d, want %d/%d", pkt.Type, pkt.Subtype, eap.EAPTypeSIM, sim.SubtypeStart)
+	}
+	if _, found := eap.GetAttribute[*eapaka.AtPermanentIdReq](pkt); found {
+		t.Error("永続IDの場合はAT_PERMANENT_ID_REQを含まないこと")
+	}
+
+	if created == nil {
+		t.Fatal("EAPContextが作成されていない")
+	}
+	if created.Stage != string(eap.StateSIMStartSent) || created.EAPType != eap.EAPTypeSIM {
+		t.Errorf("Stage/EAPType: got %s/%d", created.Stage, created.EAPType)
+	}
+	if created.IMSI != testIMSI || created.Identity != testSIMIdentity {
+		t.Errorf("IMSI/Identity: got %s/%s", created.IMSI, created.Identity)
+	}
+}
+
+func TestEngine_SIM_PseudonymIdentity_RequestsPermanentID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	var created *session.EAPContext
+	mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, c *session.EAPContext) error {
+			created = c
+			return nil
+		})
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "3pseudonym@realm",
+		EAPMessage: buildIdentityEAPMessage(1, eapaka.TypeAKA),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+
+	pkt, err := eap.ParseEAPPacket(result.EAPMessage)
+	if err != nil {
+		t.Fatalf("SIM/Startパース失敗: %v", err)
+	}
+	if _, found := eap.GetAttribute[*eapaka.AtPermanentIdReq](pkt); !found {
+		t.Error("AT_PERMANENT_ID_REQが含まれていない")
+	}
+	if created.IMSI != "" || eap.IdentityReqType(created.IdentityReqSent) != eap.IdentityReqPermanent {
+		t.Errorf("IMSI/IdentityReqSent: got %q/%d", created.IMSI, created.IdentityReqSent)
+	}
+}
+
+func TestEngine_SIM_StartResponse_SendsChallenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	eng.cfg.SIMRandCount = 2
+	triplets := makeTestTriplets(2)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeSIMStartContext(testIMSI, testSIMIdentity, 0), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{
+		IMSI:  testIMSI,
+		Mode:  vector.ModeTriplet,
+		Count: 2,
+	}).Return(&vector.VectorResponse{Triplets: triplets}, nil)
+
+	var updates map[string]any
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testSIMIdentity,
+		State:      []byte(testTraceID),
+		EAPMessage: buildSIMStartResponse(t, 2, ""),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	// 複数RANDのAT_RANDはgo-eapakaでパースできないため生バイトで確認する
+	if eap.GetEAPType(result.EAPMessage) != eap.EAPTypeSIM || result.EAPMessage[5] != sim.SubtypeChallenge {
+		t.Errorf("Type/Subtype: got %d/%d", result.EAPMessage[4], result.EAPMessage[5])
+	}
+
+	_, want := makeSIMChallengeContext(triplets)
+	if updates["stage"] != string(eap.StateChallengeSent) {
+		t.Errorf("stage: got %v", updates["stage"])
+	}
+	if updates["k_aut"] != hex.EncodeToString(want.K_aut) || updates["msk"] != hex.EncodeToString(want.MSK) {
+		t.Error("導出鍵がRFC 4186の手順と一致しない")
+	}
+	if updates["sres"] != "a0a0a0a0a1a1a1a1" {
+		t.Errorf("sres: got %v", updates["sres"])
+	}
+}
+
+func TestEngine_SIM_StartResponse_PermanentIDFromAtIdentity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	eng.cfg.SIMRandCount = 3
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeSIMStartContext("", "3pseudonym@realm", eap.IdentityReqPermanent), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{
+		IMSI:  testIMSI,
+		Mode:  vector.ModeTriplet,
+		Count: 3,
+	}).Return(&vector.VectorResponse{Triplets: makeTestTriplets(3)}, nil)
+
+	var updates map[string]any
+	mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, u map[string]any) error {
+			updates = u
+			return nil
+		})
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "3pseudonym@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildSIMStartResponse(t, 2, testSIMIdentity),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionChallenge {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+	}
+	if updates["imsi"] != testIMSI || updates["identity"] != testSIMIdentity {
+		t.Errorf("imsi/identity: got %v/%v", updates["imsi"], updates["identity"])
+	}
+}
+
+func TestEngine_SIM_StartResponse_Reject(t *testing.T) {
+	tests := []struct {
+		name     string
+		eapCtx   *session.EAPContext
+		identity string
+	}{
+		{
+			name:     "要求していないAT_IDENTITY",
+			eapCtx:   makeSIMStartContext(testIMSI, testSIMIdentity, 0),
+			identity: testSIMIdentity,
+		},
+		{
+			name:   "要求した永続IDが含まれない",
+			eapCtx: makeSIMStartContext("", "3pseudonym@realm", eap.IdentityReqPermanent),
+		},
+		{
+			name:     "AKAの永続ID",
+			eapCtx:   makeSIMStartContext("", "3pseudonym@realm", eap.IdentityReqPermanent),
+			identity: "0" + testIMSI + "@realm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+			mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(tt.eapCtx, nil)
+			mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+			req := &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   tt.eapCtx.Identity,
+				State:      []byte(testTraceID),
+				EAPMessage: buildSIMStartResponse(t, 2, tt.identity),
+			}
+
+			result, err := eng.Process(context.Background(), req)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionReject {
+				t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+			}
+		})
+	}
+}
+
+func TestEngine_SIM_StartResponse_NotPermitted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	eng.cfg.SIMRandCount = 3
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeSIMStartContext(testIMSI, testSIMIdentity, 0), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+	mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+		Return(nil, &vector.APIError{StatusCode: http.StatusForbidden, Message: "Forbidden"})
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testSIMIdentity,
+		State:      []byte(testTraceID),
+		EAPMessage: buildSIMStartResponse(t, 2, ""),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_SIM_StartResponse_InvalidTriplets(t *testing.T) {
+	duplicated := makeTestTriplets(2)
+	duplicated[1].RAND = duplicated[0].RAND
+
+	tests := []struct {
+		name     string
+		triplets []vector.Triplet
+	}{
+		{"トリプレット1個", makeTestTriplets(1)},
+		{"RAND重複", duplicated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, mockVector, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+			eng.cfg.SIMRandCount = 2
+
+			mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+				Return(makeSIMStartContext(testIMSI, testSIMIdentity, 0), nil)
+			expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(nil)
+			mockVector.EXPECT().GetVector(gomock.Any(), gomock.Any()).
+				Return(&vector.VectorResponse{Triplets: tt.triplets}, nil)
+			mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+			req := &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   testSIMIdentity,
+				State:      []byte(testTraceID),
+				EAPMessage: buildSIMStartResponse(t, 2, ""),
+			}
+
+			result, err := eng.Process(context.Background(), req)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionReject {
+				t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+			}
+		})
+	}
+}
+
+func TestEngine_SIM_ChallengeSuccess_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	triplets := makeTestTriplets(2)
+	eapCtx, keys := makeSIMChallengeContext(triplets)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
+		Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), matchPolicyAttributes(testNASID, testSSID)).
+		Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	req := &eap.Request{
+		TraceID:       testTraceID,
+		NASIdentifier: testNASID,
+		CalledStation: "AA-BB-CC-DD-EE-FF:" + testSSID,
+		UserName:      testSIMIdentity,
+		State:         []byte(testTraceID),
+		EAPMessage:    buildSIMChallengeResponse(t, 3, keys.K_aut, [][]byte{triplets[0].SRES, triplets[1].SRES}),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+	if !bytes.Equal(result.MSK, keys.MSK) {
+		t.Error("MSKが導出値と一致しない")
+	}
+}
+
+func TestEngine_SIM_ChallengeWrongSRES_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	triplets := makeTestTriplets(2)
+	eapCtx, keys := makeSIMChallengeContext(triplets)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	wrong := [][]byte{triplets[0].SRES, make([]byte, 4)}
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testSIMIdentity,
+		State:      []byte(testTraceID),
+		EAPMessage: buildSIMChallengeResponse(t, 3, keys.K_aut, wrong),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_SIM_TypeMismatch_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+	eapCtx, keys := makeSIMChallengeContext(makeTestTriplets(2))
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	// SIMコンテキストに対するAKA-Challenge応答
+	req := &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testSIMIdentity,
+		State:      []byte(testTraceID),
+		EAPMessage: buildChallengeResponseEAPMessage(3, eapaka.TypeAKA, keys.K_aut, testXRES),
+	}
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
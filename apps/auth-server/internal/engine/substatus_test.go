@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEngine_SubscriberStatus_Reject(t *testing.T) {
+	tests := []struct {
+		name   string
+		status *store.SubscriberStatus
+		err    error
+	}{
+		{"barred", &store.SubscriberStatus{Status: "barred"}, nil},
+		{"suspended", &store.SubscriberStatus{Status: "suspended"}, nil},
+		{"unknown status", &store.SubscriberStatus{Status: "disabled"}, nil},
+		{"not yet valid", &store.SubscriberStatus{ValidFrom: "2999-01-01T00:00:00Z"}, nil},
+		{"expired", &store.SubscriberStatus{Status: "active", ValidUntil: "2000-01-01T00:00:00Z"}, nil},
+		{"store error", nil, errors.New("valkey down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+			mockSubStore := mocks.NewMockSubscriberStore(ctrl)
+			eng.subStore = mockSubStore
+
+			// ポリシーは参照せずに拒否する
+			req, eapCtx, _ := challengeSuccessRequest(false)
+			mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+			mockSubStore.EXPECT().GetSubscriberStatus(gomock.Any(), testIMSI).Return(tt.status, tt.err)
+			mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+			result, err := eng.Process(context.Background(), req)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionReject {
+				t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+			}
+		})
+	}
+}
+
+func TestEngine_SubscriberStatus_Active_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	mockSubStore := mocks.NewMockSubscriberStore(ctrl)
+	eng.subStore = mockSubStore
+
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockSubStore.EXPECT().GetSubscriberStatus(gomock.Any(), testIMSI).Return(&store.SubscriberStatus{
+		Status:     "active",
+		ValidFrom:  "2000-01-01T00:00:00Z",
+		ValidUntil: "2999-01-01T00:00:00Z",
+	}, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).Return(nil)
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
+
+func TestEngine_SubscriberStatus_ResultInd_NotificationCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		code   uint16
+	}{
+		{"barred", "barred", eap.NotificationNotSubscribed},
+		{"suspended", "suspended", eap.NotificationTemporarilyDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, m := newResultIndTestEngine(ctrl)
+			mockSubStore := mocks.NewMockSubscriberStore(ctrl)
+			eng.subStore = mockSubStore
+
+			req, eapCtx, _ := challengeSuccessRequest(true)
+			m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+			mockSubStore.EXPECT().GetSubscriberStatus(gomock.Any(), testIMSI).Return(&store.SubscriberStatus{Status: tt.status}, nil)
+			m.ctxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Any()).Return(nil)
+
+			result, err := eng.Process(context.Background(), req)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionChallenge {
+				t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+			}
+			_, notif := parseNotification(t, result.EAPMessage)
+			if notif.Code != tt.code {
+				t.Errorf("通知コード: got %d, want %d", notif.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestEngine_SubscriberStatus_ReauthResponse_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 高速再認証はVector APIを経由しないため、認証成功後に利用状態で拒否する
+	eng, m := newReauthTestEngine(ctrl)
+	mockSubStore := mocks.NewMockSubscriberStore(ctrl)
+	eng.subStore = mockSubStore
+
+	m.ctxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(makeReauthSentContext(2, "4next"), nil)
+	mockSubStore.EXPECT().GetSubscriberStatus(gomock.Any(), testIMSI).Return(&store.SubscriberStatus{Status: "barred"}, nil)
+	m.ctxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   testReauthUserName,
+		State:      []byte(testTraceID),
+		EAPMessage: buildReauthResponseEAPMessage(t, 2, 2, false, testReauthKAut),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	eapaka "github.com/oyaguma3/go-eapaka"
+)
+
+// deconcealIdentity は秘匿化ID（SUCI）をホームネットワーク秘密鍵で復号し、identityを永続IDに置き換える
+// SUCIは方式を示すプレフィックスを持たないため、EAPパケットのType（AKA/AKA'）に従い、
+// EAP-Response/Identity等で判別できない場合はEAP-AKA'とする
+// 復号できない場合（未知の鍵ID・MAC不一致等）はfalseを返す
+func (e *EngineImpl) deconcealIdentity(traceID string, identity *eap.ParsedIdentity, eapType uint8) bool {
+	if eapType != eapaka.TypeAKA {
+		eapType = eapaka.TypeAKAPrime
+	}
+
+	imsi, err := e.keyring.Deconceal(identity.SUCI)
+	if err != nil {
+		slog.Warn("秘匿化ID復号失敗",
+			"event_id", "EAP_SUCI_DECONCEAL_FAILED",
+			"trace_id", traceID,
+			"scheme", identity.SUCI.Scheme.String(),
+			"key_id", identity.SUCI.KeyID,
+			"error", err,
+		)
+		return false
+	}
+
+	identity.SetDeconcealed(imsi, eapType)
+	slog.Info("秘匿化ID復号成功",
+		"event_id", "EAP_SUCI_DECONCEALED",
+		"trace_id", traceID,
+		"imsi", e.maskIMSI(imsi),
+		"scheme", identity.SUCI.Scheme.String(),
+		"key_id", identity.SUCI.KeyID,
+	)
+	return true
+}
@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/suci"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// newTestKeyring はProfile A（鍵ID 1）とProfile B（鍵ID 2）の鍵を持つKeyringと公開鍵を生成する
+func newTestKeyring(t *testing.T) (*suci.Keyring, map[uint8][]byte) {
+	t.Helper()
+	kf := &suci.KeyFile{}
+	pubs := map[uint8][]byte{}
+	for id, scheme := range map[uint8]suci.Scheme{1: suci.SchemeProfileA, 2: suci.SchemeProfileB} {
+		key, err := kf.Generate(id, scheme)
+		if err != nil {
+			t.Fatalf("鍵生成失敗: %v", err)
+		}
+		if pubs[id], err = key.PublicKey(); err != nil {
+			t.Fatalf("公開鍵取得失敗: %v", err)
+		}
+	}
+	kr, err := suci.NewKeyring(kf)
+	if err != nil {
+		t.Fatalf("Keyring生成失敗: %v", err)
+	}
+	return kr, pubs
+}
+
+// concealTestIMSI はtestIMSIをSUCI形式に秘匿化する
+func concealTestIMSI(t *testing.T, scheme suci.Scheme, keyID uint8, pub []byte) string {
+	t.Helper()
+	nai, err := suci.Conceal(scheme, keyID, pub, testIMSI, 2)
+	if err != nil {
+		t.Fatalf("秘匿化失敗: %v", err)
+	}
+	return nai
+}
+
+// buildEAPResponseIdentity はRFC 3748のEAP-Response/Identity（Type=1）を構築する
+func buildEAPResponseIdentity(identifier uint8, identity string) []byte {
+	length := 5 + len(identity)
+	msg := []byte{eapaka.CodeResponse, identifier, byte(length >> 8), byte(length), eap.EAPTypeIdentity}
+	return append(msg, identity...)
+}
+
+// newSUCITestEngine はKeyringを設定したエンジンとモックを生成する
+func newSUCITestEngine(ctrl *gomock.Controller, kr *suci.Keyring) (*EngineImpl, *mocks.MockVectorClient, *mocks.MockContextStore) {
+	eng, m := newTestEngine(ctrl, newTestConfig(), WithKeyring(kr))
+	return eng, m.vector, m.ctxStore
+}
+
+func TestEngine_ConcealedIdentity_Challenge(t *testing.T) {
+	kr, pubs := newTestKeyring(t)
+
+	tests := []struct {
+		name     string
+		scheme   suci.Scheme
+		keyID    uint8
+		eapMsg   func(userName string) []byte
+		wantType uint8
+	}{
+		{
+			name:     "Profile A（EAP-Response/Identity→AKA'）",
+			scheme:   suci.SchemeProfileA,
+			keyID:    1,
+			eapMsg:   func(userName string) []byte { return buildEAPResponseIdentity(1, userName) },
+			wantType: eapaka.TypeAKAPrime,
+		},
+		{
+			name:     "Profile B（EAP-Response/Identity→AKA'）",
+			scheme:   suci.SchemeProfileB,
+			keyID:    2,
+			eapMsg:   func(userName string) []byte { return buildEAPResponseIdentity(1, userName) },
+			wantType: eapaka.TypeAKAPrime,
+		},
+		{
+			name:     "Profile A（EAP-AKA Identity→AKA）",
+			scheme:   suci.SchemeProfileA,
+			keyID:    1,
+			eapMsg:   func(string) []byte { return buildIdentityEAPMessage(1, eapaka.TypeAKA) },
+			wantType: eapaka.TypeAKA,
+		},
+		{
+			name:     "Null-scheme",
+			scheme:   suci.SchemeNull,
+			eapMsg:   func(userName string) []byte { return buildEAPResponseIdentity(1, userName) },
+			wantType: eapaka.TypeAKAPrime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, mockVector, mockCtxStore := newSUCITestEngine(ctrl, kr)
+			userName := concealTestIMSI(t, tt.scheme, tt.keyID, pubs[tt.keyID])
+
+			mockCtxStore.EXPECT().Create(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+			mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+				Return(&vector.VectorResponse{
+					RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+				}, nil)
+			mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, updates map[string]any) error {
+					// 鍵導出にはピアが送信した秘匿化IDをそのまま使用する
+					if updates["identity"] != userName {
+						t.Errorf("identity: got %v, want %q", updates["identity"], userName)
+					}
+					if updates["imsi"] != testIMSI {
+						t.Errorf("imsi: got %v, want %q", updates["imsi"], testIMSI)
+					}
+					return nil
+				})
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   userName,
+				EAPMessage: tt.eapMsg(userName),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionChallenge {
+				t.Fatalf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+			}
+			if result.IMSI != testIMSI {
+				t.Errorf("IMSI: got %q, want %q", result.IMSI, testIMSI)
+			}
+			pkt, err := eapaka.Parse(result.EAPMessage)
+			if err != nil {
+				t.Fatalf("パース失敗: %v", err)
+			}
+			if pkt.Type != tt.wantType || pkt.Subtype != eapaka.SubtypeChallenge {
+				t.Errorf("Type/Subtype: got %d/%d, want %d/%d", pkt.Type, pkt.Subtype, tt.wantType, eapaka.SubtypeChallenge)
+			}
+		})
+	}
+}
+
+func TestEngine_ConcealedIdentity_Reject(t *testing.T) {
+	kr, pubs := newTestKeyring(t)
+	_, otherPubs := newTestKeyring(t)
+
+	tests := []struct {
+		name     string
+		keyring  *suci.Keyring
+		userName string
+	}{
+		{"未知の鍵ID", kr, concealTestIMSI(t, suci.SchemeProfileA, 9, pubs[1])},
+		{"保護方式と鍵の不一致", kr, concealTestIMSI(t, suci.SchemeProfileB, 1, pubs[2])},
+		{"別の鍵で秘匿化（MAC不一致）", kr, concealTestIMSI(t, suci.SchemeProfileA, 1, otherPubs[1])},
+		{"Keyring未設定", nil, concealTestIMSI(t, suci.SchemeProfileA, 1, pubs[1])},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Vector要求・コンテキスト作成は行わない
+			eng, _, _ := newSUCITestEngine(ctrl, tt.keyring)
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   tt.userName,
+				EAPMessage: buildEAPResponseIdentity(1, tt.userName),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionReject {
+				t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+			}
+		})
+	}
+}
+
+func TestEngine_IdentityResponse_Concealed(t *testing.T) {
+	kr, pubs := newTestKeyring(t)
+
+	t.Run("AT_PERMANENT_ID_REQへの秘匿化ID応答", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		eng, mockVector, mockCtxStore := newSUCITestEngine(ctrl, kr)
+		userName := concealTestIMSI(t, suci.SchemeProfileB, 2, pubs[2])
+
+		mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+			Return(makeWaitingIdentityContext(eap.IdentityReqPermanent), nil)
+		mockCtxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), map[string]any{
+			"imsi":     testIMSI,
+			"stage":    string(eap.StateIdentityReceived),
+			"eap_type": uint8(eapaka.TypeAKA),
+		}).Return(nil)
+		mockVector.EXPECT().GetVector(gomock.Any(), &vector.VectorRequest{IMSI: testIMSI}).
+			Return(&vector.VectorResponse{
+				RAND: testRAND, AUTN: testAUTN, XRES: testXRES, CK: testCK, IK: testIK,
+			}, nil)
+		mockCtxStore.EXPECT().Update(gomock.Any(), testTraceID, gomock.Any()).Return(nil)
+
+		result, err := eng.Process(context.Background(), &eap.Request{
+			TraceID:    testTraceID,
+			UserName:   "anonymous@realm",
+			State:      []byte(testTraceID),
+			EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, userName),
+		})
+		if err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+		if result.Action != eap.ActionChallenge {
+			t.Errorf("Action: got %v, want %v", result.Action, eap.ActionChallenge)
+		}
+	})
+
+	t.Run("復号失敗", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		eng, _, mockCtxStore := newSUCITestEngine(ctrl, kr)
+		userName := concealTestIMSI(t, suci.SchemeProfileA, 7, pubs[1])
+
+		mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+			Return(makeWaitingIdentityContext(eap.IdentityReqPermanent), nil)
+		mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+		result, err := eng.Process(context.Background(), &eap.Request{
+			TraceID:    testTraceID,
+			UserName:   "anonymous@realm",
+			State:      []byte(testTraceID),
+			EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, userName),
+		})
+		if err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+		if result.Action != eap.ActionReject {
+			t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+		}
+	})
+}
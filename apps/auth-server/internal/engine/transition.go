@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+)
+
+// transitionContext はEAPコンテキストのstage・resync_countが読み込み時から変わっていない場合のみ更新する（Compare-and-Set）
+// 同一コンテキストへの並行リクエスト（再送・複数auth-server）に先を越された場合は応答せずに破棄し、Vectorの重複取得を防ぐ
+// 更新に成功した場合はnilを返す
+func (e *EngineImpl) transitionContext(ctx context.Context, traceID string, eapCtx *session.EAPContext, identifier uint8, updates map[string]any) *eap.Result {
+	expected := map[string]any{
+		"stage":        eapCtx.Stage,
+		"resync_count": eapCtx.ResyncCount,
+	}
+	err := e.ctxStore.CompareAndUpdate(ctx, traceID, expected, updates)
+	if err == nil {
+		return nil
+	}
+
+	// 他のリクエストが遷移済み・削除済み → 破棄
+	if errors.Is(err, session.ErrContextConflict) || errors.Is(err, session.ErrContextNotFound) {
+		slog.Info("EAPコンテキスト競合のため破棄",
+			"event_id", "EAP_CTX_CONFLICT",
+			"trace_id", traceID,
+			"stage", eapCtx.Stage,
+			"next_stage", updates["stage"],
+			"error", err,
+		)
+		return &eap.Result{Action: eap.ActionDrop}
+	}
+
+	slog.Error("EAPコンテキスト更新失敗",
+		"event_id", "EAP_CTX_UPDATE_ERR",
+		"trace_id", traceID,
+		"error", err,
+	)
+	return e.buildReject(identifier + 1)
+}
@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	eapaka "github.com/oyaguma3/go-eapaka"
+	"go.uber.org/mock/gomock"
+)
+
+// expectTransition は指定stageへのCompare-and-Set遷移を期待する
+func expectTransition(m *mocks.MockContextStore, stage eap.EAPState) *gomock.Call {
+	return m.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID, gomock.Any(), gomock.Cond(func(u map[string]any) bool {
+		return u["stage"] == string(stage)
+	}))
+}
+
+func TestEngine_Resync_Conflict_Drop(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "他リクエストが遷移済み", err: session.ErrContextConflict},
+		{name: "他リクエストが削除済み", err: session.ErrContextNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+			keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+			eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+			eapCtx.ResyncCount = 1
+
+			// 競合に敗れた場合はVectorを取得せず、コンテキストも削除しない
+			mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+			mockCtxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID,
+				map[string]any{"stage": string(eap.StateChallengeSent), "resync_count": 1},
+				map[string]any{"stage": string(eap.StateResyncSent), "resync_count": 2},
+			).Return(tt.err)
+
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				UserName:   "0" + testIMSI + "@realm",
+				State:      []byte(testTraceID),
+				EAPMessage: buildSyncFailureEAPMessage(2, eapaka.TypeAKA, make([]byte, 14)),
+			})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Action != eap.ActionDrop {
+				t.Errorf("Action: got %v, want %v", result.Action, eap.ActionDrop)
+			}
+		})
+	}
+}
+
+func TestEngine_IdentityResponse_Conflict_Drop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		Return(makeWaitingIdentityContext(eap.IdentityReqPermanent), nil)
+	expectTransition(mockCtxStore, eap.StateIdentityReceived).Return(session.ErrContextConflict)
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		State:      []byte(testTraceID),
+		EAPMessage: buildAKAIdentityResponse(2, eapaka.TypeAKA, "0"+testIMSI+"@realm"),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionDrop {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionDrop)
+	}
+}
+
+func TestEngine_Resync_TransitionError_Reject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, _, _ := newChallengeTestEngine(ctrl)
+
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+	eapCtx := makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK)
+
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	expectTransition(mockCtxStore, eap.StateResyncSent).Return(errors.New("valkey error"))
+
+	result, err := eng.Process(context.Background(), &eap.Request{
+		TraceID:    testTraceID,
+		UserName:   "0" + testIMSI + "@realm",
+		State:      []byte(testTraceID),
+		EAPMessage: buildSyncFailureEAPMessage(2, eapaka.TypeAKA, make([]byte, 14)),
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionReject {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionReject)
+	}
+}
+
+func TestEngine_ChallengeSuccess_ConcurrentResponse_Drop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+	keys := eapaka.DeriveKeysAKA("0"+testIMSI+"@realm", testCK, testIK)
+
+	// 同一Stateの応答が並行して検証を通過しても、成功への遷移を確定できるのは1件のみ
+	var mu sync.Mutex
+	claimed := false
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).
+		DoAndReturn(func(context.Context, string) (*session.EAPContext, error) {
+			return makeChallengeContext(eapaka.TypeAKA, keys.K_aut, testXRES, keys.MSK), nil
+		}).Times(2)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).
+		Return(&policy.Policy{Default: "allow"}, nil).Times(2)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).
+		Return(&policy.EvaluationResult{Allowed: true}).Times(2)
+	mockCtxStore.EXPECT().CompareAndUpdate(gomock.Any(), testTraceID,
+		map[string]any{"stage": string(eap.StateChallengeSent), "resync_count": 0},
+		map[string]any{"stage": string(eap.StateSuccess)},
+	).DoAndReturn(func(context.Context, string, map[string]any, map[string]any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if claimed {
+			return session.ErrContextConflict
+		}
+		claimed = true
+		return nil
+	}).Times(2)
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).Return(nil)
+
+	var wg sync.WaitGroup
+	results := make([]*eap.Result, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := eng.Process(context.Background(), &eap.Request{
+				TraceID:    testTraceID,
+				SrcIP:      "192.168.1.1",
+				UserName:   "0" + testIMSI + "@realm",
+				State:      []byte(testTraceID),
+				EAPMessage: buildChallengeResponseEAPMessage(2, eapaka.TypeAKA, keys.K_aut, testXRES),
+			})
+			if err != nil {
+				t.Errorf("予期しないエラー: %v", err)
+				return
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	actions := map[eap.Action]int{}
+	for _, r := range results {
+		if r != nil {
+			actions[r.Action]++
+		}
+	}
+	if actions[eap.ActionAccept] != 1 || actions[eap.ActionDrop] != 1 {
+		t.Errorf("Action: got %v, want Accept×1 / Drop×1", actions)
+	}
+}
+
+func TestEngine_ChallengeSuccess_DeadlineExceeded_Drop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, _, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+
+	// 成功を確定する前に期限を超過 → 遷移・削除を行わずに応答なし（再送で改めて処理する）
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(&policy.EvaluationResult{Allowed: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := eng.Process(ctx, req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionDrop {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionDrop)
+	}
+}
+
+func TestEngine_ChallengeSuccess_DeadlineExceededAfterCommit_Accept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	eng, _, mockCtxStore, mockSessStore, mockPolicyStore, mockEvaluator := newChallengeTestEngine(ctrl)
+
+	// 成功の確定直後に期限を超過 → セッション作成等を完了させて認証成功
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notCanceled := func(ctx context.Context) {
+		if ctx.Err() != nil {
+			t.Error("確定後の処理に期限超過のコンテキストが渡された")
+		}
+	}
+
+	req, eapCtx, _ := challengeSuccessRequest(false)
+	mockCtxStore.EXPECT().Get(gomock.Any(), testTraceID).Return(eapCtx, nil)
+	mockPolicyStore.EXPECT().GetPolicy(gomock.Any(), testIMSI, gomock.Any()).Return(&policy.Policy{Default: "allow"}, nil)
+	mockEvaluator.EXPECT().Evaluate(gomock.Any(), gomock.Any()).Return(&policy.EvaluationResult{Allowed: true})
+	expectTransition(mockCtxStore, eap.StateSuccess).
+		DoAndReturn(func(context.Context, string, map[string]any, map[string]any) error {
+			cancel()
+			return nil
+		})
+	mockSessStore.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ *session.Session) error {
+			notCanceled(ctx)
+			return nil
+		})
+	mockSessStore.EXPECT().AddUserIndex(gomock.Any(), testIMSI, gomock.Any(), 0).Return(true, nil)
+	mockCtxStore.EXPECT().Delete(gomock.Any(), testTraceID).
+		DoAndReturn(func(ctx context.Context, _ string) error {
+			notCanceled(ctx)
+			return nil
+		})
+
+	result, err := eng.Process(ctx, req)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if result.Action != eap.ActionAccept {
+		t.Errorf("Action: got %v, want %v", result.Action, eap.ActionAccept)
+	}
+}
@@ -42,18 +42,18 @@ func (m *MockPolicyStore) EXPECT() *MockPolicyStoreMockRecorder {
 }
 
 // GetPolicy mocks base method.
-func (m *MockPolicyStore) GetPolicy(ctx context.Context, imsi string) (*policy.Policy, error) {
+func (m *MockPolicyStore) GetPolicy(ctx context.Context, imsi, clientIP string) (*policy.Policy, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPolicy", ctx, imsi)
+	ret := m.ctrl.Call(m, "GetPolicy", ctx, imsi, clientIP)
 	ret0, _ := ret[0].(*policy.Policy)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPolicy indicates an expected call of GetPolicy.
-func (mr *MockPolicyStoreMockRecorder) GetPolicy(ctx, imsi any) *gomock.Call {
+func (mr *MockPolicyStoreMockRecorder) GetPolicy(ctx, imsi, clientIP any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockPolicyStore)(nil).GetPolicy), ctx, imsi)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPolicy", reflect.TypeOf((*MockPolicyStore)(nil).GetPolicy), ctx, imsi, clientIP)
 }
 
 // MockEvaluator is a mock of Evaluator interface.
@@ -81,15 +81,15 @@ func (m *MockEvaluator) EXPECT() *MockEvaluatorMockRecorder {
 }
 
 // Evaluate mocks base method.
-func (m *MockEvaluator) Evaluate(arg0 *policy.Policy, nasID, ssid string) *policy.EvaluationResult {
+func (m *MockEvaluator) Evaluate(arg0 *policy.Policy, attrs *policy.Attributes) *policy.EvaluationResult {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Evaluate", arg0, nasID, ssid)
+	ret := m.ctrl.Call(m, "Evaluate", arg0, attrs)
 	ret0, _ := ret[0].(*policy.EvaluationResult)
 	return ret0
 }
 
 // Evaluate indicates an expected call of Evaluate.
-func (mr *MockEvaluatorMockRecorder) Evaluate(arg0, nasID, ssid any) *gomock.Call {
+func (mr *MockEvaluatorMockRecorder) Evaluate(arg0, attrs any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockEvaluator)(nil).Evaluate), arg0, nasID, ssid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockEvaluator)(nil).Evaluate), arg0, attrs)
 }
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	session "github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
+	vector "github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +42,20 @@ func (m *MockContextStore) EXPECT() *MockContextStoreMockRecorder {
 	return m.recorder
 }
 
+// CompareAndUpdate mocks base method.
+func (m *MockContextStore) CompareAndUpdate(ctx context.Context, traceID string, expected, updates map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndUpdate", ctx, traceID, expected, updates)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompareAndUpdate indicates an expected call of CompareAndUpdate.
+func (mr *MockContextStoreMockRecorder) CompareAndUpdate(ctx, traceID, expected, updates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndUpdate", reflect.TypeOf((*MockContextStore)(nil).CompareAndUpdate), ctx, traceID, expected, updates)
+}
+
 // Create mocks base method.
 func (m *MockContextStore) Create(ctx context.Context, traceID string, eapCtx *session.EAPContext) error {
 	m.ctrl.T.Helper()
@@ -138,17 +153,18 @@ func (m *MockSessionStore) EXPECT() *MockSessionStoreMockRecorder {
 }
 
 // AddUserIndex mocks base method.
-func (m *MockSessionStore) AddUserIndex(ctx context.Context, imsi, sessionID string) error {
+func (m *MockSessionStore) AddUserIndex(ctx context.Context, imsi, sessionID string, maxSessions int) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddUserIndex", ctx, imsi, sessionID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "AddUserIndex", ctx, imsi, sessionID, maxSessions)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // AddUserIndex indicates an expected call of AddUserIndex.
-func (mr *MockSessionStoreMockRecorder) AddUserIndex(ctx, imsi, sessionID any) *gomock.Call {
+func (mr *MockSessionStoreMockRecorder) AddUserIndex(ctx, imsi, sessionID, maxSessions any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserIndex", reflect.TypeOf((*MockSessionStore)(nil).AddUserIndex), ctx, imsi, sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserIndex", reflect.TypeOf((*MockSessionStore)(nil).AddUserIndex), ctx, imsi, sessionID, maxSessions)
 }
 
 // Create mocks base method.
@@ -165,6 +181,20 @@ func (mr *MockSessionStoreMockRecorder) Create(ctx, sessionID, sess any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionStore)(nil).Create), ctx, sessionID, sess)
 }
 
+// Delete mocks base method.
+func (m *MockSessionStore) Delete(ctx context.Context, imsi, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, imsi, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSessionStoreMockRecorder) Delete(ctx, imsi, sessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionStore)(nil).Delete), ctx, imsi, sessionID)
+}
+
 // Get mocks base method.
 func (m *MockSessionStore) Get(ctx context.Context, sessionID string) (*session.Session, error) {
 	m.ctrl.T.Helper()
@@ -179,3 +209,394 @@ func (mr *MockSessionStoreMockRecorder) Get(ctx, sessionID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionStore)(nil).Get), ctx, sessionID)
 }
+
+// ListByIMSI mocks base method.
+func (m *MockSessionStore) ListByIMSI(ctx context.Context, imsi string) ([]*session.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByIMSI", ctx, imsi)
+	ret0, _ := ret[0].([]*session.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByIMSI indicates an expected call of ListByIMSI.
+func (mr *MockSessionStoreMockRecorder) ListByIMSI(ctx, imsi any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByIMSI", reflect.TypeOf((*MockSessionStore)(nil).ListByIMSI), ctx, imsi)
+}
+
+// MockPseudonymStore is a mock of PseudonymStore interface.
+type MockPseudonymStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPseudonymStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockPseudonymStoreMockRecorder is the mock recorder for MockPseudonymStore.
+type MockPseudonymStoreMockRecorder struct {
+	mock *MockPseudonymStore
+}
+
+// NewMockPseudonymStore creates a new mock instance.
+func NewMockPseudonymStore(ctrl *gomock.Controller) *MockPseudonymStore {
+	mock := &MockPseudonymStore{ctrl: ctrl}
+	mock.recorder = &MockPseudonymStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPseudonymStore) EXPECT() *MockPseudonymStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPseudonymStore) Create(ctx context.Context, pseudonym string, entry *session.PseudonymEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, pseudonym, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPseudonymStoreMockRecorder) Create(ctx, pseudonym, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPseudonymStore)(nil).Create), ctx, pseudonym, entry)
+}
+
+// Delete mocks base method.
+func (m *MockPseudonymStore) Delete(ctx context.Context, pseudonym string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, pseudonym)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockPseudonymStoreMockRecorder) Delete(ctx, pseudonym any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPseudonymStore)(nil).Delete), ctx, pseudonym)
+}
+
+// Get mocks base method.
+func (m *MockPseudonymStore) Get(ctx context.Context, pseudonym string) (*session.PseudonymEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, pseudonym)
+	ret0, _ := ret[0].(*session.PseudonymEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPseudonymStoreMockRecorder) Get(ctx, pseudonym any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPseudonymStore)(nil).Get), ctx, pseudonym)
+}
+
+// MockReauthStore is a mock of ReauthStore interface.
+type MockReauthStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockReauthStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockReauthStoreMockRecorder is the mock recorder for MockReauthStore.
+type MockReauthStoreMockRecorder struct {
+	mock *MockReauthStore
+}
+
+// NewMockReauthStore creates a new mock instance.
+func NewMockReauthStore(ctrl *gomock.Controller) *MockReauthStore {
+	mock := &MockReauthStore{ctrl: ctrl}
+	mock.recorder = &MockReauthStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReauthStore) EXPECT() *MockReauthStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReauthStore) Create(ctx context.Context, reauthID string, rc *session.ReauthContext) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, reauthID, rc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReauthStoreMockRecorder) Create(ctx, reauthID, rc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReauthStore)(nil).Create), ctx, reauthID, rc)
+}
+
+// Delete mocks base method.
+func (m *MockReauthStore) Delete(ctx context.Context, reauthID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, reauthID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockReauthStoreMockRecorder) Delete(ctx, reauthID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReauthStore)(nil).Delete), ctx, reauthID)
+}
+
+// Get mocks base method.
+func (m *MockReauthStore) Get(ctx context.Context, reauthID string) (*session.ReauthContext, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, reauthID)
+	ret0, _ := ret[0].(*session.ReauthContext)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockReauthStoreMockRecorder) Get(ctx, reauthID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockReauthStore)(nil).Get), ctx, reauthID)
+}
+
+// MockERPStore is a mock of ERPStore interface.
+type MockERPStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockERPStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockERPStoreMockRecorder is the mock recorder for MockERPStore.
+type MockERPStoreMockRecorder struct {
+	mock *MockERPStore
+}
+
+// NewMockERPStore creates a new mock instance.
+func NewMockERPStore(ctrl *gomock.Controller) *MockERPStore {
+	mock := &MockERPStore{ctrl: ctrl}
+	mock.recorder = &MockERPStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockERPStore) EXPECT() *MockERPStoreMockRecorder {
+	return m.recorder
+}
+
+// AdvanceSeq mocks base method.
+func (m *MockERPStore) AdvanceSeq(ctx context.Context, keyName string, seq uint16) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceSeq", ctx, keyName, seq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceSeq indicates an expected call of AdvanceSeq.
+func (mr *MockERPStoreMockRecorder) AdvanceSeq(ctx, keyName, seq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceSeq", reflect.TypeOf((*MockERPStore)(nil).AdvanceSeq), ctx, keyName, seq)
+}
+
+// Create mocks base method.
+func (m *MockERPStore) Create(ctx context.Context, keyName string, key *session.ERPKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, keyName, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockERPStoreMockRecorder) Create(ctx, keyName, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockERPStore)(nil).Create), ctx, keyName, key)
+}
+
+// Get mocks base method.
+func (m *MockERPStore) Get(ctx context.Context, keyName string) (*session.ERPKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, keyName)
+	ret0, _ := ret[0].(*session.ERPKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockERPStoreMockRecorder) Get(ctx, keyName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockERPStore)(nil).Get), ctx, keyName)
+}
+
+// MockLockoutStore is a mock of LockoutStore interface.
+type MockLockoutStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockoutStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockLockoutStoreMockRecorder is the mock recorder for MockLockoutStore.
+type MockLockoutStoreMockRecorder struct {
+	mock *MockLockoutStore
+}
+
+// NewMockLockoutStore creates a new mock instance.
+func NewMockLockoutStore(ctrl *gomock.Controller) *MockLockoutStore {
+	mock := &MockLockoutStore{ctrl: ctrl}
+	mock.recorder = &MockLockoutStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLockoutStore) EXPECT() *MockLockoutStoreMockRecorder {
+	return m.recorder
+}
+
+// ClearFailures mocks base method.
+func (m *MockLockoutStore) ClearFailures(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearFailures", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearFailures indicates an expected call of ClearFailures.
+func (mr *MockLockoutStoreMockRecorder) ClearFailures(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearFailures", reflect.TypeOf((*MockLockoutStore)(nil).ClearFailures), ctx, id)
+}
+
+// IsLocked mocks base method.
+func (m *MockLockoutStore) IsLocked(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsLocked", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsLocked indicates an expected call of IsLocked.
+func (mr *MockLockoutStoreMockRecorder) IsLocked(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLocked", reflect.TypeOf((*MockLockoutStore)(nil).IsLocked), ctx, id)
+}
+
+// RecordFailure mocks base method.
+func (m *MockLockoutStore) RecordFailure(ctx context.Context, id, reason string) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, id, reason)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockLockoutStoreMockRecorder) RecordFailure(ctx, id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockLockoutStore)(nil).RecordFailure), ctx, id, reason)
+}
+
+// MockRateLimiter is a mock of RateLimiter interface.
+type MockRateLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimiterMockRecorder
+	isgomock struct{}
+}
+
+// MockRateLimiterMockRecorder is the mock recorder for MockRateLimiter.
+type MockRateLimiterMockRecorder struct {
+	mock *MockRateLimiter
+}
+
+// NewMockRateLimiter creates a new mock instance.
+func NewMockRateLimiter(ctrl *gomock.Controller) *MockRateLimiter {
+	mock := &MockRateLimiter{ctrl: ctrl}
+	mock.recorder = &MockRateLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimiter) EXPECT() *MockRateLimiterMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockRateLimiter) Allow(ctx context.Context, kind session.RateLimitKind, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", ctx, kind, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockRateLimiterMockRecorder) Allow(ctx, kind, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimiter)(nil).Allow), ctx, kind, id)
+}
+
+// MockVectorCache is a mock of VectorCache interface.
+type MockVectorCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockVectorCacheMockRecorder
+	isgomock struct{}
+}
+
+// MockVectorCacheMockRecorder is the mock recorder for MockVectorCache.
+type MockVectorCacheMockRecorder struct {
+	mock *MockVectorCache
+}
+
+// NewMockVectorCache creates a new mock instance.
+func NewMockVectorCache(ctrl *gomock.Controller) *MockVectorCache {
+	mock := &MockVectorCache{ctrl: ctrl}
+	mock.recorder = &MockVectorCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVectorCache) EXPECT() *MockVectorCacheMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MockVectorCache) Flush(ctx context.Context, imsi string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx, imsi)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockVectorCacheMockRecorder) Flush(ctx, imsi any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockVectorCache)(nil).Flush), ctx, imsi)
+}
+
+// Pop mocks base method.
+func (m *MockVectorCache) Pop(ctx context.Context, imsi string) (*vector.Quintet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pop", ctx, imsi)
+	ret0, _ := ret[0].(*vector.Quintet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pop indicates an expected call of Pop.
+func (mr *MockVectorCacheMockRecorder) Pop(ctx, imsi any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pop", reflect.TypeOf((*MockVectorCache)(nil).Pop), ctx, imsi)
+}
+
+// Store mocks base method.
+func (m *MockVectorCache) Store(ctx context.Context, imsi string, usedSQN uint64, vectors []vector.Quintet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, imsi, usedSQN, vectors)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockVectorCacheMockRecorder) Store(ctx, imsi, usedSQN, vectors any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockVectorCache)(nil).Store), ctx, imsi, usedSQN, vectors)
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	store "github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +41,21 @@ func (m *MockClientStore) EXPECT() *MockClientStoreMockRecorder {
 	return m.recorder
 }
 
+// GetClient mocks base method.
+func (m *MockClientStore) GetClient(ctx context.Context, ip string) (*store.RadiusClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClient", ctx, ip)
+	ret0, _ := ret[0].(*store.RadiusClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClient indicates an expected call of GetClient.
+func (mr *MockClientStoreMockRecorder) GetClient(ctx, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClient", reflect.TypeOf((*MockClientStore)(nil).GetClient), ctx, ip)
+}
+
 // GetClientSecret mocks base method.
 func (m *MockClientStore) GetClientSecret(ctx context.Context, ip string) (string, error) {
 	m.ctrl.T.Helper()
@@ -54,3 +70,42 @@ func (mr *MockClientStoreMockRecorder) GetClientSecret(ctx, ip any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientSecret", reflect.TypeOf((*MockClientStore)(nil).GetClientSecret), ctx, ip)
 }
+
+// MockSubscriberStore is a mock of SubscriberStore interface.
+type MockSubscriberStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriberStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockSubscriberStoreMockRecorder is the mock recorder for MockSubscriberStore.
+type MockSubscriberStoreMockRecorder struct {
+	mock *MockSubscriberStore
+}
+
+// NewMockSubscriberStore creates a new mock instance.
+func NewMockSubscriberStore(ctrl *gomock.Controller) *MockSubscriberStore {
+	mock := &MockSubscriberStore{ctrl: ctrl}
+	mock.recorder = &MockSubscriberStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriberStore) EXPECT() *MockSubscriberStoreMockRecorder {
+	return m.recorder
+}
+
+// GetSubscriberStatus mocks base method.
+func (m *MockSubscriberStore) GetSubscriberStatus(ctx context.Context, imsi string) (*store.SubscriberStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriberStatus", ctx, imsi)
+	ret0, _ := ret[0].(*store.SubscriberStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriberStatus indicates an expected call of GetSubscriberStatus.
+func (mr *MockSubscriberStoreMockRecorder) GetSubscriberStatus(ctx, imsi any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriberStatus", reflect.TypeOf((*MockSubscriberStore)(nil).GetSubscriberStatus), ctx, imsi)
+}
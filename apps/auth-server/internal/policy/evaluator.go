@@ -1,36 +1,64 @@
 package policy
 
-import "strings"
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
 
 // evaluator はEvaluatorインターフェースの実装。
-type evaluator struct{}
+type evaluator struct {
+	loc *time.Location // 曜日・時間帯条件の判定に使用するタイムゾーン
+}
 
 // NewEvaluator は新しいEvaluatorを生成する。
-func NewEvaluator() Evaluator {
-	return &evaluator{}
+// locがnilの場合はローカルタイムゾーンで曜日・時間帯条件を判定する。
+func NewEvaluator(loc *time.Location) Evaluator {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &evaluator{loc: loc}
 }
 
-// Evaluate はポリシーをNAS-IDとSSIDで評価し、結果を返す。
-// ルール配列を順次評価し、最初に一致したルールを適用する。
+// Evaluate はポリシーをリクエスト属性で評価し、結果を返す。
+// ルールをPriorityの小さい順（同じ値の場合は配列の順）に評価し、最初に一致したルールの動作を適用する。
 // 一致するルールがない場合はdefault値に基づいて判定する。
-func (e *evaluator) Evaluate(p *Policy, nasID string, ssid string) *EvaluationResult {
-	for i := range p.Rules {
-		rule := &p.Rules[i]
+func (e *evaluator) Evaluate(p *Policy, attrs *Attributes) *EvaluationResult {
+	now := attrs.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.In(e.loc)
 
-		// NAS-ID完全一致（大文字小文字区別）
-		if rule.NasID != nasID {
+	for _, rule := range orderedRules(p.Rules) {
+
+		// NAS-IDワイルドカード一致（大文字小文字区別）
+		if !matchGlob(rule.NasID, attrs.NASIdentifier) {
 			continue
 		}
 
 		// AllowedSSIDsチェック
-		if !matchSSID(rule.AllowedSSIDs, ssid) {
+		if !matchSSID(rule.AllowedSSIDs, attrs.SSID) {
 			continue
 		}
 
-		return &EvaluationResult{
-			Allowed:     true,
-			MatchedRule: rule,
+		if !matchNASIP(rule.NasIPs, attrs.NASIPAddress) {
+			continue
+		}
+		if !matchCallingStation(rule.CallingStationAllow, rule.CallingStationDeny, attrs.CallingStation) {
+			continue
+		}
+		if !matchNASPortType(rule.NasPortTypes, attrs.NASPortType) {
+			continue
+		}
+		if !matchTimeWindow(rule.Days, rule.TimeStart, rule.TimeEnd, now) {
+			continue
 		}
+
+		return applyRule(rule)
 	}
 
 	// 一致するルールなし → default判定
@@ -46,6 +74,72 @@ func (e *evaluator) Evaluate(p *Policy, nasID string, ssid string) *EvaluationRe
 	}
 }
 
+// orderedRules はルールを評価順（Priorityの小さい順、同じ値の場合は配列の順）に並べる。
+func orderedRules(rules []PolicyRule) []*PolicyRule {
+	ordered := make([]*PolicyRule, len(rules))
+	for i := range rules {
+		ordered[i] = &rules[i]
+	}
+	slices.SortStableFunc(ordered, func(a, b *PolicyRule) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	return ordered
+}
+
+// applyRule は一致したルールの動作から評価結果を生成する。
+// 未知の動作は拒否として扱う。
+func applyRule(rule *PolicyRule) *EvaluationResult {
+	switch rule.Action {
+	case "", RuleActionAllow:
+		return &EvaluationResult{
+			Allowed:     true,
+			MatchedRule: rule,
+		}
+	case RuleActionReject:
+		return &EvaluationResult{
+			Allowed:      false,
+			MatchedRule:  rule,
+			DenyReason:   fmt.Sprintf("matched reject rule (priority %d, reason %s)", rule.Priority, rule.RejectReason),
+			RejectReason: rule.RejectReason,
+		}
+	default:
+		return &EvaluationResult{
+			Allowed:     false,
+			MatchedRule: rule,
+			DenyReason:  fmt.Sprintf("matched %s rule (priority %d)", rule.Action, rule.Priority),
+		}
+	}
+}
+
+// matchGlob はvalueがワイルドカードパターンに一致するかを判定する。
+// *は任意の文字列（空文字を含む）、?は任意の1文字に一致する。
+func matchGlob(pattern, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		// *は値に*が含まれる場合もワイルドカードとして扱うため、1文字の一致より先に判定する
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case star >= 0:
+			// 直前の*に1文字多く一致させて再試行する
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
 // matchSSID はSSIDがAllowedSSIDsリストに一致するかを判定する。
 // ["*"]はワイルドカードとして全SSIDに一致する。
 // SSID比較は大文字小文字を区別しない。
@@ -71,3 +165,152 @@ func ExtractSSID(calledStationID string) string {
 	}
 	return calledStationID[idx+1:]
 }
+
+// matchNASIP はNAS IPアドレスがIPアドレスまたはCIDRのリストに一致するかを判定する。
+// リストが空の場合は常に一致する。
+func matchNASIP(nasIPs []string, addr string) bool {
+	if len(nasIPs) == 0 {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range nasIPs {
+		if strings.Contains(entry, "/") {
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCallingStation は端末MACアドレスが許可リストに含まれ、除外リストに含まれないかを判定する。
+// 許可リストが空の場合は除外リストのみ判定する。
+func matchCallingStation(allow, deny []string, callingStation string) bool {
+	mac := normalizeMAC(callingStation)
+	for _, entry := range deny {
+		if mac != "" && normalizeMAC(entry) == mac {
+			return false
+		}
+	}
+	if len(allow) == 0 {
+		return true
+	}
+	for _, entry := range allow {
+		if mac != "" && normalizeMAC(entry) == mac {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeMAC はMACアドレスを区切り文字なしの小文字16進表記に正規化する。
+// 形式: "AA-BB-CC-DD-EE-FF"、"aa:bb:cc:dd:ee:ff"、"aabb.ccdd.eeff" → "aabbccddeeff"
+// MACアドレスとして解釈できない場合は空文字を返す。
+func normalizeMAC(s string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case c == ':' || c == '-' || c == '.':
+			continue
+		case (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f'):
+			b.WriteRune(c)
+		default:
+			return ""
+		}
+	}
+	if b.Len() != 12 {
+		return ""
+	}
+	return b.String()
+}
+
+// matchNASPortType はNAS-Port-Typeがリストのいずれかに一致するかを判定する。
+// リストが空の場合は常に一致する。
+func matchNASPortType(portTypes []int, portType int) bool {
+	if len(portTypes) == 0 {
+		return true
+	}
+	for _, t := range portTypes {
+		if t == portType {
+			return true
+		}
+	}
+	return false
+}
+
+// weekdays は曜日条件の表記とtime.Weekdayの対応を表す。
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// matchTimeWindow は評価時刻が曜日・時間帯条件に一致するかを判定する。
+// 時間帯はTimeStart以上TimeEnd未満で、TimeStart > TimeEndの場合は日付をまたぐ時間帯として扱い、
+// 翌日の部分は開始日の曜日で判定する。TimeStartとTimeEndが同じ場合は終日とする。
+// 条件の形式が不正な場合は一致しない。
+func matchTimeWindow(days []string, timeStart, timeEnd string, now time.Time) bool {
+	day := now.Weekday()
+
+	if timeStart != "" || timeEnd != "" {
+		start, end := 0, 24*60
+		var ok bool
+		if timeStart != "" {
+			if start, ok = parseClock(timeStart); !ok {
+				return false
+			}
+		}
+		if timeEnd != "" {
+			if end, ok = parseClock(timeEnd); !ok {
+				return false
+			}
+		}
+
+		minute := now.Hour()*60 + now.Minute()
+		switch {
+		case start < end:
+			if minute < start || minute >= end {
+				return false
+			}
+		case start > end:
+			if minute < end {
+				day = (day + 6) % 7 // 日付をまたいだ部分は前日の時間帯
+			} else if minute < start {
+				return false
+			}
+		}
+	}
+
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if wd, ok := weekdays[strings.ToLower(d)]; ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock は"HH:MM"形式の時刻を0時からの経過分に変換する。
+func parseClock(s string) (int, bool) {
+	if len(s) != len("15:04") {
+		return 0, false
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
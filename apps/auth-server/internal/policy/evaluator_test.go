@@ -1,6 +1,9 @@
 package policy
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestEvaluateMatchFirstRule(t *testing.T) {
 	p := &Policy{
@@ -10,8 +13,8 @@ func TestEvaluateMatchFirstRule(t *testing.T) {
 		},
 		Default: "deny",
 	}
-	e := NewEvaluator()
-	result := e.Evaluate(p, "nas-01", "SSID-A")
+	e := NewEvaluator(nil)
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A"})
 	if !result.Allowed {
 		t.Fatal("expected Allowed=true")
 	}
@@ -31,8 +34,8 @@ func TestEvaluateMatchSecondRule(t *testing.T) {
 		},
 		Default: "deny",
 	}
-	e := NewEvaluator()
-	result := e.Evaluate(p, "nas-02", "SSID-B")
+	e := NewEvaluator(nil)
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-02", SSID: "SSID-B"})
 	if !result.Allowed {
 		t.Fatal("expected Allowed=true")
 	}
@@ -51,8 +54,8 @@ func TestEvaluateNoMatchDefaultAllow(t *testing.T) {
 		},
 		Default: "allow",
 	}
-	e := NewEvaluator()
-	result := e.Evaluate(p, "nas-99", "SSID-X")
+	e := NewEvaluator(nil)
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-99", SSID: "SSID-X"})
 	if !result.Allowed {
 		t.Fatal("expected Allowed=true with default=allow")
 	}
@@ -68,8 +71,8 @@ func TestEvaluateNoMatchDefaultDeny(t *testing.T) {
 		},
 		Default: "deny",
 	}
-	e := NewEvaluator()
-	result := e.Evaluate(p, "nas-99", "SSID-X")
+	e := NewEvaluator(nil)
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-99", SSID: "SSID-X"})
 	if result.Allowed {
 		t.Fatal("expected Allowed=false with default=deny")
 	}
@@ -85,8 +88,8 @@ func TestEvaluateWildcardSSID(t *testing.T) {
 		},
 		Default: "deny",
 	}
-	e := NewEvaluator()
-	result := e.Evaluate(p, "nas-01", "ANY-SSID")
+	e := NewEvaluator(nil)
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "ANY-SSID"})
 	if !result.Allowed {
 		t.Fatal("expected Allowed=true with wildcard SSID")
 	}
@@ -102,8 +105,8 @@ func TestEvaluateSSIDCaseInsensitive(t *testing.T) {
 		},
 		Default: "deny",
 	}
-	e := NewEvaluator()
-	result := e.Evaluate(p, "nas-01", "my-ssid")
+	e := NewEvaluator(nil)
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "my-ssid"})
 	if !result.Allowed {
 		t.Fatal("expected Allowed=true (SSID comparison should be case-insensitive)")
 	}
@@ -116,9 +119,9 @@ func TestEvaluateNasIDCaseSensitive(t *testing.T) {
 		},
 		Default: "deny",
 	}
-	e := NewEvaluator()
+	e := NewEvaluator(nil)
 	// 小文字のnas-01は大文字のNAS-01と一致しない
-	result := e.Evaluate(p, "nas-01", "SSID-A")
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A"})
 	if result.Allowed {
 		t.Fatal("expected Allowed=false (NAS-ID comparison should be case-sensitive)")
 	}
@@ -129,15 +132,15 @@ func TestEvaluateEmptyRules(t *testing.T) {
 		Rules:   []PolicyRule{},
 		Default: "deny",
 	}
-	e := NewEvaluator()
-	result := e.Evaluate(p, "nas-01", "SSID-A")
+	e := NewEvaluator(nil)
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A"})
 	if result.Allowed {
 		t.Fatal("expected Allowed=false with empty rules and default=deny")
 	}
 
 	// default=allowの場合
 	p.Default = "allow"
-	result = e.Evaluate(p, "nas-01", "SSID-A")
+	result = e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A"})
 	if !result.Allowed {
 		t.Fatal("expected Allowed=true with empty rules and default=allow")
 	}
@@ -163,3 +166,275 @@ func TestExtractSSIDEmpty(t *testing.T) {
 		t.Errorf("ExtractSSID = %q, want empty string", ssid)
 	}
 }
+
+func TestEvaluateNasIDWildcard(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{NasID: "ap-*-floor?", AllowedSSIDs: []string{"*"}},
+		},
+		Default: "deny",
+	}
+	e := NewEvaluator(nil)
+
+	tests := []struct {
+		nasID string
+		want  bool
+	}{
+		{"ap-tokyo-floor1", true},
+		{"ap--floor2", true},
+		{"ap-tokyo-floor10", false},
+		{"AP-tokyo-floor1", false},
+		{"sw-tokyo-floor1", false},
+	}
+	for _, tt := range tests {
+		result := e.Evaluate(p, &Attributes{NASIdentifier: tt.nasID, SSID: "SSID-A"})
+		if result.Allowed != tt.want {
+			t.Errorf("nasID=%q: Allowed = %v, want %v", tt.nasID, result.Allowed, tt.want)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"*", "", true},
+		{"*", "nas-01", true},
+		{"*", "*x", true},
+		{"a*", "a*b", true},
+		{"a*b", "a*b", true},
+		{"a*b", "a**b", true},
+		{"*b", "*a", false},
+		{"a?c", "a*c", true},
+		{"nas-01", "nas-01", true},
+		{"nas-01", "nas-02", false},
+		{"a*", "b*", false},
+		{"", "", true},
+		{"", "a", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateNasIP(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{NasID: "*", AllowedSSIDs: []string{"*"}, NasIPs: []string{"10.0.0.0/24", "192.168.1.10", "2001:db8::/32"}},
+		},
+		Default: "deny",
+	}
+	e := NewEvaluator(nil)
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.0.0.200", true},
+		{"10.0.1.1", false},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"2001:db8::1", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", NASIPAddress: tt.ip, SSID: "SSID-A"})
+		if result.Allowed != tt.want {
+			t.Errorf("ip=%q: Allowed = %v, want %v", tt.ip, result.Allowed, tt.want)
+		}
+	}
+}
+
+func TestEvaluateCallingStation(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{
+				NasID:               "*",
+				AllowedSSIDs:        []string{"*"},
+				CallingStationAllow: []string{"AA-BB-CC-DD-EE-01", "aa:bb:cc:dd:ee:02"},
+				CallingStationDeny:  []string{"aabb.ccdd.ee02"},
+			},
+		},
+		Default: "deny",
+	}
+	e := NewEvaluator(nil)
+
+	tests := []struct {
+		mac  string
+		want bool
+	}{
+		{"aa:bb:cc:dd:ee:01", true},
+		{"AA-BB-CC-DD-EE-02", false}, // 除外リストが優先
+		{"AA-BB-CC-DD-EE-03", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A", CallingStation: tt.mac})
+		if result.Allowed != tt.want {
+			t.Errorf("mac=%q: Allowed = %v, want %v", tt.mac, result.Allowed, tt.want)
+		}
+	}
+
+	// 除外リストのみの場合は除外対象以外に一致する
+	p.Rules[0].CallingStationAllow = nil
+	if !e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A", CallingStation: "AA-BB-CC-DD-EE-03"}).Allowed {
+		t.Error("expected Allowed=true for MAC not in deny list")
+	}
+	if !e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A"}).Allowed {
+		t.Error("expected Allowed=true without Calling-Station-Id")
+	}
+}
+
+func TestEvaluateNasPortType(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{NasID: "*", AllowedSSIDs: []string{"*"}, NasPortTypes: []int{19}, VlanID: "100"},
+			{NasID: "*", AllowedSSIDs: []string{"*"}, VlanID: "200"},
+		},
+		Default: "deny",
+	}
+	e := NewEvaluator(nil)
+
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A", NASPortType: 19})
+	if result.MatchedRule == nil || result.MatchedRule.VlanID != "100" {
+		t.Errorf("NAS-Port-Type=19: MatchedRule = %+v, want VlanID 100", result.MatchedRule)
+	}
+	result = e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A", NASPortType: -1})
+	if result.MatchedRule == nil || result.MatchedRule.VlanID != "200" {
+		t.Errorf("NAS-Port-Type absent: MatchedRule = %+v, want VlanID 200", result.MatchedRule)
+	}
+}
+
+func TestEvaluateTimeWindow(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	e := NewEvaluator(jst)
+
+	// 2026-10-16は金曜日
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, jst)
+	}
+
+	tests := []struct {
+		name string
+		rule PolicyRule
+		now  time.Time
+		want bool
+	}{
+		{"weekday in window", PolicyRule{Days: []string{"mon", "tue", "wed", "thu", "fri"}, TimeStart: "09:00", TimeEnd: "18:00"}, at(16, 9, 0), true},
+		{"weekday end exclusive", PolicyRule{Days: []string{"fri"}, TimeStart: "09:00", TimeEnd: "18:00"}, at(16, 18, 0), false},
+		{"weekend", PolicyRule{Days: []string{"Mon", "FRI"}}, at(17, 12, 0), false},
+		{"overnight before midnight", PolicyRule{Days: []string{"fri"}, TimeStart: "22:00", TimeEnd: "06:00"}, at(16, 23, 30), true},
+		{"overnight after midnight uses start day", PolicyRule{Days: []string{"fri"}, TimeStart: "22:00", TimeEnd: "06:00"}, at(17, 5, 59), true},
+		{"overnight after midnight other day", PolicyRule{Days: []string{"sat"}, TimeStart: "22:00", TimeEnd: "06:00"}, at(17, 5, 59), false},
+		{"overnight outside", PolicyRule{TimeStart: "22:00", TimeEnd: "06:00"}, at(16, 12, 0), false},
+		{"start only", PolicyRule{TimeStart: "20:00"}, at(16, 23, 59), true},
+		{"invalid time", PolicyRule{TimeStart: "9:00", TimeEnd: "18:00"}, at(16, 12, 0), false},
+		// 評価時刻は評価器のタイムゾーンに変換して判定する（UTC 00:30 = JST 09:30）
+		{"timezone conversion", PolicyRule{TimeStart: "09:00", TimeEnd: "10:00"}, time.Date(2026, 10, 16, 0, 30, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			rule.NasID = "*"
+			rule.AllowedSSIDs = []string{"*"}
+			p := &Policy{Rules: []PolicyRule{rule}, Default: "deny"}
+			result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "SSID-A", Time: tt.now})
+			if result.Allowed != tt.want {
+				t.Errorf("Allowed = %v, want %v", result.Allowed, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMAC(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"AA-BB-CC-DD-EE-FF", "aabbccddeeff"},
+		{"aa:bb:cc:dd:ee:ff", "aabbccddeeff"},
+		{"aabb.ccdd.eeff", "aabbccddeeff"},
+		{"AABBCCDDEEFF", "aabbccddeeff"},
+		{"AA-BB-CC-DD-EE", ""},
+		{"GG-BB-CC-DD-EE-FF", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeMAC(tt.in); got != tt.want {
+			t.Errorf("normalizeMAC(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateRuleAction(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{NasID: "nas-01", AllowedSSIDs: []string{"GUEST"}, Action: RuleActionDeny},
+			{NasID: "nas-02", AllowedSSIDs: []string{"GUEST"}, Action: RuleActionReject, RejectReason: RejectReasonNotSubscribed},
+			{NasID: "nas-03", AllowedSSIDs: []string{"GUEST"}, Action: "block"},
+			{NasID: "*", AllowedSSIDs: []string{"*"}, Action: RuleActionAllow, VlanID: "100"},
+		},
+		Default: "deny",
+	}
+	e := NewEvaluator(nil)
+
+	tests := []struct {
+		nasID            string
+		wantAllowed      bool
+		wantRejectReason string
+	}{
+		{"nas-01", false, ""},
+		{"nas-02", false, RejectReasonNotSubscribed},
+		{"nas-03", false, ""}, // 未知の動作は拒否
+		{"nas-04", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nasID, func(t *testing.T) {
+			result := e.Evaluate(p, &Attributes{NASIdentifier: tt.nasID, SSID: "GUEST"})
+			if result.Allowed != tt.wantAllowed {
+				t.Errorf("Allowed = %v, want %v", result.Allowed, tt.wantAllowed)
+			}
+			if result.MatchedRule == nil {
+				t.Fatal("expected MatchedRule to be non-nil")
+			}
+			if result.RejectReason != tt.wantRejectReason {
+				t.Errorf("RejectReason = %q, want %q", result.RejectReason, tt.wantRejectReason)
+			}
+			if !tt.wantAllowed && result.DenyReason == "" {
+				t.Error("expected DenyReason to be set")
+			}
+		})
+	}
+}
+
+func TestEvaluatePriority(t *testing.T) {
+	p := &Policy{
+		Rules: []PolicyRule{
+			{NasID: "*", AllowedSSIDs: []string{"*"}, VlanID: "100", Priority: 10},
+			{NasID: "nas-01", AllowedSSIDs: []string{"GUEST"}, Action: RuleActionDeny, Priority: 5},
+			{NasID: "*", AllowedSSIDs: []string{"*"}, VlanID: "200", Priority: 10},
+		},
+		Default: "deny",
+	}
+	e := NewEvaluator(nil)
+
+	// Priorityの小さいdenyルールが配列の先頭のallowルールより先に評価される
+	if result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "GUEST"}); result.Allowed {
+		t.Error("expected deny rule with lower priority to be evaluated first")
+	}
+
+	// 同じPriorityの場合は配列の順
+	result := e.Evaluate(p, &Attributes{NASIdentifier: "nas-01", SSID: "CORP"})
+	if !result.Allowed || result.MatchedRule.VlanID != "100" {
+		t.Errorf("expected first allow rule, got %+v", result.MatchedRule)
+	}
+
+	// 評価によってルールの順序を変更しない
+	if p.Rules[1].NasID != "nas-01" {
+		t.Error("Evaluate must not reorder policy rules")
+	}
+}
@@ -5,11 +5,12 @@ import "context"
 // PolicyStore はポリシーデータへのアクセスを定義する。
 type PolicyStore interface {
 	// GetPolicy は指定されたIMSIに対応するポリシーを取得する。
-	GetPolicy(ctx context.Context, imsi string) (*Policy, error)
+	// 加入者ポリシーがない場合はclientIP（NASクライアントのIPアドレス）を含めて既定ポリシーを解決する。
+	GetPolicy(ctx context.Context, imsi, clientIP string) (*Policy, error)
 }
 
 // Evaluator はポリシー評価エンジンを定義する。
 type Evaluator interface {
-	// Evaluate はポリシーをNAS-IDとSSIDで評価し、結果を返す。
-	Evaluate(policy *Policy, nasID string, ssid string) *EvaluationResult
+	// Evaluate はポリシーをリクエスト属性で評価し、結果を返す。
+	Evaluate(policy *Policy, attrs *Attributes) *EvaluationResult
 }
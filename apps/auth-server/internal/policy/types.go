@@ -1,22 +1,98 @@
 package policy
 
+import "time"
+
 // Policy は認可ポリシーを表す（D-09 セクション8.3/8.4.2準拠）。
 type Policy struct {
 	Rules   []PolicyRule
 	Default string // "allow" or "deny"
+	// RequireAKAPrime がtrueの場合、この加入者のEAP-AKA（AKA'以外）を拒否する
+	RequireAKAPrime bool
+	// MaxSessions はこの加入者の同時セッション数の上限（0の場合はMAX_SESSIONS_PER_USERを適用）
+	MaxSessions int
+	// Profile は適用したポリシープロファイル名（プロファイルを参照しない場合は空）
+	Profile string
+	// Source はポリシーを解決した段階（PolicySourceIMSI等）
+	Source string
 }
 
+// ポリシーの解決段階（加入者ポリシーがない場合は上から順にフォールバックする）
+const (
+	PolicySourceIMSI   = "imsi"   // 加入者ポリシー（policy:{IMSI}）
+	PolicySourceRange  = "range"  // IMSI範囲で参照するプロファイル
+	PolicySourcePrefix = "prefix" // PLMN・IMSIプレフィックスのポリシー（policy-prefix:{prefix}）
+	PolicySourceClient = "client" // NASクライアントの既定プロファイル（client:{IP}のpolicy_profile）
+	PolicySourceGlobal = "global" // 全体の既定ポリシー（policy-default）
+)
+
 // PolicyRule は個別の認可ルールを表す（D-09 セクション8.3.3準拠）。
+// 省略可能な条件は未設定（空）の場合は判定しない。
 type PolicyRule struct {
-	NasID          string   `json:"nas_id"`
+	NasID          string   `json:"nas_id"` // ワイルドカード（*、?）可
 	AllowedSSIDs   []string `json:"allowed_ssids"`
 	VlanID         string   `json:"vlan_id,omitempty"`
 	SessionTimeout int      `json:"session_timeout,omitempty"`
+
+	// NasIPs はNAS IPアドレスまたはCIDRのリスト
+	NasIPs []string `json:"nas_ips,omitempty"`
+	// CallingStationAllow は許可する端末MACアドレスのリスト（Calling-Station-Id）
+	CallingStationAllow []string `json:"calling_station_allow,omitempty"`
+	// CallingStationDeny は除外する端末MACアドレスのリスト（Calling-Station-Id）
+	CallingStationDeny []string `json:"calling_station_deny,omitempty"`
+	// NasPortTypes はNAS-Port-Type値のリスト（例: 19=Wireless-802.11）
+	NasPortTypes []int `json:"nas_port_types,omitempty"`
+	// Days は適用する曜日のリスト（"mon"〜"sun"）
+	Days []string `json:"days,omitempty"`
+	// TimeStart/TimeEnd は適用する時間帯（"HH:MM"、TimeStart > TimeEndの場合は日付をまたぐ）
+	TimeStart string `json:"time_start,omitempty"`
+	TimeEnd   string `json:"time_end,omitempty"`
+
+	// ReplyAttributes はルール一致時にAccess-Acceptへ付与するRADIUS属性のリスト
+	ReplyAttributes []ReplyAttribute `json:"reply_attributes,omitempty"`
+
+	// Action はルール一致時の動作（"allow"・"deny"・"reject"、空は"allow"）
+	Action string `json:"action,omitempty"`
+	// RejectReason はAction="reject"の場合の拒否理由（失敗通知の通知コードに対応）
+	RejectReason string `json:"reject_reason,omitempty"`
+	// Priority は評価順（小さいほど先に評価、同じ値の場合は配列の順）
+	Priority int `json:"priority,omitempty"`
+}
+
+// ルール一致時の動作
+const (
+	RuleActionAllow  = "allow"  // 許可
+	RuleActionDeny   = "deny"   // 拒否（General failure after authentication）
+	RuleActionReject = "reject" // 拒否理由を指定して拒否
+)
+
+// 拒否理由（Action="reject"で指定）
+const (
+	RejectReasonGeneralFailure    = "general_failure"    // General failure after authentication
+	RejectReasonTemporarilyDenied = "temporarily_denied" // User has been temporarily denied access
+	RejectReasonNotSubscribed     = "not_subscribed"     // User has not subscribed to the requested service
+)
+
+// ReplyAttribute はAccess-Acceptへ付与するRADIUS属性を表す（D-09 セクション8.6.5準拠）。
+type ReplyAttribute struct {
+	Name   string `json:"name"`             // 属性名（例: "Filter-Id"、"WISPr-Bandwidth-Max-Up"）
+	Vendor string `json:"vendor,omitempty"` // ベンダー名（例: "WISPr"。標準属性は空）
+	Value  string `json:"value"`            // 属性値（整数型は10進数、列挙型は値の名前も可）
+}
+
+// Attributes はポリシー評価に使用するリクエスト属性を表す。
+type Attributes struct {
+	NASIdentifier  string    // NAS-Identifier属性
+	NASIPAddress   string    // NAS-IP-Address属性（属性なしの場合は送信元IPアドレス）
+	SSID           string    // Called-Station-Idから抽出したSSID
+	CallingStation string    // Calling-Station-Id属性
+	NASPortType    int       // NAS-Port-Type属性（属性なしの場合は-1）
+	Time           time.Time // 評価時刻（ゼロ値の場合は現在時刻）
 }
 
 // EvaluationResult はポリシー評価結果を表す（D-09 セクション8.5.4準拠）。
 type EvaluationResult struct {
-	Allowed     bool
-	MatchedRule *PolicyRule // nilの場合はdefault適用
-	DenyReason  string      // Deny時の理由
+	Allowed      bool
+	MatchedRule  *PolicyRule // nilの場合はdefault適用
+	DenyReason   string      // Deny時の理由
+	RejectReason string      // 一致したルールの拒否理由（Action="reject"の場合のみ）
 }
@@ -80,6 +80,16 @@ func GetNASIPAddress(p *radius.Packet) (net.IP, bool) {
 	return ip, true
 }
 
+// GetNASPortType はNAS-Port-Type属性を取得する。
+// 属性が存在しない場合は(0, false)を返す。
+func GetNASPortType(p *radius.Packet) (int, bool) {
+	val, err := rfc2865.NASPortType_Lookup(p)
+	if err != nil {
+		return 0, false
+	}
+	return int(val), true
+}
+
 // GetCalledStationID はCalled-Station-Id属性を取得する。
 // 属性が存在しない場合は("", false)を返す。
 func GetCalledStationID(p *radius.Packet) (string, bool) {
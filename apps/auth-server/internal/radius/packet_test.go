@@ -189,6 +189,26 @@ func TestGetNASIPAddress(t *testing.T) {
 	}
 }
 
+func TestGetNASPortType(t *testing.T) {
+	p := radius.New(radius.CodeAccessRequest, []byte("secret"))
+
+	// 属性なし
+	_, ok := GetNASPortType(p)
+	if ok {
+		t.Error("GetNASPortType returned true for empty packet")
+	}
+
+	// 属性あり（Async=0も属性ありとして扱う）
+	_ = rfc2865.NASPortType_Add(p, rfc2865.NASPortType_Value_Async)
+	got, ok := GetNASPortType(p)
+	if !ok {
+		t.Fatal("GetNASPortType returned false, want true")
+	}
+	if got != 0 {
+		t.Errorf("GetNASPortType = %d, want 0", got)
+	}
+}
+
 func TestGetCalledStationID(t *testing.T) {
 	p := radius.New(radius.CodeAccessRequest, []byte("secret"))
 
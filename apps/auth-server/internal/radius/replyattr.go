@@ -0,0 +1,95 @@
+package radius
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/replyattr"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+	"layeh.com/radius/rfc2869"
+	"layeh.com/radius/vendors/aruba"
+	"layeh.com/radius/vendors/mikrotik"
+	"layeh.com/radius/vendors/wispr"
+)
+
+// replyAttributeAdder は応答属性をパケットへ追加する関数
+// 文字列型はaddString、整数型（列挙型を含む）はaddIntegerで追加する
+type replyAttributeAdder struct {
+	addString  func(p *radius.Packet, value string) error
+	addInteger func(p *radius.Packet, value uint32) error
+}
+
+// replyAttributeAdders は応答属性の追加関数（キーは小文字の属性名、属性の定義はreplyattr.Defsに対応）
+var replyAttributeAdders = map[string]replyAttributeAdder{
+	"filter-id":     {addString: rfc2865.FilterID_AddString},
+	"reply-message": {addString: rfc2865.ReplyMessage_AddString},
+	"class":         {addString: rfc2865.Class_AddString},
+	"idle-timeout": {addInteger: func(p *radius.Packet, v uint32) error {
+		return rfc2865.IdleTimeout_Add(p, rfc2865.IdleTimeout(v))
+	}},
+	"termination-action": {addInteger: func(p *radius.Packet, v uint32) error {
+		return rfc2865.TerminationAction_Add(p, rfc2865.TerminationAction(v))
+	}},
+	"acct-interim-interval": {addInteger: func(p *radius.Packet, v uint32) error {
+		return rfc2869.AcctInterimInterval_Add(p, rfc2869.AcctInterimInterval(v))
+	}},
+	"wispr-bandwidth-min-up": {addInteger: func(p *radius.Packet, v uint32) error {
+		return wispr.WISPrBandwidthMinUp_Add(p, wispr.WISPrBandwidthMinUp(v))
+	}},
+	"wispr-bandwidth-min-down": {addInteger: func(p *radius.Packet, v uint32) error {
+		return wispr.WISPrBandwidthMinDown_Add(p, wispr.WISPrBandwidthMinDown(v))
+	}},
+	"wispr-bandwidth-max-up": {addInteger: func(p *radius.Packet, v uint32) error {
+		return wispr.WISPrBandwidthMaxUp_Add(p, wispr.WISPrBandwidthMaxUp(v))
+	}},
+	"wispr-bandwidth-max-down": {addInteger: func(p *radius.Packet, v uint32) error {
+		return wispr.WISPrBandwidthMaxDown_Add(p, wispr.WISPrBandwidthMaxDown(v))
+	}},
+	"wispr-redirection-url":        {addString: wispr.WISPrRedirectionURL_AddString},
+	"wispr-session-terminate-time": {addString: wispr.WISPrSessionTerminateTime_AddString},
+	"aruba-user-role":              {addString: aruba.ArubaUserRole_AddString},
+	"mikrotik-rate-limit":          {addString: mikrotik.MikrotikRateLimit_AddString},
+}
+
+// AddReplyAttributes はポリシールールの応答属性をパケットに追加する。
+// 未対応の属性・ベンダー不一致・不正な値の属性は追加せず、その属性名とエラーをまとめて返す（他の属性は追加する）。
+func AddReplyAttributes(p *radius.Packet, attrs []policy.ReplyAttribute) (invalid []string, err error) {
+	var errs []error
+	for _, attr := range attrs {
+		if err := addReplyAttribute(p, attr); err != nil {
+			invalid = append(invalid, attr.Name)
+			errs = append(errs, fmt.Errorf("%s: %w", attr.Name, err))
+		}
+	}
+	return invalid, errors.Join(errs...)
+}
+
+// addReplyAttribute は応答属性を1つ追加する
+func addReplyAttribute(p *radius.Packet, attr policy.ReplyAttribute) error {
+	def, ok := replyattr.Lookup(attr.Name)
+	if !ok {
+		return errors.New("unsupported attribute")
+	}
+	adder, ok := replyAttributeAdders[strings.ToLower(def.Name)]
+	if !ok {
+		return errors.New("unsupported attribute")
+	}
+	if !strings.EqualFold(attr.Vendor, def.Vendor) {
+		return fmt.Errorf("vendor must be %q", def.Vendor)
+	}
+	if attr.Value == "" {
+		return errors.New("value is required")
+	}
+
+	if !def.Integer {
+		return adder.addString(p, attr.Value)
+	}
+	v, ok := def.IntegerValue(attr.Value)
+	if !ok {
+		return errors.New("value must be an unsigned 32-bit integer")
+	}
+	return adder.addInteger(p, v)
+}
@@ -0,0 +1,97 @@
+package radius
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/replyattr"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+	"layeh.com/radius/rfc2869"
+	"layeh.com/radius/vendors/wispr"
+)
+
+func TestAddReplyAttributes(t *testing.T) {
+	p := radius.New(radius.CodeAccessAccept, []byte("secret"))
+
+	invalid, err := AddReplyAttributes(p, []policy.ReplyAttribute{
+		{Name: "Filter-Id", Value: "staff"},
+		{Name: "idle-timeout", Value: "600"},
+		{Name: "Termination-Action", Value: "RADIUS-Request"},
+		{Name: "Acct-Interim-Interval", Value: "300"},
+		{Name: "WISPr-Bandwidth-Max-Up", Vendor: "WISPr", Value: "1000000"},
+		{Name: "WISPr-Bandwidth-Max-Down", Vendor: "wispr", Value: "5000000"},
+	})
+	if err != nil || len(invalid) != 0 {
+		t.Fatalf("unexpected error: %v (invalid=%v)", err, invalid)
+	}
+
+	if got := rfc2865.FilterID_GetString(p); got != "staff" {
+		t.Errorf("Filter-Id = %q, want staff", got)
+	}
+	if got := rfc2865.IdleTimeout_Get(p); got != 600 {
+		t.Errorf("Idle-Timeout = %d, want 600", got)
+	}
+	if got := rfc2865.TerminationAction_Get(p); got != rfc2865.TerminationAction_Value_RADIUSRequest {
+		t.Errorf("Termination-Action = %d, want RADIUS-Request", got)
+	}
+	if got := rfc2869.AcctInterimInterval_Get(p); got != 300 {
+		t.Errorf("Acct-Interim-Interval = %d, want 300", got)
+	}
+	if got := wispr.WISPrBandwidthMaxUp_Get(p); got != 1000000 {
+		t.Errorf("WISPr-Bandwidth-Max-Up = %d, want 1000000", got)
+	}
+	if got := wispr.WISPrBandwidthMaxDown_Get(p); got != 5000000 {
+		t.Errorf("WISPr-Bandwidth-Max-Down = %d, want 5000000", got)
+	}
+}
+
+func TestAddReplyAttributes_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		attr policy.ReplyAttribute
+		want string
+	}{
+		{"unsupported", policy.ReplyAttribute{Name: "Framed-Pool", Value: "pool1"}, "unsupported"},
+		{"vendor missing", policy.ReplyAttribute{Name: "WISPr-Bandwidth-Max-Up", Value: "1000"}, "vendor"},
+		{"vendor for standard attribute", policy.ReplyAttribute{Name: "Filter-Id", Vendor: "WISPr", Value: "staff"}, "vendor"},
+		{"empty value", policy.ReplyAttribute{Name: "Filter-Id"}, "required"},
+		{"not integer", policy.ReplyAttribute{Name: "Idle-Timeout", Value: "10m"}, "integer"},
+		{"negative", policy.ReplyAttribute{Name: "Idle-Timeout", Value: "-1"}, "integer"},
+		{"too long", policy.ReplyAttribute{Name: "Filter-Id", Value: strings.Repeat("a", 254)}, "too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := radius.New(radius.CodeAccessAccept, []byte("secret"))
+			invalid, err := AddReplyAttributes(p, []policy.ReplyAttribute{tt.attr, {Name: "Reply-Message", Value: "welcome"}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+			if len(invalid) != 1 || invalid[0] != tt.attr.Name {
+				t.Errorf("invalid = %v, want [%s]", invalid, tt.attr.Name)
+			}
+			// 不正な属性以外は追加される
+			if got := rfc2865.ReplyMessage_GetString(p); got != "welcome" {
+				t.Errorf("Reply-Message = %q, want welcome", got)
+			}
+		})
+	}
+}
+
+func TestReplyAttributeAdders_MatchDefs(t *testing.T) {
+	if len(replyAttributeAdders) != len(replyattr.Defs) {
+		t.Errorf("adders = %d, defs = %d", len(replyAttributeAdders), len(replyattr.Defs))
+	}
+	for _, def := range replyattr.Defs {
+		adder, ok := replyAttributeAdders[strings.ToLower(def.Name)]
+		if !ok {
+			t.Errorf("%s: no adder", def.Name)
+			continue
+		}
+		if def.Integer && adder.addInteger == nil || !def.Integer && adder.addString == nil {
+			t.Errorf("%s: adder does not match the attribute type", def.Name)
+		}
+	}
+}
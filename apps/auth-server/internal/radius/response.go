@@ -1,6 +1,9 @@
 package radius
 
 import (
+	"log/slog"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
 	"layeh.com/radius"
 	"layeh.com/radius/rfc2865"
 	"layeh.com/radius/rfc2868"
@@ -21,8 +24,12 @@ type AcceptParams struct {
 	VlanID string
 	// SessionTimeout はタイムアウト秒数（0以下なら設定しない）
 	SessionTimeout int
+	// ReplyAttributes はポリシールールで指定された追加の応答属性（不正な属性は設定しない）
+	ReplyAttributes []policy.ReplyAttribute
 	// ProxyStates はリクエストから抽出されたProxy-State属性
 	ProxyStates *ProxyStates
+	// TraceID は応答属性の付与失敗をログ出力する際のTrace ID
+	TraceID string
 }
 
 // ChallengeParams はAccess-Challenge生成に必要なパラメータ
@@ -80,6 +87,16 @@ func BuildAccessAccept(request *radius.Packet, secret []byte, params *AcceptPara
 		_ = rfc2865.SessionTimeout_Set(resp, rfc2865.SessionTimeout(params.SessionTimeout))
 	}
 
+	// ポリシールールの応答属性（Class属性はセッションUUIDの後に追加される）
+	if invalid, err := AddReplyAttributes(resp, params.ReplyAttributes); err != nil {
+		slog.Warn("応答属性の一部を設定できない",
+			"event_id", "RADIUS_REPLY_ATTR_INVALID",
+			"trace_id", params.TraceID,
+			"attributes", invalid,
+			"error", err,
+		)
+	}
+
 	// Proxy-State
 	params.ProxyStates.Apply(resp)
 
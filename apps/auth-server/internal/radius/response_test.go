@@ -3,6 +3,7 @@ package radius
 import (
 	"testing"
 
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
 	"layeh.com/radius"
 	"layeh.com/radius/rfc2865"
 	"layeh.com/radius/rfc2868"
@@ -132,6 +133,38 @@ func TestBuildAccessAccept_NoTimeout(t *testing.T) {
 	}
 }
 
+func TestBuildAccessAccept_ReplyAttributes(t *testing.T) {
+	secret := []byte("test-secret")
+	req := newTestRequest(secret)
+
+	resp := BuildAccessAccept(req, secret, &AcceptParams{
+		EAPMessage: []byte{0x03, 0x01, 0x00, 0x04},
+		MSK:        make([]byte, 64),
+		SessionID:  "session-1",
+		ReplyAttributes: []policy.ReplyAttribute{
+			{Name: "Class", Value: "tenant-a"},
+			{Name: "Filter-Id", Value: "staff"},
+			{Name: "Unknown-Attr", Value: "ignored"},
+		},
+		ProxyStates: &ProxyStates{},
+	})
+
+	// セッションUUIDのClass属性が先頭のまま、追加のClass属性が続く
+	classes, err := rfc2865.Class_GetStrings(resp)
+	if err != nil {
+		t.Fatalf("Class_GetStrings: %v", err)
+	}
+	if len(classes) != 2 || classes[0] != "session-1" || classes[1] != "tenant-a" {
+		t.Errorf("Class = %v, want [session-1 tenant-a]", classes)
+	}
+	if got := rfc2865.FilterID_GetString(resp); got != "staff" {
+		t.Errorf("Filter-Id = %q, want staff", got)
+	}
+	if !VerifyMessageAuthenticator(resp, secret) {
+		t.Error("Message-Authenticator verification failed")
+	}
+}
+
 func TestBuildAccessAccept_ProxyState(t *testing.T) {
 	secret := []byte("test-secret")
 	req := newTestRequest(secret)
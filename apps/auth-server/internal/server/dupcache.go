@@ -0,0 +1,99 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"layeh.com/radius"
+)
+
+// duplicateKey はAccess-Requestの重複判定キー（RFC 5080 Section 2.2.2）。
+// 送信元IPアドレス・ポート、RADIUS Identifier、Request Authenticatorの組で再送を識別する。
+type duplicateKey struct {
+	src           string
+	identifier    byte
+	authenticator [16]byte
+}
+
+// newDuplicateKey はリクエストから重複判定キーを生成する
+func newDuplicateKey(r *radius.Request) duplicateKey {
+	var src string
+	if r.RemoteAddr != nil {
+		src = r.RemoteAddr.String()
+	}
+	return duplicateKey{
+		src:           src,
+		identifier:    r.Identifier,
+		authenticator: r.Authenticator,
+	}
+}
+
+// duplicateEntry は重複キャッシュのエントリ。
+// respがnilの場合は処理中を表す。
+type duplicateEntry struct {
+	resp    *radius.Packet
+	expires time.Time
+}
+
+// DuplicateCache はAccess-Requestの再送に前回と同一の応答を返すための重複キャッシュ。
+// NASが応答を受信できずに再送した場合に、EAP処理を再実行せずに応答を再送する。
+type DuplicateCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[duplicateKey]*duplicateEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+// NewDuplicateCache は新しいDuplicateCacheを生成する。
+// ttlは応答を保持する期間（NASの再送間隔・回数を考慮して設定する）。
+func NewDuplicateCache(ttl time.Duration) *DuplicateCache {
+	return &DuplicateCache{
+		ttl:     ttl,
+		entries: make(map[duplicateKey]*duplicateEntry),
+		now:     time.Now,
+	}
+}
+
+// begin はリクエストの処理開始を登録する。
+// 重複の場合はtrueと前回の応答を返す（処理中の場合、応答はnil）。
+func (c *DuplicateCache) begin(key duplicateKey) (*radius.Packet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expires) {
+		return entry.resp, true
+	}
+	c.entries[key] = &duplicateEntry{expires: now.Add(c.ttl)}
+	return nil, false
+}
+
+// complete はリクエストの応答を記録する。
+// 応答なし（nil）の場合はエントリを削除し、再送時に改めて処理させる。
+func (c *DuplicateCache) complete(key duplicateKey, resp *radius.Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if resp == nil {
+		delete(c.entries, key)
+		return
+	}
+	c.entries[key] = &duplicateEntry{resp: resp, expires: c.now().Add(c.ttl)}
+}
+
+// sweep は期限切れのエントリを削除する（ttl間隔で実行）。
+// 呼び出し元でロックを取得していること。
+func (c *DuplicateCache) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+	for key, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.nextSweep = now.Add(c.ttl)
+}
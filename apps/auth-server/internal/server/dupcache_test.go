@@ -0,0 +1,108 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"layeh.com/radius"
+)
+
+// newTestDuplicateCache は時刻を操作可能なDuplicateCacheを生成する
+func newTestDuplicateCache(ttl time.Duration) (*DuplicateCache, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewDuplicateCache(ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestDuplicateCache_ReplayWithinTTL(t *testing.T) {
+	c, now := newTestDuplicateCache(5 * time.Second)
+	key := duplicateKey{src: "192.0.2.1:1645", identifier: 1}
+	resp := &radius.Packet{Code: radius.CodeAccessChallenge}
+
+	if _, dup := c.begin(key); dup {
+		t.Fatal("初回リクエストが重複と判定された")
+	}
+	// 処理中の再送 → 重複（応答なし）
+	if cached, dup := c.begin(key); !dup || cached != nil {
+		t.Errorf("処理中の再送: got (%v, %v), want (nil, true)", cached, dup)
+	}
+
+	c.complete(key, resp)
+	*now = now.Add(4 * time.Second)
+	if cached, dup := c.begin(key); !dup || cached != resp {
+		t.Errorf("処理完了後の再送: got (%v, %v), want (%v, true)", cached, dup, resp)
+	}
+
+	// 期限切れ → 新規リクエストとして処理
+	*now = now.Add(2 * time.Second)
+	if _, dup := c.begin(key); dup {
+		t.Error("期限切れ後のリクエストが重複と判定された")
+	}
+}
+
+func TestDuplicateCache_NoResponseNotCached(t *testing.T) {
+	c, _ := newTestDuplicateCache(5 * time.Second)
+	key := duplicateKey{src: "192.0.2.1:1645", identifier: 1}
+
+	c.begin(key)
+	c.complete(key, nil)
+
+	// 応答しなかったリクエストの再送は改めて処理する
+	if _, dup := c.begin(key); dup {
+		t.Error("応答なしのリクエストが重複と判定された")
+	}
+}
+
+func TestDuplicateCache_KeyFields(t *testing.T) {
+	c, _ := newTestDuplicateCache(5 * time.Second)
+	base := duplicateKey{src: "192.0.2.1:1645", identifier: 1}
+	c.begin(base)
+	c.complete(base, &radius.Packet{Code: radius.CodeAccessReject})
+
+	others := map[string]duplicateKey{
+		"送信元ポート":                {src: "192.0.2.1:1646", identifier: 1},
+		"Identifier":            {src: "192.0.2.1:1645", identifier: 2},
+		"Request Authenticator": {src: "192.0.2.1:1645", identifier: 1, authenticator: [16]byte{1}},
+	}
+	for name, key := range others {
+		if _, dup := c.begin(key); dup {
+			t.Errorf("%sが異なるリクエストが重複と判定された", name)
+		}
+	}
+}
+
+func TestDuplicateCache_Sweep(t *testing.T) {
+	c, now := newTestDuplicateCache(5 * time.Second)
+	old := duplicateKey{src: "192.0.2.1:1645", identifier: 1}
+	c.begin(old)
+	c.complete(old, &radius.Packet{Code: radius.CodeAccessReject})
+
+	*now = now.Add(6 * time.Second)
+	c.begin(duplicateKey{src: "192.0.2.1:1645", identifier: 2})
+
+	if _, ok := c.entries[old]; ok {
+		t.Error("期限切れエントリが削除されていない")
+	}
+}
+
+func TestNewDuplicateKey(t *testing.T) {
+	p := radius.New(radius.CodeAccessRequest, []byte("secret"))
+	p.Identifier = 7
+	r := &radius.Request{
+		Packet:     p,
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	}
+
+	key := newDuplicateKey(r)
+	if key.src != "192.0.2.1:1645" {
+		t.Errorf("src: got %q, want %q", key.src, "192.0.2.1:1645")
+	}
+	if key.identifier != 7 {
+		t.Errorf("identifier: got %d, want 7", key.identifier)
+	}
+	if key.authenticator != p.Authenticator {
+		t.Error("authenticatorがRequest Authenticatorと一致しない")
+	}
+}
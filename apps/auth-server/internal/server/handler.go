@@ -3,22 +3,32 @@ package server
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
 	radiuspkg "github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/radius"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
 	"layeh.com/radius"
 )
 
 // Handler はRADIUSリクエストを処理するハンドラ。
 // layeh.com/radius.Handlerインターフェースの実装。
 type Handler struct {
-	engine eap.EAPProcessor
+	engine         eap.EAPProcessor
+	dupCache       *DuplicateCache
+	nasLockout     session.LockoutStore
+	rateLimiter    session.RateLimiter
+	requestTimeout time.Duration
 }
 
-// NewHandler は新しいHandlerを生成する
-func NewHandler(engine eap.EAPProcessor) *Handler {
-	return &Handler{engine: engine}
+// NewHandler は新しいHandlerを生成する。
+// dupCacheがnilの場合、Access-Requestの重複検出は無効。
+// nasLockoutがnilの場合、Message-Authenticator検証失敗によるNAS単位のロックアウトは無効。
+// rateLimiterがnilの場合、要求レート制限は無効。
+// requestTimeoutが0の場合、Access-Request単位の処理期限は設定しない。
+func NewHandler(engine eap.EAPProcessor, dupCache *DuplicateCache, nasLockout session.LockoutStore, rateLimiter session.RateLimiter, requestTimeout time.Duration) *Handler {
+	return &Handler{engine: engine, dupCache: dupCache, nasLockout: nasLockout, rateLimiter: rateLimiter, requestTimeout: requestTimeout}
 }
 
 // ServeRADIUS はRADIUSリクエストを処理する
@@ -52,18 +62,154 @@ func (h *Handler) ServeRADIUS(w radius.ResponseWriter, r *radius.Request) {
 
 // handleAccessRequest はAccess-Requestを処理する
 func (h *Handler) handleAccessRequest(w radius.ResponseWriter, r *radius.Request, traceID, srcIP string) {
-	secret := r.Secret
+	// ロックアウト中のNAS → 応答なし
+	if h.nasLocked(r.Context(), traceID, srcIP) {
+		return
+	}
 
 	// Message-Authenticator検証
-	if !radiuspkg.VerifyMessageAuthenticator(r.Packet, secret) {
+	if !radiuspkg.VerifyMessageAuthenticator(r.Packet, r.Secret) {
+		srcPort := extractPort(r.RemoteAddr)
 		slog.Warn("Message-Authenticator検証失敗",
 			"event_id", "PKT_MA_INVALID",
 			"trace_id", traceID,
 			"src_ip", srcIP,
+			"src_port", srcPort,
 		)
+		h.recordNASFailure(r.Context(), traceID, srcIP, srcPort)
 		return // 応答なし
 	}
 
+	// 重複検出なし → そのまま処理
+	if h.dupCache == nil {
+		h.writeResponse(w, h.processAccessRequest(r, traceID, srcIP), traceID)
+		return
+	}
+
+	// 再送（RFC 5080 Section 2.2.2） → 前回と同一の応答を返す（処理中の場合は無応答）
+	key := newDuplicateKey(r)
+	if cached, dup := h.dupCache.begin(key); dup {
+		slog.Info("重複Access-Request受信",
+			"event_id", "PKT_DUPLICATE",
+			"trace_id", traceID,
+			"src_ip", srcIP,
+			"identifier", r.Identifier,
+			"replayed", cached != nil,
+		)
+		h.writeResponse(w, cached, traceID)
+		return
+	}
+
+	resp := h.processAccessRequest(r, traceID, srcIP)
+	h.dupCache.complete(key, resp)
+	h.writeResponse(w, resp, traceID)
+}
+
+// nasLocked は送信元NASがMessage-Authenticator検証失敗の繰り返しによりロックアウト中かどうかを判定する。
+// ロックアウト状態を取得できない場合は処理を継続する。
+func (h *Handler) nasLocked(ctx context.Context, traceID, srcIP string) bool {
+	if h.nasLockout == nil {
+		return false
+	}
+
+	locked, err := h.nasLockout.IsLocked(ctx, srcIP)
+	if err != nil {
+		slog.Warn("NASロックアウト状態取得失敗",
+			"event_id", "NAS_LOCKOUT_ERR",
+			"trace_id", traceID,
+			"src_ip", srcIP,
+			"error", err,
+		)
+		return false
+	}
+	if locked {
+		slog.Warn("ロックアウト中のNASからのAccess-Requestを破棄",
+			"event_id", "NAS_LOCKED",
+			"trace_id", traceID,
+			"src_ip", srcIP,
+		)
+	}
+	return locked
+}
+
+// recordNASFailure はMessage-Authenticator検証失敗を計数し、閾値に達した場合は送信元NASをロックアウトする。
+// 計数は送信元IPのみを鍵とするため、送信元IPを詐称したパケットで正規のNASがロックアウトされ得る。
+func (h *Handler) recordNASFailure(ctx context.Context, traceID, srcIP string, srcPort int) {
+	if h.nasLockout == nil {
+		return
+	}
+
+	failures, locked, err := h.nasLockout.RecordFailure(ctx, srcIP, "PKT_MA_INVALID")
+	if err != nil {
+		slog.Warn("Message-Authenticator検証失敗回数の記録失敗",
+			"event_id", "NAS_LOCKOUT_ERR",
+			"trace_id", traceID,
+			"src_ip", srcIP,
+			"error", err,
+		)
+		return
+	}
+	if locked {
+		slog.Warn("Message-Authenticator検証失敗回数超過によりNASをロックアウト",
+			"event_id", "NAS_LOCKOUT",
+			"trace_id", traceID,
+			"src_ip", srcIP,
+			"src_port", srcPort,
+			"failures", failures,
+		)
+	}
+}
+
+// rateLimitTarget はレート制限の対象種別とIDの組
+type rateLimitTarget struct {
+	kind session.RateLimitKind
+	id   string
+}
+
+// rateLimited はRADIUSクライアント・端末単位の要求レートを超過しているかどうかを判定する。
+// 端末（Calling-Station-Id）単位はStateを含まない認証開始要求にのみ適用する。
+// IMSI単位は永続IDの確定後にEAPエンジンで判定する（外側のUser-Nameは匿名・SUCIの場合があるため）。
+// レート制限の状態を取得できない場合は処理を継続する。
+func (h *Handler) rateLimited(ctx context.Context, traceID, srcIP, callingStation string, hasState bool) bool {
+	if h.rateLimiter == nil {
+		return false
+	}
+
+	targets := []rateLimitTarget{{session.RateLimitClient, srcIP}}
+	if !hasState && callingStation != "" {
+		targets = append(targets, rateLimitTarget{session.RateLimitStation, callingStation})
+	}
+
+	for _, t := range targets {
+		allowed, err := h.rateLimiter.Allow(ctx, t.kind, t.id)
+		if err != nil {
+			slog.Warn("レート制限状態の取得失敗",
+				"event_id", "RATE_LIMIT_ERR",
+				"trace_id", traceID,
+				"src_ip", srcIP,
+				"limit", string(t.kind),
+				"error", err,
+			)
+			continue
+		}
+		if !allowed {
+			slog.Warn("要求レート超過のためAccess-Requestを破棄",
+				"event_id", "RATE_LIMITED",
+				"trace_id", traceID,
+				"src_ip", srcIP,
+				"limit", string(t.kind),
+			)
+			return true
+		}
+	}
+	return false
+}
+
+// processAccessRequest はMessage-Authenticator検証済みのAccess-RequestをEAPエンジンで処理し、応答パケットを返す。
+// 応答しない場合はnilを返す。
+func (h *Handler) processAccessRequest(r *radius.Request, traceID, srcIP string) *radius.Packet {
+	secret := r.Secret
+
 	// EAP-Message抽出
 	eapMessage, ok := radiuspkg.GetEAPMessage(r.Packet)
 	if !ok {
@@ -72,12 +218,21 @@ func (h *Handler) handleAccessRequest(w radius.ResponseWriter, r *radius.Request
 			"trace_id", traceID,
 			"src_ip", srcIP,
 		)
-		return // 応答なし
+		return nil // 応答なし
 	}
 
 	// RADIUS属性抽出
 	nasID, _ := radiuspkg.GetNASIdentifier(r.Packet)
+	var nasIP string
+	if ip, ok := radiuspkg.GetNASIPAddress(r.Packet); ok {
+		nasIP = ip.String()
+	}
+	nasPortType, ok := radiuspkg.GetNASPortType(r.Packet)
+	if !ok {
+		nasPortType = -1
+	}
 	calledStation, _ := radiuspkg.GetCalledStationID(r.Packet)
+	callingStation, _ := radiuspkg.GetCallingStationID(r.Packet)
 	userName, _ := radiuspkg.GetUserName(r.Packet)
 	state, _ := radiuspkg.GetState(r.Packet)
 
@@ -86,79 +241,101 @@ func (h *Handler) handleAccessRequest(w radius.ResponseWriter, r *radius.Request
 
 	// EAPリクエスト構築
 	eapReq := &eap.Request{
-		TraceID:       traceID,
-		SrcIP:         srcIP,
-		NASIdentifier: nasID,
-		CalledStation: calledStation,
-		UserName:      userName,
-		State:         state,
-		EAPMessage:    eapMessage,
+		TraceID:        traceID,
+		SrcIP:          srcIP,
+		NASIdentifier:  nasID,
+		NASIPAddress:   nasIP,
+		NASPortType:    nasPortType,
+		CalledStation:  calledStation,
+		CallingStation: callingStation,
+		UserName:       userName,
+		State:          state,
+		EAPMessage:     eapMessage,
+	}
+
+	// EAPエンジン処理（Valkey・Vector Gatewayへの呼び出しにもリクエスト期限を伝搬する）
+	ctx := r.Context()
+	if h.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.requestTimeout)
+		defer cancel()
+	}
+	// 要求レート超過 → 応答なし（再送判定キャッシュに記録されないため、NASの再送は改めてレート判定される）
+	if h.rateLimited(ctx, traceID, srcIP, callingStation, len(state) > 0) {
+		return nil
 	}
 
-	// EAPエンジン処理
-	ctx := context.Background()
+	started := time.Now()
 	result, err := h.engine.Process(ctx, eapReq)
+
+	// 期限超過 → NASは既に再送または断念しているため、遅れた応答は返さない
+	// ただし認証成功はエンジンで確定済み（再送を処理できない）のため、期限超過後も応答して重複キャッシュに記録する
+	if ctx.Err() != nil && (err != nil || result.Action != eap.ActionAccept) {
+		slog.Warn("処理期限超過のため応答を破棄",
+			"event_id", "PKT_DEADLINE_EXCEEDED",
+			"trace_id", traceID,
+			"src_ip", srcIP,
+			"elapsed_ms", time.Since(started).Milliseconds(),
+			"timeout_ms", h.requestTimeout.Milliseconds(),
+		)
+		return nil // 応答なし
+	}
 	if err != nil {
 		slog.Error("EAPエンジンエラー",
 			"event_id", "EAP_ENGINE_ERR",
 			"trace_id", traceID,
 			"error", err,
 		)
-		return // 応答なし
+		return nil // 応答なし
 	}
 
 	// 結果に基づいてRADIUS応答を構築
 	switch result.Action {
 	case eap.ActionAccept:
-		resp := radiuspkg.BuildAccessAccept(r.Packet, secret, &radiuspkg.AcceptParams{
-			EAPMessage:     result.EAPMessage,
-			MSK:            result.MSK,
-			SessionID:      result.SessionID,
-			VlanID:         result.VlanID,
-			SessionTimeout: result.SessionTimeout,
-			ProxyStates:    proxyStates,
+		return radiuspkg.BuildAccessAccept(r.Packet, secret, &radiuspkg.AcceptParams{
+			EAPMessage:      result.EAPMessage,
+			MSK:             result.MSK,
+			SessionID:       result.SessionID,
+			VlanID:          result.VlanID,
+			SessionTimeout:  result.SessionTimeout,
+			ReplyAttributes: result.ReplyAttributes,
+			ProxyStates:     proxyStates,
+			TraceID:         traceID,
 		})
-		if err := w.Write(resp); err != nil {
-			slog.Error("RADIUS応答送信失敗",
-				"event_id", "PKT_SEND_ERR",
-				"trace_id", traceID,
-				"error", err,
-			)
-		}
 
 	case eap.ActionChallenge:
-		resp := radiuspkg.BuildAccessChallenge(r.Packet, secret, &radiuspkg.ChallengeParams{
+		return radiuspkg.BuildAccessChallenge(r.Packet, secret, &radiuspkg.ChallengeParams{
 			EAPMessage:  result.EAPMessage,
 			State:       result.State,
 			ProxyStates: proxyStates,
 		})
-		if err := w.Write(resp); err != nil {
-			slog.Error("RADIUS応答送信失敗",
-				"event_id", "PKT_SEND_ERR",
-				"trace_id", traceID,
-				"error", err,
-			)
-		}
 
 	case eap.ActionReject:
-		resp := radiuspkg.BuildAccessReject(r.Packet, secret, &radiuspkg.RejectParams{
+		return radiuspkg.BuildAccessReject(r.Packet, secret, &radiuspkg.RejectParams{
 			EAPMessage:  result.EAPMessage,
 			ProxyStates: proxyStates,
 		})
-		if err := w.Write(resp); err != nil {
-			slog.Error("RADIUS応答送信失敗",
-				"event_id", "PKT_SEND_ERR",
-				"trace_id", traceID,
-				"error", err,
-			)
-		}
 
 	case eap.ActionDrop:
 		slog.Info("パケットドロップ",
 			"event_id", "PKT_DROP",
 			"trace_id", traceID,
 		)
-		// 応答なし
+	}
+	return nil // 応答なし
+}
+
+// writeResponse はRADIUS応答を送信する（respがnilの場合は送信しない）
+func (h *Handler) writeResponse(w radius.ResponseWriter, resp *radius.Packet, traceID string) {
+	if resp == nil {
+		return
+	}
+	if err := w.Write(resp); err != nil {
+		slog.Error("RADIUS応答送信失敗",
+			"event_id", "PKT_SEND_ERR",
+			"trace_id", traceID,
+			"error", err,
+		)
 	}
 }
 
@@ -1,16 +1,21 @@
 package server
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/md5"
 	"errors"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/eap"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/mocks"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
 	eapaka "github.com/oyaguma3/go-eapaka"
 	"go.uber.org/mock/gomock"
 	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
 	"layeh.com/radius/rfc2869"
 )
 
@@ -82,7 +87,7 @@ func TestHandler_AccessRequest_Accept(t *testing.T) {
 			SessionTimeout: 3600,
 		}, nil)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	eapMsg := buildTestEAPIdentity()
@@ -115,7 +120,7 @@ func TestHandler_AccessRequest_Challenge(t *testing.T) {
 			State:      []byte("trace-id"),
 		}, nil)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	eapMsg := buildTestEAPIdentity()
@@ -145,7 +150,7 @@ func TestHandler_AccessRequest_Reject(t *testing.T) {
 			EAPMessage: []byte{4, 2, 0, 4}, // EAP-Failure
 		}, nil)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	eapMsg := buildTestEAPIdentity()
@@ -174,7 +179,7 @@ func TestHandler_AccessRequest_Drop(t *testing.T) {
 			Action: eap.ActionDrop,
 		}, nil)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	eapMsg := buildTestEAPIdentity()
@@ -197,7 +202,7 @@ func TestHandler_AccessRequest_NoMA(t *testing.T) {
 	mockEngine := mocks.NewMockEAPProcessor(ctrl)
 	// Process呼び出しは期待しない
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	p := &radius.Packet{
@@ -223,7 +228,7 @@ func TestHandler_StatusServer(t *testing.T) {
 
 	mockEngine := mocks.NewMockEAPProcessor(ctrl)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	p := &radius.Packet{
@@ -253,7 +258,7 @@ func TestHandler_StatusServer_InvalidMA(t *testing.T) {
 
 	mockEngine := mocks.NewMockEAPProcessor(ctrl)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	p := &radius.Packet{
@@ -283,7 +288,7 @@ func TestHandler_StatusServer_NoMA(t *testing.T) {
 
 	mockEngine := mocks.NewMockEAPProcessor(ctrl)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	p := &radius.Packet{
@@ -310,7 +315,7 @@ func TestHandler_UnknownCode(t *testing.T) {
 
 	mockEngine := mocks.NewMockEAPProcessor(ctrl)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	p := &radius.Packet{
 		Code:       radius.CodeAccountingRequest,
@@ -336,7 +341,7 @@ func TestHandler_AccessRequest_EngineError(t *testing.T) {
 	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
 		Return(nil, errors.New("engine error"))
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	eapMsg := buildTestEAPIdentity()
@@ -369,7 +374,7 @@ func TestHandler_AccessRequest_WriteError(t *testing.T) {
 			SessionTimeout: 3600,
 		}, nil)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	eapMsg := buildTestEAPIdentity()
@@ -392,7 +397,7 @@ func TestHandler_StatusServer_WriteError(t *testing.T) {
 
 	mockEngine := mocks.NewMockEAPProcessor(ctrl)
 
-	handler := NewHandler(mockEngine)
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
 
 	secret := []byte("test-secret")
 	p := &radius.Packet{
@@ -413,3 +418,441 @@ func TestHandler_StatusServer_WriteError(t *testing.T) {
 		t.Fatalf("written packets: got %d, want 1", len(rw.written))
 	}
 }
+
+func TestHandler_AccessRequest_DuplicateReplayed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 再送はEAPエンジンで再処理しない
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		Return(&eap.Result{
+			Action:     eap.ActionChallenge,
+			EAPMessage: []byte{1, 2, 0, 8, 23, 5, 0, 0},
+			State:      []byte("trace-id"),
+		}, nil).Times(1)
+
+	handler := NewHandler(mockEngine, NewDuplicateCache(5*time.Second), nil, nil, 0)
+
+	secret := []byte("test-secret")
+	p := buildTestAccessRequest(secret, buildTestEAPIdentity())
+	req := &radius.Request{
+		Packet:     p,
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	}
+
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, req)
+	handler.ServeRADIUS(rw, req)
+
+	if len(rw.written) != 2 {
+		t.Fatalf("written packets: got %d, want 2", len(rw.written))
+	}
+	if rw.written[1] != rw.written[0] {
+		t.Error("再送に前回と異なる応答を返した")
+	}
+}
+
+func TestHandler_AccessRequest_DuplicateAfterDrop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 応答しなかったリクエストの再送は改めて処理する
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		Return(&eap.Result{Action: eap.ActionDrop}, nil).Times(2)
+
+	handler := NewHandler(mockEngine, NewDuplicateCache(5*time.Second), nil, nil, 0)
+
+	secret := []byte("test-secret")
+	p := buildTestAccessRequest(secret, buildTestEAPIdentity())
+	req := &radius.Request{Packet: p}
+
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, req)
+	handler.ServeRADIUS(rw, req)
+
+	if len(rw.written) != 0 {
+		t.Errorf("written packets: got %d, want 0 (drop)", len(rw.written))
+	}
+}
+
+func TestHandler_AccessRequest_DeadlineApplied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 処理期限がEAPエンジンへ渡されること
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *eap.Request) (*eap.Result, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Error("処理期限が設定されていない")
+			} else if remaining := time.Until(deadline); remaining <= 0 || remaining > 3*time.Second {
+				t.Errorf("remaining: got %v, want (0, 3s]", remaining)
+			}
+			return &eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil
+		})
+
+	handler := NewHandler(mockEngine, nil, nil, nil, 3*time.Second)
+
+	secret := []byte("test-secret")
+	p := buildTestAccessRequest(secret, buildTestEAPIdentity())
+
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{Packet: p})
+
+	if len(rw.written) != 1 {
+		t.Fatalf("written packets: got %d, want 1", len(rw.written))
+	}
+}
+
+func TestHandler_AccessRequest_DeadlineExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 期限超過後に得られた結果は応答しない
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *eap.Request) (*eap.Result, error) {
+			<-ctx.Done()
+			return &eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil
+		}).Times(2)
+
+	handler := NewHandler(mockEngine, NewDuplicateCache(5*time.Second), nil, nil, 10*time.Millisecond)
+
+	secret := []byte("test-secret")
+	p := buildTestAccessRequest(secret, buildTestEAPIdentity())
+	req := &radius.Request{Packet: p}
+
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, req)
+
+	if len(rw.written) != 0 {
+		t.Fatalf("written packets: got %d, want 0", len(rw.written))
+	}
+
+	// 破棄した応答はキャッシュされず、再送は改めて処理される
+	handler.ServeRADIUS(rw, req)
+	if len(rw.written) != 0 {
+		t.Errorf("written packets: got %d, want 0", len(rw.written))
+	}
+}
+
+func TestHandler_AccessRequest_DeadlineExceededAfterAccept(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 認証成功の確定後に期限を超過した場合も応答し、再送にはキャッシュから応答する
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ *eap.Request) (*eap.Result, error) {
+			<-ctx.Done()
+			return &eap.Result{
+				Action:     eap.ActionAccept,
+				EAPMessage: []byte{3, 2, 0, 4},
+				MSK:        make([]byte, 64),
+				SessionID:  "test-session",
+			}, nil
+		}).Times(1)
+
+	handler := NewHandler(mockEngine, NewDuplicateCache(5*time.Second), nil, nil, 10*time.Millisecond)
+
+	secret := []byte("test-secret")
+	p := buildTestAccessRequest(secret, buildTestEAPIdentity())
+	req := &radius.Request{Packet: p}
+
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, req)
+	handler.ServeRADIUS(rw, req)
+
+	if len(rw.written) != 2 {
+		t.Fatalf("written packets: got %d, want 2", len(rw.written))
+	}
+	for i, w := range rw.written {
+		if w.Code != radius.CodeAccessAccept {
+			t.Errorf("written[%d] Code: got %v, want %v", i, w.Code, radius.CodeAccessAccept)
+		}
+	}
+}
+
+func TestHandler_AccessRequest_PolicyAttributes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// ポリシー評価に使用するRADIUS属性がEAPエンジンへ渡されること
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *eap.Request) (*eap.Result, error) {
+			if req.NASIdentifier != "ap-01" {
+				t.Errorf("NASIdentifier: got %q, want ap-01", req.NASIdentifier)
+			}
+			if req.NASIPAddress != "10.0.0.1" {
+				t.Errorf("NASIPAddress: got %q, want 10.0.0.1", req.NASIPAddress)
+			}
+			if req.NASPortType != int(rfc2865.NASPortType_Value_Wireless80211) {
+				t.Errorf("NASPortType: got %d, want %d", req.NASPortType, rfc2865.NASPortType_Value_Wireless80211)
+			}
+			if req.CallingStation != "11-22-33-44-55-66" {
+				t.Errorf("CallingStation: got %q", req.CallingStation)
+			}
+			return &eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil
+		})
+
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
+
+	secret := []byte("test-secret")
+	p := &radius.Packet{Code: radius.CodeAccessRequest, Identifier: 1, Secret: secret}
+	_ = rfc2869.EAPMessage_Set(p, buildTestEAPIdentity())
+	_ = rfc2865.NASIdentifier_SetString(p, "ap-01")
+	_ = rfc2865.NASIPAddress_Set(p, net.ParseIP("10.0.0.1"))
+	_ = rfc2865.NASPortType_Set(p, rfc2865.NASPortType_Value_Wireless80211)
+	_ = rfc2865.CallingStationID_SetString(p, "11-22-33-44-55-66")
+	setValidMessageAuthenticator(p, secret)
+
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{Packet: p})
+}
+
+func TestHandler_AccessRequest_NoNASPortType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *eap.Request) (*eap.Result, error) {
+			if req.NASPortType != -1 {
+				t.Errorf("NASPortType: got %d, want -1", req.NASPortType)
+			}
+			if req.NASIPAddress != "" {
+				t.Errorf("NASIPAddress: got %q, want empty", req.NASIPAddress)
+			}
+			return &eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil
+		})
+
+	handler := NewHandler(mockEngine, nil, nil, nil, 0)
+
+	secret := []byte("test-secret")
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{Packet: buildTestAccessRequest(secret, buildTestEAPIdentity())})
+}
+
+func TestHandler_AccessRequest_NASLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	// Process呼び出しは期待しない
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), "192.0.2.1").Return(true, nil)
+
+	handler := NewHandler(mockEngine, nil, mockLockout, nil, 0)
+
+	secret := []byte("test-secret")
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{
+		Packet:     buildTestAccessRequest(secret, buildTestEAPIdentity()),
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	})
+
+	if len(rw.written) != 0 {
+		t.Errorf("written packets: got %d, want 0 (NAS locked)", len(rw.written))
+	}
+}
+
+func TestHandler_AccessRequest_InvalidMA_RecordsNASFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), "192.0.2.1").Return(false, nil)
+	mockLockout.EXPECT().RecordFailure(gomock.Any(), "192.0.2.1", "PKT_MA_INVALID").Return(int64(10), true, nil)
+
+	handler := NewHandler(mockEngine, nil, mockLockout, nil, 0)
+
+	p := buildTestAccessRequest([]byte("wrong-secret"), buildTestEAPIdentity())
+	p.Secret = []byte("test-secret")
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{
+		Packet:     p,
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	})
+
+	if len(rw.written) != 0 {
+		t.Errorf("written packets: got %d, want 0 (MA verification failed)", len(rw.written))
+	}
+}
+
+func TestHandler_AccessRequest_NASLockoutError_Continues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		Return(&eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil)
+	mockLockout := mocks.NewMockLockoutStore(ctrl)
+	mockLockout.EXPECT().IsLocked(gomock.Any(), "192.0.2.1").Return(false, errors.New("valkey down"))
+
+	handler := NewHandler(mockEngine, nil, mockLockout, nil, 0)
+
+	secret := []byte("test-secret")
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{
+		Packet:     buildTestAccessRequest(secret, buildTestEAPIdentity()),
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	})
+
+	// ロックアウト状態を取得できない場合は処理を継続する
+	if len(rw.written) != 1 || rw.written[0].Code != radius.CodeAccessReject {
+		t.Errorf("written packets: got %d, want 1 Access-Reject", len(rw.written))
+	}
+}
+
+// buildTestRateLimitRequest はUser-Name・Calling-Station-Id（・State）付きのAccess-Requestを構築する
+func buildTestRateLimitRequest(secret, state []byte) *radius.Packet {
+	p := &radius.Packet{Code: radius.CodeAccessRequest, Identifier: 1, Secret: secret}
+	_ = rfc2869.EAPMessage_Set(p, buildTestEAPIdentity())
+	_ = rfc2865.UserName_SetString(p, "0440101234567890@wlan.mnc010.mcc440.3gppnetwork.org")
+	_ = rfc2865.CallingStationID_SetString(p, "11-22-33-44-55-66")
+	if state != nil {
+		_ = rfc2865.State_Set(p, state)
+	}
+	setValidMessageAuthenticator(p, secret)
+	return p
+}
+
+func TestHandler_AccessRequest_RateLimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		limited session.RateLimitKind // 破棄する対象種別
+	}{
+		{"RADIUSクライアント", session.RateLimitClient},
+		{"端末", session.RateLimitStation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockEngine := mocks.NewMockEAPProcessor(ctrl)
+			// Process呼び出しは期待しない
+			mockLimiter := mocks.NewMockRateLimiter(ctrl)
+			targets := []struct {
+				kind session.RateLimitKind
+				id   string
+			}{
+				{session.RateLimitClient, "192.0.2.1"},
+				{session.RateLimitStation, "11-22-33-44-55-66"},
+			}
+			var calls []any
+			for _, target := range targets {
+				allowed := target.kind != tt.limited
+				calls = append(calls, mockLimiter.EXPECT().Allow(gomock.Any(), target.kind, target.id).Return(allowed, nil))
+				if !allowed {
+					break
+				}
+			}
+			gomock.InOrder(calls...)
+
+			handler := NewHandler(mockEngine, nil, nil, mockLimiter, 0)
+
+			secret := []byte("test-secret")
+			rw := &mockResponseWriter{}
+			handler.ServeRADIUS(rw, &radius.Request{
+				Packet:     buildTestRateLimitRequest(secret, nil),
+				RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+			})
+
+			if len(rw.written) != 0 {
+				t.Errorf("written packets: got %d, want 0 (rate limited)", len(rw.written))
+			}
+		})
+	}
+}
+
+func TestHandler_AccessRequest_RateLimit_WithState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		Return(&eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil)
+	// 認証継続中の要求は端末単位の制限を適用しない
+	mockLimiter := mocks.NewMockRateLimiter(ctrl)
+	mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitClient, "192.0.2.1").Return(true, nil)
+
+	handler := NewHandler(mockEngine, nil, nil, mockLimiter, 0)
+
+	secret := []byte("test-secret")
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{
+		Packet:     buildTestRateLimitRequest(secret, []byte("trace-id")),
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	})
+
+	if len(rw.written) != 1 || rw.written[0].Code != radius.CodeAccessReject {
+		t.Errorf("written packets: got %d, want 1 Access-Reject", len(rw.written))
+	}
+}
+
+func TestHandler_AccessRequest_RateLimited_Retransmit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		Return(&eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil)
+	// 破棄した要求の再送は再送判定キャッシュで止めず、改めてトークンを消費して判定する
+	mockLimiter := mocks.NewMockRateLimiter(ctrl)
+	gomock.InOrder(
+		mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitClient, "192.0.2.1").Return(false, nil),
+		mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitClient, "192.0.2.1").Return(true, nil),
+		mockLimiter.EXPECT().Allow(gomock.Any(), session.RateLimitStation, "11-22-33-44-55-66").Return(true, nil),
+	)
+
+	handler := NewHandler(mockEngine, NewDuplicateCache(5*time.Second), nil, mockLimiter, 0)
+
+	secret := []byte("test-secret")
+	req := &radius.Request{
+		Packet:     buildTestRateLimitRequest(secret, nil),
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	}
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, req)
+	if len(rw.written) != 0 {
+		t.Fatalf("written packets: got %d, want 0 (rate limited)", len(rw.written))
+	}
+	handler.ServeRADIUS(rw, req)
+
+	if len(rw.written) != 1 || rw.written[0].Code != radius.CodeAccessReject {
+		t.Errorf("written packets: got %d, want 1 Access-Reject", len(rw.written))
+	}
+}
+
+func TestHandler_AccessRequest_RateLimitError_Continues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEngine := mocks.NewMockEAPProcessor(ctrl)
+	mockEngine.EXPECT().Process(gomock.Any(), gomock.Any()).
+		Return(&eap.Result{Action: eap.ActionReject, EAPMessage: []byte{4, 2, 0, 4}}, nil)
+	mockLimiter := mocks.NewMockRateLimiter(ctrl)
+	mockLimiter.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(true, errors.New("valkey down")).Times(2)
+
+	handler := NewHandler(mockEngine, nil, nil, mockLimiter, 0)
+
+	secret := []byte("test-secret")
+	rw := &mockResponseWriter{}
+	handler.ServeRADIUS(rw, &radius.Request{
+		Packet:     buildTestRateLimitRequest(secret, nil),
+		RemoteAddr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1645},
+	})
+
+	// レート制限の状態を取得できない場合は処理を継続する
+	if len(rw.written) != 1 || rw.written[0].Code != radius.CodeAccessReject {
+		t.Errorf("written packets: got %d, want 1 Access-Reject", len(rw.written))
+	}
+}
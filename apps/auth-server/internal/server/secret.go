@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"net"
+	"strconv"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
 )
@@ -92,3 +93,19 @@ func extractIP(addr net.Addr) string {
 	}
 	return host
 }
+
+// extractPort はnet.Addrから送信元ポート番号を抽出する（取得できない場合は0）
+func extractPort(addr net.Addr) int {
+	if addr == nil {
+		return 0
+	}
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		return udpAddr.Port
+	}
+	_, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(port)
+	return n
+}
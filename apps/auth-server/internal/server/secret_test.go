@@ -128,6 +128,26 @@ func TestSecretSource_IPExtraction(t *testing.T) {
 	}
 }
 
+func TestExtractPort(t *testing.T) {
+	tests := []struct {
+		name string
+		addr net.Addr
+		want int
+	}{
+		{"UDPAddr", &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 50000}, 50000},
+		{"TCPAddr", &net.TCPAddr{IP: net.ParseIP("172.16.0.1"), Port: 1812}, 1812},
+		{"nil addr", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractPort(tt.addr); got != tt.want {
+				t.Errorf("extractPort: got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSecretSource_NilAddr_WithFallback(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
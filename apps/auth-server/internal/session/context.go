@@ -4,24 +4,73 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/redis/go-redis/v9"
+
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
 )
 
 // EAPContext はEAP認証コンテキストを表す（D-09 セクション6.6.1/9.2.1準拠）。
 type EAPContext struct {
-	IMSI                 string `redis:"imsi"`
-	Stage                string `redis:"stage"`
-	EAPType              uint8  `redis:"eap_type"`
-	RAND                 string `redis:"rand"`
-	AUTN                 string `redis:"autn"`
-	XRES                 string `redis:"xres"`
-	Kaut                 string `redis:"k_aut"`
-	MSK                  string `redis:"msk"`
-	ResyncCount          int    `redis:"resync_count"`
-	PermanentIDRequested bool   `redis:"permanent_id_requested"`
+	IMSI            string `redis:"imsi"`
+	Stage           string `redis:"stage"`
+	EAPType         uint8  `redis:"eap_type"`
+	RAND            string `redis:"rand"`
+	AUTN            string `redis:"autn"`
+	XRES            string `redis:"xres"`
+	Kaut            string `redis:"k_aut"`
+	MSK             string `redis:"msk"`
+	EMSK            string `redis:"emsk"` // ERPのrRK導出に使用するEMSK
+	ResyncCount     int    `redis:"resync_count"`
+	IdentityReqSent uint8  `redis:"identity_req_sent"` // 送信済みAKA-Identity要求（eap.IdentityReqTypeのビット集合）
+	KEncr           string `redis:"k_encr"`
+	ReauthKey       string `redis:"reauth_key"`       // EAP-AKA: MK, EAP-AKA': K_re
+	NextReauthID    string `redis:"next_reauth_id"`   // AT_NEXT_REAUTH_IDで通知した再認証ID
+	ReauthID        string `redis:"reauth_id"`        // 高速再認証中の再認証ID
+	NextPseudonym   string `redis:"next_pseudonym"`   // AT_NEXT_PSEUDONYMで通知した仮名（成功時に登録）
+	Pseudonym       string `redis:"pseudonym"`        // フル認証で使用された仮名（成功時に削除）
+	Counter         int    `redis:"counter"`          // 高速再認証のAT_COUNTER
+	NonceS          string `redis:"nonce_s"`          // 高速再認証のAT_NONCE_S
+	Notification    int    `redis:"notification"`     // 送信したAT_NOTIFICATIONの通知コード
+	VlanID          string `redis:"vlan_id"`          // 成功通知応答後のAccessAcceptで使用するVLAN ID
+	SessionTimeout  int    `redis:"session_timeout"`  // 成功通知応答後のAccessAcceptで使用するタイムアウト
+	MaxSessions     int    `redis:"max_sessions"`     // 成功通知応答後のセッション作成で適用する同時セッション数の上限
+	ReplyAttributes string `redis:"reply_attributes"` // 成功通知応答後のAccessAcceptで使用する応答属性（JSON）
+	Identity        string `redis:"identity"`         // 鍵導出に使用したIdentity
+	NetworkName     string `redis:"network_name"`     // EAP-AKA': AT_KDF_INPUTで使用したネットワーク名
+	Checkcode       string `redis:"checkcode"`        // AKA-Identityメッセージのハッシュ途中状態（AT_CHECKCODE計算用）
+	NonceMT         string `redis:"nonce_mt"`         // EAP-SIM: AT_NONCE_MT
+	SRES            string `redis:"sres"`             // EAP-SIM: n*SRES（RANDはrandにn*RANDとして保存）
+	EAPIdentifier   int    `redis:"eap_identifier"`   // 最後に送信したEAP-RequestのIdentifier+1（0は未記録）
 }
 
+// updateScript はEAPコンテキストの存在確認・比較・更新・TTLリフレッシュを1回の操作で行うLuaスクリプト。
+// KEYS[1]: EAPコンテキストのキー
+// ARGV[1]: TTL（ミリ秒）、ARGV[2]: 比較するフィールド数n
+// ARGV[3]以降: 比較するフィールドと期待値をn組、続けて更新するフィールドと値
+// 戻り値: 1=更新成功、0=期待値と不一致、-1=コンテキストなし
+var updateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local i = 3
+for _ = 1, tonumber(ARGV[2]) do
+	local v = redis.call('HGET', KEYS[1], ARGV[i])
+	if v == false then
+		v = ''
+	end
+	if v ~= ARGV[i + 1] then
+		return 0
+	end
+	i = i + 2
+end
+if i <= #ARGV then
+	redis.call('HSET', KEYS[1], unpack(ARGV, i))
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 1
+`)
+
 // contextStore はContextStoreの実装。
 type contextStore struct {
 	vc *store.ValkeyClient
@@ -66,22 +115,34 @@ func (s *contextStore) Get(ctx context.Context, traceID string) (*EAPContext, er
 
 // Update はEAPコンテキストを部分更新し、TTLをリフレッシュする。
 func (s *contextStore) Update(ctx context.Context, traceID string, updates map[string]any) error {
+	return s.CompareAndUpdate(ctx, traceID, nil, updates)
+}
+
+// CompareAndUpdate はEAPコンテキストのフィールドがexpectedと一致する場合のみ部分更新し、TTLをリフレッシュする。
+// 比較・更新はValkey上で不可分に行うため、同一コンテキストへの並行リクエストのうち1つだけが成功する。
+// 一致しない場合はErrContextConflictを返す。
+func (s *contextStore) CompareAndUpdate(ctx context.Context, traceID string, expected, updates map[string]any) error {
 	key := store.KeyPrefixEAPContext + traceID
 
-	exists, err := s.vc.Client().Exists(ctx, key).Result()
-	if err != nil {
-		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	args := make([]any, 0, 2+2*(len(expected)+len(updates)))
+	args = append(args, config.EAPContextTTL.Milliseconds(), len(expected))
+	for field, value := range expected {
+		args = append(args, field, value)
 	}
-	if exists == 0 {
-		return ErrContextNotFound
+	for field, value := range updates {
+		args = append(args, field, value)
 	}
 
-	pipe := s.vc.Client().Pipeline()
-	pipe.HSet(ctx, key, updates)
-	pipe.Expire(ctx, key, config.EAPContextTTL)
-	if _, err := pipe.Exec(ctx); err != nil {
+	res, err := updateScript.Run(ctx, s.vc.Client(), []string{key}, args...).Int()
+	if err != nil {
 		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
 	}
+	switch res {
+	case -1:
+		return ErrContextNotFound
+	case 0:
+		return ErrContextConflict
+	}
 	return nil
 }
 
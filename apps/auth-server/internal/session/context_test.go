@@ -36,16 +36,16 @@ func TestContextStoreCreate(t *testing.T) {
 	ctx := context.Background()
 
 	eapCtx := &EAPContext{
-		IMSI:                 "440101234567890",
-		Stage:                "identity",
-		EAPType:              23,
-		RAND:                 "aabbccdd",
-		AUTN:                 "11223344",
-		XRES:                 "deadbeef",
-		Kaut:                 "cafebabe",
-		MSK:                  "01020304",
-		ResyncCount:          0,
-		PermanentIDRequested: false,
+		IMSI:            "440101234567890",
+		Stage:           "identity",
+		EAPType:         23,
+		RAND:            "aabbccdd",
+		AUTN:            "11223344",
+		XRES:            "deadbeef",
+		Kaut:            "cafebabe",
+		MSK:             "01020304",
+		ResyncCount:     0,
+		IdentityReqSent: 0,
 	}
 
 	if err := cs.Create(ctx, "trace-001", eapCtx); err != nil {
@@ -92,7 +92,7 @@ func TestContextStoreGet(t *testing.T) {
 	mr.HSet("eap:trace-get", "k_aut", "1122")
 	mr.HSet("eap:trace-get", "msk", "3344")
 	mr.HSet("eap:trace-get", "resync_count", "2")
-	mr.HSet("eap:trace-get", "permanent_id_requested", "1")
+	mr.HSet("eap:trace-get", "identity_req_sent", "5")
 
 	got, err := cs.Get(ctx, "trace-get")
 	if err != nil {
@@ -125,8 +125,8 @@ func TestContextStoreGet(t *testing.T) {
 	if got.ResyncCount != 2 {
 		t.Errorf("ResyncCount: got %v, want 2", got.ResyncCount)
 	}
-	if got.PermanentIDRequested != true {
-		t.Errorf("PermanentIDRequested: got %v, want true", got.PermanentIDRequested)
+	if got.IdentityReqSent != 5 {
+		t.Errorf("IdentityReqSent: got %v, want 5", got.IdentityReqSent)
 	}
 }
 
@@ -217,6 +217,74 @@ func TestContextStoreUpdateRefreshTTL(t *testing.T) {
 	}
 }
 
+func TestContextStoreCompareAndUpdate(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	cs := NewContextStore(vc)
+	ctx := context.Background()
+
+	eapCtx := &EAPContext{IMSI: "440101234567890", Stage: "CHALLENGE_SENT", ResyncCount: 1}
+	if err := cs.Create(ctx, "trace-cas", eapCtx); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	mr.FastForward(30 * time.Second)
+
+	expected := map[string]any{"stage": "CHALLENGE_SENT", "resync_count": 1}
+	updates := map[string]any{"stage": "RESYNC_SENT", "resync_count": 2}
+	if err := cs.CompareAndUpdate(ctx, "trace-cas", expected, updates); err != nil {
+		t.Fatalf("CompareAndUpdate failed: %v", err)
+	}
+
+	got, err := cs.Get(ctx, "trace-cas")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Stage != "RESYNC_SENT" || got.ResyncCount != 2 {
+		t.Errorf("Stage/ResyncCount: got %v/%v, want RESYNC_SENT/2", got.Stage, got.ResyncCount)
+	}
+	if ttl := mr.TTL("eap:trace-cas"); ttl != config.EAPContextTTL {
+		t.Errorf("TTL after update: got %v, want %v", ttl, config.EAPContextTTL)
+	}
+
+	// 同じ期待値での2回目の遷移は競合として失敗し、値は変更されない
+	err = cs.CompareAndUpdate(ctx, "trace-cas", expected, map[string]any{"stage": "FAILURE"})
+	if !errors.Is(err, ErrContextConflict) {
+		t.Errorf("expected ErrContextConflict, got: %v", err)
+	}
+	if v := mr.HGet("eap:trace-cas", "stage"); v != "RESYNC_SENT" {
+		t.Errorf("stage after conflict: got %v, want RESYNC_SENT", v)
+	}
+}
+
+func TestContextStoreCompareAndUpdateMissingField(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	cs := NewContextStore(vc)
+	ctx := context.Background()
+
+	// 未設定のフィールドは空文字列として比較する
+	mr.HSet("eap:trace-cas2", "stage", "WAITING_IDENTITY")
+	err := cs.CompareAndUpdate(ctx, "trace-cas2", map[string]any{"checkcode": ""}, map[string]any{"stage": "IDENTITY_RECEIVED"})
+	if err != nil {
+		t.Fatalf("CompareAndUpdate failed: %v", err)
+	}
+	if v := mr.HGet("eap:trace-cas2", "stage"); v != "IDENTITY_RECEIVED" {
+		t.Errorf("stage: got %v, want IDENTITY_RECEIVED", v)
+	}
+}
+
+func TestContextStoreCompareAndUpdateNotFound(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	cs := NewContextStore(vc)
+	ctx := context.Background()
+
+	err := cs.CompareAndUpdate(ctx, "nonexistent", map[string]any{"stage": "CHALLENGE_SENT"}, map[string]any{"stage": "RESYNC_SENT"})
+	if !errors.Is(err, ErrContextNotFound) {
+		t.Errorf("expected ErrContextNotFound, got: %v", err)
+	}
+}
+
 func TestContextStoreDelete(t *testing.T) {
 	mr := miniredis.RunT(t)
 	vc := newTestValkeyClient(t, mr)
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+// ERPKey はERP（RFC 6696）の再認証に必要な鍵情報を表す。キーはkeyName-NAIのユーザー名部（EMSKname）。
+type ERPKey struct {
+	IMSI      string `redis:"imsi"`
+	EAPType   uint8  `redis:"eap_type"`   // rRKの導出元となったEAP方式
+	RRK       string `redis:"rrk"`        // rRK（hex）
+	Seq       int    `redis:"seq"`        // 最後に受け付けたSEQ（未使用の場合は-1）
+	ExpiresAt int64  `redis:"expires_at"` // rRK有効期限（Unix秒）
+}
+
+// advanceSeqScript はSEQが前回値より大きい場合のみ更新するLuaスクリプト。
+// KEYS[1]: ERP鍵のキー、ARGV[1]: 受信したSEQ
+// 戻り値: 1=更新成功、0=使用済みのSEQ、-1=鍵なし
+var advanceSeqScript = redis.NewScript(`
+local last = redis.call('HGET', KEYS[1], 'seq')
+if last == false then
+	return -1
+end
+if tonumber(ARGV[1]) <= tonumber(last) then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'seq', ARGV[1])
+return 1
+`)
+
+// erpStore はERPStoreの実装。
+type erpStore struct {
+	vc *store.ValkeyClient
+}
+
+// NewERPStore はERPStoreの新しいインスタンスを生成する。
+func NewERPStore(vc *store.ValkeyClient) ERPStore {
+	return &erpStore{vc: vc}
+}
+
+// Create はERP鍵を保存する。キーはExpiresAtで失効する。
+func (s *erpStore) Create(ctx context.Context, keyName string, key *ERPKey) error {
+	k := store.KeyPrefixERP + keyName
+	m := store.StructToMap(key)
+
+	pipe := s.vc.Client().Pipeline()
+	pipe.HSet(ctx, k, m)
+	pipe.ExpireAt(ctx, k, time.Unix(key.ExpiresAt, 0))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return nil
+}
+
+// Get はERP鍵を取得する。未登録・期限切れの場合はErrERPKeyNotFoundを返す。
+func (s *erpStore) Get(ctx context.Context, keyName string) (*ERPKey, error) {
+	k := store.KeyPrefixERP + keyName
+	m, err := s.vc.Client().HGetAll(ctx, k).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	if len(m) == 0 {
+		return nil, ErrERPKeyNotFound
+	}
+
+	var key ERPKey
+	if err := store.MapToStruct(m, &key); err != nil {
+		return nil, fmt.Errorf("erp key deserialization error: %w", err)
+	}
+	if key.IMSI == "" || time.Now().Unix() >= key.ExpiresAt {
+		return nil, ErrERPKeyNotFound
+	}
+	return &key, nil
+}
+
+// AdvanceSeq は受信したSEQを記録する（RFC 6696 Section 5.3.2のリプレイ保護）。
+// SEQが前回受け付けた値以下の場合はErrERPSeqReplay、鍵がない場合はErrERPKeyNotFoundを返す。
+// 比較と更新を1回の操作で行うため、同一SEQの並行リクエストは1件のみ成功する。
+func (s *erpStore) AdvanceSeq(ctx context.Context, keyName string, seq uint16) error {
+	k := store.KeyPrefixERP + keyName
+	res, err := advanceSeqScript.Run(ctx, s.vc.Client(), []string{k}, int(seq)).Int()
+	if err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	switch res {
+	case -1:
+		return ErrERPKeyNotFound
+	case 0:
+		return ErrERPSeqReplay
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+func TestERPStoreCreateAndGet(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	es := NewERPStore(vc)
+	ctx := context.Background()
+
+	key := &ERPKey{
+		IMSI:      "440101234567890",
+		EAPType:   50,
+		RRK:       "0102",
+		Seq:       -1,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+	if err := es.Create(ctx, "0123456789abcdef", key); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// rRK有効期限に合わせてキーが失効する
+	if ttl := mr.TTL("erp:0123456789abcdef"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("TTL: got %v, want (0, 1h]", ttl)
+	}
+
+	got, err := es.Get(ctx, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.IMSI != key.IMSI || got.EAPType != 50 || got.RRK != "0102" || got.Seq != -1 {
+		t.Errorf("unexpected key: %+v", got)
+	}
+}
+
+func TestERPStoreGetNotFound(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	es := NewERPStore(vc)
+
+	_, err := es.Get(context.Background(), "unknown")
+	if !errors.Is(err, ErrERPKeyNotFound) {
+		t.Errorf("expected ErrERPKeyNotFound, got: %v", err)
+	}
+
+	// キー失効前でもExpiresAtを過ぎていれば無効
+	mr.HSet("erp:old", "imsi", "440101234567890")
+	mr.HSet("erp:old", "expires_at", "1")
+	_, err = es.Get(context.Background(), "old")
+	if !errors.Is(err, ErrERPKeyNotFound) {
+		t.Errorf("expected ErrERPKeyNotFound, got: %v", err)
+	}
+}
+
+func TestERPStoreAdvanceSeq(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	es := NewERPStore(vc)
+	ctx := context.Background()
+
+	mr.HSet("erp:k", "imsi", "440101234567890")
+	mr.HSet("erp:k", "seq", "-1")
+
+	if err := es.AdvanceSeq(ctx, "k", 0); err != nil {
+		t.Fatalf("AdvanceSeq(0) failed: %v", err)
+	}
+	if err := es.AdvanceSeq(ctx, "k", 0); !errors.Is(err, ErrERPSeqReplay) {
+		t.Errorf("AdvanceSeq(0) again: expected ErrERPSeqReplay, got: %v", err)
+	}
+	if err := es.AdvanceSeq(ctx, "k", 5); err != nil {
+		t.Fatalf("AdvanceSeq(5) failed: %v", err)
+	}
+	if err := es.AdvanceSeq(ctx, "k", 3); !errors.Is(err, ErrERPSeqReplay) {
+		t.Errorf("AdvanceSeq(3): expected ErrERPSeqReplay, got: %v", err)
+	}
+	if got := mr.HGet("erp:k", "seq"); got != "5" {
+		t.Errorf("seq: got %q, want %q", got, "5")
+	}
+
+	if err := es.AdvanceSeq(ctx, "missing", 1); !errors.Is(err, ErrERPKeyNotFound) {
+		t.Errorf("expected ErrERPKeyNotFound, got: %v", err)
+	}
+}
+
+func TestERPStoreAdvanceSeqConcurrent(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	es := NewERPStore(vc)
+
+	mr.HSet("erp:k", "imsi", "440101234567890")
+	mr.HSet("erp:k", "seq", "-1")
+
+	// 同一SEQの並行リクエストは1件のみ受け付ける
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := es.AdvanceSeq(context.Background(), "k", 1); err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != 1 {
+		t.Errorf("accepted: got %d, want 1", accepted)
+	}
+}
+
+func TestERPStoreValkeyError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	es := NewERPStore(vc)
+
+	mr.Close()
+
+	_, err := es.Get(context.Background(), "any")
+	if !errors.Is(err, store.ErrValkeyUnavailable) {
+		t.Errorf("expected ErrValkeyUnavailable, got: %v", err)
+	}
+	if err := es.AdvanceSeq(context.Background(), "any", 1); !errors.Is(err, store.ErrValkeyUnavailable) {
+		t.Errorf("expected ErrValkeyUnavailable, got: %v", err)
+	}
+}
@@ -12,6 +12,9 @@ var (
 
 	// ErrContextInvalid はEAPコンテキストの内容が不正な場合のエラー
 	ErrContextInvalid = errors.New("eap context invalid")
+
+	// ErrContextConflict はEAPコンテキストが他のリクエストにより更新済みの場合のエラー
+	ErrContextConflict = errors.New("eap context modified concurrently")
 )
 
 // セッション関連エラー
@@ -22,3 +25,30 @@ var (
 	// ErrSessionExpired はセッションの有効期限が切れた場合のエラー
 	ErrSessionExpired = errors.New("session expired")
 )
+
+// 仮名関連エラー
+var (
+	// ErrPseudonymNotFound は仮名が未登録または有効期限切れの場合のエラー
+	ErrPseudonymNotFound = errors.New("pseudonym not found")
+)
+
+// 高速再認証関連エラー
+var (
+	// ErrReauthNotFound は再認証IDが未登録または鍵有効期限切れの場合のエラー
+	ErrReauthNotFound = errors.New("reauth context not found")
+)
+
+// ERP関連エラー
+var (
+	// ErrERPKeyNotFound はkeyName-NAIに対応するrRKが未登録または有効期限切れの場合のエラー
+	ErrERPKeyNotFound = errors.New("erp key not found")
+
+	// ErrERPSeqReplay はEAP-Initiate/Re-authのSEQが使用済みの場合のエラー
+	ErrERPSeqReplay = errors.New("erp sequence number replayed")
+)
+
+// ベクターキャッシュ関連エラー
+var (
+	// ErrVectorCacheInvalid はキャッシュしたベクターを復号できない場合のエラー
+	ErrVectorCacheInvalid = errors.New("vector cache entry invalid")
+)
@@ -1,12 +1,17 @@
 package session
 
-import "context"
+import (
+	"context"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+)
 
 // ContextStore はEAP認証コンテキストのCRUD操作を定義する。
 type ContextStore interface {
 	Create(ctx context.Context, traceID string, eapCtx *EAPContext) error
 	Get(ctx context.Context, traceID string) (*EAPContext, error)
 	Update(ctx context.Context, traceID string, updates map[string]any) error
+	CompareAndUpdate(ctx context.Context, traceID string, expected, updates map[string]any) error
 	Delete(ctx context.Context, traceID string) error
 	Exists(ctx context.Context, traceID string) (bool, error)
 }
@@ -15,5 +20,47 @@ type ContextStore interface {
 type SessionStore interface {
 	Create(ctx context.Context, sessionID string, sess *Session) error
 	Get(ctx context.Context, sessionID string) (*Session, error)
-	AddUserIndex(ctx context.Context, imsi string, sessionID string) error
+	AddUserIndex(ctx context.Context, imsi string, sessionID string, maxSessions int) (bool, error)
+	ListByIMSI(ctx context.Context, imsi string) ([]*Session, error)
+	Delete(ctx context.Context, imsi string, sessionID string) error
+}
+
+// PseudonymStore は仮名→IMSIマッピングの操作を定義する。
+type PseudonymStore interface {
+	Create(ctx context.Context, pseudonym string, entry *PseudonymEntry) error
+	Get(ctx context.Context, pseudonym string) (*PseudonymEntry, error)
+	Delete(ctx context.Context, pseudonym string) error
+}
+
+// ReauthStore は高速再認証コンテキストの操作を定義する。
+type ReauthStore interface {
+	Create(ctx context.Context, reauthID string, rc *ReauthContext) error
+	Get(ctx context.Context, reauthID string) (*ReauthContext, error)
+	Delete(ctx context.Context, reauthID string) error
+}
+
+// ERPStore はERP鍵（rRK）とシーケンス番号の操作を定義する。
+type ERPStore interface {
+	Create(ctx context.Context, keyName string, key *ERPKey) error
+	Get(ctx context.Context, keyName string) (*ERPKey, error)
+	AdvanceSeq(ctx context.Context, keyName string, seq uint16) error
+}
+
+// LockoutStore は認証失敗回数の計数と一時的なロックアウトの操作を定義する。
+type LockoutStore interface {
+	IsLocked(ctx context.Context, id string) (bool, error)
+	RecordFailure(ctx context.Context, id, reason string) (failures int64, locked bool, err error)
+	ClearFailures(ctx context.Context, id string) error
+}
+
+// RateLimiter はトークンバケットによる対象種別・ID単位の要求レート制限を定義する。
+type RateLimiter interface {
+	Allow(ctx context.Context, kind RateLimitKind, id string) (bool, error)
+}
+
+// VectorCache は先行取得した認証ベクターのIMSI単位のキャッシュ操作を定義する。
+type VectorCache interface {
+	Pop(ctx context.Context, imsi string) (*vector.Quintet, error)
+	Store(ctx context.Context, imsi string, usedSQN uint64, vectors []vector.Quintet) error
+	Flush(ctx context.Context, imsi string) error
 }
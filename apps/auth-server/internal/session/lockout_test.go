@@ -0,0 +1,132 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestLockoutStoreRecordFailure(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ls := NewIMSILockoutStore(vc, 3, 5*time.Minute, 15*time.Minute)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	for i := int64(1); i <= 2; i++ {
+		failures, locked, err := ls.RecordFailure(ctx, imsi, "AUTH_MAC_INVALID")
+		if err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+		if failures != i || locked {
+			t.Errorf("%d回目: got failures=%d locked=%v", i, failures, locked)
+		}
+	}
+	// 計数期間は最初の失敗から開始し、以降の失敗では延長しない
+	if ttl := mr.TTL("authfail:imsi:" + imsi); ttl != 5*time.Minute {
+		t.Errorf("計数期間: got %v, want %v", ttl, 5*time.Minute)
+	}
+	if locked, _ := ls.IsLocked(ctx, imsi); locked {
+		t.Error("閾値未満でロックアウトされている")
+	}
+
+	failures, locked, err := ls.RecordFailure(ctx, imsi, "AUTH_RES_MISMATCH")
+	if err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	if failures != 3 || !locked {
+		t.Errorf("閾値到達: got failures=%d locked=%v", failures, locked)
+	}
+	if locked, _ := ls.IsLocked(ctx, imsi); !locked {
+		t.Error("閾値到達後にロックアウトされていない")
+	}
+	if ttl := mr.TTL("lockout:imsi:" + imsi); ttl != 15*time.Minute {
+		t.Errorf("ロックアウト期間: got %v, want %v", ttl, 15*time.Minute)
+	}
+	if got := mr.HGet("lockout:imsi:"+imsi, "reason"); got != "AUTH_RES_MISMATCH" {
+		t.Errorf("reason: got %q, want AUTH_RES_MISMATCH", got)
+	}
+	if got := mr.HGet("lockout:imsi:"+imsi, "failures"); got != "3" {
+		t.Errorf("failures: got %q, want 3", got)
+	}
+	if mr.Exists("authfail:imsi:" + imsi) {
+		t.Error("ロックアウト開始後に失敗回数が残っている")
+	}
+
+	// ロックアウト期間の経過で解除される
+	mr.FastForward(15 * time.Minute)
+	if locked, _ := ls.IsLocked(ctx, imsi); locked {
+		t.Error("ロックアウト期間経過後も解除されていない")
+	}
+}
+
+func TestLockoutStoreWindowExpired(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ls := NewNASLockoutStore(vc, 2, time.Minute, 5*time.Minute)
+	ctx := context.Background()
+
+	if _, _, err := ls.RecordFailure(ctx, "192.168.1.1", "PKT_MA_INVALID"); err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	// 計数期間を過ぎた失敗は合算しない
+	mr.FastForward(time.Minute)
+	failures, locked, err := ls.RecordFailure(ctx, "192.168.1.1", "PKT_MA_INVALID")
+	if err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	if failures != 1 || locked {
+		t.Errorf("got failures=%d locked=%v, want 1 false", failures, locked)
+	}
+	if !mr.Exists("authfail:nas:192.168.1.1") {
+		t.Error("NASの失敗回数キーがない")
+	}
+}
+
+func TestLockoutStoreClearFailures(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ls := NewIMSILockoutStore(vc, 3, 5*time.Minute, 15*time.Minute)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	for range 2 {
+		if _, _, err := ls.RecordFailure(ctx, imsi, "AUTH_MAC_INVALID"); err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+	}
+	if err := ls.ClearFailures(ctx, imsi); err != nil {
+		t.Fatalf("ClearFailures failed: %v", err)
+	}
+	if mr.Exists("authfail:imsi:" + imsi) {
+		t.Error("リセット後に失敗回数が残っている")
+	}
+
+	// リセット後は1回目から計数し直す
+	failures, locked, err := ls.RecordFailure(ctx, imsi, "AUTH_MAC_INVALID")
+	if err != nil {
+		t.Fatalf("RecordFailure failed: %v", err)
+	}
+	if failures != 1 || locked {
+		t.Errorf("got failures=%d locked=%v, want 1 false", failures, locked)
+	}
+}
+
+func TestLockoutStoreValkeyDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ls := NewIMSILockoutStore(vc, 3, time.Minute, time.Minute)
+	mr.Close()
+
+	if _, err := ls.IsLocked(context.Background(), "440101234567890"); err == nil {
+		t.Error("Valkey停止時にエラーが返らない")
+	}
+	if _, _, err := ls.RecordFailure(context.Background(), "440101234567890", "AUTH_MAC_INVALID"); err == nil {
+		t.Error("Valkey停止時にエラーが返らない")
+	}
+	if err := ls.ClearFailures(context.Background(), "440101234567890"); err == nil {
+		t.Error("Valkey停止時にエラーが返らない")
+	}
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+// pseudonymRandomBytes は仮名のランダム部のバイト長
+const pseudonymRandomBytes = 16
+
+// PseudonymEntry は仮名に紐付くIMSI情報を表す。
+type PseudonymEntry struct {
+	IMSI      string `redis:"imsi"`
+	EAPType   uint8  `redis:"eap_type"`
+	CreatedAt int64  `redis:"created_at"`
+}
+
+// pseudonymStore はPseudonymStoreの実装。
+type pseudonymStore struct {
+	vc *store.ValkeyClient
+}
+
+// NewPseudonymStore はPseudonymStoreの新しいインスタンスを生成する。
+func NewPseudonymStore(vc *store.ValkeyClient) PseudonymStore {
+	return &pseudonymStore{vc: vc}
+}
+
+// Create は仮名→IMSIマッピングをTTL付きでValkeyに保存する。
+func (s *pseudonymStore) Create(ctx context.Context, pseudonym string, entry *PseudonymEntry) error {
+	key := store.KeyPrefixPseudonym + pseudonym
+	m := store.StructToMap(entry)
+
+	pipe := s.vc.Client().Pipeline()
+	pipe.HSet(ctx, key, m)
+	pipe.Expire(ctx, key, config.PseudonymTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return nil
+}
+
+// Get は仮名に紐付くIMSI情報を取得する。未登録・期限切れの場合はErrPseudonymNotFoundを返す。
+func (s *pseudonymStore) Get(ctx context.Context, pseudonym string) (*PseudonymEntry, error) {
+	key := store.KeyPrefixPseudonym + pseudonym
+	m, err := s.vc.Client().HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	if len(m) == 0 {
+		return nil, ErrPseudonymNotFound
+	}
+
+	var entry PseudonymEntry
+	if err := store.MapToStruct(m, &entry); err != nil {
+		return nil, fmt.Errorf("pseudonym deserialization error: %w", err)
+	}
+	if entry.IMSI == "" {
+		return nil, ErrPseudonymNotFound
+	}
+	return &entry, nil
+}
+
+// Delete は仮名マッピングを削除する。存在しなくてもエラーにしない。
+func (s *pseudonymStore) Delete(ctx context.Context, pseudonym string) error {
+	key := store.KeyPrefixPseudonym + pseudonym
+	if err := s.vc.Client().Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return nil
+}
+
+// GeneratePseudonym は指定プレフィックス（'2' or '7'）付きの仮名ユーザー名部を生成する。
+func GeneratePseudonym(prefix rune) (string, error) {
+	b := make([]byte, pseudonymRandomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate pseudonym: %w", err)
+	}
+	return string(prefix) + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,117 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+func TestPseudonymStoreCreateAndGet(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ps := NewPseudonymStore(vc)
+	ctx := context.Background()
+
+	entry := &PseudonymEntry{IMSI: "440101234567890", EAPType: 50, CreatedAt: 1700000000}
+	if err := ps.Create(ctx, "7abcdef", entry); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// TTL確認
+	ttl := mr.TTL("pseudo:7abcdef")
+	if ttl != config.PseudonymTTL {
+		t.Errorf("TTL: got %v, want %v", ttl, config.PseudonymTTL)
+	}
+
+	got, err := ps.Get(ctx, "7abcdef")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.IMSI != "440101234567890" {
+		t.Errorf("IMSI: got %v, want 440101234567890", got.IMSI)
+	}
+	if got.EAPType != 50 {
+		t.Errorf("EAPType: got %v, want 50", got.EAPType)
+	}
+}
+
+func TestPseudonymStoreGetNotFound(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ps := NewPseudonymStore(vc)
+
+	_, err := ps.Get(context.Background(), "2unknown")
+	if !errors.Is(err, ErrPseudonymNotFound) {
+		t.Errorf("expected ErrPseudonymNotFound, got: %v", err)
+	}
+}
+
+func TestPseudonymStoreExpired(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ps := NewPseudonymStore(vc)
+	ctx := context.Background()
+
+	if err := ps.Create(ctx, "2expire", &PseudonymEntry{IMSI: "440101234567890", EAPType: 23}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	mr.FastForward(config.PseudonymTTL + 1)
+
+	_, err := ps.Get(ctx, "2expire")
+	if !errors.Is(err, ErrPseudonymNotFound) {
+		t.Errorf("expected ErrPseudonymNotFound after expiry, got: %v", err)
+	}
+}
+
+func TestPseudonymStoreDelete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ps := NewPseudonymStore(vc)
+	ctx := context.Background()
+
+	mr.HSet("pseudo:2del", "imsi", "440101234567890")
+	if err := ps.Delete(ctx, "2del"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if mr.Exists("pseudo:2del") {
+		t.Error("key should be deleted")
+	}
+
+	// 存在しないキーの削除もエラーにならない
+	if err := ps.Delete(ctx, "2none"); err != nil {
+		t.Errorf("Delete(nonexistent) failed: %v", err)
+	}
+}
+
+func TestPseudonymStoreValkeyError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ps := NewPseudonymStore(vc)
+
+	mr.Close()
+
+	_, err := ps.Get(context.Background(), "2any")
+	if !errors.Is(err, store.ErrValkeyUnavailable) {
+		t.Errorf("expected ErrValkeyUnavailable, got: %v", err)
+	}
+}
+
+func TestGeneratePseudonym(t *testing.T) {
+	p, err := GeneratePseudonym('7')
+	if err != nil {
+		t.Fatalf("GeneratePseudonym failed: %v", err)
+	}
+	if !regexp.MustCompile(`^7[0-9a-f]{32}$`).MatchString(p) {
+		t.Errorf("GeneratePseudonym() = %q, unexpected format", p)
+	}
+
+	q, _ := GeneratePseudonym('7')
+	if p == q {
+		t.Error("pseudonyms should be unique")
+	}
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+// RateLimitKind はレート制限の対象種別を表す。
+type RateLimitKind string
+
+// レート制限の対象種別
+const (
+	RateLimitClient  RateLimitKind = "client"  // RADIUSクライアント（送信元IP）
+	RateLimitStation RateLimitKind = "station" // 端末（Calling-Station-Id）
+	RateLimitIMSI    RateLimitKind = "imsi"    // 加入者（永続IDのIMSI）
+)
+
+// RateLimit はトークンバケットの設定を表す。
+// Rateは1秒あたりの補充トークン数、Burstはバケットの容量（連続して受け付ける要求数）。
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// takeTokenScript はトークンバケットからトークンを1つ取り出すLuaスクリプト。
+// KEYS[1]: トークンバケット（Hash）、KEYS[2]: 破棄数の統計（Hash）
+// ARGV[1]: 1秒あたりの補充トークン数、ARGV[2]: バケットの容量、ARGV[3]: 統計のフィールド名
+// 戻り値: 1=受付、0=破棄
+// 複数のAuth Serverで同じバケットを共有するため、時刻はValkeyのTIMEを使用する。
+var takeTokenScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(b[1])
+local ts = tonumber(b[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+if allowed == 0 then
+	redis.call('HINCRBY', KEYS[2], ARGV[3], 1)
+end
+return allowed
+`)
+
+// rateLimiter はRateLimiterの実装。
+// バケットはバケットが満杯に戻るまでの期間を有効期限として保持し、以降は削除されて満杯から再開する。
+type rateLimiter struct {
+	vc     *store.ValkeyClient
+	limits map[RateLimitKind]RateLimit
+}
+
+// NewRateLimiter はRateLimiterの新しいインスタンスを生成する。
+// limitsに含まれない、またはRateが0以下の対象種別は制限しない。
+func NewRateLimiter(vc *store.ValkeyClient, limits map[RateLimitKind]RateLimit) RateLimiter {
+	return &rateLimiter{vc: vc, limits: limits}
+}
+
+// Allow は対象の要求を受け付けるかどうかを返す。
+// 破棄した場合は統計（stats:ratelimit）の対象種別の破棄数を加算する。
+func (r *rateLimiter) Allow(ctx context.Context, kind RateLimitKind, id string) (bool, error) {
+	limit, ok := r.limits[kind]
+	if !ok || limit.Rate <= 0 {
+		return true, nil
+	}
+
+	keys := []string{store.KeyPrefixRateLimit + string(kind) + ":" + id, store.KeyRateLimitStats}
+	args := []any{strconv.FormatFloat(limit.Rate, 'f', -1, 64), limit.Burst, string(kind)}
+	allowed, err := takeTokenScript.Run(ctx, r.vc.Client(), keys, args...).Int()
+	if err != nil {
+		return true, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return allowed == 1, nil
+}
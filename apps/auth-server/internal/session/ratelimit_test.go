@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	now := time.Unix(1700000000, 0)
+	mr.SetTime(now)
+	vc := newTestValkeyClient(t, mr)
+	rl := NewRateLimiter(vc, map[RateLimitKind]RateLimit{
+		RateLimitClient: {Rate: 2, Burst: 3},
+	})
+	ctx := context.Background()
+
+	// 容量までは連続して受け付ける
+	for i := 1; i <= 3; i++ {
+		allowed, err := rl.Allow(ctx, RateLimitClient, "192.0.2.1")
+		if err != nil {
+			t.Fatalf("Allow failed: %v", err)
+		}
+		if !allowed {
+			t.Fatalf("%d回目: 破棄された", i)
+		}
+	}
+	allowed, err := rl.Allow(ctx, RateLimitClient, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Allow failed: %v", err)
+	}
+	if allowed {
+		t.Error("容量超過: 受け付けられた")
+	}
+	if got := mr.HGet(store.KeyRateLimitStats, "client"); got != "1" {
+		t.Errorf("破棄数: got %q, want %q", got, "1")
+	}
+
+	// 他の送信元は別のバケット
+	if allowed, _ := rl.Allow(ctx, RateLimitClient, "192.0.2.2"); !allowed {
+		t.Error("別の送信元: 破棄された")
+	}
+
+	// 0.5秒で1トークン補充
+	mr.SetTime(now.Add(500 * time.Millisecond))
+	if allowed, _ := rl.Allow(ctx, RateLimitClient, "192.0.2.1"); !allowed {
+		t.Error("補充後: 破棄された")
+	}
+	if allowed, _ := rl.Allow(ctx, RateLimitClient, "192.0.2.1"); allowed {
+		t.Error("補充分の消費後: 受け付けられた")
+	}
+	if got := mr.HGet(store.KeyRateLimitStats, "client"); got != "2" {
+		t.Errorf("破棄数: got %q, want %q", got, "2")
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	rl := NewRateLimiter(vc, map[RateLimitKind]RateLimit{
+		RateLimitStation: {Rate: 0, Burst: 1},
+	})
+
+	// 設定なし・Rateが0の対象種別はValkeyにアクセスせずに受け付ける
+	mr.Close()
+	for _, kind := range []RateLimitKind{RateLimitStation, RateLimitIMSI} {
+		allowed, err := rl.Allow(context.Background(), kind, "id")
+		if err != nil || !allowed {
+			t.Errorf("%s: got allowed=%v err=%v", kind, allowed, err)
+		}
+	}
+}
+
+func TestRateLimiterValkeyDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	rl := NewRateLimiter(vc, map[RateLimitKind]RateLimit{
+		RateLimitIMSI: {Rate: 1, Burst: 10},
+	})
+	mr.Close()
+
+	allowed, err := rl.Allow(context.Background(), RateLimitIMSI, "440101234567890")
+	if !errors.Is(err, store.ErrValkeyUnavailable) {
+		t.Errorf("error: got %v, want ErrValkeyUnavailable", err)
+	}
+	if !allowed {
+		t.Error("Valkey障害時は受け付ける")
+	}
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+// reauthIDRandomBytes は再認証IDのランダム部のバイト長
+const reauthIDRandomBytes = 16
+
+// ReauthContext は高速再認証に必要な鍵情報を表す（RFC 4187 Section 5 / RFC 5448 Section 3.3）。
+type ReauthContext struct {
+	IMSI      string `redis:"imsi"`
+	EAPType   uint8  `redis:"eap_type"`
+	ReauthKey string `redis:"reauth_key"` // EAP-AKA: MK, EAP-AKA': K_re（hex）
+	KEncr     string `redis:"k_encr"`     // フル認証時のK_encr（hex）
+	Kaut      string `redis:"k_aut"`      // フル認証時のK_aut（hex）
+	Counter   int    `redis:"counter"`    // 最後に使用したAT_COUNTER値（フル認証直後は0）
+	ExpiresAt int64  `redis:"expires_at"` // 鍵有効期限（Unix秒）
+}
+
+// reauthStore はReauthStoreの実装。
+type reauthStore struct {
+	vc *store.ValkeyClient
+}
+
+// NewReauthStore はReauthStoreの新しいインスタンスを生成する。
+func NewReauthStore(vc *store.ValkeyClient) ReauthStore {
+	return &reauthStore{vc: vc}
+}
+
+// Create は再認証コンテキストを保存する。キーはExpiresAtで失効する。
+func (s *reauthStore) Create(ctx context.Context, reauthID string, rc *ReauthContext) error {
+	key := store.KeyPrefixReauth + reauthID
+	m := store.StructToMap(rc)
+
+	pipe := s.vc.Client().Pipeline()
+	pipe.HSet(ctx, key, m)
+	pipe.ExpireAt(ctx, key, time.Unix(rc.ExpiresAt, 0))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return nil
+}
+
+// Get は再認証コンテキストを取得する。未登録・期限切れの場合はErrReauthNotFoundを返す。
+func (s *reauthStore) Get(ctx context.Context, reauthID string) (*ReauthContext, error) {
+	key := store.KeyPrefixReauth + reauthID
+	m, err := s.vc.Client().HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	if len(m) == 0 {
+		return nil, ErrReauthNotFound
+	}
+
+	var rc ReauthContext
+	if err := store.MapToStruct(m, &rc); err != nil {
+		return nil, fmt.Errorf("reauth context deserialization error: %w", err)
+	}
+	if rc.IMSI == "" || time.Now().Unix() >= rc.ExpiresAt {
+		return nil, ErrReauthNotFound
+	}
+	return &rc, nil
+}
+
+// Delete は再認証コンテキストを削除する。存在しなくてもエラーにしない。
+func (s *reauthStore) Delete(ctx context.Context, reauthID string) error {
+	key := store.KeyPrefixReauth + reauthID
+	if err := s.vc.Client().Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return nil
+}
+
+// GenerateReauthID は指定プレフィックス（'4' or '8'）付きの再認証IDユーザー名部を生成する。
+func GenerateReauthID(prefix rune) (string, error) {
+	b := make([]byte, reauthIDRandomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate reauth id: %w", err)
+	}
+	return string(prefix) + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+)
+
+func TestReauthStoreCreateAndGet(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	rs := NewReauthStore(vc)
+	ctx := context.Background()
+
+	rc := &ReauthContext{
+		IMSI:      "440101234567890",
+		EAPType:   23,
+		ReauthKey: "0102",
+		KEncr:     "0304",
+		Kaut:      "0506",
+		Counter:   2,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+	if err := rs.Create(ctx, "4abcdef", rc); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// 鍵有効期限に合わせてキーが失効する
+	if ttl := mr.TTL("reauth:4abcdef"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("TTL: got %v, want (0, 1h]", ttl)
+	}
+
+	got, err := rs.Get(ctx, "4abcdef")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.IMSI != rc.IMSI || got.Counter != 2 || got.ReauthKey != "0102" {
+		t.Errorf("unexpected context: %+v", got)
+	}
+}
+
+func TestReauthStoreGetNotFound(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	rs := NewReauthStore(vc)
+
+	_, err := rs.Get(context.Background(), "4unknown")
+	if !errors.Is(err, ErrReauthNotFound) {
+		t.Errorf("expected ErrReauthNotFound, got: %v", err)
+	}
+}
+
+func TestReauthStoreGetExpired(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	rs := NewReauthStore(vc)
+
+	// キー失効前でもExpiresAtを過ぎていれば無効
+	mr.HSet("reauth:4old", "imsi", "440101234567890")
+	mr.HSet("reauth:4old", "expires_at", "1")
+
+	_, err := rs.Get(context.Background(), "4old")
+	if !errors.Is(err, ErrReauthNotFound) {
+		t.Errorf("expected ErrReauthNotFound, got: %v", err)
+	}
+}
+
+func TestReauthStoreDelete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	rs := NewReauthStore(vc)
+
+	mr.HSet("reauth:4del", "imsi", "440101234567890")
+	if err := rs.Delete(context.Background(), "4del"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if mr.Exists("reauth:4del") {
+		t.Error("key should be deleted")
+	}
+}
+
+func TestReauthStoreValkeyError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	rs := NewReauthStore(vc)
+
+	mr.Close()
+
+	_, err := rs.Get(context.Background(), "4any")
+	if !errors.Is(err, store.ErrValkeyUnavailable) {
+		t.Errorf("expected ErrValkeyUnavailable, got: %v", err)
+	}
+}
+
+func TestGenerateReauthID(t *testing.T) {
+	id, err := GenerateReauthID('8')
+	if err != nil {
+		t.Fatalf("GenerateReauthID failed: %v", err)
+	}
+	if !regexp.MustCompile(`^8[0-9a-f]{32}$`).MatchString(id) {
+		t.Errorf("GenerateReauthID() = %q, unexpected format", id)
+	}
+}
@@ -2,15 +2,19 @@ package session
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/google/uuid"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+	"github.com/redis/go-redis/v9"
 )
 
 // Session はアクティブセッションを表す（D-09 セクション9.3.1準拠）。
 type Session struct {
+	ID           string `redis:"-"` // セッションID（ListByIMSIで設定）
 	IMSI         string `redis:"imsi"`
 	NasIP        string `redis:"nas_ip"`
 	StartTime    int64  `redis:"start_time"`
@@ -62,10 +66,102 @@ func (s *sessionStore) Get(ctx context.Context, sessionID string) (*Session, err
 	return &sess, nil
 }
 
+// addUserIndexScript は同時セッション数を確認してユーザーインデックスにセッションIDを追加するLuaスクリプト。
+// KEYS[1]: ユーザーインデックス（Set）
+// ARGV[1]: セッションID、ARGV[2]: 同時セッション数の上限（0は無制限）、ARGV[3]: セッションのキープレフィックス
+// 戻り値: 1=追加、0=上限到達のため追加しない
+// 確認と追加を1つのスクリプトで行い、並行する認証が同時に上限を超えないようにする。
+// アクティブセッションの確認のためKEYS以外のセッションキーも参照する（単一インスタンス構成が前提）。
+var addUserIndexScript = redis.NewScript(`
+local max = tonumber(ARGV[2])
+if max > 0 then
+	local active = 0
+	for _, id in ipairs(redis.call('SMEMBERS', KEYS[1])) do
+		if id ~= ARGV[1] then
+			if redis.call('EXISTS', ARGV[3] .. id) == 1 then
+				active = active + 1
+			else
+				redis.call('SREM', KEYS[1], id)
+			end
+		end
+	end
+	if active >= max then
+		return 0
+	end
+end
+redis.call('SADD', KEYS[1], ARGV[1])
+return 1
+`)
+
 // AddUserIndex はIMSIとセッションIDの紐付けをSet型で追加する。
-func (s *sessionStore) AddUserIndex(ctx context.Context, imsi string, sessionID string) error {
-	key := store.KeyPrefixUserIndex + imsi
-	if err := s.vc.Client().SAdd(ctx, key, sessionID).Err(); err != nil {
+// maxSessionsが0より大きい場合、他のアクティブセッション数が上限に達していれば追加せずfalseを返す。
+// インデックスに残っている期限切れ・削除済みのセッションは確認時にインデックスから取り除く。
+func (s *sessionStore) AddUserIndex(ctx context.Context, imsi string, sessionID string, maxSessions int) (bool, error) {
+	keys := []string{store.KeyPrefixUserIndex + imsi}
+	added, err := addUserIndexScript.Run(ctx, s.vc.Client(), keys, sessionID, maxSessions, store.KeyPrefixSession).Int()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return added == 1, nil
+}
+
+// ListByIMSI はIMSIのアクティブセッションを開始時刻の古い順に返す。
+// インデックスに残っている期限切れ・削除済みのセッションはインデックスから取り除く。
+func (s *sessionStore) ListByIMSI(ctx context.Context, imsi string) ([]*Session, error) {
+	indexKey := store.KeyPrefixUserIndex + imsi
+	ids, err := s.vc.Client().SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	pipe := s.vc.Client().Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, store.KeyPrefixSession+id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+
+	var sessions []*Session
+	var stale []any
+	for i, cmd := range cmds {
+		m, err := cmd.Result()
+		if err != nil {
+			continue
+		}
+		if len(m) == 0 {
+			stale = append(stale, ids[i])
+			continue
+		}
+		var sess Session
+		if err := store.MapToStruct(m, &sess); err != nil {
+			continue
+		}
+		sess.ID = ids[i]
+		sessions = append(sessions, &sess)
+	}
+
+	// 存在しないセッションのクリーンアップ（失敗しても次回再試行される）
+	if len(stale) > 0 {
+		_ = s.vc.Client().SRem(ctx, indexKey, stale...).Err()
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime < sessions[j].StartTime
+	})
+	return sessions, nil
+}
+
+// Delete はセッションを削除し、IMSIのインデックスから取り除く。
+func (s *sessionStore) Delete(ctx context.Context, imsi string, sessionID string) error {
+	pipe := s.vc.Client().Pipeline()
+	pipe.Del(ctx, store.KeyPrefixSession+sessionID)
+	pipe.SRem(ctx, store.KeyPrefixUserIndex+imsi, sessionID)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
 	}
 	return nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"regexp"
+	"slices"
 	"testing"
 
 	"github.com/alicebob/miniredis/v2"
@@ -113,7 +114,7 @@ func TestSessionStoreAddUserIndex(t *testing.T) {
 	ss := NewSessionStore(vc)
 	ctx := context.Background()
 
-	if err := ss.AddUserIndex(ctx, "440101234567890", "sess-001"); err != nil {
+	if _, err := ss.AddUserIndex(ctx, "440101234567890", "sess-001", 0); err != nil {
 		t.Fatalf("AddUserIndex failed: %v", err)
 	}
 
@@ -127,7 +128,7 @@ func TestSessionStoreAddUserIndex(t *testing.T) {
 	}
 
 	// 2つ目のセッション追加
-	if err := ss.AddUserIndex(ctx, "440101234567890", "sess-002"); err != nil {
+	if _, err := ss.AddUserIndex(ctx, "440101234567890", "sess-002", 0); err != nil {
 		t.Fatalf("AddUserIndex(2nd) failed: %v", err)
 	}
 	members, err = mr.Members("idx:user:440101234567890")
@@ -139,6 +140,50 @@ func TestSessionStoreAddUserIndex(t *testing.T) {
 	}
 }
 
+func TestSessionStoreAddUserIndexLimit(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ss := NewSessionStore(vc)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	for _, id := range []string{"sess-001", "sess-002"} {
+		if err := ss.Create(ctx, id, &Session{IMSI: imsi}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		added, err := ss.AddUserIndex(ctx, imsi, id, 2)
+		if err != nil || !added {
+			t.Fatalf("AddUserIndex(%s): added=%v, err=%v", id, added, err)
+		}
+	}
+
+	// 上限到達時は追加しない
+	added, err := ss.AddUserIndex(ctx, imsi, "sess-003", 2)
+	if err != nil {
+		t.Fatalf("AddUserIndex failed: %v", err)
+	}
+	if added {
+		t.Error("上限到達時に追加された")
+	}
+	if ok, _ := mr.SIsMember("idx:user:"+imsi, "sess-003"); ok {
+		t.Error("上限到達時にインデックスへ追加された")
+	}
+
+	// 期限切れのセッションは数えずインデックスから取り除く
+	mr.Del("sess:sess-001")
+	added, err = ss.AddUserIndex(ctx, imsi, "sess-003", 2)
+	if err != nil || !added {
+		t.Fatalf("AddUserIndex after expiry: added=%v, err=%v", added, err)
+	}
+	members, err := mr.Members("idx:user:" + imsi)
+	if err != nil {
+		t.Fatalf("Members failed: %v", err)
+	}
+	if want := []string{"sess-002", "sess-003"}; !slices.Equal(members, want) {
+		t.Errorf("members: got %v, want %v", members, want)
+	}
+}
+
 func TestSessionStoreAddUserIndexDuplicate(t *testing.T) {
 	mr := miniredis.RunT(t)
 	vc := newTestValkeyClient(t, mr)
@@ -146,10 +191,10 @@ func TestSessionStoreAddUserIndexDuplicate(t *testing.T) {
 	ctx := context.Background()
 
 	// 同じセッションIDを2回追加してもエラーにならない
-	if err := ss.AddUserIndex(ctx, "440101234567890", "sess-dup"); err != nil {
+	if _, err := ss.AddUserIndex(ctx, "440101234567890", "sess-dup", 0); err != nil {
 		t.Fatalf("AddUserIndex failed: %v", err)
 	}
-	if err := ss.AddUserIndex(ctx, "440101234567890", "sess-dup"); err != nil {
+	if _, err := ss.AddUserIndex(ctx, "440101234567890", "sess-dup", 0); err != nil {
 		t.Fatalf("AddUserIndex(duplicate) failed: %v", err)
 	}
 
@@ -163,6 +208,76 @@ func TestSessionStoreAddUserIndexDuplicate(t *testing.T) {
 	}
 }
 
+func TestSessionStoreListByIMSI(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ss := NewSessionStore(vc)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	for _, s := range []struct {
+		id    string
+		start int64
+	}{{"sess-new", 1700000200}, {"sess-old", 1700000000}, {"sess-mid", 1700000100}} {
+		if err := ss.Create(ctx, s.id, &Session{IMSI: imsi, StartTime: s.start}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if _, err := ss.AddUserIndex(ctx, imsi, s.id, 0); err != nil {
+			t.Fatalf("AddUserIndex failed: %v", err)
+		}
+	}
+	// 期限切れのセッション（インデックスのみ残存）
+	if _, err := ss.AddUserIndex(ctx, imsi, "sess-stale", 0); err != nil {
+		t.Fatalf("AddUserIndex failed: %v", err)
+	}
+
+	got, err := ss.ListByIMSI(ctx, imsi)
+	if err != nil {
+		t.Fatalf("ListByIMSI failed: %v", err)
+	}
+	var ids []string
+	for _, sess := range got {
+		ids = append(ids, sess.ID)
+	}
+	if want := []string{"sess-old", "sess-mid", "sess-new"}; !slices.Equal(ids, want) {
+		t.Errorf("ListByIMSI: got %v, want %v", ids, want)
+	}
+	if ok, _ := mr.SIsMember("idx:user:"+imsi, "sess-stale"); ok {
+		t.Error("期限切れセッションがインデックスに残っている")
+	}
+
+	// セッションなし
+	got, err = ss.ListByIMSI(ctx, "440109999999999")
+	if err != nil || len(got) != 0 {
+		t.Errorf("ListByIMSI(empty): got (%v, %v), want empty", got, err)
+	}
+}
+
+func TestSessionStoreDelete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vc := newTestValkeyClient(t, mr)
+	ss := NewSessionStore(vc)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	if err := ss.Create(ctx, "sess-del", &Session{IMSI: imsi}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := ss.AddUserIndex(ctx, imsi, "sess-del", 0); err != nil {
+		t.Fatalf("AddUserIndex failed: %v", err)
+	}
+
+	if err := ss.Delete(ctx, imsi, "sess-del"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if mr.Exists("sess:sess-del") {
+		t.Error("セッションが削除されていない")
+	}
+	if ok, _ := mr.SIsMember("idx:user:"+imsi, "sess-del"); ok {
+		t.Error("インデックスから削除されていない")
+	}
+}
+
 func TestGenerateSessionID(t *testing.T) {
 	id := GenerateSessionID()
 	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
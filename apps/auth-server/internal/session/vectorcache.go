@@ -0,0 +1,140 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+)
+
+// VectorCacheKeyLength はベクターキャッシュ暗号鍵のバイト長（AES-256-GCM）
+const VectorCacheKeyLength = 32
+
+// storeVectorsScript は使用済みSQNを更新し、それより新しいベクターのみをキャッシュするLuaスクリプト。
+// KEYS[1]: ベクターキャッシュ（Sorted Set、スコア=SQN）、KEYS[2]: 使用済みSQN
+// ARGV[1]: 使用したベクターのSQN、ARGV[2]: TTL（ミリ秒）、ARGV[3..]: SQNと暗号化ベクターの組
+// 使用済みSQN以下のベクターは以後使用できない（USIMが古いSQNとして拒否する）ため削除する。
+var storeVectorsScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[2]) or '-1')
+if tonumber(ARGV[1]) > used then
+	used = tonumber(ARGV[1])
+end
+redis.call('SET', KEYS[2], string.format('%.0f', used), 'PX', ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', used)
+for i = 3, #ARGV, 2 do
+	if tonumber(ARGV[i]) > used then
+		redis.call('ZADD', KEYS[1], ARGV[i], ARGV[i + 1])
+	end
+end
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// popVectorScript はSQNが最小のベクターを取り出し、使用済みSQNを更新するLuaスクリプト。
+// KEYS[1]: ベクターキャッシュ、KEYS[2]: 使用済みSQN、ARGV[1]: TTL（ミリ秒）
+// 戻り値: 暗号化ベクター（キャッシュが空の場合はnil）
+var popVectorScript = redis.NewScript(`
+local r = redis.call('ZPOPMIN', KEYS[1])
+if #r == 0 then
+	return false
+end
+redis.call('SET', KEYS[2], r[2], 'PX', ARGV[1])
+return r[1]
+`)
+
+// vectorCache はVectorCacheの実装。
+// ベクター（CK/IKを含む）はAES-256-GCMで暗号化し、IMSIを追加認証データとして保存する。
+type vectorCache struct {
+	vc   *store.ValkeyClient
+	aead cipher.AEAD
+	ttl  time.Duration
+}
+
+// NewVectorCache はVectorCacheの新しいインスタンスを生成する。
+// keyはVectorCacheKeyLengthバイトの暗号鍵、ttlはキャッシュしたベクターの有効期間。
+func NewVectorCache(vc *store.ValkeyClient, key []byte, ttl time.Duration) (VectorCache, error) {
+	if len(key) != VectorCacheKeyLength {
+		return nil, fmt.Errorf("vector cache key must be %d bytes, got %d", VectorCacheKeyLength, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("vector cache cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("vector cache cipher: %w", err)
+	}
+	return &vectorCache{vc: vc, aead: aead, ttl: ttl}, nil
+}
+
+// Pop はIMSIのキャッシュからSQNが最小のベクターを取り出す。キャッシュが空の場合はnilを返す。
+// 取り出したベクターは削除されるため、並行する認証で同じベクターが使用されることはない。
+func (c *vectorCache) Pop(ctx context.Context, imsi string) (*vector.Quintet, error) {
+	keys := []string{store.KeyPrefixVectorCache + imsi, store.KeyPrefixVectorSQN + imsi}
+	sealed, err := popVectorScript.Run(ctx, c.vc.Client(), keys, c.ttl.Milliseconds()).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, ErrVectorCacheInvalid
+	}
+	plain, err := c.aead.Open(nil, []byte(sealed[:nonceSize]), []byte(sealed[nonceSize:]), []byte(imsi))
+	if err != nil {
+		return nil, ErrVectorCacheInvalid
+	}
+	var q vector.Quintet
+	if err := json.Unmarshal(plain, &q); err != nil {
+		return nil, ErrVectorCacheInvalid
+	}
+	return &q, nil
+}
+
+// Store は使用したベクターのSQNを記録し、それより大きいSQNのベクターをキャッシュする。
+// 並行する先行取得の結果が遅れて保存されても、使用済みSQN以下のベクターはキャッシュに残らない。
+func (c *vectorCache) Store(ctx context.Context, imsi string, usedSQN uint64, vectors []vector.Quintet) error {
+	args := make([]any, 0, 2+len(vectors)*2)
+	args = append(args, strconv.FormatUint(usedSQN, 10), c.ttl.Milliseconds())
+	for i := range vectors {
+		plain, err := json.Marshal(&vectors[i])
+		if err != nil {
+			return fmt.Errorf("vector cache serialization error: %w", err)
+		}
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("vector cache nonce: %w", err)
+		}
+		sealed := c.aead.Seal(nonce, nonce, plain, []byte(imsi))
+		args = append(args, strconv.FormatUint(vectors[i].SQN, 10), string(sealed))
+	}
+
+	keys := []string{store.KeyPrefixVectorCache + imsi, store.KeyPrefixVectorSQN + imsi}
+	if err := storeVectorsScript.Run(ctx, c.vc.Client(), keys, args...).Err(); err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return nil
+}
+
+// Flush はIMSIのキャッシュと使用済みSQNを破棄する。
+func (c *vectorCache) Flush(ctx context.Context, imsi string) error {
+	if err := c.vc.Client().Del(ctx, store.KeyPrefixVectorCache+imsi, store.KeyPrefixVectorSQN+imsi).Err(); err != nil {
+		return fmt.Errorf("%w: %v", store.ErrValkeyUnavailable, err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+)
+
+var testVectorCacheKey = bytes.Repeat([]byte{0x42}, VectorCacheKeyLength)
+
+// testQuintet はテスト用のベクターを生成する（RANDの先頭バイトでベクターを識別する）
+func testQuintet(sqn uint64) vector.Quintet {
+	return vector.Quintet{
+		RAND: append([]byte{byte(sqn)}, make([]byte, 15)...),
+		AUTN: make([]byte, 16),
+		XRES: make([]byte, 8),
+		CK:   make([]byte, 16),
+		IK:   make([]byte, 16),
+		SQN:  sqn,
+	}
+}
+
+func newTestVectorCache(t *testing.T, mr *miniredis.Miniredis) VectorCache {
+	t.Helper()
+	vcache, err := NewVectorCache(newTestValkeyClient(t, mr), testVectorCacheKey, time.Minute)
+	if err != nil {
+		t.Fatalf("NewVectorCache failed: %v", err)
+	}
+	return vcache
+}
+
+func TestVectorCacheStoreAndPop(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vcache := newTestVectorCache(t, mr)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	if err := vcache.Store(ctx, imsi, 0x40, []vector.Quintet{testQuintet(0x80), testQuintet(0x60)}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if ttl := mr.TTL("vcache:" + imsi); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL: got %v, want (0, 1m]", ttl)
+	}
+
+	// ベクター（CK/IK）は平文で保存しない
+	members, _ := mr.ZMembers("vcache:" + imsi)
+	for _, m := range members {
+		if bytes.Contains([]byte(m), []byte(`"SQN"`)) {
+			t.Error("ベクターが平文で保存されている")
+		}
+	}
+
+	// SQN昇順に取り出される
+	for _, want := range []uint64{0x60, 0x80} {
+		q, err := vcache.Pop(ctx, imsi)
+		if err != nil {
+			t.Fatalf("Pop failed: %v", err)
+		}
+		if q == nil || q.SQN != want || q.RAND[0] != byte(want) {
+			t.Fatalf("Pop: got %+v, want SQN=%x", q, want)
+		}
+	}
+
+	q, err := vcache.Pop(ctx, imsi)
+	if err != nil || q != nil {
+		t.Errorf("空のキャッシュ: got (%v, %v), want (nil, nil)", q, err)
+	}
+}
+
+func TestVectorCacheStoreDiscardsUsedSQN(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vcache := newTestVectorCache(t, mr)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	// 後から取得したバッチ（0xa0を使用、0xc0をキャッシュ）が先に保存される
+	if err := vcache.Store(ctx, imsi, 0xa0, []vector.Quintet{testQuintet(0xc0)}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	// 先に取得したバッチ（0x40を使用）が遅れて保存されても、0xa0以下のベクターはキャッシュしない
+	if err := vcache.Store(ctx, imsi, 0x40, []vector.Quintet{testQuintet(0x60), testQuintet(0x80)}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	q, err := vcache.Pop(ctx, imsi)
+	if err != nil || q == nil || q.SQN != 0xc0 {
+		t.Fatalf("Pop: got (%+v, %v), want SQN=c0", q, err)
+	}
+	if q, _ := vcache.Pop(ctx, imsi); q != nil {
+		t.Errorf("使用済みSQN以下のベクターが残っている: %+v", q)
+	}
+}
+
+func TestVectorCacheFlush(t *testing.T) {
+	mr := miniredis.RunT(t)
+	vcache := newTestVectorCache(t, mr)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	if err := vcache.Store(ctx, imsi, 0x40, []vector.Quintet{testQuintet(0x60)}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if err := vcache.Flush(ctx, imsi); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if mr.Exists("vcache:"+imsi) || mr.Exists("vsqn:"+imsi) {
+		t.Error("Flush後にキーが残っている")
+	}
+
+	// 再同期後はSQNが小さくなり得るため、使用済みSQNも破棄されている
+	if err := vcache.Store(ctx, imsi, 0x20, []vector.Quintet{testQuintet(0x30)}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if q, _ := vcache.Pop(ctx, imsi); q == nil || q.SQN != 0x30 {
+		t.Errorf("Flush後のPop: got %+v, want SQN=30", q)
+	}
+}
+
+func TestVectorCachePopWrongKey(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	imsi := "440101234567890"
+
+	if err := newTestVectorCache(t, mr).Store(ctx, imsi, 0x40, []vector.Quintet{testQuintet(0x60)}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	other, err := NewVectorCache(newTestValkeyClient(t, mr), bytes.Repeat([]byte{0x43}, VectorCacheKeyLength), time.Minute)
+	if err != nil {
+		t.Fatalf("NewVectorCache failed: %v", err)
+	}
+	if _, err := other.Pop(ctx, imsi); !errors.Is(err, ErrVectorCacheInvalid) {
+		t.Errorf("Pop: got %v, want ErrVectorCacheInvalid", err)
+	}
+}
+
+func TestNewVectorCacheInvalidKey(t *testing.T) {
+	mr := miniredis.RunT(t)
+	if _, err := NewVectorCache(newTestValkeyClient(t, mr), make([]byte, 16), time.Minute); err == nil {
+		t.Error("NewVectorCache() error = nil, want error")
+	}
+}
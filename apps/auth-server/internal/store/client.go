@@ -13,6 +13,10 @@ type RadiusClient struct {
 	Secret string `redis:"secret"`
 	Name   string `redis:"name"`
 	Vendor string `redis:"vendor"`
+	// RequireAKAPrime がtrueの場合、このクライアント経由のEAP-AKA（AKA'以外）を拒否する
+	RequireAKAPrime bool `redis:"require_aka_prime"`
+	// NetworkName はEAP-AKA'のAT_KDF_INPUTで使用するネットワーク名（空の場合はSSID・Realm・既定値から決定）
+	NetworkName string `redis:"network_name"`
 }
 
 // clientStore はClientStoreインターフェースの実装。
@@ -38,3 +42,22 @@ func (s *clientStore) GetClientSecret(ctx context.Context, ip string) (string, e
 	}
 	return secret, nil
 }
+
+// GetClient は指定されたIPのRADIUSクライアント情報を取得する。
+// 未登録の場合はnilとnilを返す。
+func (s *clientStore) GetClient(ctx context.Context, ip string) (*RadiusClient, error) {
+	key := KeyPrefixClient + ip
+	m, err := s.vc.Client().HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValkeyUnavailable, err)
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	client := &RadiusClient{IP: ip}
+	if err := MapToStruct(m, client); err != nil {
+		return nil, fmt.Errorf("client %s: %w", ip, err)
+	}
+	return client, nil
+}
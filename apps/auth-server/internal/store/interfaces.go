@@ -7,4 +7,14 @@ type ClientStore interface {
 	// GetClientSecret は指定されたIPのShared Secretを取得する
 	// 未登録の場合は空文字列とnilを返す
 	GetClientSecret(ctx context.Context, ip string) (string, error)
+
+	// GetClient は指定されたIPのRADIUSクライアント情報を取得する
+	// 未登録の場合はnilとnilを返す
+	GetClient(ctx context.Context, ip string) (*RadiusClient, error)
+}
+
+// SubscriberStore は加入者の利用状態へのアクセスを定義する
+type SubscriberStore interface {
+	// GetSubscriberStatus は指定されたIMSIの利用状態と有効期間を取得する
+	GetSubscriberStatus(ctx context.Context, imsi string) (*SubscriberStatus, error)
 }
@@ -2,10 +2,31 @@ package store
 
 // Valkeyキープレフィックス（D-02準拠）
 const (
-	KeyPrefixSubscriber = "sub:"      // 加入者情報
-	KeyPrefixClient     = "client:"   // RADIUSクライアント設定
-	KeyPrefixPolicy     = "policy:"   // 認可ポリシー
-	KeyPrefixEAPContext = "eap:"      // EAP認証コンテキスト
-	KeyPrefixSession    = "sess:"     // アクティブセッション
-	KeyPrefixUserIndex  = "idx:user:" // ユーザー検索インデックス
+	KeyPrefixSubscriber   = "sub:"           // 加入者情報
+	KeyPrefixClient       = "client:"        // RADIUSクライアント設定
+	KeyPrefixPolicy       = "policy:"        // 認可ポリシー
+	KeyPrefixPrefixPolicy = "policy-prefix:" // PLMN・IMSIプレフィックス単位のポリシー
+	KeyPrefixProfile      = "profile:"       // 共有ポリシープロファイル
+	KeyPrefixEAPContext   = "eap:"           // EAP認証コンテキスト
+	KeyPrefixSession      = "sess:"          // アクティブセッション
+	KeyPrefixUserIndex    = "idx:user:"      // ユーザー検索インデックス
+	KeyPrefixPseudonym    = "pseudo:"        // 仮名→IMSIマッピング
+	KeyPrefixReauth       = "reauth:"        // 高速再認証コンテキスト
+	KeyPrefixERP          = "erp:"           // ERP鍵（rRK）
+	KeyPrefixVectorCache  = "vcache:"        // 先行取得した認証ベクター
+	KeyPrefixVectorSQN    = "vsqn:"          // 使用済みベクターのSQN
+	KeyPrefixIMSIFailure  = "authfail:imsi:" // IMSI単位の認証失敗回数
+	KeyPrefixIMSILockout  = "lockout:imsi:"  // IMSI単位のロックアウト
+	KeyPrefixNASFailure   = "authfail:nas:"  // NAS（送信元IP）単位のMessage-Authenticator検証失敗回数
+	KeyPrefixNASLockout   = "lockout:nas:"   // NAS（送信元IP）単位のロックアウト
+	KeyPrefixRateLimit    = "ratelimit:"     // 要求レート制限のトークンバケット
 )
+
+// KeyProfileRanges はIMSI範囲→プロファイルのインデックス（Sorted Set、score=範囲の開始IMSI、member="{開始}:{終了}:{プロファイル名}"）
+const KeyProfileRanges = "idx:profile:range"
+
+// KeyRateLimitStats はレート制限により破棄した要求数（Hash、field=対象種別）
+const KeyRateLimitStats = "stats:ratelimit"
+
+// KeyDefaultPolicy は全体の既定ポリシー（Hash、加入者ポリシーと同じフィールド）
+const KeyDefaultPolicy = "policy-default"
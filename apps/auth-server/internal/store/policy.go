@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/policy"
+	"github.com/redis/go-redis/v9"
 )
 
 // policyStore はpolicy.PolicyStoreインターフェースの実装。
@@ -19,18 +22,162 @@ func NewPolicyStore(vc *ValkeyClient) policy.PolicyStore {
 }
 
 // GetPolicy は指定されたIMSIに対応するポリシーを取得する。
-func (s *policyStore) GetPolicy(ctx context.Context, imsi string) (*policy.Policy, error) {
-	key := KeyPrefixPolicy + imsi
-	result, err := s.vc.Client().HGetAll(ctx, key).Result()
+// 以下の順にポリシーを解決し、最初に見つかった段階をPolicy.Sourceに設定する。
+//  1. 加入者ポリシー（policy:{IMSI}）
+//  2. IMSI範囲で参照するプロファイル（idx:profile:range）
+//  3. PLMN・IMSIプレフィックスのポリシー（policy-prefix:{prefix}、最長一致）
+//  4. NASクライアントの既定プロファイル（client:{IP}のpolicy_profile）
+//  5. 全体の既定ポリシー（policy-default）
+//
+// 各段階のポリシーがprofileフィールドでプロファイル（profile:{name}）を参照する場合は、
+// プロファイルを基にポリシーの設定を重ねて返す。
+func (s *policyStore) GetPolicy(ctx context.Context, imsi, clientIP string) (*policy.Policy, error) {
+	sub, err := s.getHash(ctx, KeyPrefixPolicy+imsi)
+	if err != nil {
+		return nil, err
+	}
+	if len(sub) > 0 {
+		return s.resolvePolicy(ctx, sub, policy.PolicySourceIMSI)
+	}
+
+	profileName, err := s.lookupRangeProfile(ctx, imsi)
+	if err != nil {
+		return nil, err
+	}
+	if profileName != "" {
+		return s.resolvePolicy(ctx, map[string]string{"profile": profileName}, policy.PolicySourceRange)
+	}
+
+	prefixPol, err := s.lookupPrefixPolicy(ctx, imsi)
+	if err != nil {
+		return nil, err
+	}
+	if len(prefixPol) > 0 {
+		return s.resolvePolicy(ctx, prefixPol, policy.PolicySourcePrefix)
+	}
+
+	if clientIP != "" {
+		profileName, err = s.vc.Client().HGet(ctx, KeyPrefixClient+clientIP, "policy_profile").Result()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("%w: %v", ErrValkeyUnavailable, err)
+		}
+		if profileName != "" {
+			return s.resolvePolicy(ctx, map[string]string{"profile": profileName}, policy.PolicySourceClient)
+		}
+	}
+
+	global, err := s.getHash(ctx, KeyDefaultPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if len(global) > 0 {
+		return s.resolvePolicy(ctx, global, policy.PolicySourceGlobal)
+	}
+
+	return nil, policy.ErrPolicyNotFound
+}
+
+// getHash はHashの全フィールドを取得する（キーが存在しない場合は空map）。
+func (s *policyStore) getHash(ctx context.Context, key string) (map[string]string, error) {
+	m, err := s.vc.Client().HGetAll(ctx, key).Result()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrValkeyUnavailable, err)
 	}
+	return m, nil
+}
+
+// resolvePolicy はポリシーのHashフィールドをポリシーに変換する。
+// profileフィールドがある場合はプロファイルを基にポリシーの設定を重ねる。
+func (s *policyStore) resolvePolicy(ctx context.Context, fields map[string]string, source string) (*policy.Policy, error) {
+	profileName := fields["profile"]
+	if profileName == "" {
+		p, err := parsePolicy(fields)
+		if err != nil {
+			return nil, err
+		}
+		p.Source = source
+		return p, nil
+	}
 
-	// キーが存在しない場合、HGetAllは空mapを返す
-	if len(result) == 0 {
-		return nil, policy.ErrPolicyNotFound
+	prof, err := s.getHash(ctx, KeyPrefixProfile+profileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(prof) == 0 {
+		return nil, fmt.Errorf("%w: profile %q not found", policy.ErrPolicyInvalid, profileName)
 	}
 
+	p, err := parsePolicy(prof)
+	if err != nil {
+		return nil, err
+	}
+	p.Profile = profileName
+	p.Source = source
+	if err := overlayPolicy(p, fields); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// lookupPrefixPolicy はIMSIに前方一致するプレフィックスのうち最長のもののポリシーを返す（該当なしの場合は空）。
+// 候補となるプレフィックス（IMSIの先頭1桁〜全桁-1）をパイプラインでまとめて取得する。
+func (s *policyStore) lookupPrefixPolicy(ctx context.Context, imsi string) (map[string]string, error) {
+	if len(imsi) < 2 {
+		return nil, nil
+	}
+
+	pipe := s.vc.Client().Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(imsi)-1)
+	for n := len(imsi) - 1; n >= 1; n-- {
+		cmds = append(cmds, pipe.HGetAll(ctx, KeyPrefixPrefixPolicy+imsi[:n]))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValkeyUnavailable, err)
+	}
+
+	// 長いプレフィックスから順に並んでいる
+	for _, cmd := range cmds {
+		if m := cmd.Val(); len(m) > 0 {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// lookupRangeProfile はIMSIを含むIMSI範囲のプロファイル名を返す（該当なしの場合は空）。
+// 開始IMSIがimsi以下で最大の範囲を取得し、終了IMSIと比較する（範囲の重複はAdmin TUIで禁止）。
+func (s *policyStore) lookupRangeProfile(ctx context.Context, imsi string) (string, error) {
+	n, err := strconv.ParseUint(imsi, 10, 64)
+	if err != nil {
+		return "", nil
+	}
+
+	members, err := s.vc.Client().ZRevRangeByScore(ctx, KeyProfileRanges, &redis.ZRangeBy{
+		Max:   strconv.FormatUint(n, 10),
+		Min:   "-inf",
+		Count: 1,
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrValkeyUnavailable, err)
+	}
+	if len(members) == 0 {
+		return "", nil
+	}
+
+	// member形式: "{開始}:{終了}:{プロファイル名}"
+	parts := strings.SplitN(members[0], ":", 3)
+	if len(parts) != 3 {
+		return "", nil
+	}
+	end, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || n > end {
+		return "", nil
+	}
+	return parts[2], nil
+}
+
+// parsePolicy はポリシーまたはプロファイルのHashフィールドをポリシーに変換する。
+func parsePolicy(result map[string]string) (*policy.Policy, error) {
 	p := &policy.Policy{}
 
 	// defaultフィールドの取得
@@ -41,17 +188,67 @@ func (s *policyStore) GetPolicy(ctx context.Context, imsi string) (*policy.Polic
 		p.Default = defaultVal
 	}
 
+	// require_aka_primeフィールドの取得（未設定・不正値は要求なし）
+	if v, ok := result["require_aka_prime"]; ok {
+		p.RequireAKAPrime, _ = strconv.ParseBool(v)
+	}
+
+	// max_sessionsフィールドの取得（未設定・不正値は全体設定に従う）
+	p.MaxSessions = parseMaxSessions(result["max_sessions"])
+
 	// rulesフィールドのJSONデシリアライズ
-	rulesJSON, ok := result["rules"]
-	if ok && rulesJSON != "" {
-		var rules []policy.PolicyRule
-		if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
-			return nil, fmt.Errorf("%w: rules JSON parse error: %v", policy.ErrPolicyInvalid, err)
-		}
-		p.Rules = rules
-	} else {
-		p.Rules = []policy.PolicyRule{}
+	rules, err := parseRules(result["rules"])
+	if err != nil {
+		return nil, err
 	}
+	p.Rules = rules
 
 	return p, nil
 }
+
+// overlayPolicy はプロファイルのポリシーに加入者ポリシー等の設定を重ねる。
+// defaultとmax_sessionsは重ねる側に有効な値がある場合に上書きし、
+// require_aka_primeはいずれかで要求されていれば要求する。
+// 重ねる側のルールはプロファイルのルールより前に置く（同じpriorityの場合は重ねる側を先に評価）。
+func overlayPolicy(p *policy.Policy, sub map[string]string) error {
+	if v := sub["default"]; v == "allow" || v == "deny" {
+		p.Default = v
+	}
+	if v, _ := strconv.ParseBool(sub["require_aka_prime"]); v {
+		p.RequireAKAPrime = true
+	}
+	if n := parseMaxSessions(sub["max_sessions"]); n > 0 {
+		p.MaxSessions = n
+	}
+
+	rules, err := parseRules(sub["rules"])
+	if err != nil {
+		return err
+	}
+	p.Rules = append(rules, p.Rules...)
+	return nil
+}
+
+// parseRules はrulesフィールドのJSONをデシリアライズする（未設定は空）。
+func parseRules(rulesJSON string) ([]policy.PolicyRule, error) {
+	if rulesJSON == "" {
+		return []policy.PolicyRule{}, nil
+	}
+	var rules []policy.PolicyRule
+	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+		return nil, fmt.Errorf("%w: rules JSON parse error: %v", policy.ErrPolicyInvalid, err)
+	}
+	if rules == nil {
+		rules = []policy.PolicyRule{}
+	}
+	return rules, nil
+}
+
+// parseMaxSessions はmax_sessionsフィールドを解析する（未設定・不正値・0以下は0）。
+func parseMaxSessions(v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
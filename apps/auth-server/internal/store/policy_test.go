@@ -35,7 +35,7 @@ func TestGetPolicy(t *testing.T) {
 	ps := NewPolicyStore(vc)
 	ctx := context.Background()
 
-	p, err := ps.GetPolicy(ctx, "001010123456789")
+	p, err := ps.GetPolicy(ctx, "001010123456789", "")
 	if err != nil {
 		t.Fatalf("GetPolicy failed: %v", err)
 	}
@@ -72,7 +72,7 @@ func TestGetPolicyNotFound(t *testing.T) {
 	ps := NewPolicyStore(vc)
 	ctx := context.Background()
 
-	_, err = ps.GetPolicy(ctx, "999999999999999")
+	_, err = ps.GetPolicy(ctx, "999999999999999", "")
 	if err == nil {
 		t.Fatal("expected error for missing policy, got nil")
 	}
@@ -97,7 +97,7 @@ func TestGetPolicyInvalidJSON(t *testing.T) {
 	ps := NewPolicyStore(vc)
 	ctx := context.Background()
 
-	_, err = ps.GetPolicy(ctx, "001010123456789")
+	_, err = ps.GetPolicy(ctx, "001010123456789", "")
 	if err == nil {
 		t.Fatal("expected error for invalid JSON, got nil")
 	}
@@ -124,7 +124,7 @@ func TestGetPolicyInvalidDefault(t *testing.T) {
 	ps := NewPolicyStore(vc)
 	ctx := context.Background()
 
-	p, err := ps.GetPolicy(ctx, "001010123456789")
+	p, err := ps.GetPolicy(ctx, "001010123456789", "")
 	if err != nil {
 		t.Fatalf("GetPolicy failed: %v", err)
 	}
@@ -150,7 +150,7 @@ func TestGetPolicyEmptyRules(t *testing.T) {
 	ps := NewPolicyStore(vc)
 	ctx := context.Background()
 
-	p, err := ps.GetPolicy(ctx, "001010123456789")
+	p, err := ps.GetPolicy(ctx, "001010123456789", "")
 	if err != nil {
 		t.Fatalf("GetPolicy failed: %v", err)
 	}
@@ -159,6 +159,77 @@ func TestGetPolicyEmptyRules(t *testing.T) {
 	}
 }
 
+func TestGetPolicyRequireAKAPrime(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("policy:001010123456789", "default", "allow")
+	mr.HSet("policy:001010123456789", "require_aka_prime", "true")
+	mr.HSet("policy:001010123456790", "default", "allow")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	ps := NewPolicyStore(vc)
+	ctx := context.Background()
+
+	p, err := ps.GetPolicy(ctx, "001010123456789", "")
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if !p.RequireAKAPrime {
+		t.Error("RequireAKAPrime = false, want true")
+	}
+
+	// フィールドなしは要求なし
+	p, err = ps.GetPolicy(ctx, "001010123456790", "")
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if p.RequireAKAPrime {
+		t.Error("RequireAKAPrime = true, want false")
+	}
+}
+
+func TestGetPolicyMaxSessions(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("policy:001010123456789", "default", "allow")
+	mr.HSet("policy:001010123456789", "max_sessions", "2")
+	mr.HSet("policy:001010123456790", "default", "allow")
+	mr.HSet("policy:001010123456790", "max_sessions", "-1")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	ps := NewPolicyStore(vc)
+	ctx := context.Background()
+
+	p, err := ps.GetPolicy(ctx, "001010123456789", "")
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if p.MaxSessions != 2 {
+		t.Errorf("MaxSessions = %d, want 2", p.MaxSessions)
+	}
+
+	// 不正値は全体設定に従う
+	p, err = ps.GetPolicy(ctx, "001010123456790", "")
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if p.MaxSessions != 0 {
+		t.Errorf("MaxSessions = %d, want 0", p.MaxSessions)
+	}
+}
+
 func TestGetPolicyValkeyError(t *testing.T) {
 	mr := miniredis.RunT(t)
 
@@ -174,7 +245,7 @@ func TestGetPolicyValkeyError(t *testing.T) {
 	ps := NewPolicyStore(vc)
 	ctx := context.Background()
 
-	_, err = ps.GetPolicy(ctx, "001010123456789")
+	_, err = ps.GetPolicy(ctx, "001010123456789", "")
 	if err == nil {
 		t.Fatal("expected error when Valkey is down, got nil")
 	}
@@ -182,3 +253,195 @@ func TestGetPolicyValkeyError(t *testing.T) {
 		t.Errorf("expected ErrValkeyUnavailable, got: %v", err)
 	}
 }
+
+func TestGetPolicyProfile(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("profile:staff", "default", "deny")
+	mr.HSet("profile:staff", "rules", `[{"nas_id":"*","allowed_ssids":["STAFF"],"vlan_id":"100"}]`)
+	mr.HSet("profile:staff", "max_sessions", "2")
+
+	// プロファイルのみ参照
+	mr.HSet("policy:001010123456789", "profile", "staff")
+	// 加入者ごとの設定で上書き
+	mr.HSet("policy:001010123456790", "profile", "staff")
+	mr.HSet("policy:001010123456790", "default", "allow")
+	mr.HSet("policy:001010123456790", "require_aka_prime", "true")
+	mr.HSet("policy:001010123456790", "max_sessions", "5")
+	mr.HSet("policy:001010123456790", "rules", `[{"nas_id":"*","allowed_ssids":["STAFF"],"vlan_id":"200"}]`)
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	ps := NewPolicyStore(vc)
+	ctx := context.Background()
+
+	p, err := ps.GetPolicy(ctx, "001010123456789", "")
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if p.Profile != "staff" || p.Default != "deny" || p.MaxSessions != 2 || p.RequireAKAPrime {
+		t.Errorf("unexpected policy: %+v", p)
+	}
+	if len(p.Rules) != 1 || p.Rules[0].VlanID != "100" {
+		t.Errorf("Rules = %+v, want profile rule", p.Rules)
+	}
+
+	p, err = ps.GetPolicy(ctx, "001010123456790", "")
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if p.Profile != "staff" || p.Default != "allow" || p.MaxSessions != 5 || !p.RequireAKAPrime {
+		t.Errorf("unexpected policy: %+v", p)
+	}
+	// 加入者ポリシーのルールがプロファイルのルールより先
+	if len(p.Rules) != 2 || p.Rules[0].VlanID != "200" || p.Rules[1].VlanID != "100" {
+		t.Errorf("Rules = %+v, want subscriber rule first", p.Rules)
+	}
+}
+
+func TestGetPolicyProfileNotFound(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("policy:001010123456789", "profile", "missing")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	ps := NewPolicyStore(vc)
+
+	_, err = ps.GetPolicy(context.Background(), "001010123456789", "")
+	if !errors.Is(err, policy.ErrPolicyInvalid) {
+		t.Errorf("expected ErrPolicyInvalid, got: %v", err)
+	}
+}
+
+func TestGetPolicyProfileRange(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("profile:iot", "default", "allow")
+	mr.HSet("profile:guest", "default", "deny")
+	mr.ZAdd("idx:profile:range", 1010123450000, "001010123450000:001010123459999:iot")
+	mr.ZAdd("idx:profile:range", 1010123470000, "001010123470000:001010123470099:guest")
+	// 加入者ポリシーがある場合は範囲より優先
+	mr.HSet("policy:001010123450001", "default", "deny")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	ps := NewPolicyStore(vc)
+	ctx := context.Background()
+
+	tests := []struct {
+		imsi        string
+		wantProfile string
+		wantDefault string
+		wantErr     error
+	}{
+		{"001010123450000", "iot", "allow", nil},
+		{"001010123459999", "iot", "allow", nil},
+		{"001010123470050", "guest", "deny", nil},
+		{"001010123450001", "", "deny", nil},
+		{"001010123460000", "", "", policy.ErrPolicyNotFound},
+		{"001010123440000", "", "", policy.ErrPolicyNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.imsi, func(t *testing.T) {
+			p, err := ps.GetPolicy(ctx, tt.imsi, "")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPolicy failed: %v", err)
+			}
+			if p.Profile != tt.wantProfile || p.Default != tt.wantDefault {
+				t.Errorf("Profile = %q, Default = %q, want %q, %q", p.Profile, p.Default, tt.wantProfile, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestGetPolicyFallback(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("profile:iot", "default", "allow")
+	mr.HSet("profile:visitor", "default", "allow")
+	mr.HSet("profile:visitor", "rules", `[{"nas_id":"*","allowed_ssids":["GUEST"]}]`)
+	mr.HSet("policy:001010123456789", "default", "deny")
+	mr.ZAdd("idx:profile:range", 1010123450000, "001010123450000:001010123459999:iot")
+	mr.HSet("policy-prefix:00101", "default", "allow")
+	mr.HSet("policy-prefix:00101", "max_sessions", "3")
+	mr.HSet("policy-prefix:0010199", "profile", "iot")
+	mr.HSet("policy-prefix:0010199", "max_sessions", "1")
+	mr.HSet("client:192.168.1.1", "secret", "s")
+	mr.HSet("client:192.168.1.1", "policy_profile", "visitor")
+	mr.HSet("client:192.168.1.2", "secret", "s")
+	mr.HSet("policy-default", "default", "deny")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	ps := NewPolicyStore(vc)
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		imsi            string
+		clientIP        string
+		wantSource      string
+		wantProfile     string
+		wantDefault     string
+		wantMaxSessions int
+	}{
+		{"加入者ポリシー", "001010123456789", "192.168.1.1", policy.PolicySourceIMSI, "", "deny", 0},
+		{"IMSI範囲", "001010123450001", "192.168.1.1", policy.PolicySourceRange, "iot", "allow", 0},
+		{"プレフィックス", "001010000000001", "192.168.1.1", policy.PolicySourcePrefix, "", "allow", 3},
+		{"最長一致のプレフィックス", "001019900000001", "192.168.1.1", policy.PolicySourcePrefix, "iot", "allow", 1},
+		{"NASクライアント", "440100000000001", "192.168.1.1", policy.PolicySourceClient, "visitor", "allow", 0},
+		{"プロファイル未指定のクライアント", "440100000000001", "192.168.1.2", policy.PolicySourceGlobal, "", "deny", 0},
+		{"未登録のクライアント", "440100000000001", "192.168.1.3", policy.PolicySourceGlobal, "", "deny", 0},
+		{"クライアント不明", "440100000000001", "", policy.PolicySourceGlobal, "", "deny", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ps.GetPolicy(ctx, tt.imsi, tt.clientIP)
+			if err != nil {
+				t.Fatalf("GetPolicy failed: %v", err)
+			}
+			if p.Source != tt.wantSource || p.Profile != tt.wantProfile || p.Default != tt.wantDefault || p.MaxSessions != tt.wantMaxSessions {
+				t.Errorf("unexpected policy: %+v", p)
+			}
+		})
+	}
+
+	// 全体の既定ポリシーがない場合は見つからない
+	mr.Del("policy-default")
+	if _, err := ps.GetPolicy(ctx, "440100000000001", "192.168.1.2"); !errors.Is(err, policy.ErrPolicyNotFound) {
+		t.Errorf("expected ErrPolicyNotFound, got: %v", err)
+	}
+
+	// NASクライアントの既定プロファイルが存在しない場合は不正
+	mr.HSet("client:192.168.1.2", "policy_profile", "missing")
+	if _, err := ps.GetPolicy(ctx, "440100000000001", "192.168.1.2"); !errors.Is(err, policy.ErrPolicyInvalid) {
+		t.Errorf("expected ErrPolicyInvalid, got: %v", err)
+	}
+}
@@ -139,3 +139,128 @@ func TestGetClientSecretValkeyError(t *testing.T) {
 		t.Error("error should not be redis.Nil")
 	}
 }
+
+func TestGetClient(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("client:192.168.1.1", "secret", "testing123")
+	mr.HSet("client:192.168.1.1", "name", "AP-01")
+	mr.HSet("client:192.168.1.1", "vendor", "TestVendor")
+	mr.HSet("client:192.168.1.1", "require_aka_prime", "true")
+	mr.HSet("client:192.168.1.1", "network_name", "5G:mnc001.mcc001.3gppnetwork.org")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	cs := NewClientStore(vc)
+	ctx := context.Background()
+
+	client, err := cs.GetClient(ctx, "192.168.1.1")
+	if err != nil {
+		t.Fatalf("GetClient failed: %v", err)
+	}
+	if client == nil {
+		t.Fatal("GetClient returned nil")
+	}
+	if client.IP != "192.168.1.1" {
+		t.Errorf("IP = %q, want %q", client.IP, "192.168.1.1")
+	}
+	if client.Name != "AP-01" {
+		t.Errorf("Name = %q, want %q", client.Name, "AP-01")
+	}
+	if !client.RequireAKAPrime {
+		t.Error("RequireAKAPrime = false, want true")
+	}
+	if client.NetworkName != "5G:mnc001.mcc001.3gppnetwork.org" {
+		t.Errorf("NetworkName = %q, want %q", client.NetworkName, "5G:mnc001.mcc001.3gppnetwork.org")
+	}
+}
+
+func TestGetClientNotFound(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	cs := NewClientStore(vc)
+	client, err := cs.GetClient(context.Background(), "10.0.0.99")
+	if err != nil {
+		t.Fatalf("GetClient returned error for missing key: %v", err)
+	}
+	if client != nil {
+		t.Errorf("GetClient = %+v, want nil", client)
+	}
+}
+
+func TestGetClientWithoutRequireAKAPrime(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	// 既存登録（require_aka_primeフィールドなし）は要求なしとして扱う
+	mr.HSet("client:192.168.1.1", "secret", "testing123")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	cs := NewClientStore(vc)
+	client, err := cs.GetClient(context.Background(), "192.168.1.1")
+	if err != nil {
+		t.Fatalf("GetClient failed: %v", err)
+	}
+	if client == nil || client.RequireAKAPrime {
+		t.Errorf("GetClient = %+v, want RequireAKAPrime=false", client)
+	}
+}
+
+func TestGetSubscriberStatus(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	mr.HSet("sub:001010000000001", "ki", "00112233445566778899aabbccddeeff")
+	mr.HSet("sub:001010000000001", "status", "barred")
+	mr.HSet("sub:001010000000001", "valid_until", "2026-01-01T00:00:00Z")
+	mr.HSet("sub:001010000000002", "ki", "00112233445566778899aabbccddeeff")
+
+	cfg := newTestConfig(mr.Addr())
+	vc, err := NewValkeyClient(cfg)
+	if err != nil {
+		t.Fatalf("NewValkeyClient failed: %v", err)
+	}
+	defer vc.Close()
+
+	ss := NewSubscriberStore(vc)
+	ctx := context.Background()
+
+	st, err := ss.GetSubscriberStatus(ctx, "001010000000001")
+	if err != nil {
+		t.Fatalf("GetSubscriberStatus failed: %v", err)
+	}
+	want := SubscriberStatus{Status: "barred", ValidUntil: "2026-01-01T00:00:00Z"}
+	if *st != want {
+		t.Errorf("GetSubscriberStatus = %+v, want %+v", *st, want)
+	}
+
+	// 利用状態・有効期間が未設定の加入者は空（制限なし）
+	st, err = ss.GetSubscriberStatus(ctx, "001010000000002")
+	if err != nil {
+		t.Fatalf("GetSubscriberStatus failed: %v", err)
+	}
+	if *st != (SubscriberStatus{}) {
+		t.Errorf("GetSubscriberStatus = %+v, want empty", *st)
+	}
+
+	mr.Close()
+	if _, err := ss.GetSubscriberStatus(ctx, "001010000000001"); !errors.Is(err, ErrValkeyUnavailable) {
+		t.Errorf("expected ErrValkeyUnavailable, got: %v", err)
+	}
+}
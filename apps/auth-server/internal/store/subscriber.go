@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// SubscriberStatus は加入者の利用状態と有効期間を表す（D-02 sub:{IMSI}）。
+// 認証情報（Ki/OPc）は読み込まない。
+type SubscriberStatus struct {
+	Status     string `redis:"status"`      // active / suspended / barred（未設定はactive）
+	ValidFrom  string `redis:"valid_from"`  // 利用開始日時（RFC3339、空は制限なし）
+	ValidUntil string `redis:"valid_until"` // 利用終了日時（RFC3339、空は制限なし）
+}
+
+// subscriberStore はSubscriberStoreインターフェースの実装。
+type subscriberStore struct {
+	vc *ValkeyClient
+}
+
+// NewSubscriberStore は新しいSubscriberStoreを生成する。
+func NewSubscriberStore(vc *ValkeyClient) SubscriberStore {
+	return &subscriberStore{vc: vc}
+}
+
+// GetSubscriberStatus は指定されたIMSIの利用状態と有効期間を取得する。
+// フィールドが未設定の場合は空文字列（制限なし）として返す。
+func (s *subscriberStore) GetSubscriberStatus(ctx context.Context, imsi string) (*SubscriberStatus, error) {
+	key := KeyPrefixSubscriber + imsi
+	var st SubscriberStatus
+	if err := s.vc.Client().HMGet(ctx, key, "status", "valid_from", "valid_until").Scan(&st); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValkeyUnavailable, err)
+	}
+	return &st, nil
+}
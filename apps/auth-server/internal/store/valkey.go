@@ -25,6 +25,8 @@ func NewValkeyClient(cfg *config.Config) (*ValkeyClient, error) {
 		WriteTimeout: config.ValkeyCommandTimeout,
 		PoolSize:     config.ValkeyPoolSize,
 		MinIdleConns: 2,
+		// Access-Requestの処理期限をコマンドのタイムアウトにも反映する
+		ContextTimeoutEnabled: true,
 	})
 
 	// 接続確認
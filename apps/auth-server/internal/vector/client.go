@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/httputil"
 	"github.com/sony/gobreaker"
 )
 
@@ -74,17 +77,35 @@ func (c *Client) GetVector(ctx context.Context, req *VectorRequest) (*VectorResp
 		return nil, ErrTraceIDMissing
 	}
 
+	// 処理期限切れの場合はGatewayへ問い合わせない
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDeadlineExceeded, err)
+	}
+	// 残り時間が1ms未満の場合も、ヘッダで期限を伝搬できないため問い合わせない
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < time.Millisecond {
+		return nil, fmt.Errorf("%w: %w", ErrDeadlineExceeded, context.DeadlineExceeded)
+	}
+
 	start := time.Now()
 
 	result, err := c.cb.Execute(func() (any, error) {
-		resp, err := c.httpClient.R().
+		r := c.httpClient.R().
 			SetContext(ctx).
 			SetHeader(HeaderTraceID, traceID).
 			SetHeader(HeaderContentType, ContentTypeJSON).
-			SetBody(req).
-			Post(c.baseURL + "/api/v1/vector")
+			SetBody(req)
+
+		// 処理期限の残り時間をGatewayへ伝搬（0は期限なしと解釈されるため1ms以上とする）
+		if deadline, ok := ctx.Deadline(); ok {
+			r.SetHeader(httputil.HeaderRequestTimeout, strconv.FormatInt(max(time.Until(deadline).Milliseconds(), 1), 10))
+		}
 
+		resp, err := r.Post(c.baseURL + "/api/v1/vector")
 		if err != nil {
+			// 処理期限切れはGatewayの障害ではないためCBカウントに含めない
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("%w: %w", ErrDeadlineExceeded, ctxErr), nil
+			}
 			return nil, &ConnectionError{Cause: err}
 		}
 
@@ -133,9 +154,9 @@ func (c *Client) GetVector(ctx context.Context, req *VectorRequest) (*VectorResp
 		return nil, err
 	}
 
-	// CB対象外のAPIErrorの場合
-	if apiErr, ok := result.(*APIError); ok {
-		return nil, apiErr
+	// CB対象外のAPIErrorまたは処理期限切れの場合
+	if resultErr, ok := result.(error); ok {
+		return nil, resultErr
 	}
 
 	// 正常レスポンスのパース
@@ -154,34 +175,82 @@ func (c *Client) parseResponse(body []byte) (*VectorResponse, error) {
 		return nil, fmt.Errorf("%w: json unmarshal: %v", ErrInvalidResponse, err)
 	}
 
+	// 複数件要求時はVectorsのみが返る（先頭を使用し、残りは先行取得分とする）
+	first := quintetJSON{RAND: raw.RAND, AUTN: raw.AUTN, XRES: raw.XRES, CK: raw.CK, IK: raw.IK, SQN: raw.SQN}
+	if len(raw.Vectors) > 0 {
+		first = raw.Vectors[0]
+	}
+	q, err := decodeQuintet(first)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefetched []Quintet
+	for i := 1; i < len(raw.Vectors); i++ {
+		p, err := decodeQuintet(raw.Vectors[i])
+		if err != nil {
+			return nil, fmt.Errorf("vectors[%d]: %w", i, err)
+		}
+		if p.SQN <= q.SQN || (len(prefetched) > 0 && p.SQN <= prefetched[len(prefetched)-1].SQN) {
+			return nil, fmt.Errorf("%w: vectors[%d] sqn not ascending", ErrInvalidResponse, i)
+		}
+		prefetched = append(prefetched, p)
+	}
+
+	triplets := make([]Triplet, 0, len(raw.Triplets))
+	for i, t := range raw.Triplets {
+		tRand, err1 := hex.DecodeString(t.RAND)
+		tSRES, err2 := hex.DecodeString(t.SRES)
+		tKc, err3 := hex.DecodeString(t.Kc)
+		if err := errors.Join(err1, err2, err3); err != nil {
+			return nil, fmt.Errorf("%w: triplet[%d] hex decode: %v", ErrInvalidResponse, i, err)
+		}
+		triplets = append(triplets, Triplet{RAND: tRand, SRES: tSRES, Kc: tKc})
+	}
+
+	return &VectorResponse{
+		RAND:       q.RAND,
+		AUTN:       q.AUTN,
+		XRES:       q.XRES,
+		CK:         q.CK,
+		IK:         q.IK,
+		SQN:        q.SQN,
+		Prefetched: prefetched,
+		Triplets:   triplets,
+	}, nil
+}
+
+// decodeQuintet はHex文字列のクインテットをバイト列に変換する。
+// SQNはEAP-SIM（トリプレット）応答では省略されるため、空の場合は0とする。
+func decodeQuintet(raw quintetJSON) (Quintet, error) {
 	randBytes, err := hex.DecodeString(raw.RAND)
 	if err != nil {
-		return nil, fmt.Errorf("%w: rand hex decode: %v", ErrInvalidResponse, err)
+		return Quintet{}, fmt.Errorf("%w: rand hex decode: %v", ErrInvalidResponse, err)
 	}
 	autnBytes, err := hex.DecodeString(raw.AUTN)
 	if err != nil {
-		return nil, fmt.Errorf("%w: autn hex decode: %v", ErrInvalidResponse, err)
+		return Quintet{}, fmt.Errorf("%w: autn hex decode: %v", ErrInvalidResponse, err)
 	}
 	xresBytes, err := hex.DecodeString(raw.XRES)
 	if err != nil {
-		return nil, fmt.Errorf("%w: xres hex decode: %v", ErrInvalidResponse, err)
+		return Quintet{}, fmt.Errorf("%w: xres hex decode: %v", ErrInvalidResponse, err)
 	}
 	ckBytes, err := hex.DecodeString(raw.CK)
 	if err != nil {
-		return nil, fmt.Errorf("%w: ck hex decode: %v", ErrInvalidResponse, err)
+		return Quintet{}, fmt.Errorf("%w: ck hex decode: %v", ErrInvalidResponse, err)
 	}
 	ikBytes, err := hex.DecodeString(raw.IK)
 	if err != nil {
-		return nil, fmt.Errorf("%w: ik hex decode: %v", ErrInvalidResponse, err)
+		return Quintet{}, fmt.Errorf("%w: ik hex decode: %v", ErrInvalidResponse, err)
 	}
-
-	return &VectorResponse{
-		RAND: randBytes,
-		AUTN: autnBytes,
-		XRES: xresBytes,
-		CK:   ckBytes,
-		IK:   ikBytes,
-	}, nil
+	var sqn uint64
+	if raw.SQN != "" {
+		sqn, err = strconv.ParseUint(raw.SQN, 16, 48)
+		if err != nil {
+			return Quintet{}, fmt.Errorf("%w: sqn parse: %v", ErrInvalidResponse, err)
+		}
+	}
+	return Quintet{RAND: randBytes, AUTN: autnBytes, XRES: xresBytes, CK: ckBytes, IK: ikBytes, SQN: sqn}, nil
 }
 
 // parseAPIError はHTTPエラーレスポンスをAPIErrorに変換する。
@@ -7,9 +7,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/httputil"
 )
 
 // テスト用の認証ベクター（固定値）
@@ -125,6 +128,186 @@ func TestGetVectorWithResync(t *testing.T) {
 	}
 }
 
+func TestGetVectorTriplet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req VectorRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Mode != ModeTriplet || req.Count != 2 {
+			t.Errorf("expected mode=triplet count=2, got %q %d", req.Mode, req.Count)
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		json.NewEncoder(w).Encode(vectorResponseJSON{
+			Triplets: []tripletJSON{
+				{RAND: "23553cbe9637a89d218ae64dae47bf35", SRES: "46f8416a", Kc: "eae4be823af9a08b"},
+				{RAND: "f4b38a1c2d3e4f5a6b7c8d9e0f1a2b3c", SRES: "11223344", Kc: "0011223344556677"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	resp, err := client.GetVector(ctxWithTrace(), &VectorRequest{IMSI: "440101234567890", Mode: ModeTriplet, Count: 2})
+	if err != nil {
+		t.Fatalf("GetVector triplet failed: %v", err)
+	}
+	if len(resp.Triplets) != 2 {
+		t.Fatalf("Triplets length = %d, want 2", len(resp.Triplets))
+	}
+	if hex.EncodeToString(resp.Triplets[0].SRES) != "46f8416a" {
+		t.Errorf("SRES = %x, want 46f8416a", resp.Triplets[0].SRES)
+	}
+	if hex.EncodeToString(resp.Triplets[0].Kc) != "eae4be823af9a08b" {
+		t.Errorf("Kc = %x, want eae4be823af9a08b", resp.Triplets[0].Kc)
+	}
+}
+
+func TestGetVectorTripletInvalidHex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		json.NewEncoder(w).Encode(vectorResponseJSON{
+			Triplets: []tripletJSON{{RAND: "zz", SRES: "46f8416a", Kc: "eae4be823af9a08b"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	_, err := client.GetVector(ctxWithTrace(), &VectorRequest{IMSI: "440101234567890", Mode: ModeTriplet})
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("expected ErrInvalidResponse, got %v", err)
+	}
+}
+
+func TestGetVectorBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req VectorRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Count != 3 {
+			t.Errorf("expected count=3, got %d", req.Count)
+		}
+
+		vectors := make([]quintetJSON, 0, 3)
+		for _, sqn := range []string{"000000000040", "000000000060", "000000000080"} {
+			vectors = append(vectors, quintetJSON{
+				RAND: testVector.RAND, AUTN: testVector.AUTN, XRES: testVector.XRES,
+				CK: testVector.CK, IK: testVector.IK, SQN: sqn,
+			})
+		}
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		json.NewEncoder(w).Encode(vectorResponseJSON{Vectors: vectors})
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	resp, err := client.GetVector(ctxWithTrace(), &VectorRequest{IMSI: "440101234567890", Count: 3})
+	if err != nil {
+		t.Fatalf("GetVector batch failed: %v", err)
+	}
+	if hex.EncodeToString(resp.RAND) != testVector.RAND || resp.SQN != 0x40 {
+		t.Errorf("先頭ベクターが不正: RAND=%x SQN=%x", resp.RAND, resp.SQN)
+	}
+	if len(resp.Prefetched) != 2 || resp.Prefetched[0].SQN != 0x60 || resp.Prefetched[1].SQN != 0x80 {
+		t.Errorf("先行取得ベクターが不正: %+v", resp.Prefetched)
+	}
+}
+
+func TestGetVectorBatchSQNNotAscending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		json.NewEncoder(w).Encode(vectorResponseJSON{Vectors: []quintetJSON{
+			{RAND: testVector.RAND, AUTN: testVector.AUTN, XRES: testVector.XRES, CK: testVector.CK, IK: testVector.IK, SQN: "000000000060"},
+			{RAND: testVector.RAND, AUTN: testVector.AUTN, XRES: testVector.XRES, CK: testVector.CK, IK: testVector.IK, SQN: "000000000040"},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	_, err := client.GetVector(ctxWithTrace(), &VectorRequest{IMSI: "440101234567890", Count: 2})
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("expected ErrInvalidResponse, got %v", err)
+	}
+}
+
+func TestGetVectorForbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(ProblemDetails{
+			Type:   "about:blank",
+			Title:  "Forbidden",
+			Detail: "EAP-SIM is not enabled for this subscriber",
+			Status: 403,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	_, err := client.GetVector(ctxWithTrace(), &VectorRequest{IMSI: "440101234567890", Mode: ModeTriplet})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T: %v", err, err)
+	}
+	if !apiErr.IsForbidden() {
+		t.Errorf("expected IsForbidden() = true (status=%d)", apiErr.StatusCode)
+	}
+}
+
+func TestGetVectorSubscriberStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		problemType string
+		barred      bool
+		suspended   bool
+		outside     bool
+	}{
+		{"barred", httputil.ProblemTypeSubscriberBarred, true, false, false},
+		{"suspended", httputil.ProblemTypeSubscriberSuspended, false, true, false},
+		{"outside validity", httputil.ProblemTypeSubscriberInvalid, false, false, true},
+		{"other forbidden", "about:blank", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", ContentTypeJSON)
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(ProblemDetails{
+					Type:   tt.problemType,
+					Title:  "Forbidden",
+					Detail: "subscriber is not available",
+					Status: 403,
+				})
+			}))
+			defer server.Close()
+
+			client := NewClient(newTestConfig(server.URL))
+			_, err := client.GetVector(ctxWithTrace(), &VectorRequest{IMSI: "440101234567890"})
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected APIError, got %T: %v", err, err)
+			}
+			if !apiErr.IsForbidden() {
+				t.Errorf("expected IsForbidden() = true (status=%d)", apiErr.StatusCode)
+			}
+			if got := apiErr.IsSubscriberBarred(); got != tt.barred {
+				t.Errorf("IsSubscriberBarred() = %v, want %v", got, tt.barred)
+			}
+			if got := apiErr.IsSubscriberSuspended(); got != tt.suspended {
+				t.Errorf("IsSubscriberSuspended() = %v, want %v", got, tt.suspended)
+			}
+			if got := apiErr.IsSubscriberOutsideValidity(); got != tt.outside {
+				t.Errorf("IsSubscriberOutsideValidity() = %v, want %v", got, tt.outside)
+			}
+		})
+	}
+}
+
 func TestGetVectorNotFound(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", ContentTypeJSON)
@@ -323,6 +506,109 @@ func TestGetVector501NotCountedByCB(t *testing.T) {
 	}
 }
 
+func TestGetVectorPropagatesRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms, err := strconv.ParseInt(r.Header.Get(httputil.HeaderRequestTimeout), 10, 64)
+		if err != nil || ms <= 0 || ms > 2000 {
+			t.Errorf("%s = %q, want remaining budget in (0, 2000]", httputil.HeaderRequestTimeout, r.Header.Get(httputil.HeaderRequestTimeout))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(testVector)
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	ctx, cancel := context.WithTimeout(ctxWithTrace(), 2*time.Second)
+	defer cancel()
+
+	if _, err := client.GetVector(ctx, &VectorRequest{IMSI: "440101234567890"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetVectorWithoutDeadlineOmitsRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := r.Header.Get(httputil.HeaderRequestTimeout); v != "" {
+			t.Errorf("%s = %q, want empty", httputil.HeaderRequestTimeout, v)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(testVector)
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	if _, err := client.GetVector(ctxWithTrace(), &VectorRequest{IMSI: "440101234567890"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetVectorDeadlineExceededNotCountedByCB(t *testing.T) {
+	// 処理期限切れはCB対象外であることを確認
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(newTestConfig(server.URL))
+
+	for i := 0; i < config.CBFailureThreshold+1; i++ {
+		ctx, cancel := context.WithTimeout(ctxWithTrace(), 20*time.Millisecond)
+		_, err := client.GetVector(ctx, &VectorRequest{IMSI: "440101234567890"})
+		cancel()
+		if !errors.Is(err, ErrDeadlineExceeded) {
+			t.Fatalf("iteration %d: expected ErrDeadlineExceeded, got: %v", i, err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("iteration %d: expected context.DeadlineExceeded in chain, got: %v", i, err)
+		}
+	}
+}
+
+func TestGetVectorDeadlineAlreadyExceeded(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	ctx, cancel := context.WithCancel(ctxWithTrace())
+	cancel()
+
+	_, err := client.GetVector(ctx, &VectorRequest{IMSI: "440101234567890"})
+	if !errors.Is(err, ErrDeadlineExceeded) {
+		t.Fatalf("expected ErrDeadlineExceeded, got: %v", err)
+	}
+	if called {
+		t.Error("期限切れのリクエストをGatewayへ送信した")
+	}
+}
+
+func TestGetVectorDeadlineUnderOneMillisecond(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestConfig(server.URL))
+	ctx, cancel := context.WithTimeout(ctxWithTrace(), 500*time.Microsecond)
+	defer cancel()
+
+	_, err := client.GetVector(ctx, &VectorRequest{IMSI: "440101234567890"})
+	if !errors.Is(err, ErrDeadlineExceeded) {
+		t.Fatalf("expected ErrDeadlineExceeded, got: %v", err)
+	}
+	if called {
+		t.Error("残り1ms未満のリクエストをGatewayへ送信した")
+	}
+}
+
 func TestWithTraceID(t *testing.T) {
 	ctx := WithTraceID(context.Background(), "abc-123")
 	val, ok := ctx.Value(traceIDKey{}).(string)
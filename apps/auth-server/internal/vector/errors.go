@@ -3,6 +3,8 @@ package vector
 import (
 	"errors"
 	"fmt"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/httputil"
 )
 
 // センチネルエラー
@@ -15,6 +17,9 @@ var (
 
 	// ErrTraceIDMissing はコンテキストにTrace IDが設定されていない場合のエラー
 	ErrTraceIDMissing = errors.New("trace id missing in context")
+
+	// ErrDeadlineExceeded はAccess-Requestの処理期限までにVector Gatewayの応答を得られなかった場合のエラー
+	ErrDeadlineExceeded = errors.New("request deadline exceeded")
 )
 
 // APIError はHTTP APIエラーを表す
@@ -41,6 +46,30 @@ func (e *APIError) IsBadRequest() bool {
 	return e.StatusCode == 400
 }
 
+// IsForbidden は利用不可エラー（EAP-SIM未許可等）かどうかを判定する
+func (e *APIError) IsForbidden() bool {
+	return e.StatusCode == 403
+}
+
+// IsSubscriberBarred は加入者が利用停止（barred）のため拒否されたかどうかを判定する
+func (e *APIError) IsSubscriberBarred() bool {
+	return e.hasProblemType(httputil.ProblemTypeSubscriberBarred)
+}
+
+// IsSubscriberSuspended は加入者が一時停止（suspended）のため拒否されたかどうかを判定する
+func (e *APIError) IsSubscriberSuspended() bool {
+	return e.hasProblemType(httputil.ProblemTypeSubscriberSuspended)
+}
+
+// IsSubscriberOutsideValidity は加入者の有効期間外のため拒否されたかどうかを判定する
+func (e *APIError) IsSubscriberOutsideValidity() bool {
+	return e.hasProblemType(httputil.ProblemTypeSubscriberInvalid)
+}
+
+func (e *APIError) hasProblemType(problemType string) bool {
+	return e.Details != nil && e.Details.Type == problemType
+}
+
 // IsServerError はサーバーエラーかどうかを判定する
 func (e *APIError) IsServerError() bool {
 	return e.StatusCode >= 500
@@ -4,8 +4,13 @@ package vector
 type VectorRequest struct {
 	IMSI       string      `json:"imsi"`
 	ResyncInfo *ResyncInfo `json:"resync_info,omitempty"`
+	Mode       string      `json:"mode,omitempty"`  // ModeTripletの場合はGSMトリプレットを要求
+	Count      int         `json:"count,omitempty"` // トリプレット数（2〜3）、またはクインテット数（先行取得時、1〜8）
 }
 
+// ModeTriplet はEAP-SIM用GSMトリプレット要求を表す
+const ModeTriplet = "triplet"
+
 // ResyncInfo は再同期情報を表す
 type ResyncInfo struct {
 	RAND string `json:"rand"` // Hex文字列
@@ -19,6 +24,27 @@ type VectorResponse struct {
 	XRES []byte // 4-16バイト
 	CK   []byte // 16バイト
 	IK   []byte // 16バイト
+	SQN  uint64 // ベクター生成時のSQN（ベクターキャッシュの順序管理に使用）
+
+	Prefetched []Quintet // Count>1時、先頭以外のベクター（SQN昇順）
+	Triplets   []Triplet // ModeTriplet時のみ
+}
+
+// Quintet はAKA認証ベクター（クインテット）を表す
+type Quintet struct {
+	RAND []byte
+	AUTN []byte
+	XRES []byte
+	CK   []byte
+	IK   []byte
+	SQN  uint64
+}
+
+// Triplet はGSM認証トリプレットを表す
+type Triplet struct {
+	RAND []byte // 16バイト
+	SRES []byte // 4バイト
+	Kc   []byte // 8バイト
 }
 
 // vectorResponseJSON はJSONパース用の内部構造体
@@ -28,6 +54,27 @@ type vectorResponseJSON struct {
 	XRES string `json:"xres"`
 	CK   string `json:"ck"`
 	IK   string `json:"ik"`
+	SQN  string `json:"sqn"`
+
+	Vectors  []quintetJSON `json:"vectors"`
+	Triplets []tripletJSON `json:"triplets"`
+}
+
+// quintetJSON はクインテットのJSONパース用の内部構造体
+type quintetJSON struct {
+	RAND string `json:"rand"`
+	AUTN string `json:"autn"`
+	XRES string `json:"xres"`
+	CK   string `json:"ck"`
+	IK   string `json:"ik"`
+	SQN  string `json:"sqn"`
+}
+
+// tripletJSON はトリプレットのJSONパース用の内部構造体
+type tripletJSON struct {
+	RAND string `json:"rand"`
+	SRES string `json:"sres"`
+	Kc   string `json:"kc"`
 }
 
 // ProblemDetails はRFC 7807エラーレスポンスを表す
@@ -3,10 +3,12 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/config"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/engine"
@@ -15,6 +17,7 @@ import (
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/session"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/store"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/auth-server/internal/vector"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/suci"
 )
 
 func main() {
@@ -56,25 +59,92 @@ func main() {
 	// 5. Store/Session層生成
 	clientStore := store.NewClientStore(valkeyClient)
 	policyStore := store.NewPolicyStore(valkeyClient)
+	subscriberStore := store.NewSubscriberStore(valkeyClient)
 	ctxStore := session.NewContextStore(valkeyClient)
 	sessStore := session.NewSessionStore(valkeyClient)
+	pseudoStore := session.NewPseudonymStore(valkeyClient)
+	reauthStore := session.NewReauthStore(valkeyClient)
+	erpStore := session.NewERPStore(valkeyClient)
+
+	// 6. ポリシー評価器（曜日・時間帯条件はPOLICY_TIMEZONEで判定）
+	policyLoc, _ := time.LoadLocation(cfg.PolicyTimezone) // 設定読み込み時に検証済み
+	evaluator := policy.NewEvaluator(policyLoc)
+
+	// 7. SUCIホームネットワーク鍵（未設定の場合はECIES方式の秘匿化IDを拒否）
+	var keyring *suci.Keyring
+	if cfg.SUCIKeyFile != "" {
+		keyring, err = suci.LoadKeyring(cfg.SUCIKeyFile)
+		if err != nil {
+			slog.Error("SUCI鍵ファイル読み込み失敗",
+				"path", cfg.SUCIKeyFile,
+				"error", err,
+			)
+			os.Exit(1)
+		}
+		slog.Info("SUCI鍵読み込み完了", "key_ids", keyring.KeyIDs())
+	}
+
+	// 8. 認証ベクターキャッシュ（VECTOR_PREFETCH_COUNTが1以下の場合は先行取得しない）
+	var vectorCache session.VectorCache
+	if cfg.VectorPrefetchCount > 1 {
+		key, _ := hex.DecodeString(cfg.VectorCacheKey) // 形式は設定読み込み時に検証済み
+		vectorCache, err = session.NewVectorCache(valkeyClient, key, cfg.VectorCacheTTL)
+		if err != nil {
+			slog.Error("ベクターキャッシュ初期化失敗", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("認証ベクター先行取得有効",
+			"prefetch_count", cfg.VectorPrefetchCount,
+			"cache_ttl", cfg.VectorCacheTTL,
+		)
+	}
 
-	// 6. ポリシー評価器
-	evaluator := policy.NewEvaluator()
+	// 9. 認証失敗によるロックアウト（閾値が0の場合はロックアウトしない）
+	var imsiLockout, nasLockout session.LockoutStore
+	if cfg.AuthLockoutThreshold > 0 {
+		imsiLockout = session.NewIMSILockoutStore(valkeyClient, cfg.AuthLockoutThreshold, cfg.AuthLockoutWindow, cfg.AuthLockoutDuration)
+	}
+	if cfg.NASLockoutThreshold > 0 {
+		nasLockout = session.NewNASLockoutStore(valkeyClient, cfg.NASLockoutThreshold, cfg.NASLockoutWindow, cfg.NASLockoutDuration)
+	}
+
+	// 10. 要求レート制限（RATEが0の対象種別は制限しない。全種別が0の場合は無効）
+	var rateLimiter session.RateLimiter
+	if cfg.RateLimitClientRate > 0 || cfg.RateLimitStationRate > 0 || cfg.RateLimitIMSIRate > 0 {
+		rateLimiter = session.NewRateLimiter(valkeyClient, map[session.RateLimitKind]session.RateLimit{
+			session.RateLimitClient:  {Rate: cfg.RateLimitClientRate, Burst: cfg.RateLimitClientBurst},
+			session.RateLimitStation: {Rate: cfg.RateLimitStationRate, Burst: cfg.RateLimitStationBurst},
+			session.RateLimitIMSI:    {Rate: cfg.RateLimitIMSIRate, Burst: cfg.RateLimitIMSIBurst},
+		})
+	}
 
-	// 7. EAPエンジン
-	eapEngine := engine.NewEngine(vectorClient, ctxStore, sessStore, policyStore, evaluator, cfg)
+	// 11. EAPエンジン
+	eapEngine := engine.NewEngine(vectorClient, ctxStore, sessStore, policyStore, evaluator, cfg,
+		engine.WithPseudonymStore(pseudoStore),
+		engine.WithReauthStore(reauthStore),
+		engine.WithERPStore(erpStore),
+		engine.WithVectorCache(vectorCache),
+		engine.WithLockoutStore(imsiLockout),
+		engine.WithRateLimiter(rateLimiter),
+		engine.WithClientStore(clientStore),
+		engine.WithSubscriberStore(subscriberStore),
+		engine.WithKeyring(keyring),
+	)
 
-	// 8. RADIUS Secret解決
+	// 12. RADIUS Secret解決
 	secretSource := server.NewSecretSource(clientStore, cfg.RadiusSecret)
 
-	// 9. RADIUSハンドラ
-	handler := server.NewHandler(eapEngine)
+	// 13. RADIUSハンドラ（RADIUS_DUP_CACHE_TTLが0の場合は重複検出なし、RADIUS_REQUEST_TIMEOUTが0の場合は処理期限なし）
+	var dupCache *server.DuplicateCache
+	if cfg.DupCacheTTL > 0 {
+		dupCache = server.NewDuplicateCache(cfg.DupCacheTTL)
+	}
+	handler := server.NewHandler(eapEngine, dupCache, nasLockout, rateLimiter, cfg.RequestTimeout)
 
-	// 10. UDPサーバー
+	// 14. UDPサーバー
 	srv := server.NewServer(cfg.ListenAddr, handler, secretSource)
 
-	// 11. サーバー起動（goroutine）
+	// 15. サーバー起動（goroutine）
 	go func() {
 		slog.Info("RADIUSサーバー起動", "addr", cfg.ListenAddr)
 		if err := srv.ListenAndServe(); err != nil {
@@ -82,7 +152,7 @@ func main() {
 		}
 	}()
 
-	// 12. シグナル待機 → Graceful Shutdown
+	// 16. シグナル待機 → Graceful Shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
 
@@ -1,10 +1,16 @@
 // Package dto はリクエスト・レスポンスのデータ転送オブジェクトを定義する。
 package dto
 
+// ModeTriplet はGSMトリプレット生成モード（EAP-SIM用）を表す。
+const ModeTriplet = "triplet"
+
 // VectorRequest はベクター生成リクエストを表す。
+// Modeが空の場合はAKAクインテットをCount件（省略時は1件）生成する。
 type VectorRequest struct {
 	IMSI       string      `json:"imsi" binding:"required"`
 	ResyncInfo *ResyncInfo `json:"resync_info,omitempty"`
+	Mode       string      `json:"mode,omitempty" binding:"omitempty,oneof=triplet"`
+	Count      int         `json:"count,omitempty"` // Mode=triplet時はトリプレット数（2〜3）、それ以外はクインテット数（1〜8）
 }
 
 // ResyncInfo は再同期情報を表す。
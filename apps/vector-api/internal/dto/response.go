@@ -1,12 +1,34 @@
 package dto
 
 // VectorResponse はベクター生成レスポンスを表す。
+// Mode=triplet時はTripletsのみ、クインテットを複数件要求した場合はVectorsのみを設定する。
 type VectorResponse struct {
+	RAND     string    `json:"rand,omitempty"`
+	AUTN     string    `json:"autn,omitempty"`
+	XRES     string    `json:"xres,omitempty"`
+	CK       string    `json:"ck,omitempty"`
+	IK       string    `json:"ik,omitempty"`
+	SQN      string    `json:"sqn,omitempty"`
+	Vectors  []Quintet `json:"vectors,omitempty"`
+	Triplets []Triplet `json:"triplets,omitempty"`
+}
+
+// Quintet はAKA認証ベクター（クインテット）を表す。
+// SQNは呼び出し元がベクターを昇順に使用するための値で、Vectors内はSQN昇順に並ぶ。
+type Quintet struct {
 	RAND string `json:"rand"`
 	AUTN string `json:"autn"`
 	XRES string `json:"xres"`
 	CK   string `json:"ck"`
 	IK   string `json:"ik"`
+	SQN  string `json:"sqn"`
+}
+
+// Triplet はGSM認証トリプレットを表す。
+type Triplet struct {
+	RAND string `json:"rand"`
+	SRES string `json:"sres"`
+	Kc   string `json:"kc"`
 }
 
 // HealthResponse はヘルスチェックレスポンスを表す。
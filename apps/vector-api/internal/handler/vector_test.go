@@ -12,6 +12,7 @@ import (
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/config"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/dto"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/usecase"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/httputil"
 	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/logging"
 )
 
@@ -188,4 +189,74 @@ func TestHandleVector(t *testing.T) {
 			t.Errorf("Status = %d, want %d", w.Code, http.StatusNotFound)
 		}
 	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		cfg := &config.Config{LogMaskIMSI: true}
+		h := NewVectorHandler(nil, cfg)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		reqBody := `{"imsi":"440101234567890","mode":"unknown"}`
+		c.Request, _ = http.NewRequest("POST", "/api/v1/vector", bytes.NewBufferString(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(TraceIDKey, "test-trace-id")
+
+		h.HandleVector(c)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("SIM not permitted", func(t *testing.T) {
+		mockUC := &mockVectorUseCase{
+			err: usecase.ErrSIMNotPermitted,
+		}
+		cfg := &config.Config{LogMaskIMSI: true}
+		h := NewVectorHandler(mockUC, cfg)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		reqBody := `{"imsi":"440101234567890","mode":"triplet","count":3}`
+		c.Request, _ = http.NewRequest("POST", "/api/v1/vector", bytes.NewBufferString(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(TraceIDKey, "test-trace-id")
+
+		h.HandleVector(c)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("subscriber barred", func(t *testing.T) {
+		mockUC := &mockVectorUseCase{
+			err: usecase.ErrSubscriberBarred,
+		}
+		cfg := &config.Config{LogMaskIMSI: true}
+		h := NewVectorHandler(mockUC, cfg)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		reqBody := `{"imsi":"440101234567890"}`
+		c.Request, _ = http.NewRequest("POST", "/api/v1/vector", bytes.NewBufferString(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set(TraceIDKey, "test-trace-id")
+
+		h.HandleVector(c)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Status = %d, want %d", w.Code, http.StatusForbidden)
+		}
+		var pd dto.ProblemDetail
+		if err := json.Unmarshal(w.Body.Bytes(), &pd); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if pd.Type != httputil.ProblemTypeSubscriberBarred {
+			t.Errorf("Type = %q, want %q", pd.Type, httputil.ProblemTypeSubscriberBarred)
+		}
+	})
 }
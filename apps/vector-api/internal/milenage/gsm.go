@@ -0,0 +1,66 @@
+package milenage
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/wmnsk/milenage"
+)
+
+// Triplet はGSM認証トリプレットを表す（EAP-SIM用）。
+type Triplet struct {
+	RAND []byte // 16 bytes
+	SRES []byte // 4 bytes
+	Kc   []byte // 8 bytes
+}
+
+// GenerateTriplet はMilenageの出力からGSMトリプレットを生成する。
+// SRES/KcはSQN・AMFに依存しないため、SQNは消費しない。
+func (c *Calculator) GenerateTriplet(ki, opc []byte) (*Triplet, error) {
+	randVal := make([]byte, 16)
+	if _, err := rand.Read(randVal); err != nil {
+		return nil, fmt.Errorf("failed to generate RAND: %w", err)
+	}
+
+	return c.GenerateTripletWithRAND(ki, opc, randVal)
+}
+
+// GenerateTripletWithRAND は指定されたRANDでGSMトリプレットを生成する。
+// テスト用に公開。
+func (c *Calculator) GenerateTripletWithRAND(ki, opc, randVal []byte) (*Triplet, error) {
+	m := milenage.NewWithOPc(ki, opc, randVal, 0, 0)
+
+	res, ck, ik, _, err := m.F2345()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute f2345: %w", err)
+	}
+
+	return &Triplet{
+		RAND: randVal,
+		SRES: C2(res),
+		Kc:   C3(ck, ik),
+	}, nil
+}
+
+// C2 はXRESからSRESを導出する（3GPP TS 33.102 Section 6.8.1.2）。
+// XRESを128bitまでゼロ埋めし、4つの32bitブロックのXORを取る。
+func C2(xres []byte) []byte {
+	padded := make([]byte, 16)
+	copy(padded, xres)
+
+	sres := make([]byte, 4)
+	for i := 0; i < 16; i++ {
+		sres[i%4] ^= padded[i]
+	}
+	return sres
+}
+
+// C3 はCK/IKからKcを導出する（3GPP TS 33.102 Section 6.8.1.2）。
+// Kc = CK1 ⊕ CK2 ⊕ IK1 ⊕ IK2（CKi/IKiは各64bit）
+func C3(ck, ik []byte) []byte {
+	kc := make([]byte, 8)
+	for i := 0; i < 8; i++ {
+		kc[i] = ck[i] ^ ck[i+8] ^ ik[i] ^ ik[i+8]
+	}
+	return kc
+}
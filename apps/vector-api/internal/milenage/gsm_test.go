@@ -0,0 +1,89 @@
+package milenage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// 3GPP TS 35.208 テストセット1
+const (
+	ts35208Ki   = "465b5ce8b199b49faa5f0a2ee238a6bc"
+	ts35208OPc  = "cd63cb71954a9f4e48a5994e37a02baf"
+	ts35208RAND = "23553cbe9637a89d218ae64dae47bf35"
+)
+
+func TestC2(t *testing.T) {
+	tests := []struct {
+		name string
+		xres string
+		want string
+	}{
+		{"64bit XRES", "a54211d5e3ba50bf", "46f8416a"},
+		{"128bit XRES", "0102030405060708090a0b0c0d0e0f10", "00000010"},
+		{"32bit XRES", "deadbeef", "deadbeef"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xres, _ := hex.DecodeString(tt.xres)
+			got := hex.EncodeToString(C2(xres))
+			if got != tt.want {
+				t.Errorf("C2(%s) = %s, want %s", tt.xres, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestC3(t *testing.T) {
+	ck, _ := hex.DecodeString("b40ba9a3c58b2a05bbf0d987b21bf8cb")
+	ik, _ := hex.DecodeString("f769bcd751044604127672711c6d3441")
+
+	got := hex.EncodeToString(C3(ck, ik))
+	if got != "eae4be823af9a08b" {
+		t.Errorf("C3 = %s, want eae4be823af9a08b", got)
+	}
+}
+
+func TestGenerateTripletWithRAND(t *testing.T) {
+	ki, _ := hex.DecodeString(ts35208Ki)
+	opc, _ := hex.DecodeString(ts35208OPc)
+	randVal, _ := hex.DecodeString(ts35208RAND)
+
+	calc := NewCalculator()
+	tr, err := calc.GenerateTripletWithRAND(ki, opc, randVal)
+	if err != nil {
+		t.Fatalf("GenerateTripletWithRAND() error = %v", err)
+	}
+
+	if !bytes.Equal(tr.RAND, randVal) {
+		t.Errorf("RAND = %x, want %x", tr.RAND, randVal)
+	}
+	if got := hex.EncodeToString(tr.SRES); got != "46f8416a" {
+		t.Errorf("SRES = %s, want 46f8416a", got)
+	}
+	if got := hex.EncodeToString(tr.Kc); got != "eae4be823af9a08b" {
+		t.Errorf("Kc = %s, want eae4be823af9a08b", got)
+	}
+}
+
+func TestGenerateTriplet_RandomRAND(t *testing.T) {
+	ki, _ := hex.DecodeString(ts35208Ki)
+	opc, _ := hex.DecodeString(ts35208OPc)
+
+	calc := NewCalculator()
+	tr1, err := calc.GenerateTriplet(ki, opc)
+	if err != nil {
+		t.Fatalf("GenerateTriplet() error = %v", err)
+	}
+	tr2, err := calc.GenerateTriplet(ki, opc)
+	if err != nil {
+		t.Fatalf("GenerateTriplet() error = %v", err)
+	}
+
+	if len(tr1.RAND) != 16 || len(tr1.SRES) != 4 || len(tr1.Kc) != 8 {
+		t.Errorf("unexpected lengths: RAND=%d SRES=%d Kc=%d", len(tr1.RAND), len(tr1.SRES), len(tr1.Kc))
+	}
+	if bytes.Equal(tr1.RAND, tr2.RAND) {
+		t.Error("RAND should be random")
+	}
+}
@@ -26,3 +26,30 @@ func VectorToResponse(v *Vector) *dto.VectorResponse {
 		IK:   HexEncode(v.IK),
 	}
 }
+
+// VectorToQuintet はVectorと生成時のSQN（Hex）をQuintetに変換する。
+func VectorToQuintet(v *Vector, sqn string) dto.Quintet {
+	return dto.Quintet{
+		RAND: HexEncode(v.RAND),
+		AUTN: HexEncode(v.AUTN),
+		XRES: HexEncode(v.XRES),
+		CK:   HexEncode(v.CK),
+		IK:   HexEncode(v.IK),
+		SQN:  sqn,
+	}
+}
+
+// TripletsToResponse はTriplet群をレスポンスDTOに変換する。
+func TripletsToResponse(triplets []*Triplet) *dto.VectorResponse {
+	resp := &dto.VectorResponse{
+		Triplets: make([]dto.Triplet, 0, len(triplets)),
+	}
+	for _, t := range triplets {
+		resp.Triplets = append(resp.Triplets, dto.Triplet{
+			RAND: HexEncode(t.RAND),
+			SRES: HexEncode(t.SRES),
+			Kc:   HexEncode(t.Kc),
+		})
+	}
+	return resp
+}
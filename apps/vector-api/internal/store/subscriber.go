@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,6 +21,13 @@ type Subscriber struct {
 	OPc  string // Hex 32桁
 	AMF  string // Hex 4桁
 	SQN  string // Hex 12桁
+	// SIMEnabled はEAP-SIM（GSMトリプレット）の利用可否。未設定時はfalse
+	SIMEnabled bool
+	// Status は利用状態（active / suspended / barred）。未設定時はactive
+	Status string
+	// ValidFrom・ValidUntil は有効期間（RFC3339）。空の場合は制限なし
+	ValidFrom  string
+	ValidUntil string
 }
 
 // SubscriberStore は加入者データへのアクセスを提供する。
@@ -52,6 +60,11 @@ func (s *SubscriberStore) Get(ctx context.Context, imsi string) (*Subscriber, er
 		OPc:  result["opc"],
 		AMF:  result["amf"],
 		SQN:  result["sqn"],
+		// 不正値・未設定はfalse扱い（EAP-SIM無効）
+		SIMEnabled: parseBool(result["sim_enabled"]),
+		Status:     result["status"],
+		ValidFrom:  result["valid_from"],
+		ValidUntil: result["valid_until"],
 	}, nil
 }
 
@@ -97,6 +110,12 @@ func (s *SubscriberStore) GetWithRetry(ctx context.Context, imsi string) (*Subsc
 	return nil, lastErr
 }
 
+// parseBool は真偽値文字列を解析する。解析できない場合はfalseを返す。
+func parseBool(s string) bool {
+	b, err := strconv.ParseBool(s)
+	return err == nil && b
+}
+
 // isConnectionError は接続エラーかどうかを判定する。
 func isConnectionError(err error) bool {
 	if err == nil {
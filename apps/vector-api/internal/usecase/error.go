@@ -4,10 +4,12 @@ import (
 	"log/slog"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/dto"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/httputil"
 )
 
 // ProblemError はビジネスロジックエラーを表す。
 type ProblemError struct {
+	Type    string // 空の場合は"about:blank"
 	Status  int
 	Title   string
 	Detail  string
@@ -22,7 +24,11 @@ func (e *ProblemError) Error() string {
 
 // ToProblemDetail はProblemDetailに変換する。
 func (e *ProblemError) ToProblemDetail() *dto.ProblemDetail {
-	return dto.NewProblemDetail(e.Status, e.Title, e.Detail)
+	pd := dto.NewProblemDetail(e.Status, e.Title, e.Detail)
+	if e.Type != "" {
+		pd.Type = e.Type
+	}
+	return pd
 }
 
 // LogLevel はログレベルを返す。
@@ -55,6 +61,57 @@ var (
 		EventID: "CALC_ERR",
 	}
 
+	ErrSIMNotPermitted = &ProblemError{
+		Status:  403,
+		Title:   "Forbidden",
+		Detail:  "EAP-SIM is not enabled for this subscriber",
+		Message: "EAP-SIM not permitted",
+		EventID: "CALC_SIM_DENIED",
+	}
+
+	ErrSubscriberBarred = &ProblemError{
+		Type:    httputil.ProblemTypeSubscriberBarred,
+		Status:  403,
+		Title:   "Subscriber Barred",
+		Detail:  "Subscriber is barred",
+		Message: "subscriber barred",
+		EventID: "CALC_SUB_BARRED",
+	}
+
+	ErrSubscriberSuspended = &ProblemError{
+		Type:    httputil.ProblemTypeSubscriberSuspended,
+		Status:  403,
+		Title:   "Subscriber Suspended",
+		Detail:  "Subscriber is suspended",
+		Message: "subscriber suspended",
+		EventID: "CALC_SUB_SUSPENDED",
+	}
+
+	ErrSubscriberOutsideValidity = &ProblemError{
+		Type:    httputil.ProblemTypeSubscriberInvalid,
+		Status:  403,
+		Title:   "Subscriber Not Valid",
+		Detail:  "Current time is outside the subscriber validity period",
+		Message: "subscriber outside validity period",
+		EventID: "CALC_SUB_EXPIRED",
+	}
+
+	ErrInvalidTripletRequest = &ProblemError{
+		Status:  400,
+		Title:   "Bad Request",
+		Detail:  "Triplet count must be 2 or 3 and resync_info is not allowed",
+		Message: "invalid triplet request",
+		EventID: "CALC_ERR",
+	}
+
+	ErrInvalidVectorCount = &ProblemError{
+		Status:  400,
+		Title:   "Bad Request",
+		Detail:  "Vector count must be between 1 and 8",
+		Message: "invalid vector count",
+		EventID: "CALC_ERR",
+	}
+
 	ErrResyncMACFailed = &ProblemError{
 		Status:  400,
 		Title:   "Bad Request",
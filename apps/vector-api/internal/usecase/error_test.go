@@ -3,6 +3,8 @@ package usecase
 import (
 	"log/slog"
 	"testing"
+
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/httputil"
 )
 
 func TestProblemError(t *testing.T) {
@@ -27,6 +29,20 @@ func TestProblemError(t *testing.T) {
 		if pd.Detail != err.Detail {
 			t.Errorf("Detail = %q, want %q", pd.Detail, err.Detail)
 		}
+		if pd.Type != "about:blank" {
+			t.Errorf("Type = %q, want %q", pd.Type, "about:blank")
+		}
+	})
+
+	t.Run("ToProblemDetail with type", func(t *testing.T) {
+		pd := ErrSubscriberBarred.ToProblemDetail()
+
+		if pd.Type != httputil.ProblemTypeSubscriberBarred {
+			t.Errorf("Type = %q, want %q", pd.Type, httputil.ProblemTypeSubscriberBarred)
+		}
+		if pd.Status != 403 {
+			t.Errorf("Status = %d, want 403", pd.Status)
+		}
 	})
 }
 
@@ -55,6 +71,11 @@ func TestPredefinedErrors(t *testing.T) {
 	errors := []*ProblemError{
 		ErrSubscriberNotFound,
 		ErrInvalidIMSI,
+		ErrSIMNotPermitted,
+		ErrSubscriberBarred,
+		ErrSubscriberSuspended,
+		ErrSubscriberOutsideValidity,
+		ErrInvalidTripletRequest,
 		ErrResyncMACFailed,
 		ErrResyncInvalidFormat,
 		ErrResyncDeltaExceeded,
@@ -14,6 +14,7 @@ import (
 // MilenageCalculator はMilenage計算のインターフェース。
 type MilenageCalculator interface {
 	GenerateVector(ki, opc, amf []byte, sqn uint64) (*milenage.Vector, error)
+	GenerateTriplet(ki, opc []byte) (*milenage.Triplet, error)
 }
 
 // ResyncProcessor は再同期処理のインターフェース。
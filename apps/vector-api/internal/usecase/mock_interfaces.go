@@ -43,6 +43,21 @@ func (m *MockMilenageCalculator) EXPECT() *MockMilenageCalculatorMockRecorder {
 	return m.recorder
 }
 
+// GenerateTriplet mocks base method.
+func (m *MockMilenageCalculator) GenerateTriplet(ki, opc []byte) (*milenage.Triplet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateTriplet", ki, opc)
+	ret0, _ := ret[0].(*milenage.Triplet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateTriplet indicates an expected call of GenerateTriplet.
+func (mr *MockMilenageCalculatorMockRecorder) GenerateTriplet(ki, opc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateTriplet", reflect.TypeOf((*MockMilenageCalculator)(nil).GenerateTriplet), ki, opc)
+}
+
 // GenerateVector mocks base method.
 func (m *MockMilenageCalculator) GenerateVector(ki, opc, amf []byte, sqn uint64) (*milenage.Vector, error) {
 	m.ctrl.T.Helper()
@@ -4,12 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/config"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/dto"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/milenage"
+	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/model"
 )
 
+// トリプレット生成数の範囲（RFC 4186 Section 9.3: AT_RANDは2〜3個）
+const (
+	minTripletCount = 2
+	maxTripletCount = 3
+)
+
+// maxVectorCount は1リクエストで生成するクインテットの上限（認証ベクターの先行取得用）
+const maxVectorCount = 8
+
 // VectorUseCase はベクター生成ユースケースを実装する。
 type VectorUseCase struct {
 	subscriberStore    SubscriberRepository
@@ -44,9 +56,22 @@ func NewVectorUseCase(
 
 // GenerateVector はベクターを生成する。
 func (u *VectorUseCase) GenerateVector(ctx context.Context, req *dto.VectorRequest) (*dto.VectorResponse, error) {
+	// トリプレットモード（EAP-SIM）
+	if req.Mode == dto.ModeTriplet {
+		return u.generateTriplets(ctx, req)
+	}
+
+	count := req.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > maxVectorCount {
+		return nil, ErrInvalidVectorCount
+	}
+
 	// 0. テストモード判定（有効な場合）
 	if u.testVectorProvider != nil && u.testVectorProvider.IsTestIMSI(req.IMSI) {
-		return u.generateTestVector(ctx, req)
+		return u.generateTestVector(ctx, req, count)
 	}
 
 	// 1. 加入者情報取得
@@ -57,6 +82,9 @@ func (u *VectorUseCase) GenerateVector(ctx context.Context, req *dto.VectorReque
 	if sub == nil {
 		return nil, ErrSubscriberNotFound
 	}
+	if err := checkSubscriberStatus(sub, time.Now()); err != nil {
+		return nil, err
+	}
 
 	// 2. 鍵情報をバイト列に変換
 	ki, err := milenage.HexDecode(sub.Ki)
@@ -92,19 +120,121 @@ func (u *VectorUseCase) GenerateVector(ctx context.Context, req *dto.VectorReque
 	}
 
 	// 4. ベクター生成
-	vector, err := u.calculator.GenerateVector(ki, opc, amf, newSQN)
+	resp, lastSQNHex, err := u.generateQuintets(ki, opc, amf, newSQN, count)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrMilenageCalculation, err)
+		return nil, err
 	}
 
-	// 5. SQN更新
-	newSQNHex := u.sqnManager.FormatHex(newSQN)
-	if err := u.subscriberStore.UpdateSQN(ctx, req.IMSI, newSQNHex); err != nil {
+	// 5. SQN更新（最後に生成したベクターのSQN）
+	if err := u.subscriberStore.UpdateSQN(ctx, req.IMSI, lastSQNHex); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrValkeyConnection, err)
 	}
 
-	// 6. レスポンス変換
-	return milenage.VectorToResponse(vector), nil
+	return resp, nil
+}
+
+// checkSubscriberStatus は加入者の利用状態と有効期間を確認し、認証不可の場合はエラーを返す。
+// 未知の状態値は設定誤りとみなし、一時停止として扱う。
+func checkSubscriberStatus(sub *store.Subscriber, now time.Time) error {
+	switch sub.Status {
+	case "", model.SubscriberStatusActive:
+	case model.SubscriberStatusBarred:
+		return ErrSubscriberBarred
+	default:
+		return ErrSubscriberSuspended
+	}
+	if !model.WithinValidity(sub.ValidFrom, sub.ValidUntil, now) {
+		return ErrSubscriberOutsideValidity
+	}
+	return nil
+}
+
+// generateQuintets はfirstSQNから連続するSQNでcount件のクインテットを生成する。
+// 1件の場合は従来どおりトップレベルに、複数件の場合はVectorsにSQN昇順で設定する。
+// 最後に生成したベクターのSQN（Hex）を併せて返す。
+func (u *VectorUseCase) generateQuintets(ki, opc, amf []byte, firstSQN uint64, count int) (*dto.VectorResponse, string, error) {
+	quintets := make([]dto.Quintet, 0, count)
+	var vector *milenage.Vector
+	var sqnHex string
+	sqn := firstSQN
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			next, err := u.sqnManager.Increment(sqn)
+			if err != nil {
+				return nil, "", ErrSQNOverflow
+			}
+			sqn = next
+		}
+
+		var err error
+		vector, err = u.calculator.GenerateVector(ki, opc, amf, sqn)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrMilenageCalculation, err)
+		}
+		sqnHex = u.sqnManager.FormatHex(sqn)
+		quintets = append(quintets, milenage.VectorToQuintet(vector, sqnHex))
+	}
+
+	if count == 1 {
+		resp := milenage.VectorToResponse(vector)
+		resp.SQN = sqnHex
+		return resp, sqnHex, nil
+	}
+	return &dto.VectorResponse{Vectors: quintets}, sqnHex, nil
+}
+
+// generateTriplets はEAP-SIM用のGSMトリプレットを生成する。
+// SRES/KcはMilenageのRES/CK/IKからc2/c3変換で導出する（3GPP TS 33.102 Section 6.8.1.2）。
+// EAP-SIMはUSIM認証より弱いため、sim_enabledが設定された加入者のみ許可する。
+// SQNは使用しないため更新しない。
+func (u *VectorUseCase) generateTriplets(ctx context.Context, req *dto.VectorRequest) (*dto.VectorResponse, error) {
+	count := req.Count
+	if count == 0 {
+		count = maxTripletCount
+	}
+	if count < minTripletCount || count > maxTripletCount || req.ResyncInfo != nil {
+		return nil, ErrInvalidTripletRequest
+	}
+
+	var ki, opc []byte
+	if u.testVectorProvider != nil && u.testVectorProvider.IsTestIMSI(req.IMSI) {
+		// テストモード: 固定暗号パラメータを使用し、sim_enabledは問わない
+		ki, opc, _ = u.testVectorProvider.GetTestCryptoParams()
+	} else {
+		sub, err := u.subscriberStore.Get(ctx, req.IMSI)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrValkeyConnection, err)
+		}
+		if sub == nil {
+			return nil, ErrSubscriberNotFound
+		}
+		if err := checkSubscriberStatus(sub, time.Now()); err != nil {
+			return nil, err
+		}
+		if !sub.SIMEnabled {
+			return nil, ErrSIMNotPermitted
+		}
+
+		ki, err = milenage.HexDecode(sub.Ki)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Ki format: %w", err)
+		}
+		opc, err = milenage.HexDecode(sub.OPc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OPc format: %w", err)
+		}
+	}
+
+	triplets := make([]*milenage.Triplet, 0, count)
+	for i := 0; i < count; i++ {
+		t, err := u.calculator.GenerateTriplet(ki, opc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMilenageCalculation, err)
+		}
+		triplets = append(triplets, t)
+	}
+
+	return milenage.TripletsToResponse(triplets), nil
 }
 
 // processResync は再同期処理を行う。
@@ -159,9 +289,9 @@ func (u *VectorUseCase) processResync(ki, opc []byte, resyncInfo *dto.ResyncInfo
 	return newSQN, nil
 }
 
-// generateTestVector はテストモード用のベクターを生成する。
+// generateTestVector はテストモード用のベクターをcount件生成する。
 // Ki/OPc/AMFは固定値を使用し、SQNはValkey経由でステートフルに管理する。
-func (u *VectorUseCase) generateTestVector(ctx context.Context, req *dto.VectorRequest) (*dto.VectorResponse, error) {
+func (u *VectorUseCase) generateTestVector(ctx context.Context, req *dto.VectorRequest, count int) (*dto.VectorResponse, error) {
 	// 1. テスト用暗号パラメータ取得
 	ki, opc, amf := u.testVectorProvider.GetTestCryptoParams()
 
@@ -203,13 +333,12 @@ func (u *VectorUseCase) generateTestVector(ctx context.Context, req *dto.VectorR
 	}
 
 	// 4. ベクター生成
-	vector, err := u.calculator.GenerateVector(ki, opc, amf, newSQN)
+	resp, newSQNHex, err := u.generateQuintets(ki, opc, amf, newSQN, count)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrMilenageCalculation, err)
+		return nil, err
 	}
 
 	// 5. ValkeyにSQN書き戻し
-	newSQNHex := u.sqnManager.FormatHex(newSQN)
 	if err := u.subscriberStore.UpdateSQN(ctx, req.IMSI, newSQNHex); err != nil {
 		slog.Warn("test mode: failed to persist SQN to Valkey",
 			"event_id", "TEST_SQN_PERSIST_ERR",
@@ -224,5 +353,5 @@ func (u *VectorUseCase) generateTestVector(ctx context.Context, req *dto.VectorR
 		"sqn", newSQNHex,
 	)
 
-	return milenage.VectorToResponse(vector), nil
+	return resp, nil
 }
@@ -3,12 +3,14 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/config"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/dto"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/milenage"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-api/internal/store"
+	"github.com/oyaguma3/eapaka-radius-server-poc/pkg/model"
 	"go.uber.org/mock/gomock"
 )
 
@@ -338,6 +340,74 @@ func TestGenerateVector_Success(t *testing.T) {
 	}
 }
 
+// --- TestGenerateVector_Batch ---
+
+func TestGenerateVector_Batch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	uc, mockRepo, mockCalc, mockSQNMgr, _, _, mockTestVP := setupUseCase(ctrl)
+
+	mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+	mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(validSubscriber(), nil)
+	mockSQNMgr.EXPECT().ParseHex(validHexSQN).Return(uint64(0x20), nil)
+	mockSQNMgr.EXPECT().Increment(uint64(0x20)).Return(uint64(0x40), nil)
+	mockSQNMgr.EXPECT().Increment(uint64(0x40)).Return(uint64(0x60), nil)
+	mockSQNMgr.EXPECT().Increment(uint64(0x60)).Return(uint64(0x80), nil)
+	for _, sqn := range []uint64{0x40, 0x60, 0x80} {
+		mockCalc.EXPECT().GenerateVector(gomock.Any(), gomock.Any(), gomock.Any(), sqn).Return(dummyVector(), nil)
+		mockSQNMgr.EXPECT().FormatHex(sqn).Return(fmt.Sprintf("%012x", sqn))
+	}
+	// SQNは最後に生成したベクターの値で1回だけ更新する
+	mockRepo.EXPECT().UpdateSQN(gomock.Any(), normalIMSI, "000000000080").Return(nil)
+
+	resp, err := uc.GenerateVector(context.Background(), &dto.VectorRequest{IMSI: normalIMSI, Count: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.RAND != "" {
+		t.Error("複数件要求時にトップレベルのベクターが設定された")
+	}
+	if len(resp.Vectors) != 3 {
+		t.Fatalf("len(Vectors) = %d, want 3", len(resp.Vectors))
+	}
+	for i, want := range []string{"000000000040", "000000000060", "000000000080"} {
+		if resp.Vectors[i].SQN != want {
+			t.Errorf("Vectors[%d].SQN = %s, want %s", i, resp.Vectors[i].SQN, want)
+		}
+	}
+}
+
+func TestGenerateVector_Batch_Overflow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	uc, mockRepo, mockCalc, mockSQNMgr, _, _, mockTestVP := setupUseCase(ctrl)
+
+	mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+	mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(validSubscriber(), nil)
+	mockSQNMgr.EXPECT().ParseHex(validHexSQN).Return(uint64(0x20), nil)
+	mockSQNMgr.EXPECT().Increment(uint64(0x20)).Return(uint64(0x40), nil)
+	mockCalc.EXPECT().GenerateVector(gomock.Any(), gomock.Any(), gomock.Any(), uint64(0x40)).Return(dummyVector(), nil)
+	mockSQNMgr.EXPECT().FormatHex(uint64(0x40)).Return("000000000040")
+	mockSQNMgr.EXPECT().Increment(uint64(0x40)).Return(uint64(0), errors.New("overflow"))
+
+	_, err := uc.GenerateVector(context.Background(), &dto.VectorRequest{IMSI: normalIMSI, Count: 2})
+	if !errors.Is(err, ErrSQNOverflow) {
+		t.Errorf("expected ErrSQNOverflow, got %v", err)
+	}
+}
+
+func TestGenerateVector_InvalidCount(t *testing.T) {
+	for _, count := range []int{-1, maxVectorCount + 1} {
+		ctrl := gomock.NewController(t)
+		uc, _, _, _, _, _, _ := setupUseCase(ctrl)
+
+		_, err := uc.GenerateVector(context.Background(), &dto.VectorRequest{IMSI: normalIMSI, Count: count})
+		if !errors.Is(err, ErrInvalidVectorCount) {
+			t.Errorf("count=%d: expected ErrInvalidVectorCount, got %v", count, err)
+		}
+	}
+}
+
 // --- TestGenerateVector_SubscriberGetError ---
 
 func TestGenerateVector_SubscriberGetError(t *testing.T) {
@@ -377,6 +447,68 @@ func TestGenerateVector_SubscriberNotFound(t *testing.T) {
 	}
 }
 
+// --- TestGenerateVector_SubscriberStatus ---
+
+func TestGenerateVector_SubscriberStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		validFrom  string
+		validUntil string
+		wantErr    error
+	}{
+		{"barred", model.SubscriberStatusBarred, "", "", ErrSubscriberBarred},
+		{"suspended", model.SubscriberStatusSuspended, "", "", ErrSubscriberSuspended},
+		{"unknown status", "disabled", "", "", ErrSubscriberSuspended},
+		{"not yet valid", model.SubscriberStatusActive, "2999-01-01T00:00:00Z", "", ErrSubscriberOutsideValidity},
+		{"expired", "", "", "2000-01-01T00:00:00Z", ErrSubscriberOutsideValidity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			uc, mockRepo, _, _, _, _, mockTestVP := setupUseCase(ctrl)
+
+			sub := validSubscriber()
+			sub.Status = tt.status
+			sub.ValidFrom = tt.validFrom
+			sub.ValidUntil = tt.validUntil
+
+			mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+			mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(sub, nil)
+
+			req := &dto.VectorRequest{IMSI: normalIMSI}
+			_, err := uc.GenerateVector(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGenerateVector_SubscriberWithinValidity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uc, mockRepo, mockCalc, mockSQNMgr, _, _, mockTestVP := setupUseCase(ctrl)
+
+	sub := validSubscriber()
+	sub.Status = model.SubscriberStatusActive
+	sub.ValidFrom = "2000-01-01T00:00:00Z"
+	sub.ValidUntil = "2999-01-01T00:00:00Z"
+
+	mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+	mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(sub, nil)
+	mockSQNMgr.EXPECT().ParseHex(validHexSQN).Return(uint64(0x20), nil)
+	mockSQNMgr.EXPECT().Increment(uint64(0x20)).Return(uint64(0x21), nil)
+	mockCalc.EXPECT().GenerateVector(gomock.Any(), gomock.Any(), gomock.Any(), uint64(0x21)).Return(dummyVector(), nil)
+	mockSQNMgr.EXPECT().FormatHex(uint64(0x21)).Return("000000000021")
+	mockRepo.EXPECT().UpdateSQN(gomock.Any(), normalIMSI, "000000000021").Return(nil)
+
+	req := &dto.VectorRequest{IMSI: normalIMSI}
+	if _, err := uc.GenerateVector(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // --- TestGenerateVector_InvalidKi ---
 
 func TestGenerateVector_InvalidKi(t *testing.T) {
@@ -736,3 +868,166 @@ func TestGenerateVector_Resync_Success(t *testing.T) {
 		t.Fatal("expected non-nil response")
 	}
 }
+
+// --- TestGenerateVector_Triplet ---
+
+// dummyTriplet はテスト用固定トリプレットを返すヘルパー。
+func dummyTriplet() *milenage.Triplet {
+	return &milenage.Triplet{
+		RAND: make([]byte, 16),
+		SRES: make([]byte, 4),
+		Kc:   make([]byte, 8),
+	}
+}
+
+func TestGenerateVector_Triplet(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		wantCount int
+	}{
+		{"デフォルト（3個）", 0, 3},
+		{"2個指定", 2, 2},
+		{"3個指定", 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			uc, mockRepo, mockCalc, _, _, _, mockTestVP := setupUseCase(ctrl)
+
+			sub := validSubscriber()
+			sub.SIMEnabled = true
+			mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+			mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(sub, nil)
+			mockCalc.EXPECT().GenerateTriplet(gomock.Any(), gomock.Any()).Return(dummyTriplet(), nil).Times(tt.wantCount)
+			// SQNは更新しない
+			mockRepo.EXPECT().UpdateSQN(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			req := &dto.VectorRequest{IMSI: normalIMSI, Mode: dto.ModeTriplet, Count: tt.count}
+			resp, err := uc.GenerateVector(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(resp.Triplets) != tt.wantCount {
+				t.Errorf("len(Triplets) = %d, want %d", len(resp.Triplets), tt.wantCount)
+			}
+			if resp.RAND != "" || resp.XRES != "" {
+				t.Error("quintet fields should be empty in triplet mode")
+			}
+			if resp.Triplets[0].SRES != "00000000" || resp.Triplets[0].Kc != "0000000000000000" {
+				t.Errorf("unexpected triplet: %+v", resp.Triplets[0])
+			}
+		})
+	}
+}
+
+func TestGenerateVector_Triplet_SIMNotEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uc, mockRepo, _, _, _, _, mockTestVP := setupUseCase(ctrl)
+
+	mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+	mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(validSubscriber(), nil)
+
+	req := &dto.VectorRequest{IMSI: normalIMSI, Mode: dto.ModeTriplet}
+	_, err := uc.GenerateVector(context.Background(), req)
+	if !errors.Is(err, ErrSIMNotPermitted) {
+		t.Errorf("expected ErrSIMNotPermitted, got %v", err)
+	}
+}
+
+func TestGenerateVector_Triplet_SubscriberBarred(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uc, mockRepo, _, _, _, _, mockTestVP := setupUseCase(ctrl)
+
+	sub := validSubscriber()
+	sub.SIMEnabled = true
+	sub.Status = model.SubscriberStatusBarred
+
+	mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+	mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(sub, nil)
+
+	req := &dto.VectorRequest{IMSI: normalIMSI, Mode: dto.ModeTriplet}
+	_, err := uc.GenerateVector(context.Background(), req)
+	if !errors.Is(err, ErrSubscriberBarred) {
+		t.Errorf("expected ErrSubscriberBarred, got %v", err)
+	}
+}
+
+func TestGenerateVector_Triplet_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *dto.VectorRequest
+	}{
+		{"count=1", &dto.VectorRequest{IMSI: normalIMSI, Mode: dto.ModeTriplet, Count: 1}},
+		{"count=4", &dto.VectorRequest{IMSI: normalIMSI, Mode: dto.ModeTriplet, Count: 4}},
+		{"resync_info指定", &dto.VectorRequest{
+			IMSI:       normalIMSI,
+			Mode:       dto.ModeTriplet,
+			ResyncInfo: &dto.ResyncInfo{RAND: "00", AUTS: "00"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			uc, _, _, _, _, _, _ := setupUseCase(ctrl)
+
+			_, err := uc.GenerateVector(context.Background(), tt.req)
+			if !errors.Is(err, ErrInvalidTripletRequest) {
+				t.Errorf("expected ErrInvalidTripletRequest, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateVector_Triplet_SubscriberNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uc, mockRepo, _, _, _, _, mockTestVP := setupUseCase(ctrl)
+
+	mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+	mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(nil, nil)
+
+	req := &dto.VectorRequest{IMSI: normalIMSI, Mode: dto.ModeTriplet}
+	_, err := uc.GenerateVector(context.Background(), req)
+	if !errors.Is(err, ErrSubscriberNotFound) {
+		t.Errorf("expected ErrSubscriberNotFound, got %v", err)
+	}
+}
+
+func TestGenerateVector_Triplet_TestMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uc, mockRepo, mockCalc, _, _, _, mockTestVP := setupUseCase(ctrl)
+
+	// テストモードではValkeyを参照せず、固定暗号パラメータを使用する
+	mockTestVP.EXPECT().IsTestIMSI(testIMSI).Return(true)
+	mockTestVP.EXPECT().GetTestCryptoParams().Return(testKi, testOPc, testAMF)
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Times(0)
+	mockCalc.EXPECT().GenerateTriplet(testKi, testOPc).Return(dummyTriplet(), nil).Times(2)
+
+	req := &dto.VectorRequest{IMSI: testIMSI, Mode: dto.ModeTriplet, Count: 2}
+	resp, err := uc.GenerateVector(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Triplets) != 2 {
+		t.Errorf("len(Triplets) = %d, want 2", len(resp.Triplets))
+	}
+}
+
+func TestGenerateVector_Triplet_CalculationError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	uc, mockRepo, mockCalc, _, _, _, mockTestVP := setupUseCase(ctrl)
+
+	sub := validSubscriber()
+	sub.SIMEnabled = true
+	mockTestVP.EXPECT().IsTestIMSI(normalIMSI).Return(false)
+	mockRepo.EXPECT().Get(gomock.Any(), normalIMSI).Return(sub, nil)
+	mockCalc.EXPECT().GenerateTriplet(gomock.Any(), gomock.Any()).Return(nil, errors.New("calc failed"))
+
+	req := &dto.VectorRequest{IMSI: normalIMSI, Mode: dto.ModeTriplet}
+	_, err := uc.GenerateVector(context.Background(), req)
+	if !errors.Is(err, ErrMilenageCalculation) {
+		t.Errorf("expected ErrMilenageCalculation, got %v", err)
+	}
+}
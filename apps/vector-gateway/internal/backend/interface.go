@@ -7,6 +7,8 @@ import "context"
 type VectorRequest struct {
 	IMSI       string      `json:"imsi"`
 	ResyncInfo *ResyncInfo `json:"resync_info,omitempty"`
+	Mode       string      `json:"mode,omitempty"`  // "triplet"の場合はEAP-SIM用トリプレット
+	Count      int         `json:"count,omitempty"` // トリプレット数、またはクインテット数（先行取得）
 }
 
 // ResyncInfo は再同期情報を表す。
@@ -17,11 +19,31 @@ type ResyncInfo struct {
 
 // VectorResponse はベクター生成レスポンスを表す。
 type VectorResponse struct {
+	RAND     string    `json:"rand,omitempty"`
+	AUTN     string    `json:"autn,omitempty"`
+	XRES     string    `json:"xres,omitempty"`
+	CK       string    `json:"ck,omitempty"`
+	IK       string    `json:"ik,omitempty"`
+	SQN      string    `json:"sqn,omitempty"`
+	Vectors  []Quintet `json:"vectors,omitempty"`
+	Triplets []Triplet `json:"triplets,omitempty"`
+}
+
+// Quintet はAKA認証ベクター（クインテット）を表す。
+type Quintet struct {
 	RAND string `json:"rand"`
 	AUTN string `json:"autn"`
 	XRES string `json:"xres"`
 	CK   string `json:"ck"`
 	IK   string `json:"ik"`
+	SQN  string `json:"sqn"`
+}
+
+// Triplet はGSM認証トリプレットを表す。
+type Triplet struct {
+	RAND string `json:"rand"`
+	SRES string `json:"sres"`
+	Kc   string `json:"kc"`
 }
 
 // Backend はベクター生成バックエンドのインターフェース。
@@ -200,6 +200,40 @@ func TestInternalBackend_GetVector_WithResyncInfo(t *testing.T) {
 	}
 }
 
+func TestInternalBackend_GetVector_Triplet(t *testing.T) {
+	var receivedReq VectorRequest
+	expected := &VectorResponse{
+		Triplets: []Triplet{
+			{RAND: "0102030405060708090a0b0c0d0e0f10", SRES: "46f8416a", Kc: "eae4be823af9a08b"},
+			{RAND: "1112131415161718191a1b1c1d1e1f20", SRES: "11223344", Kc: "0011223344556677"},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedReq)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(expected)
+	}))
+	defer srv.Close()
+
+	b := NewInternalBackend(srv.URL, 5*time.Second)
+	req := &VectorRequest{IMSI: "440101234567890", Mode: "triplet", Count: 2}
+	resp, err := b.GetVector(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GetVector() error = %v", err)
+	}
+
+	if receivedReq.Mode != "triplet" || receivedReq.Count != 2 {
+		t.Errorf("Mode/Count = %q/%d, want triplet/2", receivedReq.Mode, receivedReq.Count)
+	}
+	if len(resp.Triplets) != 2 {
+		t.Fatalf("len(Triplets) = %d, want 2", len(resp.Triplets))
+	}
+	if resp.Triplets[0] != expected.Triplets[0] {
+		t.Errorf("Triplets[0] = %+v, want %+v", resp.Triplets[0], expected.Triplets[0])
+	}
+}
+
 func TestInternalBackend_IDAndName(t *testing.T) {
 	b := NewInternalBackend("http://localhost:8080", 5*time.Second)
 
@@ -2,10 +2,13 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oyaguma3/eapaka-radius-server-poc/apps/vector-gateway/internal/backend"
@@ -37,6 +40,13 @@ func (h *VectorHandler) HandleVector(c *gin.Context) {
 	traceID, _ := c.Get(TraceIDKey)
 	ctx := backend.ContextWithTraceID(c.Request.Context(), fmt.Sprint(traceID))
 
+	// 呼び出し元の処理期限をバックエンド呼び出しに反映
+	if timeout, ok := parseRequestTimeout(c.GetHeader(httputil.HeaderRequestTimeout)); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// 1. リクエストバインド
 	var req backend.VectorRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -87,6 +97,10 @@ func (h *VectorHandler) HandleVector(c *gin.Context) {
 	// 4. ベクター取得
 	resp, err := b.GetVector(ctx, &req)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			h.handleDeadlineExceeded(c, traceID, req.IMSI, b.ID())
+			return
+		}
 		h.handleBackendError(c, traceID, req.IMSI, err)
 		return
 	}
@@ -179,6 +193,35 @@ func (h *VectorHandler) handleBackendError(c *gin.Context, traceID any, imsi str
 	))
 }
 
+// handleDeadlineExceeded は呼び出し元の処理期限超過を処理する。
+// 呼び出し元は既に応答を待っていないため、バックエンド障害とは区別して記録する。
+func (h *VectorHandler) handleDeadlineExceeded(c *gin.Context, traceID any, imsi, backendID string) {
+	slog.Warn("request deadline exceeded",
+		"trace_id", traceID,
+		"event_id", "GW_DEADLINE_EXCEEDED",
+		"imsi", logging.MaskIMSI(imsi, h.cfg.LogMaskIMSI),
+		"backend_id", backendID,
+	)
+	c.JSON(http.StatusGatewayTimeout, httputil.NewProblemDetail(
+		http.StatusGatewayTimeout,
+		"Gateway Timeout",
+		"Request deadline exceeded",
+	))
+}
+
+// parseRequestTimeout は処理期限ヘッダの値を解釈する。
+// 未指定または正の整数でない場合はfalseを返す（期限なし）。
+func parseRequestTimeout(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
 // validateIMSI はIMSI形式を検証する。
 func validateIMSI(imsi string) error {
 	if len(imsi) != 15 {
@@ -283,6 +283,63 @@ func TestHandleVector_BackendConnectionError(t *testing.T) {
 	}
 }
 
+func TestHandleVector_RequestTimeoutExceeded(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	h := setupHandlerWithMockServer(srv)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	reqBody := `{"imsi":"440101234567890"}`
+	c.Request, _ = http.NewRequest("POST", "/api/v1/vector", bytes.NewBufferString(reqBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set(httputil.HeaderRequestTimeout, "20")
+	c.Set(TraceIDKey, "test-trace-id")
+
+	start := time.Now()
+	h.HandleVector(c)
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusGatewayTimeout)
+	}
+	if elapsed := time.Since(start); elapsed >= 5*time.Second {
+		t.Errorf("elapsed = %v, want below InternalTimeout", elapsed)
+	}
+}
+
+func TestParseRequestTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "valid", value: "2500", want: 2500 * time.Millisecond, wantOK: true},
+		{name: "empty", value: "", wantOK: false},
+		{name: "zero", value: "0", wantOK: false},
+		{name: "negative", value: "-1", wantOK: false},
+		{name: "not a number", value: "3s", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRequestTimeout(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseRequestTimeout(%q) = (%v, %v), want (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestHandleVector_RoutingError_BackendNotImplemented(t *testing.T) {
 	// PLMNマップを設定し、未登録のバックエンドIDにルーティングさせる
 	cfg := &config.Config{
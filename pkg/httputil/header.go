@@ -0,0 +1,8 @@
+package httputil
+
+// サービス間で共通のHTTPヘッダ名。
+const (
+	// HeaderRequestTimeout は呼び出し元の処理期限までの残り時間（ミリ秒、1以上）を示すヘッダ。
+	// 未指定の場合、受信側は期限なしとして扱う。
+	HeaderRequestTimeout = "X-Request-Timeout-Ms"
+)
@@ -14,6 +14,14 @@ type ProblemDetail struct {
 	Detail string `json:"detail,omitempty"` // 詳細説明
 }
 
+// 加入者の利用状態によるエラーのタイプURI。
+// いずれも403 Forbiddenで返し、呼び出し元はTypeで拒否理由を区別する。
+const (
+	ProblemTypeSubscriberBarred    = "urn:eapaka:problem:subscriber-barred"
+	ProblemTypeSubscriberSuspended = "urn:eapaka:problem:subscriber-suspended"
+	ProblemTypeSubscriberInvalid   = "urn:eapaka:problem:subscriber-outside-validity"
+)
+
 // NewProblemDetail は新しいProblemDetailを生成する。
 func NewProblemDetail(status int, title, detail string) *ProblemDetail {
 	return &ProblemDetail{
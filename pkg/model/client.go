@@ -7,6 +7,15 @@ type RadiusClient struct {
 	Secret string `json:"secret"` // 共有シークレット
 	Name   string `json:"name"`   // クライアント名（識別用）
 	Vendor string `json:"vendor"` // ベンダー名（任意）
+
+	// RequireAKAPrime がtrueの場合、このクライアント経由のEAP-AKA（AKA'以外）を拒否する
+	RequireAKAPrime bool `json:"require_aka_prime"`
+
+	// NetworkName はEAP-AKA'のAT_KDF_INPUTで使用するアクセスネットワーク名（空の場合はAuth Serverの既定値）
+	NetworkName string `json:"network_name,omitempty"`
+
+	// PolicyProfile は加入者ポリシー・プレフィックスのポリシーがない加入者に適用するポリシープロファイル名（空は未設定）
+	PolicyProfile string `json:"policy_profile,omitempty"`
 }
 
 // NewRadiusClient は新しいRadiusClientを生成する。
@@ -9,6 +9,9 @@ type Policy struct {
 	Default   string       `json:"default"`    // デフォルトアクション（"allow" or "deny"）
 	RulesJSON string       `json:"rules_json"` // ルールのJSON文字列（Valkey保存用）
 	Rules     []PolicyRule `json:"-"`          // パース済みルール（メモリ上のみ）
+
+	// RequireAKAPrime がtrueの場合、この加入者のEAP-AKA（AKA'以外）を拒否する
+	RequireAKAPrime bool `json:"require_aka_prime"`
 }
 
 // PolicyRule はポリシールールを表す。
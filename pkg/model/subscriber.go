@@ -1,6 +1,15 @@
 // Package model は共通データ構造体を提供する。
 package model
 
+import "time"
+
+// 加入者の利用状態（sub:{IMSI} の status フィールド）
+const (
+	SubscriberStatusActive    = "active"    // 利用中
+	SubscriberStatusSuspended = "suspended" // 一時停止（認証情報は保持したまま認証を拒否）
+	SubscriberStatusBarred    = "barred"    // 利用停止（不正利用・解約等による恒久的な拒否）
+)
+
 // Subscriber は加入者情報を表す。
 // Valkeyキー: sub:{IMSI}
 type Subscriber struct {
@@ -10,6 +19,50 @@ type Subscriber struct {
 	AMF       string `json:"amf"`        // 認証管理フィールド（4文字16進数）
 	SQN       string `json:"sqn"`        // シーケンス番号（12文字16進数）
 	CreatedAt string `json:"created_at"` // 作成日時（RFC3339形式）
+
+	// SIMEnabled はEAP-SIM（GSMトリプレット）認証の許可フラグ。
+	// EAP-SIMはUSIM認証より弱いため、明示的に許可した加入者のみtrueとする。
+	SIMEnabled bool `json:"sim_enabled"`
+
+	// Status は利用状態（active / suspended / barred）。未設定はactiveとして扱う。
+	Status string `json:"status,omitempty"`
+	// StatusReason は利用状態を設定した理由（運用者向けの自由記述）。
+	StatusReason string `json:"status_reason,omitempty"`
+	// ValidFrom・ValidUntil は認証を許可する期間（RFC3339形式）。空の場合は制限なし。
+	ValidFrom  string `json:"valid_from,omitempty"`
+	ValidUntil string `json:"valid_until,omitempty"`
+}
+
+// EffectiveStatus は未設定をactiveとみなした利用状態を返す。
+func (s *Subscriber) EffectiveStatus() string {
+	if s.Status == "" {
+		return SubscriberStatusActive
+	}
+	return s.Status
+}
+
+// IsWithinValidity はtが有効期間内（ValidFrom以上ValidUntil未満）かどうかを判定する。
+// 日時を解析できない場合は期間外として扱う。
+func (s *Subscriber) IsWithinValidity(t time.Time) bool {
+	return WithinValidity(s.ValidFrom, s.ValidUntil, t)
+}
+
+// WithinValidity はtがvalidFrom以上validUntil未満かどうかを判定する。
+// 空の境界は制限なしとし、RFC3339として解析できない境界がある場合はfalseを返す。
+func WithinValidity(validFrom, validUntil string, t time.Time) bool {
+	if validFrom != "" {
+		from, err := time.Parse(time.RFC3339, validFrom)
+		if err != nil || t.Before(from) {
+			return false
+		}
+	}
+	if validUntil != "" {
+		until, err := time.Parse(time.RFC3339, validUntil)
+		if err != nil || !t.Before(until) {
+			return false
+		}
+	}
+	return true
 }
 
 // NewSubscriber は新しいSubscriberを生成する。
@@ -1,6 +1,9 @@
 package model
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNewSubscriber(t *testing.T) {
 	sub := NewSubscriber(
@@ -47,3 +50,55 @@ func TestSubscriberStruct(t *testing.T) {
 		t.Errorf("IMSI = %q, want %q", sub.IMSI, "440109876543210")
 	}
 }
+
+func TestSubscriberEffectiveStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{"", SubscriberStatusActive},
+		{SubscriberStatusActive, SubscriberStatusActive},
+		{SubscriberStatusSuspended, SubscriberStatusSuspended},
+		{SubscriberStatusBarred, SubscriberStatusBarred},
+	}
+
+	for _, tt := range tests {
+		sub := Subscriber{Status: tt.status}
+		if got := sub.EffectiveStatus(); got != tt.want {
+			t.Errorf("EffectiveStatus(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestWithinValidity(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		validFrom  string
+		validUntil string
+		want       bool
+	}{
+		{"no limit", "", "", true},
+		{"within range", "2025-01-01T00:00:00Z", "2026-01-01T00:00:00Z", true},
+		{"from boundary", "2025-06-01T00:00:00Z", "", true},
+		{"not yet valid", "2025-06-01T00:00:01Z", "", false},
+		{"until boundary", "", "2025-06-01T00:00:00Z", false},
+		{"expired", "", "2025-05-31T23:59:59Z", false},
+		{"timezone offset", "2025-06-01T08:00:00+09:00", "", true},
+		{"invalid from", "2025-01-01", "", false},
+		{"invalid until", "", "tomorrow", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithinValidity(tt.validFrom, tt.validUntil, now); got != tt.want {
+				t.Errorf("WithinValidity() = %v, want %v", got, tt.want)
+			}
+			sub := Subscriber{ValidFrom: tt.validFrom, ValidUntil: tt.validUntil}
+			if got := sub.IsWithinValidity(now); got != tt.want {
+				t.Errorf("IsWithinValidity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package replyattr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Def はポリシールールでAccess-Acceptへ付与できる応答属性の定義（D-09 セクション8.6.5準拠）
+type Def struct {
+	Name    string            // 属性名
+	Vendor  string            // ベンダー名（標準属性は空）
+	Integer bool              // 整数型の場合true（falseは文字列型）
+	Values  map[string]uint32 // 列挙型の値の名前（小文字）と値（整数型のみ）
+}
+
+// Defs はポリシールールで指定可能な応答属性の一覧（Auth ServerとAdmin TUIで共通）
+var Defs = []Def{
+	{Name: "Filter-Id"},
+	{Name: "Reply-Message"},
+	{Name: "Class"},
+	{Name: "Idle-Timeout", Integer: true},
+	// RFC 2865 5.29: Default(0), RADIUS-Request(1)
+	{Name: "Termination-Action", Integer: true, Values: map[string]uint32{"default": 0, "radius-request": 1}},
+	{Name: "Acct-Interim-Interval", Integer: true},
+	{Name: "WISPr-Bandwidth-Min-Up", Vendor: "WISPr", Integer: true},
+	{Name: "WISPr-Bandwidth-Min-Down", Vendor: "WISPr", Integer: true},
+	{Name: "WISPr-Bandwidth-Max-Up", Vendor: "WISPr", Integer: true},
+	{Name: "WISPr-Bandwidth-Max-Down", Vendor: "WISPr", Integer: true},
+	{Name: "WISPr-Redirection-URL", Vendor: "WISPr"},
+	{Name: "WISPr-Session-Terminate-Time", Vendor: "WISPr"},
+	{Name: "Aruba-User-Role", Vendor: "Aruba"},
+	{Name: "Mikrotik-Rate-Limit", Vendor: "Mikrotik"},
+}
+
+// Lookup は属性名（大文字小文字を区別しない）から応答属性の定義を返す。
+func Lookup(name string) (Def, bool) {
+	for _, def := range Defs {
+		if strings.EqualFold(def.Name, name) {
+			return def, true
+		}
+	}
+	return Def{}, false
+}
+
+// IntegerValue は整数型の属性値（列挙型の値の名前、または10進数の符号なし32ビット整数）を解釈する。
+// 文字列型の属性、または解釈できない値の場合はfalseを返す。
+func (d Def) IntegerValue(value string) (uint32, bool) {
+	if !d.Integer {
+		return 0, false
+	}
+	if v, ok := d.Values[strings.ToLower(value)]; ok {
+		return v, true
+	}
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(n), true
+}
@@ -0,0 +1,63 @@
+package replyattr
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	def, ok := Lookup("wispr-bandwidth-max-up")
+	if !ok {
+		t.Fatal("expected WISPr-Bandwidth-Max-Up to be found")
+	}
+	if def.Name != "WISPr-Bandwidth-Max-Up" || def.Vendor != "WISPr" || !def.Integer {
+		t.Errorf("unexpected definition: %+v", def)
+	}
+
+	if _, ok := Lookup("Framed-Pool"); ok {
+		t.Error("expected Framed-Pool to be unsupported")
+	}
+}
+
+func TestDefs_UniqueNames(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, def := range Defs {
+		if seen[def.Name] {
+			t.Errorf("duplicate attribute %s", def.Name)
+		}
+		seen[def.Name] = true
+		if !def.Integer && len(def.Values) > 0 {
+			t.Errorf("%s: enum values are only allowed for integer attributes", def.Name)
+		}
+	}
+}
+
+func TestDef_IntegerValue(t *testing.T) {
+	termination, _ := Lookup("Termination-Action")
+	idle, _ := Lookup("Idle-Timeout")
+	filter, _ := Lookup("Filter-Id")
+
+	tests := []struct {
+		name   string
+		def    Def
+		value  string
+		want   uint32
+		wantOK bool
+	}{
+		{"enum name", termination, "RADIUS-Request", 1, true},
+		{"enum default", termination, "default", 0, true},
+		{"enum numeric", termination, "1", 1, true},
+		{"decimal", idle, "600", 600, true},
+		{"max uint32", idle, "4294967295", 4294967295, true},
+		{"overflow", idle, "4294967296", 0, false},
+		{"negative", idle, "-1", 0, false},
+		{"not integer", idle, "10m", 0, false},
+		{"string attribute", filter, "1", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.def.IntegerValue(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("IntegerValue(%q) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package suci
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// TS 33.501 Annex C.3.4のProfile A/B共通パラメータ
+const (
+	encKeyLen = 16 // AES-128鍵長
+	icbLen    = 16 // AES-CTRの初期カウンタブロック長
+	macKeyLen = 32 // HMAC-SHA-256鍵長
+	// MACLen はMACタグ長（HMAC-SHA-256の先頭64bit）
+	MACLen = 8
+)
+
+// Curve は保護方式に対応する楕円曲線を返す。
+func (s Scheme) Curve() (ecdh.Curve, error) {
+	switch s {
+	case SchemeProfileA:
+		return ecdh.X25519(), nil
+	case SchemeProfileB:
+		return ecdh.P256(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedScheme, s)
+	}
+}
+
+// Encrypt はUE側の秘匿化処理を行い、一時公開鍵・暗号文・MACタグを返す。
+// Profile Bの一時公開鍵は圧縮形式で返す。
+func Encrypt(scheme Scheme, hnPub *ecdh.PublicKey, plaintext []byte) (ephPub, ciphertext, mac []byte, err error) {
+	curve, err := scheme.Curve()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	eph, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	z, err := eph.ECDH(hnPub)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	ephPub, err = encodePublicKey(scheme, eph.PublicKey())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	encKey, icb, macKey := deriveKeys(z, ephPub)
+	ciphertext, err = aesCTR(encKey, icb, plaintext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ephPub, ciphertext, computeMAC(macKey, ciphertext), nil
+}
+
+// Decrypt はホームネットワーク秘密鍵でSUCIの保護方式出力を復号する。
+// MACタグが一致しない場合はErrMACMismatchを返す。
+func Decrypt(scheme Scheme, hnPriv *ecdh.PrivateKey, ephPub, ciphertext, mac []byte) ([]byte, error) {
+	curve, err := scheme.Curve()
+	if err != nil {
+		return nil, err
+	}
+	if hnPriv.Curve() != curve {
+		return nil, fmt.Errorf("%w: curve mismatch for %s", ErrInvalidKey, scheme)
+	}
+	pub, err := decodePublicKey(scheme, ephPub)
+	if err != nil {
+		return nil, err
+	}
+	z, err := hnPriv.ECDH(pub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSUCI, err)
+	}
+
+	encKey, icb, macKey := deriveKeys(z, ephPub)
+	if !hmac.Equal(computeMAC(macKey, ciphertext), mac) {
+		return nil, ErrMACMismatch
+	}
+	return aesCTR(encKey, icb, ciphertext)
+}
+
+// EncodePublicKey はSUCIおよびUSIMで使用する形式で公開鍵を符号化する。
+// Profile Aは32オクテット、Profile Bは圧縮形式の33オクテットとなる。
+func EncodePublicKey(scheme Scheme, pub *ecdh.PublicKey) ([]byte, error) {
+	return encodePublicKey(scheme, pub)
+}
+
+func encodePublicKey(scheme Scheme, pub *ecdh.PublicKey) ([]byte, error) {
+	raw := pub.Bytes()
+	if scheme != SchemeProfileB {
+		return raw, nil
+	}
+	// ecdhのP-256公開鍵は非圧縮形式（0x04||X||Y）
+	x := new(big.Int).SetBytes(raw[1:33])
+	y := new(big.Int).SetBytes(raw[33:])
+	return elliptic.MarshalCompressed(elliptic.P256(), x, y), nil
+}
+
+func decodePublicKey(scheme Scheme, b []byte) (*ecdh.PublicKey, error) {
+	curve, err := scheme.Curve()
+	if err != nil {
+		return nil, err
+	}
+	if scheme == SchemeProfileB && len(b) == 33 {
+		x, y := elliptic.UnmarshalCompressed(elliptic.P256(), b)
+		if x == nil {
+			return nil, fmt.Errorf("%w: invalid ephemeral public key", ErrInvalidSUCI)
+		}
+		b = make([]byte, 65)
+		b[0] = 0x04
+		x.FillBytes(b[1:33])
+		y.FillBytes(b[33:])
+	}
+	pub, err := curve.NewPublicKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ephemeral public key", ErrInvalidSUCI)
+	}
+	return pub, nil
+}
+
+// deriveKeys はANSI-X9.63-KDF（SHA-256）で暗号鍵・ICB・MAC鍵を導出する。
+// SharedInfo1には一時公開鍵（送信形式）を使用する。
+func deriveKeys(z, sharedInfo []byte) (encKey, icb, macKey []byte) {
+	need := encKeyLen + icbLen + macKeyLen
+	out := make([]byte, 0, need+sha256.Size)
+	var counter [4]byte
+	for i := uint32(1); len(out) < need; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h := sha256.New()
+		h.Write(z)
+		h.Write(counter[:])
+		h.Write(sharedInfo)
+		out = h.Sum(out)
+	}
+	return out[:encKeyLen], out[encKeyLen : encKeyLen+icbLen], out[encKeyLen+icbLen : need]
+}
+
+func aesCTR(key, icb, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, icb).XORKeyStream(out, in)
+	return out, nil
+}
+
+func computeMAC(key, ciphertext []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(ciphertext)
+	return h.Sum(nil)[:MACLen]
+}
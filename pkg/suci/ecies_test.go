@@ -0,0 +1,137 @@
+package suci
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("hex.DecodeString(%q): %v", s, err)
+	}
+	return b
+}
+
+// TS 33.501 Annex C.4のテストデータ
+func TestDecrypt_TestVectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		scheme     Scheme
+		curve      ecdh.Curve
+		privateKey string
+		publicKey  string
+		ephPub     string
+		ciphertext string
+		mac        string
+	}{
+		{
+			name:       "Profile A",
+			scheme:     SchemeProfileA,
+			curve:      ecdh.X25519(),
+			privateKey: "c53c22208b61860b06c62e5406a7b330c2b577aa5558981510d128247d38bd1d",
+			publicKey:  "5a8d38864820197c3394b92613b20b91633cbd897119273bf8e4a6f4eec0a650",
+			ephPub:     "b2e92f836055a255837debf850b528997ce0201cb82adfe4be1f587d07d8457d",
+			ciphertext: "cb02352410",
+			mac:        "cddd9e730ef3fa87",
+		},
+		{
+			name:       "Profile B",
+			scheme:     SchemeProfileB,
+			curve:      ecdh.P256(),
+			privateKey: "f1ab1074477ebcc7f554ea1c5fc368b1616730155e0041ac447d6301975fecda",
+			publicKey:  "0272da71976234ce833a6907425867b82e074d44ef907dfb4b3e21c1c2256ebcd1",
+			ephPub:     "039aab8376597021e855679a9778ea0b67396e68c66df32c0f41e9acca2da9b9d1",
+			ciphertext: "46a33fc271",
+			mac:        "6ac7dae96aa30a4d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv, err := tt.curve.NewPrivateKey(mustHex(t, tt.privateKey))
+			if err != nil {
+				t.Fatalf("NewPrivateKey: %v", err)
+			}
+			pub, err := EncodePublicKey(tt.scheme, priv.PublicKey())
+			if err != nil {
+				t.Fatalf("EncodePublicKey: %v", err)
+			}
+			if hex.EncodeToString(pub) != tt.publicKey {
+				t.Errorf("public key = %x, want %s", pub, tt.publicKey)
+			}
+
+			plaintext, err := Decrypt(tt.scheme, priv, mustHex(t, tt.ephPub), mustHex(t, tt.ciphertext), mustHex(t, tt.mac))
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if hex.EncodeToString(plaintext) != "00012080f6" {
+				t.Errorf("plaintext = %x, want 00012080f6", plaintext)
+			}
+		})
+	}
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	for _, scheme := range []Scheme{SchemeProfileA, SchemeProfileB} {
+		t.Run(scheme.String(), func(t *testing.T) {
+			curve, _ := scheme.Curve()
+			priv, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatalf("GenerateKey: %v", err)
+			}
+			plaintext := EncodeMSIN("0123456789")
+
+			ephPub, ciphertext, mac, err := Encrypt(scheme, priv.PublicKey(), plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt: %v", err)
+			}
+			if scheme == SchemeProfileB && len(ephPub) != 33 {
+				t.Errorf("Profile B ephemeral key length = %d, want 33 (compressed)", len(ephPub))
+			}
+			if len(mac) != MACLen {
+				t.Errorf("MAC length = %d, want %d", len(mac), MACLen)
+			}
+
+			got, err := Decrypt(scheme, priv, ephPub, ciphertext, mac)
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("Decrypt = %x, want %x", got, plaintext)
+			}
+
+			// 暗号文の改ざんはMAC不一致となる
+			ciphertext[0] ^= 0xff
+			if _, err := Decrypt(scheme, priv, ephPub, ciphertext, mac); !errors.Is(err, ErrMACMismatch) {
+				t.Errorf("Decrypt(tampered) error = %v, want ErrMACMismatch", err)
+			}
+		})
+	}
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	privA, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	privB, _ := ecdh.P256().GenerateKey(rand.Reader)
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		if _, err := Decrypt(SchemeNull, privA, nil, nil, nil); !errors.Is(err, ErrUnsupportedScheme) {
+			t.Errorf("error = %v, want ErrUnsupportedScheme", err)
+		}
+	})
+	t.Run("curve mismatch", func(t *testing.T) {
+		if _, err := Decrypt(SchemeProfileA, privB, make([]byte, 32), []byte{1}, make([]byte, MACLen)); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("error = %v, want ErrInvalidKey", err)
+		}
+	})
+	t.Run("invalid ephemeral key", func(t *testing.T) {
+		if _, err := Decrypt(SchemeProfileB, privB, []byte{0x02, 0x01}, []byte{1}, make([]byte, MACLen)); !errors.Is(err, ErrInvalidSUCI) {
+			t.Errorf("error = %v, want ErrInvalidSUCI", err)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package suci
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ErrDuplicateKeyID は既に存在する鍵IDで鍵を生成しようとした場合のエラー
+var ErrDuplicateKeyID = errors.New("duplicate home network key ID")
+
+// HomeNetworkKey は鍵ファイルに格納するホームネットワーク鍵を表す。
+type HomeNetworkKey struct {
+	ID         uint8     `json:"id"`          // 鍵ID（SUCIのhnkey、0-255）
+	Scheme     Scheme    `json:"scheme"`      // 保護方式（1: Profile A、2: Profile B）
+	PrivateKey string    `json:"private_key"` // 秘密鍵（16進数）
+	CreatedAt  time.Time `json:"created_at"`  // 生成日時
+}
+
+// KeyFile はホームネットワーク鍵ファイル（JSON）の内容を表す。
+type KeyFile struct {
+	Keys []HomeNetworkKey `json:"keys"`
+}
+
+// LoadKeyFile は鍵ファイルを読み込む。
+func LoadKeyFile(path string) (*KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var kf KeyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return &kf, nil
+}
+
+// Save は鍵ファイルを書き込む（所有者のみ読み書き可能）。
+// 書き込み途中の内容を読ませないよう、一時ファイルへ書き込んでから置き換える。
+func (kf *KeyFile) Save(path string) error {
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".suci-keys-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Generate は新しい鍵ペアを生成して鍵ファイルに追加する。
+func (kf *KeyFile) Generate(id uint8, scheme Scheme) (*HomeNetworkKey, error) {
+	if kf.Find(id) != nil {
+		return nil, fmt.Errorf("%w: %d", ErrDuplicateKeyID, id)
+	}
+	curve, err := scheme.Curve()
+	if err != nil {
+		return nil, err
+	}
+	priv, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	kf.Keys = append(kf.Keys, HomeNetworkKey{
+		ID:         id,
+		Scheme:     scheme,
+		PrivateKey: hex.EncodeToString(priv.Bytes()),
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	})
+	return &kf.Keys[len(kf.Keys)-1], nil
+}
+
+// Find は鍵IDに一致する鍵を返す。存在しない場合はnilを返す。
+func (kf *KeyFile) Find(id uint8) *HomeNetworkKey {
+	for i := range kf.Keys {
+		if kf.Keys[i].ID == id {
+			return &kf.Keys[i]
+		}
+	}
+	return nil
+}
+
+// NextID は未使用の最小の鍵IDを返す。空きがない場合はfalseを返す。
+func (kf *KeyFile) NextID() (uint8, bool) {
+	for id := 0; id <= 255; id++ {
+		if kf.Find(uint8(id)) == nil {
+			return uint8(id), true
+		}
+	}
+	return 0, false
+}
+
+// privateKey は秘密鍵を復元する。
+func (k *HomeNetworkKey) privateKey() (*ecdh.PrivateKey, error) {
+	curve, err := k.Scheme.Curve()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(k.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %d: %v", ErrInvalidKey, k.ID, err)
+	}
+	priv, err := curve.NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %d: %v", ErrInvalidKey, k.ID, err)
+	}
+	return priv, nil
+}
+
+// PublicKey はUSIMに設定するホームネットワーク公開鍵を返す。
+func (k *HomeNetworkKey) PublicKey() ([]byte, error) {
+	priv, err := k.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	return EncodePublicKey(k.Scheme, priv.PublicKey())
+}
+
+// Keyring は鍵IDで引けるホームネットワーク秘密鍵の集合を表す。
+type Keyring struct {
+	keys map[uint8]*keyringEntry
+}
+
+type keyringEntry struct {
+	scheme Scheme
+	priv   *ecdh.PrivateKey
+}
+
+// NewKeyring は鍵ファイルの内容からKeyringを生成する。
+// 鍵IDの重複や不正な鍵が含まれる場合はエラーを返す。
+func NewKeyring(kf *KeyFile) (*Keyring, error) {
+	kr := &Keyring{keys: make(map[uint8]*keyringEntry, len(kf.Keys))}
+	for i := range kf.Keys {
+		k := &kf.Keys[i]
+		if _, dup := kr.keys[k.ID]; dup {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateKeyID, k.ID)
+		}
+		priv, err := k.privateKey()
+		if err != nil {
+			return nil, err
+		}
+		kr.keys[k.ID] = &keyringEntry{scheme: k.Scheme, priv: priv}
+	}
+	return kr, nil
+}
+
+// LoadKeyring は鍵ファイルを読み込んでKeyringを生成する。
+func LoadKeyring(path string) (*Keyring, error) {
+	kf, err := LoadKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(kf)
+}
+
+// KeyIDs は有効な鍵IDを昇順で返す。
+func (kr *Keyring) KeyIDs() []uint8 {
+	ids := make([]uint8, 0, len(kr.keys))
+	for id := range kr.keys {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Deconceal はSUCIを復号してIMSIを返す。
+// Null-schemeは鍵を使用せずにMSINからIMSIを組み立てる（nilのKeyringでも可）。
+func (kr *Keyring) Deconceal(id *Identity) (string, error) {
+	if id.Scheme == SchemeNull {
+		return id.MCC + id.MNC + id.MSIN, nil
+	}
+
+	if kr == nil {
+		return "", fmt.Errorf("%w: %d", ErrUnknownKeyID, id.KeyID)
+	}
+	entry, ok := kr.keys[id.KeyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrUnknownKeyID, id.KeyID)
+	}
+	if entry.scheme != id.Scheme {
+		return "", fmt.Errorf("%w: key %d is %s, SUCI uses %s", ErrUnsupportedScheme, id.KeyID, entry.scheme, id.Scheme)
+	}
+
+	plaintext, err := Decrypt(id.Scheme, entry.priv, id.EphemeralKey, id.Ciphertext, id.MAC)
+	if err != nil {
+		return "", err
+	}
+	msin, err := DecodeMSIN(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return id.MCC + id.MNC + msin, nil
+}
@@ -0,0 +1,171 @@
+package suci
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyFile_GenerateSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suci_keys.json")
+
+	kf := &KeyFile{}
+	if _, err := kf.Generate(1, SchemeProfileA); err != nil {
+		t.Fatalf("Generate(A): %v", err)
+	}
+	if _, err := kf.Generate(2, SchemeProfileB); err != nil {
+		t.Fatalf("Generate(B): %v", err)
+	}
+	if _, err := kf.Generate(1, SchemeProfileB); !errors.Is(err, ErrDuplicateKeyID) {
+		t.Errorf("Generate(duplicate) error = %v, want ErrDuplicateKeyID", err)
+	}
+	if _, err := kf.Generate(3, SchemeNull); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("Generate(null) error = %v, want ErrUnsupportedScheme", err)
+	}
+	if err := kf.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permission = %o, want 600", perm)
+	}
+
+	loaded, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeyFile: %v", err)
+	}
+	if len(loaded.Keys) != 2 || loaded.Find(2) == nil || loaded.Find(2).Scheme != SchemeProfileB {
+		t.Errorf("loaded keys = %+v", loaded.Keys)
+	}
+	if next, ok := loaded.NextID(); !ok || next != 0 {
+		t.Errorf("NextID = %d, %v, want 0, true", next, ok)
+	}
+
+	pub, err := loaded.Find(2).PublicKey()
+	if err != nil || len(pub) != 33 {
+		t.Errorf("PublicKey(B) = %x, %v, want 33 bytes", pub, err)
+	}
+}
+
+func TestKeyring_Deconceal(t *testing.T) {
+	const imsi = "001010123456789"
+
+	kf := &KeyFile{}
+	keyA, _ := kf.Generate(1, SchemeProfileA)
+	keyB, _ := kf.Generate(2, SchemeProfileB)
+	path := filepath.Join(t.TempDir(), "suci_keys.json")
+	if err := kf.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	kr, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	if ids := kr.KeyIDs(); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("KeyIDs = %v, want [1 2]", ids)
+	}
+
+	pubA, _ := keyA.PublicKey()
+	pubB, _ := keyB.PublicKey()
+
+	tests := []struct {
+		name    string
+		scheme  Scheme
+		keyID   uint8
+		pub     []byte
+		wantErr error
+	}{
+		{"Profile A", SchemeProfileA, 1, pubA, nil},
+		{"Profile B", SchemeProfileB, 2, pubB, nil},
+		{"null scheme", SchemeNull, 0, nil, nil},
+		{"unknown key ID", SchemeProfileA, 9, pubA, ErrUnknownKeyID},
+		{"scheme mismatch", SchemeProfileB, 1, pubB, ErrUnsupportedScheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nai, err := Conceal(tt.scheme, tt.keyID, tt.pub, imsi, 2)
+			if err != nil {
+				t.Fatalf("Conceal: %v", err)
+			}
+			id, err := ParseNAI(nai)
+			if err != nil {
+				t.Fatalf("ParseNAI(%s): %v", nai, err)
+			}
+			got, err := kr.Deconceal(id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Deconceal error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != imsi {
+				t.Errorf("Deconceal = %s, want %s", got, imsi)
+			}
+		})
+	}
+
+	t.Run("wrong private key", func(t *testing.T) {
+		other := &KeyFile{}
+		otherKey, _ := other.Generate(1, SchemeProfileA)
+		otherPub, _ := otherKey.PublicKey()
+		nai, _ := Conceal(SchemeProfileA, 1, otherPub, imsi, 2)
+		id, _ := ParseNAI(nai)
+		if _, err := kr.Deconceal(id); !errors.Is(err, ErrMACMismatch) {
+			t.Errorf("Deconceal error = %v, want ErrMACMismatch", err)
+		}
+	})
+
+	t.Run("nil keyring", func(t *testing.T) {
+		var nilKR *Keyring
+		nai, _ := Conceal(SchemeProfileA, 1, pubA, imsi, 2)
+		id, _ := ParseNAI(nai)
+		if _, err := nilKR.Deconceal(id); !errors.Is(err, ErrUnknownKeyID) {
+			t.Errorf("Deconceal error = %v, want ErrUnknownKeyID", err)
+		}
+	})
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		kf      *KeyFile
+		wantErr error
+	}{
+		{"duplicate ID", &KeyFile{Keys: []HomeNetworkKey{
+			{ID: 1, Scheme: SchemeProfileA, PrivateKey: "c53c22208b61860b06c62e5406a7b330c2b577aa5558981510d128247d38bd1d"},
+			{ID: 1, Scheme: SchemeProfileA, PrivateKey: "c53c22208b61860b06c62e5406a7b330c2b577aa5558981510d128247d38bd1d"},
+		}}, ErrDuplicateKeyID},
+		{"invalid hex", &KeyFile{Keys: []HomeNetworkKey{{ID: 1, Scheme: SchemeProfileA, PrivateKey: "zz"}}}, ErrInvalidKey},
+		{"wrong length", &KeyFile{Keys: []HomeNetworkKey{{ID: 1, Scheme: SchemeProfileB, PrivateKey: "0102"}}}, ErrInvalidKey},
+		{"null scheme", &KeyFile{Keys: []HomeNetworkKey{{ID: 1, Scheme: SchemeNull, PrivateKey: "00"}}}, ErrUnsupportedScheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.kf); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewKeyring error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseScheme(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Scheme
+	}{
+		{"profile-a", SchemeProfileA},
+		{"B", SchemeProfileB},
+		{"0", SchemeNull},
+	}
+	for _, tt := range tests {
+		got, err := ParseScheme(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseScheme(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseScheme("profile-c"); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("ParseScheme(invalid) error = %v, want ErrUnsupportedScheme", err)
+	}
+}
@@ -0,0 +1,241 @@
+package suci
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SUPIタイプ（TS 23.003 Section 28.7.3）
+const supiTypeIMSI = 0
+
+// realmSuffix はSUCI NAIのレルム末尾
+const realmSuffix = ".3gppnetwork.org"
+
+// Identity はSUCI形式のNAIを解析した結果を保持する。
+// 形式: type0.rid<RID>.schid<方式>.hnkey<鍵ID>.ecckey<一時公開鍵>.cip<暗号文>.mac<MAC>@nai.5gc.mnc<MNC>.mcc<MCC>.3gppnetwork.org
+// Null-schemeの場合はhnkey以降が userid<MSIN> となる。
+type Identity struct {
+	RoutingIndicator string // ルーティング識別子
+	Scheme           Scheme // 保護方式
+	KeyID            uint8  // ホームネットワーク公開鍵ID
+	MCC              string // レルムのMCC
+	MNC              string // レルムのMNC（2桁MNCは先頭の"0"を除去）
+	MSIN             string // Null-schemeのMSIN
+	EphemeralKey     []byte // 一時公開鍵
+	Ciphertext       []byte // 暗号文
+	MAC              []byte // MACタグ
+}
+
+// IsNAI はIdentityのユーザー部がSUCI形式（"type<N>."で始まる）かどうかを判定する。
+// 永続ID・仮名・再認証IDは数字で始まるため衝突しない。
+func IsNAI(identity string) bool {
+	return len(identity) > 5 && strings.HasPrefix(identity, "type") &&
+		identity[4] >= '0' && identity[4] <= '9' && identity[5] == '.'
+}
+
+// ParseNAI はSUCI形式のNAIを解析する。
+// SUPIタイプはIMSI（type0）のみ対応する。
+func ParseNAI(nai string) (*Identity, error) {
+	userPart, realm, found := strings.Cut(nai, "@")
+	if !found || !IsNAI(userPart) {
+		return nil, ErrInvalidSUCI
+	}
+
+	fields := map[string]string{}
+	var order []string
+	for label := range strings.SplitSeq(userPart, ".") {
+		name, value := splitLabel(label)
+		if name == "" {
+			return nil, fmt.Errorf("%w: malformed label %q", ErrInvalidSUCI, label)
+		}
+		if _, dup := fields[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate label %q", ErrInvalidSUCI, name)
+		}
+		fields[name] = value
+		order = append(order, name)
+	}
+
+	if fields["type"] != strconv.Itoa(supiTypeIMSI) {
+		return nil, fmt.Errorf("%w: SUPI type %s", ErrUnsupportedScheme, fields["type"])
+	}
+	if len(order) < 3 || order[1] != "rid" || order[2] != "schid" {
+		return nil, fmt.Errorf("%w: missing rid/schid", ErrInvalidSUCI)
+	}
+
+	id := &Identity{RoutingIndicator: fields["rid"]}
+	if !isDigits(id.RoutingIndicator) || len(id.RoutingIndicator) > 4 {
+		return nil, fmt.Errorf("%w: invalid routing indicator", ErrInvalidSUCI)
+	}
+	schid, err := strconv.ParseUint(fields["schid"], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid scheme ID", ErrInvalidSUCI)
+	}
+	id.Scheme = Scheme(schid)
+
+	id.MCC, id.MNC, err = parseRealm(realm)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case id.Scheme == SchemeNull:
+		if len(order) != 4 || order[3] != "userid" || !isDigits(fields["userid"]) {
+			return nil, fmt.Errorf("%w: null-scheme requires userid", ErrInvalidSUCI)
+		}
+		id.MSIN = fields["userid"]
+	case id.Scheme.IsECIES():
+		if len(order) != 7 || order[3] != "hnkey" || order[4] != "ecckey" || order[5] != "cip" || order[6] != "mac" {
+			return nil, fmt.Errorf("%w: incomplete scheme output", ErrInvalidSUCI)
+		}
+		keyID, err := strconv.ParseUint(fields["hnkey"], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid home network key ID", ErrInvalidSUCI)
+		}
+		id.KeyID = uint8(keyID)
+		if id.EphemeralKey, err = hex.DecodeString(fields["ecckey"]); err != nil {
+			return nil, fmt.Errorf("%w: invalid ecckey", ErrInvalidSUCI)
+		}
+		if id.Ciphertext, err = hex.DecodeString(fields["cip"]); err != nil || len(id.Ciphertext) == 0 {
+			return nil, fmt.Errorf("%w: invalid cip", ErrInvalidSUCI)
+		}
+		if id.MAC, err = hex.DecodeString(fields["mac"]); err != nil || len(id.MAC) != MACLen {
+			return nil, fmt.Errorf("%w: invalid mac", ErrInvalidSUCI)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedScheme, id.Scheme)
+	}
+
+	return id, nil
+}
+
+// FormatNAI はSUCI形式のNAIを組み立てる。
+func (id *Identity) FormatNAI() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type%d.rid%s.schid%d", supiTypeIMSI, id.RoutingIndicator, id.Scheme)
+	if id.Scheme == SchemeNull {
+		fmt.Fprintf(&b, ".userid%s", id.MSIN)
+	} else {
+		fmt.Fprintf(&b, ".hnkey%d.ecckey%x.cip%x.mac%x", id.KeyID, id.EphemeralKey, id.Ciphertext, id.MAC)
+	}
+	mnc := id.MNC
+	if len(mnc) == 2 {
+		mnc = "0" + mnc
+	}
+	fmt.Fprintf(&b, "@nai.5gc.mnc%s.mcc%s%s", mnc, id.MCC, realmSuffix)
+	return b.String()
+}
+
+// Conceal はIMSIをSUCI形式のNAIに秘匿化する（UE側の処理）。
+// mncLenはIMSIに含まれるMNCの桁数（2または3）。
+func Conceal(scheme Scheme, keyID uint8, hnPub []byte, imsi string, mncLen int) (string, error) {
+	if !isDigits(imsi) || (mncLen != 2 && mncLen != 3) || len(imsi) <= 3+mncLen {
+		return "", fmt.Errorf("%w: invalid IMSI", ErrInvalidSUCI)
+	}
+	id := &Identity{
+		RoutingIndicator: "0",
+		Scheme:           scheme,
+		KeyID:            keyID,
+		MCC:              imsi[:3],
+		MNC:              imsi[3 : 3+mncLen],
+	}
+	msin := imsi[3+mncLen:]
+
+	if scheme == SchemeNull {
+		id.MSIN = msin
+		return id.FormatNAI(), nil
+	}
+
+	pub, err := decodePublicKey(scheme, hnPub)
+	if err != nil {
+		return "", err
+	}
+	id.EphemeralKey, id.Ciphertext, id.MAC, err = Encrypt(scheme, pub, EncodeMSIN(msin))
+	if err != nil {
+		return "", err
+	}
+	return id.FormatNAI(), nil
+}
+
+// EncodeMSIN はMSINをTBCD（下位ニブル先行、奇数桁は0xFで埋める）に符号化する。
+func EncodeMSIN(msin string) []byte {
+	out := make([]byte, (len(msin)+1)/2)
+	for i := range out {
+		lo := msin[2*i] - '0'
+		hi := byte(0x0f)
+		if 2*i+1 < len(msin) {
+			hi = msin[2*i+1] - '0'
+		}
+		out[i] = hi<<4 | lo
+	}
+	return out
+}
+
+// DecodeMSIN はTBCD符号化されたMSINを数字列に復号する。
+func DecodeMSIN(b []byte) (string, error) {
+	digits := make([]byte, 0, len(b)*2)
+	for i, octet := range b {
+		lo, hi := octet&0x0f, octet>>4
+		if lo > 9 {
+			return "", fmt.Errorf("%w: invalid MSIN digit", ErrInvalidSUCI)
+		}
+		digits = append(digits, '0'+lo)
+		if hi == 0x0f && i == len(b)-1 {
+			break
+		}
+		if hi > 9 {
+			return "", fmt.Errorf("%w: invalid MSIN digit", ErrInvalidSUCI)
+		}
+		digits = append(digits, '0'+hi)
+	}
+	if len(digits) == 0 {
+		return "", fmt.Errorf("%w: empty MSIN", ErrInvalidSUCI)
+	}
+	return string(digits), nil
+}
+
+// parseRealm は"nai.5gc.mnc<MNC>.mcc<MCC>.3gppnetwork.org"からMCC/MNCを取り出す。
+// レルムのMNCは3桁固定のため、先頭が"0"の場合は2桁MNCとして扱う。
+func parseRealm(realm string) (mcc, mnc string, err error) {
+	lower := strings.ToLower(realm)
+	rest, ok := strings.CutPrefix(lower, "nai.5gc.")
+	if ok {
+		rest, ok = strings.CutSuffix(rest, realmSuffix)
+	}
+	if !ok {
+		return "", "", fmt.Errorf("%w: unexpected realm %q", ErrInvalidSUCI, realm)
+	}
+	mncLabel, mccLabel, _ := strings.Cut(rest, ".")
+	mnc, okMNC := strings.CutPrefix(mncLabel, "mnc")
+	mcc, okMCC := strings.CutPrefix(mccLabel, "mcc")
+	if !okMNC || !okMCC || len(mnc) != 3 || len(mcc) != 3 || !isDigits(mnc) || !isDigits(mcc) {
+		return "", "", fmt.Errorf("%w: unexpected realm %q", ErrInvalidSUCI, realm)
+	}
+	if mnc[0] == '0' {
+		mnc = mnc[1:]
+	}
+	return mcc, mnc, nil
+}
+
+// splitLabel は"schid1"のようなラベルを名前と値に分割する。
+func splitLabel(label string) (name, value string) {
+	for _, prefix := range []string{"type", "rid", "schid", "hnkey", "ecckey", "cip", "mac", "userid"} {
+		if v, ok := strings.CutPrefix(label, prefix); ok && v != "" {
+			return prefix, v
+		}
+	}
+	return "", ""
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
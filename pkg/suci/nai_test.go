@@ -0,0 +1,142 @@
+package suci
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestIsNAI(t *testing.T) {
+	tests := []struct {
+		identity string
+		want     bool
+	}{
+		{"type0.rid0.schid1.hnkey1.ecckey00.cip00.mac00", true},
+		{"type1.rid0", true},
+		{"0440101234567890", false},
+		{"typeX.rid0", false},
+		{"type0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsNAI(tt.identity); got != tt.want {
+			t.Errorf("IsNAI(%q) = %v, want %v", tt.identity, got, tt.want)
+		}
+	}
+}
+
+func TestParseNAI(t *testing.T) {
+	const realm = "@nai.5gc.mnc010.mcc440.3gppnetwork.org"
+
+	t.Run("ECIES scheme", func(t *testing.T) {
+		id, err := ParseNAI("type0.rid678.schid1.hnkey27.ecckey0a0b.cip0102.mac0011223344556677" + realm)
+		if err != nil {
+			t.Fatalf("ParseNAI: %v", err)
+		}
+		if id.RoutingIndicator != "678" || id.Scheme != SchemeProfileA || id.KeyID != 27 {
+			t.Errorf("rid/scheme/key = %s/%s/%d", id.RoutingIndicator, id.Scheme, id.KeyID)
+		}
+		if id.MCC != "440" || id.MNC != "10" {
+			t.Errorf("MCC/MNC = %s/%s, want 440/10", id.MCC, id.MNC)
+		}
+		if len(id.EphemeralKey) != 2 || len(id.Ciphertext) != 2 || len(id.MAC) != MACLen {
+			t.Errorf("scheme output lengths = %d/%d/%d", len(id.EphemeralKey), len(id.Ciphertext), len(id.MAC))
+		}
+	})
+
+	t.Run("null scheme", func(t *testing.T) {
+		id, err := ParseNAI("type0.rid0.schid0.userid1234567890" + realm)
+		if err != nil {
+			t.Fatalf("ParseNAI: %v", err)
+		}
+		if id.Scheme != SchemeNull || id.MSIN != "1234567890" {
+			t.Errorf("scheme/MSIN = %s/%s", id.Scheme, id.MSIN)
+		}
+	})
+
+	t.Run("3-digit MNC", func(t *testing.T) {
+		id, err := ParseNAI("type0.rid0.schid0.userid123456789@nai.5gc.mnc310.mcc310.3gppnetwork.org")
+		if err != nil {
+			t.Fatalf("ParseNAI: %v", err)
+		}
+		if id.MNC != "310" {
+			t.Errorf("MNC = %s, want 310", id.MNC)
+		}
+	})
+
+	errTests := []struct {
+		name    string
+		nai     string
+		wantErr error
+	}{
+		{"missing realm", "type0.rid0.schid0.userid1234", ErrInvalidSUCI},
+		{"not SUCI", "0440101234567890" + realm, ErrInvalidSUCI},
+		{"unsupported SUPI type", "type1.rid0.schid0.userid1234" + realm, ErrUnsupportedScheme},
+		{"unsupported scheme", "type0.rid0.schid5.hnkey1.ecckey00.cip00.mac0011223344556677" + realm, ErrUnsupportedScheme},
+		{"missing mac", "type0.rid0.schid1.hnkey1.ecckey00.cip00" + realm, ErrInvalidSUCI},
+		{"short mac", "type0.rid0.schid1.hnkey1.ecckey00.cip00.mac0011" + realm, ErrInvalidSUCI},
+		{"invalid hex", "type0.rid0.schid1.hnkey1.ecckeyzz.cip00.mac0011223344556677" + realm, ErrInvalidSUCI},
+		{"key ID out of range", "type0.rid0.schid1.hnkey256.ecckey00.cip00.mac0011223344556677" + realm, ErrInvalidSUCI},
+		{"unknown label", "type0.rid0.schid0.foo1" + realm, ErrInvalidSUCI},
+		{"duplicate label", "type0.rid0.rid1.schid0.userid1" + realm, ErrInvalidSUCI},
+		{"unexpected realm", "type0.rid0.schid0.userid1234@wlan.example.com", ErrInvalidSUCI},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseNAI(tt.nai); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseNAI error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatNAI_RoundTrip(t *testing.T) {
+	id := &Identity{
+		RoutingIndicator: "0",
+		Scheme:           SchemeProfileB,
+		KeyID:            3,
+		MCC:              "001",
+		MNC:              "01",
+		EphemeralKey:     []byte{0x02, 0xaa},
+		Ciphertext:       []byte{0x01, 0x02},
+		MAC:              make([]byte, MACLen),
+	}
+	nai := id.FormatNAI()
+	if want := "type0.rid0.schid2.hnkey3.ecckey02aa.cip0102.mac0000000000000000@nai.5gc.mnc001.mcc001.3gppnetwork.org"; nai != want {
+		t.Fatalf("FormatNAI = %s, want %s", nai, want)
+	}
+	parsed, err := ParseNAI(nai)
+	if err != nil {
+		t.Fatalf("ParseNAI: %v", err)
+	}
+	if parsed.MNC != "01" || parsed.KeyID != 3 || parsed.Scheme != SchemeProfileB {
+		t.Errorf("parsed = %+v", parsed)
+	}
+}
+
+func TestMSINEncoding(t *testing.T) {
+	tests := []struct {
+		msin    string
+		encoded string
+	}{
+		{"001002086", "00012080f6"},
+		{"0123456789", "1032547698"},
+	}
+	for _, tt := range tests {
+		got := EncodeMSIN(tt.msin)
+		if hexStr := hex.EncodeToString(got); hexStr != tt.encoded {
+			t.Errorf("EncodeMSIN(%s) = %s, want %s", tt.msin, hexStr, tt.encoded)
+		}
+		decoded, err := DecodeMSIN(got)
+		if err != nil || decoded != tt.msin {
+			t.Errorf("DecodeMSIN = %s, %v, want %s", decoded, err, tt.msin)
+		}
+	}
+
+	if _, err := DecodeMSIN([]byte{0x0a}); !errors.Is(err, ErrInvalidSUCI) {
+		t.Errorf("DecodeMSIN(invalid) error = %v, want ErrInvalidSUCI", err)
+	}
+	if _, err := DecodeMSIN([]byte{0xf1, 0x23}); !errors.Is(err, ErrInvalidSUCI) {
+		t.Errorf("DecodeMSIN(filler in middle) error = %v, want ErrInvalidSUCI", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Package suci は3GPP TS 33.501 Annex CのSUCI（秘匿化IMSI）処理を提供する。
+// ECIES Profile A（X25519）・Profile B（P-256）の暗号化・復号、
+// TS 23.003 Section 28.7.3形式のNAIの解析、およびホームネットワーク鍵ファイルの管理を行う。
+package suci
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Scheme は保護方式識別子（Protection Scheme Identifier）を表す。
+type Scheme uint8
+
+const (
+	// SchemeNull はNull-scheme（秘匿化なし）
+	SchemeNull Scheme = 0
+	// SchemeProfileA はECIES Profile A（Curve25519）
+	SchemeProfileA Scheme = 1
+	// SchemeProfileB はECIES Profile B（secp256r1）
+	SchemeProfileB Scheme = 2
+)
+
+// String は保護方式の表示名を返す。
+func (s Scheme) String() string {
+	switch s {
+	case SchemeNull:
+		return "null"
+	case SchemeProfileA:
+		return "profile-a"
+	case SchemeProfileB:
+		return "profile-b"
+	default:
+		return fmt.Sprintf("scheme-%d", uint8(s))
+	}
+}
+
+// IsECIES はECIESによる秘匿化方式（Profile A/B）かどうかを判定する。
+func (s Scheme) IsECIES() bool {
+	return s == SchemeProfileA || s == SchemeProfileB
+}
+
+// ParseScheme は表示名（"profile-a"等）または数値表記から保護方式を返す。
+func ParseScheme(s string) (Scheme, error) {
+	switch s {
+	case "null", "0":
+		return SchemeNull, nil
+	case "profile-a", "A", "a", "1":
+		return SchemeProfileA, nil
+	case "profile-b", "B", "b", "2":
+		return SchemeProfileB, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnsupportedScheme, s)
+	}
+}
+
+// SUCI関連エラー
+var (
+	// ErrInvalidSUCI はSUCI形式のNAIが不正な場合のエラー
+	ErrInvalidSUCI = errors.New("invalid SUCI")
+	// ErrUnsupportedScheme は非対応の保護方式の場合のエラー
+	ErrUnsupportedScheme = errors.New("unsupported protection scheme")
+	// ErrUnknownKeyID は鍵ファイルに存在しない鍵IDの場合のエラー
+	ErrUnknownKeyID = errors.New("unknown home network key ID")
+	// ErrMACMismatch はMACタグ検証失敗の場合のエラー
+	ErrMACMismatch = errors.New("SUCI MAC mismatch")
+	// ErrInvalidKey は鍵が不正な場合のエラー
+	ErrInvalidKey = errors.New("invalid home network key")
+)